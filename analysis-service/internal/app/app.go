@@ -10,10 +10,10 @@ import (
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/delivery/httpd"
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
-	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/analyzer"
-	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
-	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/worker"
-	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/worker/queue"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/buildinfo"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/httpmetrics"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/tracing"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/websocket"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -21,129 +21,114 @@ import (
 )
 
 type App struct {
-	server         *http.Server
-	logger         zerolog.Logger
-	config         *config.Config
-	db             *sql.DB
-	analysisWorker worker.AnalysisWorker
-	rabbitMQRepo   repository.RabbitMQRepository
+	server     *http.Server
+	logger     zerolog.Logger
+	config     *config.Config
+	db         *sql.DB
+	core       *core
+	eventHub   service.EventHub
+	stopEvents context.CancelFunc
 }
 
 func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
-	rabbitMQRepo, err := repository.NewRabbitMQRepository(cfg.RabbitMQ.URL, log)
+	c, err := newCore(cfg, log, db)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := rabbitMQRepo.SetupQueue(
-		cfg.RabbitMQ.Exchange,
-		cfg.RabbitMQ.QueueName,
-		cfg.RabbitMQ.RoutingKey,
-	); err != nil {
-		return nil, err
-	}
-
-	rabbitMQPublisher := queue.NewRabbitMQPublisher(rabbitMQRepo.Channel(), log)
-	rabbitMQConsumer := queue.NewRabbitMQConsumer(
-		rabbitMQRepo.Channel(),
-		cfg.RabbitMQ.QueueName,
-		cfg.RabbitMQ.ConsumerTag,
-		log,
-	)
-
-	reportRepo := repository.NewReportRepository(db, log)
-	plagiarismRepo := repository.NewPlagiarismRepository(db, log)
+	overrideRepo := repository.NewOverrideRepository(db, log)
 
-	fileClient := integration.NewFileClient(
-		cfg.Services.File.URL,
-		cfg.Services.File.Timeout,
-		cfg.Services.File.RetryCount,
-		cfg.Services.File.RetryDelay,
+	referenceService := service.NewReferenceCorpusService(
+		repository.NewReferenceRepository(db, log),
+		c.fileClient,
+		c.plagiarismChecker,
 		log,
-	)
-
-	workClient := integration.NewWorkClient(
-		cfg.Services.Work.URL,
-		cfg.Services.Work.Timeout,
-		cfg.Services.Work.RetryCount,
-		cfg.Services.Work.RetryDelay,
-		fileClient,
-		log,
-	)
-
-	hashComparator := analyzer.NewHashComparator(cfg.Analysis.HashAlgorithm)
-
-	plagiarismChecker := analyzer.NewPlagiarismChecker(
-		workClient,
-		fileClient,
-		hashComparator,
-		log,
-		analyzer.PlagiarismCheckerConfig{
-			HashAlgorithm:       cfg.Analysis.HashAlgorithm,
-			SimilarityThreshold: cfg.Analysis.SimilarityThreshold,
-			EnableDeepAnalysis:  cfg.Analysis.EnableContentAnalysis,
-			Timeout:             cfg.Analysis.Timeout,
-			MaxRetries:          cfg.Services.Work.RetryCount,
+		service.ReferenceCorpusConfig{
+			Enabled:          cfg.ReferenceCorpus.Enabled,
+			MaxBulkDocuments: cfg.ReferenceCorpus.MaxBulkDocuments,
 		},
 	)
 
-	messageHandler := queue.NewMessageHandler(log)
-
-	analysisService := service.NewAnalysisService(
-		reportRepo,
-		plagiarismRepo,
-		workClient,
-		fileClient,
-		plagiarismChecker,
-		messageHandler,
-		rabbitMQPublisher,
+	reportService := service.NewReportService(
+		c.reportRepo,
+		c.plagiarismRepo,
+		c.queueRepo,
+		overrideRepo,
 		log,
-		service.AnalysisConfig{
-			HashAlgorithm:       cfg.Analysis.HashAlgorithm,
-			SimilarityThreshold: cfg.Analysis.SimilarityThreshold,
-			EnableDeepAnalysis:  cfg.Analysis.EnableContentAnalysis,
-			Timeout:             cfg.Analysis.Timeout,
-			MaxRetries:          cfg.Services.Work.RetryCount,
-			BatchSize:           cfg.Analysis.BatchSize,
+		service.ExportConfig{
+			AsyncThreshold:    cfg.Export.AsyncThreshold,
+			JobTTL:            cfg.Export.JobTTL,
+			Timeout:           cfg.Analysis.Timeout,
+			DefaultThreshold:  cfg.Analysis.SimilarityThreshold,
+			RosterMaxRows:     cfg.Export.Roster.MaxRows,
+			RosterLinkBaseURL: cfg.Export.Roster.LinkBaseURL,
+			MinCoverage:       cfg.Reports.MinCoverage,
 		},
 	)
 
-	reportService := service.NewReportService(
-		reportRepo,
-		plagiarismRepo,
-		log,
-	)
+	overrideService := service.NewOverrideService(overrideRepo, c.reportRepo, log)
 
 	wordCloudService := service.NewWordCloudService(
-		reportRepo,
-		fileClient,
+		c.reportRepo,
+		c.fileClient,
 		log,
 	)
 
-	workerPool := worker.NewWorkerPool(cfg.Analysis.MaxWorkers, log)
-
-	analysisWorker := worker.NewAnalysisWorker(
-		workerPool,
-		rabbitMQConsumer,
-		reportRepo,
-		analysisService,
-		log,
-	)
+	var eventHub service.EventHub
+	if cfg.Events.WebSocket.Enabled {
+		eventHub = service.NewEventHub(log, service.EventHubConfig{
+			BufferSize:       cfg.Events.WebSocket.BufferSize,
+			MaxSubscribers:   cfg.Events.WebSocket.MaxClients,
+			SlowClientPolicy: cfg.Events.WebSocket.SlowClientPolicy,
+		})
+	}
 
 	handler := httpd.NewHandler(
-		analysisService,
+		c.analysisService,
 		reportService,
 		wordCloudService,
+		c.notificationService,
+		overrideService,
+		c.webhookService,
+		referenceService,
+		eventHub,
 		log,
+		httpd.HandlerConfig{
+			ExportRateLimit:     cfg.Export.RateLimit,
+			ExportRateWindow:    cfg.Export.RateWindow,
+			StreamPingInterval:  cfg.Events.WebSocket.PingInterval,
+			StreamWriteTimeout:  cfg.Events.WebSocket.WriteTimeout,
+			MatchesDefaultLimit: cfg.Reports.MatchesDefaultLimit,
+			MatchesMaxLimit:     cfg.Reports.MatchesMaxLimit,
+			ValidateUUIDs:       cfg.Analysis.ValidateUUIDs,
+			UrgentRateLimit:     cfg.Analysis.Urgent.RateLimit,
+			UrgentRateWindow:    cfg.Analysis.Urgent.RateWindow,
+			PreviewRateLimit:    cfg.Analysis.Preview.RateLimit,
+			PreviewRateWindow:   cfg.Analysis.Preview.RateWindow,
+			PreviewMaxFileSize:  cfg.Analysis.Preview.MaxFileSize,
+
+			DetailedValidationErrors: cfg.Server.DetailedValidationErrors,
+		},
 	)
 
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
 	router.Use(middleware.RealIP)
+	router.Use(skipForWebSocket(tracing.Middleware("/health", "/ready", "/version", cfg.Metrics.Path, cfg.Metrics.PrometheusPath)))
+
+	var metrics *httpmetrics.Registry
+	if cfg.Metrics.Enabled {
+		metrics = httpmetrics.New(httpmetrics.Config{
+			SizeBuckets:    cfg.Metrics.SizeBuckets,
+			LatencyBuckets: cfg.Metrics.LatencyBuckets,
+		})
+		router.Use(metrics.Middleware)
+	}
+
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
-	router.Use(middleware.Timeout(60 * time.Second))
+	router.Use(skipForWebSocket(middleware.Timeout(60 * time.Second)))
 
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   cfg.CORS.AllowedOrigins,
@@ -154,7 +139,17 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 		MaxAge:           cfg.CORS.MaxAge,
 	}))
 
+	// Без ключа доступны только проверки здоровья, версия и метрики
+	router.Use(httpd.RequireAPIKey(cfg.Auth.APIKeys, "/health", "/ready", "/version", cfg.Metrics.Path, cfg.Metrics.PrometheusPath))
+
 	handler.RegisterRoutes(router)
+	if cfg.Server.VersionEndpoint {
+		router.Get("/version", buildinfo.Handler("analysis-service", c.plagiarismChecker.GetCheckerInfo().Version))
+	}
+	if metrics != nil {
+		router.Get(cfg.Metrics.Path, metrics.Handler)
+		router.Get(cfg.Metrics.PrometheusPath, c.promMetrics.Handler)
+	}
 
 	server := &http.Server{
 		Addr:         cfg.Server.Address,
@@ -165,22 +160,64 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 	}
 
 	return &App{
-		server:         server,
-		logger:         log,
-		config:         cfg,
-		db:             db,
-		analysisWorker: analysisWorker,
-		rabbitMQRepo:   rabbitMQRepo,
+		server:   server,
+		logger:   log,
+		config:   cfg,
+		db:       db,
+		core:     c,
+		eventHub: eventHub,
 	}, nil
 }
 
+// skipForWebSocket не применяет middleware к запросам на WebSocket: поток событий
+// живёт дольше таймаута запроса, а ответ после перехвата соединения уже не записать
+func skipForWebSocket(mw func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if websocket.IsUpgradeRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
+
+// startEventStream подписывает хаб на события анализа в брокере, включая опубликованные другими экземплярами и воркерами
+func (a *App) startEventStream() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	deliveries, err := a.core.rabbitMQRepo.SubscribeEvents(ctx, a.config.RabbitMQ.Exchange, "analysis-events-stream", service.StreamEventTypes)
+	if err != nil {
+		cancel()
+		return err
+	}
+	a.stopEvents = cancel
+
+	go func() {
+		for delivery := range deliveries {
+			a.eventHub.Publish(delivery.RoutingKey, delivery.Body)
+		}
+		a.logger.Info().Msg("Analysis event stream stopped")
+	}()
+
+	return nil
+}
+
 func (a *App) Run() error {
 	ctx := context.Background()
-	if err := a.analysisWorker.Start(ctx); err != nil {
+	if err := a.core.start(ctx); err != nil {
 		a.logger.Error().Err(err).Msg("Failed to start analysis worker")
 		return err
 	}
 
+	if a.eventHub != nil {
+		if err := a.startEventStream(); err != nil {
+			a.logger.Error().Err(err).Msg("Failed to subscribe to analysis events")
+			return err
+		}
+	}
+
 	a.logger.Info().Msgf("Starting analysis service on %s", a.config.Server.Address)
 	return a.server.ListenAndServe()
 }
@@ -188,25 +225,31 @@ func (a *App) Run() error {
 func (a *App) Shutdown(ctx context.Context) error {
 	a.logger.Info().Msg("Shutting down analysis service...")
 
-	if err := a.analysisWorker.Stop(); err != nil {
-		a.logger.Error().Err(err).Msg("Failed to stop analysis worker")
+	// Сначала перестаём принимать запросы и дожидаемся текущих,
+	// пока БД и RabbitMQ ещё доступны обработчикам
+	serverErr := a.server.Shutdown(ctx)
+	if serverErr != nil {
+		a.logger.Error().Err(serverErr).Msg("Failed to shutdown HTTP server")
 	}
 
-	if a.rabbitMQRepo != nil {
-		if err := a.rabbitMQRepo.Close(); err != nil {
-			a.logger.Error().Err(err).Msg("Failed to close RabbitMQ connection")
+	// Перехваченные WebSocket-соединения Shutdown не ждёт — закрываем их сами
+	if a.eventHub != nil {
+		if a.stopEvents != nil {
+			a.stopEvents()
 		}
+		a.eventHub.Close()
 	}
 
+	a.core.stop(a.logger)
+
 	if a.db != nil {
 		if err := a.db.Close(); err != nil {
 			a.logger.Error().Err(err).Msg("Failed to close database connection")
 		}
 	}
 
-	if err := a.server.Shutdown(ctx); err != nil {
-		a.logger.Error().Err(err).Msg("Failed to shutdown HTTP server")
-		return err
+	if serverErr != nil {
+		return serverErr
 	}
 
 	a.logger.Info().Msg("Analysis service stopped")
@@ -0,0 +1,398 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/config"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/analyzer"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/worker"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/worker/queue"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/prommetrics"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/sharedcache"
+	"github.com/rs/zerolog"
+)
+
+// core — общая для HTTP-сервиса и отдельного воркера сборка: очередь, клиенты, проверка, анализ и фоновые воркеры
+type core struct {
+	rabbitMQRepo repository.RabbitMQRepository
+
+	reportRepo     repository.ReportRepository
+	plagiarismRepo repository.PlagiarismRepository
+	queueRepo      repository.AnalysisQueueRepository
+
+	fileClient          integration.FileClient
+	cacheStore          sharedcache.Store
+	plagiarismChecker   analyzer.PlagiarismChecker
+	notificationService service.NotificationService
+	webhookService      service.WebhookService
+	auditLogger         service.AuditLogger
+	analysisService     service.AnalysisService
+
+	// nil, если метрики выключены
+	promMetrics *prommetrics.Registry
+
+	analysisWorker worker.AnalysisWorker
+	retrySweeper   *worker.RetrySweeper
+	hashIndex      *worker.HashIndexRefresher
+}
+
+func newCore(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*core, error) {
+	rabbitMQRepo, err := repository.NewRabbitMQRepository(cfg.RabbitMQ.URL, log)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rabbitMQRepo.SetupQueueWithRetry(
+		context.Background(),
+		cfg.RabbitMQ.Exchange,
+		cfg.RabbitMQ.QueueName,
+		cfg.RabbitMQ.RoutingKey,
+		cfg.RabbitMQ.DLQName,
+		repository.SetupRetry(cfg.RabbitMQ.SetupRetry),
+	); err != nil {
+		rabbitMQRepo.Close()
+		return nil, err
+	}
+
+	rabbitMQPublisher := queue.NewRabbitMQPublisher(rabbitMQRepo.Channel(), log, queue.PublisherConfig{
+		DLQName:          cfg.RabbitMQ.DLQName,
+		SourceQueue:      cfg.RabbitMQ.QueueName,
+		SourceRoutingKey: cfg.RabbitMQ.RoutingKey,
+	})
+	rabbitMQConsumer := queue.NewRabbitMQConsumer(
+		rabbitMQRepo.Channel(),
+		cfg.RabbitMQ.QueueName,
+		cfg.RabbitMQ.ConsumerTag,
+		cfg.RabbitMQ.DrainTimeout,
+		queue.RateLimit{
+			PerSecond: cfg.RabbitMQ.IngestionRateLimit.MessagesPerSecond,
+			Burst:     cfg.RabbitMQ.IngestionRateLimit.Burst,
+		},
+		log,
+	)
+
+	reportRepo := repository.NewReportRepository(db, log)
+	plagiarismRepo := repository.NewPlagiarismRepository(db, log)
+	notificationRepo := repository.NewNotificationRepository(db, log)
+	queueRepo := repository.NewAnalysisQueueRepository(db, log)
+
+	fileClient := integration.NewFileClient(
+		cfg.Services.File.URL,
+		cfg.Services.File.APIKey,
+		cfg.Services.File.Timeout,
+		cfg.Services.File.RetryCount,
+		cfg.Services.File.RetryDelay,
+		log,
+	)
+
+	// С redis.url кеши общие для всех экземпляров, иначе — в памяти процесса
+	cacheStore := sharedcache.New(context.Background(), sharedcache.Config{
+		RedisURL:    cfg.Redis.URL,
+		KeyPrefix:   cfg.Redis.KeyPrefix,
+		DialTimeout: cfg.Redis.DialTimeout,
+	}, log)
+
+	// Хеши файлов кешируются для всех проверок, если включён прогрев заданий
+	if cfg.Analysis.Warmup.Enabled {
+		fileClient = integration.NewCachingFileClient(fileClient, cacheStore, cfg.Analysis.Warmup.TTL)
+	}
+
+	workClient := integration.NewWorkClient(
+		cfg.Services.Work.URL,
+		cfg.Services.Work.APIKey,
+		cfg.Services.Work.Timeout,
+		cfg.Services.Work.RetryCount,
+		cfg.Services.Work.RetryDelay,
+		fileClient,
+		log,
+	)
+
+	var promMetrics *prommetrics.Registry
+	if cfg.Metrics.Enabled {
+		promMetrics = prommetrics.NewRegistry()
+	}
+
+	plagiarismChecker := newPlagiarismChecker(cfg, log, db, workClient, fileClient, cacheStore, promMetrics)
+
+	messageHandler := queue.NewMessageHandler(log)
+
+	notificationService := service.NewNotificationService(
+		notificationRepo,
+		log,
+		service.NotificationConfig{
+			Enabled:           cfg.Notifications.Enabled,
+			DefaultRecipients: cfg.Notifications.DefaultRecipients,
+			Timeout:           cfg.Notifications.Timeout,
+			SMTPHost:          cfg.Notifications.SMTP.Host,
+			SMTPPort:          cfg.Notifications.SMTP.Port,
+			SMTPUsername:      cfg.Notifications.SMTP.Username,
+			SMTPPassword:      cfg.Notifications.SMTP.Password,
+			SMTPFrom:          cfg.Notifications.SMTP.From,
+		},
+	)
+
+	webhookService := service.NewWebhookService(
+		repository.NewWebhookRepository(db, log),
+		log,
+		service.WebhookConfig{
+			Enabled:     cfg.Webhooks.Enabled,
+			Timeout:     cfg.Webhooks.Timeout,
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			RetryDelay:  cfg.Webhooks.RetryDelay,
+			MaxFailures: cfg.Webhooks.MaxFailures,
+		},
+	)
+
+	auditLogger, err := service.NewAuditLogger(
+		repository.NewAuditRepository(db, log),
+		service.AuditConfig{
+			Enabled: cfg.Audit.Enabled,
+			Sink:    cfg.Audit.Sink,
+			LogPath: cfg.Audit.LogPath,
+		},
+	)
+	if err != nil {
+		cacheStore.Close()
+		rabbitMQRepo.Close()
+		return nil, err
+	}
+
+	analysisService := service.NewAnalysisService(
+		reportRepo,
+		plagiarismRepo,
+		queueRepo,
+		workClient,
+		fileClient,
+		plagiarismChecker,
+		messageHandler,
+		rabbitMQPublisher,
+		rabbitMQRepo,
+		notificationService,
+		webhookService,
+		auditLogger,
+		log,
+		analysisConfig(cfg),
+	)
+
+	workerPool := worker.NewWorkerPool(cfg.Analysis.MaxWorkers, log)
+
+	var workerMetrics *worker.Metrics
+	if promMetrics != nil {
+		workerMetrics = worker.NewMetrics(promMetrics, workerPool, rabbitMQConsumer)
+	}
+
+	analysisWorker := worker.NewAnalysisWorker(
+		workerPool,
+		rabbitMQConsumer,
+		rabbitMQPublisher,
+		reportRepo,
+		analysisService,
+		log,
+		worker.AnalysisWorkerConfig{
+			DuplicateEvents: cfg.Analysis.DuplicateEvents,
+			Metrics:         workerMetrics,
+		},
+	)
+
+	// Несколько экземпляров разбирают очередь повторов без пересечений (SKIP LOCKED)
+	var retrySweeper *worker.RetrySweeper
+	if cfg.Analysis.RetryQueue.Enabled {
+		retrySweeper = worker.NewRetrySweeper(analysisService, log, worker.RetrySweeperConfig{
+			Interval:  cfg.Analysis.RetryQueue.SweepInterval,
+			BatchSize: cfg.Analysis.RetryQueue.SweepBatch,
+		})
+	}
+
+	// Индекс точных копий у каждого процесса свой; периодическое перестроение подтягивает отчёты других экземпляров
+	var hashIndex *worker.HashIndexRefresher
+	if cfg.Analysis.HashIndex.Enabled {
+		hashIndex = worker.NewHashIndexRefresher(analysisService, log, cfg.Analysis.HashIndex.RefreshInterval)
+	}
+
+	return &core{
+		rabbitMQRepo:        rabbitMQRepo,
+		reportRepo:          reportRepo,
+		plagiarismRepo:      plagiarismRepo,
+		queueRepo:           queueRepo,
+		fileClient:          fileClient,
+		cacheStore:          cacheStore,
+		plagiarismChecker:   plagiarismChecker,
+		notificationService: notificationService,
+		webhookService:      webhookService,
+		auditLogger:         auditLogger,
+		analysisService:     analysisService,
+		promMetrics:         promMetrics,
+		analysisWorker:      analysisWorker,
+		retrySweeper:        retrySweeper,
+		hashIndex:           hashIndex,
+	}, nil
+}
+
+// newPlagiarismChecker собирает проверку по конфигурации; с реестром метрик она считает проверки и найденный плагиат
+func newPlagiarismChecker(
+	cfg *config.Config,
+	log zerolog.Logger,
+	db *sql.DB,
+	workClient integration.WorkClient,
+	fileClient integration.FileClient,
+	cacheStore sharedcache.Store,
+	promMetrics *prommetrics.Registry,
+) analyzer.PlagiarismChecker {
+	// LRU в памяти процесса точнее ограничивает объём, поэтому общее хранилище для текста — только Redis
+	var textCacheStore sharedcache.Store
+	if cacheStore.Shared() {
+		textCacheStore = cacheStore
+	}
+	var pairCacheStore sharedcache.Store
+	if cfg.Analysis.PairCache.Backend == "redis" && cacheStore.Shared() {
+		pairCacheStore = cacheStore
+	}
+
+	var checkerMetrics *analyzer.CheckerMetrics
+	if promMetrics != nil {
+		checkerMetrics = analyzer.NewCheckerMetrics(promMetrics)
+	}
+
+	var fingerprintStore analyzer.FingerprintStore
+	if cfg.Analysis.Winnowing.Persist {
+		fingerprintStore = repository.NewFingerprintRepository(db, log)
+	}
+
+	return analyzer.NewPlagiarismChecker(
+		workClient,
+		fileClient,
+		analyzer.NewHashComparator(cfg.Analysis.HashAlgorithm),
+		log,
+		analyzer.PlagiarismCheckerConfig{
+			HashAlgorithm:          cfg.Analysis.HashAlgorithm,
+			SimilarityThreshold:    cfg.Analysis.SimilarityThreshold,
+			EnableDeepAnalysis:     cfg.Analysis.EnableContentAnalysis,
+			Timeout:                cfg.Analysis.Timeout,
+			MaxRetries:             cfg.Services.Work.RetryCount,
+			ContentTypes:           cfg.Analysis.ContentTypes,
+			CodeLanguage:           cfg.Analysis.CodeLanguage,
+			ExtractionFallback:     cfg.Analysis.ExtractionFallback,
+			MaxConcurrentDownloads: cfg.Analysis.MaxContentDownloads,
+			UrgentDownloads:        urgentLimits(cfg).downloads,
+			SizePrefilter:          cfg.Analysis.SizePrefilter,
+			MinSizeRatio:           cfg.Analysis.MinSizeRatio,
+			MinRecordedMatch:       cfg.Analysis.MinRecordedMatch,
+			PartialMatchCap:        cfg.Analysis.PartialMatchCap,
+			EditDistanceMaxLength:  cfg.Analysis.EditDistanceMaxLength,
+			WinnowingEnabled:       cfg.Analysis.Winnowing.Enabled,
+			WinnowingK:             cfg.Analysis.Winnowing.K,
+			WinnowingWindow:        cfg.Analysis.Winnowing.Window,
+			FingerprintStore:       fingerprintStore,
+			SimHashStore:           repository.NewFingerprintRepository(db, log),
+			TextCacheEnabled:       cfg.Analysis.TextCache.Enabled,
+			TextCacheMaxBytes:      cfg.Analysis.TextCache.MaxBytes,
+			TextCacheMaxEntries:    cfg.Analysis.TextCache.MaxEntries,
+			TextCacheStore:         textCacheStore,
+			TextCacheTTL:           cfg.Analysis.TextCache.TTL,
+			PairCacheEnabled:       cfg.Analysis.PairCache.Enabled,
+			PairCacheMaxEntries:    cfg.Analysis.PairCache.MaxEntries,
+			PairCacheStore:         pairCacheStore,
+			PairCacheTTL:           cfg.Analysis.PairCache.TTL,
+			WarmupEnabled:          cfg.Analysis.Warmup.Enabled,
+			WarmupTTL:              cfg.Analysis.Warmup.TTL,
+			HashIndexEnabled:       cfg.Analysis.HashIndex.Enabled,
+			AnalysisVersion:        cfg.Analysis.AlgorithmVersion,
+			Metrics:                checkerMetrics,
+		},
+	)
+}
+
+type urgentReserve struct {
+	slots     int
+	downloads int
+}
+
+// urgentLimits — резерв срочного анализа; выключенный не резервирует ни слотов, ни загрузок
+func urgentLimits(cfg *config.Config) urgentReserve {
+	if !cfg.Analysis.Urgent.Enabled {
+		return urgentReserve{}
+	}
+	return urgentReserve{slots: cfg.Analysis.Urgent.Slots, downloads: cfg.Analysis.Urgent.Downloads}
+}
+
+func analysisConfig(cfg *config.Config) service.AnalysisConfig {
+	return service.AnalysisConfig{
+		HashAlgorithm:           cfg.Analysis.HashAlgorithm,
+		SimilarityThreshold:     cfg.Analysis.SimilarityThreshold,
+		EnableDeepAnalysis:      cfg.Analysis.EnableContentAnalysis,
+		Timeout:                 cfg.Analysis.Timeout,
+		MaxRetries:              cfg.Services.Work.RetryCount,
+		BatchSize:               cfg.Analysis.BatchSize,
+		PublishStartedEvent:     cfg.Analysis.PublishStartedEvent,
+		ShareBatchComparisonSet: cfg.Analysis.ShareBatchComparison,
+		MaxReportRetries:        cfg.Analysis.MaxReportRetries,
+		RetryConcurrency:        cfg.Analysis.RetryConcurrency,
+		RetryOrder:              cfg.Analysis.RetryOrder,
+		RefreshStatsAfterBatch:  cfg.Analysis.RefreshStatsAfterBatch,
+		PendingFile:             cfg.Analysis.PendingFile,
+		RetryQueueEnabled:       cfg.Analysis.RetryQueue.Enabled,
+		RetryQueueMaxAttempts:   cfg.Analysis.RetryQueue.MaxAttempts,
+		RetryQueueBaseDelay:     cfg.Analysis.RetryQueue.BaseDelay,
+		RetryQueueMaxDelay:      cfg.Analysis.RetryQueue.MaxDelay,
+		SiblingRecheck:          cfg.Analysis.SiblingRecheck.Enabled,
+		SiblingRecheckWindow:    cfg.Analysis.SiblingRecheck.Window,
+		SiblingRecheckMaxWorks:  cfg.Analysis.SiblingRecheck.MaxWorks,
+		CrossAssignment:         cfg.Analysis.CrossAssignment.Enabled,
+		CrossAssignmentWindow:   cfg.Analysis.CrossAssignment.Window,
+		CrossAssignmentMaxWorks: cfg.Analysis.CrossAssignment.MaxWorks,
+		LaterMatches:            cfg.Analysis.LaterMatches.Enabled,
+		LaterMatchMinMatch:      cfg.Analysis.LaterMatches.MinMatch,
+		HashIndexEnabled:        cfg.Analysis.HashIndex.Enabled,
+		MaxWorkers:              cfg.Analysis.MaxWorkers,
+		UrgentSlots:             urgentLimits(cfg).slots,
+		UrgentWaitTimeout:       cfg.Analysis.Urgent.WaitTimeout,
+		SyncMaxComparisonSet:    cfg.Analysis.SyncLimit.MaxComparisonSet,
+		SyncLimitAction:         cfg.Analysis.SyncLimit.Action,
+		PreviewEnabled:          cfg.Analysis.Preview.Enabled,
+	}
+}
+
+// start запускает разбор очереди и фоновые воркеры
+func (c *core) start(ctx context.Context) error {
+	if err := c.analysisWorker.Start(ctx); err != nil {
+		return err
+	}
+	if c.retrySweeper != nil {
+		c.retrySweeper.Start(ctx)
+	}
+	if c.hashIndex != nil {
+		c.hashIndex.Start(ctx)
+	}
+	return nil
+}
+
+// stop останавливает воркеры и закрывает кеш, журнал аудита и соединение с RabbitMQ
+func (c *core) stop(log zerolog.Logger) {
+	if c.retrySweeper != nil {
+		c.retrySweeper.Stop()
+	}
+
+	if c.hashIndex != nil {
+		c.hashIndex.Stop()
+	}
+
+	if err := c.analysisWorker.Stop(); err != nil {
+		log.Error().Err(err).Msg("Failed to stop analysis worker")
+	}
+
+	if err := c.cacheStore.Close(); err != nil {
+		log.Error().Err(err).Msg("Failed to close cache store")
+	}
+
+	if err := c.auditLogger.Close(); err != nil {
+		log.Error().Err(err).Msg("Failed to close audit log")
+	}
+
+	if err := c.rabbitMQRepo.Close(); err != nil {
+		log.Error().Err(err).Msg("Failed to close RabbitMQ connection")
+	}
+}
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/config"
+)
+
+func TestAnalysisConfigCarriesLimits(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Analysis.MaxWorkers = 8
+	cfg.Analysis.Urgent = config.UrgentConfig{Enabled: true, Slots: 2, WaitTimeout: 3 * time.Second, Downloads: 4}
+	cfg.Analysis.SyncLimit = config.SyncLimitConfig{MaxComparisonSet: 500, Action: "async"}
+	cfg.Analysis.Preview.Enabled = true
+
+	got := analysisConfig(cfg)
+
+	if got.MaxWorkers != 8 || got.UrgentSlots != 2 || got.UrgentWaitTimeout != 3*time.Second {
+		t.Fatalf("worker limits not passed: %+v", got)
+	}
+	if got.SyncMaxComparisonSet != 500 || got.SyncLimitAction != "async" || !got.PreviewEnabled {
+		t.Fatalf("sync limit or preview not passed: %+v", got)
+	}
+}
+
+func TestUrgentLimitsDisabled(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Analysis.Urgent = config.UrgentConfig{Enabled: false, Slots: 2, Downloads: 4}
+
+	if got := urgentLimits(cfg); got.slots != 0 || got.downloads != 0 {
+		t.Fatalf("disabled urgent analysis reserves %+v", got)
+	}
+	if got := analysisConfig(cfg); got.UrgentSlots != 0 {
+		t.Fatalf("UrgentSlots = %d, want 0", got.UrgentSlots)
+	}
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/config"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/prommetrics"
+	"github.com/rs/zerolog"
+)
+
+// Worker — отдельный процесс разбора очереди без HTTP API; собирается тем же newCore, что и App
+type Worker struct {
+	core   *core
+	logger zerolog.Logger
+	// Метрики Prometheus на server.address; nil, если метрики выключены
+	metricsServer *http.Server
+}
+
+func NewWorker(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*Worker, error) {
+	c, err := newCore(cfg, log, db)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Worker{core: c, logger: log}
+	if c.promMetrics != nil {
+		w.metricsServer = &http.Server{
+			Addr:         cfg.Server.Address,
+			Handler:      metricsHandler(cfg, c.promMetrics),
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
+		}
+	}
+	return w, nil
+}
+
+// metricsHandler отдаёт метрики воркера по metrics.prometheus_path — тому же пути, что и у HTTP-сервиса
+func metricsHandler(cfg *config.Config, reg *prommetrics.Registry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(cfg.Metrics.PrometheusPath, reg.Handler)
+	return mux
+}
+
+func (w *Worker) Start(ctx context.Context) error {
+	if err := w.core.start(ctx); err != nil {
+		return err
+	}
+
+	if w.metricsServer != nil {
+		go func() {
+			w.logger.Info().Msgf("Serving worker metrics on %s", w.metricsServer.Addr)
+			if err := w.metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				w.logger.Error().Err(err).Msg("Worker metrics server failed")
+			}
+		}()
+	}
+	return nil
+}
+
+func (w *Worker) Shutdown(ctx context.Context) error {
+	var serverErr error
+	if w.metricsServer != nil {
+		serverErr = w.metricsServer.Shutdown(ctx)
+	}
+
+	w.core.stop(w.logger)
+	return serverErr
+}
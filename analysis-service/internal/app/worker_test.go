@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/config"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/prommetrics"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/sharedcache"
+)
+
+// fakeFileClient отдаёт хеши файлов из памяти; остальные методы интерфейса не вызываются
+type fakeFileClient struct {
+	integration.FileClient
+	files map[string][]byte
+}
+
+func (f *fakeFileClient) GetFileHash(_ context.Context, fileID string) (string, int64, error) {
+	sum := sha256.Sum256(f.files[fileID])
+	return hex.EncodeToString(sum[:]), int64(len(f.files[fileID])), nil
+}
+
+func (f *fakeFileClient) GetContentHash(context.Context, string) (string, error) {
+	return "", nil
+}
+
+// scrapeCounter читает значение счётчика из ответа metricsHandler
+func scrapeCounter(t *testing.T, handler http.Handler, path, name string) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d", path, rec.Code)
+	}
+	for _, line := range strings.Split(rec.Body.String(), "\n") {
+		if value, ok := strings.CutPrefix(line, name+" "); ok {
+			return value
+		}
+	}
+	t.Fatalf("%s not found in:\n%s", name, rec.Body.String())
+	return ""
+}
+
+func TestWorkerMetricsCountChecks(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Metrics.PrometheusPath = "/metrics/prometheus"
+	cfg.Analysis.HashAlgorithm = "sha256"
+
+	reg := prommetrics.NewRegistry()
+	files := &fakeFileClient{files: map[string][]byte{
+		"file-a": []byte("same essay"),
+		"file-b": []byte("same essay"),
+	}}
+	cache := sharedcache.New(context.Background(), sharedcache.Config{}, zerolog.Nop())
+	checker := newPlagiarismChecker(cfg, zerolog.Nop(), nil, nil, files, cache, reg)
+	handler := metricsHandler(cfg, reg)
+
+	if got := scrapeCounter(t, handler, cfg.Metrics.PrometheusPath, "analysis_plagiarism_checks_total"); got != "0" {
+		t.Fatalf("checks before = %s, want 0", got)
+	}
+
+	hash, size, _ := files.GetFileHash(context.Background(), "file-a")
+	previous := []models.SimilarWork{{WorkID: "work-a", StudentID: "alice", FileID: "file-a", FileHash: hash, FileSize: size}}
+	if _, err := checker.CheckPlagiarismAgainst(context.Background(), "work-b", "file-b", "assignment", "bob", previous, 70); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if got := scrapeCounter(t, handler, cfg.Metrics.PrometheusPath, "analysis_plagiarism_checks_total"); got != "1" {
+		t.Fatalf("checks after = %s, want 1", got)
+	}
+	if got := scrapeCounter(t, handler, cfg.Metrics.PrometheusPath, "analysis_plagiarism_detected_total"); got != "1" {
+		t.Fatalf("detected after = %s, want 1", got)
+	}
+}
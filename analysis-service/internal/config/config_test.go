@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+// Поставляемый config/config.yaml должен читаться и проходить Validate: повторённый ключ
+// или опечатка в нём не дают сервису запуститься
+func TestLoadShippedConfig(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if cfg.Analysis.MaxWorkers != 5 {
+		t.Errorf("analysis.max_workers = %d, want 5", cfg.Analysis.MaxWorkers)
+	}
+}
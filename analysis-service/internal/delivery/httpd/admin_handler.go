@@ -0,0 +1,83 @@
+package httpd
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) GetThroughput(w http.ResponseWriter, r *http.Request) {
+	bucket := r.URL.Query().Get("bucket")
+	if bucket == "" {
+		bucket = "hour"
+	}
+
+	since := time.Now().Add(-7 * 24 * time.Hour)
+	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		parsed, err := parseTimeParam(sinceStr)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid since format. Use RFC3339 or YYYY-MM-DD")
+			return
+		}
+		since = parsed
+	}
+
+	ctx := r.Context()
+	response, err := h.reportService.GetThroughput(ctx, bucket, since)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid bucket") {
+			writeError(w, http.StatusBadRequest, "Invalid bucket. Use 'hour', 'day' or 'week'")
+			return
+		}
+		h.handleReportError(w, err)
+		return
+	}
+
+	writeSuccess(w, response)
+}
+
+func parseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+func (h *Handler) RefreshAssignmentStats(w http.ResponseWriter, r *http.Request) {
+	assignmentID := chi.URLParam(r, "assignment_id")
+	if assignmentID == "" {
+		writeError(w, http.StatusBadRequest, "Assignment ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	stats, err := h.reportService.RefreshAssignmentStats(ctx, assignmentID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	writeSuccess(w, stats)
+}
+
+// GetReportRawDetails отдаёт details отчёта в том виде, в каком они лежат в БД, — для разбора
+// отчётов, которые после преобразования в ответ выглядят неверно
+func (h *Handler) GetReportRawDetails(w http.ResponseWriter, r *http.Request) {
+	reportID := chi.URLParam(r, "report_id")
+	if reportID == "" {
+		writeError(w, http.StatusBadRequest, "Report ID is required")
+		return
+	}
+
+	details, err := h.reportService.GetReportRawDetails(r.Context(), reportID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(details)
+}
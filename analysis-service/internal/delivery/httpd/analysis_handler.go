@@ -2,33 +2,64 @@ package httpd
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 func (h *Handler) AnalyzeWork(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		WorkID       string `json:"work_id"`
-		FileID       string `json:"file_id"`
-		AssignmentID string `json:"assignment_id"`
-		StudentID    string `json:"student_id"`
+	var req models.CreateReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	if !h.checkUUIDs(w, "work_id", req.WorkID, "file_id", req.FileID, "assignment_id", req.AssignmentID, "student_id", req.StudentID) {
+		return
 	}
 
+	ctx := service.WithTriggerSource(r.Context(), models.TriggerSourceAPI)
+	result, err := h.analysisService.AnalyzeWorkSync(ctx, req.WorkID, req.FileID, req.AssignmentID, req.StudentID)
+	var tooLarge *service.ComparisonSetTooLargeError
+	if errors.As(err, &tooLarge) {
+		h.writeComparisonSetTooLarge(w, req.WorkID, tooLarge)
+		return
+	}
+	if err != nil {
+		h.handleAnalysisError(w, err)
+		return
+	}
+
+	writeSuccess(w, result)
+}
+
+// AnalyzeWorkUrgent анализирует работу сразу, не дожидаясь очереди; при занятых срочных слотах — 503
+func (h *Handler) AnalyzeWorkUrgent(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateReportRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	if req.WorkID == "" || req.FileID == "" || req.AssignmentID == "" || req.StudentID == "" {
-		writeError(w, http.StatusBadRequest, "All fields (work_id, file_id, assignment_id, student_id) are required")
+	if !h.validateRequest(w, &req) {
 		return
 	}
 
-	ctx := r.Context()
-	result, err := h.analysisService.AnalyzeWork(ctx, req.WorkID, req.FileID, req.AssignmentID, req.StudentID)
+	if !h.checkUUIDs(w, "work_id", req.WorkID, "file_id", req.FileID, "assignment_id", req.AssignmentID, "student_id", req.StudentID) {
+		return
+	}
+
+	result, err := h.analysisService.AnalyzeWorkUrgent(r.Context(), req.WorkID, req.FileID, req.AssignmentID, req.StudentID)
 	if err != nil {
 		h.handleAnalysisError(w, err)
 		return
@@ -38,20 +69,17 @@ func (h *Handler) AnalyzeWork(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) AnalyzeWorkAsync(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		WorkID       string `json:"work_id"`
-		FileID       string `json:"file_id"`
-		AssignmentID string `json:"assignment_id"`
-		StudentID    string `json:"student_id"`
-	}
-
+	var req models.CreateReportRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	if req.WorkID == "" || req.FileID == "" || req.AssignmentID == "" || req.StudentID == "" {
-		writeError(w, http.StatusBadRequest, "All fields (work_id, file_id, assignment_id, student_id) are required")
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	if !h.checkUUIDs(w, "work_id", req.WorkID, "file_id", req.FileID, "assignment_id", req.AssignmentID, "student_id", req.StudentID) {
 		return
 	}
 
@@ -78,6 +106,14 @@ func (h *Handler) GetAnalysisResult(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.checkUUIDs(w, "work_id", workID) {
+		return
+	}
+
+	if !h.authorizeWork(w, r, workID) {
+		return
+	}
+
 	ctx := r.Context()
 	result, err := h.analysisService.GetAnalysisResult(ctx, workID)
 	if err != nil {
@@ -100,6 +136,12 @@ func (h *Handler) BatchAnalyze(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for _, workID := range req.WorkIDs {
+		if !h.checkUUIDs(w, "work_id", workID) {
+			return
+		}
+	}
+
 	ctx := r.Context()
 	response, err := h.analysisService.BatchAnalyze(ctx, req.WorkIDs)
 	if err != nil {
@@ -110,18 +152,85 @@ func (h *Handler) BatchAnalyze(w http.ResponseWriter, r *http.Request) {
 	writeSuccess(w, response)
 }
 
+func (h *Handler) GetComparisonPair(w http.ResponseWriter, r *http.Request) {
+	workA := r.URL.Query().Get("work_a")
+	workB := r.URL.Query().Get("work_b")
+	if workA == "" || workB == "" {
+		writeError(w, http.StatusBadRequest, "Both work_a and work_b are required")
+		return
+	}
+
+	if workA == workB {
+		writeError(w, http.StatusBadRequest, "work_a and work_b must be different works")
+		return
+	}
+
+	if !h.checkUUIDs(w, "work_a", workA, "work_b", workB) {
+		return
+	}
+
+	// Студент видит сравнение, если одна из работ пары — его
+	if !h.authorizeWork(w, r, workA, workB) {
+		return
+	}
+
+	ctx := r.Context()
+	comparison, err := h.analysisService.GetComparisonPair(ctx, workA, workB)
+	if err != nil {
+		h.handleAnalysisError(w, err)
+		return
+	}
+
+	writeSuccess(w, comparison)
+}
+
+func (h *Handler) GetWorksByHash(w http.ResponseWriter, r *http.Request) {
+	fileHash := strings.ToLower(chi.URLParam(r, "hash"))
+	if fileHash == "" || len(fileHash) > 64 || !isHex(fileHash) {
+		writeError(w, http.StatusBadRequest, "hash must be a hex string of up to 64 characters")
+		return
+	}
+
+	limit := getIntQueryParam(r, "limit", 100)
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	ctx := r.Context()
+	response, err := h.analysisService.GetWorksByHash(ctx, fileHash, limit)
+	if err != nil {
+		h.handleAnalysisError(w, err)
+		return
+	}
+
+	writeSuccess(w, response)
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
 func (h *Handler) RetryFailedAnalyses(w http.ResponseWriter, r *http.Request) {
 	limit := getIntQueryParam(r, "limit", 10)
 
 	ctx := r.Context()
-	retryCount, err := h.analysisService.RetryFailedAnalyses(ctx, limit)
+	result, err := h.analysisService.RetryFailedAnalyses(ctx, limit)
 	if err != nil {
 		h.handleAnalysisError(w, err)
 		return
 	}
 
 	response := map[string]interface{}{
-		"retried":   retryCount,
+		"total":     result.Total,
+		"retried":   result.Retried,
+		"abandoned": result.Abandoned,
+		"failed":    result.Failed,
+		"results":   result.Results,
 		"limit":     limit,
 		"message":   "Failed analyses retry completed",
 		"timestamp": time.Now().UTC(),
@@ -130,31 +239,140 @@ func (h *Handler) RetryFailedAnalyses(w http.ResponseWriter, r *http.Request) {
 	writeSuccess(w, response)
 }
 
+func (h *Handler) SetAssignmentThreshold(w http.ResponseWriter, r *http.Request) {
+	assignmentID := chi.URLParam(r, "assignment_id")
+	if assignmentID == "" {
+		writeError(w, http.StatusBadRequest, "Assignment ID is required")
+		return
+	}
+
+	var req models.SetAssignmentThresholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Threshold == nil {
+		writeError(w, http.StatusBadRequest, "threshold is required")
+		return
+	}
+
+	ctx := r.Context()
+	threshold, err := h.analysisService.SetAssignmentThreshold(ctx, assignmentID, *req.Threshold, r.Header.Get("X-User-ID"))
+	if err != nil {
+		h.handleAnalysisError(w, err)
+		return
+	}
+
+	writeSuccess(w, threshold)
+}
+
+// WarmupAssignment загружает базу сравнения задания заранее, например перед дедлайном
+func (h *Handler) WarmupAssignment(w http.ResponseWriter, r *http.Request) {
+	assignmentID := chi.URLParam(r, "assignment_id")
+	if assignmentID == "" {
+		writeError(w, http.StatusBadRequest, "Assignment ID is required")
+		return
+	}
+
+	result, err := h.analysisService.WarmupAssignment(r.Context(), assignmentID)
+	if err != nil {
+		h.handleAnalysisError(w, err)
+		return
+	}
+
+	writeSuccess(w, result)
+}
+
+// RebuildHashIndex перечитывает индекс точных копий из отчётов; ?assignment_id= — только одно задание
+func (h *Handler) RebuildHashIndex(w http.ResponseWriter, r *http.Request) {
+	result, err := h.analysisService.RebuildHashIndex(r.Context(), r.URL.Query().Get("assignment_id"))
+	if err != nil {
+		h.handleAnalysisError(w, err)
+		return
+	}
+
+	writeSuccess(w, result)
+}
+
+func (h *Handler) GetAnalysisVersion(w http.ResponseWriter, r *http.Request) {
+	result, err := h.analysisService.GetAnalysisVersion(r.Context())
+	if err != nil {
+		h.handleAnalysisError(w, err)
+		return
+	}
+
+	writeSuccess(w, result)
+}
+
+func (h *Handler) ReanalyzeOutdated(w http.ResponseWriter, r *http.Request) {
+	limit := getIntQueryParam(r, "limit", 10)
+
+	ctx := r.Context()
+	result, err := h.analysisService.ReanalyzeOutdated(ctx, limit)
+	if err != nil {
+		h.handleAnalysisError(w, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"total":      result.Total,
+		"reanalyzed": result.Retried,
+		"failed":     result.Failed,
+		"results":    result.Results,
+		"limit":      limit,
+		"message":    "Outdated reports reanalysis completed",
+		"timestamp":  time.Now().UTC(),
+	}
+
+	writeSuccess(w, response)
+}
+
+// writeComparisonSetTooLarge — 202 с отчётом запущенного асинхронного анализа или 413 с предложением запустить его
+func (h *Handler) writeComparisonSetTooLarge(w http.ResponseWriter, workID string, e *service.ComparisonSetTooLargeError) {
+	if e.ReportID != "" {
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{
+			"success": true,
+			"data": map[string]interface{}{
+				"report_id":      e.ReportID,
+				"message":        "Assignment is too large for synchronous analysis, analysis started asynchronously",
+				"status_url":     "/api/v1/analysis/" + workID,
+				"comparison_set": e.Size,
+				"limit":          e.Limit,
+			},
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusRequestEntityTooLarge, map[string]interface{}{
+		"error":          http.StatusText(http.StatusRequestEntityTooLarge),
+		"code":           CodeComparisonSetTooLarge,
+		"message":        e.Error() + ", use /api/v1/analysis/async",
+		"comparison_set": e.Size,
+		"limit":          e.Limit,
+		"async_url":      "/api/v1/analysis/async",
+	})
+}
+
 func (h *Handler) handleAnalysisError(w http.ResponseWriter, err error) {
-	errMsg := err.Error()
-
-	switch {
-	case errMsg == "analysis not found for this work":
-		writeError(w, http.StatusNotFound, errMsg)
-	case errMsg == "report not found for this work":
-		writeError(w, http.StatusNotFound, errMsg)
-	case errMsg == "batch size exceeds limit":
-		writeError(w, http.StatusBadRequest, errMsg)
-	case contains(errMsg, "failed to get file hash"):
-		h.logger.Error().Err(err).Msg("File service error")
-		writeError(w, http.StatusBadGateway, "File service unavailable")
-	case contains(errMsg, "failed to get previous works"):
-		h.logger.Error().Err(err).Msg("Work service error")
-		writeError(w, http.StatusBadGateway, "Work service unavailable")
-	case contains(errMsg, "plagiarism check failed"):
-		h.logger.Error().Err(err).Msg("Analysis processing error")
-		writeError(w, http.StatusInternalServerError, "Analysis failed")
-	default:
-		h.logger.Error().Err(err).Msg("Analysis error")
-		writeError(w, http.StatusInternalServerError, "Internal server error")
-	}
-}
-
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || contains(s[1:], substr)))
+	if errors.Is(err, service.ErrUrgentBusy) {
+		w.Header().Set("Retry-After", "5")
+	}
+	h.writeMappedError(w, err, analysisErrors, "Analysis error")
+}
+
+// checkUUIDs при analysis.validate_uuids проверяет пары (имя поля, значение) и отвечает 400
+// на первое значение не в формате UUID; возвращает false, если ответ уже записан
+func (h *Handler) checkUUIDs(w http.ResponseWriter, fields ...string) bool {
+	if !h.config.ValidateUUIDs {
+		return true
+	}
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		if _, err := uuid.Parse(fields[i+1]); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid "+fields[i]+" format")
+			return false
+		}
+	}
+	return true
 }
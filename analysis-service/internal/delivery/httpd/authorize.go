@@ -0,0 +1,99 @@
+package httpd
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Роли вызывающего из заголовка X-User-Role, который проставляет аутентификация перед сервисом
+const (
+	RoleStudent = "student"
+	RoleTeacher = "teacher"
+	RoleAdmin   = "admin"
+	// Другие сервисы (work-service) читают отчёты от своего имени
+	RoleService = "service"
+)
+
+type caller struct {
+	UserID string
+	Role   string
+}
+
+func callerFromRequest(r *http.Request) caller {
+	return caller{
+		UserID: strings.TrimSpace(r.Header.Get("X-User-ID")),
+		Role:   strings.ToLower(strings.TrimSpace(r.Header.Get("X-User-Role"))),
+	}
+}
+
+// reportScope возвращает student_id, которым ограничены отчёты вызывающего ("" — все отчёты).
+// Запрос без роли или с неизвестной ролью не видит ничего.
+func (h *Handler) reportScope(r *http.Request) (string, bool) {
+	c := callerFromRequest(r)
+
+	switch c.Role {
+	case RoleTeacher, RoleAdmin, RoleService:
+		return "", true
+	case RoleStudent:
+		if c.UserID == "" {
+			return "", false
+		}
+		return c.UserID, true
+	default:
+		return "", false
+	}
+}
+
+// authorizeReport пишет 403 и возвращает false, если вызывающий не может читать отчёт студента
+func (h *Handler) authorizeReport(w http.ResponseWriter, r *http.Request, studentID string) bool {
+	scope, ok := h.reportScope(r)
+	if ok && (scope == "" || scope == studentID) {
+		return true
+	}
+
+	c := callerFromRequest(r)
+	h.logger.Warn().
+		Str("user_id", c.UserID).
+		Str("role", c.Role).
+		Str("student_id", studentID).
+		Msg("Report access denied")
+	writeError(w, http.StatusForbidden, "Access denied")
+	return false
+}
+
+// authorizeWork — authorizeReport для отчёта по работе. Студенту отчёт ищется, чтобы узнать
+// автора работы; работы без отчёта ему не видны (403), остальным проверка ничего не стоит
+func (h *Handler) authorizeWork(w http.ResponseWriter, r *http.Request, workIDs ...string) bool {
+	scope, ok := h.reportScope(r)
+	if ok && scope == "" {
+		return true
+	}
+
+	if ok {
+		for _, workID := range workIDs {
+			report, err := h.reportService.GetReportByWorkID(r.Context(), workID)
+			if err == nil && report.StudentID == scope {
+				return true
+			}
+		}
+	}
+
+	return h.authorizeReport(w, r, "")
+}
+
+// requireAdmin пропускает только вызывающих с ролью admin
+func (h *Handler) requireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := callerFromRequest(r)
+		if c.Role != RoleAdmin {
+			h.logger.Warn().
+				Str("user_id", c.UserID).
+				Str("role", c.Role).
+				Str("path", r.URL.Path).
+				Msg("Admin access denied")
+			writeError(w, http.StatusForbidden, "Access denied")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
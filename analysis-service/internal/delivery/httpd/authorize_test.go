@@ -0,0 +1,106 @@
+package httpd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+)
+
+const (
+	workOwn   = "11111111-1111-1111-1111-111111111111"
+	workOther = "22222222-2222-2222-2222-222222222222"
+)
+
+// fakeReportService отдаёт отчёты по работам из карты; остальные методы интерфейса не вызываются
+type fakeReportService struct {
+	service.ReportService
+	students map[string]string // work_id → student_id
+}
+
+func (f *fakeReportService) GetReportByWorkID(_ context.Context, workID string) (*models.GetReportResponse, error) {
+	studentID, ok := f.students[workID]
+	if !ok {
+		return nil, service.ErrReportIDNotFound
+	}
+	return &models.GetReportResponse{WorkID: workID, StudentID: studentID}, nil
+}
+
+func (f *fakeReportService) GetStudentStats(_ context.Context, studentID string) (*models.GetStudentStatsResponse, error) {
+	return &models.GetStudentStatsResponse{}, nil
+}
+
+type fakeAnalysisService struct {
+	service.AnalysisService
+}
+
+func (f *fakeAnalysisService) GetAnalysisResult(_ context.Context, workID string) (*models.AnalysisResult, error) {
+	return &models.AnalysisResult{}, nil
+}
+
+func (f *fakeAnalysisService) GetComparisonPair(_ context.Context, workA, workB string) (*models.ComparisonPairResponse, error) {
+	return &models.ComparisonPairResponse{}, nil
+}
+
+func newAuthTestRouter() http.Handler {
+	h := NewHandler(
+		&fakeAnalysisService{},
+		&fakeReportService{students: map[string]string{workOwn: "alice", workOther: "bob"}},
+		nil, nil, nil, nil, nil, nil,
+		zerolog.Nop(),
+		HandlerConfig{ValidateUUIDs: true},
+	)
+	router := chi.NewRouter()
+	h.RegisterRoutes(router)
+	return router
+}
+
+func TestReportRoutesScopedToStudent(t *testing.T) {
+	router := newAuthTestRouter()
+
+	tests := []struct {
+		name   string
+		path   string
+		role   string
+		userID string
+		want   int
+	}{
+		{"own stats", "/api/v1/reports/student/alice", RoleStudent, "alice", http.StatusOK},
+		{"other stats", "/api/v1/reports/student/bob", RoleStudent, "alice", http.StatusForbidden},
+		{"other portfolio", "/api/v1/students/bob/reports/portfolio.pdf", RoleStudent, "alice", http.StatusForbidden},
+		{"own analysis", "/api/v1/analysis/" + workOwn, RoleStudent, "alice", http.StatusOK},
+		{"other analysis", "/api/v1/analysis/" + workOther, RoleStudent, "alice", http.StatusForbidden},
+		{"pair with own work", "/api/v1/analysis/comparison?work_a=" + workOther + "&work_b=" + workOwn, RoleStudent, "alice", http.StatusOK},
+		{"pair of other works", "/api/v1/analysis/comparison?work_a=" + workOther + "&work_b=33333333-3333-3333-3333-333333333333", RoleStudent, "alice", http.StatusForbidden},
+		{"teacher sees other analysis", "/api/v1/analysis/" + workOther, RoleTeacher, "", http.StatusOK},
+		{"service sees stats", "/api/v1/reports/student/bob", RoleService, "", http.StatusOK},
+		{"no role", "/api/v1/reports/student/alice", "", "alice", http.StatusForbidden},
+		{"no role analysis", "/api/v1/analysis/" + workOwn, "", "", http.StatusForbidden},
+		{"unknown role", "/api/v1/reports/student/alice", "guest", "alice", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.role != "" {
+				req.Header.Set("X-User-Role", tt.role)
+			}
+			if tt.userID != "" {
+				req.Header.Set("X-User-ID", tt.userID)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d (body %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package httpd
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+)
+
+// Коды ошибок в поле code ответа. Ошибки без своего кода получают код по HTTP-статусу
+// (NOT_FOUND, BAD_REQUEST, INTERNAL_SERVER_ERROR), см. statusCode.
+const (
+	CodeAnalysisNotFound          = "ANALYSIS_NOT_FOUND"
+	CodeReportNotFound            = "REPORT_NOT_FOUND"
+	CodeComparisonNotFound        = "COMPARISON_NOT_FOUND"
+	CodeHashNotFound              = "HASH_NOT_FOUND"
+	CodeInvalidThreshold          = "INVALID_THRESHOLD"
+	CodeBatchTooLarge             = "BATCH_TOO_LARGE"
+	CodeWorkHasNoFile             = "WORK_HAS_NO_FILE"
+	CodeFileNotFound              = "FILE_NOT_FOUND"
+	CodeWarmupDisabled            = "WARMUP_DISABLED"
+	CodeHashIndexDisabled         = "HASH_INDEX_DISABLED"
+	CodeUrgentDisabled            = "URGENT_DISABLED"
+	CodeUrgentBusy                = "URGENT_BUSY"
+	CodePreviewDisabled           = "PREVIEW_DISABLED"
+	CodeFileTooLarge              = "FILE_TOO_LARGE"
+	CodeFileServiceUnavailable    = "FILE_SERVICE_UNAVAILABLE"
+	CodeWorkServiceUnavailable    = "WORK_SERVICE_UNAVAILABLE"
+	CodeAnalysisFailed            = "ANALYSIS_FAILED"
+	CodeComparisonSetTooLarge     = "COMPARISON_SET_TOO_LARGE"
+	CodeReportNotCompleted        = "REPORT_NOT_COMPLETED"
+	CodeExportJobNotFound         = "EXPORT_JOB_NOT_FOUND"
+	CodeAssignmentReportsNotFound = "ASSIGNMENT_REPORTS_NOT_FOUND"
+	CodeStudentReportsNotFound    = "STUDENT_REPORTS_NOT_FOUND"
+	CodeNoFlaggedReports          = "NO_FLAGGED_REPORTS"
+	CodeInvalidCursor             = "INVALID_CURSOR"
+	CodePDFRequiresSingleReport   = "PDF_REQUIRES_SINGLE_REPORT"
+	CodeReportSearchFailed        = "REPORT_SEARCH_FAILED"
+	CodeInvalidWebhookURL         = "INVALID_WEBHOOK_URL"
+	CodeInvalidAssignmentID       = "INVALID_ASSIGNMENT_ID"
+	CodeWebhookNotFound           = "WEBHOOK_NOT_FOUND"
+	CodeInvalidRecipient          = "INVALID_RECIPIENT"
+	CodeNoRecipients              = "NO_RECIPIENTS"
+	CodeRecipientNotFound         = "RECIPIENT_NOT_FOUND"
+	CodeReferenceCorpusDisabled   = "REFERENCE_CORPUS_DISABLED"
+	CodeNoReferenceDocuments      = "NO_REFERENCE_DOCUMENTS"
+	CodeTooManyReferenceDocuments = "TOO_MANY_REFERENCE_DOCUMENTS"
+	CodeInternal                  = "INTERNAL_ERROR"
+)
+
+// errorMapping — ответ на ошибку сервиса, распознанную через errors.Is
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+	// Сообщение вместо текста ошибки, чтобы не отдавать клиенту подробности сбоя ("" — текст ошибки)
+	message string
+}
+
+// Порядок важен: ошибка соседнего сервиса бывает обёрнута в ErrAnalysisFailed и должна
+// распознаваться раньше неё
+var analysisErrors = []errorMapping{
+	{err: service.ErrAnalysisNotFound, status: http.StatusNotFound, code: CodeAnalysisNotFound},
+	{err: service.ErrReportNotFound, status: http.StatusNotFound, code: CodeReportNotFound},
+	{err: service.ErrComparisonNotFound, status: http.StatusNotFound, code: CodeComparisonNotFound},
+	{err: service.ErrHashNotFound, status: http.StatusNotFound, code: CodeHashNotFound},
+	{err: service.ErrInvalidThreshold, status: http.StatusBadRequest, code: CodeInvalidThreshold},
+	{err: service.ErrBatchTooLarge, status: http.StatusBadRequest, code: CodeBatchTooLarge},
+	{err: service.ErrWorkHasNoFile, status: http.StatusUnprocessableEntity, code: CodeWorkHasNoFile},
+	{err: service.ErrWarmupDisabled, status: http.StatusConflict, code: CodeWarmupDisabled},
+	{err: service.ErrHashIndexDisabled, status: http.StatusConflict, code: CodeHashIndexDisabled},
+	{err: service.ErrUrgentDisabled, status: http.StatusConflict, code: CodeUrgentDisabled},
+	{err: service.ErrUrgentBusy, status: http.StatusServiceUnavailable, code: CodeUrgentBusy},
+	{err: service.ErrPreviewDisabled, status: http.StatusConflict, code: CodePreviewDisabled},
+	{err: service.ErrFileNotFound, status: http.StatusUnprocessableEntity, code: CodeFileNotFound},
+	{err: service.ErrFileServiceUnavailable, status: http.StatusBadGateway, code: CodeFileServiceUnavailable, message: "File service unavailable"},
+	{err: service.ErrWorkServiceUnavailable, status: http.StatusBadGateway, code: CodeWorkServiceUnavailable, message: "Work service unavailable"},
+	{err: service.ErrAnalysisFailed, status: http.StatusInternalServerError, code: CodeAnalysisFailed, message: "Analysis failed"},
+}
+
+var reportErrors = []errorMapping{
+	{err: service.ErrReportIDNotFound, status: http.StatusNotFound, code: CodeReportNotFound},
+	{err: service.ErrReportNotFound, status: http.StatusNotFound, code: CodeReportNotFound},
+	{err: service.ErrExportJobNotFound, status: http.StatusNotFound, code: CodeExportJobNotFound},
+	{err: service.ErrReportNotCompleted, status: http.StatusConflict, code: CodeReportNotCompleted},
+	{err: service.ErrAssignmentReportsNotFound, status: http.StatusNotFound, code: CodeAssignmentReportsNotFound},
+	{err: service.ErrStudentReportsNotFound, status: http.StatusNotFound, code: CodeStudentReportsNotFound},
+	{err: service.ErrNoFlaggedReports, status: http.StatusNotFound, code: CodeNoFlaggedReports},
+	{err: service.ErrInvalidCursor, status: http.StatusBadRequest, code: CodeInvalidCursor},
+	{err: service.ErrPDFRequiresSingleReport, status: http.StatusBadRequest, code: CodePDFRequiresSingleReport},
+	{err: service.ErrReportSearchFailed, status: http.StatusInternalServerError, code: CodeReportSearchFailed, message: "Failed to search reports"},
+}
+
+var webhookErrors = []errorMapping{
+	{err: service.ErrInvalidWebhookURL, status: http.StatusBadRequest, code: CodeInvalidWebhookURL, message: "URL must be an http(s) URL"},
+	{err: service.ErrInvalidAssignmentID, status: http.StatusBadRequest, code: CodeInvalidAssignmentID, message: "Invalid assignment_id format"},
+	{err: service.ErrWebhookNotFound, status: http.StatusNotFound, code: CodeWebhookNotFound},
+}
+
+var notificationErrors = []errorMapping{
+	{err: service.ErrInvalidRecipient, status: http.StatusBadRequest, code: CodeInvalidRecipient, message: "Recipient must be an email address or an http(s) webhook URL"},
+	{err: service.ErrNoRecipients, status: http.StatusUnprocessableEntity, code: CodeNoRecipients},
+	{err: service.ErrRecipientNotFound, status: http.StatusNotFound, code: CodeRecipientNotFound},
+}
+
+var referenceErrors = []errorMapping{
+	{err: service.ErrReferenceCorpusDisabled, status: http.StatusConflict, code: CodeReferenceCorpusDisabled},
+	{err: service.ErrNoReferenceDocuments, status: http.StatusBadRequest, code: CodeNoReferenceDocuments},
+	{err: service.ErrTooManyReferenceDocuments, status: http.StatusBadRequest, code: CodeTooManyReferenceDocuments},
+}
+
+// writeMappedError отвечает по первой записи mappings, которой соответствует err;
+// нераспознанная ошибка — 500 INTERNAL_ERROR без подробностей
+func (h *Handler) writeMappedError(w http.ResponseWriter, err error, mappings []errorMapping, logMessage string) {
+	for _, m := range mappings {
+		if !errors.Is(err, m.err) {
+			continue
+		}
+
+		message := m.message
+		if message == "" {
+			message = err.Error()
+		}
+		if m.status >= http.StatusInternalServerError {
+			h.logger.Error().Err(err).Str("code", m.code).Msg(logMessage)
+		}
+		writeErrorCode(w, m.status, m.code, message)
+		return
+	}
+
+	h.logger.Error().Err(err).Msg(logMessage)
+	writeErrorCode(w, http.StatusInternalServerError, CodeInternal, "Internal server error")
+}
+
+// statusCode — код ошибки по HTTP-статусу: 404 → NOT_FOUND
+func statusCode(status int) string {
+	return strings.ToUpper(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+}
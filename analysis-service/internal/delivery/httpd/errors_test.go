@@ -0,0 +1,64 @@
+package httpd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+)
+
+func TestServiceErrorsMapToStatusAndCode(t *testing.T) {
+	h := &Handler{logger: zerolog.Nop()}
+
+	tests := []struct {
+		name   string
+		handle func(http.ResponseWriter, error)
+		err    error
+		status int
+		code   string
+	}{
+		{"webhook url", h.handleWebhookError, service.ErrInvalidWebhookURL, http.StatusBadRequest, CodeInvalidWebhookURL},
+		{"webhook assignment", h.handleWebhookError, service.ErrInvalidAssignmentID, http.StatusBadRequest, CodeInvalidAssignmentID},
+		{"webhook not found", h.handleWebhookError, service.ErrWebhookNotFound, http.StatusNotFound, CodeWebhookNotFound},
+		{"webhook unknown", h.handleWebhookError, errors.New("webhook not found"), http.StatusInternalServerError, CodeInternal},
+		{"recipient invalid", h.handleNotificationError, service.ErrInvalidRecipient, http.StatusBadRequest, CodeInvalidRecipient},
+		{"no recipients", h.handleNotificationError, service.ErrNoRecipients, http.StatusUnprocessableEntity, CodeNoRecipients},
+		{"recipient not found", h.handleNotificationError, service.ErrRecipientNotFound, http.StatusNotFound, CodeRecipientNotFound},
+		{"corpus disabled", h.handleReferenceError, service.ErrReferenceCorpusDisabled, http.StatusConflict, CodeReferenceCorpusDisabled},
+		{"no documents", h.handleReferenceError, service.ErrNoReferenceDocuments, http.StatusBadRequest, CodeNoReferenceDocuments},
+		{"too many documents", h.handleReferenceError, service.ErrTooManyReferenceDocuments, http.StatusBadRequest, CodeTooManyReferenceDocuments},
+		{"wrapped sentinel", h.handleReferenceError, fmt.Errorf("import: %w", service.ErrReferenceCorpusDisabled), http.StatusConflict, CodeReferenceCorpusDisabled},
+		{"reference failure", h.handleReferenceError, errors.New("failed to list references: connection refused"), http.StatusInternalServerError, CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handle(rec, tt.err)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			var body struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body.Code != tt.code {
+				t.Fatalf("code = %q, want %q", body.Code, tt.code)
+			}
+			// Подробности внутренних сбоев клиенту не отдаются
+			if tt.status == http.StatusInternalServerError && body.Message != "Internal server error" {
+				t.Fatalf("message = %q leaks the error", body.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package httpd
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/websocket"
+)
+
+// StreamEvents отдаёт события анализа по WebSocket. Фильтры: assignment_id, student_id
+// и types — список через запятую из analysis.started, analysis.completed, analysis.failed.
+func (h *Handler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if h.eventHub == nil {
+		writeError(w, http.StatusNotFound, "Event streaming is disabled")
+		return
+	}
+	if !websocket.IsUpgradeRequest(r) {
+		writeError(w, http.StatusBadRequest, "WebSocket upgrade required")
+		return
+	}
+
+	filter := service.EventFilter{
+		AssignmentID: r.URL.Query().Get("assignment_id"),
+		StudentID:    r.URL.Query().Get("student_id"),
+	}
+	if types := r.URL.Query().Get("types"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			t = strings.TrimSpace(t)
+			if !slices.Contains(service.StreamEventTypes, t) {
+				writeError(w, http.StatusBadRequest, "Invalid event type: "+t)
+				return
+			}
+			filter.Types = append(filter.Types, t)
+		}
+	}
+
+	sub, err := h.eventHub.Subscribe(filter)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManySubscribers) || errors.Is(err, service.ErrEventHubClosed) {
+			writeError(w, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to subscribe to events")
+		return
+	}
+	defer h.eventHub.Unsubscribe(sub)
+
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		h.logger.Warn().Err(err).Msg("WebSocket upgrade failed")
+		return
+	}
+
+	pingInterval := h.config.StreamPingInterval
+	if pingInterval <= 0 {
+		pingInterval = 30 * time.Second
+	}
+	writeTimeout := h.config.StreamWriteTimeout
+	if writeTimeout <= 0 {
+		writeTimeout = 10 * time.Second
+	}
+
+	// Клиент отвечает pong на каждый ping, поэтому тишина дольше двух интервалов — обрыв связи
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		_ = conn.ReadLoop(2 * pingInterval)
+	}()
+
+	h.logger.Info().
+		Str("assignment_id", filter.AssignmentID).
+		Str("student_id", filter.StudentID).
+		Msg("Event stream client connected")
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	closeCode, closeReason := websocket.CloseNormal, ""
+loop:
+	for {
+		select {
+		case message := <-sub.Events():
+			if err := conn.WriteText(message, writeTimeout); err != nil {
+				break loop
+			}
+		case <-ticker.C:
+			if err := conn.WritePing(writeTimeout); err != nil {
+				break loop
+			}
+		case <-sub.Done():
+			closeCode, closeReason = websocket.CloseGoingAway, sub.Reason()
+			if closeReason == "slow consumer" {
+				closeCode = websocket.ClosePolicy
+			}
+			break loop
+		case <-readerDone:
+			break loop
+		}
+	}
+
+	_ = conn.Close(closeCode, closeReason)
+	<-readerDone
+
+	h.logger.Info().
+		Str("assignment_id", filter.AssignmentID).
+		Str("student_id", filter.StudentID).
+		Int64("dropped_events", sub.Dropped()).
+		Str("reason", closeReason).
+		Msg("Event stream client disconnected")
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/validation"
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
 )
@@ -14,25 +16,72 @@ type Handler struct {
 	analysisService service.AnalysisService
 	reportService   service.ReportService
 	wordCloudService service.WordCloudService
+	notificationService service.NotificationService
+	overrideService service.OverrideService
+	webhookService  service.WebhookService
+	referenceService service.ReferenceCorpusService
+	eventHub        service.EventHub
+	exportLimiter   *rateLimiter
+	urgentLimiter   *rateLimiter
+	previewLimiter  *rateLimiter
 	logger          zerolog.Logger
+	config          HandlerConfig
+}
+
+type HandlerConfig struct {
+	ExportRateLimit  int
+	ExportRateWindow time.Duration
+	// Интервал ping и таймаут записи для WebSocket-потока событий
+	StreamPingInterval time.Duration
+	StreamWriteTimeout time.Duration
+	// Размер страницы совпадений в отчёте по работе: по умолчанию и максимальный
+	MatchesDefaultLimit int
+	MatchesMaxLimit     int
+	// Проверять, что идентификаторы в запросах анализа — UUID
+	ValidateUUIDs bool
+	// Срочных анализов на пользователя за окно
+	UrgentRateLimit  int
+	UrgentRateWindow time.Duration
+	// Пробных проверок на пользователя за окно и максимальный размер загруженного для них файла
+	PreviewRateLimit   int
+	PreviewRateWindow  time.Duration
+	PreviewMaxFileSize int64
+	// Отдавать при ошибке валидации список {field, rule, message}, а не одно сообщение
+	DetailedValidationErrors bool
 }
 
 func NewHandler(
 	analysisService service.AnalysisService,
 	reportService service.ReportService,
 	wordCloudService service.WordCloudService,
+	notificationService service.NotificationService,
+	overrideService service.OverrideService,
+	webhookService service.WebhookService,
+	referenceService service.ReferenceCorpusService,
+	eventHub service.EventHub,
 	logger zerolog.Logger,
+	config HandlerConfig,
 ) *Handler {
 	return &Handler{
 		analysisService: analysisService,
 		reportService:   reportService,
 		wordCloudService: wordCloudService,
+		notificationService: notificationService,
+		overrideService: overrideService,
+		webhookService:  webhookService,
+		referenceService: referenceService,
+		eventHub:        eventHub,
+		exportLimiter:   newRateLimiter(config.ExportRateLimit, config.ExportRateWindow),
+		urgentLimiter:   newRateLimiter(config.UrgentRateLimit, config.UrgentRateWindow),
+		previewLimiter:  newRateLimiter(config.PreviewRateLimit, config.PreviewRateWindow),
 		logger:          logger,
+		config:          config,
 	}
 }
 
 func (h *Handler) RegisterRoutes(router chi.Router) {
 	router.Get("/health", h.HealthCheck)
+	router.Get("/ready", h.ReadyCheck)
 	router.Get("/status", h.GetServiceStatus)
 	router.Get("/stats", h.GetAllStats)
 
@@ -41,25 +90,90 @@ func (h *Handler) RegisterRoutes(router chi.Router) {
 			r.Post("/", h.AnalyzeWork)
 			r.Post("/batch", h.BatchAnalyze)
 			r.Post("/async", h.AnalyzeWorkAsync)
+			r.With(h.urgentLimiter.Middleware).Post("/urgent", h.AnalyzeWorkUrgent)
+			r.With(h.previewLimiter.Middleware).Post("/preview", h.PreviewAnalysis)
+			r.Get("/comparison", h.GetComparisonPair)
+			r.Get("/by-hash/{hash}", h.GetWorksByHash)
+			r.Get("/version", h.GetAnalysisVersion)
+			r.Get("/reference-corpus", h.ListReferences)
+			r.Post("/reference-corpus/bulk", h.BulkImportReferences)
 			r.Get("/{work_id}", h.GetAnalysisResult)
+			r.Get("/{work_id}/coverage", h.GetWorkCoverage)
 			r.Post("/retry", h.RetryFailedAnalyses)
 		})
 
 		api.Route("/reports", func(r chi.Router) {
 			r.Get("/", h.SearchReports)
 			r.Get("/{report_id}", h.GetReport)
+			r.Get("/{report_id}/pdf", h.GetReportPDF)
+			r.Post("/{report_id}/override", h.OverrideVerdict)
 			r.Get("/work/{work_id}", h.GetReportByWorkID)
+			r.Get("/work/{work_id}/percentile", h.GetWorkPercentile)
+			r.Post("/work/{work_id}/supersede", h.SupersedeReport)
 			r.Get("/assignment/{assignment_id}", h.GetAssignmentStats)
+			r.With(h.exportLimiter.Middleware).Get("/assignment/{assignment_id}/roster", h.ExportAssignmentRoster)
 			r.Get("/student/{student_id}", h.GetStudentStats)
-			r.Get("/export", h.ExportReports)
+			r.Delete("/student/{student_id}", h.DeleteStudentReports)
+			r.With(h.exportLimiter.Middleware).Get("/export", h.ExportReports)
+			r.Get("/export/jobs/{job_id}", h.GetExportJob)
+			r.Get("/export/jobs/{job_id}/download", h.DownloadExport)
 		})
 
 		api.Route("/wordcloud", func(r chi.Router) {
 			r.Get("/work/{work_id}", h.GetWordCloudPNG)
 		})
+
+		api.Get("/students/{student_id}/reports/portfolio.pdf", h.GetStudentPortfolio)
+
+		api.Get("/events/ws", h.StreamEvents)
+
+		api.Get("/assignments/{assignment_id}/override-stats", h.GetOverrideStats)
+		api.Put("/assignments/{assignment_id}/threshold", h.SetAssignmentThreshold)
+		api.Post("/assignments/{assignment_id}/warmup", h.WarmupAssignment)
+
+		api.Route("/assignments/{assignment_id}/notification-recipients", func(r chi.Router) {
+			r.Get("/", h.GetNotificationRecipients)
+			r.Post("/", h.AddNotificationRecipient)
+			r.Delete("/{recipient_id}", h.DeleteNotificationRecipient)
+		})
+
+		api.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", h.ListWebhooks)
+			r.Post("/", h.CreateWebhook)
+			r.Delete("/{webhook_id}", h.DeleteWebhook)
+		})
+
+		api.Route("/admin", func(r chi.Router) {
+			r.Get("/throughput", h.GetThroughput)
+			r.Post("/notifications/test", h.SendTestNotification)
+			r.Post("/assignments/{assignment_id}/refresh-stats", h.RefreshAssignmentStats)
+			r.Post("/reanalyze-outdated", h.ReanalyzeOutdated)
+			r.Post("/hash-index/rebuild", h.RebuildHashIndex)
+			r.With(h.requireAdmin).Get("/reports/{report_id}/raw-details", h.GetReportRawDetails)
+		})
 	})
 }
 
+// validateRequest проверяет DTO по тегам validate; при нарушениях отвечает 400 и возвращает false
+func (h *Handler) validateRequest(w http.ResponseWriter, req interface{}) bool {
+	errs := validation.Struct(req)
+	if errs == nil {
+		return true
+	}
+
+	if !h.config.DetailedValidationErrors {
+		writeError(w, http.StatusBadRequest, errs[0].Message)
+		return false
+	}
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error":   http.StatusText(http.StatusBadRequest),
+		"code":    statusCode(http.StatusBadRequest),
+		"message": errs[0].Message,
+		"errors":  errs,
+	})
+	return false
+}
+
 func getIntQueryParam(r *http.Request, key string, defaultValue int) int {
 	value := r.URL.Query().Get(key)
 	if value == "" {
@@ -111,9 +225,16 @@ func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	}
 }
 
+// writeError отвечает ошибкой с кодом по HTTP-статусу
 func writeError(w http.ResponseWriter, status int, message string) {
+	writeErrorCode(w, status, statusCode(status), message)
+}
+
+// writeErrorCode — ответ на ошибку: {error, code, message}; code — машинный код для клиента
+func writeErrorCode(w http.ResponseWriter, status int, code, message string) {
 	writeJSON(w, status, map[string]interface{}{
 		"error":   http.StatusText(status),
+		"code":    code,
 		"message": message,
 	})
 }
@@ -3,6 +3,8 @@ package httpd
 import (
 	"net/http"
 	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/buildinfo"
 )
 
 func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
@@ -10,12 +12,44 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		"status":    "healthy",
 		"service":   "analysis-service",
 		"timestamp": time.Now().UTC(),
-		"version":   "1.0.0",
+		"version":   buildinfo.Version,
 	}
 
 	writeJSON(w, http.StatusOK, response)
 }
 
+// ReadyCheck отвечает 503, пока хотя бы одна зависимость недоступна: под не должен получать трафик,
+// который не сможет обработать
+func (h *Handler) ReadyCheck(w http.ResponseWriter, r *http.Request) {
+	status, err := h.analysisService.GetServiceStatus(r.Context())
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to get service status")
+		writeError(w, http.StatusServiceUnavailable, "Failed to check dependencies")
+		return
+	}
+
+	code := http.StatusOK
+	ready := "ready"
+	if status.Status != "healthy" {
+		code = http.StatusServiceUnavailable
+		ready = "not_ready"
+	}
+
+	response := map[string]interface{}{
+		"status": ready,
+		"health": status.Status,
+		"dependencies": map[string]bool{
+			"database":     status.Database,
+			"rabbitmq":     status.RabbitMQ,
+			"work_service": status.WorkService,
+			"file_service": status.FileService,
+		},
+		"timestamp": time.Now().UTC(),
+	}
+
+	writeJSON(w, code, response)
+}
+
 func (h *Handler) GetServiceStatus(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	status, err := h.analysisService.GetServiceStatus(ctx)
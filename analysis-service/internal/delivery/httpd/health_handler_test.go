@@ -0,0 +1,63 @@
+package httpd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+)
+
+// statusService отдаёт заранее заданное состояние зависимостей
+type statusService struct {
+	service.AnalysisService
+	status *models.HealthCheckResponse
+}
+
+func (s *statusService) GetServiceStatus(context.Context) (*models.HealthCheckResponse, error) {
+	return s.status, nil
+}
+
+func TestReadyCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		status models.HealthCheckResponse
+		code   int
+		ready  string
+	}{
+		{"healthy", models.HealthCheckResponse{Status: "healthy", Database: true, RabbitMQ: true, WorkService: true, FileService: true}, http.StatusOK, "ready"},
+		{"file service down", models.HealthCheckResponse{Status: "degraded", Database: true, RabbitMQ: true, WorkService: true}, http.StatusServiceUnavailable, "not_ready"},
+		{"database down", models.HealthCheckResponse{Status: "unhealthy", RabbitMQ: true, WorkService: true, FileService: true}, http.StatusServiceUnavailable, "not_ready"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{analysisService: &statusService{status: &tt.status}, logger: zerolog.Nop()}
+			rec := httptest.NewRecorder()
+			h.ReadyCheck(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+			if rec.Code != tt.code {
+				t.Fatalf("status code = %d, want %d", rec.Code, tt.code)
+			}
+			var body struct {
+				Status       string          `json:"status"`
+				Health       string          `json:"health"`
+				Dependencies map[string]bool `json:"dependencies"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if body.Status != tt.ready || body.Health != tt.status.Status {
+				t.Fatalf("body = %+v, want status %s and health %s", body, tt.ready, tt.status.Status)
+			}
+			if body.Dependencies["file_service"] != tt.status.FileService || body.Dependencies["database"] != tt.status.Database {
+				t.Fatalf("dependencies = %v", body.Dependencies)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package httpd
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) GetNotificationRecipients(w http.ResponseWriter, r *http.Request) {
+	assignmentID := chi.URLParam(r, "assignment_id")
+	if assignmentID == "" {
+		writeError(w, http.StatusBadRequest, "Assignment ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	recipients, err := h.notificationService.GetRecipients(ctx, assignmentID)
+	if err != nil {
+		h.handleNotificationError(w, err)
+		return
+	}
+
+	writeSuccess(w, recipients)
+}
+
+func (h *Handler) AddNotificationRecipient(w http.ResponseWriter, r *http.Request) {
+	assignmentID := chi.URLParam(r, "assignment_id")
+	if assignmentID == "" {
+		writeError(w, http.StatusBadRequest, "Assignment ID is required")
+		return
+	}
+
+	var req models.AddNotificationRecipientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Recipient == "" {
+		writeError(w, http.StatusBadRequest, "Recipient is required")
+		return
+	}
+
+	ctx := r.Context()
+	recipient, err := h.notificationService.AddRecipient(ctx, assignmentID, req.Recipient)
+	if err != nil {
+		h.handleNotificationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    recipient,
+	})
+}
+
+func (h *Handler) DeleteNotificationRecipient(w http.ResponseWriter, r *http.Request) {
+	assignmentID := chi.URLParam(r, "assignment_id")
+	recipientID := chi.URLParam(r, "recipient_id")
+	if assignmentID == "" || recipientID == "" {
+		writeError(w, http.StatusBadRequest, "Assignment ID and recipient ID are required")
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.notificationService.RemoveRecipient(ctx, assignmentID, recipientID); err != nil {
+		h.handleNotificationError(w, err)
+		return
+	}
+
+	writeSuccess(w, map[string]string{
+		"message": "Recipient removed",
+	})
+}
+
+func (h *Handler) SendTestNotification(w http.ResponseWriter, r *http.Request) {
+	var req models.TestNotificationRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	ctx := r.Context()
+	response, err := h.notificationService.SendTestNotification(ctx, req.AssignmentID)
+	if err != nil {
+		h.handleNotificationError(w, err)
+		return
+	}
+
+	writeSuccess(w, response)
+}
+
+func (h *Handler) handleNotificationError(w http.ResponseWriter, err error) {
+	h.writeMappedError(w, err, notificationErrors, "Notification service error")
+}
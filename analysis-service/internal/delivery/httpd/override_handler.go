@@ -0,0 +1,54 @@
+package httpd
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) OverrideVerdict(w http.ResponseWriter, r *http.Request) {
+	reportID := chi.URLParam(r, "report_id")
+	if reportID == "" {
+		writeError(w, http.StatusBadRequest, "Report ID is required")
+		return
+	}
+
+	var req models.OverrideVerdictRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.PlagiarismFlag == nil {
+		writeError(w, http.StatusBadRequest, "plagiarism_flag is required")
+		return
+	}
+
+	ctx := r.Context()
+	override, err := h.overrideService.OverrideVerdict(ctx, reportID, *req.PlagiarismFlag, req.Reason, r.Header.Get("X-User-ID"))
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	writeSuccess(w, override)
+}
+
+func (h *Handler) GetOverrideStats(w http.ResponseWriter, r *http.Request) {
+	assignmentID := chi.URLParam(r, "assignment_id")
+	if assignmentID == "" {
+		writeError(w, http.StatusBadRequest, "Assignment ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	stats, err := h.overrideService.GetAssignmentOverrideStats(ctx, assignmentID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	writeSuccess(w, stats)
+}
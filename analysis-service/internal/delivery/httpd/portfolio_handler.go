@@ -0,0 +1,81 @@
+package httpd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// pdfStreamWriter выставляет заголовки PDF только при первой записи,
+// чтобы ошибку до начала генерации можно было вернуть обычным JSON.
+type pdfStreamWriter struct {
+	w        http.ResponseWriter
+	filename string
+	started  bool
+}
+
+func (p *pdfStreamWriter) Write(b []byte) (int, error) {
+	if !p.started {
+		p.started = true
+		p.w.Header().Set("Content-Type", "application/pdf")
+		p.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", p.filename))
+		p.w.WriteHeader(http.StatusOK)
+	}
+	return p.w.Write(b)
+}
+
+func (h *Handler) GetStudentPortfolio(w http.ResponseWriter, r *http.Request) {
+	studentID := chi.URLParam(r, "student_id")
+	if studentID == "" {
+		writeError(w, http.StatusBadRequest, "Student ID is required")
+		return
+	}
+
+	if !h.authorizeReport(w, r, studentID) {
+		return
+	}
+
+	opts := service.PortfolioOptions{
+		AssignmentID: r.URL.Query().Get("assignment_id"),
+	}
+	if from := r.URL.Query().Get("from"); from != "" {
+		t, err := parseTimeParam(from)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid 'from' parameter. Use RFC3339 or YYYY-MM-DD")
+			return
+		}
+		opts.From = &t
+	}
+	if to := r.URL.Query().Get("to"); to != "" {
+		t, err := parseTimeParam(to)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid 'to' parameter. Use RFC3339 or YYYY-MM-DD")
+			return
+		}
+		// Дата без времени включает весь день
+		if len(to) == len("2006-01-02") {
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		opts.To = &t
+	}
+	if opts.From != nil && opts.To != nil && opts.From.After(*opts.To) {
+		writeError(w, http.StatusBadRequest, "'from' must be before 'to'")
+		return
+	}
+
+	out := &pdfStreamWriter{w: w, filename: fmt.Sprintf("portfolio_%s.pdf", studentID)}
+	err := h.reportService.WriteStudentPortfolio(r.Context(), studentID, opts, out)
+	if err == nil {
+		return
+	}
+
+	if !out.started {
+		h.handleReportError(w, err)
+		return
+	}
+	// Ответ уже частично отправлен — остаётся только залогировать обрыв
+	h.logger.Error().Err(err).Str("student_id", studentID).Msg("Portfolio PDF streaming failed")
+}
@@ -0,0 +1,105 @@
+package httpd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/analyzer"
+)
+
+// Память под поля multipart-формы; файл сверх неё ParseMultipartForm пишет во временный файл
+const previewFormMemory = 1 << 20
+
+// PreviewAnalysis сравнивает файл с работами задания, не создавая работу и отчёт.
+// multipart/form-data: поля file, assignment_id и student_id; JSON — PreviewAnalysisRequest с file_id
+func (h *Handler) PreviewAnalysis(w http.ResponseWriter, r *http.Request) {
+	var file analyzer.PreviewFile
+	var assignmentID, studentID string
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		content, ok := h.readPreviewUpload(w, r)
+		if !ok {
+			return
+		}
+		file.Content = content
+		assignmentID = r.FormValue("assignment_id")
+		studentID = r.FormValue("student_id")
+		if assignmentID == "" {
+			writeError(w, http.StatusBadRequest, "assignment_id is required")
+			return
+		}
+	} else {
+		var req models.PreviewAnalysisRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		if !h.validateRequest(w, &req) {
+			return
+		}
+		if !h.checkUUIDs(w, "file_id", req.FileID) {
+			return
+		}
+		file.FileID = req.FileID
+		assignmentID = req.AssignmentID
+		studentID = req.StudentID
+	}
+
+	if !h.checkUUIDs(w, "assignment_id", assignmentID) {
+		return
+	}
+	if studentID != "" && !h.checkUUIDs(w, "student_id", studentID) {
+		return
+	}
+
+	result, err := h.analysisService.PreviewAnalysis(r.Context(), file, assignmentID, studentID)
+	if err != nil {
+		h.handleAnalysisError(w, err)
+		return
+	}
+
+	writeSuccess(w, result)
+}
+
+// readPreviewUpload читает поле file формы не больше PreviewMaxFileSize байт; при ошибке отвечает сам
+func (h *Handler) readPreviewUpload(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	maxSize := h.config.PreviewMaxFileSize
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize+previewFormMemory)
+	if err := r.ParseMultipartForm(previewFormMemory); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeErrorCode(w, http.StatusRequestEntityTooLarge, CodeFileTooLarge, fmt.Sprintf("File must not exceed %d bytes", maxSize))
+			return nil, false
+		}
+		writeError(w, http.StatusBadRequest, "Invalid multipart form")
+		return nil, false
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	upload, _, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "file is required")
+		return nil, false
+	}
+	defer upload.Close()
+
+	content, err := io.ReadAll(io.LimitReader(upload, maxSize+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to read file")
+		return nil, false
+	}
+	if int64(len(content)) > maxSize {
+		writeErrorCode(w, http.StatusRequestEntityTooLarge, CodeFileTooLarge, fmt.Sprintf("File must not exceed %d bytes", maxSize))
+		return nil, false
+	}
+	if len(content) == 0 {
+		writeError(w, http.StatusBadRequest, "file is empty")
+		return nil, false
+	}
+	return content, true
+}
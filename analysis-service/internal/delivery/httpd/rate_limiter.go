@@ -0,0 +1,85 @@
+package httpd
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimiter ограничивает число запросов на клиента в фиксированном окне.
+// Клиент определяется по X-User-ID, а при его отсутствии — по IP.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	clients map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		clients: make(map[string]*rateWindow),
+	}
+}
+
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for k, w := range l.clients {
+		if now.Sub(w.start) >= l.window {
+			delete(l.clients, k)
+		}
+	}
+
+	w, ok := l.clients[key]
+	if !ok {
+		l.clients[key] = &rateWindow{start: now, count: 1}
+		return true, 0
+	}
+
+	if w.count >= l.limit {
+		return false, l.window - now.Sub(w.start)
+	}
+
+	w.count++
+	return true, 0
+}
+
+func (l *rateLimiter) Middleware(next http.Handler) http.Handler {
+	if l.limit <= 0 || l.window <= 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allowed, retryAfter := l.allow(rateLimitKey(r))
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			writeError(w, http.StatusTooManyRequests, "Rate limit exceeded, try again later")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func rateLimitKey(r *http.Request) string {
+	if userID := r.Header.Get("X-User-ID"); userID != "" {
+		return "user:" + userID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
@@ -0,0 +1,61 @@
+package httpd
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+// maxReferencesPageSize — верхняя граница limit в списке документов базы сравнения
+const maxReferencesPageSize = 100
+
+// BulkImportReferences индексирует документы базы сравнения; ответ — результат по каждому документу
+func (h *Handler) BulkImportReferences(w http.ResponseWriter, r *http.Request) {
+	var req models.BulkImportReferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	result, err := h.referenceService.BulkImport(r.Context(), req)
+	if err != nil {
+		h.handleReferenceError(w, err)
+		return
+	}
+
+	writeSuccess(w, result)
+}
+
+// ListReferences — документы базы сравнения; ?source= и ?category= фильтруют по меткам
+func (h *Handler) ListReferences(w http.ResponseWriter, r *http.Request) {
+	page := getIntQueryParam(r, "page", 1)
+	limit := getIntQueryParam(r, "limit", 20)
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxReferencesPageSize {
+		limit = maxReferencesPageSize
+	}
+
+	query := r.URL.Query()
+	documents, total, err := h.referenceService.List(r.Context(), query.Get("source"), query.Get("category"), page, limit)
+	if err != nil {
+		h.handleReferenceError(w, err)
+		return
+	}
+
+	writeSuccess(w, map[string]interface{}{
+		"documents": documents,
+		"total":     total,
+		"page":      page,
+		"limit":     limit,
+	})
+}
+
+func (h *Handler) handleReferenceError(w http.ResponseWriter, err error) {
+	h.writeMappedError(w, err, referenceErrors, "Reference corpus error")
+}
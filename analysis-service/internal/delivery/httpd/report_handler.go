@@ -1,7 +1,7 @@
 package httpd
 
 import (
-	_ "encoding/json"
+	"encoding/json"
 	"net/http"
 
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
@@ -22,6 +22,10 @@ func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizeReport(w, r, report.StudentID) {
+		return
+	}
+
 	writeSuccess(w, report)
 }
 
@@ -39,10 +43,39 @@ func (h *Handler) GetReportByWorkID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizeReport(w, r, report.StudentID) {
+		return
+	}
+
+	// Без параметров пагинации ответ прежний: все совпадения в details.comparison_results
+	query := r.URL.Query()
+	if query.Has("matches_page") || query.Has("matches_limit") {
+		page := getIntQueryParam(r, "matches_page", 1)
+		limit := getIntQueryParam(r, "matches_limit", h.config.MatchesDefaultLimit)
+		if h.config.MatchesMaxLimit > 0 && limit > h.config.MatchesMaxLimit {
+			limit = h.config.MatchesMaxLimit
+		}
+
+		matches, err := h.reportService.GetComparisonMatches(ctx, workID, page, limit)
+		if err != nil {
+			h.handleReportError(w, err)
+			return
+		}
+
+		delete(report.Details, "comparison_results")
+		report.Matches = matches
+	}
+
 	writeSuccess(w, report)
 }
 
 func (h *Handler) SearchReports(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.reportScope(r)
+	if !ok {
+		writeError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
 	workID := r.URL.Query().Get("work_id")
 	assignmentID := r.URL.Query().Get("assignment_id")
 	studentID := r.URL.Query().Get("student_id")
@@ -50,19 +83,31 @@ func (h *Handler) SearchReports(w http.ResponseWriter, r *http.Request) {
 	plagiarismFlag := getBoolQueryParam(r, "plagiarism_flag")
 	dateFrom := r.URL.Query().Get("date_from")
 	dateTo := r.URL.Query().Get("date_to")
+	analysisVersion := r.URL.Query().Get("analysis_version")
 	page := getIntQueryParam(r, "page", 1)
 	limit := getIntQueryParam(r, "limit", 20)
 
 	req := models.SearchReportsRequest{
-		WorkID:         stringOrNil(workID),
-		AssignmentID:   stringOrNil(assignmentID),
-		StudentID:      stringOrNil(studentID),
-		Status:         stringOrNil(status),
-		PlagiarismFlag: plagiarismFlag,
-		DateFrom:       stringOrNil(dateFrom),
-		DateTo:         stringOrNil(dateTo),
-		Page:           page,
-		Limit:          limit,
+		WorkID:          stringOrNil(workID),
+		AssignmentID:    stringOrNil(assignmentID),
+		StudentID:       stringOrNil(studentID),
+		Status:          stringOrNil(status),
+		PlagiarismFlag:  plagiarismFlag,
+		DateFrom:        stringOrNil(dateFrom),
+		DateTo:          stringOrNil(dateTo),
+		AnalysisVersion: stringOrNil(analysisVersion),
+		Page:            page,
+		Limit:           limit,
+	}
+	if cursor, ok := r.URL.Query()["cursor"]; ok {
+		req.Cursor = &cursor[0]
+	}
+	// Студент видит только свои отчёты, какой бы student_id ни был в запросе
+	if scope != "" {
+		req.StudentID = &scope
+	}
+	if !h.validateRequest(w, &req) {
+		return
 	}
 
 	ctx := r.Context()
@@ -99,6 +144,10 @@ func (h *Handler) GetStudentStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.authorizeReport(w, r, studentID) {
+		return
+	}
+
 	ctx := r.Context()
 	stats, err := h.reportService.GetStudentStats(ctx, studentID)
 	if err != nil {
@@ -109,14 +158,68 @@ func (h *Handler) GetStudentStats(w http.ResponseWriter, r *http.Request) {
 	writeSuccess(w, stats)
 }
 
+func (h *Handler) DeleteStudentReports(w http.ResponseWriter, r *http.Request) {
+	studentID := chi.URLParam(r, "student_id")
+	if studentID == "" {
+		writeError(w, http.StatusBadRequest, "Student ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	deleted, err := h.reportService.DeleteStudentReports(ctx, studentID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	writeSuccess(w, map[string]interface{}{
+		"student_id": studentID,
+		"deleted":    deleted,
+	})
+}
+
+// SupersedeReport — вызывается work-service, когда новая попытка студента заменяет работу
+func (h *Handler) SupersedeReport(w http.ResponseWriter, r *http.Request) {
+	workID := chi.URLParam(r, "work_id")
+	if workID == "" {
+		writeError(w, http.StatusBadRequest, "Work ID is required")
+		return
+	}
+
+	var req models.SupersedeReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	if err := h.reportService.SupersedeReport(r.Context(), workID, req.SupersededBy); err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	writeSuccess(w, map[string]string{
+		"work_id":       workID,
+		"superseded_by": req.SupersededBy,
+	})
+}
+
 func (h *Handler) ExportReports(w http.ResponseWriter, r *http.Request) {
 	format := r.URL.Query().Get("format")
 	if format == "" {
 		format = "json"
 	}
 
-	if format != "json" && format != "csv" {
-		writeError(w, http.StatusBadRequest, "Unsupported format. Use 'json' or 'csv'")
+	if format != "json" && format != "csv" && format != "xlsx" && format != "pdf" {
+		writeError(w, http.StatusBadRequest, "Unsupported format. Use 'json', 'csv', 'xlsx' or 'pdf'")
+		return
+	}
+
+	scope, ok := h.reportScope(r)
+	if !ok {
+		writeError(w, http.StatusForbidden, "Access denied")
 		return
 	}
 
@@ -134,6 +237,10 @@ func (h *Handler) ExportReports(w http.ResponseWriter, r *http.Request) {
 		filters["student_id"] = studentID
 	}
 
+	if scope != "" {
+		filters["student_id"] = scope
+	}
+
 	if status := r.URL.Query().Get("status"); status != "" {
 		filters["status"] = status
 	}
@@ -142,7 +249,46 @@ func (h *Handler) ExportReports(w http.ResponseWriter, r *http.Request) {
 		filters["plagiarism_flag"] = *plagiarismFlag
 	}
 
+	// PDF строится по одному отчёту и всегда синхронно
+	if format == "pdf" {
+		if reportID := r.URL.Query().Get("report_id"); reportID != "" {
+			filters["report_id"] = reportID
+		}
+		if filters["report_id"] == nil && filters["work_id"] == nil {
+			writeError(w, http.StatusBadRequest, "PDF export requires 'report_id' or 'work_id'")
+			return
+		}
+	}
+
 	ctx := r.Context()
+
+	async := false
+	if asyncParam := getBoolQueryParam(r, "async"); asyncParam != nil {
+		async = *asyncParam
+	} else if format != "pdf" {
+		shouldAsync, err := h.reportService.ShouldExportAsync(ctx, filters)
+		if err != nil {
+			h.handleReportError(w, err)
+			return
+		}
+		async = shouldAsync
+	}
+
+	if async && format != "pdf" {
+		job, err := h.reportService.ExportReportsAsync(filters, format)
+		if err != nil {
+			h.handleReportError(w, err)
+			return
+		}
+
+		job.DownloadURL = exportDownloadURL(job.JobID)
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{
+			"success": true,
+			"data":    job,
+		})
+		return
+	}
+
 	data, err := h.reportService.ExportReports(ctx, filters, format)
 	if err != nil {
 		h.handleReportError(w, err)
@@ -155,23 +301,178 @@ func (h *Handler) ExportReports(w http.ResponseWriter, r *http.Request) {
 	w.Write(data)
 }
 
-func (h *Handler) handleReportError(w http.ResponseWriter, err error) {
-	errMsg := err.Error()
-
-	switch {
-	case errMsg == "report not found":
-		writeError(w, http.StatusNotFound, errMsg)
-	case errMsg == "assignment not found or no reports available":
-		writeError(w, http.StatusNotFound, errMsg)
-	case errMsg == "student not found or no reports available":
-		writeError(w, http.StatusNotFound, errMsg)
-	case contains(errMsg, "failed to search reports"):
-		h.logger.Error().Err(err).Msg("Database error")
-		writeError(w, http.StatusInternalServerError, "Failed to search reports")
-	default:
-		h.logger.Error().Err(err).Msg("Report service error")
-		writeError(w, http.StatusInternalServerError, "Internal server error")
+// ExportAssignmentRoster отдаёт ведомость задания для преподавателя; студентам недоступна
+func (h *Handler) ExportAssignmentRoster(w http.ResponseWriter, r *http.Request) {
+	assignmentID := chi.URLParam(r, "assignment_id")
+	if assignmentID == "" {
+		writeError(w, http.StatusBadRequest, "Assignment ID is required")
+		return
+	}
+
+	if !h.checkUUIDs(w, "assignment_id", assignmentID) {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "xlsx"
+	}
+	if format != "xlsx" {
+		writeError(w, http.StatusBadRequest, "Unsupported format. Use 'xlsx'")
+		return
+	}
+
+	if scope, ok := h.reportScope(r); !ok || scope != "" {
+		writeError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	data, err := h.reportService.ExportAssignmentRoster(r.Context(), assignmentID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", getContentType(format))
+	w.Header().Set("Content-Disposition", "attachment; filename=\"roster_"+assignmentID+"."+format+"\"")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func (h *Handler) GetWorkPercentile(w http.ResponseWriter, r *http.Request) {
+	workID := chi.URLParam(r, "work_id")
+	if workID == "" {
+		writeError(w, http.StatusBadRequest, "Work ID is required")
+		return
 	}
+
+	ctx := r.Context()
+	report, err := h.reportService.GetReportByWorkID(ctx, workID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	if !h.authorizeReport(w, r, report.StudentID) {
+		return
+	}
+
+	percentile, err := h.reportService.GetWorkPercentile(ctx, workID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	writeSuccess(w, percentile)
+}
+
+func (h *Handler) GetWorkCoverage(w http.ResponseWriter, r *http.Request) {
+	workID := chi.URLParam(r, "work_id")
+	if workID == "" {
+		writeError(w, http.StatusBadRequest, "Work ID is required")
+		return
+	}
+
+	if !h.checkUUIDs(w, "work_id", workID) {
+		return
+	}
+
+	ctx := r.Context()
+	report, err := h.reportService.GetReportByWorkID(ctx, workID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	if !h.authorizeReport(w, r, report.StudentID) {
+		return
+	}
+
+	coverage, err := h.reportService.GetWorkCoverage(ctx, workID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	writeSuccess(w, coverage)
+}
+
+func (h *Handler) GetReportPDF(w http.ResponseWriter, r *http.Request) {
+	reportID := chi.URLParam(r, "report_id")
+	if reportID == "" {
+		writeError(w, http.StatusBadRequest, "Report ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	report, err := h.reportService.GetReport(ctx, reportID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	if !h.authorizeReport(w, r, report.StudentID) {
+		return
+	}
+
+	data, err := h.reportService.ExportReports(ctx, map[string]interface{}{"report_id": reportID}, "pdf")
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", getContentType("pdf"))
+	w.Header().Set("Content-Disposition", "attachment; filename=\"report_"+report.WorkID+".pdf\"")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func (h *Handler) GetExportJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "job_id")
+
+	job, _, err := h.reportService.GetExportJob(jobID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	if job.Status == models.ExportJobStatusCompleted {
+		job.DownloadURL = exportDownloadURL(job.JobID)
+	}
+
+	writeSuccess(w, job)
+}
+
+func (h *Handler) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "job_id")
+
+	job, data, err := h.reportService.GetExportJob(jobID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	switch job.Status {
+	case models.ExportJobStatusFailed:
+		writeError(w, http.StatusInternalServerError, "Export failed: "+job.Error)
+		return
+	case models.ExportJobStatusPending:
+		writeError(w, http.StatusConflict, "Export is not ready yet")
+		return
+	}
+
+	w.Header().Set("Content-Type", getContentType(job.Format))
+	w.Header().Set("Content-Disposition", "attachment; filename=\"reports."+job.Format+"\"")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+func exportDownloadURL(jobID string) string {
+	return "/api/v1/reports/export/jobs/" + jobID + "/download"
+}
+
+func (h *Handler) handleReportError(w http.ResponseWriter, err error) {
+	h.writeMappedError(w, err, reportErrors, "Report service error")
 }
 
 func stringOrNil(s string) *string {
@@ -187,6 +488,10 @@ func getContentType(format string) string {
 		return "application/json"
 	case "csv":
 		return "text/csv"
+	case "xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case "pdf":
+		return "application/pdf"
 	default:
 		return "application/octet-stream"
 	}
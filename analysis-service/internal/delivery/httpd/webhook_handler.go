@@ -0,0 +1,67 @@
+package httpd
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.URL == "" {
+		writeError(w, http.StatusBadRequest, "URL is required")
+		return
+	}
+
+	ctx := r.Context()
+	subscription, err := h.webhookService.CreateSubscription(ctx, req)
+	if err != nil {
+		h.handleWebhookError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    subscription,
+	})
+}
+
+func (h *Handler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	subscriptions, err := h.webhookService.ListSubscriptions(ctx)
+	if err != nil {
+		h.handleWebhookError(w, err)
+		return
+	}
+
+	writeSuccess(w, subscriptions)
+}
+
+func (h *Handler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID := chi.URLParam(r, "webhook_id")
+	if webhookID == "" {
+		writeError(w, http.StatusBadRequest, "Webhook ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.webhookService.DeleteSubscription(ctx, webhookID); err != nil {
+		h.handleWebhookError(w, err)
+		return
+	}
+
+	writeSuccess(w, map[string]string{
+		"message": "Webhook removed",
+	})
+}
+
+func (h *Handler) handleWebhookError(w http.ResponseWriter, err error) {
+	h.writeMappedError(w, err, webhookErrors, "Webhook service error")
+}
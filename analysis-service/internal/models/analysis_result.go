@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -18,12 +19,30 @@ type AnalysisResult struct {
 	Details           []byte        `json:"details,omitempty"`
 }
 
+// PreviewResult — результат пробной проверки: что показал бы анализ, если бы файл сдали в задание.
+// Ни работа, ни отчёт не создаются
+type PreviewResult struct {
+	AssignmentID      string          `json:"assignment_id"`
+	FileHash          string          `json:"file_hash"`
+	PlagiarismFlag    bool            `json:"plagiarism_flag"`
+	OriginalWorkID    *string         `json:"original_work_id,omitempty"`
+	MatchPercentage   int             `json:"match_percentage"`
+	Threshold         int             `json:"threshold"`
+	ComparedWithCount int             `json:"compared_with_count"`
+	SimilarWorks      []SimilarWork   `json:"similar_works,omitempty"`
+	ProcessingTimeMs  int             `json:"processing_time_ms"`
+	Details           json.RawMessage `json:"details,omitempty"`
+}
+
 type SimilarWork struct {
 	WorkID          string    `json:"work_id"`
 	StudentID       string    `json:"student_id"`
 	StudentName     string    `json:"student_name,omitempty"`
+	AssignmentID    string    `json:"assignment_id,omitempty"`
+	FileID          string    `json:"file_id,omitempty"`
 	MatchPercentage int       `json:"match_percentage"`
 	FileHash        string    `json:"file_hash"`
+	FileSize        int64     `json:"file_size,omitempty"`
 	SubmittedAt     time.Time `json:"submitted_at"`
 }
 
@@ -48,14 +67,40 @@ type BatchAnalysisRequest struct {
 	WorkIDs []string `json:"work_ids"`
 }
 
+// Статус пачки: cancelled — запрос отменён до завершения, в Results только успевшие работы
+const (
+	BatchStatusCompleted = "completed"
+	BatchStatusCancelled = "cancelled"
+)
+
 type BatchAnalysisResponse struct {
-	Total       int                       `json:"total"`
-	Processed   int                       `json:"processed"`
-	Failed      int                       `json:"failed"`
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	Failed    int `json:"failed"`
+	// Не запускались или прерваны отменой
+	Skipped     int                       `json:"skipped"`
+	Status      string                    `json:"status"`
 	Results     []PlagiarismCheckResponse `json:"results"`
 	CompletedAt time.Time                 `json:"completed_at"`
 }
 
+type RetryFailedResponse struct {
+	Total     int           `json:"total"`
+	Retried   int           `json:"retried"`
+	Abandoned int           `json:"abandoned"`
+	Failed    int           `json:"failed"`
+	Results   []RetryResult `json:"results"`
+}
+
+type RetryResult struct {
+	ReportID string `json:"report_id"`
+	WorkID   string `json:"work_id"`
+	// retried, failed или abandoned; reanalyzed — при пересчёте устаревших отчётов
+	Status  string `json:"status"`
+	Attempt int    `json:"attempt,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
 type AnalysisStats struct {
 	TotalReports      int64             `json:"total_reports"`
 	CompletedReports  int64             `json:"completed_reports"`
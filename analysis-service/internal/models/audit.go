@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	AuditSinkLog      = "log"
+	AuditSinkDatabase = "database"
+)
+
+// AuditEntry — запись о решении анализа для журнала аудита
+type AuditEntry struct {
+	ID              string    `json:"id"`
+	ReportID        string    `json:"report_id"`
+	WorkID          string    `json:"work_id"`
+	AssignmentID    string    `json:"assignment_id"`
+	StudentID       string    `json:"student_id"`
+	PlagiarismFlag  bool      `json:"plagiarism_flag"`
+	MatchPercentage int       `json:"match_percentage"`
+	OriginalWorkID  *string   `json:"original_work_id,omitempty"`
+	Method          string    `json:"method"`
+	Threshold       int       `json:"threshold"`
+	ComparedCount   int       `json:"compared_count"`
+	MatchedWorkIDs  []string  `json:"matched_work_ids"`
+	TriggerSource   string    `json:"trigger_source"`
+	DecidedAt       time.Time `json:"decided_at"`
+}
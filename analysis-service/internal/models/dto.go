@@ -10,6 +10,14 @@ type CreateReportRequest struct {
 	StudentID    string `json:"student_id" validate:"required"`
 }
 
+// PreviewAnalysisRequest — пробная проверка файла, уже загруженного в file-service; student_id
+// исключает из флага плагиата совпадения с работами самого студента
+type PreviewAnalysisRequest struct {
+	FileID       string `json:"file_id" validate:"required"`
+	AssignmentID string `json:"assignment_id" validate:"required"`
+	StudentID    string `json:"student_id"`
+}
+
 type UpdateReportRequest struct {
 	Status          string                 `json:"status" validate:"required,oneof=pending processing completed failed"`
 	PlagiarismFlag  bool                   `json:"plagiarism_flag"`
@@ -30,11 +38,18 @@ type GetReportResponse struct {
 	MatchPercentage    int                    `json:"match_percentage"`
 	FileHash           string                 `json:"file_hash,omitempty"`
 	Details            map[string]interface{} `json:"details,omitempty"`
+	// Заполняется вместо details.comparison_results при постраничном запросе
+	Matches            *ComparisonMatchesPage `json:"matches,omitempty"`
 	ProcessingTimeMs   *int                   `json:"processing_time_ms,omitempty"`
 	ComparedFilesCount int                    `json:"compared_files_count"`
 	CreatedAt          time.Time              `json:"created_at"`
 	StartedAt          *time.Time             `json:"started_at,omitempty"`
 	CompletedAt        *time.Time             `json:"completed_at,omitempty"`
+	// Неудачные попытки анализа по очереди повторов и время следующей, если она запланирована
+	Attempts    int        `json:"attempts"`
+	NextRetryAt *time.Time `json:"next_retry_at,omitempty"`
+	// Отчёты с одинаковой версией посчитаны одной и той же логикой и сравнимы между собой
+	AnalysisVersion string `json:"analysis_version,omitempty"`
 }
 
 type GetAssignmentStatsResponse struct {
@@ -60,23 +75,38 @@ type GetStudentStatsResponse struct {
 }
 
 type SearchReportsRequest struct {
-	WorkID         *string `json:"work_id,omitempty"`
-	AssignmentID   *string `json:"assignment_id,omitempty"`
-	StudentID      *string `json:"student_id,omitempty"`
-	Status         *string `json:"status,omitempty"`
-	PlagiarismFlag *bool   `json:"plagiarism_flag,omitempty"`
-	DateFrom       *string `json:"date_from,omitempty"`
-	DateTo         *string `json:"date_to,omitempty"`
-	Page           int     `json:"page" validate:"min=1"`
-	Limit          int     `json:"limit" validate:"min=1,max=100"`
+	WorkID          *string `json:"work_id,omitempty"`
+	AssignmentID    *string `json:"assignment_id,omitempty"`
+	StudentID       *string `json:"student_id,omitempty"`
+	Status          *string `json:"status,omitempty"`
+	PlagiarismFlag  *bool   `json:"plagiarism_flag,omitempty"`
+	DateFrom        *string `json:"date_from,omitempty"`
+	DateTo          *string `json:"date_to,omitempty"`
+	AnalysisVersion *string `json:"analysis_version,omitempty"`
+	// Курсор из next_cursor предыдущего ответа; с ним page не используется
+	Cursor *string `json:"cursor,omitempty"`
+	Page   int     `json:"page" validate:"min=1"`
+	Limit  int     `json:"limit" validate:"min=1,max=100"`
 }
 
+// ComparisonMatchesPage — страница comparison_results, когда отчёт запрошен с matches_page/matches_limit
+type ComparisonMatchesPage struct {
+	Items      []ComparisonResult `json:"items"`
+	Total      int                `json:"total"`
+	Page       int                `json:"page"`
+	Limit      int                `json:"limit"`
+	TotalPages int                `json:"total_pages"`
+}
+
+// SearchReportsResponse — при запросе по курсору total, page и total_pages не считаются и равны 0
 type SearchReportsResponse struct {
 	Reports    []GetReportResponse `json:"reports"`
 	Total      int                 `json:"total"`
 	Page       int                 `json:"page"`
 	Limit      int                 `json:"limit"`
 	TotalPages int                 `json:"total_pages"`
+	// Курсор следующей страницы для ?cursor=; пустой — страниц больше нет
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type HealthCheckResponse struct {
@@ -100,3 +130,123 @@ type FileHashResponse struct {
 	Hash   string `json:"hash"`
 	Size   int64  `json:"size"`
 }
+
+type ExportJob struct {
+	JobID       string     `json:"job_id"`
+	Status      string     `json:"status"`
+	Format      string     `json:"format"`
+	Error       string     `json:"error,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+const (
+	ExportJobStatusPending   = "pending"
+	ExportJobStatusCompleted = "completed"
+	ExportJobStatusFailed    = "failed"
+)
+
+type ThroughputBucket struct {
+	BucketStart time.Time `json:"bucket_start"`
+	Completed   int       `json:"completed"`
+	Failed      int       `json:"failed"`
+	FailureRate float64   `json:"failure_rate"`
+}
+
+type ThroughputResponse struct {
+	Bucket         string             `json:"bucket"`
+	Since          time.Time          `json:"since"`
+	TotalCompleted int                `json:"total_completed"`
+	TotalFailed    int                `json:"total_failed"`
+	Buckets        []ThroughputBucket `json:"buckets"`
+}
+
+// ComparisonPairResponse — сохранённый результат сравнения двух работ.
+// ReportWorkID указывает, в отчёте какой из работ найдено сравнение.
+type ComparisonPairResponse struct {
+	WorkA           string           `json:"work_a"`
+	WorkB           string           `json:"work_b"`
+	ReportWorkID    string           `json:"report_work_id"`
+	MatchPercentage int              `json:"match_percentage"`
+	MatchedSections []MatchedSection `json:"matched_sections"`
+	ComparedAt      string           `json:"compared_at,omitempty"`
+}
+
+// HashOccurrence — работа, в которой встретился файл с заданным хешем
+type HashOccurrence struct {
+	WorkID          string    `json:"work_id"`
+	ReportID        string    `json:"report_id"`
+	AssignmentID    string    `json:"assignment_id"`
+	StudentID       string    `json:"student_id"`
+	StudentName     string    `json:"student_name,omitempty"`
+	SubmittedAt     time.Time `json:"submitted_at"`
+	Status          string    `json:"status"`
+	PlagiarismFlag  bool      `json:"plagiarism_flag"`
+	MatchPercentage int       `json:"match_percentage"`
+}
+
+type HashOccurrencesResponse struct {
+	FileHash    string           `json:"file_hash"`
+	Total       int              `json:"total"`
+	Assignments int              `json:"assignments"`
+	Students    int              `json:"students"`
+	Works       []HashOccurrence `json:"works"`
+}
+
+type AnalysisVersionCount struct {
+	Version string `json:"version"`
+	Reports int    `json:"reports"`
+}
+
+// WorkCoverageResponse — со сколькими работами задания, доступными на момент анализа, работа действительно сравнена.
+// Чистый вердикт при охвате ниже reports.min_coverage считается неубедительным
+type WorkCoverageResponse struct {
+	WorkID         string `json:"work_id"`
+	AssignmentID   string `json:"assignment_id"`
+	PlagiarismFlag bool   `json:"plagiarism_flag"`
+	// Работы задания, с которыми работа должна была сравниться
+	EligibleWorks int `json:"eligible_works"`
+	ComparedWorks int `json:"compared_works"`
+	SkippedWorks  int `json:"skipped_works"`
+	// Причины пропуска: нет хеша файла, отсеяна по размеру, ошибка сравнения
+	Skipped CoverageSkipped `json:"skipped"`
+	// Из сравнённых: только по хешу, без анализа содержимого
+	HashFallbackPairs  int     `json:"hash_fallback_pairs"`
+	CoveragePercentage float64 `json:"coverage_percentage"`
+	MinCoverage        int     `json:"min_coverage"`
+	Inconclusive       bool    `json:"inconclusive"`
+	// false — отчёт старой версии, пропуски без хеша и ошибки сравнения в нём не записаны
+	CoverageRecorded bool `json:"coverage_recorded"`
+}
+
+type CoverageSkipped struct {
+	MissingHash  int `json:"missing_hash"`
+	SizeMismatch int `json:"size_mismatch"`
+	CompareError int `json:"compare_error"`
+}
+
+// WorkPercentileResponse — место работы среди завершённых отчётов задания по проценту совпадения
+type WorkPercentileResponse struct {
+	WorkID          string `json:"work_id"`
+	AssignmentID    string `json:"assignment_id"`
+	MatchPercentage int    `json:"match_percentage"`
+	// Доля других работ задания с меньшим процентом совпадения, 0–100
+	Percentile float64 `json:"percentile"`
+	// Сколько других работ с меньшим процентом и сколько всего других завершённых работ
+	LowerCount int `json:"lower_count"`
+	PeerCount  int `json:"peer_count"`
+}
+
+// AnalysisVersionResponse — текущая версия анализа и распределение завершённых отчётов по версиям
+type AnalysisVersionResponse struct {
+	Current  string                 `json:"current"`
+	Versions []AnalysisVersionCount `json:"versions"`
+	// Завершённых отчётов, посчитанных не текущей версией
+	Outdated int `json:"outdated"`
+}
+
+// SupersedeReportRequest — тело POST /reports/work/{work_id}/supersede
+type SupersedeReportRequest struct {
+	SupersededBy string `json:"superseded_by" validate:"required,uuid"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -12,14 +13,26 @@ type WorkCreatedEvent struct {
 	Timestamp    int64  `json:"timestamp"`
 }
 
+// Названия событий анализа совпадают с ключами маршрутизации в plagiarism_exchange
+const (
+	EventAnalysisStarted   = "analysis.started"
+	EventAnalysisCompleted = "analysis.completed"
+	EventAnalysisFailed    = "analysis.failed"
+)
+
 type AnalysisStartedEvent struct {
-	WorkID    string    `json:"work_id"`
-	StartedAt time.Time `json:"started_at"`
+	WorkID       string    `json:"work_id"`
+	ReportID     string    `json:"report_id"`
+	AssignmentID string    `json:"assignment_id,omitempty"`
+	StudentID    string    `json:"student_id,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
 }
 
 type AnalysisCompletedEvent struct {
 	WorkID          string    `json:"work_id"`
 	ReportID        string    `json:"report_id"`
+	AssignmentID    string    `json:"assignment_id,omitempty"`
+	StudentID       string    `json:"student_id,omitempty"`
 	Status          string    `json:"status"`
 	PlagiarismFlag  bool      `json:"plagiarism_flag"`
 	OriginalWorkID  *string   `json:"original_work_id,omitempty"`
@@ -29,10 +42,19 @@ type AnalysisCompletedEvent struct {
 }
 
 type AnalysisFailedEvent struct {
-	WorkID   string    `json:"work_id"`
-	Error    string    `json:"error"`
-	Attempts int       `json:"attempts"`
-	FailedAt time.Time `json:"failed_at"`
+	WorkID       string    `json:"work_id"`
+	ReportID     string    `json:"report_id,omitempty"`
+	AssignmentID string    `json:"assignment_id,omitempty"`
+	StudentID    string    `json:"student_id,omitempty"`
+	Error        string    `json:"error"`
+	Attempts     int       `json:"attempts"`
+	FailedAt     time.Time `json:"failed_at"`
+}
+
+// AnalysisStreamMessage — сообщение, которое получают подписчики потока событий по WebSocket
+type AnalysisStreamMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
 }
 
 type QueueStatsEvent struct {
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+type NotificationRecipient struct {
+	ID           string    `json:"id" db:"id"`
+	AssignmentID string    `json:"assignment_id" db:"assignment_id"`
+	Recipient    string    `json:"recipient" db:"recipient"`
+	Type         string    `json:"type" db:"type"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+const (
+	RecipientTypeEmail   = "email"
+	RecipientTypeWebhook = "webhook"
+)
+
+type AddNotificationRecipientRequest struct {
+	Recipient string `json:"recipient"`
+}
+
+// PlagiarismNotification — содержимое уведомления о найденном плагиате
+type PlagiarismNotification struct {
+	WorkID          string    `json:"work_id"`
+	ReportID        string    `json:"report_id"`
+	AssignmentID    string    `json:"assignment_id"`
+	StudentID       string    `json:"student_id"`
+	OriginalWorkID  *string   `json:"original_work_id,omitempty"`
+	MatchPercentage int       `json:"match_percentage"`
+	DetectedAt      time.Time `json:"detected_at"`
+	// Пробное уведомление от /admin/notifications/test
+	Test bool `json:"test,omitempty"`
+}
+
+type TestNotificationRequest struct {
+	// Пустой assignment_id — проверка глобальных получателей по умолчанию
+	AssignmentID string `json:"assignment_id,omitempty"`
+}
+
+type NotificationDeliveryResult struct {
+	Recipient  string `json:"recipient"`
+	Type       string `json:"type"`
+	Success    bool   `json:"success"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+type TestNotificationResponse struct {
+	NotificationsEnabled bool                         `json:"notifications_enabled"`
+	Total                int                          `json:"total"`
+	Delivered            int                          `json:"delivered"`
+	Failed               int                          `json:"failed"`
+	Results              []NotificationDeliveryResult `json:"results"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type VerdictOverride struct {
+	ID             string    `json:"id" db:"id"`
+	ReportID       string    `json:"report_id" db:"report_id"`
+	AssignmentID   string    `json:"assignment_id" db:"assignment_id"`
+	AlgorithmFlag  bool      `json:"algorithm_flag" db:"algorithm_flag"`
+	OverriddenFlag bool      `json:"overridden_flag" db:"overridden_flag"`
+	OverriddenBy   string    `json:"overridden_by,omitempty" db:"overridden_by"`
+	Reason         string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+type OverrideVerdictRequest struct {
+	PlagiarismFlag *bool  `json:"plagiarism_flag"`
+	Reason         string `json:"reason"`
+}
+
+// OverrideStats — как часто преподаватели меняют вердикт алгоритма по заданию.
+// Учитывается последнее изменение каждого отчёта.
+type OverrideStats struct {
+	AssignmentID      string  `json:"assignment_id"`
+	FlaggedReports    int     `json:"flagged_reports"`
+	UnflaggedReports  int     `json:"unflagged_reports"`
+	FalsePositives    int     `json:"false_positives"`
+	FalseNegatives    int     `json:"false_negatives"`
+	FalsePositiveRate float64 `json:"false_positive_rate"`
+	FalseNegativeRate float64 `json:"false_negative_rate"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// ReferenceDocument — документ из известного источника в базе сравнения
+type ReferenceDocument struct {
+	ID       string `json:"id" db:"id"`
+	FileID   string `json:"file_id" db:"file_id"`
+	FileHash string `json:"file_hash" db:"file_hash"`
+	FileSize int64  `json:"file_size" db:"file_size"`
+	Title    string `json:"title,omitempty" db:"title"`
+	Source   string `json:"source,omitempty" db:"source"`
+	Category string `json:"category,omitempty" db:"category"`
+	// text или code
+	ContentType string `json:"content_type" db:"content_type"`
+	// 0 — документ проиндексирован только по хешу файла (winnowing выключен)
+	FingerprintCount int       `json:"fingerprint_count" db:"fingerprint_count"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+}
+
+// BulkImportReferencesRequest — тело POST /analysis/reference-corpus/bulk
+type BulkImportReferencesRequest struct {
+	Documents []ReferenceDocumentRequest `json:"documents" validate:"required"`
+	// Метки для документов, у которых не указаны свои
+	Source   string `json:"source,omitempty" validate:"max=255"`
+	Category string `json:"category,omitempty" validate:"max=255"`
+}
+
+type ReferenceDocumentRequest struct {
+	FileID      string `json:"file_id" validate:"required,max=255"`
+	Title       string `json:"title,omitempty" validate:"max=500"`
+	Source      string `json:"source,omitempty" validate:"max=255"`
+	Category    string `json:"category,omitempty" validate:"max=255"`
+	ContentType string `json:"content_type,omitempty" validate:"oneof=text code"`
+}
+
+// Статусы документа в ответе массового импорта
+const (
+	ReferenceImportIndexed   = "indexed"
+	ReferenceImportDuplicate = "duplicate"
+	ReferenceImportFailed    = "failed"
+)
+
+// ReferenceImportItem — результат импорта одного документа; Index — позиция в запросе
+type ReferenceImportItem struct {
+	Index            int    `json:"index"`
+	FileID           string `json:"file_id"`
+	Status           string `json:"status"`
+	ReferenceID      string `json:"reference_id,omitempty"`
+	FileHash         string `json:"file_hash,omitempty"`
+	FingerprintCount int    `json:"fingerprint_count,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+type BulkImportReferencesResult struct {
+	Total      int                   `json:"total"`
+	Indexed    int                   `json:"indexed"`
+	Duplicates int                   `json:"duplicates"`
+	Failed     int                   `json:"failed"`
+	Items      []ReferenceImportItem `json:"items"`
+}
@@ -24,8 +24,31 @@ type Report struct {
 	StartedAt          *time.Time      `json:"started_at,omitempty" db:"started_at"`
 	CompletedAt        *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
 	UpdatedAt          time.Time       `json:"updated_at" db:"updated_at"`
+	RetryCount         int             `json:"retry_count" db:"retry_count"`
+	TriggerSource      string          `json:"trigger_source" db:"trigger_source"`
 }
 
+// Порядок повтора упавших отчётов в RetryFailedAnalyses
+const (
+	RetryOrderNewest = "newest"
+	RetryOrderOldest = "oldest"
+	// Сначала отчёты с наименьшим числом попыток, затем более новые
+	RetryOrderPriority = "priority"
+)
+
+// Источники запуска анализа, сохраняемые в trigger_source
+const (
+	TriggerSourceAPI       = "api"
+	TriggerSourceAsync     = "async"
+	TriggerSourceBatch     = "batch"
+	TriggerSourceEvent     = "event"
+	TriggerSourceRetry     = "retry"
+	TriggerSourceReanalyze = "reanalyze"
+	TriggerSourceRecheck   = "recheck"
+	TriggerSourceUrgent    = "urgent"
+	TriggerSourceUnknown   = "unknown"
+)
+
 type ReportStatus string
 
 const (
@@ -33,8 +56,15 @@ const (
 	ReportStatusProcessing ReportStatus = "processing"
 	ReportStatusCompleted  ReportStatus = "completed"
 	ReportStatusFailed     ReportStatus = "failed"
+	// Терминальный статус: превышено число повторных попыток анализа
+	ReportStatusAbandoned ReportStatus = "abandoned"
+	// Терминальный статус: к работе не загружен файл
+	ReportStatusNoFile ReportStatus = "no_file"
 )
 
+// PendingFileID — file_id, который work-service ставит работе до загрузки файла
+const PendingFileID = "pending"
+
 func (rs ReportStatus) String() string {
 	return string(rs)
 }
@@ -43,6 +73,48 @@ type ReportDetails struct {
 	ComparisonResults []ComparisonResult `json:"comparison_results,omitempty"`
 	FileInfo          FileInfo           `json:"file_info,omitempty"`
 	AnalysisMetadata  AnalysisMetadata   `json:"analysis_metadata,omitempty"`
+
+	// Совпадения с недавними работами других заданий; есть только при включённом analysis.cross_assignment
+	CrossAssignment *CrossAssignmentCluster `json:"cross_assignment,omitempty"`
+	// Более поздние работы, совпавшие с этой; пишутся при analysis.later_matches.enabled и не меняют plagiarism_flag
+	LaterMatches []LaterMatch `json:"later_matches,omitempty"`
+	// Работа заменена новой попыткой студента (политика replace в work-service)
+	Superseded *ReportSupersession `json:"superseded,omitempty"`
+}
+
+// ReportSupersession — какая работа заменила работу отчёта и когда
+type ReportSupersession struct {
+	ByWorkID     string    `json:"by_work_id"`
+	SupersededAt time.Time `json:"superseded_at"`
+}
+
+// LaterMatch — работа, сданная позже и совпавшая с этой не меньше чем на analysis.later_matches.min_match
+type LaterMatch struct {
+	WorkID          string    `json:"work_id"`
+	ReportID        string    `json:"report_id"`
+	StudentID       string    `json:"student_id"`
+	MatchPercentage int       `json:"match_percentage"`
+	DetectedAt      time.Time `json:"detected_at"`
+}
+
+// CrossAssignmentCluster — работы других заданий, сданные в пределах окна и совпавшие с работой не ниже порога.
+// Не влияет на plagiarism_flag: задания разные, и решение остаётся за преподавателем
+type CrossAssignmentCluster struct {
+	Flagged       bool                   `json:"flagged"`
+	WindowMinutes int                    `json:"window_minutes"`
+	ComparedCount int                    `json:"compared_count"`
+	Matches       []CrossAssignmentMatch `json:"matches"`
+	CheckedAt     time.Time              `json:"checked_at"`
+}
+
+type CrossAssignmentMatch struct {
+	WorkID          string    `json:"work_id"`
+	AssignmentID    string    `json:"assignment_id"`
+	StudentID       string    `json:"student_id"`
+	MatchPercentage int       `json:"match_percentage"`
+	SubmittedAt     time.Time `json:"submitted_at"`
+	// Разница во времени сдачи двух работ
+	MinutesApart int `json:"minutes_apart"`
 }
 
 type ComparisonResult struct {
@@ -52,6 +124,19 @@ type ComparisonResult struct {
 	FileHash        string `json:"file_hash"`
 	FileName        string `json:"file_name"`
 	ComparedAt      string `json:"compared_at"`
+	// Совпавшие фрагменты сохраняются только для пар выше порога при анализе содержимого
+	MatchedSections []MatchedSection `json:"matched_sections,omitempty"`
+	// Какая оценка дала match_percentage пары (ScoreMethod*)
+	ScoreMethod string `json:"score_method,omitempty"`
+}
+
+type MatchedSection struct {
+	Text1Start int     `json:"text1_start"`
+	Text1End   int     `json:"text1_end"`
+	Text2Start int     `json:"text2_start"`
+	Text2End   int     `json:"text2_end"`
+	Similarity float64 `json:"similarity"`
+	Text       string  `json:"text"`
 }
 
 type FileInfo struct {
@@ -62,12 +147,60 @@ type FileInfo struct {
 }
 
 type AnalysisMetadata struct {
-	AlgorithmUsed    string    `json:"algorithm_used"`
-	SimilarityMethod string    `json:"similarity_method"`
-	AnalysisVersion  string    `json:"analysis_version"`
-	Threshold        int       `json:"threshold"`
-	StartedAt        time.Time `json:"started_at"`
-	CompletedAt      time.Time `json:"completed_at"`
+	AlgorithmUsed    string   `json:"algorithm_used"`
+	SimilarityMethod string   `json:"similarity_method"`
+	AnalysisVersion  string   `json:"analysis_version"`
+	Threshold        int      `json:"threshold"`
+	ContentType      string   `json:"content_type,omitempty"`
+	Language         string   `json:"language,omitempty"`
+	Normalization    []string `json:"normalization,omitempty"`
+	FallbackMethod   string   `json:"fallback_method,omitempty"`
+	FallbackReason   string   `json:"fallback_reason,omitempty"`
+	// Пары, отсеянные по размеру файла без сравнения хешей / содержимого
+	HashSkippedBySize    int `json:"hash_skipped_by_size,omitempty"`
+	ContentSkippedBySize int `json:"content_skipped_by_size,omitempty"`
+	// Работы без хеша файла и пары, сравнение хешей которых упало: в итог не вошли
+	SkippedMissingHash  int `json:"skipped_missing_hash,omitempty"`
+	SkippedCompareError int `json:"skipped_compare_error,omitempty"`
+	// Пары, сравнённые только по хешу, потому что текст одной из работ не извлёкся
+	HashFallbackPairs int `json:"hash_fallback_pairs,omitempty"`
+	// Отчёт посчитан версией, которая записывает все пропуски; в старых отчётах охват оценивается снизу
+	CoverageRecorded bool `json:"coverage_recorded,omitempty"`
+	// Сравнения ниже analysis.min_recorded_match: учтены в compared_files_count, но не сохранены
+	UnrecordedComparisons int       `json:"unrecorded_comparisons,omitempty"`
+	StartedAt             time.Time `json:"started_at"`
+	CompletedAt           time.Time `json:"completed_at"`
+	// Время по фазам анализа: что медленнее — соседние сервисы или само сравнение
+	PhaseTimings *PhaseTimings `json:"phase_timings,omitempty"`
+	// Какая оценка дала итоговый match_percentage отчёта (ScoreMethod*)
+	ScoreMethod string `json:"score_method,omitempty"`
+}
+
+// Процент совпадения пары — максимум из точного совпадения хешей файлов (0 или 100) и оценки
+// сходства (0–100). Оценка сходства неидентичных файлов ограничена analysis.partial_match_cap,
+// так что 100 означает побайтную копию, промежуточные значения — частичное совпадение.
+const (
+	ScoreMethodExactHash    = "exact_hash"
+	ScoreMethodSimHash      = "simhash"
+	ScoreMethodContent      = "content_similarity"
+	ScoreMethodEditDistance = "edit_distance"
+	ScoreMethodWinnowing    = "winnowing"
+	// Разные файлы с одинаковым нормализованным текстом (content_hash file-service), например PDF и DOCX
+	ScoreMethodContentHash = "content_hash"
+)
+
+// PhaseTimings — длительность фаз анализа, мс
+type PhaseTimings struct {
+	// Хеш и размер файла из file-service
+	HashFetchMs int64 `json:"hash_fetch_ms"`
+	// Работы задания из work-service; 0 в пакетном анализе, где набор загружается один раз на задание
+	PreviousWorksFetchMs int64 `json:"previous_works_fetch_ms"`
+	// Загрузка содержимого и SimHash-отпечатков файлов (входит в сравнение по времени, но не в comparison_ms)
+	ContentFetchMs int64 `json:"content_fetch_ms"`
+	// Сравнение без загрузки содержимого
+	ComparisonMs int64 `json:"comparison_ms"`
+	// Сохранение отчёта с результатом
+	PersistenceMs int64 `json:"persistence_ms"`
 }
 
 type AssignmentStats struct {
@@ -90,6 +223,28 @@ type StudentStats struct {
 	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// AssignmentThreshold — порог плагиата, заданный для отдельного задания
+type AssignmentThreshold struct {
+	AssignmentID string    `json:"assignment_id" db:"assignment_id"`
+	Threshold    int       `json:"threshold" db:"threshold"`
+	UpdatedBy    string    `json:"updated_by,omitempty" db:"updated_by"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SetAssignmentThresholdRequest struct {
+	Threshold *int `json:"threshold"`
+}
+
+// Статусы записи очереди повторов analysis_queue
+const (
+	QueueStatusPending    = "pending"
+	QueueStatusProcessing = "processing"
+	QueueStatusCompleted  = "completed"
+	// Терминальный статус: исчерпаны max_attempts
+	QueueStatusFailed = "failed"
+)
+
+// AnalysisQueueItem — упавший анализ, ожидающий повтора; scheduled_at растёт экспоненциально с attempts
 type AnalysisQueueItem struct {
 	ID           string     `json:"id" db:"id"`
 	WorkID       string     `json:"work_id" db:"work_id"`
@@ -106,3 +261,34 @@ type AnalysisQueueItem struct {
 	StartedAt    *time.Time `json:"started_at,omitempty" db:"started_at"`
 	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
 }
+
+// WarmupResult — что загружено в кеш при прогреве задания
+type WarmupResult struct {
+	AssignmentID string    `json:"assignment_id"`
+	Works        int       `json:"works"`
+	Fingerprints int       `json:"fingerprints"`
+	Texts        int       `json:"texts"`
+	Failed       int       `json:"failed"`
+	DurationMs   int64     `json:"duration_ms"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// HashIndexEntry — проанализированная работа в индексе точных копий
+type HashIndexEntry struct {
+	AssignmentID string
+	WorkID       string
+	StudentID    string
+	FileHash     string
+	// Время создания отчёта, то есть первого анализа работы
+	AnalyzedAt time.Time
+}
+
+// HashIndexStats — размер индекса точных копий после перестроения
+type HashIndexStats struct {
+	AssignmentID string `json:"assignment_id,omitempty"`
+	Assignments  int    `json:"assignments"`
+	Hashes       int    `json:"hashes"`
+	Works        int    `json:"works"`
+	// Время последнего полного перестроения
+	RebuiltAt time.Time `json:"rebuilt_at"`
+}
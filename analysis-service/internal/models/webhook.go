@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// WebhookSubscription — callback URL, на который отправляется analysis.completed
+type WebhookSubscription struct {
+	ID  string `json:"id" db:"id"`
+	URL string `json:"url" db:"url"`
+	// Возвращается только при создании подписки
+	Secret              string     `json:"secret,omitempty" db:"secret"`
+	AssignmentID        *string    `json:"assignment_id,omitempty" db:"assignment_id"`
+	PlagiarismFlag      *bool      `json:"plagiarism_flag,omitempty" db:"plagiarism_flag"`
+	Active              bool       `json:"active" db:"active"`
+	ConsecutiveFailures int        `json:"consecutive_failures" db:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty" db:"last_error"`
+	LastDeliveryAt      *time.Time `json:"last_delivery_at,omitempty" db:"last_delivery_at"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL string `json:"url"`
+	// Пустой assignment_id — события всех заданий
+	AssignmentID string `json:"assignment_id,omitempty"`
+	// Не задан — любой вердикт
+	PlagiarismFlag *bool `json:"plagiarism_flag,omitempty"`
+	// Пустой secret генерируется сервисом
+	Secret string `json:"secret,omitempty"`
+}
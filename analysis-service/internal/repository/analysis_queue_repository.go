@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type AnalysisQueueRepository interface {
+	// RecordFailure ставит работу в очередь или увеличивает attempts существующей записи
+	RecordFailure(ctx context.Context, item *models.AnalysisQueueItem) (*models.AnalysisQueueItem, error)
+	Schedule(ctx context.Context, id string, scheduledAt time.Time) error
+	// ClaimDue переводит в processing записи, чьё время повтора наступило; зависшие в processing
+	// дольше staleAfter считаются брошенными и тоже забираются
+	ClaimDue(ctx context.Context, now time.Time, staleAfter time.Duration, limit int) ([]models.AnalysisQueueItem, error)
+	MarkCompleted(ctx context.Context, workID string) error
+	MarkFailed(ctx context.Context, id string) error
+	GetByWorkID(ctx context.Context, workID string) (*models.AnalysisQueueItem, error)
+}
+
+type analysisQueueRepository struct {
+	*PostgresRepository
+}
+
+func NewAnalysisQueueRepository(db *sql.DB, logger zerolog.Logger) AnalysisQueueRepository {
+	return &analysisQueueRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+const analysisQueueColumns = `
+	id, work_id, file_id, assignment_id, student_id, status,
+	COALESCE(priority, 0), COALESCE(attempts, 0), COALESCE(max_attempts, 0),
+	COALESCE(error_message, ''), created_at, scheduled_at, started_at, completed_at
+`
+
+func (r *analysisQueueRepository) RecordFailure(ctx context.Context, item *models.AnalysisQueueItem) (*models.AnalysisQueueItem, error) {
+	if item.ID == "" {
+		item.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO analysis_queue (
+			id, work_id, file_id, assignment_id, student_id, status,
+			priority, attempts, max_attempts, error_message, created_at
+		) VALUES ($1, $2, $3, $4, $5, 'pending', $6, 1, $7, $8, $9)
+		ON CONFLICT (work_id) DO UPDATE SET
+			file_id = EXCLUDED.file_id,
+			status = 'pending',
+			attempts = COALESCE(analysis_queue.attempts, 0) + 1,
+			max_attempts = EXCLUDED.max_attempts,
+			error_message = EXCLUDED.error_message,
+			started_at = NULL,
+			completed_at = NULL
+		RETURNING ` + analysisQueueColumns
+
+	return r.scanOne(r.db.QueryRowContext(ctx, query,
+		item.ID,
+		item.WorkID,
+		item.FileID,
+		item.AssignmentID,
+		item.StudentID,
+		item.Priority,
+		item.MaxAttempts,
+		item.ErrorMessage,
+		time.Now(),
+	))
+}
+
+func (r *analysisQueueRepository) Schedule(ctx context.Context, id string, scheduledAt time.Time) error {
+	query := `UPDATE analysis_queue SET scheduled_at = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, scheduledAt, id)
+	return err
+}
+
+func (r *analysisQueueRepository) ClaimDue(ctx context.Context, now time.Time, staleAfter time.Duration, limit int) ([]models.AnalysisQueueItem, error) {
+	// SKIP LOCKED позволяет нескольким экземплярам сервиса разбирать очередь без двойной обработки
+	query := `
+		UPDATE analysis_queue
+		SET status = 'processing', started_at = $1
+		WHERE id IN (
+			SELECT id FROM analysis_queue
+			WHERE attempts < max_attempts
+				AND (
+					(status = 'pending' AND scheduled_at <= $1)
+					OR (status = 'processing' AND started_at < $2)
+				)
+			ORDER BY priority DESC, scheduled_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + analysisQueueColumns
+
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(-staleAfter), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.AnalysisQueueItem
+	for rows.Next() {
+		item, err := r.scanOne(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *analysisQueueRepository) MarkCompleted(ctx context.Context, workID string) error {
+	query := `
+		UPDATE analysis_queue
+		SET status = 'completed', completed_at = $1
+		WHERE work_id = $2 AND status IN ('pending', 'processing')
+	`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), workID)
+	return err
+}
+
+func (r *analysisQueueRepository) MarkFailed(ctx context.Context, id string) error {
+	query := `
+		UPDATE analysis_queue
+		SET status = 'failed', scheduled_at = NULL, completed_at = $1
+		WHERE id = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
+func (r *analysisQueueRepository) GetByWorkID(ctx context.Context, workID string) (*models.AnalysisQueueItem, error) {
+	query := `SELECT ` + analysisQueueColumns + ` FROM analysis_queue WHERE work_id = $1`
+
+	item, err := r.scanOne(r.db.QueryRowContext(ctx, query, workID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return item, err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (r *analysisQueueRepository) scanOne(row rowScanner) (*models.AnalysisQueueItem, error) {
+	item := &models.AnalysisQueueItem{}
+	err := row.Scan(
+		&item.ID,
+		&item.WorkID,
+		&item.FileID,
+		&item.AssignmentID,
+		&item.StudentID,
+		&item.Status,
+		&item.Priority,
+		&item.Attempts,
+		&item.MaxAttempts,
+		&item.ErrorMessage,
+		&item.CreatedAt,
+		&item.ScheduledAt,
+		&item.StartedAt,
+		&item.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+type AuditRepository interface {
+	Create(ctx context.Context, entry *models.AuditEntry) error
+}
+
+type auditRepository struct {
+	*PostgresRepository
+}
+
+func NewAuditRepository(db *sql.DB, logger zerolog.Logger) AuditRepository {
+	return &auditRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+func (r *auditRepository) Create(ctx context.Context, entry *models.AuditEntry) error {
+	query := `
+		INSERT INTO analysis_audit_log (
+			id, report_id, work_id, assignment_id, student_id,
+			plagiarism_flag, match_percentage, original_work_id, method, threshold,
+			compared_count, matched_work_ids, trigger_source, decided_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		entry.ID,
+		entry.ReportID,
+		entry.WorkID,
+		entry.AssignmentID,
+		entry.StudentID,
+		entry.PlagiarismFlag,
+		entry.MatchPercentage,
+		entry.OriginalWorkID,
+		entry.Method,
+		entry.Threshold,
+		entry.ComparedCount,
+		pq.Array(entry.MatchedWorkIDs),
+		entry.TriggerSource,
+		entry.DecidedAt,
+	)
+
+	return err
+}
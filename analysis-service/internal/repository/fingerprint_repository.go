@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+// FingerprintRepository хранит отпечатки winnowing и SimHash работ (реализует analyzer.FingerprintStore)
+type FingerprintRepository interface {
+	GetFingerprints(ctx context.Context, workID, method, fileHash string) ([]uint64, bool, error)
+	SaveFingerprints(ctx context.Context, workID, method, fileHash string, fingerprints []uint64) error
+}
+
+type fingerprintRepository struct {
+	*PostgresRepository
+}
+
+func NewFingerprintRepository(db *sql.DB, logger zerolog.Logger) FingerprintRepository {
+	return &fingerprintRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+// GetFingerprints — ok=false, если отпечатков нет или они построены по другому файлу
+func (r *fingerprintRepository) GetFingerprints(ctx context.Context, workID, method, fileHash string) ([]uint64, bool, error) {
+	query := `
+		SELECT fingerprints
+		FROM work_fingerprints
+		WHERE work_id = $1 AND method = $2 AND file_hash = $3
+	`
+
+	var stored pq.Int64Array
+	err := r.db.QueryRowContext(ctx, query, workID, method, fileHash).Scan(&stored)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	// BIGINT знаковый: uint64 хранится с тем же набором битов
+	fingerprints := make([]uint64, len(stored))
+	for i, v := range stored {
+		fingerprints[i] = uint64(v)
+	}
+	return fingerprints, true, nil
+}
+
+func (r *fingerprintRepository) SaveFingerprints(ctx context.Context, workID, method, fileHash string, fingerprints []uint64) error {
+	stored := make(pq.Int64Array, len(fingerprints))
+	for i, v := range fingerprints {
+		stored[i] = int64(v)
+	}
+
+	query := `
+		INSERT INTO work_fingerprints (work_id, method, file_hash, fingerprints, created_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+		ON CONFLICT (work_id, method) DO UPDATE SET
+			file_hash = EXCLUDED.file_hash,
+			fingerprints = EXCLUDED.fingerprints,
+			created_at = EXCLUDED.created_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query, workID, method, fileHash, stored)
+	return err
+}
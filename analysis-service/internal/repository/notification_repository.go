@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type NotificationRepository interface {
+	GetRecipients(ctx context.Context, assignmentID string) ([]models.NotificationRecipient, error)
+	AddRecipient(ctx context.Context, recipient *models.NotificationRecipient) error
+	DeleteRecipient(ctx context.Context, assignmentID, recipientID string) (bool, error)
+}
+
+type notificationRepository struct {
+	*PostgresRepository
+}
+
+func NewNotificationRepository(db *sql.DB, logger zerolog.Logger) NotificationRepository {
+	return &notificationRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+func (r *notificationRepository) GetRecipients(ctx context.Context, assignmentID string) ([]models.NotificationRecipient, error) {
+	query := `
+		SELECT id, assignment_id, recipient, type, created_at
+		FROM assignment_notification_recipients
+		WHERE assignment_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipients []models.NotificationRecipient
+	for rows.Next() {
+		var recipient models.NotificationRecipient
+		if err := rows.Scan(
+			&recipient.ID,
+			&recipient.AssignmentID,
+			&recipient.Recipient,
+			&recipient.Type,
+			&recipient.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	return recipients, rows.Err()
+}
+
+func (r *notificationRepository) AddRecipient(ctx context.Context, recipient *models.NotificationRecipient) error {
+	if recipient.ID == "" {
+		recipient.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO assignment_notification_recipients (id, assignment_id, recipient, type, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (assignment_id, recipient) DO NOTHING
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		recipient.ID,
+		recipient.AssignmentID,
+		recipient.Recipient,
+		recipient.Type,
+		recipient.CreatedAt,
+	)
+	return err
+}
+
+func (r *notificationRepository) DeleteRecipient(ctx context.Context, assignmentID, recipientID string) (bool, error) {
+	query := `DELETE FROM assignment_notification_recipients WHERE assignment_id = $1 AND id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, assignmentID, recipientID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
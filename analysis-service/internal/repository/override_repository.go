@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type OverrideRepository interface {
+	Create(ctx context.Context, override *models.VerdictOverride) error
+	GetFirstByReportID(ctx context.Context, reportID string) (*models.VerdictOverride, error)
+	GetAssignmentStats(ctx context.Context, assignmentID string) (*models.OverrideStats, error)
+	// GetOverriddenReportIDs — отчёты задания, вердикт которых менялся вручную
+	GetOverriddenReportIDs(ctx context.Context, assignmentID string) (map[string]bool, error)
+}
+
+type overrideRepository struct {
+	*PostgresRepository
+}
+
+func NewOverrideRepository(db *sql.DB, logger zerolog.Logger) OverrideRepository {
+	return &overrideRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+func (r *overrideRepository) Create(ctx context.Context, override *models.VerdictOverride) error {
+	if override.ID == "" {
+		override.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO verdict_overrides (
+			id, report_id, assignment_id, algorithm_flag, overridden_flag,
+			overridden_by, reason, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		override.ID,
+		override.ReportID,
+		override.AssignmentID,
+		override.AlgorithmFlag,
+		override.OverriddenFlag,
+		override.OverriddenBy,
+		override.Reason,
+		override.CreatedAt,
+	)
+
+	return err
+}
+
+func (r *overrideRepository) GetFirstByReportID(ctx context.Context, reportID string) (*models.VerdictOverride, error) {
+	query := `
+		SELECT id, report_id, assignment_id, algorithm_flag, overridden_flag,
+			COALESCE(overridden_by, ''), COALESCE(reason, ''), created_at
+		FROM verdict_overrides
+		WHERE report_id = $1
+		ORDER BY created_at
+		LIMIT 1
+	`
+
+	override := &models.VerdictOverride{}
+	err := r.db.QueryRowContext(ctx, query, reportID).Scan(
+		&override.ID,
+		&override.ReportID,
+		&override.AssignmentID,
+		&override.AlgorithmFlag,
+		&override.OverriddenFlag,
+		&override.OverriddenBy,
+		&override.Reason,
+		&override.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return override, nil
+}
+
+func (r *overrideRepository) GetAssignmentStats(ctx context.Context, assignmentID string) (*models.OverrideStats, error) {
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (report_id) report_id, algorithm_flag, overridden_flag
+			FROM verdict_overrides
+			WHERE assignment_id = $1
+			ORDER BY report_id, created_at DESC
+		),
+		verdicts AS (
+			SELECT
+				COALESCE(l.algorithm_flag, r.plagiarism_flag) AS algorithm_flag,
+				l.overridden_flag
+			FROM reports r
+			LEFT JOIN latest l ON l.report_id = r.id
+			WHERE r.assignment_id = $1 AND r.status = 'completed'
+		)
+		SELECT
+			COUNT(*) FILTER (WHERE algorithm_flag),
+			COUNT(*) FILTER (WHERE NOT algorithm_flag),
+			COUNT(*) FILTER (WHERE algorithm_flag AND overridden_flag = FALSE),
+			COUNT(*) FILTER (WHERE NOT algorithm_flag AND overridden_flag = TRUE)
+		FROM verdicts
+	`
+
+	stats := &models.OverrideStats{AssignmentID: assignmentID}
+	err := r.db.QueryRowContext(ctx, query, assignmentID).Scan(
+		&stats.FlaggedReports,
+		&stats.UnflaggedReports,
+		&stats.FalsePositives,
+		&stats.FalseNegatives,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (r *overrideRepository) GetOverriddenReportIDs(ctx context.Context, assignmentID string) (map[string]bool, error) {
+	query := `
+		SELECT DISTINCT report_id
+		FROM verdict_overrides
+		WHERE assignment_id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reportIDs := make(map[string]bool)
+	for rows.Next() {
+		var reportID string
+		if err := rows.Scan(&reportID); err != nil {
+			return nil, err
+		}
+		reportIDs[reportID] = true
+	}
+
+	return reportIDs, rows.Err()
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 
 	"github.com/rs/zerolog"
 
@@ -16,6 +17,8 @@ type PlagiarismRepository interface {
 	GetFileHashesByAssignment(ctx context.Context, assignmentID string) (map[string]string, error) // file_id -> hash
 	SaveComparisonResult(ctx context.Context, workID string, comparedWith []string, results []models.ComparisonResult) error
 	GetComparisonHistory(ctx context.Context, workID string) ([]models.ComparisonResult, error)
+	GetComparisonResultsPage(ctx context.Context, workID string, limit, offset int) ([]models.ComparisonResult, int, error)
+	GetComparisonPair(ctx context.Context, workA, workB string) (string, *models.ComparisonResult, error)
 	GetTopPlagiarizedWorks(ctx context.Context, limit int) ([]models.Report, error)
 	GetPlagiarismPatterns(ctx context.Context, assignmentID string) ([]models.ComparisonResult, error)
 }
@@ -160,9 +163,14 @@ func (r *plagiarismRepository) SaveComparisonResult(ctx context.Context, workID
 }
 
 func (r *plagiarismRepository) GetComparisonHistory(ctx context.Context, workID string) ([]models.ComparisonResult, error) {
+	// Отсутствующий ключ или не-массив (в т.ч. JSON null) считаем пустой историей
 	query := `
 		SELECT 
-			details->'comparison_results'
+			CASE
+				WHEN jsonb_typeof(details->'comparison_results') = 'array'
+					THEN details->'comparison_results'
+				ELSE '[]'::jsonb
+			END
 		FROM reports
 		WHERE work_id = $1
 			AND details IS NOT NULL
@@ -177,7 +185,106 @@ func (r *plagiarismRepository) GetComparisonHistory(ctx context.Context, workID
 		return nil, err
 	}
 
-	return []models.ComparisonResult{}, nil
+	results := []models.ComparisonResult{}
+	if err := json.Unmarshal(resultsJSON, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetComparisonResultsPage возвращает страницу comparison_results отчёта (по убыванию процента
+// совпадения) и общее число результатов. Массив разбирается в БД, чтобы не тянуть его целиком.
+func (r *plagiarismRepository) GetComparisonResultsPage(ctx context.Context, workID string, limit, offset int) ([]models.ComparisonResult, int, error) {
+	countQuery := `
+		SELECT
+			CASE
+				WHEN jsonb_typeof(details->'comparison_results') = 'array'
+					THEN jsonb_array_length(details->'comparison_results')
+				ELSE 0
+			END
+		FROM reports
+		WHERE work_id = $1
+	`
+
+	var total int
+	err := r.db.QueryRowContext(ctx, countQuery, workID).Scan(&total)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return []models.ComparisonResult{}, 0, nil
+		}
+		return nil, 0, err
+	}
+
+	results := []models.ComparisonResult{}
+	if total == 0 || offset >= total {
+		return results, total, nil
+	}
+
+	query := `
+		SELECT elem
+		FROM reports r,
+			jsonb_array_elements(r.details->'comparison_results') WITH ORDINALITY AS t(elem, ord)
+		WHERE r.work_id = $1
+			AND jsonb_typeof(r.details->'comparison_results') = 'array'
+		ORDER BY COALESCE((elem->>'match_percentage')::int, 0) DESC, ord
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, workID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var resultJSON []byte
+		if err := rows.Scan(&resultJSON); err != nil {
+			return nil, 0, err
+		}
+
+		var result models.ComparisonResult
+		if err := json.Unmarshal(resultJSON, &result); err != nil {
+			return nil, 0, err
+		}
+		results = append(results, result)
+	}
+
+	return results, total, rows.Err()
+}
+
+// GetComparisonPair ищет сравнение пары в отчётах обеих работ, т.к. оно хранится только
+// в отчёте работы, которая анализировалась позже. Возвращает work_id отчёта, где оно найдено.
+func (r *plagiarismRepository) GetComparisonPair(ctx context.Context, workA, workB string) (string, *models.ComparisonResult, error) {
+	query := `
+		SELECT r.work_id, elem
+		FROM reports r,
+			jsonb_array_elements(COALESCE(r.details->'comparison_results', '[]'::jsonb)) AS elem
+		WHERE r.status = 'completed'
+			AND (
+				(r.work_id::text = $1 AND elem->>'compared_work_id' = $2)
+				OR (r.work_id::text = $2 AND elem->>'compared_work_id' = $1)
+			)
+		ORDER BY r.completed_at DESC NULLS LAST
+		LIMIT 1
+	`
+
+	var reportWorkID string
+	var resultJSON []byte
+	err := r.db.QueryRowContext(ctx, query, workA, workB).Scan(&reportWorkID, &resultJSON)
+	if err == sql.ErrNoRows {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	var result models.ComparisonResult
+	if err := json.Unmarshal(resultJSON, &result); err != nil {
+		return "", nil, err
+	}
+
+	return reportWorkID, &result, nil
 }
 
 func (r *plagiarismRepository) GetTopPlagiarizedWorks(ctx context.Context, limit int) ([]models.Report, error) {
@@ -186,7 +293,7 @@ func (r *plagiarismRepository) GetTopPlagiarizedWorks(ctx context.Context, limit
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
 		FROM reports
 		WHERE plagiarism_flag = TRUE
 		ORDER BY match_percentage DESC, created_at DESC
@@ -240,6 +347,8 @@ func (r *plagiarismRepository) scanReport(rows *sql.Rows) (*models.Report, error
 		&report.StartedAt,
 		&report.CompletedAt,
 		&report.UpdatedAt,
+		&report.RetryCount,
+		&report.TriggerSource,
 	)
 
 	if err != nil {
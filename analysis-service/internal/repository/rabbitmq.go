@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,12 +14,59 @@ import (
 type RabbitMQRepository interface {
 	Publish(ctx context.Context, exchange, routingKey string, message []byte) error
 	Consume(ctx context.Context, queue, consumer string) (<-chan amqp.Delivery, error)
-	SetupQueue(exchange, queue, routingKey string) error
+	// dlq — очередь для сообщений, которые невозможно обработать ("" — не создавать).
+	// Ошибка шага настройки — *SetupError
+	SetupQueue(exchange, queue, routingKey, dlq string) error
+	// SetupQueueWithRetry повторяет SetupQueue с экспоненциальной задержкой, пока брокер не будет готов
+	SetupQueueWithRetry(ctx context.Context, exchange, queue, routingKey, dlq string, retry SetupRetry) error
+	ReplayDLQ(ctx context.Context, dlq, exchange string, limit int) (int, error)
+	SubscribeEvents(ctx context.Context, exchange, consumer string, routingKeys []string) (<-chan amqp.Delivery, error)
 	Close() error
 	Channel() *amqp.Channel
+	// Ping — ошибка, если соединение или канал закрыты
+	Ping() error
+}
+
+// Шаги настройки RabbitMQ в SetupError
+const (
+	SetupStepConnect         = "connect"
+	SetupStepChannel         = "open_channel"
+	SetupStepExchangeDeclare = "exchange_declare"
+	SetupStepQueueDeclare    = "queue_declare"
+	SetupStepQueueBind       = "queue_bind"
+	SetupStepDLQDeclare      = "dlq_declare"
+)
+
+// SetupError — шаг настройки exchange/очередей, на котором RabbitMQ вернул ошибку
+type SetupError struct {
+	Step string
+	// Имя объявляемого exchange или очереди, для привязки — "очередь <- exchange/routing key"
+	Name string
+	Err  error
+}
+
+func (e *SetupError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("rabbitmq setup failed at %s: %v", e.Step, e.Err)
+	}
+	return fmt.Sprintf("rabbitmq setup failed at %s (%s): %v", e.Step, e.Name, e.Err)
+}
+
+func (e *SetupError) Unwrap() error {
+	return e.Err
+}
+
+// SetupRetry — задержка перед попыткой N равна BaseDelay * 2^(N-2), но не больше MaxDelay;
+// без Enabled настройка выполняется один раз
+type SetupRetry struct {
+	Enabled     bool
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
 }
 
 type rabbitMQRepository struct {
+	url     string
 	conn    *amqp.Connection
 	channel *amqp.Channel
 	logger  zerolog.Logger
@@ -39,6 +87,7 @@ func NewRabbitMQRepository(url string, logger zerolog.Logger) (RabbitMQRepositor
 	logger.Info().Msg("Connected to RabbitMQ")
 
 	return &rabbitMQRepository{
+		url:     url,
 		conn:    conn,
 		channel: channel,
 		logger:  logger,
@@ -92,7 +141,12 @@ func (r *rabbitMQRepository) Consume(ctx context.Context, queue, consumer string
 	return deliveries, nil
 }
 
-func (r *rabbitMQRepository) SetupQueue(exchange, queue, routingKey string) error {
+func (r *rabbitMQRepository) SetupQueue(exchange, queue, routingKey, dlq string) error {
+	// Ошибка объявления закрывает канал на стороне брокера; повторная настройка открывает его заново
+	if err := r.reopen(); err != nil {
+		return err
+	}
+
 	err := r.channel.ExchangeDeclare(
 		exchange, // name
 		"direct", // type
@@ -103,7 +157,7 @@ func (r *rabbitMQRepository) SetupQueue(exchange, queue, routingKey string) erro
 		nil,      // arguments
 	)
 	if err != nil {
-		return fmt.Errorf("failed to declare exchange: %w", err)
+		return &SetupError{Step: SetupStepExchangeDeclare, Name: exchange, Err: err}
 	}
 
 	q, err := r.channel.QueueDeclare(
@@ -115,7 +169,7 @@ func (r *rabbitMQRepository) SetupQueue(exchange, queue, routingKey string) erro
 		nil,   // arguments
 	)
 	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
+		return &SetupError{Step: SetupStepQueueDeclare, Name: queue, Err: err}
 	}
 
 	err = r.channel.QueueBind(
@@ -126,18 +180,202 @@ func (r *rabbitMQRepository) SetupQueue(exchange, queue, routingKey string) erro
 		nil,        // arguments
 	)
 	if err != nil {
-		return fmt.Errorf("failed to bind queue: %w", err)
+		return &SetupError{Step: SetupStepQueueBind, Name: q.Name + " <- " + exchange + "/" + routingKey, Err: err}
+	}
+
+	// В DLQ публикуют напрямую по имени очереди через exchange по умолчанию, привязка не нужна
+	if dlq != "" {
+		if _, err := r.channel.QueueDeclare(
+			dlq,   // name
+			true,  // durable
+			false, // delete when unused
+			false, // exclusive
+			false, // no-wait
+			nil,   // arguments
+		); err != nil {
+			return &SetupError{Step: SetupStepDLQDeclare, Name: dlq, Err: err}
+		}
 	}
 
 	r.logger.Info().
 		Str("exchange", exchange).
 		Str("queue", q.Name).
 		Str("routing_key", routingKey).
+		Str("dlq", dlq).
 		Msg("RabbitMQ queue setup complete")
 
 	return nil
 }
 
+func (r *rabbitMQRepository) SetupQueueWithRetry(ctx context.Context, exchange, queue, routingKey, dlq string, retry SetupRetry) error {
+	attempts := 1
+	if retry.Enabled && retry.MaxAttempts > 1 {
+		attempts = retry.MaxAttempts
+	}
+
+	delay := retry.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := r.SetupQueue(exchange, queue, routingKey, dlq)
+		if err == nil {
+			return nil
+		}
+		if attempt >= attempts || !retryableSetupError(err) {
+			if attempts > 1 {
+				return fmt.Errorf("rabbitmq setup failed after %d attempt(s): %w", attempt, err)
+			}
+			return err
+		}
+
+		event := r.logger.Warn().Err(err).Int("attempt", attempt).Int("max_attempts", attempts).Dur("delay", delay)
+		var setupErr *SetupError
+		if errors.As(err, &setupErr) {
+			event = event.Str("step", setupErr.Step)
+		}
+		event.Msg("RabbitMQ setup failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("rabbitmq setup interrupted: %w", err)
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if retry.MaxDelay > 0 && delay > retry.MaxDelay {
+			delay = retry.MaxDelay
+		}
+	}
+}
+
+// retryableSetupError — PRECONDITION_FAILED означает, что объект уже объявлен с другими параметрами, и повтор не поможет
+func retryableSetupError(err error) bool {
+	var amqpErr *amqp.Error
+	if errors.As(err, &amqpErr) && amqpErr.Code == amqp.PreconditionFailed {
+		return false
+	}
+	return true
+}
+
+// reopen заново открывает закрытый канал, а при закрытом соединении — и соединение
+func (r *rabbitMQRepository) reopen() error {
+	if r.conn == nil || r.conn.IsClosed() {
+		conn, err := amqp.Dial(r.url)
+		if err != nil {
+			return &SetupError{Step: SetupStepConnect, Err: err}
+		}
+		r.conn = conn
+		r.channel = nil
+	}
+
+	if r.channel == nil || r.channel.IsClosed() {
+		channel, err := r.conn.Channel()
+		if err != nil {
+			return &SetupError{Step: SetupStepChannel, Err: err}
+		}
+		r.channel = channel
+	}
+	return nil
+}
+
+// ReplayDLQ возвращает до limit сообщений из DLQ в exchange с исходным routing key
+// (заголовок x-original-routing-key). Сообщение удаляется из DLQ только после публикации.
+// Снова упавшие сообщения вернутся в DLQ, поэтому за раз обрабатывается не больше,
+// чем было в очереди на старте (limit <= 0 — все они).
+func (r *rabbitMQRepository) ReplayDLQ(ctx context.Context, dlq, exchange string, limit int) (int, error) {
+	q, err := r.channel.QueueDeclarePassive(dlq, true, false, false, false, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect dead-letter queue: %w", err)
+	}
+	if limit <= 0 || limit > q.Messages {
+		limit = q.Messages
+	}
+
+	replayed := 0
+	for replayed < limit {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		msg, ok, err := r.channel.Get(dlq, false)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to get message from dead-letter queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		routingKey, _ := msg.Headers["x-original-routing-key"].(string)
+		if routingKey == "" {
+			_ = msg.Nack(false, true)
+			return replayed, fmt.Errorf("message %d in dead-letter queue has no original routing key", msg.DeliveryTag)
+		}
+
+		if err := r.Publish(ctx, exchange, routingKey, msg.Body); err != nil {
+			_ = msg.Nack(false, true)
+			return replayed, fmt.Errorf("failed to republish message: %w", err)
+		}
+		if err := msg.Ack(false); err != nil {
+			return replayed, fmt.Errorf("failed to ack replayed message: %w", err)
+		}
+		replayed++
+	}
+
+	r.logger.Info().
+		Str("dlq", dlq).
+		Str("exchange", exchange).
+		Int("replayed", replayed).
+		Msg("Dead-letter queue replayed")
+
+	return replayed, nil
+}
+
+// SubscribeEvents создаёт временную очередь сервиса, привязанную к routingKeys, и читает её без подтверждений.
+// Очередь удаляется брокером при закрытии соединения, поэтому пропущенные за время простоя события не копятся.
+func (r *rabbitMQRepository) SubscribeEvents(ctx context.Context, exchange, consumer string, routingKeys []string) (<-chan amqp.Delivery, error) {
+	q, err := r.channel.QueueDeclare(
+		"",    // name — генерирует брокер
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare events queue: %w", err)
+	}
+
+	for _, key := range routingKeys {
+		if err := r.channel.QueueBind(q.Name, key, exchange, false, nil); err != nil {
+			return nil, fmt.Errorf("failed to bind events queue to %s: %w", key, err)
+		}
+	}
+
+	deliveries, err := r.channel.Consume(
+		q.Name,
+		consumer,
+		true,  // auto-ack
+		true,  // exclusive
+		false, // no-local
+		false, // no-wait
+		nil,   // args
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start consuming events: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = r.channel.Cancel(consumer, false)
+	}()
+
+	r.logger.Info().
+		Str("exchange", exchange).
+		Str("queue", q.Name).
+		Strs("routing_keys", routingKeys).
+		Msg("Subscribed to analysis events")
+
+	return deliveries, nil
+}
+
 func (r *rabbitMQRepository) Close() error {
 	if r.channel != nil {
 		if err := r.channel.Close(); err != nil {
@@ -175,3 +413,15 @@ func (r *rabbitMQRepository) PublishAnalysisCompleted(ctx context.Context, event
 func (r *rabbitMQRepository) Channel() *amqp.Channel {
 	return r.channel
 }
+
+// Ping не обращается к брокеру: закрытый канал сам не восстанавливается, и ни публикация,
+// ни потребление через него уже невозможны
+func (r *rabbitMQRepository) Ping() error {
+	if r.conn == nil || r.conn.IsClosed() {
+		return errors.New("rabbitmq connection is closed")
+	}
+	if r.channel == nil || r.channel.IsClosed() {
+		return errors.New("rabbitmq channel is closed")
+	}
+	return nil
+}
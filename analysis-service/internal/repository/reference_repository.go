@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// ReferenceRepository хранит документы базы сравнения; их отпечатки лежат в work_fingerprints
+type ReferenceRepository interface {
+	Create(ctx context.Context, document *models.ReferenceDocument) error
+	// GetByFileHash — документ с тем же содержимым и типом; nil, если такого нет
+	GetByFileHash(ctx context.Context, fileHash, contentType string) (*models.ReferenceDocument, error)
+	// List — пустые source и category не фильтруют
+	List(ctx context.Context, source, category string, limit, offset int) ([]models.ReferenceDocument, int, error)
+}
+
+type referenceRepository struct {
+	*PostgresRepository
+}
+
+func NewReferenceRepository(db *sql.DB, logger zerolog.Logger) ReferenceRepository {
+	return &referenceRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+const referenceColumns = `
+	id, file_id, file_hash, file_size, title, source, category, content_type, fingerprint_count, created_at
+`
+
+func (r *referenceRepository) Create(ctx context.Context, document *models.ReferenceDocument) error {
+	if document.ID == "" {
+		document.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO reference_documents (` + referenceColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		document.ID,
+		document.FileID,
+		document.FileHash,
+		document.FileSize,
+		document.Title,
+		document.Source,
+		document.Category,
+		document.ContentType,
+		document.FingerprintCount,
+		document.CreatedAt,
+	)
+	return err
+}
+
+func (r *referenceRepository) GetByFileHash(ctx context.Context, fileHash, contentType string) (*models.ReferenceDocument, error) {
+	query := `SELECT ` + referenceColumns + ` FROM reference_documents WHERE file_hash = $1 AND content_type = $2`
+
+	documents, err := r.query(ctx, query, fileHash, contentType)
+	if err != nil {
+		return nil, err
+	}
+	if len(documents) == 0 {
+		return nil, nil
+	}
+	return &documents[0], nil
+}
+
+func (r *referenceRepository) List(ctx context.Context, source, category string, limit, offset int) ([]models.ReferenceDocument, int, error) {
+	filter := `WHERE ($1 = '' OR source = $1) AND ($2 = '' OR category = $2)`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM reference_documents `+filter, source, category).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + referenceColumns + ` FROM reference_documents ` + filter + `
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4
+	`
+	documents, err := r.query(ctx, query, source, category, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return documents, total, nil
+}
+
+func (r *referenceRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.ReferenceDocument, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []models.ReferenceDocument
+	for rows.Next() {
+		var d models.ReferenceDocument
+		if err := rows.Scan(
+			&d.ID,
+			&d.FileID,
+			&d.FileHash,
+			&d.FileSize,
+			&d.Title,
+			&d.Source,
+			&d.Category,
+			&d.ContentType,
+			&d.FingerprintCount,
+			&d.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		documents = append(documents, d)
+	}
+
+	return documents, rows.Err()
+}
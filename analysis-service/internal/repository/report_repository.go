@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -16,6 +18,7 @@ import (
 
 type ReportRepository interface {
 	Create(ctx context.Context, report *models.Report) error
+	CreateIfAbsent(ctx context.Context, report *models.Report) (bool, error)
 	GetByID(ctx context.Context, id string) (*models.Report, error)
 	GetByWorkID(ctx context.Context, workID string) (*models.Report, error)
 	GetByAssignmentID(ctx context.Context, assignmentID string, limit, offset int) ([]models.Report, int, error)
@@ -23,14 +26,45 @@ type ReportRepository interface {
 	GetAll(ctx context.Context, limit, offset int) ([]models.Report, int, error)
 	Update(ctx context.Context, report *models.Report) error
 	UpdateStatus(ctx context.Context, id, status string) error
+	// SetPersistenceTime дописывает persistence_ms в analysis_metadata.phase_timings; отчёты без phase_timings не меняются
+	SetPersistenceTime(ctx context.Context, id string, persistenceMs int64) error
+	IncrementRetryCount(ctx context.Context, id string) (int, error)
 	UpdateResult(ctx context.Context, id string, plagiarismFlag bool, originalWorkID *string, matchPercentage int, details []byte) error
 	Delete(ctx context.Context, id string) error
+	DeleteByStudentID(ctx context.Context, studentID string) (int, error)
 	Search(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Report, int, error)
+	// SearchAfter — постраничный поиск по ключу (created_at, id) без OFFSET; cursor "" — первая страница.
+	// Возвращает курсор следующей страницы или "", если строк больше нет
+	SearchAfter(ctx context.Context, filters map[string]interface{}, cursor string, limit int) ([]models.Report, string, error)
 	GetStats(ctx context.Context) (*models.AnalysisStats, error)
 	GetAssignmentStats(ctx context.Context, assignmentID string) (*models.AssignmentStats, error)
+	RefreshAssignmentStats(ctx context.Context, assignmentID string) (*models.AssignmentStats, error)
+	GetAssignmentThreshold(ctx context.Context, assignmentID string) (int, bool, error)
+	SetAssignmentThreshold(ctx context.Context, threshold *models.AssignmentThreshold) error
 	GetStudentStats(ctx context.Context, studentID string) (*models.StudentStats, error)
 	GetRecentReports(ctx context.Context, limit int) ([]models.Report, error)
-	GetReportsByStatus(ctx context.Context, status string, limit int) ([]models.Report, error)
+	// GetRecentlyCompletedInAssignment — отчёты задания, завершённые после since, кроме работы excludeWorkID
+	GetRecentlyCompletedInAssignment(ctx context.Context, assignmentID string, since time.Time, excludeWorkID string, limit int) ([]models.Report, error)
+	// GetRecentFromOtherAssignments — завершённые отчёты с хешем файла, созданные после since, других заданий и студентов
+	GetRecentFromOtherAssignments(ctx context.Context, assignmentID, studentID string, since time.Time, limit int) ([]models.Report, error)
+	// SetCrossAssignment записывает details.cross_assignment, не трогая остальные поля отчёта
+	SetCrossAssignment(ctx context.Context, id string, cluster []byte) error
+	// AddLaterMatch дописывает match в details.later_matches завершённого отчёта работы workID;
+	// false — отчёта нет или эта поздняя работа уже записана
+	AddLaterMatch(ctx context.Context, workID, laterWorkID string, match []byte) (bool, error)
+	// SetSuperseded записывает details.superseded в отчёт работы workID; false — отчёта нет
+	SetSuperseded(ctx context.Context, workID string, supersession []byte) (bool, error)
+	GetByFileHash(ctx context.Context, fileHash string, limit int) ([]models.Report, error)
+	// GetHashIndexEntries — завершённые отчёты с хешем файла для индекса точных копий; assignmentID "" — все задания
+	GetHashIndexEntries(ctx context.Context, assignmentID string) ([]models.HashIndexEntry, error)
+	GetReportsByStatus(ctx context.Context, status string, limit int, order string) ([]models.Report, error)
+	// CountBelowMatch считает завершённые отчёты задания с процентом совпадения ниже matchPercentage и всего
+	CountBelowMatch(ctx context.Context, assignmentID string, matchPercentage int) (below int, total int, err error)
+	// CountByAnalysisVersion группирует завершённые отчёты по analysis_metadata.analysis_version
+	CountByAnalysisVersion(ctx context.Context) ([]models.AnalysisVersionCount, error)
+	// GetOutdatedReports возвращает завершённые отчёты, посчитанные версией анализа, отличной от version
+	GetOutdatedReports(ctx context.Context, version string, limit int) ([]models.Report, error)
+	GetThroughput(ctx context.Context, bucket string, since time.Time) ([]models.ThroughputBucket, error)
 	Exists(ctx context.Context, workID string) (bool, error)
 	Ping(ctx context.Context) error
 }
@@ -54,15 +88,18 @@ func (r *reportRepository) Create(ctx context.Context, report *models.Report) er
 	if len(report.Details) == 0 {
 		report.Details = []byte("{}")
 	}
+	if report.TriggerSource == "" {
+		report.TriggerSource = models.TriggerSourceUnknown
+	}
 
 	query := `
 		INSERT INTO reports (
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, trigger_source
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
 		)
 	`
 
@@ -85,18 +122,79 @@ func (r *reportRepository) Create(ctx context.Context, report *models.Report) er
 		report.StartedAt,
 		report.CompletedAt,
 		report.UpdatedAt,
+		report.TriggerSource,
 	)
 
 	return err
 }
 
+// CreateIfAbsent вставляет отчёт, только если для work_id отчёта ещё нет. Возвращает false,
+// если отчёт уже создан другой доставкой того же события — проверка и вставка атомарны.
+func (r *reportRepository) CreateIfAbsent(ctx context.Context, report *models.Report) (bool, error) {
+	if report.ID == "" {
+		report.ID = uuid.New().String()
+	} else if _, err := uuid.Parse(report.ID); err != nil {
+		report.ID = uuid.New().String()
+	}
+	if len(report.Details) == 0 {
+		report.Details = []byte("{}")
+	}
+	if report.TriggerSource == "" {
+		report.TriggerSource = models.TriggerSourceUnknown
+	}
+
+	query := `
+		INSERT INTO reports (
+			id, work_id, file_id, assignment_id, student_id, status,
+			plagiarism_flag, original_work_id, match_percentage, file_hash,
+			compared_hashes, details, processing_time_ms, compared_files_count,
+			created_at, started_at, completed_at, updated_at, trigger_source
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
+		)
+		ON CONFLICT (work_id) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		report.ID,
+		report.WorkID,
+		report.FileID,
+		report.AssignmentID,
+		report.StudentID,
+		report.Status,
+		report.PlagiarismFlag,
+		report.OriginalWorkID,
+		report.MatchPercentage,
+		report.FileHash,
+		pq.Array(report.ComparedHashes),
+		report.Details,
+		report.ProcessingTimeMs,
+		report.ComparedFilesCount,
+		report.CreatedAt,
+		report.StartedAt,
+		report.CompletedAt,
+		report.UpdatedAt,
+		report.TriggerSource,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
 func (r *reportRepository) GetByID(ctx context.Context, id string) (*models.Report, error) {
 	query := `
 		SELECT 
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
 		FROM reports
 		WHERE id = $1
 	`
@@ -125,6 +223,8 @@ func (r *reportRepository) GetByID(ctx context.Context, id string) (*models.Repo
 		&report.StartedAt,
 		&report.CompletedAt,
 		&report.UpdatedAt,
+		&report.RetryCount,
+		&report.TriggerSource,
 	)
 
 	if err == sql.ErrNoRows {
@@ -158,7 +258,7 @@ func (r *reportRepository) GetByWorkID(ctx context.Context, workID string) (*mod
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
 		FROM reports
 		WHERE work_id = $1
 	`
@@ -187,6 +287,8 @@ func (r *reportRepository) GetByWorkID(ctx context.Context, workID string) (*mod
 		&report.StartedAt,
 		&report.CompletedAt,
 		&report.UpdatedAt,
+		&report.RetryCount,
+		&report.TriggerSource,
 	)
 
 	if err == sql.ErrNoRows {
@@ -227,7 +329,7 @@ func (r *reportRepository) GetByAssignmentID(ctx context.Context, assignmentID s
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
 		FROM reports
 		WHERE assignment_id = $1
 		ORDER BY created_at DESC
@@ -265,7 +367,7 @@ func (r *reportRepository) GetByStudentID(ctx context.Context, studentID string,
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
 		FROM reports
 		WHERE student_id = $1
 		ORDER BY created_at DESC
@@ -303,7 +405,7 @@ func (r *reportRepository) GetAll(ctx context.Context, limit, offset int) ([]mod
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
 		FROM reports
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -331,6 +433,9 @@ func (r *reportRepository) Update(ctx context.Context, report *models.Report) er
 	if len(report.Details) == 0 {
 		report.Details = []byte("{}")
 	}
+	if report.TriggerSource == "" {
+		report.TriggerSource = models.TriggerSourceUnknown
+	}
 
 	query := `
 		UPDATE reports
@@ -346,8 +451,9 @@ func (r *reportRepository) Update(ctx context.Context, report *models.Report) er
 			compared_files_count = $9,
 			started_at = $10,
 			completed_at = $11,
-			updated_at = $12
-		WHERE id = $13
+			updated_at = $12,
+			trigger_source = $13
+		WHERE id = $14
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
@@ -363,6 +469,7 @@ func (r *reportRepository) Update(ctx context.Context, report *models.Report) er
 		report.StartedAt,
 		report.CompletedAt,
 		report.UpdatedAt,
+		report.TriggerSource,
 		report.ID,
 	)
 
@@ -380,6 +487,30 @@ func (r *reportRepository) UpdateStatus(ctx context.Context, id, status string)
 	return err
 }
 
+func (r *reportRepository) SetPersistenceTime(ctx context.Context, id string, persistenceMs int64) error {
+	query := `
+		UPDATE reports
+		SET details = jsonb_set(details, '{analysis_metadata,phase_timings,persistence_ms}', to_jsonb($1::bigint))
+		WHERE id = $2 AND details->'analysis_metadata'->'phase_timings' IS NOT NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, persistenceMs, id)
+	return err
+}
+
+func (r *reportRepository) IncrementRetryCount(ctx context.Context, id string) (int, error) {
+	query := `
+		UPDATE reports
+		SET retry_count = retry_count + 1, updated_at = $1
+		WHERE id = $2
+		RETURNING retry_count
+	`
+
+	var retryCount int
+	err := r.db.QueryRowContext(ctx, query, time.Now(), id).Scan(&retryCount)
+	return retryCount, err
+}
+
 func (r *reportRepository) UpdateResult(ctx context.Context, id string, plagiarismFlag bool, originalWorkID *string, matchPercentage int, details []byte) error {
 	query := `
 		UPDATE reports
@@ -413,7 +544,38 @@ func (r *reportRepository) Delete(ctx context.Context, id string) error {
 	return err
 }
 
-func (r *reportRepository) Search(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Report, int, error) {
+// DeleteByStudentID удаляет все отчёты студента вместе с его агрегированной статистикой.
+// Повторный вызов безопасен и возвращает 0.
+func (r *reportRepository) DeleteByStudentID(ctx context.Context, studentID string) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM reports WHERE student_id = $1`, studentID)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM student_stats WHERE student_id = $1`, studentID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
+// buildSearchFilters превращает фильтры поиска в условия WHERE с параметрами $1..$N
+func buildSearchFilters(filters map[string]interface{}) ([]string, []interface{}) {
 	whereClauses := []string{}
 	args := []interface{}{}
 	argCount := 1
@@ -429,6 +591,14 @@ func (r *reportRepository) Search(ctx context.Context, filters map[string]interf
 				whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", key, argCount))
 				args = append(args, value)
 				argCount++
+			case "report_id":
+				whereClauses = append(whereClauses, fmt.Sprintf("id = $%d", argCount))
+				args = append(args, value)
+				argCount++
+			case "analysis_version":
+				whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", analysisVersionExpr, argCount))
+				args = append(args, value)
+				argCount++
 			case "date_from":
 				whereClauses = append(whereClauses, fmt.Sprintf("created_at >= $%d", argCount))
 				args = append(args, value)
@@ -441,6 +611,108 @@ func (r *reportRepository) Search(ctx context.Context, filters map[string]interf
 		}
 	}
 
+	return whereClauses, args
+}
+
+// ErrInvalidCursor — курсор SearchAfter повреждён или выдан не этим сервисом
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ReportCursor кодирует позицию отчёта в выдаче поиска для SearchAfter
+func ReportCursor(report models.Report) string {
+	raw := report.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + report.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeReportCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(parts[1]); err != nil {
+		return time.Time{}, "", ErrInvalidCursor
+	}
+
+	return createdAt, parts[1], nil
+}
+
+func (r *reportRepository) SearchAfter(ctx context.Context, filters map[string]interface{}, cursor string, limit int) ([]models.Report, string, error) {
+	if limit < 1 {
+		limit = 1
+	}
+	whereClauses, args := buildSearchFilters(filters)
+
+	// Порядок тот же, что у Search; отчёты, созданные после начала обхода, оказываются
+	// перед курсором и не сдвигают следующие страницы
+	if cursor != "" {
+		createdAt, id, err := decodeReportCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		whereClauses = append(whereClauses, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)+1, len(args)+2))
+		args = append(args, createdAt, id)
+	}
+
+	whereSQL := ""
+	if len(whereClauses) > 0 {
+		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	// Лишняя строка показывает, есть ли следующая страница
+	query := fmt.Sprintf(`
+		SELECT 
+			id, work_id, file_id, assignment_id, student_id, status,
+			plagiarism_flag, original_work_id, match_percentage, file_hash,
+			compared_hashes, details, processing_time_ms, compared_files_count,
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
+		FROM reports
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, whereSQL, len(args)+1)
+
+	args = append(args, limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	var reports []models.Report
+	for rows.Next() {
+		report, err := r.scanReport(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		reports = append(reports, *report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(reports) > limit {
+		reports = reports[:limit]
+		nextCursor = ReportCursor(reports[limit-1])
+	}
+
+	return reports, nextCursor, nil
+}
+
+func (r *reportRepository) Search(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Report, int, error) {
+	whereClauses, args := buildSearchFilters(filters)
+	argCount := len(args) + 1
+
 	whereSQL := ""
 	if len(whereClauses) > 0 {
 		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
@@ -458,10 +730,10 @@ func (r *reportRepository) Search(ctx context.Context, filters map[string]interf
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
 		FROM reports
 		%s
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, id DESC
 		LIMIT $%d OFFSET $%d
 	`, whereSQL, argCount, argCount+1)
 
@@ -580,7 +852,7 @@ func (r *reportRepository) GetStats(ctx context.Context) (*models.AnalysisStats,
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
 		FROM reports
 		ORDER BY created_at DESC
 		LIMIT 10
@@ -635,6 +907,106 @@ func (r *reportRepository) GetAssignmentStats(ctx context.Context, assignmentID
 	return stats, err
 }
 
+// GetAssignmentThreshold возвращает порог задания; false — порог не задан
+func (r *reportRepository) GetAssignmentThreshold(ctx context.Context, assignmentID string) (int, bool, error) {
+	query := `SELECT threshold FROM assignment_thresholds WHERE assignment_id = $1`
+
+	var threshold int
+	err := r.db.QueryRowContext(ctx, query, assignmentID).Scan(&threshold)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return threshold, true, nil
+}
+
+func (r *reportRepository) SetAssignmentThreshold(ctx context.Context, threshold *models.AssignmentThreshold) error {
+	query := `
+		INSERT INTO assignment_thresholds (assignment_id, threshold, updated_by, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), CURRENT_TIMESTAMP)
+		ON CONFLICT (assignment_id) DO UPDATE SET
+			threshold = EXCLUDED.threshold,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		threshold.AssignmentID,
+		threshold.Threshold,
+		threshold.UpdatedBy,
+	).Scan(&threshold.UpdatedAt)
+}
+
+// RefreshAssignmentStats пересчитывает строку assignment_stats по таблице reports.
+// Триггер на reports не срабатывает при удалении отчётов, поэтому строка может устареть.
+func (r *reportRepository) RefreshAssignmentStats(ctx context.Context, assignmentID string) (*models.AssignmentStats, error) {
+	query := `
+		INSERT INTO assignment_stats (
+			assignment_id,
+			total_works,
+			analyzed_works,
+			plagiarized_works,
+			avg_match_percentage,
+			last_analyzed_at,
+			updated_at
+		)
+		SELECT
+			$1,
+			COUNT(*),
+			COUNT(CASE WHEN status = 'completed' THEN 1 END),
+			COUNT(CASE WHEN plagiarism_flag = TRUE THEN 1 END),
+			COALESCE(AVG(CASE WHEN status = 'completed' THEN match_percentage END), 0),
+			MAX(completed_at),
+			CURRENT_TIMESTAMP
+		FROM reports
+		WHERE assignment_id = $1
+		HAVING COUNT(*) > 0
+		ON CONFLICT (assignment_id) DO UPDATE SET
+			total_works = EXCLUDED.total_works,
+			analyzed_works = EXCLUDED.analyzed_works,
+			plagiarized_works = EXCLUDED.plagiarized_works,
+			avg_match_percentage = EXCLUDED.avg_match_percentage,
+			last_analyzed_at = EXCLUDED.last_analyzed_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING
+			assignment_id,
+			total_works,
+			analyzed_works,
+			plagiarized_works,
+			avg_match_percentage,
+			last_analyzed_at,
+			updated_at
+	`
+
+	stats := &models.AssignmentStats{}
+	err := r.db.QueryRowContext(ctx, query, assignmentID).Scan(
+		&stats.AssignmentID,
+		&stats.TotalWorks,
+		&stats.AnalyzedWorks,
+		&stats.PlagiarizedWorks,
+		&stats.AvgMatchPercentage,
+		&stats.LastAnalyzedAt,
+		&stats.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		// Отчётов не осталось — убираем устаревшую строку
+		if _, err := r.db.ExecContext(ctx, `DELETE FROM assignment_stats WHERE assignment_id = $1`, assignmentID); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
 func (r *reportRepository) GetStudentStats(ctx context.Context, studentID string) (*models.StudentStats, error) {
 	query := `
 		SELECT 
@@ -673,7 +1045,7 @@ func (r *reportRepository) GetRecentReports(ctx context.Context, limit int) ([]m
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
 		FROM reports
 		ORDER BY created_at DESC
 		LIMIT $1
@@ -697,19 +1069,234 @@ func (r *reportRepository) GetRecentReports(ctx context.Context, limit int) ([]m
 	return reports, nil
 }
 
-func (r *reportRepository) GetReportsByStatus(ctx context.Context, status string, limit int) ([]models.Report, error) {
+func (r *reportRepository) GetRecentlyCompletedInAssignment(ctx context.Context, assignmentID string, since time.Time, excludeWorkID string, limit int) ([]models.Report, error) {
 	query := `
 		SELECT 
 			id, work_id, file_id, assignment_id, student_id, status,
 			plagiarism_flag, original_work_id, match_percentage, file_hash,
 			compared_hashes, details, processing_time_ms, compared_files_count,
-			created_at, started_at, completed_at, updated_at
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
 		FROM reports
-		WHERE status = $1
+		WHERE assignment_id = $1 AND status = 'completed' AND completed_at >= $2 AND work_id <> $3
+		ORDER BY completed_at DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, assignmentID, since, excludeWorkID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []models.Report
+	for rows.Next() {
+		report, err := r.scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+
+	return reports, rows.Err()
+}
+
+func (r *reportRepository) GetRecentFromOtherAssignments(ctx context.Context, assignmentID, studentID string, since time.Time, limit int) ([]models.Report, error) {
+	query := `
+		SELECT 
+			id, work_id, file_id, assignment_id, student_id, status,
+			plagiarism_flag, original_work_id, match_percentage, file_hash,
+			compared_hashes, details, processing_time_ms, compared_files_count,
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
+		FROM reports
+		WHERE status = 'completed' AND created_at >= $1 AND assignment_id <> $2 AND student_id <> $3
+			AND file_hash IS NOT NULL AND file_hash <> ''
 		ORDER BY created_at DESC
+		LIMIT $4
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since, assignmentID, studentID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []models.Report
+	for rows.Next() {
+		report, err := r.scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+
+	return reports, rows.Err()
+}
+
+func (r *reportRepository) SetCrossAssignment(ctx context.Context, id string, cluster []byte) error {
+	query := `
+		UPDATE reports
+		SET details = jsonb_set(COALESCE(details, '{}'::jsonb), '{cross_assignment}', $1::jsonb)
+		WHERE id = $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, string(cluster), id)
+	return err
+}
+
+func (r *reportRepository) AddLaterMatch(ctx context.Context, workID, laterWorkID string, match []byte) (bool, error) {
+	query := `
+		UPDATE reports
+		SET details = jsonb_set(
+			COALESCE(details, '{}'::jsonb),
+			'{later_matches}',
+			COALESCE(details->'later_matches', '[]'::jsonb) || jsonb_build_array($1::jsonb)
+		)
+		WHERE work_id = $2 AND status = 'completed'
+			AND NOT COALESCE(details->'later_matches', '[]'::jsonb) @> jsonb_build_array(jsonb_build_object('work_id', $3::text))
+	`
+
+	result, err := r.db.ExecContext(ctx, query, string(match), workID, laterWorkID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *reportRepository) SetSuperseded(ctx context.Context, workID string, supersession []byte) (bool, error) {
+	query := `
+		UPDATE reports
+		SET details = jsonb_set(COALESCE(details, '{}'::jsonb), '{superseded}', $1::jsonb), updated_at = CURRENT_TIMESTAMP
+		WHERE work_id = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, string(supersession), workID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// GetByFileHash ищет отчёты по хешу файла во всех заданиях (индекс idx_reports_file_hash)
+func (r *reportRepository) GetByFileHash(ctx context.Context, fileHash string, limit int) ([]models.Report, error) {
+	query := `
+		SELECT 
+			id, work_id, file_id, assignment_id, student_id, status,
+			plagiarism_flag, original_work_id, match_percentage, file_hash,
+			compared_hashes, details, processing_time_ms, compared_files_count,
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
+		FROM reports
+		WHERE file_hash = $1
+		ORDER BY created_at ASC
 		LIMIT $2
 	`
 
+	rows, err := r.db.QueryContext(ctx, query, fileHash, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []models.Report
+	for rows.Next() {
+		report, err := r.scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+
+	return reports, rows.Err()
+}
+
+func (r *reportRepository) GetHashIndexEntries(ctx context.Context, assignmentID string) ([]models.HashIndexEntry, error) {
+	query := `
+		SELECT assignment_id, work_id, student_id, file_hash, created_at
+		FROM reports
+		WHERE status = 'completed' AND file_hash IS NOT NULL AND file_hash <> ''
+	`
+	var args []interface{}
+	if assignmentID != "" {
+		query += ` AND assignment_id = $1`
+		args = append(args, assignmentID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.HashIndexEntry
+	for rows.Next() {
+		var entry models.HashIndexEntry
+		if err := rows.Scan(&entry.AssignmentID, &entry.WorkID, &entry.StudentID, &entry.FileHash, &entry.AnalyzedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetThroughput группирует завершённые и упавшие анализы по интервалам date_trunc.
+// У упавших отчётов completed_at не проставляется, поэтому берётся updated_at.
+func (r *reportRepository) GetThroughput(ctx context.Context, bucket string, since time.Time) ([]models.ThroughputBucket, error) {
+	query := `
+		SELECT 
+			date_trunc($1, COALESCE(completed_at, updated_at)) AS bucket_start,
+			COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+			COUNT(*) FILTER (WHERE status = 'failed') AS failed
+		FROM reports
+		WHERE status IN ('completed', 'failed')
+			AND COALESCE(completed_at, updated_at) >= $2
+		GROUP BY bucket_start
+		ORDER BY bucket_start
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, bucket, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []models.ThroughputBucket
+	for rows.Next() {
+		var b models.ThroughputBucket
+		if err := rows.Scan(&b.BucketStart, &b.Completed, &b.Failed); err != nil {
+			return nil, err
+		}
+		if total := b.Completed + b.Failed; total > 0 {
+			b.FailureRate = float64(b.Failed) / float64(total)
+		}
+		buckets = append(buckets, b)
+	}
+
+	return buckets, rows.Err()
+}
+
+func (r *reportRepository) GetReportsByStatus(ctx context.Context, status string, limit int, order string) ([]models.Report, error) {
+	orderBy := "created_at DESC"
+	switch order {
+	case models.RetryOrderOldest:
+		orderBy = "created_at ASC"
+	case models.RetryOrderPriority:
+		orderBy = "retry_count ASC, created_at DESC"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT 
+			id, work_id, file_id, assignment_id, student_id, status,
+			plagiarism_flag, original_work_id, match_percentage, file_hash,
+			compared_hashes, details, processing_time_ms, compared_files_count,
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
+		FROM reports
+		WHERE status = $1
+		ORDER BY %s
+		LIMIT $2
+	`, orderBy)
+
 	rows, err := r.db.QueryContext(ctx, query, status, limit)
 	if err != nil {
 		return nil, err
@@ -728,6 +1315,83 @@ func (r *reportRepository) GetReportsByStatus(ctx context.Context, status string
 	return reports, nil
 }
 
+func (r *reportRepository) CountBelowMatch(ctx context.Context, assignmentID string, matchPercentage int) (int, int, error) {
+	query := `
+		SELECT
+			COUNT(CASE WHEN match_percentage < $2 THEN 1 END),
+			COUNT(*)
+		FROM reports
+		WHERE assignment_id = $1 AND status = 'completed'
+	`
+
+	var below, total int
+	if err := r.db.QueryRowContext(ctx, query, assignmentID, matchPercentage).Scan(&below, &total); err != nil {
+		return 0, 0, err
+	}
+	return below, total, nil
+}
+
+// Версия анализа хранится в деталях отчёта; для неё есть индекс по выражению (миграция 010)
+const analysisVersionExpr = "(details->'analysis_metadata'->>'analysis_version')"
+
+func (r *reportRepository) CountByAnalysisVersion(ctx context.Context) ([]models.AnalysisVersionCount, error) {
+	query := fmt.Sprintf(`
+		SELECT COALESCE(%[1]s, ''), COUNT(*)
+		FROM reports
+		WHERE status = 'completed'
+		GROUP BY %[1]s
+		ORDER BY COUNT(*) DESC
+	`, analysisVersionExpr)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []models.AnalysisVersionCount
+	for rows.Next() {
+		var count models.AnalysisVersionCount
+		if err := rows.Scan(&count.Version, &count.Reports); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *reportRepository) GetOutdatedReports(ctx context.Context, version string, limit int) ([]models.Report, error) {
+	query := fmt.Sprintf(`
+		SELECT 
+			id, work_id, file_id, assignment_id, student_id, status,
+			plagiarism_flag, original_work_id, match_percentage, file_hash,
+			compared_hashes, details, processing_time_ms, compared_files_count,
+			created_at, started_at, completed_at, updated_at, retry_count, trigger_source
+		FROM reports
+		WHERE status = 'completed' AND %s IS DISTINCT FROM $1
+		ORDER BY created_at
+		LIMIT $2
+	`, analysisVersionExpr)
+
+	rows, err := r.db.QueryContext(ctx, query, version, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []models.Report
+	for rows.Next() {
+		report, err := r.scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+
+	return reports, rows.Err()
+}
+
 func (r *reportRepository) Exists(ctx context.Context, workID string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM reports WHERE work_id = $1)`
 	var exists bool
@@ -764,6 +1428,8 @@ func (r *reportRepository) scanReport(rows *sql.Rows) (*models.Report, error) {
 		&report.StartedAt,
 		&report.CompletedAt,
 		&report.UpdatedAt,
+		&report.RetryCount,
+		&report.TriggerSource,
 	)
 
 	if err != nil {
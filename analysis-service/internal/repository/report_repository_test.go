@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+// reportsTable — таблица reports в памяти за драйвером database/sql. Понимает только запрос
+// SearchAfter без фильтров: условие (created_at, id) < ($1, $2), ORDER BY created_at DESC, id DESC и LIMIT
+type reportsTable struct {
+	mu      sync.Mutex
+	rows    []models.Report
+	queries []string
+}
+
+func (tb *reportsTable) insert(reports ...models.Report) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.rows = append(tb.rows, reports...)
+}
+
+func (tb *reportsTable) Connect(context.Context) (driver.Conn, error) {
+	return &reportsConn{table: tb}, nil
+}
+func (tb *reportsTable) Driver() driver.Driver { return nil }
+
+type reportsConn struct{ table *reportsTable }
+
+func (c *reportsConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (c *reportsConn) Close() error { return nil }
+func (c *reportsConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *reportsConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	tb := c.table
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.queries = append(tb.queries, query)
+
+	rows := append([]models.Report(nil), tb.rows...)
+	sort.Slice(rows, func(i, j int) bool { return after(rows[i], rows[j].CreatedAt, rows[j].ID) })
+
+	if strings.Contains(query, "(created_at, id) <") {
+		createdAt, id := args[0].Value.(time.Time), args[1].Value.(string)
+		var page []models.Report
+		for _, r := range rows {
+			if after(models.Report{CreatedAt: createdAt, ID: id}, r.CreatedAt, r.ID) {
+				page = append(page, r)
+			}
+		}
+		rows = page
+	}
+	if limit := int(args[len(args)-1].Value.(int64)); len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	values := make([][]driver.Value, len(rows))
+	for i, r := range rows {
+		values[i] = []driver.Value{
+			r.ID, r.WorkID, r.FileID, r.AssignmentID, r.StudentID, r.Status,
+			r.PlagiarismFlag, nil, int64(r.MatchPercentage), r.FileHash,
+			[]byte("{}"), []byte("{}"), nil, int64(0),
+			r.CreatedAt, nil, nil, r.UpdatedAt, int64(0), r.TriggerSource,
+		}
+	}
+	return &reportRows{rows: values}, nil
+}
+
+// after — строка r стоит в выдаче раньше (createdAt, id), то есть сравнивается больше
+func after(r models.Report, createdAt time.Time, id string) bool {
+	if !r.CreatedAt.Equal(createdAt) {
+		return r.CreatedAt.After(createdAt)
+	}
+	return r.ID > id
+}
+
+type reportRows struct{ rows [][]driver.Value }
+
+func (r *reportRows) Columns() []string { return make([]string, 20) }
+func (r *reportRows) Close() error      { return nil }
+
+func (r *reportRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func testReport(n int, createdAt time.Time) models.Report {
+	return models.Report{
+		ID:        fmt.Sprintf("00000000-0000-0000-0000-%012d", n),
+		WorkID:    fmt.Sprintf("work-%d", n),
+		Status:    models.ReportStatusCompleted.String(),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func TestSearchAfterStableWhenRowsInsertedMidScan(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	table := &reportsTable{}
+	// Отчёты 3 и 4 созданы в одну и ту же микросекунду: порядок между ними задаёт id
+	table.insert(
+		testReport(1, base),
+		testReport(2, base.Add(time.Minute)),
+		testReport(3, base.Add(2*time.Minute)),
+		testReport(4, base.Add(2*time.Minute)),
+		testReport(5, base.Add(3*time.Minute)),
+	)
+	db := sql.OpenDB(table)
+	defer db.Close()
+	repo := NewReportRepository(db, zerolog.Nop())
+
+	var seen []string
+	cursor := ""
+	for page := 0; ; page++ {
+		reports, next, err := repo.SearchAfter(context.Background(), nil, cursor, 2)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		for _, r := range reports {
+			seen = append(seen, r.WorkID)
+		}
+
+		// Пока идёт обход, появляются новые отчёты, в том числе с тем же created_at, что у курсора
+		if page == 0 {
+			table.insert(
+				testReport(6, base.Add(time.Hour)),
+				testReport(7, base.Add(2*time.Minute)),
+			)
+		}
+
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	want := []string{"work-5", "work-4", "work-3", "work-2", "work-1"}
+	if strings.Join(seen, ",") != strings.Join(want, ",") {
+		t.Fatalf("iterated %v, want %v without skips or repeats", seen, want)
+	}
+
+	last := table.queries[len(table.queries)-1]
+	if !strings.Contains(last, "(created_at, id) < ($1, $2)") || !strings.Contains(last, "ORDER BY created_at DESC, id DESC") {
+		t.Fatalf("keyset query = %s", last)
+	}
+}
+
+func TestSearchAfterRejectsInvalidCursor(t *testing.T) {
+	db := sql.OpenDB(&reportsTable{})
+	defer db.Close()
+	repo := NewReportRepository(db, zerolog.Nop())
+
+	for _, cursor := range []string{"not base64!", "bm8tc2VwYXJhdG9y", ReportCursor(models.Report{ID: "not-a-uuid"})} {
+		if _, _, err := repo.SearchAfter(context.Background(), nil, cursor, 10); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("cursor %q: err = %v, want ErrInvalidCursor", cursor, err)
+		}
+	}
+}
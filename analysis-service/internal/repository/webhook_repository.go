@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, subscription *models.WebhookSubscription) error
+	List(ctx context.Context) ([]models.WebhookSubscription, error)
+	Delete(ctx context.Context, id string) (bool, error)
+	// GetActiveFor возвращает активные подписки, фильтр которых подходит под задание и вердикт
+	GetActiveFor(ctx context.Context, assignmentID string, plagiarismFlag bool) ([]models.WebhookSubscription, error)
+	RecordSuccess(ctx context.Context, id string) error
+	// RecordFailure учитывает неудачную доставку и отключает подписку после maxFailures неудач подряд
+	// (0 — не отключать); возвращает true, если подписка отключена этим вызовом
+	RecordFailure(ctx context.Context, id, lastError string, maxFailures int) (bool, error)
+}
+
+type webhookRepository struct {
+	*PostgresRepository
+}
+
+func NewWebhookRepository(db *sql.DB, logger zerolog.Logger) WebhookRepository {
+	return &webhookRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+const webhookColumns = `
+	id, url, secret, assignment_id, plagiarism_flag, active,
+	consecutive_failures, COALESCE(last_error, ''), last_delivery_at, created_at
+`
+
+func (r *webhookRepository) Create(ctx context.Context, subscription *models.WebhookSubscription) error {
+	if subscription.ID == "" {
+		subscription.ID = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO webhook_subscriptions (id, url, secret, assignment_id, plagiarism_flag, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		subscription.ID,
+		subscription.URL,
+		subscription.Secret,
+		subscription.AssignmentID,
+		subscription.PlagiarismFlag,
+		subscription.Active,
+		subscription.CreatedAt,
+	)
+	return err
+}
+
+func (r *webhookRepository) List(ctx context.Context) ([]models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhook_subscriptions ORDER BY created_at`
+	return r.query(ctx, query)
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *webhookRepository) GetActiveFor(ctx context.Context, assignmentID string, plagiarismFlag bool) ([]models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookColumns + `
+		FROM webhook_subscriptions
+		WHERE active
+		AND (assignment_id IS NULL OR assignment_id::text = $1)
+		AND (plagiarism_flag IS NULL OR plagiarism_flag = $2)
+		ORDER BY created_at
+	`
+	return r.query(ctx, query, assignmentID, plagiarismFlag)
+}
+
+func (r *webhookRepository) RecordSuccess(ctx context.Context, id string) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET consecutive_failures = 0, last_error = NULL, last_delivery_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *webhookRepository) RecordFailure(ctx context.Context, id, lastError string, maxFailures int) (bool, error) {
+	query := `
+		UPDATE webhook_subscriptions
+		SET consecutive_failures = consecutive_failures + 1,
+			last_error = $2,
+			last_delivery_at = CURRENT_TIMESTAMP,
+			active = ($3 = 0 OR consecutive_failures + 1 < $3)
+		WHERE id = $1 AND active
+		RETURNING active
+	`
+
+	var active bool
+	err := r.db.QueryRowContext(ctx, query, id, lastError, maxFailures).Scan(&active)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !active, nil
+}
+
+func (r *webhookRepository) query(ctx context.Context, query string, args ...interface{}) ([]models.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []models.WebhookSubscription
+	for rows.Next() {
+		var subscription models.WebhookSubscription
+		if err := rows.Scan(
+			&subscription.ID,
+			&subscription.URL,
+			&subscription.Secret,
+			&subscription.AssignmentID,
+			&subscription.PlagiarismFlag,
+			&subscription.Active,
+			&subscription.ConsecutiveFailures,
+			&subscription.LastError,
+			&subscription.LastDeliveryAt,
+			&subscription.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
@@ -20,22 +20,45 @@ import (
 type AnalysisService interface {
 	AnalyzeWork(ctx context.Context, workID, fileID, assignmentID, studentID string) (*models.AnalysisResult, error)
 	AnalyzeWorkAsync(ctx context.Context, workID, fileID, assignmentID, studentID string) (string, error)
+	// AnalyzeWorkSync — AnalyzeWork для HTTP-запроса с ограничением размера задания (ComparisonSetTooLargeError)
+	AnalyzeWorkSync(ctx context.Context, workID, fileID, assignmentID, studentID string) (*models.AnalysisResult, error)
+	// AnalyzeWorkUrgent — синхронный анализ в зарезервированном слоте, минуя очередь
+	AnalyzeWorkUrgent(ctx context.Context, workID, fileID, assignmentID, studentID string) (*models.AnalysisResult, error)
+	// PreviewAnalysis — пробное сравнение файла с работами задания без работы и отчёта
+	PreviewAnalysis(ctx context.Context, file analyzer.PreviewFile, assignmentID, studentID string) (*models.PreviewResult, error)
 	GetAnalysisResult(ctx context.Context, workID string) (*models.AnalysisResult, error)
+	GetComparisonPair(ctx context.Context, workA, workB string) (*models.ComparisonPairResponse, error)
+	GetWorksByHash(ctx context.Context, fileHash string, limit int) (*models.HashOccurrencesResponse, error)
 	BatchAnalyze(ctx context.Context, workIDs []string) (*models.BatchAnalysisResponse, error)
 	GetServiceStatus(ctx context.Context) (*models.HealthCheckResponse, error)
-	RetryFailedAnalyses(ctx context.Context, limit int) (int, error)
+	RetryFailedAnalyses(ctx context.Context, limit int) (*models.RetryFailedResponse, error)
+	// RetryDueAnalyses повторяет анализы из очереди повторов, время которых наступило
+	RetryDueAnalyses(ctx context.Context, limit int) (*models.RetryFailedResponse, error)
+	SetAssignmentThreshold(ctx context.Context, assignmentID string, threshold int, updatedBy string) (*models.AssignmentThreshold, error)
+	WarmupAssignment(ctx context.Context, assignmentID string) (*models.WarmupResult, error)
+	// RebuildHashIndex перечитывает индекс точных копий из отчётов задания (assignmentID "" — всех заданий)
+	RebuildHashIndex(ctx context.Context, assignmentID string) (*models.HashIndexStats, error)
+	GetAnalysisVersion(ctx context.Context) (*models.AnalysisVersionResponse, error)
+	ReanalyzeOutdated(ctx context.Context, limit int) (*models.RetryFailedResponse, error)
 }
 
 type analysisService struct {
 	reportRepo        repository.ReportRepository
 	plagiarismRepo    repository.PlagiarismRepository
+	queueRepo         repository.AnalysisQueueRepository
 	workClient        integration.WorkClient
 	fileClient        integration.FileClient
 	plagiarismChecker analyzer.PlagiarismChecker
 	messageHandler    queue.MessageHandler
 	rabbitMQPublisher queue.RabbitMQPublisher
-	logger            zerolog.Logger
-	config            AnalysisConfig
+	rabbitMQRepo      repository.RabbitMQRepository
+	notifier          NotificationService
+	webhooks          WebhookService
+	auditLogger       AuditLogger
+	// Слоты срочного анализа (nil — срочный анализ выключен)
+	urgentSlots chan struct{}
+	logger      zerolog.Logger
+	config      AnalysisConfig
 }
 
 type AnalysisConfig struct {
@@ -45,33 +68,117 @@ type AnalysisConfig struct {
 	Timeout             time.Duration
 	MaxRetries          int
 	BatchSize           int
+	PublishStartedEvent bool
+	// Общий набор работ для сравнения на задание в рамках BatchAnalyze
+	ShareBatchComparisonSet bool
+	// Сколько раз RetryFailedAnalyses повторяет отчёт до статуса abandoned (0 — без ограничения)
+	MaxReportRetries int
+	// Размер пула и порядок повтора в RetryFailedAnalyses
+	RetryConcurrency int
+	RetryOrder       string
+	// Пересчитывать assignment_stats затронутых заданий после BatchAnalyze
+	RefreshStatsAfterBatch bool
+	// Работа без файла: no_file — сохранить отчёт со статусом no_file, reject — вернуть ErrWorkHasNoFile
+	PendingFile string
+	// Очередь повторов упавших анализов с экспоненциальной задержкой
+	RetryQueueEnabled     bool
+	RetryQueueMaxAttempts int
+	RetryQueueBaseDelay   time.Duration
+	RetryQueueMaxDelay    time.Duration
+	// Перепроверять работы задания, завершённые за SiblingRecheckWindow до текущей и не сравнивавшиеся с ней
+	SiblingRecheck         bool
+	SiblingRecheckWindow   time.Duration
+	SiblingRecheckMaxWorks int
+	// Сравнивать работу с работами других заданий, сданными за CrossAssignmentWindow до неё
+	CrossAssignment         bool
+	CrossAssignmentWindow   time.Duration
+	CrossAssignmentMaxWorks int
+	// Отмечать в отчётах более ранних работ совпадение с текущей не ниже LaterMatchMinMatch
+	LaterMatches       bool
+	LaterMatchMinMatch int
+	// Индекс точных копий по хешам проанализированных работ включён в проверяющем
+	HashIndexEnabled bool
+	// Одновременных анализов в BatchAnalyze
+	MaxWorkers int
+	// Одновременных срочных анализов (0 — срочный анализ выключен) и ожидание свободного слота
+	UrgentSlots       int
+	UrgentWaitTimeout time.Duration
+	// Работ для сравнения, сверх которых AnalyzeWorkSync не анализирует синхронно (0 — без ограничения),
+	// и что делать с таким запросом: SyncLimitReject или SyncLimitAsync
+	SyncMaxComparisonSet int
+	SyncLimitAction      string
+	// Пробная проверка файла без создания работы (POST /analysis/preview)
+	PreviewEnabled bool
 }
 
+const (
+	PendingFileNoFile = "no_file"
+	PendingFileReject = "reject"
+)
+
+// ErrWorkHasNoFile — у работы file_id = "pending", анализировать нечего
+var ErrWorkHasNoFile = errors.New("work has no uploaded file")
+
 func NewAnalysisService(
 	reportRepo repository.ReportRepository,
 	plagiarismRepo repository.PlagiarismRepository,
+	queueRepo repository.AnalysisQueueRepository,
 	workClient integration.WorkClient,
 	fileClient integration.FileClient,
 	plagiarismChecker analyzer.PlagiarismChecker,
 	messageHandler queue.MessageHandler,
 	rabbitMQPublisher queue.RabbitMQPublisher,
+	rabbitMQRepo repository.RabbitMQRepository,
+	notifier NotificationService,
+	webhooks WebhookService,
+	auditLogger AuditLogger,
 	logger zerolog.Logger,
 	config AnalysisConfig,
 ) AnalysisService {
+	var urgentSlots chan struct{}
+	if config.UrgentSlots > 0 {
+		urgentSlots = make(chan struct{}, config.UrgentSlots)
+	}
+
 	return &analysisService{
 		reportRepo:        reportRepo,
 		plagiarismRepo:    plagiarismRepo,
+		queueRepo:         queueRepo,
 		workClient:        workClient,
 		fileClient:        fileClient,
 		plagiarismChecker: plagiarismChecker,
 		messageHandler:    messageHandler,
 		rabbitMQPublisher: rabbitMQPublisher,
+		rabbitMQRepo:      rabbitMQRepo,
+		notifier:          notifier,
+		webhooks:          webhooks,
+		auditLogger:       auditLogger,
+		urgentSlots:       urgentSlots,
 		logger:            logger,
 		config:            config,
 	}
 }
 
+type triggerSourceKey struct{}
+
+// WithTriggerSource помечает контекст источником запуска, который попадёт в trigger_source отчёта
+func WithTriggerSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, triggerSourceKey{}, source)
+}
+
+func triggerSourceFromContext(ctx context.Context) string {
+	if source, ok := ctx.Value(triggerSourceKey{}).(string); ok && source != "" {
+		return source
+	}
+	return models.TriggerSourceUnknown
+}
+
 func (s *analysisService) AnalyzeWork(ctx context.Context, workID, fileID, assignmentID, studentID string) (*models.AnalysisResult, error) {
+	return s.analyzeWork(ctx, workID, fileID, assignmentID, studentID, nil)
+}
+
+// analyzeWork при comparisonSet == nil сам загружает работы задания для сравнения
+func (s *analysisService) analyzeWork(ctx context.Context, workID, fileID, assignmentID, studentID string, comparisonSet []models.SimilarWork) (*models.AnalysisResult, error) {
 	startTime := time.Now()
 
 	existingReport, err := s.reportRepo.GetByWorkID(ctx, workID)
@@ -84,16 +191,22 @@ func (s *analysisService) AnalyzeWork(ctx context.Context, workID, fileID, assig
 		return s.convertReportToResult(existingReport), nil
 	}
 
+	// Хеш "pending" запрашивать бессмысленно: file-service вернёт ошибку и отчёт уйдёт в повторы
+	if fileID == models.PendingFileID {
+		return s.handlePendingFile(ctx, existingReport, workID, assignmentID, studentID)
+	}
+
 	report := &models.Report{
-		ID:           uuid.New().String(),
-		WorkID:       workID,
-		FileID:       fileID,
-		AssignmentID: assignmentID,
-		StudentID:    studentID,
-		Status:       models.ReportStatusProcessing.String(),
-		StartedAt:    &startTime,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		ID:            uuid.New().String(),
+		WorkID:        workID,
+		FileID:        fileID,
+		AssignmentID:  assignmentID,
+		StudentID:     studentID,
+		Status:        models.ReportStatusProcessing.String(),
+		TriggerSource: triggerSourceFromContext(ctx),
+		StartedAt:     &startTime,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	if existingReport != nil {
@@ -111,11 +224,22 @@ func (s *analysisService) AnalyzeWork(ctx context.Context, workID, fileID, assig
 		}
 	}
 
+	if s.config.PublishStartedEvent {
+		s.publishAnalysisStarted(ctx, report, startTime)
+	}
+
 	if err := s.workClient.UpdateWorkStatus(ctx, workID, "analyzing"); err != nil {
 		s.logger.Error().Err(err).Str("work_id", workID).Msg("Failed to update work status")
 	}
 
-	result, err := s.plagiarismChecker.CheckPlagiarism(ctx, workID, fileID, assignmentID, studentID)
+	threshold := s.resolveThreshold(ctx, assignmentID)
+
+	var result *models.AnalysisResult
+	if comparisonSet != nil {
+		result, err = s.plagiarismChecker.CheckPlagiarismAgainst(ctx, workID, fileID, assignmentID, studentID, comparisonSet, threshold)
+	} else {
+		result, err = s.plagiarismChecker.CheckPlagiarism(ctx, workID, fileID, assignmentID, studentID, threshold)
+	}
 	if err != nil {
 		report.Status = models.ReportStatusFailed.String()
 		report.UpdatedAt = time.Now()
@@ -127,7 +251,17 @@ func (s *analysisService) AnalyzeWork(ctx context.Context, workID, fileID, assig
 			s.logger.Error().Err(updateErr).Msg("Failed to update work status to failed")
 		}
 
-		return nil, fmt.Errorf("plagiarism check failed: %w", err)
+		attempts := 1
+		if existingReport != nil {
+			attempts = existingReport.RetryCount + 1
+		}
+		s.publishAnalysisFailed(ctx, report, err, attempts)
+
+		if s.config.RetryQueueEnabled {
+			s.scheduleRetry(ctx, report, err)
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrAnalysisFailed, err)
 	}
 
 	completedAt := time.Now()
@@ -155,9 +289,32 @@ func (s *analysisService) AnalyzeWork(ctx context.Context, workID, fileID, assig
 		report.Details = result.Details
 	}
 
+	persistStart := time.Now()
 	if err := s.reportRepo.Update(ctx, report); err != nil {
 		return nil, fmt.Errorf("failed to update report with results: %w", err)
 	}
+	// Время сохранения известно только после записи отчёта, поэтому дописывается отдельно
+	if result.Details != nil {
+		if err := s.reportRepo.SetPersistenceTime(ctx, report.ID, time.Since(persistStart).Milliseconds()); err != nil {
+			s.logger.Warn().Err(err).Str("report_id", report.ID).Msg("Failed to record persistence time")
+		}
+	}
+
+	s.auditDecision(ctx, report, result, threshold)
+
+	s.plagiarismChecker.IndexWork(models.HashIndexEntry{
+		AssignmentID: report.AssignmentID,
+		WorkID:       workID,
+		StudentID:    report.StudentID,
+		FileHash:     report.FileHash,
+		AnalyzedAt:   report.CreatedAt,
+	})
+
+	if s.config.RetryQueueEnabled {
+		if err := s.queueRepo.MarkCompleted(ctx, workID); err != nil {
+			s.logger.Error().Err(err).Str("work_id", workID).Msg("Failed to mark queue item as completed")
+		}
+	}
 
 	workStatus := "analyzed"
 	if result.PlagiarismFlag {
@@ -171,6 +328,8 @@ func (s *analysisService) AnalyzeWork(ctx context.Context, workID, fileID, assig
 	event := models.AnalysisCompletedEvent{
 		WorkID:          workID,
 		ReportID:        report.ID,
+		AssignmentID:    report.AssignmentID,
+		StudentID:       report.StudentID,
 		Status:          report.Status,
 		PlagiarismFlag:  report.PlagiarismFlag,
 		OriginalWorkID:  report.OriginalWorkID,
@@ -183,11 +342,30 @@ func (s *analysisService) AnalyzeWork(ctx context.Context, workID, fileID, assig
 	if err != nil {
 		s.logger.Error().Err(err).Msg("Failed to marshal analysis completed event")
 	} else {
-		if err := s.rabbitMQPublisher.Publish(ctx, "plagiarism_exchange", "analysis.completed", eventJSON); err != nil {
+		if err := s.rabbitMQPublisher.Publish(ctx, "plagiarism_exchange", models.EventAnalysisCompleted, eventJSON); err != nil {
 			s.logger.Error().Err(err).Msg("Failed to publish analysis completed event")
 		}
 	}
 
+	if s.webhooks != nil {
+		go s.notifyWebhooks(event)
+	}
+
+	if result.PlagiarismFlag && s.notifier != nil {
+		go s.notifyPlagiarism(report, completedAt)
+	}
+
+	// Перепроверка сама не запускает перепроверок, иначе соседние работы проверяли бы друг друга по кругу
+	if s.config.SiblingRecheck && report.TriggerSource != models.TriggerSourceRecheck {
+		go s.recheckSiblings(report, completedAt)
+	}
+	if s.config.CrossAssignment && report.TriggerSource != models.TriggerSourceRecheck {
+		go s.checkCrossAssignment(report, threshold)
+	}
+	if s.config.LaterMatches && len(result.SimilarWorks) > 0 {
+		go s.annotateEarlierWorks(report, result.SimilarWorks, completedAt)
+	}
+
 	s.logger.Info().
 		Str("work_id", workID).
 		Bool("plagiarism", result.PlagiarismFlag).
@@ -198,17 +376,186 @@ func (s *analysisService) AnalyzeWork(ctx context.Context, workID, fileID, assig
 	return result, nil
 }
 
+// resolveThreshold — порог задания, а если он не задан или не читается — общий из конфигурации
+func (s *analysisService) resolveThreshold(ctx context.Context, assignmentID string) int {
+	threshold, ok, err := s.reportRepo.GetAssignmentThreshold(ctx, assignmentID)
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Str("assignment_id", assignmentID).
+			Msg("Failed to get assignment threshold, using default")
+		return s.config.SimilarityThreshold
+	}
+	if !ok {
+		return s.config.SimilarityThreshold
+	}
+	return threshold
+}
+
+func (s *analysisService) WarmupAssignment(ctx context.Context, assignmentID string) (*models.WarmupResult, error) {
+	return s.plagiarismChecker.Warmup(ctx, assignmentID)
+}
+
+func (s *analysisService) RebuildHashIndex(ctx context.Context, assignmentID string) (*models.HashIndexStats, error) {
+	if !s.config.HashIndexEnabled {
+		return nil, analyzer.ErrHashIndexDisabled
+	}
+
+	entries, err := s.reportRepo.GetHashIndexEntries(ctx, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hash index entries: %w", err)
+	}
+
+	return s.plagiarismChecker.RebuildHashIndex(assignmentID, entries)
+}
+
+func (s *analysisService) SetAssignmentThreshold(ctx context.Context, assignmentID string, threshold int, updatedBy string) (*models.AssignmentThreshold, error) {
+	if threshold < 0 || threshold > 100 {
+		return nil, ErrInvalidThreshold
+	}
+
+	record := &models.AssignmentThreshold{
+		AssignmentID: assignmentID,
+		Threshold:    threshold,
+		UpdatedBy:    updatedBy,
+	}
+	if err := s.reportRepo.SetAssignmentThreshold(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to set assignment threshold: %w", err)
+	}
+
+	s.logger.Info().
+		Str("assignment_id", assignmentID).
+		Int("threshold", threshold).
+		Str("updated_by", updatedBy).
+		Msg("Assignment threshold updated")
+
+	return record, nil
+}
+
+// auditDecision фиксирует вердикт в журнале аудита. Сбой записи не отменяет результат анализа
+func (s *analysisService) auditDecision(ctx context.Context, report *models.Report, result *models.AnalysisResult, threshold int) {
+	if s.auditLogger == nil {
+		return
+	}
+
+	method := "hash"
+	var details models.ReportDetails
+	if len(report.Details) > 0 && json.Unmarshal(report.Details, &details) == nil {
+		if details.AnalysisMetadata.SimilarityMethod != "" {
+			method = details.AnalysisMetadata.SimilarityMethod
+		} else if details.AnalysisMetadata.AlgorithmUsed != "" {
+			method = details.AnalysisMetadata.AlgorithmUsed
+		}
+	}
+
+	matched := make([]string, 0, len(result.SimilarWorks))
+	for _, work := range result.SimilarWorks {
+		if work.MatchPercentage >= threshold {
+			matched = append(matched, work.WorkID)
+		}
+	}
+
+	entry := models.AuditEntry{
+		ReportID:        report.ID,
+		WorkID:          report.WorkID,
+		AssignmentID:    report.AssignmentID,
+		StudentID:       report.StudentID,
+		PlagiarismFlag:  report.PlagiarismFlag,
+		MatchPercentage: report.MatchPercentage,
+		OriginalWorkID:  report.OriginalWorkID,
+		Method:          method,
+		Threshold:       threshold,
+		ComparedCount:   report.ComparedFilesCount,
+		MatchedWorkIDs:  matched,
+		TriggerSource:   report.TriggerSource,
+		DecidedAt:       *report.CompletedAt,
+	}
+
+	if err := s.auditLogger.LogDecision(ctx, entry); err != nil {
+		s.logger.Error().Err(err).Str("work_id", report.WorkID).Msg("Failed to write audit entry")
+	}
+}
+
+// notifyPlagiarism рассылает уведомления в фоне, не задерживая ответ анализа
+func (s *analysisService) notifyPlagiarism(report *models.Report, detectedAt time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	notification := models.PlagiarismNotification{
+		WorkID:          report.WorkID,
+		ReportID:        report.ID,
+		AssignmentID:    report.AssignmentID,
+		StudentID:       report.StudentID,
+		OriginalWorkID:  report.OriginalWorkID,
+		MatchPercentage: report.MatchPercentage,
+		DetectedAt:      detectedAt,
+	}
+
+	if err := s.notifier.NotifyPlagiarism(ctx, notification); err != nil {
+		s.logger.Error().Err(err).Str("work_id", report.WorkID).Msg("Failed to send plagiarism notifications")
+	}
+}
+
+// notifyWebhooks доставляет событие подписчикам в фоне; время доставки ограничено
+// таймаутом запроса и числом попыток из webhooks.*
+func (s *analysisService) notifyWebhooks(event models.AnalysisCompletedEvent) {
+	s.webhooks.NotifyAnalysisCompleted(context.Background(), event)
+}
+
+func (s *analysisService) publishAnalysisStarted(ctx context.Context, report *models.Report, startedAt time.Time) {
+	event := models.AnalysisStartedEvent{
+		WorkID:       report.WorkID,
+		ReportID:     report.ID,
+		AssignmentID: report.AssignmentID,
+		StudentID:    report.StudentID,
+		StartedAt:    startedAt,
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to marshal analysis started event")
+		return
+	}
+
+	if err := s.rabbitMQPublisher.Publish(ctx, "plagiarism_exchange", models.EventAnalysisStarted, eventJSON); err != nil {
+		s.logger.Error().Err(err).Str("work_id", report.WorkID).Msg("Failed to publish analysis started event")
+	}
+}
+
+func (s *analysisService) publishAnalysisFailed(ctx context.Context, report *models.Report, cause error, attempts int) {
+	event := models.AnalysisFailedEvent{
+		WorkID:       report.WorkID,
+		ReportID:     report.ID,
+		AssignmentID: report.AssignmentID,
+		StudentID:    report.StudentID,
+		Error:        cause.Error(),
+		Attempts:     attempts,
+		FailedAt:     time.Now(),
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to marshal analysis failed event")
+		return
+	}
+
+	if err := s.rabbitMQPublisher.Publish(ctx, "plagiarism_exchange", models.EventAnalysisFailed, eventJSON); err != nil {
+		s.logger.Error().Err(err).Str("work_id", report.WorkID).Msg("Failed to publish analysis failed event")
+	}
+}
+
 func (s *analysisService) AnalyzeWorkAsync(ctx context.Context, workID, fileID, assignmentID, studentID string) (string, error) {
 	reportID := uuid.New().String()
 	report := &models.Report{
-		ID:           reportID,
-		WorkID:       workID,
-		FileID:       fileID,
-		AssignmentID: assignmentID,
-		StudentID:    studentID,
-		Status:       models.ReportStatusPending.String(),
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		ID:            reportID,
+		WorkID:        workID,
+		FileID:        fileID,
+		AssignmentID:  assignmentID,
+		StudentID:     studentID,
+		Status:        models.ReportStatusPending.String(),
+		TriggerSource: models.TriggerSourceAsync,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	if err := s.reportRepo.Create(ctx, report); err != nil {
@@ -246,19 +593,99 @@ func (s *analysisService) GetAnalysisResult(ctx context.Context, workID string)
 	}
 
 	if report == nil {
-		return nil, errors.New("analysis not found for this work")
+		return nil, ErrAnalysisNotFound
 	}
 
 	return s.convertReportToResult(report), nil
 }
 
+func (s *analysisService) GetComparisonPair(ctx context.Context, workA, workB string) (*models.ComparisonPairResponse, error) {
+	reportWorkID, comparison, err := s.plagiarismRepo.GetComparisonPair(ctx, workA, workB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comparison: %w", err)
+	}
+
+	if comparison == nil {
+		return nil, ErrComparisonNotFound
+	}
+
+	sections := comparison.MatchedSections
+	if sections == nil {
+		sections = []models.MatchedSection{}
+	}
+
+	return &models.ComparisonPairResponse{
+		WorkA:           workA,
+		WorkB:           workB,
+		ReportWorkID:    reportWorkID,
+		MatchPercentage: comparison.MatchPercentage,
+		MatchedSections: sections,
+		ComparedAt:      comparison.ComparedAt,
+	}, nil
+}
+
+// GetWorksByHash показывает все работы с данным хешем файла во всех заданиях.
+// Время сдачи и имя студента берутся из work-service; если он недоступен — время создания отчёта.
+func (s *analysisService) GetWorksByHash(ctx context.Context, fileHash string, limit int) (*models.HashOccurrencesResponse, error) {
+	reports, err := s.reportRepo.GetByFileHash(ctx, fileHash, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports by hash: %w", err)
+	}
+
+	if len(reports) == 0 {
+		return nil, ErrHashNotFound
+	}
+
+	response := &models.HashOccurrencesResponse{
+		FileHash: fileHash,
+		Total:    len(reports),
+		Works:    make([]models.HashOccurrence, 0, len(reports)),
+	}
+
+	assignments := make(map[string]bool)
+	students := make(map[string]bool)
+	for _, report := range reports {
+		occurrence := models.HashOccurrence{
+			WorkID:          report.WorkID,
+			ReportID:        report.ID,
+			AssignmentID:    report.AssignmentID,
+			StudentID:       report.StudentID,
+			SubmittedAt:     report.CreatedAt,
+			Status:          report.Status,
+			PlagiarismFlag:  report.PlagiarismFlag,
+			MatchPercentage: report.MatchPercentage,
+		}
+
+		work, err := s.workClient.GetWorkInfo(ctx, report.WorkID)
+		if err != nil {
+			s.logger.Warn().Err(err).Str("work_id", report.WorkID).Msg("Failed to get work info for hash lookup")
+		} else if work != nil {
+			occurrence.StudentName = work.StudentName
+			if !work.SubmittedAt.IsZero() {
+				occurrence.SubmittedAt = work.SubmittedAt
+			}
+		}
+
+		assignments[report.AssignmentID] = true
+		students[report.StudentID] = true
+		response.Works = append(response.Works, occurrence)
+	}
+
+	response.Assignments = len(assignments)
+	response.Students = len(students)
+
+	return response, nil
+}
+
 func (s *analysisService) BatchAnalyze(ctx context.Context, workIDs []string) (*models.BatchAnalysisResponse, error) {
 	startTime := time.Now()
 
 	if len(workIDs) > s.config.BatchSize {
-		return nil, fmt.Errorf("batch size exceeds limit of %d", s.config.BatchSize)
+		return nil, fmt.Errorf("%w of %d", ErrBatchTooLarge, s.config.BatchSize)
 	}
 
+	ctx = WithTriggerSource(ctx, models.TriggerSourceBatch)
+
 	s.logger.Info().
 		Int("work_count", len(workIDs)).
 		Msg("Starting batch analysis")
@@ -267,148 +694,402 @@ func (s *analysisService) BatchAnalyze(ctx context.Context, workIDs []string) (*
 		Total:       len(workIDs),
 		Processed:   0,
 		Failed:      0,
+		Status:      models.BatchStatusCompleted,
 		Results:     make([]models.PlagiarismCheckResponse, 0, len(workIDs)),
 		CompletedAt: time.Now(),
 	}
 
-	// Обрабатываем работы небольшими пачками, чтобы не перегружать CPU/БД.
-	batchSize := 5
-	for i := 0; i < len(workIDs); i += batchSize {
-		end := i + batchSize
-		if end > len(workIDs) {
-			end = len(workIDs)
-		}
+	works, comparisonSets, errs := s.prepareBatch(ctx, workIDs)
 
-		batch := workIDs[i:end]
+	concurrency := s.config.MaxWorkers
+	if concurrency < 1 {
+		concurrency = 1
+	}
 
-		var wg sync.WaitGroup
-		results := make([]models.PlagiarismCheckResponse, len(batch))
-		errors := make([]error, len(batch))
+	// Одновременно анализируется не больше MaxWorkers работ. После отмены ctx (клиент отключился)
+	// новые работы не запускаются, уже запущенные прерываются вместе с ctx.
+	results := make([]models.PlagiarismCheckResponse, len(workIDs))
+	started := make([]bool, len(workIDs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for idx := range workIDs {
+		if errs[idx] != nil {
+			continue
+		}
 
-		for j, workID := range batch {
-			wg.Add(1)
-			go func(idx int, wID string) {
-				defer wg.Done()
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
 
-				result, err := s.AnalyzeWork(ctx, wID, "file_"+wID, "assignment_"+wID, "student_"+wID)
-				if err != nil {
-					errors[idx] = err
-					return
-				}
+		started[idx] = true
+		wg.Add(1)
+		go func(idx int, work *models.SimilarWork) {
+			defer wg.Done()
+			defer func() { <-sem }()
 
-				report, repErr := s.reportRepo.GetByWorkID(ctx, wID)
-				if repErr != nil {
-					errors[idx] = repErr
-					return
-				}
-				reportID := ""
-				if report != nil {
-					reportID = report.ID
-				}
-				if reportID == "" {
-					reportID = uuid.New().String()
-				}
+			var comparisonSet []models.SimilarWork
+			if set, ok := comparisonSets[work.AssignmentID]; ok {
+				comparisonSet = excludeWork(set, work.WorkID)
+			}
 
-				results[idx] = models.PlagiarismCheckResponse{
-					ReportID:        reportID,
-					WorkID:          wID,
-					Status:          result.Status,
-					PlagiarismFlag:  result.PlagiarismFlag,
-					MatchPercentage: result.MatchPercentage,
-					OriginalWorkID:  result.OriginalWorkID,
-					AnalyzedAt:      result.AnalyzedAt,
-				}
-			}(j, workID)
-		}
+			result, err := s.analyzeWork(ctx, work.WorkID, work.FileID, work.AssignmentID, work.StudentID, comparisonSet)
+			if err != nil {
+				errs[idx] = err
+				return
+			}
 
-		wg.Wait()
+			report, repErr := s.reportRepo.GetByWorkID(ctx, work.WorkID)
+			if repErr != nil {
+				errs[idx] = repErr
+				return
+			}
+			reportID := ""
+			if report != nil {
+				reportID = report.ID
+			}
+			if reportID == "" {
+				reportID = uuid.New().String()
+			}
 
-		for j, result := range results {
-			if result.WorkID != "" {
-				response.Results = append(response.Results, result)
-				response.Processed++
-			} else if errors[j] != nil {
-				s.logger.Error().
-					Err(errors[j]).
-					Str("work_id", batch[j]).
-					Msg("Failed to analyze work in batch")
-				response.Failed++
+			results[idx] = models.PlagiarismCheckResponse{
+				ReportID:        reportID,
+				WorkID:          work.WorkID,
+				Status:          result.Status,
+				PlagiarismFlag:  result.PlagiarismFlag,
+				MatchPercentage: result.MatchPercentage,
+				OriginalWorkID:  result.OriginalWorkID,
+				AnalyzedAt:      result.AnalyzedAt,
 			}
+		}(idx, works[idx])
+	}
+	wg.Wait()
+
+	cancelled := ctx.Err() != nil
+	if cancelled {
+		response.Status = models.BatchStatusCancelled
+	}
+
+	for idx := range workIDs {
+		switch {
+		case results[idx].WorkID != "":
+			response.Results = append(response.Results, results[idx])
+			response.Processed++
+		case cancelled && (!started[idx] || isContextError(errs[idx])):
+			// Работа не запускалась или прервана отменой — это не ошибка анализа
+			response.Skipped++
+		case errs[idx] != nil:
+			s.logger.Error().
+				Err(errs[idx]).
+				Str("work_id", workIDs[idx]).
+				Msg("Failed to analyze work in batch")
+			response.Failed++
 		}
 	}
 
+	// При отмене ctx уже недействителен; статистику пересчитает следующий анализ задания
+	if s.config.RefreshStatsAfterBatch && !cancelled {
+		s.refreshAssignmentStats(ctx, works)
+	}
+
 	response.CompletedAt = time.Now()
 
 	s.logger.Info().
 		Int("total", response.Total).
 		Int("processed", response.Processed).
 		Int("failed", response.Failed).
+		Int("skipped", response.Skipped).
+		Str("status", response.Status).
+		Int("assignments", len(comparisonSets)).
 		Dur("duration", response.CompletedAt.Sub(startTime)).
 		Msg("Batch analysis completed")
 
 	return response, nil
 }
 
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func (s *analysisService) refreshAssignmentStats(ctx context.Context, works []*models.SimilarWork) {
+	refreshed := make(map[string]bool)
+	for _, work := range works {
+		if work == nil || refreshed[work.AssignmentID] {
+			continue
+		}
+		refreshed[work.AssignmentID] = true
+
+		if _, err := s.reportRepo.RefreshAssignmentStats(ctx, work.AssignmentID); err != nil {
+			s.logger.Error().Err(err).Str("assignment_id", work.AssignmentID).Msg("Failed to refresh assignment stats")
+		}
+	}
+}
+
+// prepareBatch загружает данные работ и, если включено, один набор работ
+// для сравнения на каждое задание, общий для всех работ пачки.
+func (s *analysisService) prepareBatch(ctx context.Context, workIDs []string) ([]*models.SimilarWork, map[string][]models.SimilarWork, []error) {
+	works := make([]*models.SimilarWork, len(workIDs))
+	errs := make([]error, len(workIDs))
+	comparisonSets := make(map[string][]models.SimilarWork)
+	setErrors := make(map[string]error)
+
+	for i, workID := range workIDs {
+		work, err := s.workClient.GetWorkInfo(ctx, workID)
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to get work info: %w", err)
+			continue
+		}
+		if work == nil {
+			errs[i] = errors.New("work not found")
+			continue
+		}
+		works[i] = work
+
+		if !s.config.ShareBatchComparisonSet {
+			continue
+		}
+
+		if _, ok := comparisonSets[work.AssignmentID]; ok {
+			continue
+		}
+		if err, ok := setErrors[work.AssignmentID]; ok {
+			errs[i] = err
+			continue
+		}
+
+		set, err := s.workClient.GetPreviousWorks(ctx, work.AssignmentID, "")
+		if err != nil {
+			setErrors[work.AssignmentID] = fmt.Errorf("failed to get previous works: %w", err)
+			errs[i] = setErrors[work.AssignmentID]
+			continue
+		}
+		comparisonSets[work.AssignmentID] = set
+	}
+
+	return works, comparisonSets, errs
+}
+
+func excludeWork(works []models.SimilarWork, workID string) []models.SimilarWork {
+	filtered := make([]models.SimilarWork, 0, len(works))
+	for _, work := range works {
+		if work.WorkID != workID {
+			filtered = append(filtered, work)
+		}
+	}
+	return filtered
+}
+
+// dependencyCheckTimeout ограничивает каждую проверку зависимости в GetServiceStatus
+const dependencyCheckTimeout = 3 * time.Second
+
+// GetServiceStatus проверяет зависимости параллельно. Без БД или RabbitMQ сервис не может
+// ни принимать события, ни отдавать отчёты — unhealthy; без work- или file-service отчёты
+// доступны, но новые работы не анализируются — degraded.
 func (s *analysisService) GetServiceStatus(ctx context.Context) (*models.HealthCheckResponse, error) {
-	dbOK := true
-	if err := s.reportRepo.Ping(ctx); err != nil {
-		dbOK = false
-		s.logger.Error().Err(err).Msg("Database health check failed")
+	ctx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+	defer cancel()
+
+	checks := []struct {
+		name  string
+		check func(context.Context) error
+		ok    bool
+	}{
+		{name: "database", check: s.reportRepo.Ping},
+		{name: "rabbitmq", check: func(context.Context) error { return s.rabbitMQRepo.Ping() }},
+		{name: "work_service", check: s.workClient.HealthCheck},
+		{name: "file_service", check: s.fileClient.HealthCheck},
 	}
 
-	workServiceOK := true
-	fileServiceOK := true
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := checks[i].check(ctx); err != nil {
+				s.logger.Error().Err(err).Str("dependency", checks[i].name).Msg("Dependency health check failed")
+				return
+			}
+			checks[i].ok = true
+		}(i)
+	}
+	wg.Wait()
 
 	response := &models.HealthCheckResponse{
 		Status:        "healthy",
-		Database:      dbOK,
-		RabbitMQ:      true,
-		WorkService:   workServiceOK,
-		FileService:   fileServiceOK,
+		Database:      checks[0].ok,
+		RabbitMQ:      checks[1].ok,
+		WorkService:   checks[2].ok,
+		FileService:   checks[3].ok,
 		ActiveWorkers: 0,
 		QueueLength:   0,
 		Uptime:        "24h",
 		Timestamp:     time.Now(),
 	}
 
-	if !dbOK || !workServiceOK || !fileServiceOK {
+	switch {
+	case !response.Database || !response.RabbitMQ:
+		response.Status = "unhealthy"
+	case !response.WorkService || !response.FileService:
 		response.Status = "degraded"
 	}
 
 	return response, nil
 }
 
-func (s *analysisService) RetryFailedAnalyses(ctx context.Context, limit int) (int, error) {
-	failedReports, err := s.reportRepo.GetReportsByStatus(ctx, models.ReportStatusFailed.String(), limit)
+func (s *analysisService) RetryFailedAnalyses(ctx context.Context, limit int) (*models.RetryFailedResponse, error) {
+	ctx = WithTriggerSource(ctx, models.TriggerSourceRetry)
+
+	failedReports, err := s.reportRepo.GetReportsByStatus(ctx, models.ReportStatusFailed.String(), limit, s.config.RetryOrder)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get failed reports: %w", err)
+		return nil, fmt.Errorf("failed to get failed reports: %w", err)
 	}
 
-	retryCount := 0
-	for _, report := range failedReports {
-		s.logger.Info().
-			Str("work_id", report.WorkID).
-			Str("report_id", report.ID).
-			Msg("Retrying failed analysis")
+	concurrency := s.config.RetryConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
 
-		_, err := s.AnalyzeWork(ctx, report.WorkID, report.FileID, report.AssignmentID, report.StudentID)
-		if err != nil {
+	// Загрузки файлов дополнительно ограничены общим семафором чекера,
+	// так что пул не превышает лимиты file-service
+	results := make([]models.RetryResult, len(failedReports))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range failedReports {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, report *models.Report) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = s.retryReport(ctx, report)
+		}(i, &failedReports[i])
+	}
+	wg.Wait()
+
+	response := &models.RetryFailedResponse{
+		Total:   len(failedReports),
+		Results: results,
+	}
+	for _, result := range results {
+		switch result.Status {
+		case "retried":
+			response.Retried++
+		case "abandoned":
+			response.Abandoned++
+		default:
+			response.Failed++
+		}
+	}
+
+	s.logger.Info().
+		Int("total_failed", response.Total).
+		Int("retried", response.Retried).
+		Int("abandoned", response.Abandoned).
+		Int("failed", response.Failed).
+		Int("concurrency", concurrency).
+		Str("order", s.config.RetryOrder).
+		Msg("Failed analyses retry completed")
+
+	return response, nil
+}
+
+func (s *analysisService) retryReport(ctx context.Context, report *models.Report) models.RetryResult {
+	result := models.RetryResult{
+		ReportID: report.ID,
+		WorkID:   report.WorkID,
+		Status:   "failed",
+	}
+
+	if s.config.MaxReportRetries > 0 && report.RetryCount >= s.config.MaxReportRetries {
+		if err := s.reportRepo.UpdateStatus(ctx, report.ID, models.ReportStatusAbandoned.String()); err != nil {
 			s.logger.Error().
 				Err(err).
-				Str("work_id", report.WorkID).
-				Msg("Failed to retry analysis")
-			continue
+				Str("report_id", report.ID).
+				Msg("Failed to mark report as abandoned")
+			result.Error = err.Error()
+			return result
 		}
 
-		retryCount++
+		s.logger.Warn().
+			Str("work_id", report.WorkID).
+			Str("report_id", report.ID).
+			Int("retry_count", report.RetryCount).
+			Msg("Analysis abandoned after max retries")
+		result.Status = "abandoned"
+		result.Attempt = report.RetryCount
+		return result
+	}
+
+	attempt, err := s.reportRepo.IncrementRetryCount(ctx, report.ID)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("report_id", report.ID).
+			Msg("Failed to increment retry count")
+		result.Error = err.Error()
+		return result
 	}
+	result.Attempt = attempt
 
 	s.logger.Info().
-		Int("total_failed", len(failedReports)).
-		Int("retried", retryCount).
-		Msg("Failed analyses retry completed")
+		Str("work_id", report.WorkID).
+		Str("report_id", report.ID).
+		Int("attempt", attempt).
+		Msg("Retrying failed analysis")
+
+	_, err = s.AnalyzeWork(ctx, report.WorkID, report.FileID, report.AssignmentID, report.StudentID)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("work_id", report.WorkID).
+			Msg("Failed to retry analysis")
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = "retried"
+	return result
+}
+
+func (s *analysisService) handlePendingFile(ctx context.Context, existingReport *models.Report, workID, assignmentID, studentID string) (*models.AnalysisResult, error) {
+	s.logger.Warn().
+		Str("work_id", workID).
+		Str("policy", s.config.PendingFile).
+		Msg("Work has no uploaded file, skipping analysis")
+
+	if s.config.PendingFile == PendingFileReject {
+		return nil, ErrWorkHasNoFile
+	}
+
+	now := time.Now()
+	if existingReport != nil {
+		if err := s.reportRepo.UpdateStatus(ctx, existingReport.ID, models.ReportStatusNoFile.String()); err != nil {
+			return nil, fmt.Errorf("failed to update report status: %w", err)
+		}
+	} else {
+		report := &models.Report{
+			ID:            uuid.New().String(),
+			WorkID:        workID,
+			FileID:        models.PendingFileID,
+			AssignmentID:  assignmentID,
+			StudentID:     studentID,
+			Status:        models.ReportStatusNoFile.String(),
+			TriggerSource: triggerSourceFromContext(ctx),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if err := s.reportRepo.Create(ctx, report); err != nil {
+			return nil, fmt.Errorf("failed to create report: %w", err)
+		}
+	}
 
-	return retryCount, nil
+	return &models.AnalysisResult{
+		WorkID:     workID,
+		Status:     models.ReportStatusNoFile.String(),
+		AnalyzedAt: now,
+	}, nil
 }
 
 func (s *analysisService) convertReportToResult(report *models.Report) *models.AnalysisResult {
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/rs/zerolog"
+)
+
+func TestBatchAnalyzeStopsOnCancellation(t *testing.T) {
+	works := make(map[string]*models.SimilarWork)
+	var workIDs []string
+	for i := 0; i < 5; i++ {
+		id := fmt.Sprintf("work-%d", i)
+		works[id] = &models.SimilarWork{WorkID: id, FileID: "file-" + id, AssignmentID: "a1", StudentID: "s" + id}
+		workIDs = append(workIDs, id)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Первая работа дожидается отмены; остальные не должны запуститься вовсе
+	var calls atomic.Int32
+	started := make(chan struct{})
+	checker := &fakeChecker{check: func(ctx context.Context, workID string) (*models.AnalysisResult, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+
+	s := NewAnalysisService(newFakeReportRepo(), nil, nil, &fakeWorkClient{works: works}, nil, checker,
+		nil, &fakePublisher{}, nil, nil, nil, nil, zerolog.Nop(),
+		AnalysisConfig{BatchSize: 10, MaxWorkers: 1, ShareBatchComparisonSet: true, RefreshStatsAfterBatch: true})
+
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	done := make(chan *models.BatchAnalysisResponse)
+	go func() {
+		response, err := s.BatchAnalyze(ctx, workIDs)
+		if err != nil {
+			t.Errorf("BatchAnalyze: %v", err)
+		}
+		done <- response
+	}()
+
+	var response *models.BatchAnalysisResponse
+	select {
+	case response = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("BatchAnalyze did not return after cancellation")
+	}
+
+	if response.Status != models.BatchStatusCancelled {
+		t.Errorf("status = %q, want %q", response.Status, models.BatchStatusCancelled)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("checker called %d times, want 1", got)
+	}
+	if response.Skipped != len(workIDs) || response.Failed != 0 || response.Processed != 0 {
+		t.Errorf("skipped/failed/processed = %d/%d/%d, want %d/0/0",
+			response.Skipped, response.Failed, response.Processed, len(workIDs))
+	}
+}
+
+func TestBatchAnalyzeCompletesWithinMaxWorkers(t *testing.T) {
+	works := make(map[string]*models.SimilarWork)
+	var workIDs []string
+	for i := 0; i < 6; i++ {
+		id := fmt.Sprintf("work-%d", i)
+		works[id] = &models.SimilarWork{WorkID: id, FileID: "file-" + id, AssignmentID: "a1", StudentID: "s" + id}
+		workIDs = append(workIDs, id)
+	}
+
+	var running, peak atomic.Int32
+	checker := &fakeChecker{check: func(ctx context.Context, workID string) (*models.AnalysisResult, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return &models.AnalysisResult{WorkID: workID, Status: "completed"}, nil
+	}}
+
+	s := NewAnalysisService(newFakeReportRepo(), nil, nil, &fakeWorkClient{works: works}, nil, checker,
+		nil, &fakePublisher{}, nil, nil, nil, nil, zerolog.Nop(),
+		AnalysisConfig{BatchSize: 10, MaxWorkers: 2, ShareBatchComparisonSet: true})
+
+	response, err := s.BatchAnalyze(context.Background(), workIDs)
+	if err != nil {
+		t.Fatalf("BatchAnalyze: %v", err)
+	}
+	if response.Status != models.BatchStatusCompleted || response.Processed != len(workIDs) {
+		t.Errorf("status = %q, processed = %d, want completed, %d", response.Status, response.Processed, len(workIDs))
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", p)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+func (s *analysisService) GetAnalysisVersion(ctx context.Context) (*models.AnalysisVersionResponse, error) {
+	current := s.plagiarismChecker.GetCheckerInfo().Version
+
+	counts, err := s.reportRepo.CountByAnalysisVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reports by analysis version: %w", err)
+	}
+
+	response := &models.AnalysisVersionResponse{
+		Current:  current,
+		Versions: make([]models.AnalysisVersionCount, 0, len(counts)),
+	}
+	for _, count := range counts {
+		response.Versions = append(response.Versions, count)
+		if count.Version != current {
+			response.Outdated += count.Reports
+		}
+	}
+
+	return response, nil
+}
+
+// ReanalyzeOutdated заново анализирует завершённые отчёты, посчитанные не текущей версией анализа
+func (s *analysisService) ReanalyzeOutdated(ctx context.Context, limit int) (*models.RetryFailedResponse, error) {
+	ctx = WithTriggerSource(ctx, models.TriggerSourceReanalyze)
+	current := s.plagiarismChecker.GetCheckerInfo().Version
+
+	reports, err := s.reportRepo.GetOutdatedReports(ctx, current, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outdated reports: %w", err)
+	}
+
+	concurrency := s.config.RetryConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]models.RetryResult, len(reports))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range reports {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, report *models.Report) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = s.reanalyzeReport(ctx, report)
+		}(i, &reports[i])
+	}
+	wg.Wait()
+
+	response := &models.RetryFailedResponse{
+		Total:   len(reports),
+		Results: results,
+	}
+	for _, result := range results {
+		if result.Status == "reanalyzed" {
+			response.Retried++
+		} else {
+			response.Failed++
+		}
+	}
+
+	s.logger.Info().
+		Str("version", current).
+		Int("total", response.Total).
+		Int("reanalyzed", response.Retried).
+		Int("failed", response.Failed).
+		Msg("Outdated reports reanalysis completed")
+
+	return response, nil
+}
+
+func (s *analysisService) reanalyzeReport(ctx context.Context, report *models.Report) models.RetryResult {
+	result := models.RetryResult{
+		ReportID: report.ID,
+		WorkID:   report.WorkID,
+		Status:   "failed",
+	}
+
+	// Завершённый отчёт AnalyzeWork отдаёт из кеша, поэтому сначала сбрасываем статус
+	if err := s.reportRepo.UpdateStatus(ctx, report.ID, models.ReportStatusPending.String()); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if _, err := s.AnalyzeWork(ctx, report.WorkID, report.FileID, report.AssignmentID, report.StudentID); err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("work_id", report.WorkID).
+			Msg("Failed to reanalyze report")
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = "reanalyzed"
+	return result
+}
@@ -0,0 +1,255 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	ContentTypeText = "text"
+	ContentTypeCode = "code"
+
+	// Длина k-грамм токенов, по которым сравнивается структура кода
+	codeShingleSize = 4
+)
+
+var codeKeywords = map[string][]string{
+	"go": {
+		"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough",
+		"for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range",
+		"return", "select", "struct", "switch", "type", "var", "nil", "true", "false",
+	},
+	"python": {
+		"and", "as", "assert", "async", "await", "break", "class", "continue", "def", "del",
+		"elif", "else", "except", "finally", "for", "from", "global", "if", "import", "in",
+		"is", "lambda", "nonlocal", "not", "or", "pass", "raise", "return", "try", "while",
+		"with", "yield", "None", "True", "False",
+	},
+	"java": {
+		"abstract", "boolean", "break", "byte", "case", "catch", "char", "class", "continue",
+		"default", "do", "double", "else", "enum", "extends", "final", "finally", "float", "for",
+		"if", "implements", "import", "instanceof", "int", "interface", "long", "new", "package",
+		"private", "protected", "public", "return", "short", "static", "super", "switch", "this",
+		"throw", "throws", "try", "void", "while", "null", "true", "false",
+	},
+	"c": {
+		"auto", "break", "case", "char", "const", "continue", "default", "do", "double", "else",
+		"enum", "extern", "float", "for", "goto", "if", "int", "long", "register", "return",
+		"short", "signed", "sizeof", "static", "struct", "switch", "typedef", "union", "unsigned",
+		"void", "volatile", "while",
+	},
+	"cpp": {
+		"auto", "bool", "break", "case", "catch", "char", "class", "const", "continue", "default",
+		"delete", "do", "double", "else", "enum", "false", "float", "for", "if", "int", "long",
+		"namespace", "new", "nullptr", "private", "protected", "public", "return", "short",
+		"sizeof", "static", "struct", "switch", "template", "this", "throw", "true", "try",
+		"typedef", "using", "virtual", "void", "while",
+	},
+	"javascript": {
+		"async", "await", "break", "case", "catch", "class", "const", "continue", "default",
+		"delete", "do", "else", "export", "extends", "false", "finally", "for", "function", "if",
+		"import", "in", "instanceof", "let", "new", "null", "return", "switch", "this", "throw",
+		"true", "try", "typeof", "undefined", "var", "void", "while", "yield",
+	},
+}
+
+// CodeSimilarityAnalyzer сравнивает исходный код с учётом токенов:
+// комментарии и пробелы отбрасываются, идентификаторы и литералы
+// заменяются плейсхолдерами, поэтому переименование переменных
+// и переформатирование не влияют на результат.
+type CodeSimilarityAnalyzer interface {
+	SimilarityAnalyzer
+	Language() string
+	Normalization() []string
+}
+
+type codeSimilarityAnalyzer struct {
+	language string
+	keywords map[string]bool
+	text     *similarityAnalyzer
+	logger   zerolog.Logger
+}
+
+func NewCodeSimilarityAnalyzer(language string, logger zerolog.Logger) CodeSimilarityAnalyzer {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if _, ok := codeKeywords[language]; !ok {
+		language = "generic"
+	}
+
+	keywords := make(map[string]bool)
+	for lang, words := range codeKeywords {
+		if language != "generic" && lang != language {
+			continue
+		}
+		for _, word := range words {
+			keywords[word] = true
+		}
+	}
+
+	return &codeSimilarityAnalyzer{
+		language: language,
+		keywords: keywords,
+		text:     &similarityAnalyzer{logger: logger},
+		logger:   logger,
+	}
+}
+
+func (a *codeSimilarityAnalyzer) Language() string {
+	return a.language
+}
+
+func (a *codeSimilarityAnalyzer) Normalization() []string {
+	return []string{"strip_comments", "collapse_whitespace", "rename_identifiers", "mask_literals"}
+}
+
+func (a *codeSimilarityAnalyzer) AnalyzeContent(ctx context.Context, file1, file2 []byte) (float64, error) {
+	startTime := time.Now()
+
+	code1, err := a.ExtractText(file1)
+	if err != nil {
+		return 0, fmt.Errorf("failed to extract text from first file: %w", err)
+	}
+
+	code2, err := a.ExtractText(file2)
+	if err != nil {
+		return 0, fmt.Errorf("failed to extract text from second file: %w", err)
+	}
+
+	similarity := a.CalculateSimilarity(code1, code2)
+
+	a.logger.Debug().
+		Str("language", a.language).
+		Int("tokens1", len(strings.Fields(code1))).
+		Int("tokens2", len(strings.Fields(code2))).
+		Float64("similarity", similarity).
+		Dur("processing_time", time.Since(startTime)).
+		Msg("Code analysis completed")
+
+	return similarity, nil
+}
+
+// ExtractText возвращает нормализованный поток токенов, разделённых пробелом.
+func (a *codeSimilarityAnalyzer) ExtractText(content []byte) (string, error) {
+	if !utf8.Valid(content) {
+		return "", errors.New("content is not valid UTF-8 source code")
+	}
+
+	source := []rune(string(content))
+	tokens := make([]string, 0, len(source)/4)
+
+	for i := 0; i < len(source); {
+		ch := source[i]
+
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+
+		case ch == '/' && i+1 < len(source) && source[i+1] == '/',
+			ch == '#' && a.language == "python":
+			for i < len(source) && source[i] != '\n' {
+				i++
+			}
+
+		case ch == '/' && i+1 < len(source) && source[i+1] == '*':
+			i += 2
+			for i+1 < len(source) && !(source[i] == '*' && source[i+1] == '/') {
+				i++
+			}
+			i += 2
+
+		case ch == '"' || ch == '\'' || ch == '`':
+			i++
+			for i < len(source) && source[i] != ch {
+				if source[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+			tokens = append(tokens, "STR")
+
+		case unicode.IsDigit(ch):
+			for i < len(source) && (unicode.IsLetter(source[i]) || unicode.IsDigit(source[i]) || source[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, "NUM")
+
+		case unicode.IsLetter(ch) || ch == '_':
+			start := i
+			for i < len(source) && (unicode.IsLetter(source[i]) || unicode.IsDigit(source[i]) || source[i] == '_') {
+				i++
+			}
+			word := string(source[start:i])
+			if a.keywords[word] {
+				tokens = append(tokens, word)
+			} else {
+				tokens = append(tokens, "ID")
+			}
+
+		default:
+			tokens = append(tokens, string(ch))
+			i++
+		}
+	}
+
+	return strings.Join(tokens, " "), nil
+}
+
+// CalculateSimilarity считает коэффициент Жаккара по k-граммам токенов,
+// чтобы учитывалась структура кода, а не только набор ключевых слов.
+func (a *codeSimilarityAnalyzer) CalculateSimilarity(code1, code2 string) float64 {
+	if code1 == "" || code2 == "" {
+		return 0.0
+	}
+
+	set1 := tokenShingles(strings.Fields(code1), codeShingleSize)
+	set2 := tokenShingles(strings.Fields(code2), codeShingleSize)
+
+	intersection := 0
+	for shingle := range set1 {
+		if set2[shingle] {
+			intersection++
+		}
+	}
+
+	union := len(set1) + len(set2) - intersection
+	if union == 0 {
+		return 0.0
+	}
+
+	return float64(intersection) / float64(union)
+}
+
+// CalculateEditSimilarity сравнивает нормализованный код посимвольно; длинный код — по k-граммам токенов
+func (a *codeSimilarityAnalyzer) CalculateEditSimilarity(code1, code2 string) float64 {
+	if similarity, ok := editSimilarity(code1, code2); ok {
+		return similarity
+	}
+	return a.CalculateSimilarity(code1, code2)
+}
+
+func (a *codeSimilarityAnalyzer) FindSimilarSections(code1, code2 string, minLength int) []SimilarSection {
+	return a.text.FindSimilarSections(code1, code2, minLength)
+}
+
+func tokenShingles(tokens []string, k int) map[string]bool {
+	shingles := make(map[string]bool)
+	if len(tokens) < k {
+		if len(tokens) > 0 {
+			shingles[strings.Join(tokens, " ")] = true
+		}
+		return shingles
+	}
+
+	for i := 0; i <= len(tokens)-k; i++ {
+		shingles[strings.Join(tokens[i:i+k], " ")] = true
+	}
+	return shingles
+}
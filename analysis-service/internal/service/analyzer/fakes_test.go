@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
+)
+
+// Заглушки клиентов и хранилищ проверяющего: встроенный интерфейс остаётся nil, поэтому вызов
+// метода, который тест не ожидает, сразу падает с паникой
+
+var errFileNotFound = errors.New("file not found")
+
+// fakeFileClient отдаёт файлы из памяти и считает загрузки содержимого по file_id;
+// contentHashes — content_hash файлов, как его посчитал бы file-service
+type fakeFileClient struct {
+	integration.FileClient
+
+	mu            sync.Mutex
+	files         map[string][]byte
+	contentHashes map[string]string
+	downloads     map[string]int
+}
+
+func newFakeFileClient(files map[string][]byte) *fakeFileClient {
+	return &fakeFileClient{files: files, contentHashes: make(map[string]string), downloads: make(map[string]int)}
+}
+
+func (f *fakeFileClient) GetFileHash(_ context.Context, fileID string) (string, int64, error) {
+	content, ok := f.files[fileID]
+	if !ok {
+		return "", 0, errFileNotFound
+	}
+	return sha256Hex(content), int64(len(content)), nil
+}
+
+func (f *fakeFileClient) GetFileContent(_ context.Context, fileID string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	content, ok := f.files[fileID]
+	if !ok {
+		return nil, errFileNotFound
+	}
+	f.downloads[fileID]++
+	return content, nil
+}
+
+func (f *fakeFileClient) GetContentHash(_ context.Context, fileID string) (string, error) {
+	if _, ok := f.files[fileID]; !ok {
+		return "", errFileNotFound
+	}
+	return f.contentHashes[fileID], nil
+}
+
+func (f *fakeFileClient) downloadCount(fileID string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.downloads[fileID]
+}
+
+// previousWork — работа задания с хешем и размером её файла из fakeFileClient
+func (f *fakeFileClient) previousWork(workID, studentID, fileID string) models.SimilarWork {
+	content := f.files[fileID]
+	return models.SimilarWork{
+		WorkID:    workID,
+		StudentID: studentID,
+		FileID:    fileID,
+		FileHash:  sha256Hex(content),
+		FileSize:  int64(len(content)),
+	}
+}
+
+type memFingerprintStore struct {
+	mu    sync.Mutex
+	items map[string][]uint64
+	saves int
+}
+
+func newMemFingerprintStore() *memFingerprintStore {
+	return &memFingerprintStore{items: make(map[string][]uint64)}
+}
+
+func (s *memFingerprintStore) GetFingerprints(_ context.Context, workID, method, fileHash string) ([]uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fingerprints, ok := s.items[workID+"|"+method+"|"+fileHash]
+	return fingerprints, ok, nil
+}
+
+func (s *memFingerprintStore) SaveFingerprints(_ context.Context, workID, method, fileHash string, fingerprints []uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[workID+"|"+method+"|"+fileHash] = fingerprints
+	s.saves++
+	return nil
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
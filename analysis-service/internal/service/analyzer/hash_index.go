@@ -0,0 +1,237 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+var ErrHashIndexDisabled = errors.New("hash index is disabled")
+
+// hashIndex — точные копии файлов по заданиям: assignment_id → file_hash → работы.
+// Строится из завершённых отчётов и пополняется после каждого анализа, поэтому содержит
+// только уже проанализированные работы; точная копия находится без запроса работ задания.
+type hashIndex struct {
+	mu          sync.RWMutex
+	assignments map[string]map[string][]models.HashIndexEntry
+	rebuiltAt   time.Time
+}
+
+func newHashIndex() *hashIndex {
+	return &hashIndex{
+		assignments: make(map[string]map[string][]models.HashIndexEntry),
+	}
+}
+
+// replace заменяет записи задания или, при пустом assignmentID, весь индекс
+func (i *hashIndex) replace(assignmentID string, entries []models.HashIndexEntry) {
+	built := make(map[string]map[string][]models.HashIndexEntry)
+	for _, entry := range entries {
+		hashes, ok := built[entry.AssignmentID]
+		if !ok {
+			hashes = make(map[string][]models.HashIndexEntry)
+			built[entry.AssignmentID] = hashes
+		}
+		hashes[entry.FileHash] = append(hashes[entry.FileHash], entry)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if assignmentID == "" {
+		i.assignments = built
+		i.rebuiltAt = time.Now()
+		return
+	}
+
+	delete(i.assignments, assignmentID)
+	if hashes, ok := built[assignmentID]; ok {
+		i.assignments[assignmentID] = hashes
+	}
+}
+
+// add добавляет работу; повторный анализ той же работы заменяет её запись
+func (i *hashIndex) add(entry models.HashIndexEntry) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	hashes, ok := i.assignments[entry.AssignmentID]
+	if !ok {
+		hashes = make(map[string][]models.HashIndexEntry)
+		i.assignments[entry.AssignmentID] = hashes
+	}
+
+	for hash, works := range hashes {
+		for n, work := range works {
+			if work.WorkID != entry.WorkID {
+				continue
+			}
+			if hash == entry.FileHash {
+				works[n] = entry
+				return
+			}
+			hashes[hash] = append(works[:n:n], works[n+1:]...)
+			if len(hashes[hash]) == 0 {
+				delete(hashes, hash)
+			}
+			break
+		}
+	}
+
+	hashes[entry.FileHash] = append(hashes[entry.FileHash], entry)
+}
+
+// earlierCopies возвращает работы задания с тем же хешем, проанализированные раньше workID,
+// по возрастанию времени
+func (i *hashIndex) earlierCopies(assignmentID, fileHash, workID string) []models.HashIndexEntry {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	works := i.assignments[assignmentID][fileHash]
+
+	// При повторном анализе учитываются только работы, проанализированные до первой проверки этой
+	var before time.Time
+	for _, work := range works {
+		if work.WorkID == workID {
+			before = work.AnalyzedAt
+		}
+	}
+
+	copies := make([]models.HashIndexEntry, 0, len(works))
+	for _, work := range works {
+		if work.WorkID == workID || (!before.IsZero() && !work.AnalyzedAt.Before(before)) {
+			continue
+		}
+		copies = append(copies, work)
+	}
+
+	sort.Slice(copies, func(a, b int) bool {
+		return copies[a].AnalyzedAt.Before(copies[b].AnalyzedAt)
+	})
+	return copies
+}
+
+func (i *hashIndex) stats(assignmentID string) *models.HashIndexStats {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	stats := &models.HashIndexStats{AssignmentID: assignmentID, RebuiltAt: i.rebuiltAt}
+	for id, hashes := range i.assignments {
+		if assignmentID != "" && id != assignmentID {
+			continue
+		}
+		stats.Assignments++
+		stats.Hashes += len(hashes)
+		for _, works := range hashes {
+			stats.Works += len(works)
+		}
+	}
+	return stats
+}
+
+func (c *plagiarismChecker) RebuildHashIndex(assignmentID string, entries []models.HashIndexEntry) (*models.HashIndexStats, error) {
+	if c.hashIndex == nil {
+		return nil, ErrHashIndexDisabled
+	}
+
+	c.hashIndex.replace(assignmentID, entries)
+	return c.hashIndex.stats(assignmentID), nil
+}
+
+func (c *plagiarismChecker) IndexWork(entry models.HashIndexEntry) {
+	if c.hashIndex == nil || entry.FileHash == "" {
+		return
+	}
+	c.hashIndex.add(entry)
+}
+
+// exactCopyResult проверяет работу по индексу точных копий, не запрашивая работы задания:
+// файл, побайтно совпадающий с уже проанализированной работой другого студента, совпадает на 100%.
+// nil — такой копии в индексе нет, нужна обычная проверка.
+func (c *plagiarismChecker) exactCopyResult(workID, assignmentID, studentID, fileHash string, fileSize int64, threshold int, startTime time.Time, timings *models.PhaseTimings) *models.AnalysisResult {
+	if c.hashIndex == nil {
+		return nil
+	}
+
+	copies := c.hashIndex.earlierCopies(assignmentID, fileHash, workID)
+	var originalWorkID *string
+	for n := range copies {
+		if copies[n].StudentID != studentID {
+			originalWorkID = &copies[n].WorkID
+			break
+		}
+	}
+	if originalWorkID == nil {
+		return nil
+	}
+
+	similarWorks := make([]models.SimilarWork, 0, len(copies))
+	details := models.ReportDetails{
+		ComparisonResults: make([]models.ComparisonResult, 0, len(copies)),
+		FileInfo: models.FileInfo{
+			FileSize: fileSize,
+		},
+		AnalysisMetadata: models.AnalysisMetadata{
+			AlgorithmUsed:    c.config.HashAlgorithm,
+			SimilarityMethod: "exact_hash_index",
+			AnalysisVersion:  c.config.AnalysisVersion,
+			Threshold:        threshold,
+			ContentType:      c.contentType(assignmentID),
+			StartedAt:        startTime,
+			CompletedAt:      time.Now(),
+			PhaseTimings:     timings,
+			ScoreMethod:      models.ScoreMethodExactHash,
+		},
+	}
+
+	for _, work := range copies {
+		// Собственная прежняя попытка студента — не источник списывания
+		if work.StudentID == studentID {
+			continue
+		}
+		similarWorks = append(similarWorks, models.SimilarWork{
+			WorkID:          work.WorkID,
+			StudentID:       work.StudentID,
+			AssignmentID:    work.AssignmentID,
+			MatchPercentage: 100,
+			FileHash:        work.FileHash,
+			SubmittedAt:     work.AnalyzedAt,
+		})
+		details.ComparisonResults = append(details.ComparisonResults, models.ComparisonResult{
+			ComparedWorkID:  work.WorkID,
+			StudentID:       work.StudentID,
+			MatchPercentage: 100,
+			FileHash:        work.FileHash,
+			ComparedAt:      time.Now().Format(time.RFC3339),
+			ScoreMethod:     models.ScoreMethodExactHash,
+		})
+	}
+
+	detailsJSON, _ := json.Marshal(details)
+
+	result := &models.AnalysisResult{
+		WorkID:            workID,
+		Status:            "completed",
+		PlagiarismFlag:    true,
+		OriginalWorkID:    originalWorkID,
+		MatchPercentage:   100,
+		FileHash:          fileHash,
+		SimilarWorks:      similarWorks,
+		ComparedWithCount: len(similarWorks),
+		ProcessingTimeMs:  int(time.Since(startTime).Milliseconds()),
+		AnalyzedAt:        time.Now(),
+		Details:           detailsJSON,
+	}
+
+	c.logger.Info().
+		Str("work_id", workID).
+		Str("original_work_id", *originalWorkID).
+		Int("exact_copies", len(similarWorks)).
+		Msg("Exact copy found in hash index")
+
+	return result
+}
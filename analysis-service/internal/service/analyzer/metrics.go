@@ -0,0 +1,35 @@
+package analyzer
+
+import (
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/prommetrics"
+)
+
+type CheckerMetrics struct {
+	Checks   *prommetrics.Counter
+	Failed   *prommetrics.Counter
+	Detected *prommetrics.Counter
+}
+
+func NewCheckerMetrics(reg *prommetrics.Registry) *CheckerMetrics {
+	return &CheckerMetrics{
+		Checks:   reg.NewCounter("analysis_plagiarism_checks_total", "Plagiarism checks performed."),
+		Failed:   reg.NewCounter("analysis_plagiarism_checks_failed_total", "Plagiarism checks that returned an error."),
+		Detected: reg.NewCounter("analysis_plagiarism_detected_total", "Plagiarism checks that flagged the work."),
+	}
+}
+
+func (m *CheckerMetrics) observe(result *models.AnalysisResult, err error) {
+	if m == nil {
+		return
+	}
+
+	m.Checks.Inc()
+	if err != nil {
+		m.Failed.Inc()
+		return
+	}
+	if result != nil && result.PlagiarismFlag {
+		m.Detected.Inc()
+	}
+}
@@ -0,0 +1,189 @@
+package analyzer
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/sharedcache"
+)
+
+// PairCache хранит результат анализа содержимого пары файлов по упорядоченной паре их хешей.
+// Содержимое с данным хешем не меняется, поэтому повторное сравнение той же пары не скачивает
+// файлы и не пересчитывает оценку; записи только вытесняются по LRU или TTL.
+type PairCache interface {
+	Get(key string) (PairResult, bool)
+	Put(key string, result PairResult)
+	Stats() PairCacheStats
+}
+
+type PairCacheStats struct {
+	Entries int   `json:"entries"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+// PairResult — оценка пары, где первый файл — с меньшим хешем. Оценки в обе стороны хранятся
+// отдельно: доля общих отпечатков winnowing зависит от того, какая работа проверяется.
+type PairResult struct {
+	ScoreMethod string `json:"score_method"`
+	FirstScore  int    `json:"first_score"`
+	SecondScore int    `json:"second_score"`
+	// Совпавшие фрагменты ищутся только для пар не ниже порога; без них запись не подходит
+	// проверке, которой фрагменты нужны
+	SectionsFound bool                    `json:"sections_found"`
+	Sections      []models.MatchedSection `json:"sections,omitempty"`
+}
+
+type pairCacheEntry struct {
+	key    string
+	result PairResult
+}
+
+type pairCache struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List
+	hits       int64
+	misses     int64
+	maxEntries int
+}
+
+// NewPairCache ограничивает кеш числом пар (0 — без ограничения)
+func NewPairCache(maxEntries int) PairCache {
+	return &pairCache{
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *pairCache) Get(key string) (PairResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return PairResult{}, false
+	}
+
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*pairCacheEntry).result, true
+}
+
+func (c *pairCache) Put(key string, result PairResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Запись с найденными фрагментами заменяет прежнюю, посчитанную при более высоком пороге
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*pairCacheEntry).result = result
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&pairCacheEntry{key: key, result: result})
+
+	for c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*pairCacheEntry).key)
+	}
+}
+
+func (c *pairCache) Stats() PairCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return PairCacheStats{
+		Entries: c.order.Len(),
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+}
+
+// sharedPairCache хранит результаты пар в общем хранилище (Redis) в JSON, чтобы их использовали
+// все экземпляры. Вытеснение — по TTL, поэтому Entries не считается.
+type sharedPairCache struct {
+	store  sharedcache.Store
+	ttl    time.Duration
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func NewSharedPairCache(store sharedcache.Store, ttl time.Duration) PairCache {
+	return &sharedPairCache{store: store, ttl: ttl}
+}
+
+func (c *sharedPairCache) Get(key string) (PairResult, bool) {
+	var result PairResult
+	value, ok, err := c.store.Get(context.Background(), "pair:"+key)
+	if err != nil || !ok || json.Unmarshal([]byte(value), &result) != nil {
+		c.misses.Add(1)
+		return PairResult{}, false
+	}
+
+	c.hits.Add(1)
+	return result, true
+}
+
+func (c *sharedPairCache) Put(key string, result PairResult) {
+	value, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	_ = c.store.Set(context.Background(), "pair:"+key, string(value), c.ttl)
+}
+
+func (c *sharedPairCache) Stats() PairCacheStats {
+	return PairCacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+}
+
+// forHash возвращает оценку и фрагменты пары с точки зрения файла hash: для второго файла пары
+// координаты фрагментов меняются местами
+func (r PairResult) forHash(hash, otherHash string) (int, []models.MatchedSection) {
+	if hash <= otherHash {
+		return r.FirstScore, r.Sections
+	}
+
+	sections := make([]models.MatchedSection, len(r.Sections))
+	for i, section := range r.Sections {
+		sections[i] = models.MatchedSection{
+			Text1Start: section.Text2Start,
+			Text1End:   section.Text2End,
+			Text2Start: section.Text1Start,
+			Text2End:   section.Text1End,
+			Similarity: section.Similarity,
+			Text:       section.Text,
+		}
+	}
+	return r.SecondScore, sections
+}
+
+// newPairResult раскладывает оценки проверки файла hash в порядок хешей пары
+func newPairResult(hash, otherHash, method string, score, reverseScore int, sections []models.MatchedSection, sectionsFound bool) PairResult {
+	result := PairResult{
+		ScoreMethod:   method,
+		FirstScore:    score,
+		SecondScore:   reverseScore,
+		SectionsFound: sectionsFound,
+		Sections:      sections,
+	}
+	if hash <= otherHash {
+		return result
+	}
+
+	// forHash симметрична: переворот с точки зрения второго файла даёт порядок пары
+	result.FirstScore, result.SecondScore = reverseScore, score
+	_, result.Sections = result.forHash(hash, otherHash)
+	return result
+}
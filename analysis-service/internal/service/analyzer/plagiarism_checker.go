@@ -3,33 +3,85 @@ package analyzer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/sharedcache"
 	"github.com/rs/zerolog"
 )
 
 type PlagiarismChecker interface {
-	CheckPlagiarism(ctx context.Context, workID, fileID, assignmentID, studentID string) (*models.AnalysisResult, error)
+	// threshold — порог плагиата, уже выбранный для задания (собственный или общий из конфигурации)
+	CheckPlagiarism(ctx context.Context, workID, fileID, assignmentID, studentID string, threshold int) (*models.AnalysisResult, error)
+	// CheckPlagiarismAgainst сравнивает работу с заранее полученным набором работ задания
+	CheckPlagiarismAgainst(ctx context.Context, workID, fileID, assignmentID, studentID string, previousWorks []models.SimilarWork, threshold int) (*models.AnalysisResult, error)
 	BatchCheck(ctx context.Context, requests []models.PlagiarismCheckRequest) ([]models.AnalysisResult, error)
+	// Warmup заранее загружает в кеш базу сравнения задания
+	Warmup(ctx context.Context, assignmentID string) (*models.WarmupResult, error)
+	// RebuildHashIndex заменяет записи индекса точных копий задания (при пустом assignmentID — всех заданий)
+	RebuildHashIndex(assignmentID string, entries []models.HashIndexEntry) (*models.HashIndexStats, error)
+	// IndexWork добавляет проанализированную работу в индекс точных копий; без индекса ничего не делает
+	IndexWork(entry models.HashIndexEntry)
+	// IndexReference строит и сохраняет отпечатки winnowing документа базы сравнения под его referenceID;
+	// возвращает их число, без winnowing — 0
+	IndexReference(ctx context.Context, referenceID, fileID, fileHash, contentType string) (int, error)
+	// CheckPreview — пробное сравнение файла с работами задания, ничего не сохраняющее
+	CheckPreview(ctx context.Context, file PreviewFile, assignmentID, studentID string, threshold int) (*models.AnalysisResult, error)
 	GetCheckerInfo() CheckerInfo
 }
 
+// AlgorithmVersion пишется в analysis_metadata.analysis_version каждого отчёта. Отчёты с разными
+// версиями нельзя сравнивать напрямую: увеличивайте её при любом изменении, влияющем на match_percentage
+const AlgorithmVersion = "2.0"
+
+// ErrTextExtractionFailed — файл не удалось привести к тексту (бинарный или повреждённый)
+var ErrTextExtractionFailed = errors.New("text extraction failed")
+
+const (
+	ExtractionFallbackHash = "hash"
+	ExtractionFallbackFail = "fail"
+
+	// Параметры сохранения совпавших фрагментов в деталях отчёта
+	matchedSectionMinWords = 10
+	maxMatchedSections     = 20
+)
+
 type CheckerInfo struct {
-	Name        string `json:"name"`
-	Version     string `json:"version"`
-	Algorithm   string `json:"algorithm"`
-	Description string `json:"description"`
+	Name        string          `json:"name"`
+	Version     string          `json:"version"`
+	Algorithm   string          `json:"algorithm"`
+	Description string          `json:"description"`
+	TextCache   *TextCacheStats `json:"text_cache,omitempty"`
+	PairCache   *PairCacheStats `json:"pair_cache,omitempty"`
 }
 
 type plagiarismChecker struct {
 	workClient     integration.WorkClient
 	fileClient     integration.FileClient
 	hashComparator HashComparator
-	logger         zerolog.Logger
-	config         PlagiarismCheckerConfig
+	textAnalyzer   SimilarityAnalyzer
+	codeAnalyzer   CodeSimilarityAnalyzer
+	// Ограничивает число одновременных загрузок содержимого файлов (nil — без ограничения)
+	downloadSem chan struct{}
+	// Загрузки срочных проверок, которые не ждут общий лимит (nil — срочные делят downloadSem)
+	urgentDownloadSem chan struct{}
+	// Извлечённый текст по хешу файла (nil — кеш выключен)
+	textCache TextCache
+	// Результаты анализа содержимого по паре хешей файлов (nil — кеш выключен)
+	pairCache PairCache
+	// SimHash-отпечатки по file_id (nil — прогрев выключен)
+	fingerprints *fingerprintCache
+	// Отпечатки winnowing при анализе содержимого (nil — обычная оценка сходства)
+	winnower *Fingerprinter
+	// Точные копии по заданиям (nil — индекс выключен)
+	hashIndex *hashIndex
+	logger    zerolog.Logger
+	config    PlagiarismCheckerConfig
 }
 
 type PlagiarismCheckerConfig struct {
@@ -38,6 +90,56 @@ type PlagiarismCheckerConfig struct {
 	EnableDeepAnalysis  bool
 	Timeout             time.Duration
 	MaxRetries          int
+	// Тип содержимого по заданиям (assignment_id -> text|code)
+	ContentTypes map[string]string
+	CodeLanguage string
+	// Поведение при ошибке извлечения текста: hash — сравнить по хешу, fail — упасть
+	ExtractionFallback string
+	// Максимум одновременных загрузок содержимого для анализа, общий для всех проверок (0 — без ограничения)
+	MaxConcurrentDownloads int
+	// Загрузки, зарезервированные для проверок с контекстом WithUrgent, сверх MaxConcurrentDownloads
+	UrgentDownloads int
+	// Файлы разного размера не могут совпадать побайтно — хеши для них не сравниваются
+	SizePrefilter bool
+	// Минимальное отношение меньшего размера к большему для сравнения содержимого (0 — не отсеивать)
+	MinSizeRatio float64
+	// Результаты сравнения ниже этого процента не сохраняются в деталях отчёта, только считаются
+	MinRecordedMatch int
+	// Потолок оценки сходства неидентичных файлов (0 — без ограничения), см. normalizeScore
+	PartialMatchCap int
+	// Работы не длиннее стольких символов (при сравнении только по хешам — байт файла) сравниваются
+	// по расстоянию Левенштейна: в коротком коде правка в один символ не меняет набор токенов (0 — выключено)
+	EditDistanceMaxLength int
+	// При анализе содержимого оценивать пару долей общих отпечатков winnowing из k-грамм символов
+	WinnowingEnabled bool
+	WinnowingK       int
+	WinnowingWindow  int
+	// Сохранённые отпечатки работ (nil — считаются при каждом анализе)
+	FingerprintStore FingerprintStore
+	// SimHash работ при hash_algorithm simhash, хранится один раз на работу и файл (nil — считается при каждом анализе)
+	SimHashStore FingerprintStore
+	// Кешировать извлечённый текст по хешу файла, чтобы не скачивать и не разбирать его повторно
+	TextCacheEnabled    bool
+	TextCacheMaxBytes   int64
+	TextCacheMaxEntries int
+	// Общее хранилище текста для всех экземпляров (nil — LRU в памяти процесса); записи живут TextCacheTTL
+	TextCacheStore sharedcache.Store
+	TextCacheTTL   time.Duration
+	// Кешировать результат анализа содержимого пары файлов по паре их хешей; общее хранилище
+	// PairCacheStore (nil — LRU в памяти процесса на PairCacheMaxEntries пар), записи живут PairCacheTTL
+	PairCacheEnabled    bool
+	PairCacheMaxEntries int
+	PairCacheStore      sharedcache.Store
+	PairCacheTTL        time.Duration
+	// Прогрев базы сравнения: отпечатки файлов хранятся WarmupTTL
+	WarmupEnabled bool
+	WarmupTTL     time.Duration
+	// Искать точные копии по индексу file_hash проанализированных работ до запроса работ задания
+	HashIndexEnabled bool
+	// nil — метрики не собираются
+	Metrics *CheckerMetrics
+	// Версия анализа вместо AlgorithmVersion, например при смене нормализации через конфигурацию ("" — встроенная)
+	AnalysisVersion string
 }
 
 func NewPlagiarismChecker(
@@ -47,17 +149,122 @@ func NewPlagiarismChecker(
 	logger zerolog.Logger,
 	config PlagiarismCheckerConfig,
 ) PlagiarismChecker {
+	if strings.EqualFold(config.HashAlgorithm, HashAlgorithmSimHash) {
+		hashComparator = NewSimHashComparator()
+	}
+
+	if config.AnalysisVersion == "" {
+		config.AnalysisVersion = AlgorithmVersion
+	}
+
+	var downloadSem, urgentDownloadSem chan struct{}
+	if config.MaxConcurrentDownloads > 0 {
+		downloadSem = make(chan struct{}, config.MaxConcurrentDownloads)
+		if config.UrgentDownloads > 0 {
+			urgentDownloadSem = make(chan struct{}, config.UrgentDownloads)
+		}
+	}
+
+	var textCache TextCache
+	switch {
+	case config.TextCacheEnabled && config.TextCacheStore != nil:
+		textCache = NewSharedTextCache(config.TextCacheStore, config.TextCacheTTL)
+	case config.TextCacheEnabled:
+		textCache = NewTextCache(config.TextCacheMaxBytes, config.TextCacheMaxEntries)
+	}
+
+	var pairCache PairCache
+	switch {
+	case config.PairCacheEnabled && config.PairCacheStore != nil:
+		pairCache = NewSharedPairCache(config.PairCacheStore, config.PairCacheTTL)
+	case config.PairCacheEnabled:
+		pairCache = NewPairCache(config.PairCacheMaxEntries)
+	}
+
+	var fingerprints *fingerprintCache
+	if config.WarmupEnabled {
+		fingerprints = newFingerprintCache(config.WarmupTTL)
+	}
+
+	var index *hashIndex
+	if config.HashIndexEnabled {
+		index = newHashIndex()
+	}
+
+	var winnower *Fingerprinter
+	if config.WinnowingEnabled {
+		winnower = NewFingerprinter(config.WinnowingK, config.WinnowingWindow)
+	}
+
 	return &plagiarismChecker{
-		workClient:     workClient,
-		fileClient:     fileClient,
-		hashComparator: hashComparator,
-		logger:         logger,
-		config:         config,
+		workClient:        workClient,
+		fileClient:        fileClient,
+		hashComparator:    hashComparator,
+		textAnalyzer:      NewSimilarityAnalyzer(fileClient, logger),
+		codeAnalyzer:      NewCodeSimilarityAnalyzer(config.CodeLanguage, logger),
+		downloadSem:       downloadSem,
+		urgentDownloadSem: urgentDownloadSem,
+		textCache:         textCache,
+		pairCache:         pairCache,
+		fingerprints:      fingerprints,
+		hashIndex:         index,
+		winnower:          winnower,
+		logger:            logger,
+		config:            config,
 	}
 }
 
-func (c *plagiarismChecker) CheckPlagiarism(ctx context.Context, workID, fileID, assignmentID, studentID string) (*models.AnalysisResult, error) {
+func (c *plagiarismChecker) CheckPlagiarism(ctx context.Context, workID, fileID, assignmentID, studentID string, threshold int) (*models.AnalysisResult, error) {
+	loadPrevious := func(ctx context.Context, timings *models.PhaseTimings) ([]models.SimilarWork, error) {
+		fetchStart := time.Now()
+		previousWorks, err := c.workClient.GetPreviousWorks(ctx, assignmentID, workID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get previous works: %w", err)
+		}
+		timings.PreviousWorksFetchMs = time.Since(fetchStart).Milliseconds()
+		return previousWorks, nil
+	}
+
+	result, err := c.checkAgainst(ctx, workID, fileID, assignmentID, studentID, loadPrevious, threshold)
+	c.config.Metrics.observe(result, err)
+	return result, err
+}
+
+func (c *plagiarismChecker) CheckPlagiarismAgainst(ctx context.Context, workID, fileID, assignmentID, studentID string, previousWorks []models.SimilarWork, threshold int) (*models.AnalysisResult, error) {
+	loadPrevious := func(context.Context, *models.PhaseTimings) ([]models.SimilarWork, error) {
+		return previousWorks, nil
+	}
+
+	result, err := c.checkAgainst(ctx, workID, fileID, assignmentID, studentID, loadPrevious, threshold)
+	c.config.Metrics.observe(result, err)
+	return result, err
+}
+
+// otherStudentsWorks убирает из сравнения работы самого студента: его прежняя попытка совпала бы
+// с повторной сдачей и завысила бы оценку. Общий набор пакетного анализа и предпросмотр получают
+// работы задания целиком, поэтому фильтр нужен и здесь, а не только в клиенте work-service
+func otherStudentsWorks(works []models.SimilarWork, studentID string) []models.SimilarWork {
+	if studentID == "" {
+		return works
+	}
+	filtered := make([]models.SimilarWork, 0, len(works))
+	for _, work := range works {
+		if work.StudentID != studentID {
+			filtered = append(filtered, work)
+		}
+	}
+	return filtered
+}
+
+// checkAgainst запрашивает работы задания через loadPrevious только после поиска в индексе точных копий
+func (c *plagiarismChecker) checkAgainst(
+	ctx context.Context,
+	workID, fileID, assignmentID, studentID string,
+	loadPrevious func(context.Context, *models.PhaseTimings) ([]models.SimilarWork, error),
+	threshold int,
+) (*models.AnalysisResult, error) {
 	startTime := time.Now()
+	timings := &models.PhaseTimings{}
 
 	c.logger.Info().
 		Str("work_id", workID).
@@ -65,10 +272,24 @@ func (c *plagiarismChecker) CheckPlagiarism(ctx context.Context, workID, fileID,
 		Str("assignment_id", assignmentID).
 		Msg("Starting plagiarism check")
 
-	currentFileHash, currentFileSize, err := c.fileClient.GetFileHash(ctx, fileID)
+	currentFileHash, currentFileSize, err := c.fileHash(ctx, fileID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get current file hash: %w", err)
 	}
+	timings.HashFetchMs = time.Since(startTime).Milliseconds()
+
+	if result := c.exactCopyResult(workID, assignmentID, studentID, currentFileHash, currentFileSize, threshold, startTime, timings); result != nil {
+		return result, nil
+	}
+
+	previousWorks, err := loadPrevious(ctx, timings)
+	if err != nil {
+		return nil, err
+	}
+	previousWorks = otherStudentsWorks(previousWorks, studentID)
+
+	comparisonStart := time.Now()
+	var contentFetch time.Duration
 
 	c.logger.Debug().
 		Str("work_id", workID).
@@ -76,11 +297,6 @@ func (c *plagiarismChecker) CheckPlagiarism(ctx context.Context, workID, fileID,
 		Int64("file_size", currentFileSize).
 		Msg("Got current file hash")
 
-	previousWorks, err := c.workClient.GetPreviousWorks(ctx, assignmentID, workID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get previous works: %w", err)
-	}
-
 	c.logger.Debug().
 		Str("work_id", workID).
 		Int("previous_works_count", len(previousWorks)).
@@ -107,8 +323,56 @@ func (c *plagiarismChecker) CheckPlagiarism(ctx context.Context, workID, fileID,
 		return result, nil
 	}
 
+	contentType := c.contentType(assignmentID)
+	contentAnalyzer := c.contentAnalyzer(contentType)
+
+	var currentText string
+	var fallbackReason string
+	pairFallbacks := 0
+	hashSkipped, contentSkipped := 0, 0
+	missingHashSkipped, compareFailed := 0, 0
+	if contentAnalyzer != nil {
+		fetchStart := time.Now()
+		currentText, err = c.extractContent(ctx, contentAnalyzer, contentType, fileID, currentFileHash)
+		contentFetch += time.Since(fetchStart)
+		if err != nil {
+			if !c.canFallbackToHash(err) {
+				return nil, err
+			}
+			c.logger.Warn().
+				Err(err).
+				Str("work_id", workID).
+				Msg("Text extraction failed, falling back to hash comparison")
+			fallbackReason = "text extraction failed for the analyzed file"
+			contentAnalyzer = nil
+		}
+	}
+
+	// Текст текущего файла для расстояния Левенштейна при сравнении без анализа содержимого;
+	// извлекается один раз, при первой паре маленьких файлов
+	var currentEditText string
+	currentEditOK := false
+	currentEditReady := false
+
+	// Отпечатки winnowing текущей работы строятся один раз, при первом сравнении содержимого
+	var currentWinnowing []uint64
+	currentWinnowingReady := false
+
+	// Хеш текста текущего файла запрашивается один раз, при первой паре с разными хешами файлов
+	var currentContentHash string
+	currentContentHashReady := false
+
+	// SimHash текущего файла считается один раз, при первом сравнении по хешу
+	var currentFingerprint string
+	currentFingerprintOK := false
+	fingerprintReady := false
+
 	var similarWorks []models.SimilarWork
+	matchedSections := make(map[string][]models.MatchedSection)
+	scoreMethods := make(map[string]string)
 	var highestMatch int = 0
+	var highestMethod string
+	var originalMatch int = 0
 	var originalWorkID *string
 
 	for _, prevWork := range previousWorks {
@@ -117,18 +381,147 @@ func (c *plagiarismChecker) CheckPlagiarism(ctx context.Context, workID, fileID,
 			c.logger.Warn().
 				Str("prev_work_id", prevWork.WorkID).
 				Msg("Previous work missing file hash, skipping")
+			missingHashSkipped++
 			continue
 		}
 
-		matchPercentage, err := c.hashComparator.CompareHashes(currentFileHash, prevFileHash)
-		if err != nil {
-			c.logger.Error().
-				Err(err).
-				Str("prev_work_id", prevWork.WorkID).
-				Msg("Failed to compare hashes")
-			continue
+		matchPercentage := -1
+		scoreMethod := models.ScoreMethodExactHash
+		if contentAnalyzer != nil && !c.sizeRatioAllowed(currentFileSize, prevWork.FileSize) {
+			contentSkipped++
+			matchPercentage = 0
+			scoreMethod = models.ScoreMethodContent
+		} else if contentAnalyzer != nil {
+			pairKey := c.pairCacheKey(contentType, currentFileHash, prevFileHash)
+			if pair, ok := c.cachedPair(pairKey, currentFileHash, prevFileHash, threshold); ok {
+				var sections []models.MatchedSection
+				matchPercentage, sections = pair.forHash(currentFileHash, prevFileHash)
+				scoreMethod = pair.ScoreMethod
+				if matchPercentage > 0 && matchPercentage >= threshold {
+					matchedSections[prevWork.WorkID] = sections
+				}
+			} else {
+				fetchStart := time.Now()
+				prevText, err := c.extractContent(ctx, contentAnalyzer, contentType, prevWork.FileID, prevFileHash)
+				contentFetch += time.Since(fetchStart)
+				// Оценка проверки предыдущей работы против текущей — для записи в кеш пар
+				reverseMatch := -1
+				switch {
+				case err == nil && c.editDistanceApplies(currentText, prevText):
+					matchPercentage = int(contentAnalyzer.CalculateEditSimilarity(currentText, prevText) * 100)
+					scoreMethod = models.ScoreMethodEditDistance
+				case err == nil && c.winnower != nil:
+					if !currentWinnowingReady {
+						currentWinnowing = c.winnowingFingerprints(ctx, contentType, workID, currentFileHash, currentText)
+						currentWinnowingReady = true
+					}
+					prevWinnowing := c.winnowingFingerprints(ctx, contentType, prevWork.WorkID, prevFileHash, prevText)
+					matchPercentage = int(FingerprintOverlap(currentWinnowing, prevWinnowing) * 100)
+					reverseMatch = int(FingerprintOverlap(prevWinnowing, currentWinnowing) * 100)
+					scoreMethod = models.ScoreMethodWinnowing
+				case err == nil:
+					matchPercentage = int(contentAnalyzer.CalculateSimilarity(currentText, prevText) * 100)
+					scoreMethod = models.ScoreMethodContent
+				case c.canFallbackToHash(err):
+					c.logger.Warn().
+						Err(err).
+						Str("prev_work_id", prevWork.WorkID).
+						Msg("Text extraction failed for previous work, comparing by hash")
+					pairFallbacks++
+				default:
+					return nil, err
+				}
+
+				if err == nil {
+					var sections []models.MatchedSection
+					sectionsFound := matchPercentage > 0 && matchPercentage >= threshold
+					if sectionsFound {
+						sections = findMatchedSections(contentAnalyzer, currentText, prevText)
+						matchedSections[prevWork.WorkID] = sections
+					}
+					if reverseMatch < 0 {
+						reverseMatch = matchPercentage
+					}
+					if pairKey != "" {
+						c.pairCache.Put(pairKey, newPairResult(currentFileHash, prevFileHash, scoreMethod, matchPercentage, reverseMatch, sections, sectionsFound))
+					}
+				}
+			}
+		}
+
+		// Без анализа содержимого маленькие файлы всё равно сравниваются посимвольно, а не только по хешу
+		if matchPercentage < 0 && contentAnalyzer == nil && currentFileHash != prevFileHash &&
+			c.editDistanceSizes(currentFileSize, prevWork.FileSize) {
+			fetchStart := time.Now()
+			if !currentEditReady {
+				currentEditText, err = c.extractContent(ctx, c.textAnalyzer, ContentTypeText, fileID, currentFileHash)
+				currentEditOK = err == nil
+				currentEditReady = true
+			}
+			if currentEditOK {
+				prevText, err := c.extractContent(ctx, c.textAnalyzer, ContentTypeText, prevWork.FileID, prevFileHash)
+				if err == nil {
+					matchPercentage = int(c.textAnalyzer.CalculateEditSimilarity(currentEditText, prevText) * 100)
+					scoreMethod = models.ScoreMethodEditDistance
+				} else {
+					c.logger.Debug().
+						Err(err).
+						Str("prev_work_id", prevWork.WorkID).
+						Msg("Text extraction failed for edit distance, comparing by hash")
+				}
+			}
+			contentFetch += time.Since(fetchStart)
+		}
+
+		// Тот же текст в другом файле (другой формат, пересохранённый документ) — совпадение без скачивания
+		if matchPercentage < 0 && currentFileHash != prevFileHash {
+			if !currentContentHashReady {
+				currentContentHash = c.contentHash(ctx, fileID)
+				currentContentHashReady = true
+			}
+			if currentContentHash != "" && currentContentHash == c.contentHash(ctx, prevWork.FileID) {
+				matchPercentage = 100
+				scoreMethod = models.ScoreMethodContentHash
+			}
+		}
+
+		if matchPercentage < 0 && c.config.SizePrefilter && !c.fuzzyHash() && sizesDiffer(currentFileSize, prevWork.FileSize) {
+			hashSkipped++
+			matchPercentage = 0
 		}
 
+		if matchPercentage < 0 {
+			hash1, hash2 := currentFileHash, prevFileHash
+			if c.fuzzyHash() {
+				fetchStart := time.Now()
+				if !fingerprintReady {
+					currentFingerprint, currentFingerprintOK = c.fingerprint(ctx, workID, fileID, currentFileHash)
+					fingerprintReady = true
+				}
+				// Если отпечаток одной из сторон не получен, сравниваем сохранённые хеши точно
+				if currentFingerprintOK {
+					if prevFingerprint, ok := c.fingerprint(ctx, prevWork.WorkID, prevWork.FileID, prevFileHash); ok {
+						hash1, hash2 = currentFingerprint, prevFingerprint
+						scoreMethod = models.ScoreMethodSimHash
+					}
+				}
+				contentFetch += time.Since(fetchStart)
+			}
+
+			matchPercentage, err = c.hashComparator.CompareHashes(hash1, hash2)
+			if err != nil {
+				c.logger.Error().
+					Err(err).
+					Str("prev_work_id", prevWork.WorkID).
+					Msg("Failed to compare hashes")
+				compareFailed++
+				continue
+			}
+		}
+
+		matchPercentage, scoreMethod = c.normalizeScore(currentFileHash == prevFileHash, matchPercentage, scoreMethod)
+		scoreMethods[prevWork.WorkID] = scoreMethod
+
 		similarWork := models.SimilarWork{
 			WorkID:          prevWork.WorkID,
 			StudentID:       prevWork.StudentID,
@@ -138,12 +531,15 @@ func (c *plagiarismChecker) CheckPlagiarism(ctx context.Context, workID, fileID,
 		}
 		similarWorks = append(similarWorks, similarWork)
 
-		if matchPercentage > highestMatch {
+		if matchPercentage > highestMatch || highestMethod == "" {
 			highestMatch = matchPercentage
+			highestMethod = scoreMethod
+		}
 
-			if matchPercentage == 100 && prevWork.StudentID != studentID {
-				originalWorkID = &prevWork.WorkID
-			}
+		if prevWork.StudentID != studentID && matchPercentage > 0 &&
+			matchPercentage >= threshold && matchPercentage > originalMatch {
+			originalMatch = matchPercentage
+			originalWorkID = &prevWork.WorkID
 		}
 
 		c.logger.Debug().
@@ -153,12 +549,10 @@ func (c *plagiarismChecker) CheckPlagiarism(ctx context.Context, workID, fileID,
 			Msg("Compared with previous work")
 	}
 
-	plagiarismDetected := false
-	if highestMatch >= c.config.SimilarityThreshold {
-		if originalWorkID != nil {
-			plagiarismDetected = true
-		}
-	}
+	plagiarismDetected := originalWorkID != nil
+
+	timings.ContentFetchMs = contentFetch.Milliseconds()
+	timings.ComparisonMs = (time.Since(comparisonStart) - contentFetch).Milliseconds()
 
 	details := models.ReportDetails{
 		ComparisonResults: make([]models.ComparisonResult, 0, len(similarWorks)),
@@ -168,20 +562,63 @@ func (c *plagiarismChecker) CheckPlagiarism(ctx context.Context, workID, fileID,
 		AnalysisMetadata: models.AnalysisMetadata{
 			AlgorithmUsed:    c.config.HashAlgorithm,
 			SimilarityMethod: "hash_comparison",
-			AnalysisVersion:  "1.0",
-			Threshold:        c.config.SimilarityThreshold,
+			AnalysisVersion:  c.config.AnalysisVersion,
+			Threshold:        threshold,
+			ContentType:      contentType,
 			StartedAt:        startTime,
 			CompletedAt:      time.Now(),
+			PhaseTimings:     timings,
 		},
 	}
 
+	switch {
+	case contentAnalyzer == nil:
+		if c.fuzzyHash() {
+			details.AnalysisMetadata.SimilarityMethod = "simhash_hamming"
+		}
+	case c.winnower != nil:
+		details.AnalysisMetadata.SimilarityMethod = "winnowing"
+		if contentType == ContentTypeCode {
+			details.AnalysisMetadata.Language = c.codeAnalyzer.Language()
+			details.AnalysisMetadata.Normalization = c.codeAnalyzer.Normalization()
+		}
+	case contentType == ContentTypeCode:
+		details.AnalysisMetadata.SimilarityMethod = "code_token_shingles"
+		details.AnalysisMetadata.Language = c.codeAnalyzer.Language()
+		details.AnalysisMetadata.Normalization = c.codeAnalyzer.Normalization()
+	default:
+		details.AnalysisMetadata.SimilarityMethod = "jaccard_similarity"
+	}
+
+	details.AnalysisMetadata.ScoreMethod = highestMethod
+	details.AnalysisMetadata.HashSkippedBySize = hashSkipped
+	details.AnalysisMetadata.ContentSkippedBySize = contentSkipped
+	details.AnalysisMetadata.SkippedMissingHash = missingHashSkipped
+	details.AnalysisMetadata.SkippedCompareError = compareFailed
+	details.AnalysisMetadata.HashFallbackPairs = pairFallbacks
+	details.AnalysisMetadata.CoverageRecorded = true
+
+	if fallbackReason == "" && pairFallbacks > 0 {
+		fallbackReason = fmt.Sprintf("text extraction failed for %d compared works", pairFallbacks)
+	}
+	if fallbackReason != "" {
+		details.AnalysisMetadata.FallbackMethod = "hash_comparison"
+		details.AnalysisMetadata.FallbackReason = fallbackReason
+	}
+
 	for _, work := range similarWorks {
+		if work.MatchPercentage < c.config.MinRecordedMatch {
+			details.AnalysisMetadata.UnrecordedComparisons++
+			continue
+		}
 		details.ComparisonResults = append(details.ComparisonResults, models.ComparisonResult{
 			ComparedWorkID:  work.WorkID,
 			StudentID:       work.StudentID,
 			MatchPercentage: work.MatchPercentage,
 			FileHash:        work.FileHash,
 			ComparedAt:      time.Now().Format(time.RFC3339),
+			MatchedSections: matchedSections[work.WorkID],
+			ScoreMethod:     scoreMethods[work.WorkID],
 		})
 	}
 
@@ -206,11 +643,262 @@ func (c *plagiarismChecker) CheckPlagiarism(ctx context.Context, workID, fileID,
 	return result, nil
 }
 
+func (c *plagiarismChecker) contentType(assignmentID string) string {
+	if c.config.ContentTypes[assignmentID] == ContentTypeCode {
+		return ContentTypeCode
+	}
+	return ContentTypeText
+}
+
+// contentAnalyzer возвращает nil, если сравнение идёт только по хешам.
+// Для заданий с кодом анализ содержимого выполняется всегда.
+func (c *plagiarismChecker) contentAnalyzer(contentType string) SimilarityAnalyzer {
+	if contentType == ContentTypeCode {
+		return c.codeAnalyzer
+	}
+	if c.config.EnableDeepAnalysis {
+		return c.textAnalyzer
+	}
+	return nil
+}
+
+func findMatchedSections(contentAnalyzer SimilarityAnalyzer, text1, text2 string) []models.MatchedSection {
+	sections := contentAnalyzer.FindSimilarSections(text1, text2, matchedSectionMinWords)
+	if len(sections) > maxMatchedSections {
+		sections = sections[:maxMatchedSections]
+	}
+
+	matched := make([]models.MatchedSection, 0, len(sections))
+	for _, section := range sections {
+		matched = append(matched, models.MatchedSection{
+			Text1Start: section.Text1Start,
+			Text1End:   section.Text1End,
+			Text2Start: section.Text2Start,
+			Text2End:   section.Text2End,
+			Similarity: section.Similarity,
+			Text:       section.Text,
+		})
+	}
+	return matched
+}
+
+// extractContent при включённом кеше берёт текст по хешу файла без загрузки и разбора.
+// Ошибки извлечения не кешируются.
+func (c *plagiarismChecker) extractContent(ctx context.Context, contentAnalyzer SimilarityAnalyzer, contentType, fileID, fileHash string) (string, error) {
+	cacheKey := c.textCacheKey(contentType, fileHash)
+	if cacheKey != "" {
+		if text, ok := c.textCache.Get(cacheKey); ok {
+			return text, nil
+		}
+	}
+
+	content, err := c.downloadContent(ctx, fileID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	text, err := contentAnalyzer.ExtractText(content)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrTextExtractionFailed, err)
+	}
+
+	if cacheKey != "" {
+		c.textCache.Put(cacheKey, text)
+	}
+
+	return text, nil
+}
+
+// textCacheKey учитывает способ нормализации: один и тот же файл даёт разный текст
+// для анализа текста и кода на разных языках
+func (c *plagiarismChecker) textCacheKey(contentType, fileHash string) string {
+	if c.textCache == nil || fileHash == "" {
+		return ""
+	}
+	if contentType == ContentTypeCode {
+		return contentType + ":" + c.codeAnalyzer.Language() + ":" + fileHash
+	}
+	return contentType + ":" + fileHash
+}
+
+// pairCacheKey учитывает всё, от чего зависит оценка пары: версию анализа, нормализацию,
+// параметры winnowing и расстояния Левенштейна. Хеши упорядочены, чтобы пара давала один ключ
+// независимо от того, какая из работ проверяется.
+func (c *plagiarismChecker) pairCacheKey(contentType, hash1, hash2 string) string {
+	if c.pairCache == nil || hash1 == "" || hash2 == "" {
+		return ""
+	}
+	if hash1 > hash2 {
+		hash1, hash2 = hash2, hash1
+	}
+
+	normalization := contentType
+	if contentType == ContentTypeCode {
+		normalization += ":" + c.codeAnalyzer.Language()
+	}
+	winnowing := "off"
+	if c.winnower != nil {
+		winnowing = fmt.Sprintf("%d-%d", c.config.WinnowingK, c.config.WinnowingWindow)
+	}
+	return fmt.Sprintf("%s:%s:w%s:e%d:%s:%s",
+		c.config.AnalysisVersion, normalization, winnowing, c.config.EditDistanceMaxLength, hash1, hash2)
+}
+
+// cachedPair возвращает сохранённый результат пары, если он годится для проверки с порогом
+// threshold: при оценке не ниже порога нужны совпавшие фрагменты
+func (c *plagiarismChecker) cachedPair(key, hash, otherHash string, threshold int) (PairResult, bool) {
+	if key == "" {
+		return PairResult{}, false
+	}
+
+	pair, ok := c.pairCache.Get(key)
+	if !ok {
+		return PairResult{}, false
+	}
+	if score, _ := pair.forHash(hash, otherHash); score > 0 && score >= threshold && !pair.SectionsFound {
+		return PairResult{}, false
+	}
+	return pair, true
+}
+
+func (c *plagiarismChecker) downloadContent(ctx context.Context, fileID string) ([]byte, error) {
+	if content, ok := uploadedContent(ctx, fileID); ok {
+		return content, nil
+	}
+
+	sem := c.downloadSem
+	if c.urgentDownloadSem != nil && isUrgent(ctx) {
+		sem = c.urgentDownloadSem
+	}
+
+	if sem != nil {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return c.fileClient.GetFileContent(ctx, fileID)
+}
+
+// sizeRatioAllowed отсеивает пары, слишком разные по длине, чтобы заметно совпадать.
+// Если размер одного из файлов неизвестен, пара не отсеивается.
+func (c *plagiarismChecker) sizeRatioAllowed(size1, size2 int64) bool {
+	if c.config.MinSizeRatio <= 0 || size1 <= 0 || size2 <= 0 {
+		return true
+	}
+
+	smaller, larger := size1, size2
+	if smaller > larger {
+		smaller, larger = larger, smaller
+	}
+	return float64(smaller)/float64(larger) >= c.config.MinSizeRatio
+}
+
+// editDistanceApplies — оба извлечённых текста не длиннее EditDistanceMaxLength символов
+func (c *plagiarismChecker) editDistanceApplies(text1, text2 string) bool {
+	limit := c.config.EditDistanceMaxLength
+	return limit > 0 && utf8.RuneCountInString(text1) <= limit && utf8.RuneCountInString(text2) <= limit
+}
+
+// editDistanceSizes — оба файла известного размера не больше EditDistanceMaxLength байт
+func (c *plagiarismChecker) editDistanceSizes(size1, size2 int64) bool {
+	limit := int64(c.config.EditDistanceMaxLength)
+	return limit > 0 && size1 > 0 && size2 > 0 && size1 <= limit && size2 <= limit
+}
+
+func (c *plagiarismChecker) fuzzyHash() bool {
+	_, ok := c.hashComparator.(ContentFingerprinter)
+	return ok
+}
+
+// fingerprint возвращает отпечаток работы для нечёткого сравнения: из прогретого кеша,
+// из SimHashStore или, если его там нет, строит по извлечённому тексту и сохраняет.
+// Файлы, из которых текст не извлекается (PDF, DOCX, бинарные), отпечатка не получают.
+func (c *plagiarismChecker) fingerprint(ctx context.Context, workID, fileID, fileHash string) (string, bool) {
+	fingerprinter, ok := c.hashComparator.(ContentFingerprinter)
+	if !ok {
+		return "", false
+	}
+
+	if c.fingerprints != nil {
+		if value, ok := c.fingerprints.get(fileID); ok {
+			return value, true
+		}
+	}
+
+	store := c.config.SimHashStore
+	persist := store != nil && workID != "" && fileHash != ""
+	if persist {
+		stored, ok, err := store.GetFingerprints(ctx, workID, HashAlgorithmSimHash, fileHash)
+		if err != nil {
+			c.logger.Warn().Err(err).Str("work_id", workID).Msg("Failed to load fingerprint")
+		}
+		if ok && len(stored) == 1 {
+			value := formatSimHash(stored[0])
+			if c.fingerprints != nil {
+				c.fingerprints.put(fileID, value)
+			}
+			return value, true
+		}
+	}
+
+	text, err := c.extractContent(ctx, c.textAnalyzer, ContentTypeText, fileID, fileHash)
+	if err != nil {
+		c.logger.Warn().
+			Err(err).
+			Str("file_id", fileID).
+			Msg("Failed to get text for fingerprint, comparing stored hashes")
+		return "", false
+	}
+
+	value := fingerprinter.Fingerprint([]byte(text))
+	if persist {
+		if fp, ok := parseSimHash(value); ok {
+			if err := store.SaveFingerprints(ctx, workID, HashAlgorithmSimHash, fileHash, []uint64{fp}); err != nil {
+				c.logger.Warn().Err(err).Str("work_id", workID).Msg("Failed to save fingerprint")
+			}
+		}
+	}
+	if c.fingerprints != nil {
+		c.fingerprints.put(fileID, value)
+	}
+	return value, true
+}
+
+// contentHash — хеш нормализованного текста файла из file-service; "" для бинарных файлов,
+// загруженных при пробной проверке и при ошибке запроса (пара сравнивается дальше как обычно)
+func (c *plagiarismChecker) contentHash(ctx context.Context, fileID string) string {
+	if _, ok := uploadedContent(ctx, fileID); ok {
+		return ""
+	}
+
+	contentHash, err := c.fileClient.GetContentHash(ctx, fileID)
+	if err != nil {
+		c.logger.Debug().
+			Err(err).
+			Str("file_id", fileID).
+			Msg("Failed to get content hash")
+		return ""
+	}
+	return contentHash
+}
+
+func sizesDiffer(size1, size2 int64) bool {
+	return size1 > 0 && size2 > 0 && size1 != size2
+}
+
+func (c *plagiarismChecker) canFallbackToHash(err error) bool {
+	return errors.Is(err, ErrTextExtractionFailed) && c.config.ExtractionFallback != ExtractionFallbackFail
+}
+
 func (c *plagiarismChecker) BatchCheck(ctx context.Context, requests []models.PlagiarismCheckRequest) ([]models.AnalysisResult, error) {
 	results := make([]models.AnalysisResult, 0, len(requests))
 
 	for _, req := range requests {
-		result, err := c.CheckPlagiarism(ctx, req.WorkID, req.FileID, req.AssignmentID, req.StudentID)
+		result, err := c.CheckPlagiarism(ctx, req.WorkID, req.FileID, req.AssignmentID, req.StudentID, c.config.SimilarityThreshold)
 		if err != nil {
 			c.logger.Error().
 				Err(err).
@@ -233,10 +921,31 @@ func (c *plagiarismChecker) BatchCheck(ctx context.Context, requests []models.Pl
 }
 
 func (c *plagiarismChecker) GetCheckerInfo() CheckerInfo {
-	return CheckerInfo{
+	info := CheckerInfo{
 		Name:        "Plagiarism Checker",
-		Version:     "1.0.0",
+		Version:     c.config.AnalysisVersion,
 		Algorithm:   c.config.HashAlgorithm,
 		Description: "Checks for plagiarism by comparing file hashes",
 	}
+	if c.textCache != nil {
+		stats := c.textCache.Stats()
+		info.TextCache = &stats
+	}
+	if c.pairCache != nil {
+		stats := c.pairCache.Stats()
+		info.PairCache = &stats
+	}
+	return info
+}
+
+// normalizeScore приводит процент совпадения пары к единой шкале: максимум из точного совпадения
+// хешей файлов (0 или 100) и оценки сходства, ограниченной PartialMatchCap для неидентичных файлов
+func (c *plagiarismChecker) normalizeScore(identical bool, score int, method string) (int, string) {
+	if identical {
+		return 100, models.ScoreMethodExactHash
+	}
+	if c.config.PartialMatchCap > 0 && score > c.config.PartialMatchCap {
+		score = c.config.PartialMatchCap
+	}
+	return score, method
 }
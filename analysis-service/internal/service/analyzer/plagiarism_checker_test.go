@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+// scoreMethods — score_method каждой пары из деталей отчёта
+func scoreMethods(t *testing.T, result *models.AnalysisResult) map[string]string {
+	t.Helper()
+
+	var details models.ReportDetails
+	if err := json.Unmarshal(result.Details, &details); err != nil {
+		t.Fatalf("decode details: %v", err)
+	}
+	methods := make(map[string]string)
+	for _, r := range details.ComparisonResults {
+		methods[r.ComparedWorkID] = r.ScoreMethod
+	}
+	return methods
+}
+
+func TestCheckerMatchesSameTextInDOCXAndPDF(t *testing.T) {
+	// Разные контейнеры, текст один: file-service дал им одинаковый content_hash
+	files := newFakeFileClient(map[string][]byte{
+		"essay.docx": []byte("PK\x03\x04 docx container"),
+		"essay.pdf":  []byte("%PDF-1.7 pdf container"),
+		"other.pdf":  []byte("%PDF-1.7 another pdf"),
+	})
+	files.contentHashes["essay.docx"] = "text-hash-essay"
+	files.contentHashes["essay.pdf"] = "text-hash-essay"
+	files.contentHashes["other.pdf"] = "text-hash-other"
+
+	checker := NewPlagiarismChecker(nil, files, NewHashComparator("sha256"), zerolog.Nop(), PlagiarismCheckerConfig{
+		HashAlgorithm:   "sha256",
+		PartialMatchCap: 99,
+	})
+	previous := []models.SimilarWork{
+		files.previousWork("work-docx", "student-a", "essay.docx"),
+		files.previousWork("work-other", "student-c", "other.pdf"),
+	}
+
+	result, err := checker.CheckPlagiarismAgainst(context.Background(), "work-pdf", "essay.pdf", "assignment", "student-b", previous, 70)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	if !result.PlagiarismFlag || result.OriginalWorkID == nil || *result.OriginalWorkID != "work-docx" {
+		t.Fatalf("same text in docx not flagged: flag=%v original=%v", result.PlagiarismFlag, result.OriginalWorkID)
+	}
+	// Не побайтная копия — процент ограничен partial_match_cap
+	if result.MatchPercentage != 99 {
+		t.Fatalf("match = %d, want 99", result.MatchPercentage)
+	}
+
+	methods := scoreMethods(t, result)
+	if methods["work-docx"] != models.ScoreMethodContentHash {
+		t.Fatalf("docx pair score_method = %q, want %q", methods["work-docx"], models.ScoreMethodContentHash)
+	}
+	if methods["work-other"] != models.ScoreMethodExactHash {
+		t.Fatalf("other pair score_method = %q, want %q", methods["work-other"], models.ScoreMethodExactHash)
+	}
+	if got := files.downloadCount("essay.docx"); got != 0 {
+		t.Fatalf("docx downloaded %d times, want 0", got)
+	}
+}
+
+func TestCheckerComparesShortFilesByEditDistance(t *testing.T) {
+	files := newFakeFileClient(map[string][]byte{
+		"original.txt": []byte("for i := 0; i < n; i++ { total += prices[i] }"),
+		"edited.txt":   []byte("for i := 0; i < n; i++ { total += prices[j] }"),
+	})
+	checker := NewPlagiarismChecker(nil, files, NewHashComparator("sha256"), zerolog.Nop(), PlagiarismCheckerConfig{
+		HashAlgorithm:         "sha256",
+		EditDistanceMaxLength: 200,
+	})
+	previous := []models.SimilarWork{files.previousWork("work-original", "student-a", "original.txt")}
+
+	result, err := checker.CheckPlagiarismAgainst(context.Background(), "work-edited", "edited.txt", "assignment", "student-b", previous, 70)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	// По хешу файлы разные; посимвольно отличается один символ из 46
+	if result.MatchPercentage < 95 || result.MatchPercentage >= 100 {
+		t.Fatalf("match = %d, want high but below 100", result.MatchPercentage)
+	}
+	if !result.PlagiarismFlag {
+		t.Fatal("single-character edit not flagged")
+	}
+	if method := scoreMethods(t, result)["work-original"]; method != models.ScoreMethodEditDistance {
+		t.Fatalf("score_method = %q, want %q", method, models.ScoreMethodEditDistance)
+	}
+}
+
+func TestCheckerIgnoresStudentsOwnEarlierAttempt(t *testing.T) {
+	files := newFakeFileClient(map[string][]byte{
+		"attempt-1.txt": []byte("my essay on the industrial revolution"),
+		"attempt-2.txt": []byte("my essay on the industrial revolution"),
+		"bob.txt":       []byte("an unrelated essay about photosynthesis"),
+	})
+	checker := NewPlagiarismChecker(nil, files, NewHashComparator("sha256"), zerolog.Nop(), PlagiarismCheckerConfig{
+		HashAlgorithm:   "sha256",
+		PartialMatchCap: 99,
+	})
+	// Общий набор пакетного анализа содержит все работы задания, включая первую попытку автора
+	previous := []models.SimilarWork{
+		files.previousWork("alice-1", "alice", "attempt-1.txt"),
+		files.previousWork("bob-1", "bob", "bob.txt"),
+	}
+
+	result, err := checker.CheckPlagiarismAgainst(context.Background(), "alice-2", "attempt-2.txt", "assignment", "alice", previous, 70)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	// Тот же файл, что в первой попытке, не даёт 100%: оценка — только совпадение с работой Боба
+	if len(result.SimilarWorks) != 1 || result.SimilarWorks[0].WorkID != "bob-1" || result.ComparedWithCount != 1 {
+		t.Fatalf("similar works = %+v (compared with %d), want only bob-1", result.SimilarWorks, result.ComparedWithCount)
+	}
+	if result.MatchPercentage != result.SimilarWorks[0].MatchPercentage || result.MatchPercentage >= 70 || result.PlagiarismFlag {
+		t.Fatalf("match = %d (flag %v), want bob's low score without a flag", result.MatchPercentage, result.PlagiarismFlag)
+	}
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+// PreviewFile — файл пробной проверки: уже загруженный в file-service (FileID) или переданный
+// в запросе (Content), который никуда не сохраняется
+type PreviewFile struct {
+	FileID  string
+	Content []byte
+}
+
+type previewContentKey struct{}
+
+type previewContent struct {
+	fileID  string
+	content []byte
+}
+
+// CheckPreview сравнивает файл со всеми работами задания так же, как CheckPlagiarism, но без работы:
+// отпечатки winnowing не сохраняются, метрики проверок не меняются, в индекс точных копий файл не попадает
+func (c *plagiarismChecker) CheckPreview(ctx context.Context, file PreviewFile, assignmentID, studentID string, threshold int) (*models.AnalysisResult, error) {
+	fileID := file.FileID
+	if file.Content != nil {
+		// Идентификатор по хешу: кеш SimHash-отпечатков по file_id не спутает разные загрузки
+		sum := sha256.Sum256(file.Content)
+		fileID = "preview:" + hex.EncodeToString(sum[:])
+		ctx = context.WithValue(ctx, previewContentKey{}, previewContent{fileID: fileID, content: file.Content})
+	}
+
+	loadPrevious := func(ctx context.Context, timings *models.PhaseTimings) ([]models.SimilarWork, error) {
+		previousWorks, err := c.workClient.GetPreviousWorks(ctx, assignmentID, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get previous works: %w", err)
+		}
+		return previousWorks, nil
+	}
+
+	// Пустой workID: winnowingFingerprints не обращается к хранилищу отпечатков
+	return c.checkAgainst(ctx, "", fileID, assignmentID, studentID, loadPrevious, threshold)
+}
+
+// uploadedContent — содержимое файла, переданного в CheckPreview, если fileID указывает на него
+func uploadedContent(ctx context.Context, fileID string) ([]byte, bool) {
+	preview, ok := ctx.Value(previewContentKey{}).(previewContent)
+	if !ok || preview.fileID != fileID {
+		return nil, false
+	}
+	return preview.content, true
+}
+
+// fileHash — хеш и размер файла из file-service; для содержимого пробной проверки считается SHA-256,
+// как при загрузке в file-service, чтобы точные копии и кеш пар находились по тем же хешам
+func (c *plagiarismChecker) fileHash(ctx context.Context, fileID string) (string, int64, error) {
+	if content, ok := uploadedContent(ctx, fileID); ok {
+		sum := sha256.Sum256(content)
+		return hex.EncodeToString(sum[:]), int64(len(content)), nil
+	}
+	return c.fileClient.GetFileHash(ctx, fileID)
+}
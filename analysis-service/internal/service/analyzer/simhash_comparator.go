@@ -0,0 +1,128 @@
+package analyzer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	HashAlgorithmSimHash = "simhash"
+
+	simHashBits         = 64
+	simHashHexLength    = simHashBits / 4
+	simHashShingleWords = 3
+)
+
+// ContentFingerprinter — компаратор, которому для сравнения нужен отпечаток содержимого,
+// а не сохранённый криптографический хеш файла.
+type ContentFingerprinter interface {
+	Fingerprint(content []byte) string
+}
+
+type simHashComparator struct{}
+
+// NewSimHashComparator сравнивает 64-битные SimHash-отпечатки по расстоянию Хэмминга,
+// поэтому мелкие правки файла дают высокий, а не нулевой процент совпадения.
+func NewSimHashComparator() HashComparator {
+	return &simHashComparator{}
+}
+
+// Fingerprint строит SimHash по шинглам из слов; регистр, пробелы и пунктуация не учитываются.
+func (c *simHashComparator) Fingerprint(content []byte) string {
+	tokens := strings.FieldsFunc(strings.ToLower(string(content)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var weights [simHashBits]int
+	addShingle := func(shingle string) {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		sum := h.Sum64()
+		for i := 0; i < simHashBits; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	if len(tokens) < simHashShingleWords {
+		addShingle(strings.Join(tokens, " "))
+	} else {
+		for i := 0; i+simHashShingleWords <= len(tokens); i++ {
+			addShingle(strings.Join(tokens[i:i+simHashShingleWords], " "))
+		}
+	}
+
+	var fingerprint uint64
+	for i, w := range weights {
+		if w > 0 {
+			fingerprint |= 1 << uint(i)
+		}
+	}
+
+	return formatSimHash(fingerprint)
+}
+
+// CompareHashes возвращает процент совпадения двух отпечатков. У несвязанных текстов
+// в среднем различается половина битов, поэтому 32 и более отличающихся бита считаются 0%.
+// Если хотя бы одно значение не SimHash (например, сохранённый SHA-256), сравнение точное.
+func (c *simHashComparator) CompareHashes(hash1, hash2 string) (int, error) {
+	hash1 = strings.ToLower(strings.TrimSpace(hash1))
+	hash2 = strings.ToLower(strings.TrimSpace(hash2))
+
+	fp1, ok1 := parseSimHash(hash1)
+	fp2, ok2 := parseSimHash(hash2)
+	if !ok1 || !ok2 {
+		if hash1 == hash2 {
+			return 100, nil
+		}
+		return 0, nil
+	}
+
+	distance := bits.OnesCount64(fp1 ^ fp2)
+	if distance >= simHashBits/2 {
+		return 0, nil
+	}
+
+	return 100 - distance*100/(simHashBits/2), nil
+}
+
+func (c *simHashComparator) CompareMultiple(hashes []string, targetHash string) (map[string]int, error) {
+	results := make(map[string]int)
+
+	for _, hash := range hashes {
+		percentage, err := c.CompareHashes(targetHash, hash)
+		if err != nil {
+			return nil, err
+		}
+		results[hash] = percentage
+	}
+
+	return results, nil
+}
+
+func (c *simHashComparator) GetAlgorithm() string {
+	return HashAlgorithmSimHash
+}
+
+func formatSimHash(fingerprint uint64) string {
+	return fmt.Sprintf("%016x", fingerprint)
+}
+
+func parseSimHash(value string) (uint64, bool) {
+	if len(value) != simHashHexLength {
+		return 0, false
+	}
+
+	fp, err := strconv.ParseUint(value, 16, 64)
+	if err != nil {
+		return 0, false
+	}
+	return fp, true
+}
@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+const simHashEssay = `Plagiarism detection compares each submitted work with earlier works of the same
+assignment. Exact hashes only catch byte for byte copies, so a student who changes a single word
+or reflows a paragraph escapes them. Locality sensitive fingerprints keep similar documents close
+together: a small edit flips only a few bits of the fingerprint, while unrelated documents differ
+in about half of them. The checker stores one fingerprint per work and compares stored values
+instead of downloading every previous file for each new submission.`
+
+const simHashUnrelated = `The recipe calls for two cups of flour, a pinch of salt and three eggs beaten
+with warm milk. Knead the dough for ten minutes, cover it with a towel and leave it near the oven
+until it doubles in size. Bake the loaves at a high temperature for twenty minutes, then lower the
+heat and let the crust turn golden brown before cooling them on a wire rack overnight.`
+
+func newSimHashChecker(files *fakeFileClient, store FingerprintStore) PlagiarismChecker {
+	return NewPlagiarismChecker(nil, files, NewHashComparator("sha256"), zerolog.Nop(), PlagiarismCheckerConfig{
+		HashAlgorithm:       HashAlgorithmSimHash,
+		SimilarityThreshold: 70,
+		SimHashStore:        store,
+	})
+}
+
+func TestSimHashNearDuplicates(t *testing.T) {
+	c := NewSimHashComparator().(*simHashComparator)
+	original := c.Fingerprint([]byte(simHashEssay))
+
+	tests := []struct {
+		name    string
+		text    string
+		atLeast int
+		below   int
+	}{
+		{"identical", simHashEssay, 100, 101},
+		{"case and whitespace", strings.ToUpper(strings.Join(strings.Fields(simHashEssay), "  ")), 100, 101},
+		{"one word changed", strings.Replace(simHashEssay, "single word", "single term", 1), 75, 100},
+		{"unrelated", simHashUnrelated, 0, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score, err := c.CompareHashes(original, c.Fingerprint([]byte(tt.text)))
+			if err != nil {
+				t.Fatalf("CompareHashes: %v", err)
+			}
+			if score < tt.atLeast || score >= tt.below {
+				t.Fatalf("score = %d, want [%d, %d)", score, tt.atLeast, tt.below)
+			}
+		})
+	}
+}
+
+func TestSimHashCheckerStoresFingerprintOncePerWork(t *testing.T) {
+	files := newFakeFileClient(map[string][]byte{
+		"file-prev":   []byte(simHashEssay),
+		"file-first":  []byte(strings.Replace(simHashEssay, "single word", "single term", 1)),
+		"file-second": []byte(strings.Replace(simHashEssay, "reflows", "rewraps", 1)),
+	})
+	store := newMemFingerprintStore()
+	checker := newSimHashChecker(files, store)
+	previous := []models.SimilarWork{files.previousWork("work-prev", "student-a", "file-prev")}
+	ctx := context.Background()
+
+	for _, work := range []struct{ workID, fileID string }{{"work-first", "file-first"}, {"work-second", "file-second"}} {
+		result, err := checker.CheckPlagiarismAgainst(ctx, work.workID, work.fileID, "assignment", "student-b", previous, 70)
+		if err != nil {
+			t.Fatalf("check %s: %v", work.workID, err)
+		}
+		if result.MatchPercentage < 75 || result.MatchPercentage >= 100 {
+			t.Fatalf("%s: match = %d, want a high partial match", work.workID, result.MatchPercentage)
+		}
+		if !result.PlagiarismFlag {
+			t.Fatalf("%s: near duplicate not flagged", work.workID)
+		}
+	}
+
+	if got := files.downloadCount("file-prev"); got != 1 {
+		t.Fatalf("previous work downloaded %d times, want 1 (stored SimHash reused)", got)
+	}
+	if store.saves != 3 {
+		t.Fatalf("saved %d fingerprints, want one per work (3)", store.saves)
+	}
+}
+
+func TestSimHashCheckerSkipsBinaryContent(t *testing.T) {
+	// Контейнер PDF/DOCX не текст: отпечаток по сырым байтам не строится, сравниваются хеши
+	files := newFakeFileClient(map[string][]byte{
+		"file-prev": append([]byte("%PDF-1.7\n"), 0xff, 0xfe, 0x00, 0x81),
+		"file-new":  []byte(simHashEssay),
+	})
+	store := newMemFingerprintStore()
+	checker := newSimHashChecker(files, store)
+	previous := []models.SimilarWork{files.previousWork("work-prev", "student-a", "file-prev")}
+
+	result, err := checker.CheckPlagiarismAgainst(context.Background(), "work-new", "file-new", "assignment", "student-b", previous, 70)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if result.MatchPercentage != 0 {
+		t.Fatalf("match = %d, want 0 for a different binary file", result.MatchPercentage)
+	}
+	if _, ok, _ := store.GetFingerprints(context.Background(), "work-prev", HashAlgorithmSimHash, previous[0].FileHash); ok {
+		t.Fatal("fingerprint stored for binary content")
+	}
+}
@@ -2,9 +2,11 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
@@ -15,6 +17,9 @@ type SimilarityAnalyzer interface {
 	AnalyzeContent(ctx context.Context, file1, file2 []byte) (float64, error)
 	ExtractText(content []byte) (string, error)
 	CalculateSimilarity(text1, text2 string) float64
+	// CalculateEditSimilarity — 1 - расстояние Левенштейна / длина большего текста, в символах;
+	// замечает правки в один символ, которые не меняют набор токенов
+	CalculateEditSimilarity(text1, text2 string) float64
 	FindSimilarSections(text1, text2 string, minLength int) []SimilarSection
 }
 
@@ -65,6 +70,10 @@ func (a *similarityAnalyzer) AnalyzeContent(ctx context.Context, file1, file2 []
 }
 
 func (a *similarityAnalyzer) ExtractText(content []byte) (string, error) {
+	if !utf8.Valid(content) {
+		return "", errors.New("content is not valid UTF-8 text")
+	}
+
 	text := string(content)
 
 	text = strings.Join(strings.Fields(text), " ")
@@ -108,6 +117,52 @@ func (a *similarityAnalyzer) CalculateSimilarity(text1, text2 string) float64 {
 	return float64(intersection) / float64(union)
 }
 
+func (a *similarityAnalyzer) CalculateEditSimilarity(text1, text2 string) float64 {
+	if similarity, ok := editSimilarity(text1, text2); ok {
+		return similarity
+	}
+	return a.CalculateSimilarity(text1, text2)
+}
+
+// MaxEditSimilarityLength — предел длины текста в символах для расстояния Левенштейна: время растёт
+// как произведение длин, поэтому более длинные тексты сравниваются обычной оценкой сходства
+const MaxEditSimilarityLength = 10000
+
+// editSimilarity считает нормированное расстояние Левенштейна двумя строками таблицы, память O(длины).
+// ok=false — один из текстов длиннее MaxEditSimilarityLength.
+func editSimilarity(text1, text2 string) (float64, bool) {
+	r1, r2 := []rune(text1), []rune(text2)
+	if len(r1) > MaxEditSimilarityLength || len(r2) > MaxEditSimilarityLength {
+		return 0, false
+	}
+	if len(r1) == 0 || len(r2) == 0 {
+		return 0.0, true
+	}
+	if len(r1) < len(r2) {
+		r1, r2 = r2, r1
+	}
+
+	prev := make([]int, len(r2)+1)
+	curr := make([]int, len(r2)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(r1); i++ {
+		curr[0] = i
+		for j := 1; j <= len(r2); j++ {
+			cost := 1
+			if r1[i-1] == r2[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(r2)])/float64(len(r1)), true
+}
+
 func (a *similarityAnalyzer) FindSimilarSections(text1, text2 string, minLength int) []SimilarSection {
 	var sections []SimilarSection
 
@@ -171,7 +226,7 @@ func (c *TextSimilarityCalculator) GenerateReport(text1, text2 string, similarit
 		AnalysisMetadata: models.AnalysisMetadata{
 			AlgorithmUsed:    "text_similarity",
 			SimilarityMethod: "jaccard_similarity",
-			AnalysisVersion:  "1.0",
+			AnalysisVersion:  AlgorithmVersion,
 			Threshold:        80,
 			StartedAt:        time.Now(),
 			CompletedAt:      time.Now(),
@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestEditSimilaritySingleCharacterEdits(t *testing.T) {
+	const original = "func sum(a, b int) int { return a + b }"
+
+	tests := []struct {
+		name   string
+		edited string
+	}{
+		{"substitution", "func sum(a, b int) int { return a - b }"},
+		{"insertion", "func sum(a, b int) int { return a + b; }"},
+		{"deletion", "func sum(a, b int) int { return a +b }"},
+		{"case change", "func Sum(a, b int) int { return a + b }"},
+	}
+
+	analyzers := map[string]interface {
+		CalculateEditSimilarity(string, string) float64
+	}{
+		"text": NewSimilarityAnalyzer(nil, zerolog.Nop()),
+		"code": NewCodeSimilarityAnalyzer("go", zerolog.Nop()),
+	}
+	for name, analyzer := range analyzers {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				// Одна правка на 39 символов: 1 - 1/39 ≈ 0.974
+				got := analyzer.CalculateEditSimilarity(original, tt.edited)
+				if got < 0.95 || got >= 1 {
+					t.Fatalf("similarity = %.3f, want high but below 1", got)
+				}
+				if back := analyzer.CalculateEditSimilarity(tt.edited, original); back != got {
+					t.Fatalf("similarity is not symmetric: %.3f vs %.3f", got, back)
+				}
+			})
+		}
+	}
+
+	if got := NewSimilarityAnalyzer(nil, zerolog.Nop()).CalculateEditSimilarity(original, original); got != 1 {
+		t.Fatalf("identical texts: similarity = %.3f, want 1", got)
+	}
+}
+
+func TestEditSimilarityLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		text1  string
+		text2  string
+		want   float64
+		wantOK bool
+	}{
+		{"empty", "", "abc", 0, true},
+		{"counts runes, not bytes", "привет", "привед", 1 - 1.0/6, true},
+		{"too long", strings.Repeat("a", MaxEditSimilarityLength+1), "a", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := editSimilarity(tt.text1, tt.text2)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("editSimilarity = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+
+	// Длинный текст сравнивается обычной оценкой вместо квадратичной таблицы
+	long := strings.Repeat("word ", MaxEditSimilarityLength)
+	a := NewSimilarityAnalyzer(nil, zerolog.Nop())
+	if got, want := a.CalculateEditSimilarity(long, long+"extra"), a.CalculateSimilarity(long, long+"extra"); got != want {
+		t.Fatalf("long texts: similarity = %v, want token similarity %v", got, want)
+	}
+}
@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/sharedcache"
+)
+
+// TextCache хранит извлечённый и нормализованный текст файлов по хешу содержимого.
+// Содержимое файла с данным хешем не меняется, поэтому записи не инвалидируются —
+// только вытесняются по LRU при превышении лимитов.
+type TextCache interface {
+	Get(key string) (string, bool)
+	Put(key, text string)
+	Stats() TextCacheStats
+}
+
+type TextCacheStats struct {
+	Entries int   `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+type textCacheEntry struct {
+	key  string
+	text string
+}
+
+type textCache struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List
+	bytes      int64
+	hits       int64
+	misses     int64
+	maxBytes   int64
+	maxEntries int
+}
+
+// NewTextCache ограничивает кеш суммарным размером текстов и числом записей (0 — без ограничения)
+func NewTextCache(maxBytes int64, maxEntries int) TextCache {
+	return &textCache{
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		maxBytes:   maxBytes,
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *textCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses++
+		return "", false
+	}
+
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*textCacheEntry).text, true
+}
+
+func (c *textCache) Put(key, text string) {
+	size := int64(len(text))
+	// Текст больше всего кеша только вытеснил бы остальные записи
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&textCacheEntry{key: key, text: text})
+	c.bytes += size
+
+	for (c.maxBytes > 0 && c.bytes > c.maxBytes) || (c.maxEntries > 0 && c.order.Len() > c.maxEntries) {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*textCacheEntry)
+		c.order.Remove(oldest)
+		delete(c.items, entry.key)
+		c.bytes -= int64(len(entry.text))
+	}
+}
+
+func (c *textCache) Stats() TextCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return TextCacheStats{
+		Entries: c.order.Len(),
+		Bytes:   c.bytes,
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+}
+
+// sharedTextCache хранит текст в общем хранилище (Redis), чтобы его использовали все экземпляры.
+// Вытеснение — по TTL и политике самого хранилища, поэтому Entries и Bytes не считаются.
+type sharedTextCache struct {
+	store  sharedcache.Store
+	ttl    time.Duration
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func NewSharedTextCache(store sharedcache.Store, ttl time.Duration) TextCache {
+	return &sharedTextCache{store: store, ttl: ttl}
+}
+
+func (c *sharedTextCache) Get(key string) (string, bool) {
+	text, ok, err := c.store.Get(context.Background(), "text:"+key)
+	if err != nil || !ok {
+		c.misses.Add(1)
+		return "", false
+	}
+
+	c.hits.Add(1)
+	return text, true
+}
+
+func (c *sharedTextCache) Put(key, text string) {
+	_ = c.store.Set(context.Background(), "text:"+key, text, c.ttl)
+}
+
+func (c *sharedTextCache) Stats() TextCacheStats {
+	return TextCacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+	}
+}
@@ -0,0 +1,16 @@
+package analyzer
+
+import "context"
+
+type urgentKey struct{}
+
+// WithUrgent помечает проверку как срочную: её загрузки содержимого идут через
+// зарезервированный лимит UrgentDownloads и не ждут загрузок остальных проверок
+func WithUrgent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, urgentKey{}, true)
+}
+
+func isUrgent(ctx context.Context) bool {
+	urgent, _ := ctx.Value(urgentKey{}).(bool)
+	return urgent
+}
@@ -0,0 +1,136 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+var ErrWarmupDisabled = errors.New("warmup is disabled")
+
+type cachedFingerprint struct {
+	value     string
+	expiresAt time.Time
+}
+
+// fingerprintCache хранит SimHash-отпечатки по file_id, чтобы не скачивать файлы
+// предыдущих работ при каждой проверке
+type fingerprintCache struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	items     map[string]cachedFingerprint
+	nextSweep time.Time
+}
+
+func newFingerprintCache(ttl time.Duration) *fingerprintCache {
+	return &fingerprintCache{
+		ttl:       ttl,
+		items:     make(map[string]cachedFingerprint),
+		nextSweep: time.Now().Add(ttl),
+	}
+}
+
+func (c *fingerprintCache) get(fileID string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[fileID]
+	if !ok {
+		return "", false
+	}
+	if time.Now().After(item.expiresAt) {
+		delete(c.items, fileID)
+		return "", false
+	}
+	return item.value, true
+}
+
+func (c *fingerprintCache) put(fileID, value string) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[fileID] = cachedFingerprint{value: value, expiresAt: now.Add(c.ttl)}
+	if now.After(c.nextSweep) {
+		for id, item := range c.items {
+			if now.After(item.expiresAt) {
+				delete(c.items, id)
+			}
+		}
+		c.nextSweep = now.Add(c.ttl)
+	}
+}
+
+// Warmup заранее загружает базу сравнения задания: хеши файлов всех работ (через кеширующий
+// клиент file-service), SimHash-отпечатки и извлечённый текст, если они нужны для проверки.
+// Список работ при каждой проверке по-прежнему запрашивается заново, так что новые
+// работы не теряются — из кеша берётся только то, что не меняется.
+func (c *plagiarismChecker) Warmup(ctx context.Context, assignmentID string) (*models.WarmupResult, error) {
+	if c.fingerprints == nil {
+		return nil, ErrWarmupDisabled
+	}
+
+	startTime := time.Now()
+
+	works, err := c.workClient.GetPreviousWorks(ctx, assignmentID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous works: %w", err)
+	}
+
+	result := &models.WarmupResult{
+		AssignmentID: assignmentID,
+		Works:        len(works),
+	}
+
+	contentType := c.contentType(assignmentID)
+	contentAnalyzer := c.contentAnalyzer(contentType)
+	// Без кеша текста извлечённый текст сохранить негде
+	if c.textCache == nil {
+		contentAnalyzer = nil
+	}
+
+	for _, work := range works {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if c.fuzzyHash() {
+			if _, ok := c.fingerprint(ctx, work.WorkID, work.FileID, work.FileHash); ok {
+				result.Fingerprints++
+			} else {
+				result.Failed++
+			}
+		}
+
+		if contentAnalyzer != nil {
+			if _, err := c.extractContent(ctx, contentAnalyzer, contentType, work.FileID, work.FileHash); err != nil {
+				c.logger.Warn().
+					Err(err).
+					Str("work_id", work.WorkID).
+					Msg("Failed to extract text during warmup")
+				result.Failed++
+			} else {
+				result.Texts++
+			}
+		}
+	}
+
+	result.DurationMs = time.Since(startTime).Milliseconds()
+	result.ExpiresAt = startTime.Add(c.config.WarmupTTL)
+
+	c.logger.Info().
+		Str("assignment_id", assignmentID).
+		Int("works", result.Works).
+		Int("fingerprints", result.Fingerprints).
+		Int("texts", result.Texts).
+		Int("failed", result.Failed).
+		Int64("duration_ms", result.DurationMs).
+		Msg("Assignment baseline warmed up")
+
+	return result, nil
+}
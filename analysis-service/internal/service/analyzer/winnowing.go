@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"unicode"
+)
+
+// Fingerprinter выбирает отпечатки документа алгоритмом winnowing: хеши всех k-грамм
+// нормализованного текста, из каждого окна в window подряд идущих хешей — минимальный.
+// Общий фрагмент длиной не меньше k+window-1 символов всегда даёт общий отпечаток,
+// поэтому перестановка абзацев и частичное копирование не теряются, в отличие от сравнения хешей файлов.
+type Fingerprinter struct {
+	k      int
+	window int
+}
+
+func NewFingerprinter(k, window int) *Fingerprinter {
+	if k < 1 {
+		k = 1
+	}
+	if window < 1 {
+		window = 1
+	}
+	return &Fingerprinter{k: k, window: window}
+}
+
+// Key — параметры отпечатков; отпечатки с разными параметрами не сравнимы
+func (f *Fingerprinter) Key() string {
+	return fmt.Sprintf("winnow:k%d:w%d", f.k, f.window)
+}
+
+// Fingerprints возвращает отсортированное множество отпечатков text; регистр, пробелы
+// и пунктуация не учитываются
+func (f *Fingerprinter) Fingerprints(text string) []uint64 {
+	runes := make([]rune, 0, len(text))
+	for _, r := range text {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			continue
+		}
+		runes = append(runes, unicode.ToLower(r))
+	}
+	if len(runes) == 0 {
+		return nil
+	}
+
+	k := f.k
+	if len(runes) < k {
+		k = len(runes)
+	}
+	hashes := make([]uint64, len(runes)-k+1)
+	for i := range hashes {
+		h := fnv.New64a()
+		h.Write([]byte(string(runes[i : i+k])))
+		hashes[i] = h.Sum64()
+	}
+
+	window := f.window
+	if len(hashes) < window {
+		window = len(hashes)
+	}
+
+	selected := make(map[uint64]struct{})
+	minPos := -1
+	for start := 0; start+window <= len(hashes); start++ {
+		end := start + window - 1
+		switch {
+		case minPos < start:
+			// Минимум вышел из окна — ищем заново; при равенстве берём самый правый
+			minPos = start
+			for i := start + 1; i <= end; i++ {
+				if hashes[i] <= hashes[minPos] {
+					minPos = i
+				}
+			}
+		case hashes[end] <= hashes[minPos]:
+			minPos = end
+		}
+		selected[hashes[minPos]] = struct{}{}
+	}
+
+	fingerprints := make([]uint64, 0, len(selected))
+	for h := range selected {
+		fingerprints = append(fingerprints, h)
+	}
+	sort.Slice(fingerprints, func(i, j int) bool { return fingerprints[i] < fingerprints[j] })
+	return fingerprints
+}
+
+// FingerprintOverlap — доля отпечатков current, найденных в other: какая часть работы есть
+// в сравниваемой. Оба множества отсортированы, как их возвращает Fingerprints.
+func FingerprintOverlap(current, other []uint64) float64 {
+	if len(current) == 0 || len(other) == 0 {
+		return 0
+	}
+
+	common := 0
+	for i, j := 0, 0; i < len(current) && j < len(other); {
+		switch {
+		case current[i] == other[j]:
+			common++
+			i++
+			j++
+		case current[i] < other[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return float64(common) / float64(len(current))
+}
+
+// FingerprintStore хранит отпечатки работ, чтобы повторный анализ и сравнение с той же работой
+// их не пересчитывали. Запись действительна, пока у работы тот же хеш файла.
+type FingerprintStore interface {
+	GetFingerprints(ctx context.Context, workID, method, fileHash string) ([]uint64, bool, error)
+	SaveFingerprints(ctx context.Context, workID, method, fileHash string, fingerprints []uint64) error
+}
+
+// winnowingFingerprints берёт отпечатки работы из хранилища или строит их по тексту и сохраняет.
+// Ошибки хранилища не прерывают анализ: отпечатки просто считаются заново.
+func (c *plagiarismChecker) winnowingFingerprints(ctx context.Context, contentType, workID, fileHash, text string) []uint64 {
+	method := contentType + ":" + c.winnower.Key()
+	if contentType == ContentTypeCode {
+		method = contentType + ":" + c.codeAnalyzer.Language() + ":" + c.winnower.Key()
+	}
+
+	store := c.config.FingerprintStore
+	if store != nil && workID != "" && fileHash != "" {
+		fingerprints, ok, err := store.GetFingerprints(ctx, workID, method, fileHash)
+		if err != nil {
+			c.logger.Warn().Err(err).Str("work_id", workID).Msg("Failed to load fingerprints")
+		}
+		if ok {
+			return fingerprints
+		}
+	}
+
+	fingerprints := c.winnower.Fingerprints(text)
+
+	if store != nil && workID != "" && fileHash != "" {
+		if err := store.SaveFingerprints(ctx, workID, method, fileHash, fingerprints); err != nil {
+			c.logger.Warn().Err(err).Str("work_id", workID).Msg("Failed to save fingerprints")
+		}
+	}
+	return fingerprints
+}
+
+func (c *plagiarismChecker) IndexReference(ctx context.Context, referenceID, fileID, fileHash, contentType string) (int, error) {
+	if c.winnower == nil {
+		return 0, nil
+	}
+
+	// Для документов базы сравнения текст извлекается и без analysis.enable_deep_analysis
+	var contentAnalyzer SimilarityAnalyzer = c.textAnalyzer
+	if contentType == ContentTypeCode {
+		contentAnalyzer = c.codeAnalyzer
+	}
+
+	text, err := c.extractContent(ctx, contentAnalyzer, contentType, fileID, fileHash)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(c.winnowingFingerprints(ctx, contentType, referenceID, fileHash, text)), nil
+}
@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+var essayParagraphs = []string{
+	"The industrial revolution changed how people worked, moving labour from farms into crowded factories.",
+	"Steam engines made it possible to build mills far from rivers, and cities grew around the railways.",
+	"Working conditions were harsh: long shifts, child labour and little protection from injuries.",
+	"Reform movements eventually won shorter hours, factory inspections and the first trade unions.",
+}
+
+func TestWinnowingFindsMovedParagraph(t *testing.T) {
+	f := NewFingerprinter(5, 4)
+
+	original := strings.Join(essayParagraphs, "\n\n")
+	// Последний абзац перенесён в начало, остальной текст не тронут
+	moved := strings.Join(append([]string{essayParagraphs[3]}, essayParagraphs[:3]...), "\n\n")
+	unrelated := "Photosynthesis converts light into chemical energy stored in glucose molecules inside plant cells."
+
+	if got := FingerprintOverlap(f.Fingerprints(moved), f.Fingerprints(original)); got < 0.9 {
+		t.Fatalf("moved paragraph overlap = %.2f, want at least 0.9", got)
+	}
+	if got := FingerprintOverlap(f.Fingerprints(unrelated), f.Fingerprints(original)); got > 0.2 {
+		t.Fatalf("unrelated text overlap = %.2f, want at most 0.2", got)
+	}
+
+	// Абзац, вставленный в чужой текст, целиком находится в исходной работе
+	pasted := unrelated + "\n\n" + essayParagraphs[2]
+	if got := FingerprintOverlap(f.Fingerprints(essayParagraphs[2]), f.Fingerprints(pasted)); got != 1 {
+		t.Fatalf("pasted paragraph overlap = %.2f, want 1", got)
+	}
+}
+
+func TestWinnowingIgnoresCaseAndPunctuation(t *testing.T) {
+	f := NewFingerprinter(5, 4)
+
+	a := f.Fingerprints("Steam engines made it possible to build mills far from rivers.")
+	b := f.Fingerprints("STEAM ENGINES, made it possible -- to build mills far from rivers!")
+	if len(a) == 0 || FingerprintOverlap(a, b) != 1 {
+		t.Fatalf("fingerprints differ after normalization: %v vs %v", a, b)
+	}
+	if f.Fingerprints(" .,; ") != nil {
+		t.Fatal("text without letters has fingerprints")
+	}
+}
+
+func TestCheckerScoresMovedParagraphByWinnowing(t *testing.T) {
+	original := strings.Join(essayParagraphs, "\n\n")
+	moved := strings.Join(append([]string{essayParagraphs[3]}, essayParagraphs[:3]...), "\n\n")
+	files := newFakeFileClient(map[string][]byte{
+		"original.txt": []byte(original),
+		"moved.txt":    []byte(moved),
+	})
+	checker := NewPlagiarismChecker(nil, files, NewHashComparator("sha256"), zerolog.Nop(), PlagiarismCheckerConfig{
+		HashAlgorithm:      "sha256",
+		EnableDeepAnalysis: true,
+		WinnowingEnabled:   true,
+		WinnowingK:         5,
+		WinnowingWindow:    4,
+	})
+	previous := []models.SimilarWork{files.previousWork("work-original", "student-a", "original.txt")}
+
+	result, err := checker.CheckPlagiarismAgainst(context.Background(), "work-moved", "moved.txt", "assignment", "student-b", previous, 70)
+	if err != nil {
+		t.Fatalf("check: %v", err)
+	}
+
+	// Хеши файлов разные, но содержимое то же
+	if result.MatchPercentage < 90 || !result.PlagiarismFlag {
+		t.Fatalf("match = %d (flag %v), want a flagged match of at least 90", result.MatchPercentage, result.PlagiarismFlag)
+	}
+	if method := scoreMethods(t, result)["work-original"]; method != models.ScoreMethodWinnowing {
+		t.Fatalf("score_method = %q, want %q", method, models.ScoreMethodWinnowing)
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// AuditLogger пишет решения анализа в журнал аудита, отдельный от рабочих логов и таблицы reports
+type AuditLogger interface {
+	LogDecision(ctx context.Context, entry models.AuditEntry) error
+	Close() error
+}
+
+type AuditConfig struct {
+	Enabled bool
+	// log — отдельный поток JSON-логов, database — таблица analysis_audit_log
+	Sink string
+	// Файл для sink=log, пустой путь — stdout
+	LogPath string
+}
+
+type auditLogger struct {
+	auditRepo repository.AuditRepository
+	sink      zerolog.Logger
+	file      io.Closer
+	config    AuditConfig
+}
+
+func NewAuditLogger(auditRepo repository.AuditRepository, config AuditConfig) (AuditLogger, error) {
+	a := &auditLogger{
+		auditRepo: auditRepo,
+		config:    config,
+	}
+
+	if !config.Enabled {
+		return a, nil
+	}
+
+	switch config.Sink {
+	case models.AuditSinkDatabase:
+	case models.AuditSinkLog:
+		var out io.Writer = os.Stdout
+		if config.LogPath != "" {
+			file, err := os.OpenFile(config.LogPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open audit log: %w", err)
+			}
+			out = file
+			a.file = file
+		}
+		a.sink = zerolog.New(out).With().Str("stream", "audit").Logger()
+	default:
+		return nil, fmt.Errorf("unknown audit sink: %s", config.Sink)
+	}
+
+	return a, nil
+}
+
+func (a *auditLogger) LogDecision(ctx context.Context, entry models.AuditEntry) error {
+	if !a.config.Enabled {
+		return nil
+	}
+
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.MatchedWorkIDs == nil {
+		entry.MatchedWorkIDs = []string{}
+	}
+
+	if a.config.Sink == models.AuditSinkDatabase {
+		if err := a.auditRepo.Create(ctx, &entry); err != nil {
+			return fmt.Errorf("failed to write audit entry: %w", err)
+		}
+		return nil
+	}
+
+	event := a.sink.Log().
+		Str("audit_id", entry.ID).
+		Str("report_id", entry.ReportID).
+		Str("work_id", entry.WorkID).
+		Str("assignment_id", entry.AssignmentID).
+		Str("student_id", entry.StudentID).
+		Bool("plagiarism_flag", entry.PlagiarismFlag).
+		Int("match_percentage", entry.MatchPercentage).
+		Str("method", entry.Method).
+		Int("threshold", entry.Threshold).
+		Int("compared_count", entry.ComparedCount).
+		Strs("matched_work_ids", entry.MatchedWorkIDs).
+		Str("trigger_source", entry.TriggerSource).
+		Time("decided_at", entry.DecidedAt)
+	if entry.OriginalWorkID != nil {
+		event = event.Str("original_work_id", *entry.OriginalWorkID)
+	}
+	event.Msg("analysis decision")
+
+	return nil
+}
+
+func (a *auditLogger) Close() error {
+	if a.file != nil {
+		return a.file.Close()
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+// checkCrossAssignment сравнивает работу с работами других заданий, сданными в пределах
+// CrossAssignmentWindow, и записывает совпадения не ниже порога в details.cross_assignment.
+// Одинаковые работы, сданные почти одновременно в разные задания, проверка по заданию не видит
+func (s *analysisService) checkCrossAssignment(report *models.Report, threshold int) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	candidates, err := s.reportRepo.GetRecentFromOtherAssignments(
+		ctx,
+		report.AssignmentID,
+		report.StudentID,
+		report.CreatedAt.Add(-s.config.CrossAssignmentWindow),
+		s.config.CrossAssignmentMaxWorks,
+	)
+	if err != nil {
+		s.logger.Error().Err(err).Str("work_id", report.WorkID).Msg("Failed to get recent works of other assignments")
+		return
+	}
+	if len(candidates) == 0 {
+		return
+	}
+
+	byWorkID := make(map[string]*models.Report, len(candidates))
+	comparisonSet := make([]models.SimilarWork, 0, len(candidates))
+	for i := range candidates {
+		candidate := &candidates[i]
+		byWorkID[candidate.WorkID] = candidate
+		comparisonSet = append(comparisonSet, models.SimilarWork{
+			WorkID:       candidate.WorkID,
+			StudentID:    candidate.StudentID,
+			AssignmentID: candidate.AssignmentID,
+			FileID:       candidate.FileID,
+			FileHash:     candidate.FileHash,
+			SubmittedAt:  candidate.CreatedAt,
+		})
+	}
+
+	result, err := s.plagiarismChecker.CheckPlagiarismAgainst(ctx, report.WorkID, report.FileID, report.AssignmentID, report.StudentID, comparisonSet, threshold)
+	if err != nil {
+		s.logger.Error().Err(err).Str("work_id", report.WorkID).Msg("Cross-assignment comparison failed")
+		return
+	}
+
+	cluster := models.CrossAssignmentCluster{
+		WindowMinutes: int(s.config.CrossAssignmentWindow.Minutes()),
+		ComparedCount: len(comparisonSet),
+		Matches:       []models.CrossAssignmentMatch{},
+		CheckedAt:     time.Now(),
+	}
+	for _, similar := range result.SimilarWorks {
+		// Совпадение из индекса точных копий может указывать на работу своего задания
+		candidate, ok := byWorkID[similar.WorkID]
+		if !ok || similar.MatchPercentage <= 0 || similar.MatchPercentage < threshold {
+			continue
+		}
+
+		apart := report.CreatedAt.Sub(candidate.CreatedAt)
+		if apart < 0 {
+			apart = -apart
+		}
+		cluster.Matches = append(cluster.Matches, models.CrossAssignmentMatch{
+			WorkID:          candidate.WorkID,
+			AssignmentID:    candidate.AssignmentID,
+			StudentID:       candidate.StudentID,
+			MatchPercentage: similar.MatchPercentage,
+			SubmittedAt:     candidate.CreatedAt,
+			MinutesApart:    int(apart.Minutes()),
+		})
+	}
+	cluster.Flagged = len(cluster.Matches) > 0
+
+	clusterJSON, err := json.Marshal(cluster)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to marshal cross-assignment matches")
+		return
+	}
+	if err := s.reportRepo.SetCrossAssignment(ctx, report.ID, clusterJSON); err != nil {
+		s.logger.Error().Err(err).Str("report_id", report.ID).Msg("Failed to save cross-assignment matches")
+		return
+	}
+
+	if cluster.Flagged {
+		s.logger.Warn().
+			Str("work_id", report.WorkID).
+			Str("assignment_id", report.AssignmentID).
+			Int("matches", len(cluster.Matches)).
+			Msg("Submission matches recent works of other assignments")
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/analyzer"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
+)
+
+// Ошибки анализа, отчётов, подписок, уведомлений и эталонного корпуса, которые delivery-слой переводит в HTTP-статус и код через errors.Is.
+// Текст каждой ошибки совпадает с сообщением, которое видит клиент.
+var (
+	ErrAnalysisNotFound   = errors.New("analysis not found for this work")
+	ErrComparisonNotFound = errors.New("comparison not found for this pair")
+	ErrHashNotFound       = errors.New("no works found for this hash")
+	ErrInvalidThreshold   = errors.New("threshold must be within 0..100")
+	ErrBatchTooLarge      = errors.New("batch size exceeds limit")
+	// Оборачивает любую ошибку проверяющего, которая не распознана точнее
+	ErrAnalysisFailed = errors.New("plagiarism check failed")
+
+	ErrReportIDNotFound          = errors.New("report not found")
+	ErrReportNotCompleted        = errors.New("report is not completed")
+	ErrExportJobNotFound         = errors.New("export job not found")
+	ErrAssignmentReportsNotFound = errors.New("assignment not found or no reports available")
+	ErrStudentReportsNotFound    = errors.New("student not found or no reports available")
+	ErrNoFlaggedReports          = errors.New("no flagged reports found for student")
+	ErrPDFRequiresSingleReport   = errors.New("pdf export requires a single report")
+	ErrReportSearchFailed        = errors.New("failed to search reports")
+
+	ErrInvalidWebhookURL   = errors.New("invalid webhook url")
+	ErrInvalidAssignmentID = errors.New("invalid assignment_id")
+	ErrWebhookNotFound     = errors.New("webhook not found")
+
+	ErrInvalidRecipient  = errors.New("invalid recipient")
+	ErrNoRecipients      = errors.New("no notification recipients configured")
+	ErrRecipientNotFound = errors.New("recipient not found")
+
+	ErrReferenceCorpusDisabled   = errors.New("reference corpus is disabled")
+	ErrNoReferenceDocuments      = errors.New("no documents to import")
+	ErrTooManyReferenceDocuments = errors.New("too many documents in one request")
+)
+
+// Ошибки нижних слоёв, доступные delivery-слою через пакет service
+var (
+	ErrInvalidCursor          = repository.ErrInvalidCursor
+	ErrWarmupDisabled         = analyzer.ErrWarmupDisabled
+	ErrHashIndexDisabled      = analyzer.ErrHashIndexDisabled
+	ErrFileNotFound           = integration.ErrFileNotFound
+	ErrFileServiceUnavailable = integration.ErrFileServiceUnavailable
+	ErrWorkServiceUnavailable = integration.ErrWorkServiceUnavailable
+)
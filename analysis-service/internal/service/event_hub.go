@@ -0,0 +1,213 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrTooManySubscribers = errors.New("too many event subscribers")
+	ErrEventHubClosed     = errors.New("event hub is closed")
+)
+
+const (
+	SlowClientDisconnect = "disconnect"
+	SlowClientDrop       = "drop"
+)
+
+// StreamEventTypes — события анализа, которые можно получать через поток
+var StreamEventTypes = []string{
+	models.EventAnalysisStarted,
+	models.EventAnalysisCompleted,
+	models.EventAnalysisFailed,
+}
+
+// EventFilter ограничивает события подписчика; пустые поля не фильтруют
+type EventFilter struct {
+	AssignmentID string
+	StudentID    string
+	Types        []string
+}
+
+// EventHub раздаёт события анализа из брокера подключённым клиентам
+type EventHub interface {
+	Publish(eventType string, payload []byte)
+	Subscribe(filter EventFilter) (*EventSubscription, error)
+	Unsubscribe(sub *EventSubscription)
+	Close()
+}
+
+type EventHubConfig struct {
+	BufferSize int
+	// 0 — без ограничения
+	MaxSubscribers   int
+	SlowClientPolicy string
+}
+
+// EventSubscription — очередь событий одного клиента. Канал событий не закрывается;
+// об отключении хабом сообщает Done, причина доступна через Reason.
+type EventSubscription struct {
+	filter  EventFilter
+	events  chan []byte
+	done    chan struct{}
+	once    sync.Once
+	reason  string
+	dropped atomic.Int64
+}
+
+func (s *EventSubscription) Events() <-chan []byte {
+	return s.events
+}
+
+func (s *EventSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Reason можно читать только после закрытия Done
+func (s *EventSubscription) Reason() string {
+	return s.reason
+}
+
+// Dropped — сколько событий пропущено из-за переполненного буфера (политика drop)
+func (s *EventSubscription) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+func (s *EventSubscription) close(reason string) {
+	s.once.Do(func() {
+		s.reason = reason
+		close(s.done)
+	})
+}
+
+func (s *EventSubscription) matches(eventType, assignmentID, studentID string) bool {
+	if s.filter.AssignmentID != "" && s.filter.AssignmentID != assignmentID {
+		return false
+	}
+	if s.filter.StudentID != "" && s.filter.StudentID != studentID {
+		return false
+	}
+	if len(s.filter.Types) > 0 && !slices.Contains(s.filter.Types, eventType) {
+		return false
+	}
+	return true
+}
+
+type eventHub struct {
+	mu          sync.RWMutex
+	subscribers map[*EventSubscription]struct{}
+	closed      bool
+	logger      zerolog.Logger
+	config      EventHubConfig
+}
+
+func NewEventHub(logger zerolog.Logger, config EventHubConfig) EventHub {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 64
+	}
+	if config.SlowClientPolicy == "" {
+		config.SlowClientPolicy = SlowClientDisconnect
+	}
+
+	return &eventHub{
+		subscribers: make(map[*EventSubscription]struct{}),
+		logger:      logger,
+		config:      config,
+	}
+}
+
+func (h *eventHub) Subscribe(filter EventFilter) (*EventSubscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrEventHubClosed
+	}
+	if h.config.MaxSubscribers > 0 && len(h.subscribers) >= h.config.MaxSubscribers {
+		return nil, ErrTooManySubscribers
+	}
+
+	sub := &EventSubscription{
+		filter: filter,
+		events: make(chan []byte, h.config.BufferSize),
+		done:   make(chan struct{}),
+	}
+	h.subscribers[sub] = struct{}{}
+
+	return sub, nil
+}
+
+func (h *eventHub) Unsubscribe(sub *EventSubscription) {
+	h.remove(sub, "unsubscribed")
+}
+
+// Publish никогда не блокируется на клиентах: медленный подписчик либо теряет событие,
+// либо отключается, в зависимости от SlowClientPolicy
+func (h *eventHub) Publish(eventType string, payload []byte) {
+	var envelope struct {
+		AssignmentID string `json:"assignment_id"`
+		StudentID    string `json:"student_id"`
+	}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		h.logger.Warn().Err(err).Str("event_type", eventType).Msg("Skipping malformed analysis event")
+		return
+	}
+
+	message, err := json.Marshal(models.AnalysisStreamMessage{
+		Type: eventType,
+		Data: payload,
+	})
+	if err != nil {
+		h.logger.Error().Err(err).Str("event_type", eventType).Msg("Failed to marshal stream message")
+		return
+	}
+
+	var slow []*EventSubscription
+	h.mu.RLock()
+	for sub := range h.subscribers {
+		if !sub.matches(eventType, envelope.AssignmentID, envelope.StudentID) {
+			continue
+		}
+
+		select {
+		case sub.events <- message:
+		default:
+			if h.config.SlowClientPolicy == SlowClientDrop {
+				sub.dropped.Add(1)
+			} else {
+				slow = append(slow, sub)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range slow {
+		h.logger.Warn().Int("buffer_size", h.config.BufferSize).Msg("Disconnecting slow event subscriber")
+		h.remove(sub, "slow consumer")
+	}
+}
+
+func (h *eventHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subscribers {
+		sub.close("server shutting down")
+		delete(h.subscribers, sub)
+	}
+}
+
+func (h *eventHub) remove(sub *EventSubscription, reason string) {
+	h.mu.Lock()
+	delete(h.subscribers, sub)
+	h.mu.Unlock()
+
+	sub.close(reason)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+// exportJobStore хранит фоновые выгрузки в памяти до истечения TTL
+type exportJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*exportJobEntry
+	ttl  time.Duration
+}
+
+type exportJobEntry struct {
+	job  models.ExportJob
+	data []byte
+}
+
+func newExportJobStore(ttl time.Duration) *exportJobStore {
+	return &exportJobStore{
+		jobs: make(map[string]*exportJobEntry),
+		ttl:  ttl,
+	}
+}
+
+func (s *exportJobStore) add(job models.ExportJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired()
+	s.jobs[job.JobID] = &exportJobEntry{job: job}
+}
+
+func (s *exportJobStore) complete(jobID string, data []byte, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.jobs[jobID]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	entry.job.CompletedAt = &now
+	if err != nil {
+		entry.job.Status = models.ExportJobStatusFailed
+		entry.job.Error = err.Error()
+		return
+	}
+
+	entry.job.Status = models.ExportJobStatusCompleted
+	entry.data = data
+}
+
+func (s *exportJobStore) get(jobID string) (*models.ExportJob, []byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictExpired()
+	entry, ok := s.jobs[jobID]
+	if !ok {
+		return nil, nil, false
+	}
+
+	job := entry.job
+	return &job, entry.data, true
+}
+
+func (s *exportJobStore) evictExpired() {
+	if s.ttl <= 0 {
+		return
+	}
+
+	for id, entry := range s.jobs {
+		if entry.job.CompletedAt != nil && time.Since(*entry.job.CompletedAt) > s.ttl {
+			delete(s.jobs, id)
+		}
+	}
+}
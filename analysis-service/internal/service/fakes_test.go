@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/analyzer"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/worker/queue"
+)
+
+// Заглушки зависимостей сервиса: встроенный интерфейс остаётся nil, поэтому вызов метода,
+// который тест не ожидает, сразу падает с паникой
+
+type fakeReportRepo struct {
+	repository.ReportRepository
+
+	mu      sync.Mutex
+	reports map[string]*models.Report
+}
+
+func newFakeReportRepo() *fakeReportRepo {
+	return &fakeReportRepo{reports: make(map[string]*models.Report)}
+}
+
+func (r *fakeReportRepo) GetByWorkID(ctx context.Context, workID string) (*models.Report, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.reports[workID], nil
+}
+
+func (r *fakeReportRepo) Create(ctx context.Context, report *models.Report) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports[report.WorkID] = report
+	return nil
+}
+
+func (r *fakeReportRepo) Update(ctx context.Context, report *models.Report) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports[report.WorkID] = report
+	return nil
+}
+
+// Search понимает только фильтры work_id и report_id; порядок выдачи — по ID отчёта
+func (r *fakeReportRepo) Search(ctx context.Context, filters map[string]interface{}, limit, offset int) ([]models.Report, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []models.Report
+	for _, report := range r.reports {
+		if workID, ok := filters["work_id"]; ok && report.WorkID != workID {
+			continue
+		}
+		if reportID, ok := filters["report_id"]; ok && report.ID != reportID {
+			continue
+		}
+		found = append(found, *report)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found, len(found), nil
+}
+
+func (r *fakeReportRepo) UpdateStatus(ctx context.Context, id, status string) error {
+	return nil
+}
+
+func (r *fakeReportRepo) SetPersistenceTime(ctx context.Context, id string, persistenceMs int64) error {
+	return nil
+}
+
+func (r *fakeReportRepo) GetAssignmentThreshold(ctx context.Context, assignmentID string) (int, bool, error) {
+	return 0, false, nil
+}
+
+type fakeWorkClient struct {
+	integration.WorkClient
+
+	works map[string]*models.SimilarWork
+}
+
+func (c *fakeWorkClient) GetWorkInfo(ctx context.Context, workID string) (*models.SimilarWork, error) {
+	return c.works[workID], nil
+}
+
+func (c *fakeWorkClient) GetPreviousWorks(ctx context.Context, assignmentID, excludeWorkID string) ([]models.SimilarWork, error) {
+	var works []models.SimilarWork
+	for _, work := range c.works {
+		if work.AssignmentID == assignmentID && work.WorkID != excludeWorkID {
+			works = append(works, *work)
+		}
+	}
+	return works, nil
+}
+
+func (c *fakeWorkClient) UpdateWorkStatus(ctx context.Context, workID, status string) error {
+	return nil
+}
+
+// fakeChecker отдаёт результат check; без check — пустой завершённый результат
+type fakeChecker struct {
+	analyzer.PlagiarismChecker
+
+	check func(ctx context.Context, workID string) (*models.AnalysisResult, error)
+}
+
+func (c *fakeChecker) CheckPlagiarism(ctx context.Context, workID, fileID, assignmentID, studentID string, threshold int) (*models.AnalysisResult, error) {
+	return c.result(ctx, workID)
+}
+
+func (c *fakeChecker) CheckPlagiarismAgainst(ctx context.Context, workID, fileID, assignmentID, studentID string, previousWorks []models.SimilarWork, threshold int) (*models.AnalysisResult, error) {
+	return c.result(ctx, workID)
+}
+
+func (c *fakeChecker) IndexWork(entry models.HashIndexEntry) {}
+
+func (c *fakeChecker) result(ctx context.Context, workID string) (*models.AnalysisResult, error) {
+	if c.check != nil {
+		return c.check(ctx, workID)
+	}
+	return &models.AnalysisResult{WorkID: workID, Status: "completed"}, nil
+}
+
+type fakePublisher struct {
+	queue.RabbitMQPublisher
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, exchange, routingKey string, body []byte) error {
+	return nil
+}
@@ -0,0 +1,23 @@
+package integration
+
+import "net/http"
+
+// apiKeyTransport добавляет к запросам в другие сервисы общий ключ из services.<имя>.api_key
+type apiKeyTransport struct {
+	key  string
+	base http.RoundTripper
+}
+
+func newAPIKeyTransport(key string) http.RoundTripper {
+	if key == "" {
+		return http.DefaultTransport
+	}
+	return &apiKeyTransport{key: key, base: http.DefaultTransport}
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper не должен менять исходный запрос
+	req = req.Clone(req.Context())
+	req.Header.Set("X-API-Key", t.key)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,11 @@
+package integration
+
+import "errors"
+
+// Ошибки обращения к соседним сервисам. Клиенты оборачивают в них итоговую ошибку,
+// чтобы вызывающий код различал причину через errors.Is, а не по тексту.
+var (
+	ErrFileNotFound           = errors.New("file not found")
+	ErrFileServiceUnavailable = errors.New("file service unavailable")
+	ErrWorkServiceUnavailable = errors.New("work service unavailable")
+)
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/tracing"
 	"github.com/rs/zerolog"
 )
 
@@ -15,6 +16,10 @@ type FileClient interface {
 	GetFileHash(ctx context.Context, fileID string) (string, int64, error)
 	GetFileContent(ctx context.Context, fileID string) ([]byte, error)
 	GetFileInfo(ctx context.Context, fileID string) (*FileInfoResponse, error)
+	// GetContentHash — хеш нормализованного текста документа (PDF, DOCX, текст); пустой, если текст не извлекается
+	GetContentHash(ctx context.Context, fileID string) (string, error)
+	// HealthCheck — ошибка, если file-service не ответил 200 на /health
+	HealthCheck(ctx context.Context) error
 }
 
 type fileClient struct {
@@ -29,6 +34,7 @@ type fileClient struct {
 type FileInfoResponse struct {
 	FileID       string `json:"file_id"`
 	Hash         string `json:"hash"`
+	ContentHash  string `json:"content_hash,omitempty"`
 	Size         int64  `json:"file_size"`
 	OriginalName string `json:"original_name"`
 	MimeType     string `json:"mime_type"`
@@ -39,14 +45,15 @@ type fileInfoEnvelope struct {
 	Data    FileInfoResponse `json:"data"`
 }
 
-func NewFileClient(baseURL string, timeout time.Duration, retryCount int, retryDelay time.Duration, logger zerolog.Logger) FileClient {
+func NewFileClient(baseURL, apiKey string, timeout time.Duration, retryCount int, retryDelay time.Duration, logger zerolog.Logger) FileClient {
 	return &fileClient{
 		baseURL:    baseURL,
 		timeout:    timeout,
 		retryCount: retryCount,
 		retryDelay: retryDelay,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: tracing.Transport(newAPIKeyTransport(apiKey)),
 		},
 		logger: logger,
 	}
@@ -100,7 +107,7 @@ func (c *fileClient) GetFileHash(ctx context.Context, fileID string) (string, in
 
 		if resp.StatusCode == http.StatusNotFound {
 			resp.Body.Close()
-			return "", 0, fmt.Errorf("file not found: %s", fileID)
+			return "", 0, fmt.Errorf("%w: %s", ErrFileNotFound, fileID)
 		}
 
 		body, _ := io.ReadAll(resp.Body)
@@ -108,7 +115,7 @@ func (c *fileClient) GetFileHash(ctx context.Context, fileID string) (string, in
 		lastErr = fmt.Errorf("file service returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	return "", 0, fmt.Errorf("failed to get file hash after %d attempts: %w", c.retryCount+1, lastErr)
+	return "", 0, fmt.Errorf("%w: failed to get file hash after %d attempts: %w", ErrFileServiceUnavailable, c.retryCount+1, lastErr)
 }
 
 func (c *fileClient) GetFileContent(ctx context.Context, fileID string) ([]byte, error) {
@@ -155,7 +162,7 @@ func (c *fileClient) GetFileContent(ctx context.Context, fileID string) ([]byte,
 
 		if resp.StatusCode == http.StatusNotFound {
 			resp.Body.Close()
-			return nil, fmt.Errorf("file not found: %s", fileID)
+			return nil, fmt.Errorf("%w: %s", ErrFileNotFound, fileID)
 		}
 
 		body, _ := io.ReadAll(resp.Body)
@@ -163,7 +170,7 @@ func (c *fileClient) GetFileContent(ctx context.Context, fileID string) ([]byte,
 		lastErr = fmt.Errorf("file service returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	return nil, fmt.Errorf("failed to get file content after %d attempts: %w", c.retryCount+1, lastErr)
+	return nil, fmt.Errorf("%w: failed to get file content after %d attempts: %w", ErrFileServiceUnavailable, c.retryCount+1, lastErr)
 }
 
 func (c *fileClient) GetFileInfo(ctx context.Context, fileID string) (*FileInfoResponse, error) {
@@ -215,5 +222,16 @@ func (c *fileClient) GetFileInfo(ctx context.Context, fileID string) (*FileInfoR
 		lastErr = fmt.Errorf("file service returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	return nil, fmt.Errorf("failed to get file info after %d attempts: %w", c.retryCount+1, lastErr)
+	return nil, fmt.Errorf("%w: failed to get file info after %d attempts: %w", ErrFileServiceUnavailable, c.retryCount+1, lastErr)
+}
+
+func (c *fileClient) GetContentHash(ctx context.Context, fileID string) (string, error) {
+	info, err := c.GetFileInfo(ctx, fileID)
+	if err != nil {
+		return "", err
+	}
+	if info == nil {
+		return "", fmt.Errorf("%w: %s", ErrFileNotFound, fileID)
+	}
+	return info.ContentHash, nil
 }
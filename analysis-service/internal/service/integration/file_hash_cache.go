@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/sharedcache"
+)
+
+type cachedFileHash struct {
+	Hash string `json:"hash"`
+	Size int64  `json:"size"`
+}
+
+// cachingFileClient запоминает хеши и размер файла по file_id: содержимое файла после загрузки
+// не меняется, поэтому TTL нужен только чтобы не держать в кеше файлы прошедших заданий.
+// Остальные методы идут в file-service напрямую.
+type cachingFileClient struct {
+	FileClient
+	store sharedcache.Store
+	ttl   time.Duration
+}
+
+// NewCachingFileClient — с Redis-хранилищем кеш общий для всех экземпляров; ошибка кеша
+// не прерывает проверку, хеш просто запрашивается у file-service
+func NewCachingFileClient(fileClient FileClient, store sharedcache.Store, ttl time.Duration) FileClient {
+	return &cachingFileClient{
+		FileClient: fileClient,
+		store:      store,
+		ttl:        ttl,
+	}
+}
+
+func (c *cachingFileClient) GetFileHash(ctx context.Context, fileID string) (string, int64, error) {
+	key := "file_hash:" + fileID
+
+	if value, ok, err := c.store.Get(ctx, key); err == nil && ok {
+		var item cachedFileHash
+		if json.Unmarshal([]byte(value), &item) == nil {
+			return item.Hash, item.Size, nil
+		}
+	}
+
+	hash, size, err := c.FileClient.GetFileHash(ctx, fileID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if value, err := json.Marshal(cachedFileHash{Hash: hash, Size: size}); err == nil {
+		_ = c.store.Set(ctx, key, string(value), c.ttl)
+	}
+
+	return hash, size, nil
+}
+
+func (c *cachingFileClient) GetContentHash(ctx context.Context, fileID string) (string, error) {
+	key := "content_hash:" + fileID
+
+	if value, ok, err := c.store.Get(ctx, key); err == nil && ok {
+		return value, nil
+	}
+
+	contentHash, err := c.FileClient.GetContentHash(ctx, fileID)
+	if err != nil {
+		return "", err
+	}
+
+	_ = c.store.Set(ctx, key, contentHash, c.ttl)
+	return contentHash, nil
+}
@@ -0,0 +1,37 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// checkHealth запрашивает /health сервиса один раз, без повторов: проверка готовности должна
+// отвечать быстро, а недоступность сервиса — её результат, а не повод ждать
+func checkHealth(ctx context.Context, client *http.Client, baseURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("health check request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *fileClient) HealthCheck(ctx context.Context) error {
+	return checkHealth(ctx, c.client, c.baseURL)
+}
+
+func (c *workClient) HealthCheck(ctx context.Context) error {
+	return checkHealth(ctx, c.client, c.baseURL)
+}
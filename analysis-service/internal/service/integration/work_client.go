@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/tracing"
 	"github.com/rs/zerolog"
 )
 
@@ -17,6 +18,8 @@ type WorkClient interface {
 	GetPreviousWorks(ctx context.Context, assignmentID, excludeWorkID string) ([]models.SimilarWork, error)
 	GetWorkInfo(ctx context.Context, workID string) (*models.SimilarWork, error)
 	UpdateWorkStatus(ctx context.Context, workID, status string) error
+	// HealthCheck — ошибка, если work-service не ответил 200 на /health
+	HealthCheck(ctx context.Context) error
 }
 
 type workClient struct {
@@ -29,28 +32,91 @@ type workClient struct {
 	logger     zerolog.Logger
 }
 
-func NewWorkClient(baseURL string, timeout time.Duration, retryCount int, retryDelay time.Duration, fileClient FileClient, logger zerolog.Logger) WorkClient {
+func NewWorkClient(baseURL, apiKey string, timeout time.Duration, retryCount int, retryDelay time.Duration, fileClient FileClient, logger zerolog.Logger) WorkClient {
 	return &workClient{
 		baseURL:    baseURL,
 		timeout:    timeout,
 		retryCount: retryCount,
 		retryDelay: retryDelay,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: tracing.Transport(newAPIKeyTransport(apiKey)),
 		},
 		fileClient: fileClient,
 		logger:     logger,
 	}
 }
 
+// GetPreviousWorks — работы задания для сравнения с excludeWorkID: без неё самой, без работ
+// без файла, без попыток, заменённых по политике replace, и без других попыток того же студента —
+// иначе повторная сдача совпала бы с его собственной прежней попыткой
 func (c *workClient) GetPreviousWorks(ctx context.Context, assignmentID, excludeWorkID string) ([]models.SimilarWork, error) {
 	if c.fileClient == nil {
 		return nil, fmt.Errorf("file client is not configured")
 	}
 
+	works, err := c.listAssignmentWorks(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	excludeStudentID := ""
+	for _, w := range works {
+		if excludeWorkID != "" && w.ID == excludeWorkID {
+			excludeStudentID = w.StudentID
+			break
+		}
+	}
+
+	var allWorks []models.SimilarWork
+	for _, w := range works {
+		// Работа без загруженного файла не участвует в сравнении
+		if w.ID == "" || w.ID == excludeWorkID || w.FileID == "" || w.FileID == models.PendingFileID {
+			continue
+		}
+		if w.ReplacedBy != nil || (excludeStudentID != "" && w.StudentID == excludeStudentID) {
+			continue
+		}
+
+		fileHash, fileSize, err := c.fileClient.GetFileHash(ctx, w.FileID)
+		if err != nil {
+			c.logger.Warn().
+				Err(err).
+				Str("work_id", w.ID).
+				Str("file_id", w.FileID).
+				Msg("Failed to fetch hash for previous work, skipping")
+			continue
+		}
+
+		allWorks = append(allWorks, models.SimilarWork{
+			WorkID:       w.ID,
+			StudentID:    w.StudentID,
+			AssignmentID: w.AssignmentID,
+			FileID:       w.FileID,
+			FileHash:     fileHash,
+			FileSize:     fileSize,
+			SubmittedAt:  w.CreatedAt,
+		})
+	}
+
+	return allWorks, nil
+}
+
+// assignmentWork — работа из списка GET /assignments/{id}/works
+type assignmentWork struct {
+	ID           string    `json:"id"`
+	StudentID    string    `json:"student_id"`
+	AssignmentID string    `json:"assignment_id"`
+	FileID       string    `json:"file_id"`
+	ReplacedBy   *string   `json:"replaced_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// listAssignmentWorks читает все страницы списка работ задания; задание не найдено — пустой список
+func (c *workClient) listAssignmentWorks(ctx context.Context, assignmentID string) ([]assignmentWork, error) {
 	page := 1
 	limit := 100
-	var allWorks []models.SimilarWork
+	var works []assignmentWork
 
 	for {
 		url := fmt.Sprintf("%s/api/v1/assignments/%s/works?page=%d&limit=%d", c.baseURL, assignmentID, page, limit)
@@ -62,64 +128,37 @@ func (c *workClient) GetPreviousWorks(ctx context.Context, assignmentID, exclude
 
 		resp, err := c.client.Do(req)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get previous works: %w", err)
+			return nil, fmt.Errorf("%w: failed to get previous works: %w", ErrWorkServiceUnavailable, err)
 		}
 
 		if resp.StatusCode == http.StatusNotFound {
 			resp.Body.Close()
-			return []models.SimilarWork{}, nil
+			return nil, nil
 		}
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
 			resp.Body.Close()
-			return nil, fmt.Errorf("work service returned status %d: %s", resp.StatusCode, string(body))
+			return nil, fmt.Errorf("%w: work service returned status %d: %s", ErrWorkServiceUnavailable, resp.StatusCode, string(body))
 		}
 
 		var worksResp struct {
 			Success bool `json:"success"`
 			Data    struct {
-				Works []struct {
-					ID           string    `json:"id"`
-					StudentID    string    `json:"student_id"`
-					AssignmentID string    `json:"assignment_id"`
-					FileID       string    `json:"file_id"`
-					CreatedAt    time.Time `json:"created_at"`
-				} `json:"works"`
-				Total int `json:"total"`
-				Page  int `json:"page"`
-				Limit int `json:"limit"`
+				Works []assignmentWork `json:"works"`
+				Total int              `json:"total"`
+				Page  int              `json:"page"`
+				Limit int              `json:"limit"`
 			} `json:"data"`
 		}
 
 		if err := json.NewDecoder(resp.Body).Decode(&worksResp); err != nil {
 			resp.Body.Close()
-			return nil, fmt.Errorf("failed to decode work service response: %w", err)
+			return nil, fmt.Errorf("%w: failed to decode work service response: %w", ErrWorkServiceUnavailable, err)
 		}
 		resp.Body.Close()
 
-		for _, w := range worksResp.Data.Works {
-			if w.ID == "" || w.ID == excludeWorkID || w.FileID == "" {
-				continue
-			}
-
-			fileHash, _, err := c.fileClient.GetFileHash(ctx, w.FileID)
-			if err != nil {
-				c.logger.Warn().
-					Err(err).
-					Str("work_id", w.ID).
-					Str("file_id", w.FileID).
-					Msg("Failed to fetch hash for previous work, skipping")
-				continue
-			}
-
-			allWorks = append(allWorks, models.SimilarWork{
-				WorkID:      w.ID,
-				StudentID:   w.StudentID,
-				FileHash:    fileHash,
-				SubmittedAt: w.CreatedAt,
-			})
-		}
+		works = append(works, worksResp.Data.Works...)
 
 		if len(worksResp.Data.Works) == 0 || page*limit >= worksResp.Data.Total {
 			break
@@ -127,13 +166,12 @@ func (c *workClient) GetPreviousWorks(ctx context.Context, assignmentID, exclude
 		page++
 	}
 
-	return allWorks, nil
+	return works, nil
 }
 
 func (c *workClient) GetWorkInfo(ctx context.Context, workID string) (*models.SimilarWork, error) {
 	url := fmt.Sprintf("%s/api/v1/works/%s", c.baseURL, workID)
 
-	var workInfo *models.SimilarWork
 	var lastErr error
 
 	for i := 0; i <= c.retryCount; i++ {
@@ -158,13 +196,28 @@ func (c *workClient) GetWorkInfo(ctx context.Context, workID string) (*models.Si
 		}
 
 		if resp.StatusCode == http.StatusOK {
-			if err := json.NewDecoder(resp.Body).Decode(&workInfo); err != nil {
+			var workResp struct {
+				Data struct {
+					ID           string    `json:"id"`
+					StudentID    string    `json:"student_id"`
+					AssignmentID string    `json:"assignment_id"`
+					FileID       string    `json:"file_id"`
+					CreatedAt    time.Time `json:"created_at"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&workResp); err != nil {
 				resp.Body.Close()
 				lastErr = fmt.Errorf("failed to decode response: %w", err)
 				continue
 			}
 			resp.Body.Close()
-			return workInfo, nil
+			return &models.SimilarWork{
+				WorkID:       workResp.Data.ID,
+				StudentID:    workResp.Data.StudentID,
+				AssignmentID: workResp.Data.AssignmentID,
+				FileID:       workResp.Data.FileID,
+				SubmittedAt:  workResp.Data.CreatedAt,
+			}, nil
 		}
 
 		if resp.StatusCode == http.StatusNotFound {
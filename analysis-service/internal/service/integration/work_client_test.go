@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// hashFileClient отдаёт хеш файла по его id
+type hashFileClient struct {
+	FileClient
+}
+
+func (hashFileClient) GetFileHash(_ context.Context, fileID string) (string, int64, error) {
+	return "hash-" + fileID, 42, nil
+}
+
+// workServiceStub отвечает на GET /assignments/{id}/works списком works одной страницей
+func workServiceStub(t *testing.T, works []map[string]interface{}) WorkClient {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/v1/assignments/essay/works") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"works": works, "total": len(works), "page": 1, "limit": 100},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return NewWorkClient(server.URL, "", time.Second, 0, 0, hashFileClient{}, zerolog.Nop())
+}
+
+func TestGetPreviousWorksSkipsOwnAttemptsAndReplacedWorks(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	work := func(id, student, file string) map[string]interface{} {
+		return map[string]interface{}{"id": id, "student_id": student, "assignment_id": "essay", "file_id": file, "created_at": created}
+	}
+	replaced := work("bob-1", "bob", "file-bob-1")
+	replaced["replaced_by"] = "bob-2"
+
+	client := workServiceStub(t, []map[string]interface{}{
+		work("alice-2", "alice", "file-alice-2"),
+		work("alice-1", "alice", "file-alice-1"),
+		work("bob-2", "bob", "file-bob-2"),
+		replaced,
+		work("carol-1", "carol", "pending"),
+	})
+
+	tests := []struct {
+		name        string
+		excludeWork string
+		want        []string
+	}{
+		// Повторная сдача Алисы не сравнивается с её первой попыткой
+		{"resubmission", "alice-2", []string{"bob-2"}},
+		{"other student", "bob-2", []string{"alice-1", "alice-2"}},
+		// Без работы, для которой идёт сравнение, отдаются все работы с файлом, кроме заменённых
+		{"whole assignment", "", []string{"alice-1", "alice-2", "bob-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			works, err := client.GetPreviousWorks(context.Background(), "essay", tt.excludeWork)
+			if err != nil {
+				t.Fatalf("get previous works: %v", err)
+			}
+
+			var got []string
+			for _, w := range works {
+				if w.FileHash != "hash-"+w.FileID {
+					t.Errorf("work %s has hash %q, want hash of %s", w.WorkID, w.FileHash, w.FileID)
+				}
+				got = append(got, w.WorkID)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("works = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
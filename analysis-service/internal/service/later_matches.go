@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+// annotateEarlierWorks отмечает в отчётах более ранних работ других студентов, что report совпал с ними
+// не меньше чем на LaterMatchMinMatch. Ранняя работа анализировалась, когда сравнивать её было не с чем,
+// и без отметки её отчёт остаётся чистым; plagiarism_flag ранней работы не меняется и повторный анализ не запускается
+func (s *analysisService) annotateEarlierWorks(report *models.Report, similarWorks []models.SimilarWork, completedAt time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	annotated := 0
+	for _, similar := range similarWorks {
+		if similar.StudentID == report.StudentID || similar.MatchPercentage < s.config.LaterMatchMinMatch {
+			continue
+		}
+		// Совпадение с работой, сданной позже текущей, она сама отметит при своём анализе
+		if !similar.SubmittedAt.IsZero() && similar.SubmittedAt.After(report.CreatedAt) {
+			continue
+		}
+
+		match, err := json.Marshal(models.LaterMatch{
+			WorkID:          report.WorkID,
+			ReportID:        report.ID,
+			StudentID:       report.StudentID,
+			MatchPercentage: similar.MatchPercentage,
+			DetectedAt:      completedAt,
+		})
+		if err != nil {
+			s.logger.Error().Err(err).Msg("Failed to marshal later match")
+			return
+		}
+
+		added, err := s.reportRepo.AddLaterMatch(ctx, similar.WorkID, report.WorkID, match)
+		if err != nil {
+			s.logger.Error().
+				Err(err).
+				Str("work_id", similar.WorkID).
+				Str("later_work_id", report.WorkID).
+				Msg("Failed to record later match")
+			continue
+		}
+		if added {
+			annotated++
+		}
+	}
+
+	if annotated > 0 {
+		s.logger.Info().
+			Str("work_id", report.WorkID).
+			Int("annotated", annotated).
+			Msg("Earlier works annotated with later match")
+	}
+}
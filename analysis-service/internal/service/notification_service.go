@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+type NotificationService interface {
+	NotifyPlagiarism(ctx context.Context, notification models.PlagiarismNotification) error
+	GetRecipients(ctx context.Context, assignmentID string) ([]models.NotificationRecipient, error)
+	AddRecipient(ctx context.Context, assignmentID, recipient string) (*models.NotificationRecipient, error)
+	RemoveRecipient(ctx context.Context, assignmentID, recipientID string) error
+	SendTestNotification(ctx context.Context, assignmentID string) (*models.TestNotificationResponse, error)
+}
+
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	client           *http.Client
+	logger           zerolog.Logger
+	config           NotificationConfig
+}
+
+type NotificationConfig struct {
+	Enabled           bool
+	DefaultRecipients []string
+	Timeout           time.Duration
+	SMTPHost          string
+	SMTPPort          int
+	SMTPUsername      string
+	SMTPPassword      string
+	SMTPFrom          string
+}
+
+func NewNotificationService(
+	notificationRepo repository.NotificationRepository,
+	logger zerolog.Logger,
+	config NotificationConfig,
+) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		client: &http.Client{
+			Timeout: config.Timeout,
+		},
+		logger: logger,
+		config: config,
+	}
+}
+
+func (s *notificationService) NotifyPlagiarism(ctx context.Context, notification models.PlagiarismNotification) error {
+	if !s.config.Enabled {
+		return nil
+	}
+
+	recipients, err := s.resolveRecipients(ctx, notification.AssignmentID)
+	if err != nil {
+		return err
+	}
+
+	if len(recipients) == 0 {
+		s.logger.Warn().
+			Str("assignment_id", notification.AssignmentID).
+			Msg("No notification recipients configured")
+		return nil
+	}
+
+	var failed int
+	for _, recipient := range recipients {
+		if _, err := s.deliver(ctx, recipient, notification); err != nil {
+			failed++
+			s.logger.Error().
+				Err(err).
+				Str("work_id", notification.WorkID).
+				Str("recipient", recipient.Recipient).
+				Str("type", recipient.Type).
+				Msg("Failed to deliver plagiarism notification")
+			continue
+		}
+
+		s.logger.Info().
+			Str("work_id", notification.WorkID).
+			Str("recipient", recipient.Recipient).
+			Msg("Plagiarism notification delivered")
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to deliver %d of %d notifications", failed, len(recipients))
+	}
+	return nil
+}
+
+// resolveRecipients возвращает получателей задания, а если их нет — глобальных по умолчанию
+func (s *notificationService) resolveRecipients(ctx context.Context, assignmentID string) ([]models.NotificationRecipient, error) {
+	recipients, err := s.notificationRepo.GetRecipients(ctx, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification recipients: %w", err)
+	}
+
+	if len(recipients) > 0 {
+		return recipients, nil
+	}
+
+	defaults := make([]models.NotificationRecipient, 0, len(s.config.DefaultRecipients))
+	for _, recipient := range s.config.DefaultRecipients {
+		recipientType := recipientType(recipient)
+		if recipientType == "" {
+			s.logger.Warn().Str("recipient", recipient).Msg("Skipping invalid default notification recipient")
+			continue
+		}
+		defaults = append(defaults, models.NotificationRecipient{
+			AssignmentID: assignmentID,
+			Recipient:    recipient,
+			Type:         recipientType,
+		})
+	}
+
+	return defaults, nil
+}
+
+func (s *notificationService) GetRecipients(ctx context.Context, assignmentID string) ([]models.NotificationRecipient, error) {
+	recipients, err := s.notificationRepo.GetRecipients(ctx, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification recipients: %w", err)
+	}
+
+	if recipients == nil {
+		recipients = []models.NotificationRecipient{}
+	}
+	return recipients, nil
+}
+
+func (s *notificationService) AddRecipient(ctx context.Context, assignmentID, recipient string) (*models.NotificationRecipient, error) {
+	recipient = strings.TrimSpace(recipient)
+	recipientType := recipientType(recipient)
+	if recipientType == "" {
+		return nil, ErrInvalidRecipient
+	}
+
+	record := &models.NotificationRecipient{
+		AssignmentID: assignmentID,
+		Recipient:    recipient,
+		Type:         recipientType,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := s.notificationRepo.AddRecipient(ctx, record); err != nil {
+		return nil, fmt.Errorf("failed to add notification recipient: %w", err)
+	}
+
+	return record, nil
+}
+
+func (s *notificationService) RemoveRecipient(ctx context.Context, assignmentID, recipientID string) error {
+	deleted, err := s.notificationRepo.DeleteRecipient(ctx, assignmentID, recipientID)
+	if err != nil {
+		return fmt.Errorf("failed to delete notification recipient: %w", err)
+	}
+	if !deleted {
+		return ErrRecipientNotFound
+	}
+	return nil
+}
+
+// SendTestNotification отправляет пример уведомления получателям задания (или глобальным)
+// независимо от notifications.enabled. Отчёт при этом не создаётся.
+func (s *notificationService) SendTestNotification(ctx context.Context, assignmentID string) (*models.TestNotificationResponse, error) {
+	recipients, err := s.resolveRecipients(ctx, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(recipients) == 0 {
+		return nil, ErrNoRecipients
+	}
+
+	originalWorkID := "00000000-0000-0000-0000-000000000002"
+	notification := models.PlagiarismNotification{
+		WorkID:          "00000000-0000-0000-0000-000000000001",
+		ReportID:        "00000000-0000-0000-0000-000000000000",
+		AssignmentID:    assignmentID,
+		StudentID:       "00000000-0000-0000-0000-000000000003",
+		OriginalWorkID:  &originalWorkID,
+		MatchPercentage: 100,
+		DetectedAt:      time.Now(),
+		Test:            true,
+	}
+
+	response := &models.TestNotificationResponse{
+		NotificationsEnabled: s.config.Enabled,
+		Total:                len(recipients),
+		Results:              make([]models.NotificationDeliveryResult, 0, len(recipients)),
+	}
+
+	for _, recipient := range recipients {
+		result := models.NotificationDeliveryResult{
+			Recipient: recipient.Recipient,
+			Type:      recipient.Type,
+		}
+
+		statusCode, err := s.deliver(ctx, recipient, notification)
+		result.StatusCode = statusCode
+		if err != nil {
+			result.Error = err.Error()
+			response.Failed++
+		} else {
+			result.Success = true
+			response.Delivered++
+		}
+
+		response.Results = append(response.Results, result)
+	}
+
+	s.logger.Info().
+		Str("assignment_id", assignmentID).
+		Int("delivered", response.Delivered).
+		Int("failed", response.Failed).
+		Msg("Test notification sent")
+
+	return response, nil
+}
+
+// deliver возвращает код ответа вебхука или SMTP-сервера, если он известен
+func (s *notificationService) deliver(ctx context.Context, recipient models.NotificationRecipient, notification models.PlagiarismNotification) (int, error) {
+	switch recipient.Type {
+	case models.RecipientTypeWebhook:
+		return s.sendWebhook(ctx, recipient.Recipient, notification)
+	case models.RecipientTypeEmail:
+		return s.sendEmail(recipient.Recipient, notification)
+	default:
+		return 0, fmt.Errorf("unsupported recipient type: %s", recipient.Type)
+	}
+}
+
+func (s *notificationService) sendWebhook(ctx context.Context, target string, notification models.PlagiarismNotification) (int, error) {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *notificationService) sendEmail(to string, notification models.PlagiarismNotification) (int, error) {
+	if s.config.SMTPHost == "" {
+		return 0, errors.New("smtp is not configured")
+	}
+
+	subject := fmt.Sprintf("Plagiarism detected in work %s", notification.WorkID)
+	if notification.Test {
+		subject = "[TEST] " + subject
+	}
+	body := fmt.Sprintf(
+		"Work %s by student %s (assignment %s) matched another work by %d%%.\r\nReport: %s\r\n",
+		notification.WorkID,
+		notification.StudentID,
+		notification.AssignmentID,
+		notification.MatchPercentage,
+		notification.ReportID,
+	)
+	message := "From: " + s.config.SMTPFrom + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=UTF-8\r\n\r\n" +
+		body
+
+	var auth smtp.Auth
+	if s.config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
+	}
+
+	addr := fmt.Sprintf("%s:%d", s.config.SMTPHost, s.config.SMTPPort)
+	if err := smtp.SendMail(addr, auth, s.config.SMTPFrom, []string{to}, []byte(message)); err != nil {
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) {
+			return protoErr.Code, fmt.Errorf("failed to send email: %w", err)
+		}
+		return 0, fmt.Errorf("failed to send email: %w", err)
+	}
+	// 250 — код успешного завершения SMTP-транзакции
+	return 250, nil
+}
+
+// recipientType определяет тип получателя: URL вебхука или email. Пустая строка — невалидный получатель.
+func recipientType(recipient string) string {
+	if strings.HasPrefix(recipient, "http://") || strings.HasPrefix(recipient, "https://") {
+		if u, err := url.Parse(recipient); err == nil && u.Host != "" {
+			return models.RecipientTypeWebhook
+		}
+		return ""
+	}
+
+	if addr, err := mail.ParseAddress(recipient); err == nil && addr.Address == recipient {
+		return models.RecipientTypeEmail
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/rs/zerolog"
+)
+
+type OverrideService interface {
+	OverrideVerdict(ctx context.Context, reportID string, plagiarismFlag bool, reason, overriddenBy string) (*models.VerdictOverride, error)
+	GetAssignmentOverrideStats(ctx context.Context, assignmentID string) (*models.OverrideStats, error)
+}
+
+type overrideService struct {
+	overrideRepo repository.OverrideRepository
+	reportRepo   repository.ReportRepository
+	logger       zerolog.Logger
+}
+
+func NewOverrideService(
+	overrideRepo repository.OverrideRepository,
+	reportRepo repository.ReportRepository,
+	logger zerolog.Logger,
+) OverrideService {
+	return &overrideService{
+		overrideRepo: overrideRepo,
+		reportRepo:   reportRepo,
+		logger:       logger,
+	}
+}
+
+func (s *overrideService) OverrideVerdict(ctx context.Context, reportID string, plagiarismFlag bool, reason, overriddenBy string) (*models.VerdictOverride, error) {
+	report, err := s.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+	if report == nil {
+		return nil, ErrReportIDNotFound
+	}
+	if report.Status != models.ReportStatusCompleted.String() {
+		return nil, ErrReportNotCompleted
+	}
+
+	// После нескольких изменений исходным остаётся вердикт алгоритма, а не предыдущее ручное решение
+	algorithmFlag := report.PlagiarismFlag
+	first, err := s.overrideRepo.GetFirstByReportID(ctx, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous overrides: %w", err)
+	}
+	if first != nil {
+		algorithmFlag = first.AlgorithmFlag
+	}
+
+	override := &models.VerdictOverride{
+		ReportID:       report.ID,
+		AssignmentID:   report.AssignmentID,
+		AlgorithmFlag:  algorithmFlag,
+		OverriddenFlag: plagiarismFlag,
+		OverriddenBy:   overriddenBy,
+		Reason:         reason,
+		CreatedAt:      time.Now(),
+	}
+
+	if err := s.overrideRepo.Create(ctx, override); err != nil {
+		return nil, fmt.Errorf("failed to save override: %w", err)
+	}
+
+	report.PlagiarismFlag = plagiarismFlag
+	report.UpdatedAt = time.Now()
+	if err := s.reportRepo.Update(ctx, report); err != nil {
+		return nil, fmt.Errorf("failed to update report: %w", err)
+	}
+
+	s.logger.Info().
+		Str("report_id", reportID).
+		Bool("algorithm_flag", algorithmFlag).
+		Bool("overridden_flag", plagiarismFlag).
+		Str("overridden_by", overriddenBy).
+		Msg("Verdict overridden")
+
+	return override, nil
+}
+
+func (s *overrideService) GetAssignmentOverrideStats(ctx context.Context, assignmentID string) (*models.OverrideStats, error) {
+	stats, err := s.overrideRepo.GetAssignmentStats(ctx, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get override stats: %w", err)
+	}
+
+	if stats.FlaggedReports > 0 {
+		stats.FalsePositiveRate = float64(stats.FalsePositives) / float64(stats.FlaggedReports)
+	}
+	if stats.UnflaggedReports > 0 {
+		stats.FalseNegativeRate = float64(stats.FalseNegatives) / float64(stats.UnflaggedReports)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/pdf"
+)
+
+const (
+	portfolioBatchSize  = 50
+	portfolioMaxMatches = 10
+	portfolioMaxSnippet = 300
+)
+
+// PortfolioOptions сужает портфолио до одного задания и/или периода по дате создания отчёта
+type PortfolioOptions struct {
+	AssignmentID string
+	From         *time.Time
+	To           *time.Time
+}
+
+// WriteStudentPortfolio пишет в w PDF со всеми помеченными отчётами студента.
+// Отчёты читаются из БД пачками и сразу выводятся, весь документ в памяти не собирается.
+// Если отчётов нет, возвращает ошибку до записи первого байта.
+func (s *reportService) WriteStudentPortfolio(ctx context.Context, studentID string, opts PortfolioOptions, w io.Writer) error {
+	filters := map[string]interface{}{
+		"student_id":      studentID,
+		"plagiarism_flag": true,
+		"status":          models.ReportStatusCompleted.String(),
+	}
+	if opts.AssignmentID != "" {
+		filters["assignment_id"] = opts.AssignmentID
+	}
+	if opts.From != nil {
+		filters["date_from"] = *opts.From
+	}
+	if opts.To != nil {
+		filters["date_to"] = *opts.To
+	}
+
+	reports, total, err := s.reportRepo.Search(ctx, filters, portfolioBatchSize, 0)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrReportSearchFailed, err)
+	}
+	if total == 0 {
+		return ErrNoFlaggedReports
+	}
+
+	doc, err := pdf.NewDocument(w)
+	if err != nil {
+		return err
+	}
+
+	doc.Text("Plagiarism portfolio", 18, true)
+	doc.Text(fmt.Sprintf("Student: %s", studentID), 11, false)
+	if opts.AssignmentID != "" {
+		doc.Text(fmt.Sprintf("Assignment: %s", opts.AssignmentID), 11, false)
+	}
+	if opts.From != nil || opts.To != nil {
+		doc.Text(fmt.Sprintf("Period: %s - %s", formatPortfolioDate(opts.From), formatPortfolioDate(opts.To)), 11, false)
+	}
+	doc.Text(fmt.Sprintf("Flagged reports: %d", total), 11, false)
+	doc.Text(fmt.Sprintf("Generated at: %s", time.Now().UTC().Format(time.RFC3339)), 11, false)
+
+	for offset := 0; ; {
+		for i := range reports {
+			doc.NewPage()
+			writePortfolioReport(doc, &reports[i])
+		}
+
+		offset += len(reports)
+		if len(reports) < portfolioBatchSize || offset >= total {
+			break
+		}
+
+		reports, _, err = s.reportRepo.Search(ctx, filters, portfolioBatchSize, offset)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrReportSearchFailed, err)
+		}
+	}
+
+	if err := doc.Close(); err != nil {
+		return fmt.Errorf("failed to write portfolio pdf: %w", err)
+	}
+	return nil
+}
+
+func writePortfolioReport(doc *pdf.Document, report *models.Report) {
+	doc.Text(fmt.Sprintf("Report %s", report.ID), 14, true)
+	doc.Text(fmt.Sprintf("Work: %s", report.WorkID), 10, false)
+	doc.Text(fmt.Sprintf("Assignment: %s", report.AssignmentID), 10, false)
+	doc.Text(fmt.Sprintf("Match: %d%%", report.MatchPercentage), 10, false)
+	if report.OriginalWorkID != nil {
+		doc.Text(fmt.Sprintf("Original work: %s", *report.OriginalWorkID), 10, false)
+	}
+	doc.Text(fmt.Sprintf("Created: %s", report.CreatedAt.UTC().Format(time.RFC3339)), 10, false)
+	if report.CompletedAt != nil {
+		doc.Text(fmt.Sprintf("Completed: %s", report.CompletedAt.UTC().Format(time.RFC3339)), 10, false)
+	}
+
+	var details models.ReportDetails
+	if len(report.Details) > 0 {
+		if err := json.Unmarshal(report.Details, &details); err != nil {
+			doc.Space(6)
+			doc.Text("Match details are unavailable", 10, false)
+			return
+		}
+	}
+
+	if details.AnalysisMetadata.AlgorithmUsed != "" {
+		doc.Text(fmt.Sprintf("Method: %s, threshold %d%%", details.AnalysisMetadata.AlgorithmUsed, details.AnalysisMetadata.Threshold), 10, false)
+	}
+
+	matches := details.ComparisonResults
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].MatchPercentage > matches[j].MatchPercentage
+	})
+	if len(matches) > portfolioMaxMatches {
+		matches = matches[:portfolioMaxMatches]
+	}
+
+	doc.Space(6)
+	doc.Text("Matched works", 12, true)
+	if len(matches) == 0 {
+		doc.Text("No comparison details recorded", 10, false)
+		return
+	}
+
+	for _, match := range matches {
+		doc.Space(4)
+		doc.Text(fmt.Sprintf("Work %s - %d%%", match.ComparedWorkID, match.MatchPercentage), 10, true)
+		if match.StudentID != "" {
+			doc.Text(fmt.Sprintf("Student: %s", match.StudentID), 9, false)
+		}
+		if match.FileName != "" {
+			doc.Text(fmt.Sprintf("File: %s", match.FileName), 9, false)
+		}
+		for _, section := range match.MatchedSections {
+			text := section.Text
+			if len(text) > portfolioMaxSnippet {
+				text = text[:portfolioMaxSnippet] + "..."
+			}
+			doc.Text(fmt.Sprintf("[%.0f%%] %s", section.Similarity*100, text), 9, false)
+		}
+	}
+}
+
+func formatPortfolioDate(t *time.Time) string {
+	if t == nil {
+		return "..."
+	}
+	return t.UTC().Format("2006-01-02")
+}
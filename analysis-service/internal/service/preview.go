@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/analyzer"
+)
+
+// ErrPreviewDisabled — пробная проверка выключена (analysis.preview.enabled)
+var ErrPreviewDisabled = errors.New("preview analysis is disabled")
+
+// PreviewAnalysis сравнивает файл с работами задания и возвращает результат, не создавая ни работу,
+// ни отчёт: не публикуются события, не отправляются уведомления и вебхуки, не меняются статистика
+// задания и метрики проверок
+func (s *analysisService) PreviewAnalysis(ctx context.Context, file analyzer.PreviewFile, assignmentID, studentID string) (*models.PreviewResult, error) {
+	if !s.config.PreviewEnabled {
+		return nil, ErrPreviewDisabled
+	}
+
+	threshold := s.resolveThreshold(ctx, assignmentID)
+
+	result, err := s.plagiarismChecker.CheckPreview(ctx, file, assignmentID, studentID, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAnalysisFailed, err)
+	}
+
+	s.logger.Info().
+		Str("assignment_id", assignmentID).
+		Str("file_hash", result.FileHash).
+		Int("match_percentage", result.MatchPercentage).
+		Int("compared_with", result.ComparedWithCount).
+		Msg("Preview analysis completed")
+
+	return &models.PreviewResult{
+		AssignmentID:      assignmentID,
+		FileHash:          result.FileHash,
+		PlagiarismFlag:    result.PlagiarismFlag,
+		OriginalWorkID:    result.OriginalWorkID,
+		MatchPercentage:   result.MatchPercentage,
+		Threshold:         threshold,
+		ComparedWithCount: result.ComparedWithCount,
+		SimilarWorks:      result.SimilarWorks,
+		ProcessingTimeMs:  result.ProcessingTimeMs,
+		Details:           json.RawMessage(result.Details),
+	}, nil
+}
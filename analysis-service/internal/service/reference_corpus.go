@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/analyzer"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// ReferenceCorpusService ведёт базу сравнения из документов известных источников
+type ReferenceCorpusService interface {
+	// BulkImport индексирует уже загруженные в file-service файлы: хеш, размер и отпечатки winnowing.
+	// Ошибка одного документа не прерывает импорт остальных и возвращается в его результате.
+	BulkImport(ctx context.Context, req models.BulkImportReferencesRequest) (*models.BulkImportReferencesResult, error)
+	List(ctx context.Context, source, category string, page, limit int) ([]models.ReferenceDocument, int, error)
+}
+
+type ReferenceCorpusConfig struct {
+	Enabled bool
+	// Документов в одном запросе массового импорта
+	MaxBulkDocuments int
+}
+
+type referenceCorpusService struct {
+	referenceRepo     repository.ReferenceRepository
+	fileClient        integration.FileClient
+	plagiarismChecker analyzer.PlagiarismChecker
+	logger            zerolog.Logger
+	config            ReferenceCorpusConfig
+}
+
+func NewReferenceCorpusService(
+	referenceRepo repository.ReferenceRepository,
+	fileClient integration.FileClient,
+	plagiarismChecker analyzer.PlagiarismChecker,
+	logger zerolog.Logger,
+	config ReferenceCorpusConfig,
+) ReferenceCorpusService {
+	return &referenceCorpusService{
+		referenceRepo:     referenceRepo,
+		fileClient:        fileClient,
+		plagiarismChecker: plagiarismChecker,
+		logger:            logger,
+		config:            config,
+	}
+}
+
+func (s *referenceCorpusService) BulkImport(ctx context.Context, req models.BulkImportReferencesRequest) (*models.BulkImportReferencesResult, error) {
+	if !s.config.Enabled {
+		return nil, ErrReferenceCorpusDisabled
+	}
+	if len(req.Documents) == 0 {
+		return nil, ErrNoReferenceDocuments
+	}
+	if s.config.MaxBulkDocuments > 0 && len(req.Documents) > s.config.MaxBulkDocuments {
+		return nil, ErrTooManyReferenceDocuments
+	}
+
+	result := &models.BulkImportReferencesResult{
+		Total: len(req.Documents),
+		Items: make([]models.ReferenceImportItem, 0, len(req.Documents)),
+	}
+
+	for i, doc := range req.Documents {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		item := s.importDocument(ctx, req, doc)
+		item.Index = i
+
+		switch item.Status {
+		case models.ReferenceImportIndexed:
+			result.Indexed++
+		case models.ReferenceImportDuplicate:
+			result.Duplicates++
+		default:
+			result.Failed++
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	s.logger.Info().
+		Int("total", result.Total).
+		Int("indexed", result.Indexed).
+		Int("duplicates", result.Duplicates).
+		Int("failed", result.Failed).
+		Msg("Reference documents imported")
+
+	return result, nil
+}
+
+func (s *referenceCorpusService) importDocument(ctx context.Context, req models.BulkImportReferencesRequest, doc models.ReferenceDocumentRequest) models.ReferenceImportItem {
+	item := models.ReferenceImportItem{FileID: doc.FileID}
+	fail := func(err error) models.ReferenceImportItem {
+		s.logger.Warn().Err(err).Str("file_id", doc.FileID).Msg("Failed to import reference document")
+		item.Status = models.ReferenceImportFailed
+		item.Error = err.Error()
+		return item
+	}
+
+	contentType := doc.ContentType
+	if contentType == "" {
+		contentType = analyzer.ContentTypeText
+	}
+
+	fileHash, fileSize, err := s.fileClient.GetFileHash(ctx, doc.FileID)
+	if err != nil {
+		return fail(fmt.Errorf("failed to get file hash: %w", err))
+	}
+	item.FileHash = fileHash
+
+	existing, err := s.referenceRepo.GetByFileHash(ctx, fileHash, contentType)
+	if err != nil {
+		return fail(fmt.Errorf("failed to check existing reference: %w", err))
+	}
+	if existing != nil {
+		item.Status = models.ReferenceImportDuplicate
+		item.ReferenceID = existing.ID
+		item.FingerprintCount = existing.FingerprintCount
+		return item
+	}
+
+	document := &models.ReferenceDocument{
+		ID:          uuid.New().String(),
+		FileID:      doc.FileID,
+		FileHash:    fileHash,
+		FileSize:    fileSize,
+		Title:       strings.TrimSpace(doc.Title),
+		Source:      firstNonEmpty(doc.Source, req.Source),
+		Category:    firstNonEmpty(doc.Category, req.Category),
+		ContentType: contentType,
+		CreatedAt:   time.Now(),
+	}
+
+	document.FingerprintCount, err = s.plagiarismChecker.IndexReference(ctx, document.ID, doc.FileID, fileHash, contentType)
+	if err != nil {
+		return fail(fmt.Errorf("failed to index reference: %w", err))
+	}
+
+	if err := s.referenceRepo.Create(ctx, document); err != nil {
+		return fail(fmt.Errorf("failed to save reference: %w", err))
+	}
+
+	item.Status = models.ReferenceImportIndexed
+	item.ReferenceID = document.ID
+	item.FingerprintCount = document.FingerprintCount
+	return item
+}
+
+func (s *referenceCorpusService) List(ctx context.Context, source, category string, page, limit int) ([]models.ReferenceDocument, int, error) {
+	if !s.config.Enabled {
+		return nil, 0, ErrReferenceCorpusDisabled
+	}
+
+	documents, total, err := s.referenceRepo.List(ctx, source, category, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list references: %w", err)
+	}
+	return documents, total, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
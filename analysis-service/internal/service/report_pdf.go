@@ -0,0 +1,46 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/pdf"
+)
+
+// exportPDF рендерит один отчёт: PDF делается для преподавателя по конкретной работе,
+// поэтому выборка из нескольких отчётов считается ошибкой запроса
+func (s *reportService) exportPDF(reports []models.Report) ([]byte, error) {
+	if len(reports) == 0 {
+		return nil, ErrReportIDNotFound
+	}
+	if len(reports) > 1 {
+		return nil, ErrPDFRequiresSingleReport
+	}
+
+	var buf bytes.Buffer
+	doc, err := pdf.NewDocument(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &reports[0]
+	doc.Text("Plagiarism report", 18, true)
+	doc.Text(fmt.Sprintf("Student: %s", report.StudentID), 11, false)
+	doc.Text(fmt.Sprintf("Status: %s", report.Status), 11, false)
+	verdict := "no plagiarism detected"
+	if report.PlagiarismFlag {
+		verdict = "plagiarism detected"
+	}
+	doc.Text(fmt.Sprintf("Verdict: %s", verdict), 11, true)
+	doc.Text(fmt.Sprintf("Generated at: %s", time.Now().UTC().Format(time.RFC3339)), 11, false)
+	doc.Space(10)
+
+	writePortfolioReport(doc, report)
+
+	if err := doc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write report pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+func newTestReportService(reports ...*models.Report) ReportService {
+	repo := newFakeReportRepo()
+	for _, report := range reports {
+		repo.reports[report.WorkID] = report
+	}
+	return NewReportService(repo, nil, nil, nil, zerolog.Nop(), ExportConfig{})
+}
+
+func TestExportPDF(t *testing.T) {
+	details, _ := json.Marshal(models.ReportDetails{ComparisonResults: []models.ComparisonResult{{
+		ComparedWorkID:  "work-original",
+		StudentID:       "alice",
+		MatchPercentage: 87,
+		MatchedSections: []models.MatchedSection{{Text: "copied paragraph about sorting", Similarity: 0.9}},
+	}}})
+	s := newTestReportService(
+		&models.Report{ID: "report-1", WorkID: "work-42", StudentID: "bob", Status: "completed", PlagiarismFlag: true, MatchPercentage: 87, Details: details},
+		&models.Report{ID: "report-2", WorkID: "work-43", StudentID: "carol", Status: "completed"},
+	)
+
+	tests := []struct {
+		name    string
+		filters map[string]interface{}
+		want    []string
+	}{
+		{
+			name:    "with matched sections",
+			filters: map[string]interface{}{"work_id": "work-42"},
+			want:    []string{"Work: work-42", "Work work-original - 87%", "copied paragraph about sorting"},
+		},
+		{
+			name:    "without comparison results",
+			filters: map[string]interface{}{"report_id": "report-2"},
+			want:    []string{"Work: work-43", "No comparison details recorded"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := s.ExportReports(context.Background(), tt.filters, "pdf")
+			if err != nil {
+				t.Fatalf("export: %v", err)
+			}
+			if !bytes.HasPrefix(data, []byte("%PDF-")) || !bytes.HasSuffix(bytes.TrimSpace(data), []byte("%%EOF")) {
+				t.Fatalf("export is not a complete PDF: %d bytes", len(data))
+			}
+			// Потоки страниц не сжимаются, поэтому текст виден в файле как есть
+			for _, text := range tt.want {
+				if !bytes.Contains(data, []byte(text)) {
+					t.Errorf("PDF does not contain %q", text)
+				}
+			}
+		})
+	}
+}
+
+func TestExportPDFRequiresSingleReport(t *testing.T) {
+	s := newTestReportService(
+		&models.Report{ID: "report-1", WorkID: "work-42"},
+		&models.Report{ID: "report-2", WorkID: "work-43"},
+	)
+
+	if _, err := s.ExportReports(context.Background(), map[string]interface{}{}, "pdf"); !errors.Is(err, ErrPDFRequiresSingleReport) {
+		t.Fatalf("two reports: err = %v, want ErrPDFRequiresSingleReport", err)
+	}
+	if _, err := s.ExportReports(context.Background(), map[string]interface{}{"work_id": "missing"}, "pdf"); !errors.Is(err, ErrReportIDNotFound) {
+		t.Fatalf("no reports: err = %v, want ErrReportIDNotFound", err)
+	}
+}
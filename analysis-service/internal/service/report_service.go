@@ -1,42 +1,87 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/xlsx"
 	"github.com/rs/zerolog"
 )
 
 type ReportService interface {
 	GetReport(ctx context.Context, reportID string) (*models.GetReportResponse, error)
 	GetReportByWorkID(ctx context.Context, workID string) (*models.GetReportResponse, error)
+	// GetReportRawDetails — колонка details отчёта как есть, без разбора в GetReportResponse
+	GetReportRawDetails(ctx context.Context, reportID string) ([]byte, error)
+	GetComparisonMatches(ctx context.Context, workID string, page, limit int) (*models.ComparisonMatchesPage, error)
+	GetWorkPercentile(ctx context.Context, workID string) (*models.WorkPercentileResponse, error)
+	GetWorkCoverage(ctx context.Context, workID string) (*models.WorkCoverageResponse, error)
 	SearchReports(ctx context.Context, filters models.SearchReportsRequest) (*models.SearchReportsResponse, error)
 	GetAssignmentStats(ctx context.Context, assignmentID string) (*models.GetAssignmentStatsResponse, error)
+	RefreshAssignmentStats(ctx context.Context, assignmentID string) (*models.AssignmentStats, error)
 	GetStudentStats(ctx context.Context, studentID string) (*models.GetStudentStatsResponse, error)
+	DeleteStudentReports(ctx context.Context, studentID string) (int, error)
+	// SupersedeReport отмечает отчёт работы заменённым новой попыткой supersededBy; вердикт не меняется
+	SupersedeReport(ctx context.Context, workID, supersededBy string) error
 	GetAllStats(ctx context.Context) (*models.AnalysisStats, error)
+	GetThroughput(ctx context.Context, bucket string, since time.Time) (*models.ThroughputResponse, error)
 	ExportReports(ctx context.Context, filters map[string]interface{}, format string) ([]byte, error)
+	ExportAssignmentRoster(ctx context.Context, assignmentID string) ([]byte, error)
+	ShouldExportAsync(ctx context.Context, filters map[string]interface{}) (bool, error)
+	ExportReportsAsync(filters map[string]interface{}, format string) (*models.ExportJob, error)
+	GetExportJob(jobID string) (*models.ExportJob, []byte, error)
+	WriteStudentPortfolio(ctx context.Context, studentID string, opts PortfolioOptions, w io.Writer) error
 }
 
 type reportService struct {
 	reportRepo     repository.ReportRepository
 	plagiarismRepo repository.PlagiarismRepository
+	queueRepo      repository.AnalysisQueueRepository
+	overrideRepo   repository.OverrideRepository
+	exportJobs     *exportJobStore
 	logger         zerolog.Logger
+	config         ExportConfig
+}
+
+type ExportConfig struct {
+	AsyncThreshold int
+	JobTTL         time.Duration
+	Timeout        time.Duration
+	// Порог выделения строк в ведомости задания, если у задания нет своего
+	DefaultThreshold int
+	// Ведомость задания: не больше RosterMaxRows отчётов, ссылки на отчёты от RosterLinkBaseURL
+	RosterMaxRows     int
+	RosterLinkBaseURL string
+	// Охват сравнения, %, ниже которого чистый вердикт считается неубедительным
+	MinCoverage int
 }
 
 func NewReportService(
 	reportRepo repository.ReportRepository,
 	plagiarismRepo repository.PlagiarismRepository,
+	queueRepo repository.AnalysisQueueRepository,
+	overrideRepo repository.OverrideRepository,
 	logger zerolog.Logger,
+	config ExportConfig,
 ) ReportService {
 	return &reportService{
 		reportRepo:     reportRepo,
 		plagiarismRepo: plagiarismRepo,
+		queueRepo:      queueRepo,
+		overrideRepo:   overrideRepo,
+		exportJobs:     newExportJobStore(config.JobTTL),
 		logger:         logger,
+		config:         config,
 	}
 }
 
@@ -47,10 +92,25 @@ func (s *reportService) GetReport(ctx context.Context, reportID string) (*models
 	}
 
 	if report == nil {
-		return nil, errors.New("report not found")
+		return nil, ErrReportIDNotFound
+	}
+
+	response := s.convertToResponse(report)
+	s.fillRetryAttempts(ctx, response)
+	return response, nil
+}
+
+func (s *reportService) GetReportRawDetails(ctx context.Context, reportID string) ([]byte, error) {
+	report, err := s.reportRepo.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report: %w", err)
+	}
+
+	if report == nil {
+		return nil, ErrReportIDNotFound
 	}
 
-	return s.convertToResponse(report), nil
+	return report.Details, nil
 }
 
 func (s *reportService) GetReportByWorkID(ctx context.Context, workID string) (*models.GetReportResponse, error) {
@@ -60,10 +120,137 @@ func (s *reportService) GetReportByWorkID(ctx context.Context, workID string) (*
 	}
 
 	if report == nil {
-		return nil, errors.New("report not found for this work")
+		return nil, ErrReportNotFound
+	}
+
+	response := s.convertToResponse(report)
+	s.fillRetryAttempts(ctx, response)
+	return response, nil
+}
+
+// fillRetryAttempts добавляет к отчёту число неудачных попыток из очереди повторов.
+// Сбой чтения очереди не мешает отдать сам отчёт
+func (s *reportService) fillRetryAttempts(ctx context.Context, response *models.GetReportResponse) {
+	item, err := s.queueRepo.GetByWorkID(ctx, response.WorkID)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("work_id", response.WorkID).Msg("Failed to get retry queue item")
+		return
+	}
+	if item == nil {
+		return
+	}
+
+	response.Attempts = item.Attempts
+	if item.Status == models.QueueStatusPending {
+		response.NextRetryAt = item.ScheduledAt
+	}
+}
+
+func (s *reportService) GetComparisonMatches(ctx context.Context, workID string, page, limit int) (*models.ComparisonMatchesPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 1
+	}
+
+	items, total, err := s.plagiarismRepo.GetComparisonResultsPage(ctx, workID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comparison results: %w", err)
+	}
+
+	totalPages := (total + limit - 1) / limit
+
+	return &models.ComparisonMatchesPage{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetWorkPercentile сравнивает процент совпадения работы с другими завершёнными работами задания
+func (s *reportService) GetWorkPercentile(ctx context.Context, workID string) (*models.WorkPercentileResponse, error) {
+	report, err := s.reportRepo.GetByWorkID(ctx, workID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report by work ID: %w", err)
+	}
+	if report == nil {
+		return nil, ErrReportIDNotFound
+	}
+	if report.Status != models.ReportStatusCompleted.String() {
+		return nil, ErrReportNotCompleted
+	}
+
+	below, total, err := s.reportRepo.CountBelowMatch(ctx, report.AssignmentID, report.MatchPercentage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count assignment reports: %w", err)
+	}
+
+	response := &models.WorkPercentileResponse{
+		WorkID:          report.WorkID,
+		AssignmentID:    report.AssignmentID,
+		MatchPercentage: report.MatchPercentage,
+		LowerCount:      below,
+		PeerCount:       total - 1,
+	}
+	if response.PeerCount > 0 {
+		response.Percentile = math.Round(float64(below)/float64(response.PeerCount)*1000) / 10
+	}
+
+	return response, nil
+}
+
+// GetWorkCoverage считает по метаданным отчёта, сколько работ задания сравнено, а сколько пропущено и почему
+func (s *reportService) GetWorkCoverage(ctx context.Context, workID string) (*models.WorkCoverageResponse, error) {
+	report, err := s.reportRepo.GetByWorkID(ctx, workID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report by work ID: %w", err)
+	}
+	if report == nil {
+		return nil, ErrReportIDNotFound
+	}
+	if report.Status != models.ReportStatusCompleted.String() {
+		return nil, ErrReportNotCompleted
 	}
 
-	return s.convertToResponse(report), nil
+	var details models.ReportDetails
+	if len(report.Details) > 0 {
+		if err := json.Unmarshal(report.Details, &details); err != nil {
+			return nil, fmt.Errorf("failed to parse report details: %w", err)
+		}
+	}
+	metadata := details.AnalysisMetadata
+
+	response := &models.WorkCoverageResponse{
+		WorkID:         report.WorkID,
+		AssignmentID:   report.AssignmentID,
+		PlagiarismFlag: report.PlagiarismFlag,
+		EligibleWorks:  report.ComparedFilesCount,
+		Skipped: models.CoverageSkipped{
+			MissingHash:  metadata.SkippedMissingHash,
+			SizeMismatch: metadata.HashSkippedBySize + metadata.ContentSkippedBySize,
+			CompareError: metadata.SkippedCompareError,
+		},
+		HashFallbackPairs: metadata.HashFallbackPairs,
+		MinCoverage:       s.config.MinCoverage,
+		CoverageRecorded:  metadata.CoverageRecorded,
+	}
+	response.SkippedWorks = response.Skipped.MissingHash + response.Skipped.SizeMismatch + response.Skipped.CompareError
+	response.ComparedWorks = response.EligibleWorks - response.SkippedWorks
+	if response.ComparedWorks < 0 {
+		response.ComparedWorks = 0
+	}
+
+	// Сравнивать было не с чем — охват полный
+	response.CoveragePercentage = 100
+	if response.EligibleWorks > 0 {
+		response.CoveragePercentage = math.Round(float64(response.ComparedWorks)/float64(response.EligibleWorks)*1000) / 10
+	}
+	response.Inconclusive = !report.PlagiarismFlag && response.CoveragePercentage < float64(s.config.MinCoverage)
+
+	return response, nil
 }
 
 func (s *reportService) SearchReports(ctx context.Context, filters models.SearchReportsRequest) (*models.SearchReportsResponse, error) {
@@ -89,6 +276,10 @@ func (s *reportService) SearchReports(ctx context.Context, filters models.Search
 		repoFilters["plagiarism_flag"] = *filters.PlagiarismFlag
 	}
 
+	if filters.AnalysisVersion != nil && *filters.AnalysisVersion != "" {
+		repoFilters["analysis_version"] = *filters.AnalysisVersion
+	}
+
 	if filters.DateFrom != nil && *filters.DateFrom != "" {
 		if date, err := time.Parse(time.RFC3339, *filters.DateFrom); err == nil {
 			repoFilters["date_from"] = date
@@ -101,16 +292,15 @@ func (s *reportService) SearchReports(ctx context.Context, filters models.Search
 		}
 	}
 
+	if filters.Cursor != nil {
+		return s.searchReportsAfter(ctx, repoFilters, *filters.Cursor, filters.Limit)
+	}
+
 	offset := (filters.Page - 1) * filters.Limit
 
 	reports, total, err := s.reportRepo.Search(ctx, repoFilters, filters.Limit, offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search reports: %w", err)
-	}
-
-	responseReports := make([]models.GetReportResponse, 0, len(reports))
-	for _, report := range reports {
-		responseReports = append(responseReports, *s.convertToResponse(&report))
+		return nil, fmt.Errorf("%w: %w", ErrReportSearchFailed, err)
 	}
 
 	totalPages := total / filters.Limit
@@ -118,15 +308,47 @@ func (s *reportService) SearchReports(ctx context.Context, filters models.Search
 		totalPages++
 	}
 
-	return &models.SearchReportsResponse{
-		Reports:    responseReports,
+	response := &models.SearchReportsResponse{
+		Reports:    s.convertToResponses(reports),
 		Total:      total,
 		Page:       filters.Page,
 		Limit:      filters.Limit,
 		TotalPages: totalPages,
+	}
+	// С любой страницы можно перейти на обход по курсору
+	if len(reports) > 0 && offset+len(reports) < total {
+		response.NextCursor = repository.ReportCursor(reports[len(reports)-1])
+	}
+
+	return response, nil
+}
+
+// searchReportsAfter обходит результаты по курсору: без COUNT и OFFSET, и вставка новых
+// отчётов во время обхода не приводит к пропускам и повторам
+func (s *reportService) searchReportsAfter(ctx context.Context, repoFilters map[string]interface{}, cursor string, limit int) (*models.SearchReportsResponse, error) {
+	reports, nextCursor, err := s.reportRepo.SearchAfter(ctx, repoFilters, cursor, limit)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrReportSearchFailed, err)
+	}
+
+	return &models.SearchReportsResponse{
+		Reports:    s.convertToResponses(reports),
+		Limit:      limit,
+		NextCursor: nextCursor,
 	}, nil
 }
 
+func (s *reportService) convertToResponses(reports []models.Report) []models.GetReportResponse {
+	responses := make([]models.GetReportResponse, 0, len(reports))
+	for i := range reports {
+		responses = append(responses, *s.convertToResponse(&reports[i]))
+	}
+	return responses
+}
+
 func (s *reportService) GetAssignmentStats(ctx context.Context, assignmentID string) (*models.GetAssignmentStatsResponse, error) {
 	stats, err := s.reportRepo.GetAssignmentStats(ctx, assignmentID)
 	if err != nil {
@@ -134,7 +356,7 @@ func (s *reportService) GetAssignmentStats(ctx context.Context, assignmentID str
 	}
 
 	if stats == nil {
-		return nil, errors.New("assignment not found or no reports available")
+		return nil, ErrAssignmentReportsNotFound
 	}
 
 	reports, _, err := s.reportRepo.GetByAssignmentID(ctx, assignmentID, 10, 0)
@@ -172,6 +394,24 @@ func (s *reportService) GetAssignmentStats(ctx context.Context, assignmentID str
 	}, nil
 }
 
+func (s *reportService) RefreshAssignmentStats(ctx context.Context, assignmentID string) (*models.AssignmentStats, error) {
+	stats, err := s.reportRepo.RefreshAssignmentStats(ctx, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh assignment stats: %w", err)
+	}
+
+	if stats == nil {
+		return nil, ErrAssignmentReportsNotFound
+	}
+
+	s.logger.Info().
+		Str("assignment_id", assignmentID).
+		Int("total_works", stats.TotalWorks).
+		Msg("Assignment stats refreshed")
+
+	return stats, nil
+}
+
 func (s *reportService) GetStudentStats(ctx context.Context, studentID string) (*models.GetStudentStatsResponse, error) {
 	stats, err := s.reportRepo.GetStudentStats(ctx, studentID)
 	if err != nil {
@@ -179,7 +419,7 @@ func (s *reportService) GetStudentStats(ctx context.Context, studentID string) (
 	}
 
 	if stats == nil {
-		return nil, errors.New("student not found or no reports available")
+		return nil, ErrStudentReportsNotFound
 	}
 
 	reports, _, err := s.reportRepo.GetByStudentID(ctx, studentID, 10, 0)
@@ -192,7 +432,7 @@ func (s *reportService) GetStudentStats(ctx context.Context, studentID string) (
 		responseReports = append(responseReports, *s.convertToResponse(&report))
 	}
 
-	var comparisonHistory []models.ComparisonResult
+	comparisonHistory := []models.ComparisonResult{}
 	for _, report := range reports {
 		history, err := s.plagiarismRepo.GetComparisonHistory(ctx, report.WorkID)
 		if err != nil {
@@ -226,6 +466,32 @@ func (s *reportService) GetAllStats(ctx context.Context) (*models.AnalysisStats,
 	return s.reportRepo.GetStats(ctx)
 }
 
+func (s *reportService) GetThroughput(ctx context.Context, bucket string, since time.Time) (*models.ThroughputResponse, error) {
+	switch bucket {
+	case "hour", "day", "week":
+	default:
+		return nil, fmt.Errorf("invalid bucket: %s", bucket)
+	}
+
+	buckets, err := s.reportRepo.GetThroughput(ctx, bucket, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get throughput: %w", err)
+	}
+
+	response := &models.ThroughputResponse{
+		Bucket:  bucket,
+		Since:   since,
+		Buckets: make([]models.ThroughputBucket, 0, len(buckets)),
+	}
+	for _, b := range buckets {
+		response.TotalCompleted += b.Completed
+		response.TotalFailed += b.Failed
+		response.Buckets = append(response.Buckets, b)
+	}
+
+	return response, nil
+}
+
 func (s *reportService) ExportReports(ctx context.Context, filters map[string]interface{}, format string) ([]byte, error) {
 	reports, _, err := s.reportRepo.Search(ctx, filters, 1000, 0)
 	if err != nil {
@@ -237,11 +503,74 @@ func (s *reportService) ExportReports(ctx context.Context, filters map[string]in
 		return s.exportJSON(reports)
 	case "csv":
 		return s.exportCSV(reports)
+	case "pdf":
+		return s.exportPDF(reports)
+	case "xlsx":
+		stats, err := s.reportRepo.GetStats(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stats for export: %w", err)
+		}
+		return s.exportXLSX(reports, stats.TopAssignments)
 	default:
 		return nil, fmt.Errorf("unsupported export format: %s", format)
 	}
 }
 
+func (s *reportService) ShouldExportAsync(ctx context.Context, filters map[string]interface{}) (bool, error) {
+	if s.config.AsyncThreshold <= 0 {
+		return false, nil
+	}
+
+	_, total, err := s.reportRepo.Search(ctx, filters, 1, 0)
+	if err != nil {
+		return false, fmt.Errorf("failed to count reports for export: %w", err)
+	}
+
+	return total > s.config.AsyncThreshold, nil
+}
+
+func (s *reportService) ExportReportsAsync(filters map[string]interface{}, format string) (*models.ExportJob, error) {
+	if format != "json" && format != "csv" && format != "xlsx" {
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	job := models.ExportJob{
+		JobID:     uuid.New().String(),
+		Status:    models.ExportJobStatusPending,
+		Format:    format,
+		CreatedAt: time.Now(),
+	}
+	s.exportJobs.add(job)
+
+	go func() {
+		ctx := context.Background()
+		if s.config.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.config.Timeout)
+			defer cancel()
+		}
+
+		data, err := s.ExportReports(ctx, filters, format)
+		if err != nil {
+			s.logger.Error().Err(err).Str("job_id", job.JobID).Msg("Async export failed")
+		} else {
+			s.logger.Info().Str("job_id", job.JobID).Int("bytes", len(data)).Msg("Async export completed")
+		}
+		s.exportJobs.complete(job.JobID, data, err)
+	}()
+
+	return &job, nil
+}
+
+func (s *reportService) GetExportJob(jobID string) (*models.ExportJob, []byte, error) {
+	job, data, ok := s.exportJobs.get(jobID)
+	if !ok {
+		return nil, nil, ErrExportJobNotFound
+	}
+
+	return job, data, nil
+}
+
 func (s *reportService) exportJSON(reports []models.Report) ([]byte, error) {
 	responseReports := make([]models.GetReportResponse, 0, len(reports))
 	for _, report := range reports {
@@ -251,6 +580,64 @@ func (s *reportService) exportJSON(reports []models.Report) ([]byte, error) {
 	return json.MarshalIndent(responseReports, "", "  ")
 }
 
+// exportXLSX пишет книгу из двух листов: отчёты с теми же столбцами, что и в CSV, и сводку по заданиям
+func (s *reportService) exportXLSX(reports []models.Report, assignments []models.AssignmentStats) ([]byte, error) {
+	reportRows := make([][]interface{}, 0, len(reports)+1)
+	reportRows = append(reportRows, []interface{}{
+		"Report ID", "Work ID", "Assignment ID", "Student ID", "Status", "Plagiarism", "Match %",
+		"Processing Time (ms)", "Compared Files", "Created At", "Completed At",
+	})
+	for _, report := range reports {
+		completedAt := ""
+		if report.CompletedAt != nil {
+			completedAt = report.CompletedAt.Format(time.RFC3339)
+		}
+		processingTime := 0
+		if report.ProcessingTimeMs != nil {
+			processingTime = *report.ProcessingTimeMs
+		}
+
+		reportRows = append(reportRows, []interface{}{
+			report.ID,
+			report.WorkID,
+			report.AssignmentID,
+			report.StudentID,
+			report.Status,
+			report.PlagiarismFlag,
+			report.MatchPercentage,
+			processingTime,
+			report.ComparedFilesCount,
+			report.CreatedAt.Format(time.RFC3339),
+			completedAt,
+		})
+	}
+
+	statRows := make([][]interface{}, 0, len(assignments)+1)
+	statRows = append(statRows, []interface{}{
+		"Assignment ID", "Total Works", "Analyzed Works", "Plagiarized Works", "Avg Match %",
+	})
+	for _, stat := range assignments {
+		statRows = append(statRows, []interface{}{
+			stat.AssignmentID,
+			stat.TotalWorks,
+			stat.AnalyzedWorks,
+			stat.PlagiarizedWorks,
+			stat.AvgMatchPercentage,
+		})
+	}
+
+	var buf bytes.Buffer
+	header := map[int]xlsx.Style{0: xlsx.StyleHeader}
+	err := xlsx.Write(&buf,
+		xlsx.Sheet{Name: "Reports", Rows: reportRows, RowStyles: header},
+		xlsx.Sheet{Name: "Assignments", Rows: statRows, RowStyles: header},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write xlsx: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
 func (s *reportService) exportCSV(reports []models.Report) ([]byte, error) {
 	csvData := "Report ID,Work ID,Assignment ID,Student ID,Status,Plagiarism,Match %,Processing Time (ms),Compared Files,Created At,Completed At\n"
 
@@ -305,6 +692,9 @@ func (s *reportService) convertToResponse(report *models.Report) *models.GetRepo
 		var details map[string]interface{}
 		if err := json.Unmarshal(report.Details, &details); err == nil {
 			response.Details = details
+			if metadata, ok := details["analysis_metadata"].(map[string]interface{}); ok {
+				response.AnalysisVersion, _ = metadata["analysis_version"].(string)
+			}
 		}
 	}
 
@@ -315,3 +705,42 @@ func (s *reportService) convertToResponse(report *models.Report) *models.GetRepo
 
 	return response
 }
+
+func (s *reportService) SupersedeReport(ctx context.Context, workID, supersededBy string) error {
+	supersession, err := json.Marshal(models.ReportSupersession{
+		ByWorkID:     supersededBy,
+		SupersededAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal supersession: %w", err)
+	}
+
+	updated, err := s.reportRepo.SetSuperseded(ctx, workID, supersession)
+	if err != nil {
+		return fmt.Errorf("failed to supersede report: %w", err)
+	}
+	if !updated {
+		return ErrReportNotFound
+	}
+
+	s.logger.Info().
+		Str("work_id", workID).
+		Str("superseded_by", supersededBy).
+		Msg("Report superseded by a new attempt")
+
+	return nil
+}
+
+func (s *reportService) DeleteStudentReports(ctx context.Context, studentID string) (int, error) {
+	deleted, err := s.reportRepo.DeleteByStudentID(ctx, studentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete student reports: %w", err)
+	}
+
+	s.logger.Info().
+		Str("student_id", studentID).
+		Int("deleted", deleted).
+		Msg("Student reports deleted")
+
+	return deleted, nil
+}
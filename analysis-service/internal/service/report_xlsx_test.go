@@ -0,0 +1,124 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+// statsReportRepo добавляет к fakeReportRepo сводку GetStats
+type statsReportRepo struct {
+	*fakeReportRepo
+	stats *models.AnalysisStats
+}
+
+func (r *statsReportRepo) GetStats(context.Context) (*models.AnalysisStats, error) {
+	return r.stats, nil
+}
+
+// readXLSXPart разбирает XML-часть книги в v
+func readXLSXPart(t *testing.T, book *zip.Reader, name string, v interface{}) {
+	t.Helper()
+
+	f, err := book.Open(name)
+	if err != nil {
+		t.Fatalf("open %s: %v", name, err)
+	}
+	defer f.Close()
+	data, _ := io.ReadAll(f)
+	if err := xml.Unmarshal(data, v); err != nil {
+		t.Fatalf("parse %s: %v", name, err)
+	}
+}
+
+// sheetRows — значения ячеек листа построчно: текст inline-строк или содержимое <v>
+func sheetRows(t *testing.T, book *zip.Reader, name string) [][]string {
+	t.Helper()
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Inline string `xml:"is>t"`
+				Value  string `xml:"v"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	readXLSXPart(t, book, name, &sheet)
+
+	rows := make([][]string, 0, len(sheet.Rows))
+	for _, row := range sheet.Rows {
+		var cells []string
+		for _, c := range row.Cells {
+			cells = append(cells, c.Inline+c.Value)
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+func TestExportXLSXSheets(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := &statsReportRepo{
+		fakeReportRepo: newFakeReportRepo(),
+		stats: &models.AnalysisStats{TopAssignments: []models.AssignmentStats{
+			{AssignmentID: "assignment-1", TotalWorks: 3, AnalyzedWorks: 2, PlagiarizedWorks: 1, AvgMatchPercentage: 42.5},
+		}},
+	}
+	repo.reports["work-42"] = &models.Report{
+		ID: "report-1", WorkID: "work-42", AssignmentID: "assignment-1", StudentID: "bob",
+		Status: "completed", PlagiarismFlag: true, MatchPercentage: 87, CreatedAt: created,
+	}
+	s := NewReportService(repo, nil, nil, nil, zerolog.Nop(), ExportConfig{})
+
+	data, err := s.ExportReports(context.Background(), map[string]interface{}{}, "xlsx")
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	book, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	readXLSXPart(t, book, "xl/workbook.xml", &workbook)
+	var names []string
+	for _, sheet := range workbook.Sheets {
+		names = append(names, sheet.Name)
+	}
+	if want := []string{"Reports", "Assignments"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("sheets = %v, want %v", names, want)
+	}
+
+	// Столбцы и формат времени совпадают с CSV
+	reports := sheetRows(t, book, "xl/worksheets/sheet1.xml")
+	wantReports := [][]string{
+		{"Report ID", "Work ID", "Assignment ID", "Student ID", "Status", "Plagiarism", "Match %",
+			"Processing Time (ms)", "Compared Files", "Created At", "Completed At"},
+		{"report-1", "work-42", "assignment-1", "bob", "completed", "1", "87", "0", "0", "2024-03-01T12:00:00Z", ""},
+	}
+	if !reflect.DeepEqual(reports, wantReports) {
+		t.Fatalf("Reports sheet = %q, want %q", reports, wantReports)
+	}
+
+	assignments := sheetRows(t, book, "xl/worksheets/sheet2.xml")
+	wantAssignments := [][]string{
+		{"Assignment ID", "Total Works", "Analyzed Works", "Plagiarized Works", "Avg Match %"},
+		{"assignment-1", "3", "2", "1", "42.5"},
+	}
+	if !reflect.DeepEqual(assignments, wantAssignments) {
+		t.Fatalf("Assignments sheet = %q, want %q", assignments, wantAssignments)
+	}
+}
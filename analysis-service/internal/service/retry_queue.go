@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+// retryBackoff возвращает задержку перед повтором после attempts неудач: base * 2^(attempts-1), не больше max
+func retryBackoff(attempts int, base, max time.Duration) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	delay := base
+	for i := 1; i < attempts; i++ {
+		if delay >= max/2 {
+			return max
+		}
+		delay *= 2
+	}
+
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// scheduleRetry ставит упавший анализ в очередь повторов; после max_attempts неудач
+// запись очереди становится failed, а отчёт — abandoned
+func (s *analysisService) scheduleRetry(ctx context.Context, report *models.Report, cause error) {
+	item, err := s.queueRepo.RecordFailure(ctx, &models.AnalysisQueueItem{
+		WorkID:       report.WorkID,
+		FileID:       report.FileID,
+		AssignmentID: report.AssignmentID,
+		StudentID:    report.StudentID,
+		MaxAttempts:  s.config.RetryQueueMaxAttempts,
+		ErrorMessage: cause.Error(),
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Str("work_id", report.WorkID).Msg("Failed to enqueue analysis retry")
+		return
+	}
+
+	if item.Attempts >= item.MaxAttempts {
+		if err := s.queueRepo.MarkFailed(ctx, item.ID); err != nil {
+			s.logger.Error().Err(err).Str("work_id", report.WorkID).Msg("Failed to mark queue item as failed")
+		}
+		if err := s.reportRepo.UpdateStatus(ctx, report.ID, models.ReportStatusAbandoned.String()); err != nil {
+			s.logger.Error().Err(err).Str("report_id", report.ID).Msg("Failed to mark report as abandoned")
+		}
+
+		s.logger.Warn().
+			Str("work_id", report.WorkID).
+			Int("attempts", item.Attempts).
+			Msg("Analysis abandoned after max queued attempts")
+		return
+	}
+
+	scheduledAt := time.Now().Add(retryBackoff(item.Attempts, s.config.RetryQueueBaseDelay, s.config.RetryQueueMaxDelay))
+	if err := s.queueRepo.Schedule(ctx, item.ID, scheduledAt); err != nil {
+		s.logger.Error().Err(err).Str("work_id", report.WorkID).Msg("Failed to schedule analysis retry")
+		return
+	}
+
+	s.logger.Info().
+		Str("work_id", report.WorkID).
+		Int("attempts", item.Attempts).
+		Time("scheduled_at", scheduledAt).
+		Msg("Analysis retry scheduled")
+}
+
+func (s *analysisService) RetryDueAnalyses(ctx context.Context, limit int) (*models.RetryFailedResponse, error) {
+	ctx = WithTriggerSource(ctx, models.TriggerSourceRetry)
+
+	// Запись, застрявшая в processing дольше двух таймаутов анализа, осталась от упавшего экземпляра
+	items, err := s.queueRepo.ClaimDue(ctx, time.Now(), 2*s.config.Timeout, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.RetryFailedResponse{
+		Total:   len(items),
+		Results: make([]models.RetryResult, 0, len(items)),
+	}
+
+	for _, item := range items {
+		result := models.RetryResult{
+			WorkID:  item.WorkID,
+			Status:  "retried",
+			Attempt: item.Attempts + 1,
+		}
+
+		_, err := s.AnalyzeWork(ctx, item.WorkID, item.FileID, item.AssignmentID, item.StudentID)
+		if err == nil {
+			if markErr := s.queueRepo.MarkCompleted(ctx, item.WorkID); markErr != nil {
+				s.logger.Error().Err(markErr).Str("work_id", item.WorkID).Msg("Failed to mark queue item as completed")
+			}
+			response.Retried++
+			response.Results = append(response.Results, result)
+			continue
+		}
+
+		result.Status = "failed"
+		result.Error = err.Error()
+
+		// Неудача самой проверки уже записана в очередь; ошибки до неё (нет файла, сбой БД) — нет
+		current, getErr := s.queueRepo.GetByWorkID(ctx, item.WorkID)
+		if getErr != nil {
+			s.logger.Error().Err(getErr).Str("work_id", item.WorkID).Msg("Failed to get queue item")
+		} else if current != nil && current.Status == models.QueueStatusProcessing {
+			report, repErr := s.reportRepo.GetByWorkID(ctx, item.WorkID)
+			if repErr != nil || report == nil {
+				report = &models.Report{
+					WorkID:       item.WorkID,
+					FileID:       item.FileID,
+					AssignmentID: item.AssignmentID,
+					StudentID:    item.StudentID,
+				}
+			}
+			s.scheduleRetry(ctx, report, err)
+			current, _ = s.queueRepo.GetByWorkID(ctx, item.WorkID)
+		}
+		if current != nil && current.Status == models.QueueStatusFailed {
+			result.Status = "abandoned"
+			response.Abandoned++
+		} else {
+			response.Failed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	if len(items) > 0 {
+		s.logger.Info().
+			Int("total", response.Total).
+			Int("retried", response.Retried).
+			Int("abandoned", response.Abandoned).
+			Int("failed", response.Failed).
+			Msg("Queued analysis retries processed")
+	}
+
+	return response, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	base, max := 30*time.Second, 10*time.Minute
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 10 * time.Minute},
+		{7, 10 * time.Minute},
+		// Без ограничения 2^99 переполнило бы Duration
+		{100, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := retryBackoff(tt.attempts, base, max); got != tt.want {
+			t.Errorf("retryBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+
+	// Базовая задержка больше предела урезается до предела уже на первой попытке
+	if got := retryBackoff(1, time.Hour, max); got != max {
+		t.Errorf("retryBackoff with base above max = %v, want %v", got, max)
+	}
+}
+
+// fakeQueueRepo хранит одну запись очереди и запоминает, на когда назначен повтор
+type fakeQueueRepo struct {
+	repository.AnalysisQueueRepository
+
+	item        *models.AnalysisQueueItem
+	scheduledAt time.Time
+	failed      bool
+}
+
+func (r *fakeQueueRepo) RecordFailure(_ context.Context, item *models.AnalysisQueueItem) (*models.AnalysisQueueItem, error) {
+	if r.item == nil {
+		r.item = &models.AnalysisQueueItem{ID: "queue-1", WorkID: item.WorkID}
+	}
+	r.item.Attempts++
+	r.item.MaxAttempts = item.MaxAttempts
+	copied := *r.item
+	return &copied, nil
+}
+
+func (r *fakeQueueRepo) Schedule(_ context.Context, _ string, scheduledAt time.Time) error {
+	r.scheduledAt = scheduledAt
+	return nil
+}
+
+func (r *fakeQueueRepo) MarkFailed(context.Context, string) error {
+	r.failed = true
+	return nil
+}
+
+func TestScheduleRetryBacksOffUntilMaxAttempts(t *testing.T) {
+	queueRepo := &fakeQueueRepo{}
+	s := &analysisService{
+		reportRepo: newFakeReportRepo(),
+		queueRepo:  queueRepo,
+		logger:     zerolog.Nop(),
+		config: AnalysisConfig{
+			RetryQueueMaxAttempts: 3,
+			RetryQueueBaseDelay:   time.Minute,
+			RetryQueueMaxDelay:    time.Hour,
+		},
+	}
+	report := &models.Report{ID: "report-1", WorkID: "work-1"}
+
+	// Каждая неудача откладывает повтор вдвое дальше предыдущего
+	for attempt, want := range []time.Duration{time.Minute, 2 * time.Minute} {
+		before := time.Now()
+		s.scheduleRetry(context.Background(), report, errors.New("file service unavailable"))
+
+		if queueRepo.failed {
+			t.Fatalf("attempt %d: queue item marked failed too early", attempt+1)
+		}
+		if delay := queueRepo.scheduledAt.Sub(before); delay < want || delay > want+time.Second {
+			t.Fatalf("attempt %d: retry scheduled in %v, want %v", attempt+1, delay, want)
+		}
+	}
+
+	// Третья неудача исчерпывает попытки: повтор не назначается
+	previous := queueRepo.scheduledAt
+	s.scheduleRetry(context.Background(), report, errors.New("file service unavailable"))
+	if !queueRepo.failed {
+		t.Fatal("queue item not marked failed after max attempts")
+	}
+	if !queueRepo.scheduledAt.Equal(previous) {
+		t.Fatal("retry scheduled after max attempts")
+	}
+}
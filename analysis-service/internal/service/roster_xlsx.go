@@ -0,0 +1,112 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/xlsx"
+)
+
+// ExportAssignmentRoster пишет XLSX со всеми отчётами задания: студент, работа, процент совпадения,
+// вердикт и ссылка на отчёт. Строки с процентом не ниже порога задания выделены красным.
+func (s *reportService) ExportAssignmentRoster(ctx context.Context, assignmentID string) ([]byte, error) {
+	reports, _, err := s.reportRepo.Search(ctx, map[string]interface{}{"assignment_id": assignmentID}, s.config.RosterMaxRows, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reports for roster: %w", err)
+	}
+	if len(reports) == 0 {
+		return nil, ErrAssignmentReportsNotFound
+	}
+
+	threshold, ok, err := s.reportRepo.GetAssignmentThreshold(ctx, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignment threshold: %w", err)
+	}
+	if !ok {
+		threshold = s.config.DefaultThreshold
+	}
+
+	overridden, err := s.overrideRepo.GetOverriddenReportIDs(ctx, assignmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verdict overrides: %w", err)
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		if reports[i].StudentID != reports[j].StudentID {
+			return reports[i].StudentID < reports[j].StudentID
+		}
+		return reports[i].CreatedAt.Before(reports[j].CreatedAt)
+	})
+
+	rows := make([][]interface{}, 0, len(reports)+1)
+	rows = append(rows, []interface{}{
+		"Student ID", "Work ID", "Status", "Match %", "Verdict", "Overridden", "Original Work ID", "Analyzed At", "Report",
+	})
+	rowStyles := map[int]xlsx.Style{0: xlsx.StyleHeader}
+
+	for _, report := range reports {
+		completed := report.Status == models.ReportStatusCompleted.String()
+
+		verdict := report.Status
+		if completed {
+			verdict = "original"
+			if report.PlagiarismFlag {
+				verdict = "plagiarism"
+			}
+		}
+		originalWorkID := ""
+		if report.OriginalWorkID != nil {
+			originalWorkID = *report.OriginalWorkID
+		}
+		analyzedAt := ""
+		if report.CompletedAt != nil {
+			analyzedAt = report.CompletedAt.Format(time.RFC3339)
+		}
+
+		// Тот же критерий, что у проверяющего при выставлении флага
+		if completed && report.MatchPercentage > 0 && report.MatchPercentage >= threshold {
+			rowStyles[len(rows)] = xlsx.StyleHighlight
+		}
+
+		rows = append(rows, []interface{}{
+			report.StudentID,
+			report.WorkID,
+			report.Status,
+			report.MatchPercentage,
+			verdict,
+			overridden[report.ID],
+			originalWorkID,
+			analyzedAt,
+			s.rosterLink(report.ID),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := xlsx.Write(&buf, xlsx.Sheet{Name: "Roster", Rows: rows, RowStyles: rowStyles}); err != nil {
+		return nil, fmt.Errorf("failed to write xlsx: %w", err)
+	}
+
+	s.logger.Info().
+		Str("assignment_id", assignmentID).
+		Int("reports", len(reports)).
+		Int("threshold", threshold).
+		Msg("Assignment roster exported")
+
+	return buf.Bytes(), nil
+}
+
+// rosterLink — ссылка на отчёт; без export.roster.link_base_url в ячейке только ID отчёта
+func (s *reportService) rosterLink(reportID string) interface{} {
+	if s.config.RosterLinkBaseURL == "" {
+		return reportID
+	}
+	return xlsx.Link{
+		URL:  strings.TrimRight(s.config.RosterLinkBaseURL, "/") + "/" + reportID,
+		Text: reportID,
+	}
+}
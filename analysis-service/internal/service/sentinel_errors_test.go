@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+)
+
+type fakeNotificationRepo struct {
+	repository.NotificationRepository
+}
+
+func (r *fakeNotificationRepo) DeleteRecipient(context.Context, string, string) (bool, error) {
+	return false, nil
+}
+
+// Ошибки, которые delivery-слой распознаёт через errors.Is, а не по тексту
+func TestServicesReturnSentinelErrors(t *testing.T) {
+	ctx := context.Background()
+	webhooks := NewWebhookService(nil, zerolog.Nop(), WebhookConfig{})
+	notifications := NewNotificationService(&fakeNotificationRepo{}, zerolog.Nop(), NotificationConfig{})
+	disabledCorpus := NewReferenceCorpusService(nil, nil, nil, zerolog.Nop(), ReferenceCorpusConfig{})
+	corpus := NewReferenceCorpusService(nil, nil, nil, zerolog.Nop(), ReferenceCorpusConfig{Enabled: true, MaxBulkDocuments: 1})
+
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{"webhook url", func() error {
+			_, err := webhooks.CreateSubscription(ctx, models.CreateWebhookRequest{URL: "ftp://example.com"})
+			return err
+		}, ErrInvalidWebhookURL},
+		{"webhook assignment", func() error {
+			_, err := webhooks.CreateSubscription(ctx, models.CreateWebhookRequest{URL: "https://example.com/hook", AssignmentID: "not-a-uuid"})
+			return err
+		}, ErrInvalidAssignmentID},
+		{"webhook id", func() error { return webhooks.DeleteSubscription(ctx, "not-a-uuid") }, ErrWebhookNotFound},
+		{"recipient", func() error {
+			_, err := notifications.AddRecipient(ctx, "assignment", "not a recipient")
+			return err
+		}, ErrInvalidRecipient},
+		{"recipient missing", func() error { return notifications.RemoveRecipient(ctx, "assignment", "recipient") }, ErrRecipientNotFound},
+		{"corpus disabled", func() error {
+			_, _, err := disabledCorpus.List(ctx, "", "", 1, 10)
+			return err
+		}, ErrReferenceCorpusDisabled},
+		{"no documents", func() error {
+			_, err := corpus.BulkImport(ctx, models.BulkImportReferencesRequest{})
+			return err
+		}, ErrNoReferenceDocuments},
+		{"too many documents", func() error {
+			_, err := corpus.BulkImport(ctx, models.BulkImportReferencesRequest{Documents: make([]models.ReferenceDocumentRequest, 2)})
+			return err
+		}, ErrTooManyReferenceDocuments},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
+)
+
+// pingReportRepo добавляет к fakeReportRepo проверку соединения с БД
+type pingReportRepo struct {
+	*fakeReportRepo
+	err error
+}
+
+func (r *pingReportRepo) Ping(context.Context) error { return r.err }
+
+type fakeRabbitMQRepo struct {
+	repository.RabbitMQRepository
+	err error
+}
+
+func (r *fakeRabbitMQRepo) Ping() error { return r.err }
+
+// newHealthServer отвечает на /health статусом status
+func newHealthServer(t *testing.T, status int) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestServiceStatusChecksDependencies(t *testing.T) {
+	// Адрес закрытого сервера: соединение отклоняется, как у упавшего file-service
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	tests := []struct {
+		name        string
+		dbErr       error
+		rabbitErr   error
+		fileURL     string
+		status      string
+		fileService bool
+	}{
+		{"all up", nil, nil, newHealthServer(t, http.StatusOK), "healthy", true},
+		{"file service unreachable", nil, nil, down.URL, "degraded", false},
+		{"file service unhealthy", nil, nil, newHealthServer(t, http.StatusServiceUnavailable), "degraded", false},
+		{"rabbitmq channel closed", nil, errors.New("channel closed"), newHealthServer(t, http.StatusOK), "unhealthy", true},
+		{"database down", errors.New("connection refused"), nil, down.URL, "unhealthy", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Повторы не должны затягивать проверку готовности
+			fileClient := integration.NewFileClient(tt.fileURL, "", time.Second, 5, time.Hour, zerolog.Nop())
+			workClient := integration.NewWorkClient(newHealthServer(t, http.StatusOK), "", time.Second, 5, time.Hour, fileClient, zerolog.Nop())
+			s := NewAnalysisService(&pingReportRepo{fakeReportRepo: newFakeReportRepo(), err: tt.dbErr}, nil, nil, workClient, fileClient, &fakeChecker{},
+				nil, &fakePublisher{}, &fakeRabbitMQRepo{err: tt.rabbitErr}, nil, nil, nil, zerolog.Nop(), AnalysisConfig{})
+
+			status, err := s.GetServiceStatus(context.Background())
+			if err != nil {
+				t.Fatalf("GetServiceStatus: %v", err)
+			}
+			if status.Status != tt.status {
+				t.Fatalf("status = %s, want %s", status.Status, tt.status)
+			}
+			if status.FileService != tt.fileService || !status.WorkService {
+				t.Fatalf("file_service = %v, work_service = %v; want %v, true", status.FileService, status.WorkService, tt.fileService)
+			}
+			if status.Database != (tt.dbErr == nil) || status.RabbitMQ != (tt.rabbitErr == nil) {
+				t.Fatalf("database = %v, rabbitmq = %v", status.Database, status.RabbitMQ)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+// recheckSiblings заново проверяет работы задания, завершённые незадолго до report и не
+// сравнивавшиеся с ним: при одновременной сдаче первая проверка не видит вторую работу
+func (s *analysisService) recheckSiblings(report *models.Report, completedAt time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	ctx = WithTriggerSource(ctx, models.TriggerSourceRecheck)
+
+	siblings, err := s.reportRepo.GetRecentlyCompletedInAssignment(
+		ctx,
+		report.AssignmentID,
+		completedAt.Add(-s.config.SiblingRecheckWindow),
+		report.WorkID,
+		s.config.SiblingRecheckMaxWorks,
+	)
+	if err != nil {
+		s.logger.Error().Err(err).Str("work_id", report.WorkID).Msg("Failed to get sibling works for recheck")
+		return
+	}
+
+	rechecked := 0
+	for i := range siblings {
+		sibling := &siblings[i]
+		// Работы одного студента не считаются плагиатом друг друга
+		if sibling.StudentID == report.StudentID || containsString(sibling.ComparedHashes, report.FileHash) {
+			continue
+		}
+
+		result := s.reanalyzeReport(ctx, sibling)
+		if result.Status == "reanalyzed" {
+			rechecked++
+		}
+	}
+
+	if rechecked > 0 {
+		s.logger.Info().
+			Str("work_id", report.WorkID).
+			Str("assignment_id", report.AssignmentID).
+			Int("rechecked", rechecked).
+			Msg("Sibling works rechecked after analysis")
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+)
+
+const (
+	SyncLimitReject = "reject"
+	SyncLimitAsync  = "async"
+)
+
+// ErrComparisonSetTooLarge — работ для сравнения больше SyncMaxComparisonSet, синхронный анализ не успеет
+var ErrComparisonSetTooLarge = errors.New("comparison set too large for synchronous analysis")
+
+// ComparisonSetTooLargeError — синхронный анализ не выполнялся; при SyncLimitAsync ReportID — отчёт
+// запущенного вместо него асинхронного анализа
+type ComparisonSetTooLargeError struct {
+	Size     int
+	Limit    int
+	ReportID string
+}
+
+func (e *ComparisonSetTooLargeError) Error() string {
+	return ErrComparisonSetTooLarge.Error()
+}
+
+func (e *ComparisonSetTooLargeError) Unwrap() error {
+	return ErrComparisonSetTooLarge
+}
+
+// AnalyzeWorkSync — анализ в рамках HTTP-запроса. Если работ задания для сравнения больше
+// SyncMaxComparisonSet, запрос отклоняется или переводится в асинхронный (SyncLimitAction);
+// загруженные работы передаются в проверку, чтобы не запрашивать их второй раз
+func (s *analysisService) AnalyzeWorkSync(ctx context.Context, workID, fileID, assignmentID, studentID string) (*models.AnalysisResult, error) {
+	if s.config.SyncMaxComparisonSet <= 0 || fileID == models.PendingFileID {
+		return s.analyzeWork(ctx, workID, fileID, assignmentID, studentID, nil)
+	}
+
+	// Готовый отчёт отдаётся при любом размере задания
+	existingReport, err := s.reportRepo.GetByWorkID(ctx, workID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing report: %w", err)
+	}
+	if existingReport != nil && existingReport.Status == models.ReportStatusCompleted.String() {
+		return s.convertReportToResult(existingReport), nil
+	}
+
+	comparisonSet, err := s.workClient.GetPreviousWorks(ctx, assignmentID, workID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous works: %w", err)
+	}
+
+	if len(comparisonSet) <= s.config.SyncMaxComparisonSet {
+		if comparisonSet == nil {
+			comparisonSet = []models.SimilarWork{}
+		}
+		return s.analyzeWork(ctx, workID, fileID, assignmentID, studentID, comparisonSet)
+	}
+
+	tooLarge := &ComparisonSetTooLargeError{Size: len(comparisonSet), Limit: s.config.SyncMaxComparisonSet}
+
+	s.logger.Warn().
+		Str("work_id", workID).
+		Str("assignment_id", assignmentID).
+		Int("comparison_set", tooLarge.Size).
+		Int("limit", tooLarge.Limit).
+		Str("action", s.config.SyncLimitAction).
+		Msg("Comparison set too large for synchronous analysis")
+
+	if s.config.SyncLimitAction == SyncLimitAsync {
+		reportID, err := s.AnalyzeWorkAsync(ctx, workID, fileID, assignmentID, studentID)
+		if err != nil {
+			return nil, err
+		}
+		tooLarge.ReportID = reportID
+	}
+
+	return nil, tooLarge
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/analyzer"
+)
+
+var (
+	ErrUrgentDisabled = errors.New("urgent analysis is disabled")
+	// ErrUrgentBusy — все срочные слоты заняты дольше UrgentWaitTimeout
+	ErrUrgentBusy = errors.New("no urgent analysis slot available")
+)
+
+// AnalyzeWorkUrgent выполняет анализ сразу, в одном из UrgentSlots слотов: очередь событий
+// и пакетные запросы эти слоты не занимают, а загрузки файлов идут через отдельный лимит
+func (s *analysisService) AnalyzeWorkUrgent(ctx context.Context, workID, fileID, assignmentID, studentID string) (*models.AnalysisResult, error) {
+	if s.urgentSlots == nil {
+		return nil, ErrUrgentDisabled
+	}
+
+	wait := time.NewTimer(s.config.UrgentWaitTimeout)
+	defer wait.Stop()
+
+	select {
+	case s.urgentSlots <- struct{}{}:
+		defer func() { <-s.urgentSlots }()
+	case <-wait.C:
+		return nil, ErrUrgentBusy
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	ctx = analyzer.WithUrgent(WithTriggerSource(ctx, models.TriggerSourceUrgent))
+	return s.analyzeWork(ctx, workID, fileID, assignmentID, studentID, nil)
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// Заголовки запроса вебхука. Подпись — "sha256=" + hex(HMAC-SHA256(secret, тело запроса))
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, req models.CreateWebhookRequest) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	// NotifyAnalysisCompleted отправляет событие всем подходящим подпискам и ждёт окончания доставки
+	NotifyAnalysisCompleted(ctx context.Context, event models.AnalysisCompletedEvent)
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	client      *http.Client
+	logger      zerolog.Logger
+	config      WebhookConfig
+}
+
+type WebhookConfig struct {
+	Enabled bool
+	// Таймаут одного запроса
+	Timeout time.Duration
+	// Попытки доставки одного события; между ними RetryDelay * 2^(n-1)
+	MaxAttempts int
+	RetryDelay  time.Duration
+	// Неудачных доставок подряд, после которых подписка отключается (0 — не отключать)
+	MaxFailures int
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository, logger zerolog.Logger, config WebhookConfig) WebhookService {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+
+	return &webhookService{
+		webhookRepo: webhookRepo,
+		client: &http.Client{
+			Timeout: config.Timeout,
+		},
+		logger: logger,
+		config: config,
+	}
+}
+
+func (s *webhookService) CreateSubscription(ctx context.Context, req models.CreateWebhookRequest) (*models.WebhookSubscription, error) {
+	target := strings.TrimSpace(req.URL)
+	if recipientType(target) != models.RecipientTypeWebhook {
+		return nil, ErrInvalidWebhookURL
+	}
+
+	subscription := &models.WebhookSubscription{
+		URL:            target,
+		Secret:         strings.TrimSpace(req.Secret),
+		PlagiarismFlag: req.PlagiarismFlag,
+		Active:         true,
+		CreatedAt:      time.Now(),
+	}
+
+	if assignmentID := strings.TrimSpace(req.AssignmentID); assignmentID != "" {
+		if _, err := uuid.Parse(assignmentID); err != nil {
+			return nil, ErrInvalidAssignmentID
+		}
+		subscription.AssignmentID = &assignmentID
+	}
+
+	if subscription.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		subscription.Secret = secret
+	}
+
+	if err := s.webhookRepo.Create(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	s.logger.Info().
+		Str("webhook_id", subscription.ID).
+		Str("host", webhookHost(subscription.URL)).
+		Msg("Webhook subscription created")
+
+	return subscription, nil
+}
+
+func (s *webhookService) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	subscriptions, err := s.webhookRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	// Ключ подписи показывается только при создании
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	if subscriptions == nil {
+		subscriptions = []models.WebhookSubscription{}
+	}
+	return subscriptions, nil
+}
+
+func (s *webhookService) DeleteSubscription(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrWebhookNotFound
+	}
+
+	deleted, err := s.webhookRepo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	if !deleted {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (s *webhookService) NotifyAnalysisCompleted(ctx context.Context, event models.AnalysisCompletedEvent) {
+	if !s.config.Enabled {
+		return
+	}
+
+	subscriptions, err := s.webhookRepo.GetActiveFor(ctx, event.AssignmentID, event.PlagiarismFlag)
+	if err != nil {
+		s.logger.Error().Err(err).Str("work_id", event.WorkID).Msg("Failed to get webhook subscriptions")
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Error().Err(err).Str("work_id", event.WorkID).Msg("Failed to marshal webhook payload")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range subscriptions {
+		wg.Add(1)
+		go func(subscription models.WebhookSubscription) {
+			defer wg.Done()
+			s.deliver(ctx, subscription, models.EventAnalysisCompleted, body)
+		}(subscriptions[i])
+	}
+	wg.Wait()
+}
+
+// deliver отправляет событие с повторами и записывает результат в подписку
+func (s *webhookService) deliver(ctx context.Context, subscription models.WebhookSubscription, eventType string, body []byte) {
+	deliveryID := uuid.New().String()
+
+	var lastErr error
+attempts:
+	for attempt := 1; attempt <= s.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			delay := s.config.RetryDelay * time.Duration(1<<(attempt-2))
+			select {
+			case <-ctx.Done():
+				lastErr = ctx.Err()
+				break attempts
+			case <-time.After(delay):
+			}
+		}
+
+		retryable, err := s.post(ctx, subscription, eventType, deliveryID, body)
+		if err == nil {
+			if err := s.webhookRepo.RecordSuccess(ctx, subscription.ID); err != nil {
+				s.logger.Error().Err(err).Str("webhook_id", subscription.ID).Msg("Failed to record webhook delivery")
+			}
+			return
+		}
+
+		lastErr = err
+		s.logger.Warn().
+			Err(err).
+			Str("webhook_id", subscription.ID).
+			Str("delivery_id", deliveryID).
+			Int("attempt", attempt).
+			Msg("Webhook delivery attempt failed")
+
+		if !retryable {
+			break attempts
+		}
+	}
+
+	disabled, err := s.webhookRepo.RecordFailure(ctx, subscription.ID, lastErr.Error(), s.config.MaxFailures)
+	if err != nil {
+		s.logger.Error().Err(err).Str("webhook_id", subscription.ID).Msg("Failed to record webhook failure")
+		return
+	}
+	if disabled {
+		s.logger.Warn().
+			Str("webhook_id", subscription.ID).
+			Str("host", webhookHost(subscription.URL)).
+			Int("max_failures", s.config.MaxFailures).
+			Msg("Webhook subscription disabled after repeated failures")
+	}
+}
+
+// post возвращает, имеет ли смысл повторять запрос: повторяются сетевые ошибки, 429 и 5xx
+func (s *webhookService) post(ctx context.Context, subscription models.WebhookSubscription, eventType, deliveryID string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, eventType)
+	req.Header.Set(WebhookDeliveryHeader, deliveryID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(subscription.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+}
+
+// SignWebhookPayload — значение заголовка X-Webhook-Signature; получатель считает его
+// от сырого тела запроса своим ключом и сравнивает за постоянное время
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// webhookHost — хост подписки для логов без пути и параметров, в которых может быть токен
+func webhookHost(target string) string {
+	u, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
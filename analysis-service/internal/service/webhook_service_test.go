@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+)
+
+// fakeWebhookRepo отдаёт одну подписку и считает успешные и неудачные доставки
+type fakeWebhookRepo struct {
+	repository.WebhookRepository
+
+	mu           sync.Mutex
+	subscription models.WebhookSubscription
+	successes    int
+	failures     int
+}
+
+func (r *fakeWebhookRepo) GetActiveFor(context.Context, string, bool) ([]models.WebhookSubscription, error) {
+	return []models.WebhookSubscription{r.subscription}, nil
+}
+
+func (r *fakeWebhookRepo) RecordSuccess(context.Context, string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.successes++
+	r.failures = 0
+	return nil
+}
+
+func (r *fakeWebhookRepo) RecordFailure(_ context.Context, _, _ string, maxFailures int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures++
+	if maxFailures > 0 && r.failures >= maxFailures && r.subscription.Active {
+		r.subscription.Active = false
+		return true, nil
+	}
+	return false, nil
+}
+
+// webhookReceiver — получатель, который проверяет подпись и отвечает статусами из statuses по очереди
+type webhookReceiver struct {
+	t      *testing.T
+	secret string
+
+	mu         sync.Mutex
+	statuses   []int
+	deliveries []string
+	bodies     [][]byte
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if got, want := r.Header.Get(WebhookSignatureHeader), SignWebhookPayload(rcv.secret, body); !hmac.Equal([]byte(got), []byte(want)) {
+		rcv.t.Errorf("signature = %q, want %q", got, want)
+	}
+	if got := r.Header.Get(WebhookEventHeader); got != models.EventAnalysisCompleted {
+		rcv.t.Errorf("event header = %q, want %q", got, models.EventAnalysisCompleted)
+	}
+
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	rcv.deliveries = append(rcv.deliveries, r.Header.Get(WebhookDeliveryHeader))
+	rcv.bodies = append(rcv.bodies, body)
+	status := http.StatusOK
+	if len(rcv.statuses) > 0 {
+		status, rcv.statuses = rcv.statuses[0], rcv.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func newWebhookTest(t *testing.T, statuses ...int) (*webhookReceiver, *fakeWebhookRepo, WebhookService) {
+	t.Helper()
+
+	receiver := &webhookReceiver{t: t, secret: "shared-secret", statuses: statuses}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+
+	repo := &fakeWebhookRepo{subscription: models.WebhookSubscription{
+		ID: "webhook-1", URL: server.URL + "/hooks/analysis", Secret: receiver.secret, Active: true,
+	}}
+	s := NewWebhookService(repo, zerolog.Nop(), WebhookConfig{
+		Enabled:     true,
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		RetryDelay:  time.Millisecond,
+		MaxFailures: 1,
+	})
+	return receiver, repo, s
+}
+
+func TestWebhookSignedAndRetriedOnServerError(t *testing.T) {
+	receiver, repo, s := newWebhookTest(t, http.StatusInternalServerError, http.StatusInternalServerError)
+	event := models.AnalysisCompletedEvent{WorkID: "work-42", ReportID: "report-1", Status: "completed", PlagiarismFlag: true, MatchPercentage: 87}
+
+	s.NotifyAnalysisCompleted(context.Background(), event)
+
+	if len(receiver.deliveries) != 3 {
+		t.Fatalf("receiver got %d requests, want 2 failures and a success", len(receiver.deliveries))
+	}
+	// Повторы — та же доставка: получатель может отбросить дубликаты по X-Webhook-Delivery
+	for _, id := range receiver.deliveries {
+		if id == "" || id != receiver.deliveries[0] {
+			t.Fatalf("delivery ids = %v, want one non-empty id for all attempts", receiver.deliveries)
+		}
+	}
+	var got models.AnalysisCompletedEvent
+	if err := json.Unmarshal(receiver.bodies[2], &got); err != nil || got.WorkID != "work-42" || got.MatchPercentage != 87 {
+		t.Fatalf("payload = %s (%v), want the completed event", receiver.bodies[2], err)
+	}
+	if repo.successes != 1 || repo.failures != 0 {
+		t.Fatalf("recorded %d successes and %d failures, want 1 and 0", repo.successes, repo.failures)
+	}
+}
+
+func TestWebhookDisabledAfterFailedDelivery(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		requests int
+	}{
+		{"server errors exhaust attempts", []int{500, 502, 503}, 3},
+		// Ошибка клиента не исправится повтором
+		{"client error is not retried", []int{400}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, repo, s := newWebhookTest(t, tt.statuses...)
+
+			s.NotifyAnalysisCompleted(context.Background(), models.AnalysisCompletedEvent{WorkID: "work-42"})
+
+			if len(receiver.deliveries) != tt.requests {
+				t.Fatalf("receiver got %d requests, want %d", len(receiver.deliveries), tt.requests)
+			}
+			if repo.successes != 0 || repo.failures != 1 {
+				t.Fatalf("recorded %d successes and %d failures, want 0 and 1", repo.successes, repo.failures)
+			}
+			if repo.subscription.Active {
+				t.Fatal("subscription still active after reaching max failures")
+			}
+		})
+	}
+}
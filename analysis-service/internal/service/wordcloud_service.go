@@ -69,7 +69,7 @@ func (s *wordCloudService) RenderWorkWordCloudPNG(ctx context.Context, workID st
 	content, err := s.fileClient.GetFileContent(ctx, report.FileID)
 	if err != nil {
 		// Отличаем "нет файла" от остальных сбоев file-service.
-		if strings.HasPrefix(err.Error(), "file not found:") || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		if errors.Is(err, integration.ErrFileNotFound) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return nil, fmt.Errorf("%w: %v", ErrFileServiceError, err)
 		}
 		return nil, fmt.Errorf("%w: %v", ErrFileServiceError, err)
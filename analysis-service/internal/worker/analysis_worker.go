@@ -13,8 +13,12 @@ import (
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/worker/queue"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/tracing"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type AnalysisWorker interface {
@@ -32,12 +36,27 @@ type WorkerStats struct {
 	QueueLength    int `json:"queue_length"`
 }
 
+const (
+	// Повторное событие о работе подтверждается без обработки
+	DuplicateEventSkip = "skip"
+	// Повторное событие перезапускает анализ, если существующий отчёт упал
+	DuplicateEventRetryFailed = "retry_failed"
+)
+
+type AnalysisWorkerConfig struct {
+	DuplicateEvents string
+	// nil — метрики Prometheus не собираются
+	Metrics *Metrics
+}
+
 type analysisWorker struct {
 	workerPool      *WorkerPool
 	queueConsumer   queue.RabbitMQConsumer
+	queuePublisher  queue.RabbitMQPublisher
 	reportRepo      repository.ReportRepository
 	analysisService service.AnalysisService
 	logger          zerolog.Logger
+	config          AnalysisWorkerConfig
 	stats           WorkerStats
 	statsMutex      sync.RWMutex
 	startTime       time.Time
@@ -46,16 +65,28 @@ type analysisWorker struct {
 func NewAnalysisWorker(
 	workerPool *WorkerPool,
 	queueConsumer queue.RabbitMQConsumer,
+	queuePublisher queue.RabbitMQPublisher,
 	reportRepo repository.ReportRepository,
 	analysisService service.AnalysisService,
 	logger zerolog.Logger,
+	config AnalysisWorkerConfig,
 ) AnalysisWorker {
+	if config.DuplicateEvents == "" {
+		config.DuplicateEvents = DuplicateEventSkip
+	}
+	// Счётчики nil-safe, так что пустая структура просто ничего не считает
+	if config.Metrics == nil {
+		config.Metrics = &Metrics{}
+	}
+
 	return &analysisWorker{
 		workerPool:      workerPool,
 		queueConsumer:   queueConsumer,
+		queuePublisher:  queuePublisher,
 		reportRepo:      reportRepo,
 		analysisService: analysisService,
 		logger:          logger,
+		config:          config,
 		stats:           WorkerStats{},
 		startTime:       time.Now(),
 	}
@@ -82,14 +113,16 @@ func (w *analysisWorker) Start(ctx context.Context) error {
 func (w *analysisWorker) Stop() error {
 	w.logger.Info().Msg("Stopping analysis worker...")
 
-	if err := w.workerPool.Stop(); err != nil {
-		w.logger.Error().Err(err).Msg("Failed to stop worker pool")
-	}
-
+	// Подписка отменяется до остановки пула: при поэтапном развёртывании новые сообщения
+	// сразу уходят другим экземплярам, а начатые задачи успевают подтвердить свои
 	if err := w.queueConsumer.Close(); err != nil {
 		w.logger.Error().Err(err).Msg("Failed to close queue consumer")
 	}
 
+	if err := w.workerPool.Stop(); err != nil {
+		w.logger.Error().Err(err).Msg("Failed to stop worker pool")
+	}
+
 	w.logger.Info().
 		Int("total_processed", w.stats.TotalProcessed).
 		Int("failed_jobs", w.stats.FailedJobs).
@@ -118,8 +151,17 @@ func (w *analysisWorker) processMessages(ctx context.Context, msgs <-chan queue.
 					w.statsMutex.Lock()
 					w.stats.FailedJobs++
 					w.statsMutex.Unlock()
+					w.config.Metrics.JobsFailed.Inc()
 
 					if isPermanentError(err) {
+						// Если DLQ недоступна, сообщение возвращается в очередь, чтобы не потерять его
+						if dlqErr := w.queuePublisher.PublishToDLQ(ctx, msg.Body, err.Error()); dlqErr != nil {
+							w.logger.Error().Err(dlqErr).Msg("Failed to publish message to dead-letter queue")
+							if nackErr := msg.Nack(false, true); nackErr != nil {
+								w.logger.Error().Err(nackErr).Msg("Failed to nack message")
+							}
+							return
+						}
 						if ackErr := msg.Ack(false); ackErr != nil {
 							w.logger.Error().Err(ackErr).Msg("Failed to ack message")
 						}
@@ -140,13 +182,27 @@ func (w *analysisWorker) processMessages(ctx context.Context, msgs <-chan queue.
 						w.stats.ProcessedToday++
 					}
 					w.statsMutex.Unlock()
+					w.config.Metrics.JobsProcessed.Inc()
 				}
 			})
 		}
 	}
 }
 
-func (w *analysisWorker) processMessage(ctx context.Context, msg queue.RabbitMQMessage) error {
+func (w *analysisWorker) processMessage(ctx context.Context, msg queue.RabbitMQMessage) (err error) {
+	// Спан продолжает трассу загрузки работы из заголовков сообщения work-service
+	ctx, span := tracing.Tracer().Start(tracing.ExtractAMQP(ctx, msg.Headers), "work.created process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.String("messaging.system", "rabbitmq")),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	var event models.WorkCreatedEvent
 	if err := json.Unmarshal(msg.Body, &event); err != nil {
 		return permanent(fmt.Errorf("failed to unmarshal event: %w", err))
@@ -159,6 +215,11 @@ func (w *analysisWorker) processMessage(ctx context.Context, msg queue.RabbitMQM
 		return permanent(errors.New("empty file_id"))
 	}
 
+	span.SetAttributes(
+		attribute.String("work_id", event.WorkID),
+		attribute.String("assignment_id", event.AssignmentID),
+	)
+
 	w.logger.Info().
 		Str("work_id", event.WorkID).
 		Str("file_id", event.FileID).
@@ -170,36 +231,45 @@ func (w *analysisWorker) processMessage(ctx context.Context, msg queue.RabbitMQM
 
 func (w *analysisWorker) ProcessWork(ctx context.Context, workID, fileID, assignmentID, studentID string) error {
 	startTime := time.Now()
+	ctx = service.WithTriggerSource(ctx, models.TriggerSourceEvent)
 
-	exists, err := w.reportRepo.Exists(ctx, workID)
-	if err != nil {
-		return fmt.Errorf("failed to check if report exists: %w", err)
-	}
-
-	if exists {
-		w.logger.Warn().
-			Str("work_id", workID).
-			Msg("Report already exists, skipping")
+	// Отчёт для работы без файла пишет сам AnalyzeWork; при политике reject сообщение уходит в DLQ
+	if fileID == models.PendingFileID {
+		if _, err := w.analysisService.AnalyzeWork(ctx, workID, fileID, assignmentID, studentID); err != nil {
+			if errors.Is(err, service.ErrWorkHasNoFile) {
+				return permanent(err)
+			}
+			return err
+		}
 		return nil
 	}
 
 	report := &models.Report{
-		ID:           uuid.New().String(),
-		WorkID:       workID,
-		FileID:       fileID,
-		AssignmentID: assignmentID,
-		StudentID:    studentID,
-		Status:       models.ReportStatusProcessing.String(),
-		CreatedAt:    time.Now(),
-		StartedAt:    &startTime,
-		UpdatedAt:    time.Now(),
+		ID:            uuid.New().String(),
+		WorkID:        workID,
+		FileID:        fileID,
+		AssignmentID:  assignmentID,
+		StudentID:     studentID,
+		Status:        models.ReportStatusProcessing.String(),
+		TriggerSource: models.TriggerSourceEvent,
+		CreatedAt:     time.Now(),
+		StartedAt:     &startTime,
+		UpdatedAt:     time.Now(),
 	}
 
-	if err := w.reportRepo.Create(ctx, report); err != nil {
+	// RabbitMQ доставляет события хотя бы один раз: отчёт создаётся атомарно по work_id,
+	// и повторная или параллельная доставка того же события не запускает второй анализ
+	created, err := w.reportRepo.CreateIfAbsent(ctx, report)
+	if err != nil {
 		return fmt.Errorf("failed to create report: %w", err)
 	}
+	if !created {
+		return w.handleDuplicate(ctx, workID, fileID, assignmentID, studentID)
+	}
 
+	analyzeStart := time.Now()
 	result, err := w.analysisService.AnalyzeWork(ctx, workID, fileID, assignmentID, studentID)
+	w.config.Metrics.ProcessingTime.Observe(float64(time.Since(analyzeStart).Milliseconds()))
 	if err != nil {
 		report.Status = models.ReportStatusFailed.String()
 		report.UpdatedAt = time.Now()
@@ -242,6 +312,37 @@ func (w *analysisWorker) ProcessWork(ctx context.Context, workID, fileID, assign
 	return nil
 }
 
+func (w *analysisWorker) handleDuplicate(ctx context.Context, workID, fileID, assignmentID, studentID string) error {
+	if w.config.DuplicateEvents != DuplicateEventRetryFailed {
+		w.logger.Info().
+			Str("work_id", workID).
+			Msg("Report already exists, skipping duplicate event")
+		return nil
+	}
+
+	existing, err := w.reportRepo.GetByWorkID(ctx, workID)
+	if err != nil {
+		return fmt.Errorf("failed to get existing report: %w", err)
+	}
+	if existing == nil || existing.Status != models.ReportStatusFailed.String() {
+		w.logger.Info().
+			Str("work_id", workID).
+			Msg("Report already exists, skipping duplicate event")
+		return nil
+	}
+
+	w.logger.Info().
+		Str("work_id", workID).
+		Str("report_id", existing.ID).
+		Msg("Duplicate event for failed report, retrying analysis")
+
+	if _, err := w.analysisService.AnalyzeWork(ctx, workID, fileID, assignmentID, studentID); err != nil {
+		return fmt.Errorf("failed to analyze work: %w", err)
+	}
+
+	return nil
+}
+
 func (w *analysisWorker) GetStats() WorkerStats {
 	w.statsMutex.RLock()
 	defer w.statsMutex.RUnlock()
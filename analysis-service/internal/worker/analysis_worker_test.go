@@ -0,0 +1,250 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/worker/queue"
+)
+
+// memReportRepo хранит отчёты по work_id; CreateIfAbsent атомарен, как INSERT ... ON CONFLICT DO NOTHING
+type memReportRepo struct {
+	repository.ReportRepository
+
+	mu      sync.Mutex
+	reports map[string]*models.Report
+}
+
+func newMemReportRepo() *memReportRepo {
+	return &memReportRepo{reports: make(map[string]*models.Report)}
+}
+
+func (r *memReportRepo) CreateIfAbsent(_ context.Context, report *models.Report) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.reports[report.WorkID]; ok {
+		return false, nil
+	}
+	copied := *report
+	r.reports[report.WorkID] = &copied
+	return true, nil
+}
+
+func (r *memReportRepo) GetByWorkID(_ context.Context, workID string) (*models.Report, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report, ok := r.reports[workID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *report
+	return &copied, nil
+}
+
+func (r *memReportRepo) Update(_ context.Context, report *models.Report) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *report
+	r.reports[report.WorkID] = &copied
+	return nil
+}
+
+// countingAnalysis считает вызовы AnalyzeWork; release, если задан, держит анализ до закрытия
+type countingAnalysis struct {
+	service.AnalysisService
+
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (a *countingAnalysis) AnalyzeWork(_ context.Context, workID, _, _, _ string) (*models.AnalysisResult, error) {
+	a.calls.Add(1)
+	if a.release != nil {
+		<-a.release
+	}
+	return &models.AnalysisResult{WorkID: workID}, nil
+}
+
+func newTestWorker(reports repository.ReportRepository, analysis service.AnalysisService, duplicateEvents string) *analysisWorker {
+	return NewAnalysisWorker(nil, nil, nil, reports, analysis, zerolog.Nop(), AnalysisWorkerConfig{DuplicateEvents: duplicateEvents}).(*analysisWorker)
+}
+
+func TestDuplicateDeliveryAnalyzesOnce(t *testing.T) {
+	reports := newMemReportRepo()
+	analysis := &countingAnalysis{release: make(chan struct{})}
+	w := newTestWorker(reports, analysis, DuplicateEventSkip)
+
+	// Две доставки одного события обрабатываются параллельно, пока первый анализ ещё идёт
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- w.ProcessWork(context.Background(), "work-1", "file-1", "assignment", "alice")
+		}()
+	}
+	// Дубликат завершается сразу, не дожидаясь анализа
+	if err := <-errs; err != nil {
+		t.Fatalf("duplicate delivery: %v", err)
+	}
+	close(analysis.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("first delivery: %v", err)
+		}
+	}
+
+	// Повторная доставка после завершения тоже ничего не делает
+	if err := w.ProcessWork(context.Background(), "work-1", "file-1", "assignment", "alice"); err != nil {
+		t.Fatalf("redelivery: %v", err)
+	}
+
+	if got := analysis.calls.Load(); got != 1 {
+		t.Fatalf("AnalyzeWork called %d times, want 1", got)
+	}
+	if len(reports.reports) != 1 {
+		t.Fatalf("stored %d reports, want 1", len(reports.reports))
+	}
+	if report, _ := reports.GetByWorkID(context.Background(), "work-1"); report.Status != models.ReportStatusCompleted.String() {
+		t.Fatalf("report status = %s, want completed", report.Status)
+	}
+}
+
+func TestDuplicateDeliveryRetriesFailedReport(t *testing.T) {
+	for _, tt := range []struct {
+		policy    string
+		status    models.ReportStatus
+		wantCalls int32
+	}{
+		{DuplicateEventSkip, models.ReportStatusFailed, 0},
+		{DuplicateEventRetryFailed, models.ReportStatusFailed, 1},
+		{DuplicateEventRetryFailed, models.ReportStatusCompleted, 0},
+	} {
+		t.Run(tt.policy+"/"+tt.status.String(), func(t *testing.T) {
+			reports := newMemReportRepo()
+			reports.reports["work-1"] = &models.Report{ID: "report-1", WorkID: "work-1", Status: tt.status.String()}
+			analysis := &countingAnalysis{}
+			w := newTestWorker(reports, analysis, tt.policy)
+
+			if err := w.ProcessWork(context.Background(), "work-1", "file-1", "assignment", "alice"); err != nil {
+				t.Fatalf("process: %v", err)
+			}
+			if got := analysis.calls.Load(); got != tt.wantCalls {
+				t.Fatalf("AnalyzeWork called %d times, want %d", got, tt.wantCalls)
+			}
+			if len(reports.reports) != 1 {
+				t.Fatalf("stored %d reports, want 1", len(reports.reports))
+			}
+		})
+	}
+}
+
+type fakeConsumer struct {
+	msgs chan queue.RabbitMQMessage
+}
+
+func (c *fakeConsumer) Consume(context.Context) (<-chan queue.RabbitMQMessage, error) {
+	return c.msgs, nil
+}
+func (c *fakeConsumer) GetQueueLength() (int, error) { return len(c.msgs), nil }
+func (c *fakeConsumer) Close() error                 { return nil }
+
+type dlqMessage struct {
+	body   []byte
+	reason string
+}
+
+// fakePublisher запоминает сообщения, отправленные в DLQ; dlqErr имитирует недоступную DLQ
+type fakePublisher struct {
+	queue.RabbitMQPublisher
+
+	mu     sync.Mutex
+	dlq    []dlqMessage
+	dlqErr error
+}
+
+func (p *fakePublisher) PublishToDLQ(_ context.Context, body []byte, reason string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.dlqErr != nil {
+		return p.dlqErr
+	}
+	p.dlq = append(p.dlq, dlqMessage{body: body, reason: reason})
+	return nil
+}
+
+// settlement — чем завершилась доставка: ack или nack с флагом requeue
+type settlement struct {
+	ack     bool
+	requeue bool
+}
+
+// deliver прогоняет одно сообщение через запущенный воркер и ждёт его ack или nack
+func deliver(t *testing.T, publisher *fakePublisher, body string) settlement {
+	t.Helper()
+
+	consumer := &fakeConsumer{msgs: make(chan queue.RabbitMQMessage, 1)}
+	w := NewAnalysisWorker(NewWorkerPool(1, zerolog.Nop()), consumer, publisher, newMemReportRepo(), &countingAnalysis{}, zerolog.Nop(), AnalysisWorkerConfig{})
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer w.Stop()
+
+	settled := make(chan settlement, 1)
+	consumer.msgs <- queue.RabbitMQMessage{
+		Body:      []byte(body),
+		Timestamp: time.Now(),
+		Ack: func(bool) error {
+			settled <- settlement{ack: true}
+			return nil
+		},
+		Nack: func(_ bool, requeue bool) error {
+			settled <- settlement{requeue: requeue}
+			return nil
+		},
+	}
+
+	select {
+	case s := <-settled:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was neither acked nor nacked")
+		return settlement{}
+	}
+}
+
+func TestEmptyWorkIDGoesToDLQ(t *testing.T) {
+	publisher := &fakePublisher{}
+	body := `{"work_id":"","file_id":"file-1","assignment_id":"assignment","student_id":"alice"}`
+
+	if got := deliver(t, publisher, body); got != (settlement{ack: true}) {
+		t.Fatalf("settlement = %+v, want ack after publishing to DLQ", got)
+	}
+	if len(publisher.dlq) != 1 {
+		t.Fatalf("DLQ has %d messages, want 1", len(publisher.dlq))
+	}
+	if got := publisher.dlq[0]; string(got.body) != body || got.reason != "empty work_id" {
+		t.Fatalf("DLQ message = %q (%s), want original body with reason empty work_id", got.body, got.reason)
+	}
+}
+
+func TestDLQFailureRequeuesMessage(t *testing.T) {
+	publisher := &fakePublisher{dlqErr: errors.New("channel closed")}
+
+	// Без DLQ сообщение возвращается в очередь, а не теряется
+	if got := deliver(t, publisher, `{"work_id":""}`); got != (settlement{requeue: true}) {
+		t.Fatalf("settlement = %+v, want nack with requeue", got)
+	}
+}
@@ -0,0 +1,80 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+	"github.com/rs/zerolog"
+)
+
+// HashIndexRefresher строит индекс точных копий при старте и перестраивает его с интервалом:
+// так в него попадают отчёты, завершённые другими экземплярами, и уходят удалённые
+type HashIndexRefresher struct {
+	analysisService service.AnalysisService
+	logger          zerolog.Logger
+	interval        time.Duration
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
+}
+
+// NewHashIndexRefresher — interval 0 означает только построение при старте
+func NewHashIndexRefresher(analysisService service.AnalysisService, logger zerolog.Logger, interval time.Duration) *HashIndexRefresher {
+	return &HashIndexRefresher{
+		analysisService: analysisService,
+		logger:          logger,
+		interval:        interval,
+	}
+}
+
+func (r *HashIndexRefresher) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		r.rebuild(ctx)
+		if r.interval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.rebuild(ctx)
+			}
+		}
+	}()
+}
+
+func (r *HashIndexRefresher) rebuild(ctx context.Context) {
+	stats, err := r.analysisService.RebuildHashIndex(ctx, "")
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Error().Err(err).Msg("Failed to rebuild hash index")
+		}
+		return
+	}
+
+	r.logger.Info().
+		Int("assignments", stats.Assignments).
+		Int("hashes", stats.Hashes).
+		Int("works", stats.Works).
+		Msg("Hash index rebuilt")
+}
+
+// Stop дожидается завершения текущего перестроения
+func (r *HashIndexRefresher) Stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	r.wg.Wait()
+}
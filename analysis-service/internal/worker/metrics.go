@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"math"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/worker/queue"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/prommetrics"
+)
+
+// Корзины гистограммы времени анализа, мс
+var ProcessingTimeBuckets = []float64{100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000}
+
+type Metrics struct {
+	JobsProcessed  *prommetrics.Counter
+	JobsFailed     *prommetrics.Counter
+	ProcessingTime *prommetrics.Histogram
+}
+
+// NewMetrics регистрирует метрики воркера. Gauge опрашивают пул и очередь в момент сбора,
+// поэтому отражают текущее состояние, а не последнее сохранённое в WorkerStats.
+func NewMetrics(reg *prommetrics.Registry, pool *WorkerPool, consumer queue.RabbitMQConsumer) *Metrics {
+	reg.NewGaugeFunc("analysis_active_workers", "Workers currently running in the pool.", func() float64 {
+		return float64(pool.GetActiveWorkers())
+	})
+	reg.NewGaugeFunc("analysis_queue_length", "Messages waiting in the RabbitMQ analysis queue.", func() float64 {
+		length, err := consumer.GetQueueLength()
+		if err != nil {
+			return math.NaN()
+		}
+		return float64(length)
+	})
+
+	return &Metrics{
+		JobsProcessed:  reg.NewCounter("analysis_jobs_processed_total", "Analysis messages processed successfully."),
+		JobsFailed:     reg.NewCounter("analysis_jobs_failed_total", "Analysis messages that failed processing."),
+		ProcessingTime: reg.NewHistogram("analysis_processing_time_ms", "Time spent in AnalyzeWork, milliseconds.", ProcessingTimeBuckets),
+	}
+}
@@ -2,6 +2,7 @@ package queue
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -13,6 +14,8 @@ type RabbitMQMessage struct {
 	Timestamp time.Time
 	Ack       func(multiple bool) error
 	Nack      func(multiple bool, requeue bool) error
+	// Заголовки сообщения, в том числе контекст трассировки отправителя
+	Headers amqp.Table
 }
 
 type RabbitMQConsumer interface {
@@ -22,18 +25,33 @@ type RabbitMQConsumer interface {
 }
 
 type rabbitMQConsumer struct {
-	channel     *amqp.Channel // amqp091-go использует amqp.Channel
+	channel *amqp.Channel // amqp091-go использует amqp.Channel
+	// channel.Cancel; подменяется в тестах
+	cancel      func(consumer string, noWait bool) error
 	queue       string
 	consumerTag string
-	logger      zerolog.Logger
+	// Сколько при остановке ждать доставки, уже отправленные брокером, чтобы вернуть их в очередь
+	drainTimeout time.Duration
+	// Ограничение частоты выдачи сообщений (nil — без ограничения)
+	limiter *rateLimiter
+	logger  zerolog.Logger
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	// Закрывается, когда горутина доставки завершилась; nil до Consume
+	done chan struct{}
 }
 
-func NewRabbitMQConsumer(channel *amqp.Channel, queue, consumerTag string, logger zerolog.Logger) RabbitMQConsumer {
+func NewRabbitMQConsumer(channel *amqp.Channel, queue, consumerTag string, drainTimeout time.Duration, rateLimit RateLimit, logger zerolog.Logger) RabbitMQConsumer {
 	return &rabbitMQConsumer{
-		channel:     channel,
-		queue:       queue,
-		consumerTag: consumerTag,
-		logger:      logger,
+		channel:      channel,
+		cancel:       channel.Cancel,
+		queue:        queue,
+		consumerTag:  consumerTag,
+		drainTimeout: drainTimeout,
+		limiter:      newRateLimiter(rateLimit),
+		logger:       logger,
+		stop:         make(chan struct{}),
 	}
 }
 
@@ -61,14 +79,19 @@ func (c *rabbitMQConsumer) Consume(ctx context.Context) (<-chan RabbitMQMessage,
 	}
 
 	output := make(chan RabbitMQMessage)
+	c.done = make(chan struct{})
 
 	go func() {
+		defer close(c.done)
 		defer close(output)
 
 		for {
 			select {
 			case <-ctx.Done():
-				c.logger.Info().Msg("Stopping RabbitMQ consumer")
+				c.shutdown(msgs)
+				return
+			case <-c.stop:
+				c.shutdown(msgs)
 				return
 			case msg, ok := <-msgs:
 				if !ok {
@@ -76,9 +99,17 @@ func (c *rabbitMQConsumer) Consume(ctx context.Context) (<-chan RabbitMQMessage,
 					return
 				}
 
+				// Сообщение ждёт здесь, не подтверждённым: при остановке оно вернётся в очередь
+				if !c.limiter.wait(ctx, c.stop) {
+					msg.Nack(false, true)
+					c.shutdown(msgs)
+					return
+				}
+
 				rabbitMsg := RabbitMQMessage{
 					Body:      msg.Body,
 					Timestamp: msg.Timestamp,
+					Headers:   msg.Headers,
 					Ack:       msg.Ack,
 					Nack:      msg.Nack,
 				}
@@ -87,6 +118,11 @@ func (c *rabbitMQConsumer) Consume(ctx context.Context) (<-chan RabbitMQMessage,
 				case output <- rabbitMsg:
 				case <-ctx.Done():
 					msg.Nack(false, true)
+					c.shutdown(msgs)
+					return
+				case <-c.stop:
+					msg.Nack(false, true)
+					c.shutdown(msgs)
 					return
 				}
 			}
@@ -101,6 +137,65 @@ func (c *rabbitMQConsumer) Consume(ctx context.Context) (<-chan RabbitMQMessage,
 	return output, nil
 }
 
+// shutdown отменяет подписку на брокере, чтобы новые сообщения ушли другим экземплярам,
+// и возвращает в очередь доставки, которые брокер успел отправить до отмены
+func (c *rabbitMQConsumer) shutdown(msgs <-chan amqp.Delivery) {
+	c.logger.Info().Msg("Stopping RabbitMQ consumer")
+
+	if err := c.cancel(c.consumerTag, false); err != nil {
+		// Подписка жива, и канал доставок не закроется: возвращаем то, что уже в буфере, не дожидаясь закрытия
+		requeued := c.requeueBuffered(msgs)
+		c.logger.Error().Err(err).Int("requeued", requeued).Msg("Failed to cancel RabbitMQ consumer")
+		return
+	}
+
+	// После отмены библиотека закрывает канал доставок, отдав уже полученные
+	timer := time.NewTimer(c.drainTimeout)
+	defer timer.Stop()
+
+	requeued := 0
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				if requeued > 0 {
+					c.logger.Info().Int("requeued", requeued).Msg("In-flight deliveries returned to queue")
+				}
+				return
+			}
+			if err := msg.Nack(false, true); err != nil {
+				c.logger.Error().Err(err).Msg("Failed to requeue in-flight delivery")
+				continue
+			}
+			requeued++
+		case <-timer.C:
+			c.logger.Warn().
+				Int("requeued", requeued).
+				Dur("drain_timeout", c.drainTimeout).
+				Msg("Timed out draining RabbitMQ deliveries; the rest will be redelivered after channel close")
+			return
+		}
+	}
+}
+
+// requeueBuffered возвращает в очередь доставки, лежащие в буфере канала на момент вызова; вернувшиеся
+// повторно после Nack не трогает, иначе при живой подписке цикл не закончится
+func (c *rabbitMQConsumer) requeueBuffered(msgs <-chan amqp.Delivery) int {
+	requeued := 0
+	for n := len(msgs); n > 0; n-- {
+		msg, ok := <-msgs
+		if !ok {
+			break
+		}
+		if err := msg.Nack(false, true); err != nil {
+			c.logger.Error().Err(err).Msg("Failed to requeue in-flight delivery")
+			continue
+		}
+		requeued++
+	}
+	return requeued
+}
+
 func (c *rabbitMQConsumer) GetQueueLength() (int, error) {
 	queue, err := c.channel.QueueDeclarePassive(
 		c.queue, // name
@@ -117,12 +212,12 @@ func (c *rabbitMQConsumer) GetQueueLength() (int, error) {
 	return queue.Messages, nil
 }
 
+// Close отменяет подписку и дожидается возврата в очередь полученных, но не переданных в обработку
+// сообщений; сообщения, уже переданные обработчикам, подтверждаются ими как обычно
 func (c *rabbitMQConsumer) Close() error {
-	if c.channel != nil {
-		if err := c.channel.Cancel(c.consumerTag, false); err != nil {
-			c.logger.Error().Err(err).Msg("Failed to cancel RabbitMQ consumer")
-		}
-
+	c.stopOnce.Do(func() { close(c.stop) })
+	if c.done != nil {
+		<-c.done
 	}
 
 	c.logger.Info().Msg("RabbitMQ consumer closed")
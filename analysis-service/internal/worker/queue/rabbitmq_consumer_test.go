@@ -0,0 +1,99 @@
+package queue
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog"
+)
+
+// fakeAcknowledger записывает Nack доставок по их delivery tag
+type fakeAcknowledger struct {
+	mu       sync.Mutex
+	requeued []uint64
+	dropped  []uint64
+}
+
+func (a *fakeAcknowledger) Ack(uint64, bool) error { return nil }
+
+func (a *fakeAcknowledger) Nack(tag uint64, _ bool, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if requeue {
+		a.requeued = append(a.requeued, tag)
+	} else {
+		a.dropped = append(a.dropped, tag)
+	}
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error { return a.Nack(tag, false, requeue) }
+
+func bufferedDeliveries(ack amqp.Acknowledger, n int) chan amqp.Delivery {
+	msgs := make(chan amqp.Delivery, n)
+	for i := 1; i <= n; i++ {
+		msgs <- amqp.Delivery{Acknowledger: ack, DeliveryTag: uint64(i)}
+	}
+	return msgs
+}
+
+// shutdownWithin вызывает shutdown и падает, если он не вернулся за timeout
+func shutdownWithin(t *testing.T, c *rabbitMQConsumer, msgs <-chan amqp.Delivery, timeout time.Duration) {
+	t.Helper()
+
+	done := make(chan struct{})
+	go func() {
+		c.shutdown(msgs)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		t.Fatal("shutdown did not return")
+	}
+}
+
+func TestShutdownRequeuesBufferedWhenCancelFails(t *testing.T) {
+	ack := &fakeAcknowledger{}
+	// Канал доставок не закрывается: подписка на брокере осталась
+	msgs := bufferedDeliveries(ack, 3)
+	c := &rabbitMQConsumer{
+		cancel:       func(string, bool) error { return errors.New("channel/connection is not open") },
+		consumerTag:  "analysis",
+		drainTimeout: time.Minute,
+		logger:       zerolog.Nop(),
+	}
+
+	shutdownWithin(t, c, msgs, time.Second)
+
+	if len(ack.requeued) != 3 || len(ack.dropped) != 0 {
+		t.Fatalf("requeued %v, dropped %v; want all 3 requeued", ack.requeued, ack.dropped)
+	}
+	if len(msgs) != 0 {
+		t.Fatalf("%d deliveries left in buffer", len(msgs))
+	}
+}
+
+func TestShutdownRequeuesDrainedAfterCancel(t *testing.T) {
+	ack := &fakeAcknowledger{}
+	msgs := bufferedDeliveries(ack, 2)
+	c := &rabbitMQConsumer{
+		// Как amqp091-go: после отмены канал доставок закрывается, отдав полученные
+		cancel: func(string, bool) error {
+			close(msgs)
+			return nil
+		},
+		consumerTag:  "analysis",
+		drainTimeout: time.Minute,
+		logger:       zerolog.Nop(),
+	}
+
+	shutdownWithin(t, c, msgs, time.Second)
+
+	if len(ack.requeued) != 2 || len(ack.dropped) != 0 {
+		t.Fatalf("requeued %v, dropped %v; want both requeued", ack.requeued, ack.dropped)
+	}
+}
@@ -11,18 +11,30 @@ import (
 type RabbitMQPublisher interface {
 	Publish(ctx context.Context, exchange, routingKey string, body []byte) error
 	PublishWithDelay(ctx context.Context, exchange, routingKey string, body []byte, delay time.Duration) error
+	// PublishToDLQ сохраняет необрабатываемое сообщение в DLQ с причиной ошибки
+	PublishToDLQ(ctx context.Context, body []byte, reason string) error
 	Close() error
 }
 
+type PublisherConfig struct {
+	// Очередь для необрабатываемых сообщений ("" — PublishToDLQ ничего не делает)
+	DLQName string
+	// Откуда пришли сообщения, которые попадают в DLQ, — нужно для повторной публикации
+	SourceQueue      string
+	SourceRoutingKey string
+}
+
 type rabbitMQPublisher struct {
 	channel *amqp.Channel // amqp091-go использует amqp.Channel
 	logger  zerolog.Logger
+	config  PublisherConfig
 }
 
-func NewRabbitMQPublisher(channel *amqp.Channel, logger zerolog.Logger) RabbitMQPublisher {
+func NewRabbitMQPublisher(channel *amqp.Channel, logger zerolog.Logger, config PublisherConfig) RabbitMQPublisher {
 	return &rabbitMQPublisher{
 		channel: channel,
 		logger:  logger,
+		config:  config,
 	}
 }
 
@@ -71,6 +83,34 @@ func (p *rabbitMQPublisher) PublishWithDelay(ctx context.Context, exchange, rout
 	)
 }
 
+func (p *rabbitMQPublisher) PublishToDLQ(ctx context.Context, body []byte, reason string) error {
+	if p.config.DLQName == "" {
+		return nil
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return p.channel.PublishWithContext(
+		publishCtx,
+		"",               // exchange по умолчанию маршрутизирует по имени очереди
+		p.config.DLQName, // routing key
+		false,            // mandatory
+		false,            // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			Body:         body,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+			Headers: amqp.Table{
+				"x-original-routing-key": p.config.SourceRoutingKey,
+				"x-original-queue":       p.config.SourceQueue,
+				"x-error":                reason,
+			},
+		},
+	)
+}
+
 func (p *rabbitMQPublisher) Close() error {
 	p.logger.Info().Msg("RabbitMQ publisher closed")
 	return nil
@@ -0,0 +1,76 @@
+package queue
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimit — потолок выдачи сообщений обработчикам. Пока сообщение ждёт своей очереди,
+// следующие остаются в RabbitMQ, поэтому всплеск событий копится в брокере, а не в памяти.
+type RateLimit struct {
+	// Сообщений в секунду (0 — без ограничения)
+	PerSecond float64
+	// Сколько сообщений можно выдать подряд после простоя
+	Burst int
+}
+
+// rateLimiter — корзина токенов; используется только горутиной доставки, поэтому без блокировок
+type rateLimiter struct {
+	perSecond float64
+	burst     float64
+	tokens    float64
+	last      time.Time
+}
+
+// newRateLimiter возвращает nil, если ограничение выключено
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.PerSecond <= 0 {
+		return nil
+	}
+
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		perSecond: limit.PerSecond,
+		burst:     burst,
+		tokens:    burst,
+		last:      time.Now(),
+	}
+}
+
+// wait ждёт токен; false — ожидание прервано ctx или stop
+func (l *rateLimiter) wait(ctx context.Context, stop <-chan struct{}) bool {
+	if l == nil {
+		return true
+	}
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.perSecond
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return true
+	}
+
+	delay := time.Duration((1 - l.tokens) / l.perSecond * float64(time.Second))
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		// Накопленная за ожидание доля токена израсходована на это сообщение
+		l.tokens = 0
+		l.last = time.Now()
+		return true
+	case <-ctx.Done():
+		return false
+	case <-stop:
+		return false
+	}
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
+	"github.com/rs/zerolog"
+)
+
+type RetrySweeperConfig struct {
+	Interval  time.Duration
+	BatchSize int
+}
+
+// RetrySweeper периодически повторяет анализы из очереди повторов, время которых наступило
+type RetrySweeper struct {
+	analysisService service.AnalysisService
+	logger          zerolog.Logger
+	config          RetrySweeperConfig
+	cancel          context.CancelFunc
+	wg              sync.WaitGroup
+}
+
+func NewRetrySweeper(analysisService service.AnalysisService, logger zerolog.Logger, config RetrySweeperConfig) *RetrySweeper {
+	return &RetrySweeper{
+		analysisService: analysisService,
+		logger:          logger,
+		config:          config,
+	}
+}
+
+func (s *RetrySweeper) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.sweep(ctx)
+			}
+		}
+	}()
+
+	s.logger.Info().
+		Dur("interval", s.config.Interval).
+		Int("batch_size", s.config.BatchSize).
+		Msg("Retry sweeper started")
+}
+
+// sweep забирает пачки, пока очередь не опустеет, чтобы накопившиеся повторы не ждали следующего тика
+func (s *RetrySweeper) sweep(ctx context.Context) {
+	for ctx.Err() == nil {
+		result, err := s.analysisService.RetryDueAnalyses(ctx, s.config.BatchSize)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("Failed to process retry queue")
+			return
+		}
+		if result.Total < s.config.BatchSize {
+			return
+		}
+	}
+}
+
+// Stop дожидается завершения текущего прохода
+func (s *RetrySweeper) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	s.logger.Info().Msg("Retry sweeper stopped")
+}
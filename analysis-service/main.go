@@ -14,12 +14,9 @@ import (
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/config"
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/database"
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/repository"
-	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service"
-	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/analyzer"
-	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/service/integration"
-	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/worker"
-	"github.com/RubachokBoss/plagiarism-checker/analysis-service/internal/worker/queue"
 	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/logger"
+	"github.com/RubachokBoss/plagiarism-checker/analysis-service/pkg/tracing"
+	"github.com/rs/zerolog"
 )
 
 func main() {
@@ -52,6 +49,18 @@ func main() {
 		case "worker":
 			runWorker()
 			return
+		case "dlq-replay":
+			limit := 0
+			if len(os.Args) > 2 {
+				v, err := strconv.Atoi(os.Args[2])
+				if err != nil || v < 0 {
+					fmt.Fprintln(os.Stderr, "invalid limit:", os.Args[2])
+					os.Exit(2)
+				}
+				limit = v
+			}
+			runDLQReplay(limit)
+			return
 		}
 	}
 	migrateCmd := flag.NewFlagSet("migrate", flag.ExitOnError)
@@ -81,18 +90,10 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to load configuration")
 	}
 
-	db, err := database.NewPostgres(cfg.Database)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to database")
-	}
-	defer db.Close()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	shutdownTracing := initTracing(cfg, log)
 
-	if err := db.PingContext(ctx); err != nil {
-		log.Fatal().Err(err).Msg("Failed to ping database")
-	}
+	db := runStartupChecks(cfg, log)
+	defer db.Close()
 
 	log.Info().Msg("Database connection established")
 
@@ -125,9 +126,27 @@ func main() {
 		log.Error().Err(err).Msg("Failed to shutdown gracefully")
 	}
 
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	log.Info().Msg("Analysis Service stopped")
 }
 
+// initTracing включает экспорт трасс из tracing; возвращает функцию, отправляющую накопленные спаны
+func initTracing(cfg *config.Config, log zerolog.Logger) func(context.Context) error {
+	shutdown, err := tracing.Init(context.Background(), tracing.Config{
+		Enabled:     cfg.Tracing.Enabled,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize tracing")
+	}
+	return shutdown
+}
+
 func runMigrations(direction string) {
 	log := logger.New()
 	cfg, err := config.Load()
@@ -168,23 +187,15 @@ func runMigrationsForce(version int) {
 	log.Info().Int("version", version).Msg("Migration version forced successfully")
 }
 
-func runWorker() {
+// runDLQReplay возвращает сообщения из DLQ в обработку (limit 0 — все, что были в очереди)
+func runDLQReplay(limit int) {
 	log := logger.New()
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to load configuration")
 	}
-
-	db, err := database.NewPostgres(cfg.Database)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to database")
-	}
-	defer db.Close()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := db.PingContext(ctx); err != nil {
-		log.Fatal().Err(err).Msg("Failed to ping database")
+	if cfg.RabbitMQ.DLQName == "" {
+		log.Fatal().Msg("rabbitmq.dlq_name is not configured")
 	}
 
 	rabbitMQRepo, err := repository.NewRabbitMQRepository(cfg.RabbitMQ.URL, log)
@@ -193,86 +204,34 @@ func runWorker() {
 	}
 	defer rabbitMQRepo.Close()
 
-	if err := rabbitMQRepo.SetupQueue(
-		cfg.RabbitMQ.Exchange,
-		cfg.RabbitMQ.QueueName,
-		cfg.RabbitMQ.RoutingKey,
-	); err != nil {
-		log.Fatal().Err(err).Msg("Failed to setup RabbitMQ queue")
-	}
-
-	rabbitMQPublisher := queue.NewRabbitMQPublisher(rabbitMQRepo.Channel(), log)
-	rabbitMQConsumer := queue.NewRabbitMQConsumer(
-		rabbitMQRepo.Channel(),
-		cfg.RabbitMQ.QueueName,
-		cfg.RabbitMQ.ConsumerTag,
-		log,
-	)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	reportRepo := repository.NewReportRepository(db, log)
-	plagiarismRepo := repository.NewPlagiarismRepository(db, log)
+	replayed, err := rabbitMQRepo.ReplayDLQ(ctx, cfg.RabbitMQ.DLQName, cfg.RabbitMQ.Exchange, limit)
+	if err != nil {
+		log.Error().Err(err).Int("replayed", replayed).Msg("Dead-letter queue replay failed")
+		os.Exit(1)
+	}
 
-	fileClient := integration.NewFileClient(
-		cfg.Services.File.URL,
-		cfg.Services.File.Timeout,
-		cfg.Services.File.RetryCount,
-		cfg.Services.File.RetryDelay,
-		log,
-	)
+	fmt.Printf("Replayed %d message(s) from %s\n", replayed, cfg.RabbitMQ.DLQName)
+}
 
-	workClient := integration.NewWorkClient(
-		cfg.Services.Work.URL,
-		cfg.Services.Work.Timeout,
-		cfg.Services.Work.RetryCount,
-		cfg.Services.Work.RetryDelay,
-		fileClient,
-		log,
-	)
+func runWorker() {
+	log := logger.New()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load configuration")
+	}
 
-	hashComparator := analyzer.NewHashComparator(cfg.Analysis.HashAlgorithm)
-	plagiarismChecker := analyzer.NewPlagiarismChecker(
-		workClient,
-		fileClient,
-		hashComparator,
-		log,
-		analyzer.PlagiarismCheckerConfig{
-			HashAlgorithm:       cfg.Analysis.HashAlgorithm,
-			SimilarityThreshold: cfg.Analysis.SimilarityThreshold,
-			EnableDeepAnalysis:  cfg.Analysis.EnableContentAnalysis,
-			Timeout:             cfg.Analysis.Timeout,
-			MaxRetries:          cfg.Services.Work.RetryCount,
-		},
-	)
+	shutdownTracing := initTracing(cfg, log)
 
-	messageHandler := queue.NewMessageHandler(log)
-
-	analysisService := service.NewAnalysisService(
-		reportRepo,
-		plagiarismRepo,
-		workClient,
-		fileClient,
-		plagiarismChecker,
-		messageHandler,
-		rabbitMQPublisher,
-		log,
-		service.AnalysisConfig{
-			HashAlgorithm:       cfg.Analysis.HashAlgorithm,
-			SimilarityThreshold: cfg.Analysis.SimilarityThreshold,
-			EnableDeepAnalysis:  cfg.Analysis.EnableContentAnalysis,
-			Timeout:             cfg.Analysis.Timeout,
-			MaxRetries:          cfg.Services.Work.RetryCount,
-			BatchSize:           cfg.Analysis.BatchSize,
-		},
-	)
+	db := runStartupChecks(cfg, log)
+	defer db.Close()
 
-	workerPool := worker.NewWorkerPool(cfg.Analysis.MaxWorkers, log)
-	analysisWorker := worker.NewAnalysisWorker(
-		workerPool,
-		rabbitMQConsumer,
-		reportRepo,
-		analysisService,
-		log,
-	)
+	analysisWorker, err := app.NewWorker(cfg, log, db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create analysis worker")
+	}
 
 	ctxRun, stop := signal.NotifyContext(context.Background(),
 		syscall.SIGINT,
@@ -292,7 +251,15 @@ func runWorker() {
 	<-ctxRun.Done()
 	log.Info().Msg("Shutting down standalone worker...")
 
-	if err := analysisWorker.Stop(); err != nil {
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := analysisWorker.Shutdown(shutdownCtx); err != nil {
 		log.Error().Err(err).Msg("Failed to stop analysis worker gracefully")
 	}
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
 }
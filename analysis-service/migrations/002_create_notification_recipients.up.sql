@@ -0,0 +1,12 @@
+-- Получатели уведомлений о плагиате по заданиям
+CREATE TABLE IF NOT EXISTS assignment_notification_recipients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    assignment_id UUID NOT NULL,
+    recipient VARCHAR(500) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('email', 'webhook')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(assignment_id, recipient)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_recipients_assignment_id ON assignment_notification_recipients(assignment_id);
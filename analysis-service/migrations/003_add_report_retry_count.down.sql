@@ -0,0 +1,7 @@
+UPDATE reports SET status = 'failed' WHERE status = 'abandoned';
+
+ALTER TABLE reports DROP CONSTRAINT IF EXISTS reports_status_check;
+ALTER TABLE reports ADD CONSTRAINT reports_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed'));
+
+ALTER TABLE reports DROP COLUMN IF EXISTS retry_count;
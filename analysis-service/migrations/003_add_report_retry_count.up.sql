@@ -0,0 +1,6 @@
+-- Счётчик повторных попыток анализа и терминальный статус abandoned
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS retry_count INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE reports DROP CONSTRAINT IF EXISTS reports_status_check;
+ALTER TABLE reports ADD CONSTRAINT reports_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'abandoned'));
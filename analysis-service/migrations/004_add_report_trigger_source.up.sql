@@ -0,0 +1,2 @@
+-- Источник запуска анализа: api, async, batch, event, retry
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS trigger_source VARCHAR(50) NOT NULL DEFAULT 'unknown';
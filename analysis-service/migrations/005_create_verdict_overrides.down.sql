@@ -0,0 +1 @@
+DROP TABLE IF EXISTS verdict_overrides;
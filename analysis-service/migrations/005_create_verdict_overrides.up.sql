@@ -0,0 +1,15 @@
+-- Ручные изменения вердикта преподавателем
+CREATE TABLE IF NOT EXISTS verdict_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    report_id UUID NOT NULL REFERENCES reports(id) ON DELETE CASCADE,
+    assignment_id UUID NOT NULL,
+    -- Вердикт алгоритма до первого ручного изменения
+    algorithm_flag BOOLEAN NOT NULL,
+    overridden_flag BOOLEAN NOT NULL,
+    overridden_by VARCHAR(255),
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_verdict_overrides_report_id ON verdict_overrides(report_id);
+CREATE INDEX IF NOT EXISTS idx_verdict_overrides_assignment_id ON verdict_overrides(assignment_id);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS analysis_audit_log;
@@ -0,0 +1,20 @@
+-- Журнал решений анализа для аудита. Записи только добавляются и не зависят от таблицы reports
+CREATE TABLE IF NOT EXISTS analysis_audit_log (
+    id UUID PRIMARY KEY,
+    report_id UUID NOT NULL,
+    work_id UUID NOT NULL,
+    assignment_id UUID NOT NULL,
+    student_id UUID NOT NULL,
+    plagiarism_flag BOOLEAN NOT NULL,
+    match_percentage INTEGER NOT NULL DEFAULT 0,
+    original_work_id UUID,
+    method VARCHAR(100) NOT NULL,
+    threshold INTEGER NOT NULL,
+    compared_count INTEGER NOT NULL DEFAULT 0,
+    matched_work_ids TEXT[] NOT NULL DEFAULT '{}',
+    trigger_source VARCHAR(50) NOT NULL DEFAULT 'unknown',
+    decided_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_audit_log_work_id ON analysis_audit_log(work_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_audit_log_decided_at ON analysis_audit_log(decided_at);
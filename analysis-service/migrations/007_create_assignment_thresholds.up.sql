@@ -0,0 +1,7 @@
+-- Порог плагиата для отдельного задания; без строки действует analysis.similarity_threshold
+CREATE TABLE IF NOT EXISTS assignment_thresholds (
+    assignment_id UUID PRIMARY KEY,
+    threshold INTEGER NOT NULL CHECK (threshold BETWEEN 0 AND 100),
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
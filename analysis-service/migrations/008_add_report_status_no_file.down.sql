@@ -0,0 +1,5 @@
+UPDATE reports SET status = 'failed' WHERE status = 'no_file';
+
+ALTER TABLE reports DROP CONSTRAINT IF EXISTS reports_status_check;
+ALTER TABLE reports ADD CONSTRAINT reports_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'abandoned'));
@@ -0,0 +1,4 @@
+-- Терминальный статус no_file: к работе так и не загрузили файл (file_id = 'pending')
+ALTER TABLE reports DROP CONSTRAINT IF EXISTS reports_status_check;
+ALTER TABLE reports ADD CONSTRAINT reports_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'abandoned', 'no_file'));
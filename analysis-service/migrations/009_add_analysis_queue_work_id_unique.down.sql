@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_analysis_queue_due;
+DROP INDEX IF EXISTS idx_analysis_queue_work_id;
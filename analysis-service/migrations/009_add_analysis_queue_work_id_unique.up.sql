@@ -0,0 +1,7 @@
+-- Одна запись очереди повторов на работу: неудачи увеличивают attempts у существующей записи
+DELETE FROM analysis_queue a
+USING analysis_queue b
+WHERE a.work_id = b.work_id AND a.created_at < b.created_at;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_analysis_queue_work_id ON analysis_queue(work_id);
+CREATE INDEX IF NOT EXISTS idx_analysis_queue_due ON analysis_queue(status, scheduled_at);
@@ -0,0 +1,4 @@
+-- Поиск и пересчёт отчётов по версии алгоритма анализа
+CREATE INDEX IF NOT EXISTS idx_reports_analysis_version
+    ON reports ((details->'analysis_metadata'->>'analysis_version'))
+    WHERE status = 'completed';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_reports_created_at_id;
@@ -0,0 +1,2 @@
+-- Ключ постраничного поиска по курсору: ORDER BY created_at DESC, id DESC
+CREATE INDEX IF NOT EXISTS idx_reports_created_at_id ON reports(created_at DESC, id DESC);
@@ -0,0 +1,19 @@
+-- Подписки внешних систем (LMS) на завершение анализа
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url VARCHAR(500) NOT NULL,
+    -- Ключ HMAC-подписи тела запроса (заголовок X-Webhook-Signature)
+    secret VARCHAR(255) NOT NULL,
+    -- NULL — события всех заданий
+    assignment_id UUID,
+    -- NULL — любой вердикт, иначе только отчёты с этим plagiarism_flag
+    plagiarism_flag BOOLEAN,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    -- Неудачные доставки подряд; после webhooks.max_failures подписка отключается
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    last_delivery_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_active ON webhook_subscriptions(active, assignment_id);
@@ -0,0 +1,11 @@
+-- Отпечатки winnowing работ: повторный анализ и сравнение с той же работой их не пересчитывают
+CREATE TABLE IF NOT EXISTS work_fingerprints (
+    work_id UUID NOT NULL,
+    -- Тип содержимого и параметры отпечатков (k, окно); отпечатки с разными параметрами не сравнимы
+    method VARCHAR(100) NOT NULL,
+    -- Хеш файла, по которому построены отпечатки; при замене файла запись перезаписывается
+    file_hash VARCHAR(128) NOT NULL,
+    fingerprints BIGINT[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (work_id, method)
+);
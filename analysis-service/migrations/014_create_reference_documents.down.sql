@@ -0,0 +1,2 @@
+DELETE FROM work_fingerprints WHERE work_id IN (SELECT id FROM reference_documents);
+DROP TABLE IF EXISTS reference_documents;
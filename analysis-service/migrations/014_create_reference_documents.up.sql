@@ -0,0 +1,18 @@
+-- База сравнения из известных источников (учебники, статьи, открытые репозитории)
+CREATE TABLE IF NOT EXISTS reference_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- Файл, заранее загруженный в file-service
+    file_id VARCHAR(255) NOT NULL,
+    file_hash VARCHAR(128) NOT NULL,
+    file_size BIGINT NOT NULL DEFAULT 0,
+    title VARCHAR(500) NOT NULL DEFAULT '',
+    source VARCHAR(255) NOT NULL DEFAULT '',
+    category VARCHAR(255) NOT NULL DEFAULT '',
+    -- text или code: от типа зависит извлечение текста и отпечатки в work_fingerprints (work_id = id документа)
+    content_type VARCHAR(20) NOT NULL DEFAULT 'text' CHECK (content_type IN ('text', 'code')),
+    fingerprint_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reference_documents_file_hash ON reference_documents(file_hash, content_type);
+CREATE INDEX IF NOT EXISTS idx_reference_documents_source_category ON reference_documents(source, category);
@@ -0,0 +1,62 @@
+// Package buildinfo — версия сборки сервиса. Значения подставляются при сборке:
+//
+//	go build -ldflags "-X <module>/pkg/buildinfo.Version=1.4.0 -X <module>/pkg/buildinfo.Commit=$(git rev-parse HEAD) -X <module>/pkg/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Без -ldflags коммит и время берутся из данных VCS, которые go build встраивает сам, если они есть.
+package buildinfo
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+)
+
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info — ответ GET /version; в нём нет ничего, кроме сведений о сборке
+type Info struct {
+	Service   string `json:"service"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+	// Действующая версия алгоритма анализа (analysis.algorithm_version или встроенная)
+	AnalysisVersion string `json:"analysis_version"`
+}
+
+func Get(service, analysisVersion string) Info {
+	info := Info{
+		Service:         service,
+		Version:         Version,
+		Commit:          Commit,
+		BuildTime:       BuildTime,
+		GoVersion:       runtime.Version(),
+		AnalysisVersion: analysisVersion,
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "unknown":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "unknown":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+	return info
+}
+
+// Handler отвечает на GET /version сведениями о сборке service
+func Handler(service, analysisVersion string) http.HandlerFunc {
+	info := Get(service, analysisVersion)
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(info)
+	}
+}
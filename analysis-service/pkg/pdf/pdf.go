@@ -0,0 +1,221 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Минимальный генератор текстовых PDF (A4, Helvetica) без внешних зависимостей.
+// Страницы пишутся в поток сразу после заполнения, в памяти держится только текущая.
+
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	margin     = 50.0
+	lineGap    = 1.35
+)
+
+// Номера зарезервированных объектов; каталог и дерево страниц пишутся в Close.
+const (
+	objCatalog = 1
+	objPages   = 2
+	objFont    = 3
+	objBold    = 4
+)
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type Document struct {
+	out     *countingWriter
+	offsets map[int]int64
+	nextObj int
+	pageIDs []int
+	content bytes.Buffer
+	y       float64
+	err     error
+}
+
+func NewDocument(w io.Writer) (*Document, error) {
+	d := &Document{
+		out:     &countingWriter{w: w},
+		offsets: make(map[int]int64),
+		nextObj: objBold + 1,
+		y:       pageHeight - margin,
+	}
+
+	d.write("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+	d.writeObject(objFont, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	d.writeObject(objBold, "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	if d.err != nil {
+		return nil, fmt.Errorf("failed to write pdf header: %w", d.err)
+	}
+	return d, nil
+}
+
+// Text выводит строку с переносом по ширине страницы; при нехватке места начинается новая страница.
+func (d *Document) Text(text string, size float64, bold bool) {
+	if d.err != nil {
+		return
+	}
+
+	// Средняя ширина символа Helvetica ~0.5 от кегля — для переноса этого достаточно
+	maxChars := int((pageWidth - 2*margin) / (size * 0.5))
+	for _, line := range wrap(encode(text), maxChars) {
+		if d.y-size < margin {
+			d.NewPage()
+		}
+		d.y -= size * lineGap
+
+		font := "F1"
+		if bold {
+			font = "F2"
+		}
+		fmt.Fprintf(&d.content, "BT /%s %.1f Tf %.1f %.1f Td (%s) Tj ET\n", font, size, margin, d.y, escape(line))
+	}
+}
+
+// Space добавляет вертикальный отступ.
+func (d *Document) Space(height float64) {
+	d.y -= height
+}
+
+// NewPage дописывает текущую страницу в поток и начинает следующую.
+func (d *Document) NewPage() {
+	if d.err != nil {
+		return
+	}
+	d.flushPage()
+	d.y = pageHeight - margin
+}
+
+// Close завершает документ: дерево страниц, каталог, xref и trailer.
+func (d *Document) Close() error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.content.Len() > 0 || len(d.pageIDs) == 0 {
+		d.flushPage()
+	}
+
+	kids := make([]string, 0, len(d.pageIDs))
+	for _, id := range d.pageIDs {
+		kids = append(kids, fmt.Sprintf("%d 0 R", id))
+	}
+	d.writeObject(objPages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pageIDs)))
+	d.writeObject(objCatalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", objPages))
+
+	xrefOffset := d.out.n
+	d.write(fmt.Sprintf("xref\n0 %d\n0000000000 65535 f \n", d.nextObj))
+	for id := 1; id < d.nextObj; id++ {
+		d.write(fmt.Sprintf("%010d 00000 n \n", d.offsets[id]))
+	}
+	d.write(fmt.Sprintf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", d.nextObj, objCatalog, xrefOffset))
+
+	return d.err
+}
+
+func (d *Document) flushPage() {
+	contentID := d.allocObject()
+	pageID := d.allocObject()
+
+	d.writeObject(contentID, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", d.content.Len(), d.content.String()))
+	d.writeObject(pageID, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+		objPages, pageWidth, pageHeight, objFont, objBold, contentID,
+	))
+
+	d.pageIDs = append(d.pageIDs, pageID)
+	d.content.Reset()
+}
+
+func (d *Document) allocObject() int {
+	id := d.nextObj
+	d.nextObj++
+	return id
+}
+
+func (d *Document) writeObject(id int, body string) {
+	d.offsets[id] = d.out.n
+	d.write(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", id, body))
+}
+
+func (d *Document) write(s string) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = io.WriteString(d.out, s)
+}
+
+func wrap(text string, maxChars int) []string {
+	if maxChars < 1 || len(text) <= maxChars {
+		return []string{text}
+	}
+
+	var lines []string
+	for len(text) > maxChars {
+		cut := strings.LastIndexByte(text[:maxChars], ' ')
+		if cut <= 0 {
+			cut = maxChars
+		}
+		lines = append(lines, text[:cut])
+		text = strings.TrimLeft(text[cut:], " ")
+	}
+	return append(lines, text)
+}
+
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return r.Replace(s)
+}
+
+// encode переводит строку в однобайтовую WinAnsi; кириллица транслитерируется,
+// так как стандартные шрифты PDF её не содержат.
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r < 0x20:
+		case r < 0x80 || (r >= 0xA0 && r <= 0xFF):
+			b.WriteByte(byte(r))
+		default:
+			if t, ok := cyrillic[r]; ok {
+				b.WriteString(t)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+var cyrillic = func() map[rune]string {
+	lower := []string{
+		"a", "b", "v", "g", "d", "e", "zh", "z", "i", "y", "k", "l", "m", "n", "o", "p",
+		"r", "s", "t", "u", "f", "kh", "ts", "ch", "sh", "shch", "", "y", "", "e", "yu", "ya",
+	}
+	m := make(map[rune]string, 66)
+	for i, t := range lower {
+		m['а'+rune(i)] = t
+		m['А'+rune(i)] = t
+		if t != "" {
+			m['А'+rune(i)] = strings.ToUpper(t[:1]) + t[1:]
+		}
+	}
+	m['ё'] = "e"
+	m['Ё'] = "E"
+	return m
+}()
@@ -0,0 +1,194 @@
+// Package prommetrics — счётчики, gauge и гистограммы без меток, которые отдаются
+// в текстовом формате Prometheus (exposition format 0.0.4).
+package prommetrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+type Registry struct {
+	mu      sync.Mutex
+	names   map[string]bool
+	metrics []metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// Имена уникальны: повторная регистрация — ошибка в коде, поэтому panic
+func (reg *Registry) register(name string, m metric) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if reg.names[name] {
+		panic(fmt.Sprintf("prommetrics: metric %s already registered", name))
+	}
+	reg.names[name] = true
+	reg.metrics = append(reg.metrics, m)
+}
+
+func (reg *Registry) Handler(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	metrics := append([]metric(nil), reg.metrics...)
+	reg.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+	_ = bw.Flush()
+}
+
+// Counter — монотонно растущий счётчик. Методы nil-safe, чтобы код мог вызывать их
+// без проверки, включены ли метрики.
+type Counter struct {
+	name string
+	help string
+	mu   sync.Mutex
+	v    float64
+}
+
+func (reg *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	reg.register(name, c)
+	return c
+}
+
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+func (c *Counter) Add(v float64) {
+	if c == nil || v < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.v += v
+	c.mu.Unlock()
+}
+
+func (c *Counter) Value() float64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.v
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	writeSample(w, c.name, "", c.Value())
+}
+
+// GaugeFunc вычисляет значение при каждом опросе
+type GaugeFunc struct {
+	name string
+	help string
+	fn   func() float64
+}
+
+func (reg *Registry) NewGaugeFunc(name, help string, fn func() float64) *GaugeFunc {
+	g := &GaugeFunc{name: name, help: help, fn: fn}
+	reg.register(name, g)
+	return g
+}
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+	writeSample(w, g.name, "", g.fn())
+}
+
+type Histogram struct {
+	name    string
+	help    string
+	mu      sync.Mutex
+	bounds  []float64
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+func (reg *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	bounds := append([]float64(nil), buckets...)
+	sort.Float64s(bounds)
+
+	h := &Histogram{
+		name:    name,
+		help:    help,
+		bounds:  bounds,
+		buckets: make([]uint64, len(bounds)),
+	}
+	reg.register(name, h)
+	return h
+}
+
+func (h *Histogram) Observe(v float64) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.count++
+	h.sum += v
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.buckets[i]++
+		}
+	}
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	buckets := append([]uint64(nil), h.buckets...)
+	count, sum := h.count, h.sum
+	h.mu.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+	for i, bound := range h.bounds {
+		writeSample(w, h.name+"_bucket", `le="`+formatFloat(bound)+`"`, float64(buckets[i]))
+	}
+	writeSample(w, h.name+"_bucket", `le="+Inf"`, float64(count))
+	writeSample(w, h.name+"_sum", "", sum)
+	writeSample(w, h.name+"_count", "", float64(count))
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	if help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, kind)
+}
+
+func writeSample(w *bufio.Writer, name, labels string, v float64) {
+	if labels != "" {
+		fmt.Fprintf(w, "%s{%s} %s\n", name, labels, formatFloat(v))
+		return
+	}
+	fmt.Fprintf(w, "%s %s\n", name, formatFloat(v))
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,154 @@
+// Package sharedcache — кеш строк с TTL. В памяти он свой у каждого процесса,
+// в Redis — общий для всех экземпляров сервиса и воркеров.
+package sharedcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+type Store interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	// Set с ttl 0 хранит запись без срока
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// Shared — true, если состояние общее для экземпляров
+	Shared() bool
+	Close() error
+}
+
+type Config struct {
+	// Адрес Redis (redis://[:password@]host:port/db); "" — кеш в памяти процесса
+	RedisURL string
+	// Префикс ключей, чтобы сервисы могли делить один Redis
+	KeyPrefix   string
+	DialTimeout time.Duration
+}
+
+// New выбирает Redis, если он задан и отвечает, иначе — кеш в памяти.
+// Недоступный при старте Redis не мешает запуску: сервис работает с локальным кешем.
+func New(ctx context.Context, cfg Config, logger zerolog.Logger) Store {
+	if cfg.RedisURL == "" {
+		return NewMemory()
+	}
+
+	store, err := NewRedis(ctx, cfg)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Redis is unavailable, falling back to in-memory cache")
+		return NewMemory()
+	}
+
+	logger.Info().Str("key_prefix", cfg.KeyPrefix).Msg("Using Redis as shared cache")
+	return store
+}
+
+type memoryItem struct {
+	value     string
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	items     map[string]memoryItem
+	nextSweep time.Time
+}
+
+const memorySweepInterval = time.Minute
+
+func NewMemory() Store {
+	return &memoryStore{
+		items:     make(map[string]memoryItem),
+		nextSweep: time.Now().Add(memorySweepInterval),
+	}
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[key]
+	if !ok {
+		return "", false, nil
+	}
+	if !item.expiresAt.IsZero() && time.Now().After(item.expiresAt) {
+		delete(s.items, key)
+		return "", false, nil
+	}
+	return item.value, true, nil
+}
+
+func (s *memoryStore) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expiresAt = now.Add(ttl)
+	}
+	s.items[key] = item
+
+	if now.After(s.nextSweep) {
+		for k, item := range s.items {
+			if !item.expiresAt.IsZero() && now.After(item.expiresAt) {
+				delete(s.items, k)
+			}
+		}
+		s.nextSweep = now.Add(memorySweepInterval)
+	}
+	return nil
+}
+
+func (s *memoryStore) Shared() bool { return false }
+
+func (s *memoryStore) Close() error { return nil }
+
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedis(ctx context.Context, cfg Config) (Store, error) {
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+	if cfg.DialTimeout > 0 {
+		opts.DialTimeout = cfg.DialTimeout
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	return &redisStore{client: client, prefix: cfg.KeyPrefix}, nil
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func (s *redisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+func (s *redisStore) Shared() bool { return true }
+
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
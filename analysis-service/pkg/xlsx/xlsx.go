@@ -0,0 +1,216 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Минимальный генератор XLSX (SpreadsheetML) без внешних зависимостей:
+// строки пишутся как inline strings, числа и bool — как значения ячеек; из стилей — только
+// фиксированный набор Style для целых строк.
+
+// Sheet — лист книги; значения ячеек: string, bool, целые, float и Link.
+// Прочие типы выводятся через fmt.Sprint.
+type Sheet struct {
+	Name string
+	Rows [][]interface{}
+	// Стиль строки по её индексу в Rows; строки без записи не оформляются
+	RowStyles map[int]Style
+}
+
+// Style — индекс оформления ячейки в styles.xml
+type Style int
+
+const (
+	StyleNone Style = iota
+	// Жирный шрифт, для заголовка таблицы
+	StyleHeader
+	// Красная заливка и тёмно-красный шрифт, для строк, требующих внимания
+	StyleHighlight
+)
+
+// Link — ячейка-гиперссылка; пустой Text — показывается сам URL
+type Link struct {
+	URL  string
+	Text string
+}
+
+const contentTypesHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+`
+
+const rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>
+`
+
+// styles перечисляет cellXfs в порядке констант Style
+const styles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="3"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font><font><sz val="11"/><color rgb="FF9C0006"/><name val="Calibri"/></font></fonts>
+<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill><fill><patternFill patternType="solid"><fgColor rgb="FFFFC7CE"/><bgColor indexed="64"/></patternFill></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="0" fontId="2" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/></cellXfs>
+</styleSheet>
+`
+
+// Write пишет книгу из sheets в w; имена листов должны быть уникальны.
+func Write(w io.Writer, sheets ...Sheet) error {
+	if len(sheets) == 0 {
+		return fmt.Errorf("workbook must contain at least one sheet")
+	}
+
+	zw := zip.NewWriter(w)
+
+	var types, workbook, workbookRels strings.Builder
+	types.WriteString(contentTypesHeader)
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+`)
+
+	for i, sheet := range sheets {
+		n := i + 1
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`+"\n", n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(sheetName(sheet.Name)), n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`+"\n", n, n)
+
+		f, err := zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", n))
+		if err != nil {
+			return err
+		}
+		if err := writeSheet(f, sheet.Rows, sheet.RowStyles); err != nil {
+			return fmt.Errorf("failed to write sheet %q: %w", sheet.Name, err)
+		}
+	}
+
+	types.WriteString("</Types>\n")
+	workbook.WriteString("</sheets></workbook>\n")
+	fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`+"\n", len(sheets)+1)
+	workbookRels.WriteString("</Relationships>\n")
+
+	parts := []struct {
+		name string
+		body string
+	}{
+		{"[Content_Types].xml", types.String()},
+		{"_rels/.rels", rootRels},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		{"xl/styles.xml", styles},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeSheet(w io.Writer, rows [][]interface{}, rowStyles map[int]Style) error {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	for i, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, i+1)
+		for j, value := range row {
+			writeCell(&b, columnName(j)+strconv.Itoa(i+1), rowStyles[i], value)
+		}
+		b.WriteString("</row>")
+	}
+
+	b.WriteString("</sheetData></worksheet>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeCell(b *strings.Builder, ref string, style Style, value interface{}) {
+	// Пустая ячейка в оформленной строке всё равно пишется, чтобы заливка шла без разрывов
+	attrs := fmt.Sprintf(`r="%s"`, ref)
+	if style != StyleNone {
+		attrs += fmt.Sprintf(` s="%d"`, style)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		if style != StyleNone {
+			fmt.Fprintf(b, `<c %s/>`, attrs)
+		}
+	case bool:
+		n := 0
+		if v {
+			n = 1
+		}
+		fmt.Fprintf(b, `<c %s t="b"><v>%d</v></c>`, attrs, n)
+	case int, int32, int64:
+		fmt.Fprintf(b, `<c %s><v>%d</v></c>`, attrs, v)
+	case float32, float64:
+		fmt.Fprintf(b, `<c %s><v>%v</v></c>`, attrs, v)
+	case string:
+		fmt.Fprintf(b, `<c %s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, attrs, escape(v))
+	case Link:
+		text := v.Text
+		if text == "" {
+			text = v.URL
+		}
+		formula := fmt.Sprintf(`HYPERLINK("%s","%s")`, formulaString(v.URL), formulaString(text))
+		fmt.Fprintf(b, `<c %s t="str"><f>%s</f><v>%s</v></c>`, attrs, escape(formula), escape(text))
+	default:
+		fmt.Fprintf(b, `<c %s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, attrs, escape(fmt.Sprint(v)))
+	}
+}
+
+// formulaString экранирует кавычки для строкового литерала формулы
+func formulaString(s string) string {
+	return strings.ReplaceAll(s, `"`, `""`)
+}
+
+// columnName переводит индекс столбца с нуля в буквенное обозначение: 0 → A, 26 → AA
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// sheetName приводит имя к ограничениям Excel: не больше 31 символа и без []:*?/\
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	if name == "" {
+		name = "Sheet"
+	}
+	return name
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -2,25 +2,38 @@ package app
 
 import (
 	"context"
+	"net/http"
+	"strings"
 
 	"github.com/RubachokBoss/plagiarism-checker/api-gateway/internal/config"
 	"github.com/RubachokBoss/plagiarism-checker/api-gateway/internal/handler"
 	"github.com/RubachokBoss/plagiarism-checker/api-gateway/internal/middleware"
+	"github.com/RubachokBoss/plagiarism-checker/api-gateway/internal/proxy"
 	"github.com/RubachokBoss/plagiarism-checker/api-gateway/internal/server"
+	"github.com/RubachokBoss/plagiarism-checker/api-gateway/pkg/buildinfo"
+	"github.com/RubachokBoss/plagiarism-checker/api-gateway/pkg/httpmetrics"
 	"github.com/rs/zerolog"
 )
 
 type App struct {
-	server *server.Server
-	logger zerolog.Logger
-	config *config.Config
+	server      *server.Server
+	rateLimiter *middleware.RateLimiter
+	logger      zerolog.Logger
+	config      *config.Config
 }
 
 func New(cfg *config.Config, log zerolog.Logger) (*App, error) {
 	h := handler.NewHandler(log, handler.ProxyConfig{
-		Timeout:         cfg.Proxy.Timeout,
-		MaxIdleConns:    cfg.Proxy.MaxIdleConns,
-		IdleConnTimeout: cfg.Proxy.IdleConnTimeout,
+		Timeout:          cfg.Proxy.Timeout,
+		MaxIdleConns:     cfg.Proxy.MaxIdleConns,
+		IdleConnTimeout:  cfg.Proxy.IdleConnTimeout,
+		BreakerThreshold: cfg.Proxy.BreakerFailureThreshold,
+		BreakerCooldown:  cfg.Proxy.BreakerCooldown,
+		BodyLimits: proxy.BodyLimits{
+			MaxBodySize:       cfg.Proxy.MaxBodySize,
+			MaxUploadBodySize: cfg.Proxy.MaxUploadBodySize,
+			UploadPaths:       cfg.Proxy.UploadPaths,
+		},
 	})
 
 	router := h.GetRouter()
@@ -33,6 +46,39 @@ func New(cfg *config.Config, log zerolog.Logger) (*App, error) {
 		ShutdownTimeout: cfg.Server.ShutdownTimeout,
 	}, router, log)
 
+	var metrics *httpmetrics.Registry
+	var metricsMiddleware func(http.Handler) http.Handler
+	if cfg.Metrics.Enabled {
+		metrics = httpmetrics.New(httpmetrics.Config{
+			SizeBuckets:    cfg.Metrics.SizeBuckets,
+			LatencyBuckets: cfg.Metrics.LatencyBuckets,
+		})
+		metricsMiddleware = metrics.Middleware
+	}
+
+	var rateLimiter *middleware.RateLimiter
+	var rateLimitMiddleware func(http.Handler) http.Handler
+	if cfg.RateLimit.Enabled {
+		overrides := make([]middleware.RateLimitRule, 0, len(cfg.RateLimit.Overrides))
+		for _, o := range cfg.RateLimit.Overrides {
+			overrides = append(overrides, middleware.RateLimitRule{
+				Path:              o.Path,
+				Method:            o.Method,
+				RequestsPerSecond: o.RequestsPerSecond,
+				Burst:             o.Burst,
+			})
+		}
+		trustedProxies, err := middleware.ParseTrustedProxies(cfg.RateLimit.TrustedProxies)
+		if err != nil {
+			return nil, err
+		}
+		rateLimiter = middleware.NewRateLimiter(middleware.RateLimitRule{
+			RequestsPerSecond: cfg.RateLimit.RequestsPerSecond,
+			Burst:             cfg.RateLimit.Burst,
+		}, overrides, trustedProxies, newBucketStore(cfg, log))
+		rateLimitMiddleware = rateLimiter.Middleware
+	}
+
 	// Настраиваем middleware
 	srv.SetupMiddleware(
 		middleware.NewCORS(
@@ -43,25 +89,43 @@ func New(cfg *config.Config, log zerolog.Logger) (*App, error) {
 			cfg.CORS.AllowCredentials,
 			cfg.CORS.MaxAge,
 		),
+		metricsMiddleware,
 		middleware.RequestLogger(log),
+		rateLimitMiddleware,
 		middleware.Recovery(log),
 		middleware.Timeout(cfg.Proxy.Timeout),
 	)
 
 	// важно: middleware должны быть навешаны до регистрации роутов
 	h.SetupBaseRoutes()
+	if cfg.Server.VersionEndpoint {
+		router.Get("/version", buildinfo.Handler("api-gateway"))
+	}
+	if cfg.Health.Enabled {
+		h.RegisterSystemHealth(handler.SystemHealthConfig{
+			Timeout: cfg.Health.UpstreamTimeout,
+			Upstreams: []handler.Upstream{
+				{Name: "work-service", ReadyURL: readyURL(cfg.Services.Work)},
+				{Name: "file-service", ReadyURL: readyURL(cfg.Services.File)},
+				{Name: "analysis-service", ReadyURL: readyURL(cfg.Services.Analysis)},
+			},
+		})
+	}
+	if metrics != nil {
+		router.Get(cfg.Metrics.Path, metrics.Handler)
+	}
 
-	workProxy, err := h.CreateServiceProxy(cfg.Services.Work.URL, "")
+	workProxy, err := h.CreateServiceProxy(cfg.Services.Work.URL, "", serviceOptions(cfg.Services.Work))
 	if err != nil {
 		return nil, err
 	}
 
-	fileProxy, err := h.CreateServiceProxy(cfg.Services.File.URL, "")
+	fileProxy, err := h.CreateServiceProxy(cfg.Services.File.URL, "", serviceOptions(cfg.Services.File))
 	if err != nil {
 		return nil, err
 	}
 
-	analysisProxy, err := h.CreateServiceProxy(cfg.Services.Analysis.URL, "")
+	analysisProxy, err := h.CreateServiceProxy(cfg.Services.Analysis.URL, "", serviceOptions(cfg.Services.Analysis))
 	if err != nil {
 		return nil, err
 	}
@@ -70,9 +134,10 @@ func New(cfg *config.Config, log zerolog.Logger) (*App, error) {
 	h.SetupProxyRoutes(workProxy, fileProxy, analysisProxy)
 
 	return &App{
-		server: srv,
-		logger: log,
-		config: cfg,
+		server:      srv,
+		rateLimiter: rateLimiter,
+		logger:      log,
+		config:      cfg,
 	}, nil
 }
 
@@ -81,5 +146,37 @@ func (a *App) Run() error {
 }
 
 func (a *App) Shutdown(ctx context.Context) error {
+	if a.rateLimiter != nil {
+		if err := a.rateLimiter.Close(); err != nil {
+			a.logger.Error().Err(err).Msg("Failed to close rate limiter")
+		}
+	}
 	return a.server.Shutdown(ctx)
 }
+
+// newBucketStore — корзины в Redis, если он задан и отвечает, иначе в памяти процесса
+func newBucketStore(cfg *config.Config, log zerolog.Logger) middleware.BucketStore {
+	if cfg.Redis.URL != "" {
+		store, err := middleware.NewRedisBuckets(context.Background(), cfg.Redis.URL, cfg.Redis.KeyPrefix+"ratelimit:",
+			cfg.RateLimit.CleanupInterval, cfg.RateLimit.IdleTTL, log)
+		if err == nil {
+			log.Info().Msg("Using Redis for rate limit buckets")
+			return store
+		}
+		log.Warn().Err(err).Msg("Redis is unavailable, rate limit buckets are kept in memory")
+	}
+	return middleware.NewMemoryBuckets(cfg.RateLimit.CleanupInterval, cfg.RateLimit.IdleTTL)
+}
+
+func serviceOptions(service config.ServiceConfig) handler.ServiceOptions {
+	return handler.ServiceOptions{
+		APIKey:     service.APIKey,
+		Timeout:    service.Timeout,
+		RetryCount: service.RetryCount,
+		RetryDelay: service.RetryDelay,
+	}
+}
+
+func readyURL(service config.ServiceConfig) string {
+	return strings.TrimSuffix(service.URL, "/") + service.ReadyEndpoint
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"strings"
 	"time"
 
@@ -9,11 +10,23 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Proxy    ProxyConfig    `mapstructure:"proxy"`
-	Services ServicesConfig `mapstructure:"services"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
-	CORS     CORSConfig     `mapstructure:"cors"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Proxy     ProxyConfig     `mapstructure:"proxy"`
+	Services  ServicesConfig  `mapstructure:"services"`
+	Startup   StartupConfig   `mapstructure:"startup"`
+	Health    HealthConfig    `mapstructure:"system_health"`
+	Logging   LoggingConfig   `mapstructure:"logging"`
+	Metrics   MetricsConfig   `mapstructure:"metrics"`
+	CORS      CORSConfig      `mapstructure:"cors"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+}
+
+// RedisConfig — общее состояние экземпляров шлюза (корзины rate_limit).
+// Без url (или если Redis недоступен при старте) состояние хранится в памяти процесса
+type RedisConfig struct {
+	URL       string `mapstructure:"url"`
+	KeyPrefix string `mapstructure:"key_prefix"`
 }
 
 type ServerConfig struct {
@@ -22,20 +35,34 @@ type ServerConfig struct {
 	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout     time.Duration `mapstructure:"idle_timeout"`
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	// GET /version без ключа: версия, коммит и время сборки, версия Go
+	VersionEndpoint bool `mapstructure:"version_endpoint"`
 }
 
 type ProxyConfig struct {
 	Timeout         time.Duration `mapstructure:"timeout"`
 	MaxIdleConns    int           `mapstructure:"max_idle_connections"`
 	IdleConnTimeout time.Duration `mapstructure:"idle_conn_timeout"`
+	// Неудач подряд (5xx, таймаут, недоступность), после которых запросы к сервису сразу получают 503; 0 — выключено
+	BreakerFailureThreshold int `mapstructure:"breaker_failure_threshold"`
+	// Через сколько после размыкания пропускается пробный запрос
+	BreakerCooldown time.Duration `mapstructure:"breaker_cooldown"`
+	// Лимит тела запроса, байт; больше — 413 (0 — без ограничения)
+	MaxBodySize int64 `mapstructure:"max_body_size"`
+	// Лимит тела загрузки файла (запрос не в JSON к пути из upload_paths), байт
+	MaxUploadBodySize int64    `mapstructure:"max_upload_body_size"`
+	UploadPaths       []string `mapstructure:"upload_paths"`
 }
 
 type ServiceConfig struct {
 	URL            string        `mapstructure:"url"`
 	HealthEndpoint string        `mapstructure:"health_endpoint"`
+	ReadyEndpoint  string        `mapstructure:"ready_endpoint"`
 	Timeout        time.Duration `mapstructure:"timeout"`
 	RetryCount     int           `mapstructure:"retry_count"`
 	RetryDelay     time.Duration `mapstructure:"retry_delay"`
+	// Ключ X-API-Key, который шлюз передаёт сервису ("" — не передаётся)
+	APIKey string `mapstructure:"api_key"`
 }
 
 type ServicesConfig struct {
@@ -44,6 +71,27 @@ type ServicesConfig struct {
 	Analysis ServiceConfig `mapstructure:"analysis"`
 }
 
+// HealthConfig — сводная проверка GET /health/system по /ready всех сервисов
+type HealthConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	UpstreamTimeout time.Duration `mapstructure:"upstream_timeout"`
+}
+
+type StartupConfig struct {
+	// Проверять внешние зависимости (RabbitMQ, MinIO, сервисы) перед запуском
+	SelfCheck    bool          `mapstructure:"self_check"`
+	CheckTimeout time.Duration `mapstructure:"check_timeout"`
+}
+
+// MetricsConfig — метрики HTTP по маршрутам: задержка и размеры тел запроса и ответа
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
+	// Границы корзин гистограмм размеров, байт
+	SizeBuckets    []int64         `mapstructure:"size_buckets"`
+	LatencyBuckets []time.Duration `mapstructure:"latency_buckets"`
+}
+
 type LoggingConfig struct {
 	Level   string `mapstructure:"level"`
 	Pretty  bool   `mapstructure:"pretty"`
@@ -59,6 +107,27 @@ type CORSConfig struct {
 	MaxAge           int      `mapstructure:"max_age"`
 }
 
+// RateLimitConfig — корзина токенов на клиента для запросов /api/
+type RateLimitConfig struct {
+	Enabled           bool    `mapstructure:"enabled"`
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+	// Как часто удалять корзины клиентов, не обращавшихся дольше idle_ttl
+	CleanupInterval time.Duration       `mapstructure:"cleanup_interval"`
+	IdleTTL         time.Duration       `mapstructure:"idle_ttl"`
+	Overrides       []RateLimitOverride `mapstructure:"overrides"`
+	// Адреса и подсети прокси перед шлюзом: только от них принимаются X-User-ID и X-Forwarded-For
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// RateLimitOverride — отдельный лимит для префикса пути (и метода, если указан)
+type RateLimitOverride struct {
+	Path              string  `mapstructure:"path"`
+	Method            string  `mapstructure:"method"`
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	Burst             int     `mapstructure:"burst"`
+}
+
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -88,6 +157,33 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
+// Validate проверяет обязательные параметры и возвращает все найденные проблемы разом
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Server.Address == "" {
+		problems = append(problems, "server.address is empty")
+	}
+	if c.Services.Work.URL == "" || c.Services.File.URL == "" || c.Services.Analysis.URL == "" {
+		problems = append(problems, "services.work.url, services.file.url and services.analysis.url are required")
+	}
+	if c.Proxy.MaxBodySize < 0 || c.Proxy.MaxUploadBodySize < 0 {
+		problems = append(problems, "proxy.max_body_size and proxy.max_upload_body_size must not be negative")
+	}
+	for _, proxy := range c.RateLimit.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				problems = append(problems, fmt.Sprintf("rate_limit.trusted_proxies: %q is not an IP address or CIDR", proxy))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
 func setDefaults() {
 	// Значения по умолчанию: сервер
 	viper.SetDefault("server.address", ":8080")
@@ -95,34 +191,57 @@ func setDefaults() {
 	viper.SetDefault("server.write_timeout", "15s")
 	viper.SetDefault("server.idle_timeout", "60s")
 	viper.SetDefault("server.shutdown_timeout", "10s")
+	viper.SetDefault("server.version_endpoint", true)
 
 	// Значения по умолчанию: прокси
 	viper.SetDefault("proxy.timeout", "30s")
 	viper.SetDefault("proxy.max_idle_connections", 100)
 	viper.SetDefault("proxy.idle_conn_timeout", "90s")
+	viper.SetDefault("proxy.breaker_failure_threshold", 5)
+	viper.SetDefault("proxy.breaker_cooldown", "30s")
+	viper.SetDefault("proxy.max_body_size", 1048576)          // 1MB
+	viper.SetDefault("proxy.max_upload_body_size", 104857600) // 100MB
+	viper.SetDefault("proxy.upload_paths", []string{"/api/v1/works", "/api/v1/files/upload", "/api/v1/students/import", "/api/v1/analysis/preview"})
 
 	// Значения по умолчанию: work-service
 	viper.SetDefault("services.work.url", "http://work-service:8081")
 	viper.SetDefault("services.work.health_endpoint", "/health")
+	viper.SetDefault("services.work.ready_endpoint", "/ready")
 	viper.SetDefault("services.work.timeout", "10s")
 	viper.SetDefault("services.work.retry_count", 3)
 	viper.SetDefault("services.work.retry_delay", "100ms")
+	viper.SetDefault("services.work.api_key", "")
 
 	// Значения по умолчанию: file-service
 	viper.SetDefault("services.file.url", "http://file-service:8082")
 	viper.SetDefault("services.file.health_endpoint", "/health")
+	viper.SetDefault("services.file.ready_endpoint", "/ready")
 	viper.SetDefault("services.file.timeout", "15s")
 	viper.SetDefault("services.file.retry_count", 3)
 	viper.SetDefault("services.file.retry_delay", "100ms")
+	viper.SetDefault("services.file.api_key", "")
 
 	// Значения по умолчанию: analysis-service
 	viper.SetDefault("services.analysis.url", "http://analysis-service:8083")
 	viper.SetDefault("services.analysis.health_endpoint", "/health")
+	viper.SetDefault("services.analysis.ready_endpoint", "/ready")
 	viper.SetDefault("services.analysis.timeout", "10s")
 	viper.SetDefault("services.analysis.retry_count", 3)
 	viper.SetDefault("services.analysis.retry_delay", "100ms")
+	viper.SetDefault("services.analysis.api_key", "")
+
+	viper.SetDefault("system_health.enabled", true)
+	viper.SetDefault("system_health.upstream_timeout", "3s")
 
 	// Значения по умолчанию: логирование
+	viper.SetDefault("startup.self_check", true)
+	viper.SetDefault("startup.check_timeout", "10s")
+
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("metrics.path", "/metrics")
+	viper.SetDefault("metrics.size_buckets", []int64{1024, 10240, 102400, 1048576, 10485760})
+	viper.SetDefault("metrics.latency_buckets", []string{"10ms", "50ms", "100ms", "500ms", "1s", "5s"})
+
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.pretty", false)
 	viper.SetDefault("logging.no_color", false)
@@ -130,8 +249,19 @@ func setDefaults() {
 	// Значения по умолчанию: CORS
 	viper.SetDefault("cors.allowed_origins", []string{"*"})
 	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
-	viper.SetDefault("cors.allowed_headers", []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"})
+	viper.SetDefault("cors.allowed_headers", []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key"})
 	viper.SetDefault("cors.exposed_headers", []string{"Link"})
 	viper.SetDefault("cors.allow_credentials", true)
 	viper.SetDefault("cors.max_age", 300)
+
+	// Значения по умолчанию: ограничение частоты запросов
+	viper.SetDefault("rate_limit.enabled", true)
+	viper.SetDefault("rate_limit.requests_per_second", 10)
+	viper.SetDefault("rate_limit.burst", 20)
+	viper.SetDefault("rate_limit.cleanup_interval", "1m")
+	viper.SetDefault("rate_limit.idle_ttl", "10m")
+	viper.SetDefault("rate_limit.trusted_proxies", []string{})
+
+	viper.SetDefault("redis.url", "")
+	viper.SetDefault("redis.key_prefix", "gateway:")
 }
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -9,6 +11,9 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/api-gateway/internal/proxy"
+	"github.com/RubachokBoss/plagiarism-checker/api-gateway/pkg/buildinfo"
 )
 
 type Handler struct {
@@ -21,12 +26,33 @@ type ProxyConfig struct {
 	Timeout         time.Duration
 	MaxIdleConns    int
 	IdleConnTimeout time.Duration
+	// Неудач подряд (5xx или недоступность), после которых прокси к сервису размыкается; 0 — без размыкателя
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	// Лимиты тела запроса; превышение — 413
+	BodyLimits proxy.BodyLimits
 }
 
 type ServiceProxy struct {
 	TargetURL  *url.URL
 	Proxy      *httputil.ReverseProxy
 	PathPrefix string
+	Breaker    *proxy.CircuitBreaker
+	Limits     proxy.BodyLimits
+	// Повторы после первой попытки при 5xx и недоступности сервиса и пауза перед ними (растёт с номером попытки)
+	Retries    int
+	RetryDelay time.Duration
+	Logger     zerolog.Logger
+}
+
+// ServiceOptions — настройки прокси к одному сервису (services.<имя> конфигурации)
+type ServiceOptions struct {
+	// Ключ X-API-Key для сервиса ("" — не передаётся)
+	APIKey string
+	// Сколько ждать заголовков ответа сервиса (0 — без ограничения); тело ответа передаётся без дедлайна
+	Timeout    time.Duration
+	RetryCount int
+	RetryDelay time.Duration
 }
 
 func NewHandler(logger zerolog.Logger, proxyConfig ProxyConfig) *Handler {
@@ -54,7 +80,7 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		"status":    "healthy",
 		"timestamp": time.Now().UTC(),
 		"service":   "api-gateway",
-		"version":   "1.0.0",
+		"version":   buildinfo.Version,
 	}
 
 	if err := writeJSON(w, http.StatusOK, response); err != nil {
@@ -84,23 +110,26 @@ func (h *Handler) LiveCheck(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *Handler) CreateServiceProxy(targetURL, pathPrefix string) (*ServiceProxy, error) {
+// CreateServiceProxy создаёт прокси к сервису; непустой opts.APIKey передаётся сервису в X-API-Key
+func (h *Handler) CreateServiceProxy(targetURL, pathPrefix string, opts ServiceOptions) (*ServiceProxy, error) {
 	target, err := url.Parse(targetURL)
 	if err != nil {
 		return nil, err
 	}
 
-	proxy := httputil.NewSingleHostReverseProxy(target)
+	breaker := proxy.NewCircuitBreaker(target.Host, h.proxyConfig.BreakerThreshold, h.proxyConfig.BreakerCooldown, h.logger)
+	reverseProxy := httputil.NewSingleHostReverseProxy(target)
 
 	transport := &http.Transport{
-		MaxIdleConns:       h.proxyConfig.MaxIdleConns,
-		IdleConnTimeout:    h.proxyConfig.IdleConnTimeout,
-		DisableCompression: true,
+		MaxIdleConns:          h.proxyConfig.MaxIdleConns,
+		IdleConnTimeout:       h.proxyConfig.IdleConnTimeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		DisableCompression:    true,
 	}
 
-	proxy.Transport = transport
+	reverseProxy.Transport = transport
 
-	proxy.Director = func(req *http.Request) {
+	reverseProxy.Director = func(req *http.Request) {
 		req.URL.Scheme = target.Scheme
 		req.URL.Host = target.Host
 		req.Host = target.Host
@@ -111,6 +140,9 @@ func (h *Handler) CreateServiceProxy(targetURL, pathPrefix string) (*ServiceProx
 
 		req.Header.Set("X-Forwarded-Host", req.Host)
 		req.Header.Set("X-Real-IP", req.RemoteAddr)
+		if opts.APIKey != "" {
+			req.Header.Set("X-API-Key", opts.APIKey)
+		}
 
 		h.logger.Debug().
 			Str("method", req.Method).
@@ -119,7 +151,27 @@ func (h *Handler) CreateServiceProxy(targetURL, pathPrefix string) (*ServiceProx
 			Msg("Proxying request")
 	}
 
-	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+	reverseProxy.ModifyResponse = func(resp *http.Response) error {
+		if resp.StatusCode >= http.StatusInternalServerError {
+			breaker.RecordFailure()
+		} else {
+			breaker.RecordSuccess()
+		}
+		return nil
+	}
+
+	reverseProxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		// Тело сверх лимита прочитано не до конца — сервис тут ни при чём
+		if limit, ok := proxy.LimitExceeded(err); ok {
+			proxy.BodyTooLarge(w, limit)
+			return
+		}
+
+		// Отменённый клиентом запрос ничего не говорит о состоянии сервиса
+		if !errors.Is(err, context.Canceled) {
+			breaker.RecordFailure()
+		}
+
 		h.logger.Error().
 			Err(err).
 			Str("url", r.URL.String()).
@@ -139,8 +191,13 @@ func (h *Handler) CreateServiceProxy(targetURL, pathPrefix string) (*ServiceProx
 
 	return &ServiceProxy{
 		TargetURL:  target,
-		Proxy:      proxy,
+		Proxy:      reverseProxy,
 		PathPrefix: pathPrefix,
+		Breaker:    breaker,
+		Limits:     h.proxyConfig.BodyLimits,
+		Retries:    opts.RetryCount,
+		RetryDelay: opts.RetryDelay,
+		Logger:     h.logger,
 	}, nil
 }
 
@@ -24,6 +24,31 @@ type ServiceStatus struct {
 	URL    string `json:"url,omitempty"`
 }
 
+// SystemHealthResponse — сводка /health/system: status healthy, degraded (часть сервисов
+// недоступна) или unhealthy (недоступны все); gateway всегда up, раз ответ получен
+type SystemHealthResponse struct {
+	Status     string           `json:"status"`
+	Timestamp  time.Time        `json:"timestamp"`
+	Gateway    GatewayHealth    `json:"gateway"`
+	Services   []UpstreamHealth `json:"services"`
+	DurationMs int64            `json:"duration_ms"`
+}
+
+type GatewayHealth struct {
+	Status  string `json:"status"`
+	Service string `json:"service"`
+	Version string `json:"version"`
+}
+
+type UpstreamHealth struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	URL        string `json:"url"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+	LatencyMs  int64  `json:"latency_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
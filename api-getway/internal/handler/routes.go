@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"bytes"
+	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -13,6 +16,7 @@ func (h *Handler) SetupProxyRoutes(workProxy, fileProxy, analysisProxy *ServiceP
 			r.Post("/", workProxy.ServeHTTP)
 			r.Get("/", workProxy.ServeHTTP)
 			r.Get("/{id}/reports", workProxy.ServeHTTP)
+			r.Get("/{id}/percentile", workProxy.ServeHTTP)
 			r.Get("/{id}", workProxy.ServeHTTP) // для отладки
 			r.Put("/{id}/status", workProxy.ServeHTTP)
 			r.Delete("/{id}", workProxy.ServeHTTP)
@@ -21,9 +25,13 @@ func (h *Handler) SetupProxyRoutes(workProxy, fileProxy, analysisProxy *ServiceP
 		r.Route("/files", func(r chi.Router) {
 			r.Post("/upload", fileProxy.ServeHTTP)
 			r.Post("/upload/bytes", fileProxy.ServeHTTP)
+			r.Post("/upload/init", fileProxy.ServeHTTP)
+			r.Put("/upload/{id}/chunk/{n}", fileProxy.ServeHTTP)
+			r.Post("/upload/{id}/complete", fileProxy.ServeHTTP)
 			r.Get("/{id}", fileProxy.ServeHTTP)
 			r.Get("/{id}/info", fileProxy.ServeHTTP)
 			r.Get("/{id}/url", fileProxy.ServeHTTP)
+			r.Get("/{id}/assignments", workProxy.ServeHTTP)
 			r.Delete("/{id}", fileProxy.ServeHTTP)
 			r.Get("/download/by-hash", fileProxy.ServeHTTP)
 		})
@@ -32,23 +40,53 @@ func (h *Handler) SetupProxyRoutes(workProxy, fileProxy, analysisProxy *ServiceP
 			r.Post("/", analysisProxy.ServeHTTP)
 			r.Post("/batch", analysisProxy.ServeHTTP)
 			r.Post("/async", analysisProxy.ServeHTTP)
+			r.Post("/urgent", analysisProxy.ServeHTTP)
+			r.Post("/preview", analysisProxy.ServeHTTP)
+			r.Get("/comparison", analysisProxy.ServeHTTP)
+			r.Get("/by-hash/{hash}", analysisProxy.ServeHTTP)
+			r.Get("/version", analysisProxy.ServeHTTP)
+			r.Get("/reference-corpus", analysisProxy.ServeHTTP)
+			r.Post("/reference-corpus/bulk", analysisProxy.ServeHTTP)
 			r.Get("/{work_id}", analysisProxy.ServeHTTP)
+			r.Get("/{work_id}/coverage", analysisProxy.ServeHTTP)
 			r.Post("/retry", analysisProxy.ServeHTTP)
 		})
 
 		r.Route("/reports", func(r chi.Router) {
 			r.Get("/", analysisProxy.ServeHTTP)
 			r.Get("/{report_id}", analysisProxy.ServeHTTP)
+			r.Get("/{report_id}/pdf", analysisProxy.ServeHTTP)
+			r.Post("/{report_id}/override", analysisProxy.ServeHTTP)
 			r.Get("/work/{work_id}", analysisProxy.ServeHTTP)
 			r.Get("/assignment/{assignment_id}", analysisProxy.ServeHTTP)
+			r.Get("/assignment/{assignment_id}/roster", analysisProxy.ServeHTTP)
 			r.Get("/student/{student_id}", analysisProxy.ServeHTTP)
 			r.Get("/export", analysisProxy.ServeHTTP)
+			r.Get("/export/jobs/{job_id}", analysisProxy.ServeHTTP)
+			r.Get("/export/jobs/{job_id}/download", analysisProxy.ServeHTTP)
 		})
 
+		r.Get("/events/ws", analysisProxy.ServeHTTP)
+
 		r.Route("/wordcloud", func(r chi.Router) {
 			r.Get("/work/{work_id}", analysisProxy.ServeHTTP)
 		})
 
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Get("/", analysisProxy.ServeHTTP)
+			r.Post("/", analysisProxy.ServeHTTP)
+			r.Delete("/{id}", analysisProxy.ServeHTTP)
+		})
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Get("/throughput", analysisProxy.ServeHTTP)
+			r.Post("/notifications/test", analysisProxy.ServeHTTP)
+			r.Post("/assignments/{id}/refresh-stats", analysisProxy.ServeHTTP)
+			r.Post("/reanalyze-outdated", analysisProxy.ServeHTTP)
+			r.Post("/hash-index/rebuild", analysisProxy.ServeHTTP)
+			r.Get("/reports/{id}/raw-details", analysisProxy.ServeHTTP)
+		})
+
 		r.Route("/assignments", func(r chi.Router) {
 			r.Get("/", workProxy.ServeHTTP)
 			r.Post("/", workProxy.ServeHTTP)
@@ -56,16 +94,45 @@ func (h *Handler) SetupProxyRoutes(workProxy, fileProxy, analysisProxy *ServiceP
 			r.Put("/{id}", workProxy.ServeHTTP)
 			r.Delete("/{id}", workProxy.ServeHTTP)
 			r.Get("/{id}/works", workProxy.ServeHTTP)
+			r.Get("/{id}/override-stats", analysisProxy.ServeHTTP)
+			r.Put("/{id}/threshold", analysisProxy.ServeHTTP)
+			r.Post("/{id}/warmup", analysisProxy.ServeHTTP)
+			r.Get("/{id}/notification-recipients", analysisProxy.ServeHTTP)
+			r.Post("/{id}/notification-recipients", analysisProxy.ServeHTTP)
+			r.Delete("/{id}/notification-recipients/{recipient_id}", analysisProxy.ServeHTTP)
 		})
 
 		r.Route("/students", func(r chi.Router) {
 			r.Get("/", workProxy.ServeHTTP)
 			r.Post("/", workProxy.ServeHTTP)
+			r.Post("/import", workProxy.ServeHTTP)
 			r.Get("/{id}", workProxy.ServeHTTP)
 			r.Get("/email/{email}", workProxy.ServeHTTP)
 			r.Put("/{id}", workProxy.ServeHTTP)
 			r.Delete("/{id}", workProxy.ServeHTTP)
 			r.Get("/{id}/works", workProxy.ServeHTTP)
+			r.Get("/{id}/reports/portfolio.pdf", analysisProxy.ServeHTTP)
+			r.Delete("/{id}/data", workProxy.ServeHTTP)
+			r.Get("/{id}/data/purges/{purge_id}", workProxy.ServeHTTP)
+			r.Post("/{id}/data/purges/{purge_id}/confirm", workProxy.ServeHTTP)
+			r.Post("/{id}/data/purges/{purge_id}/cancel", workProxy.ServeHTTP)
+		})
+
+		r.Route("/teachers", func(r chi.Router) {
+			r.Get("/", workProxy.ServeHTTP)
+			r.Post("/", workProxy.ServeHTTP)
+			r.Get("/{id}", workProxy.ServeHTTP)
+			r.Put("/{id}", workProxy.ServeHTTP)
+			r.Delete("/{id}", workProxy.ServeHTTP)
+		})
+
+		r.Route("/courses", func(r chi.Router) {
+			r.Get("/", workProxy.ServeHTTP)
+			r.Post("/", workProxy.ServeHTTP)
+			r.Get("/{id}", workProxy.ServeHTTP)
+			r.Put("/{id}", workProxy.ServeHTTP)
+			r.Delete("/{id}", workProxy.ServeHTTP)
+			r.Get("/{id}/assignments", workProxy.ServeHTTP)
 		})
 	})
 
@@ -113,7 +180,135 @@ func (h *Handler) adminServices(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, response)
 }
 
-// ServeHTTP проксирует запрос в целевой микросервис.
+// ServeHTTP проксирует запрос в целевой микросервис; при разомкнутом размыкателе сразу отвечает 503.
+// Запросы без тела или с JSON-телом повторяются при 5xx и недоступности сервиса. Клиенту уходит
+// ровно один ответ: ответ попытки, после которой будет повтор, отбрасывается, успешный передаётся
+// потоком без буферизации. Попытки выполняются в контексте запроса и прекращаются, когда клиент ушёл.
 func (sp *ServiceProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	sp.Proxy.ServeHTTP(w, r)
+	if !sp.Breaker.Allow() {
+		sp.circuitOpen(w)
+		return
+	}
+
+	if !sp.Limits.Apply(w, r) {
+		return
+	}
+
+	// Загрузки идут потоком, а WebSocket требует исходного соединения — такие запросы не повторяются
+	if sp.Retries <= 0 || !sp.Limits.Bufferable(r) || r.Header.Get("Upgrade") != "" {
+		sp.Proxy.ServeHTTP(w, r)
+		return
+	}
+
+	// Тело для повторов; его размер уже ограничен MaxBodySize
+	var body []byte
+	if r.Body != nil && r.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			sp.Proxy.ErrorHandler(w, r, err)
+			return
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			// Сервис уже признан недоступным — повторы только добавят задержку
+			if !sp.Breaker.Allow() {
+				sp.circuitOpen(w)
+				return
+			}
+
+			select {
+			case <-time.After(time.Duration(attempt) * sp.RetryDelay):
+			case <-r.Context().Done():
+				// Клиент ушёл — повторять не для кого
+				return
+			}
+
+			sp.Logger.Info().
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Int("attempt", attempt+1).
+				Msg("Retrying request")
+		}
+
+		req := r.Clone(r.Context())
+		req.Body = http.NoBody
+		if body != nil {
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			req.ContentLength = int64(len(body))
+		}
+
+		aw := &attemptWriter{w: w, header: make(http.Header), retry: attempt < sp.Retries}
+		sp.Proxy.ServeHTTP(aw, req)
+		if !aw.discarded || r.Context().Err() != nil {
+			return
+		}
+	}
+}
+
+func (sp *ServiceProxy) circuitOpen(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(sp.Breaker.RetryAfter().Seconds())+1))
+	writeJSON(w, http.StatusServiceUnavailable, map[string]interface{}{
+		"error":   "Service unavailable",
+		"message": "The service is temporarily unavailable. Please try again later.",
+		"code":    "CIRCUIT_OPEN",
+	})
+}
+
+// attemptWriter передаёт клиенту ответ попытки, только если он окончательный. Ответ 5xx попытки,
+// после которой будет повтор, отбрасывается вместе с заголовками, не дойдя до клиента.
+type attemptWriter struct {
+	w         http.ResponseWriter
+	header    http.Header
+	retry     bool
+	wrote     bool
+	discarded bool
+}
+
+func (a *attemptWriter) Header() http.Header {
+	return a.header
+}
+
+func (a *attemptWriter) WriteHeader(status int) {
+	if a.wrote {
+		return
+	}
+	// Информационные ответы (100 Continue) не окончательные и передаются как есть
+	if status < http.StatusOK {
+		a.w.WriteHeader(status)
+		return
+	}
+	a.wrote = true
+
+	if a.retry && status >= http.StatusInternalServerError {
+		a.discarded = true
+		return
+	}
+
+	for k, v := range a.header {
+		a.w.Header()[k] = v
+	}
+	a.w.WriteHeader(status)
+}
+
+func (a *attemptWriter) Write(b []byte) (int, error) {
+	if !a.wrote {
+		a.WriteHeader(http.StatusOK)
+	}
+	if a.discarded {
+		return len(b), nil
+	}
+	return a.w.Write(b)
+}
+
+// Flush нужен ReverseProxy для потоковых ответов (экспорт, большие файлы)
+func (a *attemptWriter) Flush() {
+	if a.discarded {
+		return
+	}
+	if f, ok := a.w.(http.Flusher); ok {
+		f.Flush()
+	}
 }
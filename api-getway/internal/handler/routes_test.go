@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/api-gateway/internal/proxy"
+)
+
+// countingWriter считает записи заголовков ответа клиенту
+type countingWriter struct {
+	*httptest.ResponseRecorder
+	headerWrites int
+}
+
+func (w *countingWriter) WriteHeader(status int) {
+	w.headerWrites++
+	w.ResponseRecorder.WriteHeader(status)
+}
+
+func newTestServiceProxy(t *testing.T, backend http.HandlerFunc, retries int, limits proxy.BodyLimits) *ServiceProxy {
+	t.Helper()
+
+	server := httptest.NewServer(backend)
+	t.Cleanup(server.Close)
+
+	h := NewHandler(zerolog.Nop(), ProxyConfig{BodyLimits: limits})
+	sp, err := h.CreateServiceProxy(server.URL, "", ServiceOptions{RetryCount: retries, RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatalf("CreateServiceProxy: %v", err)
+	}
+	return sp
+}
+
+func TestServiceProxyWritesRetriedResponseOnce(t *testing.T) {
+	var calls int32
+	sp := newTestServiceProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("X-Attempt", "failed")
+			http.Error(w, "temporary failure", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	}, 2, proxy.BodyLimits{})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/analysis", strings.NewReader(`{"work_id":"w1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+
+	sp.ServeHTTP(rec, req)
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("backend called %d times, want 2", got)
+	}
+	if rec.headerWrites != 1 {
+		t.Fatalf("response header written %d times, want 1", rec.headerWrites)
+	}
+	if rec.Code != http.StatusCreated || rec.Body.String() != `{"work_id":"w1"}` {
+		t.Fatalf("response = %d %q, want 201 with the replayed body", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("X-Attempt") != "" {
+		t.Fatal("headers of the discarded attempt reached the client")
+	}
+}
+
+func TestServiceProxySurfacesLastAttempt503(t *testing.T) {
+	var calls int32
+	sp := newTestServiceProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("attempt " + string(rune('0'+n))))
+	}, 2, proxy.BodyLimits{})
+
+	rec := &countingWriter{ResponseRecorder: httptest.NewRecorder()}
+	sp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reports", nil))
+
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("backend called %d times, want 3 (1 + 2 retries)", got)
+	}
+	if rec.headerWrites != 1 {
+		t.Fatalf("response header written %d times, want 1", rec.headerWrites)
+	}
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.String() != "attempt 3" {
+		t.Fatalf("response = %d %q, want the last attempt's 503", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServiceProxyStopsRetryingWhenClientGone(t *testing.T) {
+	var calls int32
+	sp := newTestServiceProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}, 5, proxy.BodyLimits{})
+	sp.RetryDelay = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reports", nil).WithContext(ctx)
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	done := make(chan struct{})
+	go func() {
+		sp.ServeHTTP(httptest.NewRecorder(), req)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ServeHTTP kept waiting after the request context was cancelled")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("backend called %d times, want 1", got)
+	}
+}
+
+func TestServiceProxyDoesNotRetryUploads(t *testing.T) {
+	var calls int32
+	sp := newTestServiceProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusInternalServerError)
+	}, 3, proxy.BodyLimits{UploadPaths: []string{"/api/v1/files/upload"}})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/files/upload", bytes.NewReader([]byte("--boundary--")))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=boundary")
+	rec := httptest.NewRecorder()
+
+	sp.ServeHTTP(rec, req)
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("backend called %d times, want 1", got)
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", rec.Code)
+	}
+}
+
+func TestServiceProxyShortCircuitsWhenBreakerOpen(t *testing.T) {
+	var calls, healthy int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	const cooldown = 50 * time.Millisecond
+	h := NewHandler(zerolog.Nop(), ProxyConfig{BreakerThreshold: 2, BreakerCooldown: cooldown})
+	sp, err := h.CreateServiceProxy(server.URL, "", ServiceOptions{RetryCount: 3, RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatalf("CreateServiceProxy: %v", err)
+	}
+
+	// Вторая неудача размыкает цепь, и оставшиеся повторы не отправляются
+	rec := httptest.NewRecorder()
+	sp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reports", nil))
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("backend called %d times, want 2 before the breaker opens", got)
+	}
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "CIRCUIT_OPEN") {
+		t.Fatalf("response = %d %s, want 503 CIRCUIT_OPEN", rec.Code, rec.Body.String())
+	}
+
+	// Пока цепь разомкнута, запросы отклоняются, не доходя до сервиса
+	for i := 0; i < 3; i++ {
+		rec = httptest.NewRecorder()
+		sp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reports", nil))
+		if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+			t.Fatalf("response = %d (Retry-After %q), want 503 with Retry-After", rec.Code, rec.Header().Get("Retry-After"))
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Fatalf("backend called %d times while the breaker is open, want 2", got)
+	}
+
+	// Сервис восстановился: пробный запрос после cooldown проходит и замыкает цепь
+	atomic.StoreInt32(&healthy, 1)
+	time.Sleep(cooldown)
+	for i := 0; i < 2; i++ {
+		rec = httptest.NewRecorder()
+		sp.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reports", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d after recovery: status %d, want 200", i+1, rec.Code)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 4 {
+		t.Fatalf("backend called %d times, want 4", got)
+	}
+}
+
+func TestServiceProxyRejectsOversizedBody(t *testing.T) {
+	limits := proxy.BodyLimits{MaxBodySize: 64, MaxUploadBodySize: 128, UploadPaths: []string{"/api/v1/files/upload"}}
+	jsonBody := `{"comment":"` + strings.Repeat("x", 90) + `"}`
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		chunked     bool
+		status      int
+	}{
+		{"json over limit", "/api/v1/analysis", "application/json", jsonBody, false, http.StatusRequestEntityTooLarge},
+		// Без Content-Length лимит срабатывает при чтении тела для повторов
+		{"chunked json over limit", "/api/v1/analysis", "application/json", jsonBody, true, http.StatusRequestEntityTooLarge},
+		{"upload over upload limit", "/api/v1/files/upload", "application/octet-stream", strings.Repeat("x", 200), false, http.StatusRequestEntityTooLarge},
+		// У загрузок свой лимит, больше лимита JSON
+		{"upload under upload limit", "/api/v1/files/upload", "application/octet-stream", strings.Repeat("x", 100), false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			var received int
+			sp := newTestServiceProxy(t, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				body, _ := io.ReadAll(r.Body)
+				received = len(body)
+			}, 2, limits)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+
+			sp.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			got := atomic.LoadInt32(&calls)
+			if tt.status == http.StatusRequestEntityTooLarge {
+				if got != 0 || !strings.Contains(rec.Body.String(), "BODY_TOO_LARGE") {
+					t.Fatalf("backend called %d times, body %s; want no calls and BODY_TOO_LARGE", got, rec.Body)
+				}
+				return
+			}
+			if got != 1 || received != len(tt.body) {
+				t.Fatalf("backend called %d times with %d bytes, want once with %d", got, received, len(tt.body))
+			}
+		})
+	}
+}
+
+func TestServiceProxyRetriesBodyUnderLimit(t *testing.T) {
+	const body = `{"work_id":"w1"}`
+	var calls int32
+	var bodies []string
+	sp := newTestServiceProxy(t, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}, 2, proxy.BodyLimits{MaxBodySize: 64})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/analysis", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	sp.ServeHTTP(rec, req)
+
+	// Лимит не мешает повтору: тело в пределах лимита буферизуется и отправляется заново целиком
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 after a retry", rec.Code)
+	}
+	if len(bodies) != 2 || bodies[0] != body || bodies[1] != body {
+		t.Fatalf("backend received %q, want the same body twice", bodies)
+	}
+}
+
+func TestProxyRoutesReachAnalysisBackend(t *testing.T) {
+	var got []string
+	analysis := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(analysis.Close)
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("%s %s routed to the wrong service", r.Method, r.URL.Path)
+	}))
+	t.Cleanup(other.Close)
+
+	h := NewHandler(zerolog.Nop(), ProxyConfig{BodyLimits: proxy.BodyLimits{UploadPaths: []string{"/api/v1/analysis/preview"}}})
+	proxies := make([]*ServiceProxy, 3)
+	for i, url := range []string{other.URL, other.URL, analysis.URL} {
+		sp, err := h.CreateServiceProxy(url, "", ServiceOptions{})
+		if err != nil {
+			t.Fatalf("CreateServiceProxy: %v", err)
+		}
+		proxies[i] = sp
+	}
+	h.SetupProxyRoutes(proxies[0], proxies[1], proxies[2])
+
+	// Предпросмотр — POST, и он не должен попасть в GET /analysis/{work_id}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/analysis/preview", strings.NewReader("--boundary--"))
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=boundary")
+	rec := httptest.NewRecorder()
+	h.GetRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 from the analysis service", rec.Code)
+	}
+	if len(got) != 1 || got[0] != "POST /api/v1/analysis/preview" {
+		t.Fatalf("analysis service received %v, want POST /api/v1/analysis/preview", got)
+	}
+}
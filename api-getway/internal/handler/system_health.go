@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/api-gateway/pkg/buildinfo"
+)
+
+// Состояния сервиса в сводке /health/system
+const (
+	UpstreamUp       = "up"
+	UpstreamNotReady = "not_ready"
+	UpstreamTimeout  = "timeout"
+	UpstreamDown     = "down"
+)
+
+type Upstream struct {
+	Name     string
+	ReadyURL string
+}
+
+type SystemHealthConfig struct {
+	Upstreams []Upstream
+	// Сколько ждать ответа /ready каждого сервиса
+	Timeout time.Duration
+}
+
+// RegisterSystemHealth добавляет GET /health/system: gateway параллельно опрашивает /ready
+// всех сервисов. Ответ от самого gateway означает, что он работает, поэтому недоступность
+// сервиса отражается в его статусе, а не ошибкой запроса.
+func (h *Handler) RegisterSystemHealth(config SystemHealthConfig) {
+	if config.Timeout <= 0 {
+		config.Timeout = 3 * time.Second
+	}
+
+	client := &http.Client{
+		// Редиректы считаются ответом сервиса, а не поводом идти дальше
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+
+	h.router.Get("/health/system", func(w http.ResponseWriter, r *http.Request) {
+		h.systemHealth(w, r, client, config)
+	})
+}
+
+func (h *Handler) systemHealth(w http.ResponseWriter, r *http.Request, client *http.Client, config SystemHealthConfig) {
+	started := time.Now()
+	services := make([]UpstreamHealth, len(config.Upstreams))
+
+	var wg sync.WaitGroup
+	for i, upstream := range config.Upstreams {
+		wg.Add(1)
+		go func(i int, upstream Upstream) {
+			defer wg.Done()
+			services[i] = checkUpstream(r.Context(), client, upstream, config.Timeout)
+		}(i, upstream)
+	}
+	wg.Wait()
+
+	up := 0
+	for _, service := range services {
+		if service.Status == UpstreamUp {
+			up++
+		} else {
+			h.logger.Warn().
+				Str("service", service.Name).
+				Str("status", service.Status).
+				Str("error", service.Error).
+				Msg("Upstream is not ready")
+		}
+	}
+
+	status, code := "healthy", http.StatusOK
+	switch {
+	case up == 0 && len(services) > 0:
+		status, code = "unhealthy", http.StatusServiceUnavailable
+	case up < len(services):
+		status, code = "degraded", http.StatusServiceUnavailable
+	}
+
+	response := SystemHealthResponse{
+		Status:    status,
+		Timestamp: time.Now().UTC(),
+		Gateway: GatewayHealth{
+			Status:  UpstreamUp,
+			Service: "api-gateway",
+			Version: buildinfo.Version,
+		},
+		Services:   services,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+
+	if err := writeJSON(w, code, response); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to write JSON response")
+	}
+}
+
+func checkUpstream(ctx context.Context, client *http.Client, upstream Upstream, timeout time.Duration) UpstreamHealth {
+	result := UpstreamHealth{
+		Name: upstream.Name,
+		URL:  upstream.ReadyURL,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream.ReadyURL, nil)
+	if err != nil {
+		result.Status = UpstreamDown
+		result.Error = err.Error()
+		return result
+	}
+
+	resp, err := client.Do(req)
+	result.LatencyMs = time.Since(started).Milliseconds()
+	if err != nil {
+		result.Status = UpstreamDown
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Status = UpstreamTimeout
+			result.Error = fmt.Sprintf("no response within %s", timeout)
+		} else {
+			result.Error = err.Error()
+		}
+		return result
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	result.HTTPStatus = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		result.Status = UpstreamUp
+	} else {
+		// Сервис отвечает, но сообщает, что не готов обслуживать запросы
+		result.Status = UpstreamNotReady
+		result.Error = fmt.Sprintf("ready check returned %d", resp.StatusCode)
+	}
+
+	return result
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/cors"
@@ -73,10 +74,27 @@ func Recovery(log zerolog.Logger) func(next http.Handler) http.Handler {
 
 func Timeout(timeout time.Duration) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
-		return http.TimeoutHandler(next, timeout, "Request timeout")
+		timeoutHandler := http.TimeoutHandler(next, timeout, "Request timeout")
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// WebSocket живёт дольше таймаута и требует Hijack, которого нет у TimeoutHandler;
+			// таймауты сервера с такого соединения тоже снимаются
+			if isWebSocketUpgrade(r) {
+				rc := http.NewResponseController(w)
+				_ = rc.SetReadDeadline(time.Time{})
+				_ = rc.SetWriteDeadline(time.Time{})
+				next.ServeHTTP(w, r)
+				return
+			}
+			timeoutHandler.ServeHTTP(w, r)
+		})
 	}
 }
 
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
 func NewCORS(allowedOrigins, allowedMethods, allowedHeaders, exposedHeaders []string,
 	allowCredentials bool, maxAge int) func(http.Handler) http.Handler {
 
@@ -0,0 +1,259 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitRule — скорость пополнения и ёмкость корзины токенов
+type RateLimitRule struct {
+	// Пустой Path — правило по умолчанию; иначе префикс пути, Method — необязательный фильтр
+	Path              string
+	Method            string
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// BucketStore расходует токен из корзины key; при нехватке возвращает время до следующего токена
+type BucketStore interface {
+	Take(ctx context.Context, key string, rule RateLimitRule) (bool, time.Duration, error)
+	Close() error
+}
+
+// RateLimiter ограничивает запросы к /api/ корзиной токенов на клиента. Клиент — адрес соединения;
+// X-User-ID и X-Forwarded-For учитываются, только если соединение пришло от доверенного прокси,
+// иначе клиент мог бы получать новую корзину на каждый запрос, меняя заголовки.
+type RateLimiter struct {
+	def            RateLimitRule
+	overrides      []RateLimitRule
+	trustedProxies []*net.IPNet
+	store          BucketStore
+}
+
+// NewRateLimiter — корзины хранятся в store: в памяти процесса (MemoryBuckets) или в Redis (RedisBuckets);
+// trustedProxies — подсети прокси перед шлюзом (см. ParseTrustedProxies)
+func NewRateLimiter(def RateLimitRule, overrides []RateLimitRule, trustedProxies []*net.IPNet, store BucketStore) *RateLimiter {
+	return &RateLimiter{
+		def:            def,
+		overrides:      overrides,
+		trustedProxies: trustedProxies,
+		store:          store,
+	}
+}
+
+// ParseTrustedProxies разбирает адреса (10.0.0.5) и подсети (10.0.0.0/8) доверенных прокси
+func ParseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", value)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			value = fmt.Sprintf("%s/%d", value, bits)
+		}
+
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// Close останавливает фоновую очистку и закрывает хранилище корзин
+func (l *RateLimiter) Close() error {
+	return l.store.Close()
+}
+
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rule := l.ruleFor(r)
+		if rule.RequestsPerSecond <= 0 || rule.Burst <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Для переопределённого пути корзина своя, чтобы он не расходовал общий лимит
+		key := l.clientKey(r) + "|" + rule.Method + " " + rule.Path
+		allowed, retryAfter, err := l.store.Take(r.Context(), key, rule)
+		if err == nil && !allowed {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(retryAfter)))
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": "Rate limit exceeded, try again later"}`))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ruleFor выбирает переопределение с самым длинным подходящим префиксом
+func (l *RateLimiter) ruleFor(r *http.Request) RateLimitRule {
+	rule := l.def
+	matched := -1
+	for _, o := range l.overrides {
+		if o.Method != "" && !strings.EqualFold(o.Method, r.Method) {
+			continue
+		}
+		if strings.HasPrefix(r.URL.Path, o.Path) && len(o.Path) > matched {
+			rule = o
+			matched = len(o.Path)
+		}
+	}
+	return rule
+}
+
+// MemoryBuckets — корзины в памяти процесса; простаивающие дольше idleTTL удаляются фоновой очисткой
+type MemoryBuckets struct {
+	mu       sync.Mutex
+	idleTTL  time.Duration
+	buckets  map[string]*tokenBucket
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+func NewMemoryBuckets(cleanupInterval, idleTTL time.Duration) *MemoryBuckets {
+	b := &MemoryBuckets{
+		idleTTL: idleTTL,
+		buckets: make(map[string]*tokenBucket),
+		stop:    make(chan struct{}),
+	}
+
+	if cleanupInterval > 0 && idleTTL > 0 {
+		go b.cleanupLoop(cleanupInterval)
+	}
+	return b
+}
+
+func (b *MemoryBuckets) Close() error {
+	b.stopOnce.Do(func() { close(b.stop) })
+	return nil
+}
+
+func (b *MemoryBuckets) Take(_ context.Context, key string, rule RateLimitRule) (bool, time.Duration, error) {
+	allowed, wait := b.take(key, rule, time.Now())
+	return allowed, wait, nil
+}
+
+func (b *MemoryBuckets) take(key string, rule RateLimitRule, now time.Time) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	burst := float64(rule.Burst)
+	bucket, ok := b.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, lastSeen: now}
+		b.buckets[key] = bucket
+	} else {
+		bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.lastSeen).Seconds()*rule.RequestsPerSecond)
+		bucket.lastSeen = now
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true, 0
+	}
+
+	wait := (1 - bucket.tokens) / rule.RequestsPerSecond
+	return false, time.Duration(wait * float64(time.Second))
+}
+
+func (b *MemoryBuckets) cleanupLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case now := <-ticker.C:
+			b.cleanup(now)
+		}
+	}
+}
+
+func (b *MemoryBuckets) cleanup(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, bucket := range b.buckets {
+		if now.Sub(bucket.lastSeen) >= b.idleTTL {
+			delete(b.buckets, key)
+		}
+	}
+}
+
+// clientKey — от доверенного прокси: X-User-ID, затем ближайший к шлюзу недоверенный адрес
+// из X-Forwarded-For (левые записи клиент может дописать сам); иначе адрес соединения
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !l.trusted(host) {
+		return "ip:" + host
+	}
+
+	if userID := r.Header.Get("X-User-ID"); userID != "" {
+		return "user:" + userID
+	}
+
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(hops[i])
+		if ip == "" {
+			continue
+		}
+		if !l.trusted(ip) {
+			return "ip:" + ip
+		}
+		host = ip
+	}
+	return "ip:" + host
+}
+
+func (l *RateLimiter) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range l.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// takeTokenScript пополняет и расходует корзину атомарно; время берётся у Redis,
+// чтобы расхождение часов экземпляров шлюза не влияло на лимит
+var takeTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) / 1000 * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, wait}
+`)
+
+// RedisBuckets — корзины в Redis, общие для всех экземпляров шлюза. Пока Redis не отвечает,
+// лимит считается по корзинам в памяти процесса, чтобы защита не пропадала совсем.
+type RedisBuckets struct {
+	client   *redis.Client
+	prefix   string
+	idleTTL  time.Duration
+	fallback *MemoryBuckets
+	logger   zerolog.Logger
+}
+
+func NewRedisBuckets(ctx context.Context, redisURL, prefix string, cleanupInterval, idleTTL time.Duration, logger zerolog.Logger) (*RedisBuckets, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis url: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to ping redis: %w", err)
+	}
+
+	if idleTTL <= 0 {
+		idleTTL = 10 * time.Minute
+	}
+
+	return &RedisBuckets{
+		client:   client,
+		prefix:   prefix,
+		idleTTL:  idleTTL,
+		fallback: NewMemoryBuckets(cleanupInterval, idleTTL),
+		logger:   logger,
+	}, nil
+}
+
+func (b *RedisBuckets) Take(ctx context.Context, key string, rule RateLimitRule) (bool, time.Duration, error) {
+	res, err := takeTokenScript.Run(ctx, b.client, []string{b.prefix + key},
+		rule.RequestsPerSecond, rule.Burst, b.idleTTL.Milliseconds()).Int64Slice()
+	if err != nil || len(res) != 2 {
+		if ctx.Err() == nil {
+			b.logger.Warn().Err(err).Msg("Redis rate limit failed, using in-memory bucket")
+		}
+		return b.fallback.Take(ctx, key, rule)
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+func (b *RedisBuckets) Close() error {
+	b.fallback.Close()
+	return b.client.Close()
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestLimiter(t *testing.T, rule RateLimitRule, trusted ...string) *RateLimiter {
+	t.Helper()
+
+	trustedProxies, err := ParseTrustedProxies(trusted)
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+	store := NewMemoryBuckets(0, 0)
+	t.Cleanup(func() { store.Close() })
+	return NewRateLimiter(rule, nil, trustedProxies, store)
+}
+
+func doRequest(handler http.Handler, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/works", nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestRateLimiterExhaustsBucketAndRecovers(t *testing.T) {
+	limiter := newTestLimiter(t, RateLimitRule{RequestsPerSecond: 20, Burst: 2})
+	handler := limiter.Middleware(okHandler())
+
+	for i := 0; i < 2; i++ {
+		if rec := doRequest(handler, "203.0.113.7:5000", nil); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i+1, rec.Code)
+		}
+	}
+
+	rec := doRequest(handler, "203.0.113.7:5000", nil)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429 after the burst", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("Retry-After = %q, want 1", rec.Header().Get("Retry-After"))
+	}
+
+	// Другой клиент расходует свою корзину
+	if rec := doRequest(handler, "203.0.113.8:5000", nil); rec.Code != http.StatusOK {
+		t.Fatalf("other client: status = %d, want 200", rec.Code)
+	}
+
+	// 20 токенов в секунду — через 100 мс токен снова есть
+	time.Sleep(100 * time.Millisecond)
+	if rec := doRequest(handler, "203.0.113.7:5000", nil); rec.Code != http.StatusOK {
+		t.Fatalf("after refill: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestMemoryBucketsRefillSchedule(t *testing.T) {
+	b := NewMemoryBuckets(0, 0)
+	defer b.Close()
+	rule := RateLimitRule{RequestsPerSecond: 2, Burst: 1}
+	start := time.Unix(1000, 0)
+
+	if ok, _ := b.take("k", rule, start); !ok {
+		t.Fatal("first request rejected")
+	}
+	ok, wait := b.take("k", rule, start)
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("take = %v, %v; want rejected with 500ms wait", ok, wait)
+	}
+	if ok, _ := b.take("k", rule, start.Add(499*time.Millisecond)); ok {
+		t.Fatal("token granted before the refill")
+	}
+	if ok, _ := b.take("k", rule, start.Add(time.Second)); !ok {
+		t.Fatal("token not granted after the refill")
+	}
+}
+
+func TestRateLimiterIgnoresSpoofedHeaders(t *testing.T) {
+	limiter := newTestLimiter(t, RateLimitRule{RequestsPerSecond: 0.01, Burst: 1})
+	handler := limiter.Middleware(okHandler())
+
+	if rec := doRequest(handler, "203.0.113.7:5000", nil); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+
+	// Без доверенных прокси новые X-User-ID и X-Forwarded-For не дают новой корзины
+	spoofed := []map[string]string{
+		{"X-User-ID": "someone-else"},
+		{"X-Forwarded-For": "198.51.100.1"},
+	}
+	for _, headers := range spoofed {
+		if rec := doRequest(handler, "203.0.113.7:5000", headers); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("headers %v: status = %d, want 429", headers, rec.Code)
+		}
+	}
+}
+
+func TestRateLimiterTrustedProxy(t *testing.T) {
+	limiter := newTestLimiter(t, RateLimitRule{RequestsPerSecond: 0.01, Burst: 1}, "10.0.0.0/8")
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "ip:203.0.113.7"},
+		{"user behind proxy", "10.0.0.2:5000", map[string]string{"X-User-ID": "u1"}, "user:u1"},
+		{"client behind proxy", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "ip:198.51.100.1"},
+		// Левый адрес дописан клиентом, правый — добавлен прокси
+		{"forged hop", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "1.2.3.4, 198.51.100.1"}, "ip:198.51.100.1"},
+		{"chain of proxies", "10.0.0.2:5000", map[string]string{"X-Forwarded-For": "198.51.100.1, 10.0.0.3"}, "ip:198.51.100.1"},
+		{"proxy without header", "10.0.0.2:5000", nil, "ip:10.0.0.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/works", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			if got := limiter.clientKey(req); got != tt.want {
+				t.Fatalf("clientKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	if _, err := ParseTrustedProxies([]string{"10.0.0.1", "172.16.0.0/12", "::1"}); err != nil {
+		t.Fatalf("valid proxies: %v", err)
+	}
+	if _, err := ParseTrustedProxies([]string{"proxy.local"}); err == nil {
+		t.Fatal("hostname accepted as a trusted proxy")
+	}
+}
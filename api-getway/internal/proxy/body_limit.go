@@ -0,0 +1,99 @@
+package proxy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// BodyLimits ограничивает размер тела запроса, которое шлюз передаёт сервису.
+// Загрузка файла — запрос не в JSON к пути из UploadPaths: для неё свой лимит, и её тело
+// передаётся сервису потоком, без буферизации для повторов.
+type BodyLimits struct {
+	// Лимит тела обычных запросов, байт (0 — без ограничения)
+	MaxBodySize int64
+	// Лимит тела загрузок файлов, байт (0 — без ограничения)
+	MaxUploadBodySize int64
+	// Префиксы путей загрузок
+	UploadPaths []string
+}
+
+// IsUpload — запрос загружает файл: путь из UploadPaths и тело не JSON
+func (l BodyLimits) IsUpload(r *http.Request) bool {
+	if isJSON(r) {
+		return false
+	}
+	for _, prefix := range l.UploadPaths {
+		if prefix != "" && strings.HasPrefix(r.URL.Path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bufferable — тело запроса можно держать в памяти для повтора: его нет или это небольшой JSON.
+// Загрузки передаются сервису потоком и не повторяются
+func (l BodyLimits) Bufferable(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return true
+	}
+	return isJSON(r) && !l.IsUpload(r)
+}
+
+// Limit — лимит тела запроса r
+func (l BodyLimits) Limit(r *http.Request) int64 {
+	if l.IsUpload(r) {
+		return l.MaxUploadBodySize
+	}
+	return l.MaxBodySize
+}
+
+// Apply оборачивает тело запроса в http.MaxBytesReader. Если Content-Length уже больше лимита,
+// отвечает 413 сам и возвращает false — запрос не передаётся сервису.
+func (l BodyLimits) Apply(w http.ResponseWriter, r *http.Request) bool {
+	limit := l.Limit(r)
+	if limit <= 0 || r.Body == nil || r.Body == http.NoBody {
+		return true
+	}
+
+	if r.ContentLength > limit {
+		BodyTooLarge(w, limit)
+		return false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	return true
+}
+
+// LimitExceeded сообщает, вызван ли err превышением лимита http.MaxBytesReader, и возвращает этот лимит
+func LimitExceeded(err error) (int64, bool) {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return 0, false
+	}
+	return maxBytesErr.Limit, true
+}
+
+// BodyTooLarge отвечает 413 с лимитом в сообщении
+func BodyTooLarge(w http.ResponseWriter, limit int64) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   "Request entity too large",
+		"message": fmt.Sprintf("Request body must not exceed %d bytes", limit),
+		"code":    "BODY_TOO_LARGE",
+	})
+}
+
+// isJSON — тело в JSON или без Content-Type (прокси отправляет такие запросы как JSON)
+func isJSON(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
@@ -0,0 +1,145 @@
+package proxy
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker размыкается после threshold подряд неудач (5xx или таймаут) и, пока разомкнут,
+// отклоняет запросы без обращения к сервису. Через cooldown пропускает один пробный запрос:
+// успех замыкает цепь, неудача снова размыкает её.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	name      string
+	threshold int
+	cooldown  time.Duration
+	logger    zerolog.Logger
+
+	state    breakerState
+	failures int
+	openedAt time.Time
+	// Время выдачи пробного запроса; пока проба не завершилась, другие запросы отклоняются
+	probeAt time.Time
+}
+
+func NewCircuitBreaker(name string, threshold int, cooldown time.Duration, logger zerolog.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		logger:    logger,
+	}
+}
+
+// Allow сообщает, можно ли отправить запрос сервису
+func (b *CircuitBreaker) Allow() bool {
+	if b == nil || b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	switch b.state {
+	case breakerOpen:
+		if now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(breakerHalfOpen)
+		b.probeAt = now
+		return true
+	case breakerHalfOpen:
+		// Проба, результат которой так и не пришёл (например, клиент отменил запрос), не должна держать цепь вечно
+		if now.Sub(b.probeAt) < b.cooldown {
+			return false
+		}
+		b.probeAt = now
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreaker) RecordSuccess() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	if b.state != breakerClosed {
+		b.setState(breakerClosed)
+	}
+}
+
+func (b *CircuitBreaker) RecordFailure() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == breakerHalfOpen || (b.state == breakerClosed && b.failures >= b.threshold) {
+		b.openedAt = time.Now()
+		b.setState(breakerOpen)
+	}
+}
+
+// RetryAfter — сколько осталось до пробного запроса; 0, если цепь не разомкнута
+func (b *CircuitBreaker) RetryAfter() time.Duration {
+	if b == nil {
+		return 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != breakerOpen {
+		return 0
+	}
+	if left := b.cooldown - time.Since(b.openedAt); left > 0 {
+		return left
+	}
+	return 0
+}
+
+func (b *CircuitBreaker) setState(state breakerState) {
+	event := b.logger.Info()
+	if state == breakerOpen {
+		event = b.logger.Warn()
+	}
+	event.
+		Str("target", b.name).
+		Str("from", b.state.String()).
+		Str("to", state.String()).
+		Int("consecutive_failures", b.failures).
+		Msg("Circuit breaker state changed")
+
+	b.state = state
+}
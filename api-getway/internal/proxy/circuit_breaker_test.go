@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	const cooldown = 20 * time.Millisecond
+	b := NewCircuitBreaker("work-service:8080", 3, cooldown, zerolog.Nop())
+
+	// Успех между неудачами сбрасывает счётчик: размыкают только неудачи подряд
+	b.RecordFailure()
+	b.RecordFailure()
+	b.RecordSuccess()
+	b.RecordFailure()
+	b.RecordFailure()
+	if !b.Allow() {
+		t.Fatal("breaker opened before threshold consecutive failures")
+	}
+
+	b.RecordFailure()
+	if b.Allow() {
+		t.Fatal("breaker allows requests after threshold consecutive failures")
+	}
+	if left := b.RetryAfter(); left <= 0 || left > cooldown {
+		t.Fatalf("RetryAfter = %v, want within cooldown %v", left, cooldown)
+	}
+
+	// После cooldown проходит ровно один пробный запрос; его неудача снова размыкает цепь
+	time.Sleep(cooldown)
+	if !b.Allow() {
+		t.Fatal("probe request not allowed after cooldown")
+	}
+	if b.Allow() {
+		t.Fatal("second request allowed while the probe is in flight")
+	}
+	b.RecordFailure()
+	if b.Allow() {
+		t.Fatal("breaker closed after a failed probe")
+	}
+
+	time.Sleep(cooldown)
+	if !b.Allow() {
+		t.Fatal("probe request not allowed after second cooldown")
+	}
+	b.RecordSuccess()
+	for i := 0; i < 3; i++ {
+		if !b.Allow() {
+			t.Fatal("breaker still rejects requests after a successful probe")
+		}
+	}
+	if b.RetryAfter() != 0 {
+		t.Fatal("RetryAfter non-zero for a closed breaker")
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b := NewCircuitBreaker("work-service:8080", 0, time.Minute, zerolog.Nop())
+	for i := 0; i < 10; i++ {
+		b.RecordFailure()
+	}
+	if !b.Allow() {
+		t.Fatal("breaker with zero threshold rejected a request")
+	}
+}
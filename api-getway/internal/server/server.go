@@ -58,7 +58,9 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 func (s *Server) SetupMiddleware(
 	corsMiddleware func(http.Handler) http.Handler,
+	metricsMiddleware func(http.Handler) http.Handler,
 	loggerMiddleware func(http.Handler) http.Handler,
+	rateLimitMiddleware func(http.Handler) http.Handler,
 	recoveryMiddleware func(http.Handler) http.Handler,
 	timeoutMiddleware func(http.Handler) http.Handler,
 ) {
@@ -73,6 +75,10 @@ func (s *Server) SetupMiddleware(
 		s.rootRouter.Use(corsMiddleware) // cors ставится первым
 	}
 
+	if metricsMiddleware != nil {
+		s.rootRouter.Use(metricsMiddleware) // после сжатия: размеры ответа считаются до gzip
+	}
+
 	if timeoutMiddleware != nil {
 		s.rootRouter.Use(timeoutMiddleware) // таймаут перед логированием
 	}
@@ -81,6 +87,10 @@ func (s *Server) SetupMiddleware(
 		s.rootRouter.Use(loggerMiddleware) // логирование после таймаута
 	}
 
+	if rateLimitMiddleware != nil {
+		s.rootRouter.Use(rateLimitMiddleware) // после логирования: отклонённые запросы тоже попадают в лог
+	}
+
 	if recoveryMiddleware != nil {
 		s.rootRouter.Use(recoveryMiddleware) // recovery ближе к обработчику
 	}
@@ -19,6 +19,8 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to load configuration")
 	}
 
+	runStartupChecks(cfg, log)
+
 	application, err := app.New(cfg, log)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to create application")
@@ -0,0 +1,316 @@
+// Package httpmetrics собирает метрики HTTP по маршрутам chi: число запросов, задержку
+// и размеры тел запроса и ответа в виде гистограмм. Метки — метод и шаблон маршрута,
+// а не фактический путь, поэтому число рядов не растёт с числом идентификаторов.
+package httpmetrics
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+var (
+	DefaultSizeBuckets    = []int64{1 << 10, 10 << 10, 100 << 10, 1 << 20, 10 << 20}
+	DefaultLatencyBuckets = []time.Duration{
+		10 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		5 * time.Second,
+	}
+)
+
+// Маршрут для запросов, не совпавших ни с одним шаблоном
+const unmatchedRoute = "<unmatched>"
+
+type Config struct {
+	// Границы корзин гистограмм размеров, байт
+	SizeBuckets    []int64
+	LatencyBuckets []time.Duration
+}
+
+// Bucket — накопительная корзина: сколько наблюдений не больше LE
+type Bucket struct {
+	LE    int64 `json:"le"`
+	Count int64 `json:"count"`
+}
+
+type Histogram struct {
+	Count   int64    `json:"count"`
+	Sum     int64    `json:"sum"`
+	Max     int64    `json:"max"`
+	Avg     float64  `json:"avg"`
+	Buckets []Bucket `json:"buckets"`
+}
+
+type RouteStats struct {
+	Route         string    `json:"route"`
+	Requests      int64     `json:"requests"`
+	Errors        int64     `json:"errors"`
+	LatencyMs     Histogram `json:"latency_ms"`
+	RequestBytes  Histogram `json:"request_bytes"`
+	ResponseBytes Histogram `json:"response_bytes"`
+}
+
+type Registry struct {
+	mu             sync.Mutex
+	routes         map[string]*routeMetrics
+	sizeBuckets    []int64
+	latencyBuckets []int64
+}
+
+type routeMetrics struct {
+	requests      int64
+	errors        int64
+	latency       *histogram
+	requestBytes  *histogram
+	responseBytes *histogram
+}
+
+func New(config Config) *Registry {
+	if len(config.SizeBuckets) == 0 {
+		config.SizeBuckets = DefaultSizeBuckets
+	}
+	if len(config.LatencyBuckets) == 0 {
+		config.LatencyBuckets = DefaultLatencyBuckets
+	}
+
+	latency := make([]int64, len(config.LatencyBuckets))
+	for i, d := range config.LatencyBuckets {
+		latency[i] = d.Milliseconds()
+	}
+
+	return &Registry{
+		routes:         make(map[string]*routeMetrics),
+		sizeBuckets:    sortedCopy(config.SizeBuckets),
+		latencyBuckets: sortedCopy(latency),
+	}
+}
+
+// Middleware должен стоять на корневом роутере: шаблон маршрута известен только
+// после того, как chi разобрал путь, поэтому метка берётся уже после обработки запроса.
+// Размер ответа считается до сжатия, если сжатие навешано внутри.
+func (reg *Registry) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		var body *countingBody
+		if r.Body != nil && r.Body != http.NoBody {
+			body = &countingBody{ReadCloser: r.Body}
+			r.Body = body
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		requestBytes := int64(0)
+		if body != nil {
+			requestBytes = body.n
+		}
+		// Обработчик мог отклонить запрос, не читая тело
+		if requestBytes == 0 && r.ContentLength > 0 {
+			requestBytes = r.ContentLength
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		reg.observe(routeLabel(r, status), status, time.Since(start), requestBytes, int64(ww.BytesWritten()))
+	})
+}
+
+// Snapshot возвращает статистику по маршрутам, отсортированную по суммарному объёму ответов
+func (reg *Registry) Snapshot() []RouteStats {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	stats := make([]RouteStats, 0, len(reg.routes))
+	for route, m := range reg.routes {
+		stats = append(stats, RouteStats{
+			Route:         route,
+			Requests:      m.requests,
+			Errors:        m.errors,
+			LatencyMs:     m.latency.snapshot(),
+			RequestBytes:  m.requestBytes.snapshot(),
+			ResponseBytes: m.responseBytes.snapshot(),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ResponseBytes.Sum != stats[j].ResponseBytes.Sum {
+			return stats[i].ResponseBytes.Sum > stats[j].ResponseBytes.Sum
+		}
+		return stats[i].Route < stats[j].Route
+	})
+
+	return stats
+}
+
+// Handler отдаёт снимок метрик в JSON. Query: sort=response_bytes|request_bytes|latency|requests,
+// by=sum|max|avg (по умолчанию sum), limit — сколько маршрутов вернуть.
+func (reg *Registry) Handler(w http.ResponseWriter, r *http.Request) {
+	stats := reg.Snapshot()
+
+	sortBy := r.URL.Query().Get("sort")
+	by := r.URL.Query().Get("by")
+	if sortBy != "" {
+		key, ok := sortKey(sortBy, by)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error": "sort must be one of response_bytes, request_bytes, latency, requests; by must be sum, max or avg",
+			})
+			return
+		}
+		sort.SliceStable(stats, func(i, j int) bool { return key(stats[i]) > key(stats[j]) })
+	}
+
+	if limit, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && limit > 0 && limit < len(stats) {
+		stats = stats[:limit]
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"routes": stats,
+	})
+}
+
+func (reg *Registry) observe(route string, status int, latency time.Duration, requestBytes, responseBytes int64) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	m, ok := reg.routes[route]
+	if !ok {
+		m = &routeMetrics{
+			latency:       newHistogram(reg.latencyBuckets),
+			requestBytes:  newHistogram(reg.sizeBuckets),
+			responseBytes: newHistogram(reg.sizeBuckets),
+		}
+		reg.routes[route] = m
+	}
+
+	m.requests++
+	if status >= http.StatusInternalServerError {
+		m.errors++
+	}
+	m.latency.observe(latency.Milliseconds())
+	m.requestBytes.observe(requestBytes)
+	m.responseBytes.observe(responseBytes)
+}
+
+func routeLabel(r *http.Request, status int) string {
+	pattern := ""
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		pattern = rctx.RoutePattern()
+	}
+	// Смонтированный роутер оставляет шаблон "/*", даже если внутри ничего не совпало
+	if pattern == "" || (pattern == "/*" && status == http.StatusNotFound) {
+		pattern = unmatchedRoute
+	}
+	return r.Method + " " + pattern
+}
+
+func sortKey(sortBy, by string) (func(RouteStats) float64, bool) {
+	var pick func(RouteStats) Histogram
+	switch sortBy {
+	case "response_bytes":
+		pick = func(s RouteStats) Histogram { return s.ResponseBytes }
+	case "request_bytes":
+		pick = func(s RouteStats) Histogram { return s.RequestBytes }
+	case "latency":
+		pick = func(s RouteStats) Histogram { return s.LatencyMs }
+	case "requests":
+		return func(s RouteStats) float64 { return float64(s.Requests) }, true
+	default:
+		return nil, false
+	}
+
+	switch by {
+	case "", "sum":
+		return func(s RouteStats) float64 { return float64(pick(s).Sum) }, true
+	case "max":
+		return func(s RouteStats) float64 { return float64(pick(s).Max) }, true
+	case "avg":
+		return func(s RouteStats) float64 { return pick(s).Avg }, true
+	}
+	return nil, false
+}
+
+type histogram struct {
+	bounds []int64
+	counts []int64
+	count  int64
+	sum    int64
+	max    int64
+}
+
+func newHistogram(bounds []int64) *histogram {
+	return &histogram{
+		bounds: bounds,
+		counts: make([]int64, len(bounds)),
+	}
+}
+
+func (h *histogram) observe(v int64) {
+	h.count++
+	h.sum += v
+	if v > h.max {
+		h.max = v
+	}
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+}
+
+func (h *histogram) snapshot() Histogram {
+	buckets := make([]Bucket, len(h.bounds))
+	for i, bound := range h.bounds {
+		buckets[i] = Bucket{LE: bound, Count: h.counts[i]}
+	}
+
+	avg := 0.0
+	if h.count > 0 {
+		avg = float64(h.sum) / float64(h.count)
+	}
+
+	return Histogram{
+		Count:   h.count,
+		Sum:     h.sum,
+		Max:     h.max,
+		Avg:     avg,
+		Buckets: buckets,
+	}
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func sortedCopy(values []int64) []int64 {
+	out := append([]int64(nil), values...)
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/RubachokBoss/plagiarism-checker/api-gateway/internal/config"
+	"github.com/RubachokBoss/plagiarism-checker/api-gateway/pkg/selfcheck"
+	"github.com/rs/zerolog"
+)
+
+// runStartupChecks проверяет конфигурацию и доступность сервисов, выводит сводку OK/FAIL.
+// Недоступный сервис не мешает запуску: gateway отвечает 503, пока тот не поднимется.
+func runStartupChecks(cfg *config.Config, log zerolog.Logger) {
+	checks := []selfcheck.Check{
+		{Name: "config", Run: func(context.Context) error { return cfg.Validate() }},
+	}
+	if cfg.Startup.SelfCheck {
+		checks = append(checks,
+			serviceCheck("work-service", cfg.Services.Work),
+			serviceCheck("file-service", cfg.Services.File),
+			serviceCheck("analysis-service", cfg.Services.Analysis),
+		)
+	}
+
+	if err := selfcheck.Run(context.Background(), log, cfg.Startup.CheckTimeout, checks...); err != nil {
+		log.Fatal().Err(err).Msg("Startup self-check failed")
+	}
+}
+
+func serviceCheck(name string, svc config.ServiceConfig) selfcheck.Check {
+	return selfcheck.Check{
+		Name:     name,
+		Optional: true,
+		Run: func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, svc.URL+svc.HealthEndpoint, nil)
+			if err != nil {
+				return err
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("health check returned status %d", resp.StatusCode)
+			}
+			return nil
+		},
+	}
+}
@@ -12,6 +12,9 @@ import (
 
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/repository"
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/service"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/pkg/buildinfo"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/pkg/httpmetrics"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/pkg/tracing"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -19,29 +22,21 @@ import (
 )
 
 type App struct {
-	server *http.Server
-	logger zerolog.Logger
-	config *config.Config
-	db     *sql.DB
+	server        *http.Server
+	logger        zerolog.Logger
+	config        *config.Config
+	db            *sql.DB
+	chunkedUpload service.ChunkedUploadService
+	deleteService service.DeleteService
+	stopCleanup   context.CancelFunc
 }
 
 func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
-	minioRepo, err := repository.NewMinIORepository(
-		cfg.MinIO.Endpoint,
-		cfg.MinIO.AccessKey,
-		cfg.MinIO.SecretKey,
-		cfg.Storage.BucketName,
-		cfg.Storage.Region,
-		cfg.MinIO.UseSSL,
-		cfg.MinIO.Timeout,
-		log,
-	)
+	storageRepo, err := newStorageRepository(cfg, log)
 	if err != nil {
 		return nil, err
 	}
 
-	storageRepo := repository.NewStorageRepository(minioRepo, log)
-
 	metadataRepo := repository.NewFileMetadataRepository(db, log)
 
 	hashService := service.NewHashService(cfg.Hash.Algorithm)
@@ -50,13 +45,36 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 		metadataRepo,
 		storageRepo,
 		hashService,
+		service.NewTextExtractor(),
 		log,
 		service.UploadConfig{
-			MaxUploadSize:  cfg.Server.MaxUploadSize,
-			BucketName:     cfg.Storage.BucketName,
-			AllowedTypes:   []string{".txt", ".pdf", ".doc", ".docx", ".zip", ".rar"},
-			GenerateHash:   true,
-			CheckDuplicate: true,
+			MaxUploadSize:     cfg.Server.MaxUploadSize,
+			BucketName:        cfg.Storage.BucketName,
+			AllowedTypes:      []string{".txt", ".pdf", ".doc", ".docx", ".zip", ".rar"},
+			GenerateHash:      true,
+			CheckDuplicate:    true,
+			VerifyContentType: cfg.Server.VerifyContentType,
+			Compression: service.CompressionConfig{
+				Enabled:   cfg.Storage.Compression.Enabled,
+				Types:     cfg.Storage.Compression.Types,
+				MinSize:   cfg.Storage.Compression.MinSize,
+				Algorithm: cfg.Storage.Compression.Algorithm,
+			},
+		},
+	)
+
+	chunkedUploadService := service.NewChunkedUploadService(
+		repository.NewUploadSessionRepository(db, log),
+		storageRepo,
+		uploadService,
+		log,
+		service.ChunkedUploadConfig{
+			BucketName:    cfg.Storage.BucketName,
+			TempPrefix:    cfg.Chunked.TempPrefix,
+			MaxChunkSize:  cfg.Chunked.MaxChunkSize,
+			MaxChunks:     cfg.Chunked.MaxChunks,
+			MaxUploadSize: cfg.Server.MaxUploadSize,
+			SessionTTL:    cfg.Chunked.SessionTTL,
 		},
 	)
 
@@ -65,6 +83,10 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 		storageRepo,
 		log,
 		cfg.Storage.BucketName,
+		service.DownloadConfig{
+			MaxPresignedExpiry:  cfg.Storage.PresignedMaxExpiry,
+			RejectOverMaxExpiry: cfg.Storage.PresignedOverMax == "reject",
+		},
 	)
 
 	deleteService := service.NewDeleteService(
@@ -72,21 +94,38 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 		storageRepo,
 		log,
 		cfg.Storage.BucketName,
+		service.DeleteConfig{
+			ExpiredGracePeriod: cfg.Expiry.GracePeriod,
+			SweepBatchSize:     cfg.Expiry.BatchSize,
+		},
 	)
 
 	handler := httpd.NewHandler(
 		uploadService,
+		chunkedUploadService,
 		downloadService,
 		deleteService,
 		metadataRepo, // Добавляем репозиторий метаданных
 		storageRepo,  // Добавляем репозиторий хранилища
 		log,
+		cfg.Server.DetailedValidationErrors,
 	)
 
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
 	router.Use(middleware.RealIP)
+	router.Use(tracing.Middleware("/health", "/ready", "/version", cfg.Metrics.Path))
+
+	var metrics *httpmetrics.Registry
+	if cfg.Metrics.Enabled {
+		metrics = httpmetrics.New(httpmetrics.Config{
+			SizeBuckets:    cfg.Metrics.SizeBuckets,
+			LatencyBuckets: cfg.Metrics.LatencyBuckets,
+		})
+		router.Use(metrics.Middleware)
+	}
+
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Timeout(60 * time.Second))
@@ -100,7 +139,16 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 		MaxAge:           cfg.CORS.MaxAge,
 	}))
 
+	// Без ключа доступны только проверки здоровья, версия и метрики
+	router.Use(httpd.RequireAPIKey(cfg.Auth.APIKeys, "/health", "/ready", "/version", cfg.Metrics.Path))
+
 	handler.RegisterRoutes(router)
+	if cfg.Server.VersionEndpoint {
+		router.Get("/version", buildinfo.Handler("file-service"))
+	}
+	if metrics != nil {
+		router.Get(cfg.Metrics.Path, metrics.Handler)
+	}
 
 	server := &http.Server{
 		Addr:         cfg.Server.Address,
@@ -111,14 +159,21 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 	}
 
 	return &App{
-		server: server,
-		logger: log,
-		config: cfg,
-		db:     db,
+		server:        server,
+		logger:        log,
+		config:        cfg,
+		db:            db,
+		chunkedUpload: chunkedUploadService,
+		deleteService: deleteService,
 	}, nil
 }
 
 func (a *App) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopCleanup = cancel
+	go a.cleanupUploadSessions(ctx)
+	go a.sweepExpiredFiles(ctx)
+
 	a.logger.Info().Msgf("Starting file service on %s", a.config.Server.Address)
 	return a.server.ListenAndServe()
 }
@@ -126,6 +181,10 @@ func (a *App) Run() error {
 func (a *App) Shutdown(ctx context.Context) error {
 	a.logger.Info().Msg("Shutting down file service...")
 
+	if a.stopCleanup != nil {
+		a.stopCleanup()
+	}
+
 	if a.db != nil {
 		if err := a.db.Close(); err != nil {
 			a.logger.Error().Err(err).Msg("Failed to close database connection")
@@ -134,3 +193,100 @@ func (a *App) Shutdown(ctx context.Context) error {
 
 	return a.server.Shutdown(ctx)
 }
+
+// cleanupUploadSessions периодически удаляет части загрузок, которые не были завершены до истечения сессии
+func (a *App) cleanupUploadSessions(ctx context.Context) {
+	interval := a.config.Chunked.CleanupInterval
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cleaned, err := a.chunkedUpload.CleanupExpired(ctx)
+			if err != nil {
+				a.logger.Error().Err(err).Msg("Failed to clean up expired upload sessions")
+				continue
+			}
+			if cleaned > 0 {
+				a.logger.Info().Int("sessions", cleaned).Msg("Expired upload sessions cleaned up")
+			}
+		}
+	}
+}
+
+// sweepExpiredFiles периодически убирает файлы с истёкшим сроком хранения
+func (a *App) sweepExpiredFiles(ctx context.Context) {
+	interval := a.config.Expiry.SweepInterval
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			softDeleted, hardDeleted, err := a.deleteService.SweepExpiredFiles(ctx)
+			if err != nil {
+				a.logger.Error().Err(err).Msg("Failed to sweep expired files")
+				continue
+			}
+			if softDeleted > 0 || hardDeleted > 0 {
+				a.logger.Info().
+					Int("soft_deleted", softDeleted).
+					Int("hard_deleted", hardDeleted).
+					Msg("Expired files swept")
+			}
+		}
+	}
+}
+
+// newStorageRepository выбирает хранилище по storage.provider
+func newStorageRepository(cfg *config.Config, log zerolog.Logger) (repository.StorageRepository, error) {
+	var provider repository.StorageRepository
+	switch cfg.Storage.Provider {
+	case "s3":
+		s3Repo, err := repository.NewS3Repository(
+			cfg.S3.Endpoint,
+			cfg.S3.AccessKey,
+			cfg.S3.SecretKey,
+			cfg.S3.SessionToken,
+			cfg.Storage.BucketName,
+			cfg.Storage.Region,
+			cfg.S3.UseSSL,
+			cfg.S3.Timeout,
+			log,
+		)
+		if err != nil {
+			return nil, err
+		}
+		provider = s3Repo
+	default:
+		minioRepo, err := repository.NewMinIORepository(
+			cfg.MinIO.Endpoint,
+			cfg.MinIO.AccessKey,
+			cfg.MinIO.SecretKey,
+			cfg.Storage.BucketName,
+			cfg.Storage.Region,
+			cfg.MinIO.UseSSL,
+			cfg.MinIO.Timeout,
+			log,
+		)
+		if err != nil {
+			return nil, err
+		}
+		provider = minioRepo
+	}
+
+	return repository.NewStorageRepository(provider, log), nil
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/config"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/repository"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/service"
+	"github.com/rs/zerolog"
+)
+
+// MigrateStorage переносит файлы между бакетами хранилища из конфигурации (команда migrate-storage)
+func MigrateStorage(ctx context.Context, cfg *config.Config, log zerolog.Logger, db *sql.DB, opts service.StorageMigrationOptions) (*models.StorageMigration, error) {
+	storageRepo, err := newStorageRepository(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	migrationService := service.NewStorageMigrationService(
+		repository.NewFileMetadataRepository(db, log),
+		repository.NewStorageMigrationRepository(db, log),
+		storageRepo,
+		service.NewHashService(cfg.Hash.Algorithm),
+		log,
+	)
+
+	return migrationService.Migrate(ctx, opts)
+}
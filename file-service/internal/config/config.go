@@ -13,9 +13,19 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	Storage  StorageConfig  `mapstructure:"storage"`
 	MinIO    MinIOConfig    `mapstructure:"minio"`
+	S3       S3Config       `mapstructure:"s3"`
 	Hash     HashConfig     `mapstructure:"hash"`
+	Chunked  ChunkedConfig  `mapstructure:"chunked_upload"`
+	Expiry   ExpiryConfig   `mapstructure:"expiry"`
+	Auth     AuthConfig     `mapstructure:"auth"`
+	Startup  StartupConfig  `mapstructure:"startup"`
 	Logging  LoggingConfig  `mapstructure:"logging"`
+	Metrics  MetricsConfig  `mapstructure:"metrics"`
+	Tracing  TracingConfig  `mapstructure:"tracing"`
 	CORS     CORSConfig     `mapstructure:"cors"`
+
+	// Перенос файлов между бакетами командой migrate-storage
+	StorageMigration StorageMigrationConfig `mapstructure:"storage_migration"`
 }
 
 type ServerConfig struct {
@@ -25,6 +35,12 @@ type ServerConfig struct {
 	IdleTimeout     time.Duration `mapstructure:"idle_timeout"`
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
 	MaxUploadSize   int64         `mapstructure:"max_upload_size"`
+	// GET /version без ключа: версия, коммит и время сборки, версия Go
+	VersionEndpoint bool `mapstructure:"version_endpoint"`
+	// Сверять расширение загружаемого файла с сигнатурой содержимого
+	VerifyContentType bool `mapstructure:"verify_content_type"`
+	// Возвращать при ошибке валидации список нарушений {field, rule, message}
+	DetailedValidationErrors bool `mapstructure:"detailed_validation_errors"`
 }
 
 type DatabaseConfig struct {
@@ -40,9 +56,23 @@ type DatabaseConfig struct {
 }
 
 type StorageConfig struct {
-	Provider   string `mapstructure:"provider"`
-	BucketName string `mapstructure:"bucket_name"`
-	Region     string `mapstructure:"region"`
+	Provider    string            `mapstructure:"provider"`
+	BucketName  string            `mapstructure:"bucket_name"`
+	Region      string            `mapstructure:"region"`
+	Compression CompressionConfig `mapstructure:"compression"`
+	// Максимальный срок действия presigned URL
+	PresignedMaxExpiry time.Duration `mapstructure:"presigned_max_expiry"`
+	// clamp — урезать запрошенный срок до максимума, reject — отклонять запрос
+	PresignedOverMax string `mapstructure:"presigned_over_max"`
+}
+
+type CompressionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Префиксы MIME-типов для сжатия; архивы и изображения не сжимаются никогда
+	Types   []string `mapstructure:"types"`
+	MinSize int64    `mapstructure:"min_size"`
+	// gzip или zstd; уже сохранённые объекты читаются любым из них
+	Algorithm string `mapstructure:"algorithm"`
 }
 
 type MinIOConfig struct {
@@ -53,10 +83,83 @@ type MinIOConfig struct {
 	Timeout   time.Duration `mapstructure:"timeout"`
 }
 
+// S3Config используется при storage.provider = s3. Без access_key учётные данные
+// берутся из окружения AWS, ~/.aws/credentials или IAM-роли
+type S3Config struct {
+	Endpoint     string        `mapstructure:"endpoint"`
+	AccessKey    string        `mapstructure:"access_key"`
+	SecretKey    string        `mapstructure:"secret_key"`
+	SessionToken string        `mapstructure:"session_token"`
+	UseSSL       bool          `mapstructure:"use_ssl"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+}
+
+type ChunkedConfig struct {
+	// Префикс в бакете, где лежат части незавершённых загрузок
+	TempPrefix   string        `mapstructure:"temp_prefix"`
+	MaxChunkSize int64         `mapstructure:"max_chunk_size"`
+	MaxChunks    int           `mapstructure:"max_chunks"`
+	SessionTTL   time.Duration `mapstructure:"session_ttl"`
+	// Как часто удалять части истёкших сессий
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// ExpiryConfig — очистка файлов с истёкшим expires_at: сначала пометка удалённым,
+// после GracePeriod — удаление записи и объекта в хранилище
+type ExpiryConfig struct {
+	// 0 — фоновая очистка выключена; просроченные файлы всё равно не отдаются
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+	GracePeriod   time.Duration `mapstructure:"grace_period"`
+	// Файлов каждого вида за один проход
+	BatchSize int `mapstructure:"batch_size"`
+}
+
+// StorageMigrationConfig — значения по умолчанию для migrate-storage; флаги команды их переопределяют
+type StorageMigrationConfig struct {
+	SourceBucket string `mapstructure:"source_bucket"`
+	// Пусто — storage.bucket_name
+	TargetBucket string `mapstructure:"target_bucket"`
+	// Копировать объекты на стороне хранилища; false — объекты уже перенесены, проверяется их наличие
+	CopyObjects bool `mapstructure:"copy_objects"`
+	VerifyHash  bool `mapstructure:"verify_hash"`
+	BatchSize   int  `mapstructure:"batch_size"`
+}
+
 type HashConfig struct {
 	Algorithm string `mapstructure:"algorithm"`
 }
 
+// AuthConfig — общий ключ для запросов между сервисами (заголовок X-API-Key)
+type AuthConfig struct {
+	// Принимаемые ключи; несколько — на время ротации. Пустой список — проверка выключена
+	APIKeys []string `mapstructure:"api_keys"`
+}
+
+type StartupConfig struct {
+	// Проверять внешние зависимости (RabbitMQ, MinIO, сервисы) перед запуском
+	SelfCheck    bool          `mapstructure:"self_check"`
+	CheckTimeout time.Duration `mapstructure:"check_timeout"`
+}
+
+// MetricsConfig — метрики HTTP по маршрутам: задержка и размеры тел запроса и ответа
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
+	// Границы корзин гистограмм размеров, байт
+	SizeBuckets    []int64         `mapstructure:"size_buckets"`
+	LatencyBuckets []time.Duration `mapstructure:"latency_buckets"`
+}
+
+// TracingConfig — экспорт трасс OpenTelemetry по OTLP/HTTP
+type TracingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// URL коллектора, например http://otel-collector:4318
+	Endpoint    string `mapstructure:"endpoint"`
+	ServiceName string `mapstructure:"service_name"`
+	// Доля трасс, начинаемых в сервисе (0..1)
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
 type LoggingConfig struct {
 	Level   string `mapstructure:"level"`
 	Pretty  bool   `mapstructure:"pretty"`
@@ -97,13 +200,63 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
+// Validate проверяет обязательные параметры и возвращает все найденные проблемы разом
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Server.Address == "" {
+		problems = append(problems, "server.address is empty")
+	}
+	if c.Database.Host == "" || c.Database.Name == "" {
+		problems = append(problems, "database.host and database.name are required")
+	}
+	switch c.Storage.Provider {
+	case "minio":
+		if c.MinIO.Endpoint == "" || c.Storage.BucketName == "" {
+			problems = append(problems, "minio.endpoint and storage.bucket_name are required")
+		}
+	case "s3":
+		if c.S3.Endpoint == "" || c.Storage.BucketName == "" {
+			problems = append(problems, "s3.endpoint and storage.bucket_name are required")
+		}
+		if (c.S3.AccessKey == "") != (c.S3.SecretKey == "") {
+			problems = append(problems, "s3.access_key and s3.secret_key must be set together")
+		}
+	default:
+		problems = append(problems, "storage.provider must be 'minio' or 's3'")
+	}
+	if c.Chunked.MaxChunkSize <= 0 || c.Chunked.MaxChunks <= 0 || c.Chunked.SessionTTL <= 0 {
+		problems = append(problems, "chunked_upload.max_chunk_size, max_chunks and session_ttl must be positive")
+	}
+	if c.Expiry.SweepInterval > 0 && (c.Expiry.BatchSize <= 0 || c.Expiry.GracePeriod < 0) {
+		problems = append(problems, "expiry.batch_size must be positive and expiry.grace_period non-negative")
+	}
+	if a := c.Storage.Compression.Algorithm; a != "gzip" && a != "zstd" {
+		problems = append(problems, "storage.compression.algorithm must be 'gzip' or 'zstd'")
+	}
+	if c.Storage.PresignedOverMax != "clamp" && c.Storage.PresignedOverMax != "reject" {
+		problems = append(problems, "storage.presigned_over_max must be 'clamp' or 'reject'")
+	}
+	if c.Tracing.Enabled && c.Tracing.Endpoint == "" {
+		problems = append(problems, "tracing.endpoint is required when tracing is enabled")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
 func setDefaults() {
 	viper.SetDefault("server.address", ":8082")
 	viper.SetDefault("server.read_timeout", "30s")
 	viper.SetDefault("server.write_timeout", "30s")
 	viper.SetDefault("server.idle_timeout", "120s")
 	viper.SetDefault("server.shutdown_timeout", "10s")
+	viper.SetDefault("server.version_endpoint", true)
 	viper.SetDefault("server.max_upload_size", 104857600) // 100MB
+	viper.SetDefault("server.verify_content_type", true)
+	viper.SetDefault("server.detailed_validation_errors", true)
 
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
@@ -118,6 +271,12 @@ func setDefaults() {
 	viper.SetDefault("storage.provider", "minio")
 	viper.SetDefault("storage.bucket_name", "plagiarism-files")
 	viper.SetDefault("storage.region", "us-east-1")
+	viper.SetDefault("storage.compression.enabled", false)
+	viper.SetDefault("storage.compression.types", []string{"text/", "application/json", "application/xml", "application/msword", "application/rtf"})
+	viper.SetDefault("storage.compression.min_size", 1024)
+	viper.SetDefault("storage.compression.algorithm", "zstd")
+	viper.SetDefault("storage.presigned_max_expiry", "24h")
+	viper.SetDefault("storage.presigned_over_max", "clamp")
 
 	viper.SetDefault("minio.endpoint", "minio:9000")
 	viper.SetDefault("minio.access_key", "minioadmin")
@@ -125,8 +284,45 @@ func setDefaults() {
 	viper.SetDefault("minio.use_ssl", false)
 	viper.SetDefault("minio.timeout", "30s")
 
+	viper.SetDefault("s3.endpoint", "s3.amazonaws.com")
+	viper.SetDefault("s3.access_key", "")
+	viper.SetDefault("s3.secret_key", "")
+	viper.SetDefault("s3.session_token", "")
+	viper.SetDefault("s3.use_ssl", true)
+	viper.SetDefault("s3.timeout", "30s")
+
+	viper.SetDefault("chunked_upload.temp_prefix", "uploads/tmp")
+	viper.SetDefault("chunked_upload.max_chunk_size", 10485760) // 10MB
+	viper.SetDefault("chunked_upload.max_chunks", 1000)
+	viper.SetDefault("chunked_upload.session_ttl", "24h")
+	viper.SetDefault("chunked_upload.cleanup_interval", "1h")
+
+	viper.SetDefault("expiry.sweep_interval", "10m")
+	viper.SetDefault("expiry.grace_period", "24h")
+	viper.SetDefault("expiry.batch_size", 100)
+	viper.SetDefault("storage_migration.source_bucket", "")
+	viper.SetDefault("storage_migration.target_bucket", "")
+	viper.SetDefault("storage_migration.copy_objects", true)
+	viper.SetDefault("storage_migration.verify_hash", true)
+	viper.SetDefault("storage_migration.batch_size", 100)
+
 	viper.SetDefault("hash.algorithm", "sha256")
 
+	viper.SetDefault("auth.api_keys", []string{})
+
+	viper.SetDefault("startup.self_check", true)
+	viper.SetDefault("startup.check_timeout", "10s")
+
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("metrics.path", "/metrics")
+	viper.SetDefault("metrics.size_buckets", []int64{1024, 10240, 102400, 1048576, 10485760})
+	viper.SetDefault("metrics.latency_buckets", []string{"10ms", "50ms", "100ms", "500ms", "1s", "5s"})
+
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.endpoint", "http://otel-collector:4318")
+	viper.SetDefault("tracing.service_name", "file-service")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.pretty", false)
 	viper.SetDefault("logging.no_color", false)
@@ -0,0 +1,125 @@
+package httpd
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) InitChunkedUpload(w http.ResponseWriter, r *http.Request) {
+	var req models.InitUploadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(req.Metadata) > 0 {
+		var metadataMap map[string]interface{}
+		if err := json.Unmarshal(req.Metadata, &metadataMap); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid metadata format")
+			return
+		}
+	}
+
+	response, err := h.chunkedUploadService.InitUpload(r.Context(), &req)
+	if err != nil {
+		h.handleChunkedUploadError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"success": true,
+		"data":    response,
+	})
+}
+
+// UploadChunk принимает тело запроса как есть; X-Content-SHA256 проверяется для каждой части
+func (h *Handler) UploadChunk(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "session_id")
+	chunkNumber, err := strconv.Atoi(chi.URLParam(r, "n"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Chunk number must be an integer")
+		return
+	}
+
+	body := r.Body
+	if maxSize := h.chunkedUploadService.GetConfig().MaxChunkSize; maxSize > 0 {
+		body = http.MaxBytesReader(w, r.Body, maxSize)
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, http.StatusRequestEntityTooLarge, "Chunk size exceeds limit")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "Failed to read chunk")
+		return
+	}
+
+	if !verifyContentSHA256(r, data) {
+		writeError(w, http.StatusBadRequest, "Content checksum mismatch")
+		return
+	}
+
+	response, err := h.chunkedUploadService.UploadChunk(r.Context(), sessionID, chunkNumber, data)
+	if err != nil {
+		h.handleChunkedUploadError(w, err)
+		return
+	}
+
+	writeSuccess(w, response)
+}
+
+func (h *Handler) CompleteChunkedUpload(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "session_id")
+
+	response, err := h.chunkedUploadService.CompleteUpload(r.Context(), sessionID)
+	if err != nil {
+		h.handleChunkedUploadError(w, err)
+		return
+	}
+
+	writeSuccess(w, response)
+}
+
+func (h *Handler) handleChunkedUploadError(w http.ResponseWriter, err error) {
+	errMsg := err.Error()
+
+	switch {
+	case contains(errMsg, "upload session not found"):
+		writeError(w, http.StatusNotFound, "Upload session not found")
+	case contains(errMsg, "upload session expired"):
+		writeError(w, http.StatusGone, "Upload session expired")
+	case contains(errMsg, "upload session already completed"),
+		contains(errMsg, "missing chunks"):
+		writeError(w, http.StatusConflict, errMsg)
+	case contains(errMsg, "file_name is required"),
+		contains(errMsg, "total_chunks must be"),
+		contains(errMsg, "chunk number out of range"),
+		contains(errMsg, "chunk is empty"):
+		writeError(w, http.StatusBadRequest, errMsg)
+	case contains(errMsg, "chunk size exceeds limit"):
+		writeError(w, http.StatusRequestEntityTooLarge, errMsg)
+	case contains(errMsg, "failed to upload chunk to storage"),
+		contains(errMsg, "failed to read chunk"):
+		h.logger.Error().Err(err).Msg("Chunk storage error")
+		writeError(w, http.StatusInternalServerError, "Failed to store chunk")
+	case contains(errMsg, "failed to create upload session"),
+		contains(errMsg, "failed to get upload session"),
+		contains(errMsg, "failed to save chunk"),
+		contains(errMsg, "failed to list chunks"),
+		contains(errMsg, "failed to complete upload session"):
+		h.logger.Error().Err(err).Msg("Upload session database error")
+		writeError(w, http.StatusInternalServerError, "Failed to process upload session")
+	default:
+		// Ошибки сборки файла совпадают с ошибками обычной загрузки
+		h.handleUploadError(w, err)
+	}
+}
@@ -47,6 +47,38 @@ func (h *Handler) CleanupFiles(w http.ResponseWriter, r *http.Request) {
 	writeSuccess(w, response)
 }
 
+func (h *Handler) RestoreFile(w http.ResponseWriter, r *http.Request) {
+	fileID := chi.URLParam(r, "file_id")
+	if fileID == "" {
+		writeError(w, http.StatusBadRequest, "File ID is required")
+		return
+	}
+
+	response, err := h.deleteService.RestoreFile(r.Context(), fileID)
+	if err != nil {
+		h.handleRestoreError(w, err)
+		return
+	}
+
+	writeSuccess(w, response)
+}
+
+func (h *Handler) handleRestoreError(w http.ResponseWriter, err error) {
+	errMsg := err.Error()
+
+	switch {
+	case contains(errMsg, "file not found"):
+		writeError(w, http.StatusNotFound, "File not found")
+	case contains(errMsg, "file is not deleted"):
+		writeError(w, http.StatusConflict, "File is not deleted")
+	case contains(errMsg, "file no longer exists in storage"):
+		writeError(w, http.StatusConflict, "File content is no longer in storage and cannot be restored")
+	default:
+		h.logger.Error().Err(err).Msg("Restore error")
+		writeError(w, http.StatusInternalServerError, "Failed to restore file")
+	}
+}
+
 func (h *Handler) handleDeleteError(w http.ResponseWriter, err error) {
 	errMsg := err.Error()
 
@@ -1,9 +1,13 @@
 package httpd
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/service"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -15,17 +19,38 @@ func (h *Handler) DownloadFile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	response, err := h.downloadService.DownloadFile(ctx, fileID)
+	var response *models.DownloadFileResponse
+	var err error
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		response, err = h.downloadService.DownloadFileRange(ctx, fileID, rangeHeader)
+	} else {
+		response, err = h.downloadService.DownloadFile(ctx, fileID)
+	}
 	if err != nil {
+		var notSatisfiable *service.RangeNotSatisfiableError
+		if errors.As(err, &notSatisfiable) {
+			w.Header().Set("Content-Range", "bytes */"+strconv.FormatInt(notSatisfiable.Size, 10))
+			writeError(w, http.StatusRequestedRangeNotSatisfiable, "Requested range not satisfiable")
+			return
+		}
 		h.handleDownloadError(w, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", response.ContentType)
 	w.Header().Set("Content-Disposition", "attachment; filename=\""+response.FileName+"\"")
-	w.Header().Set("Content-Length", strconv.FormatInt(response.FileSize, 10))
+	w.Header().Set("Accept-Ranges", "bytes")
 	w.Header().Set("Cache-Control", "private, max-age=86400")
 
+	if response.Partial {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", response.RangeStart, response.RangeEnd, response.FileSize))
+		w.Header().Set("Content-Length", strconv.Itoa(len(response.Content)))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(response.Content)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(response.FileSize, 10))
 	w.WriteHeader(http.StatusOK)
 	w.Write(response.Content)
 }
@@ -57,15 +82,22 @@ func (h *Handler) GetFileURL(w http.ResponseWriter, r *http.Request) {
 	expiresIn := getInt64QueryParam(r, "expires", 3600) // По умолчанию 1 час
 
 	ctx := r.Context()
-	url, err := h.downloadService.GetPresignedURL(ctx, fileID, expiresIn)
+	url, effectiveExpiresIn, err := h.downloadService.GetPresignedURL(ctx, fileID, expiresIn)
 	if err != nil {
-		h.handleDownloadError(w, err)
+		switch {
+		case err.Error() == "invalid expiry":
+			writeError(w, http.StatusBadRequest, "expires must be a positive number of seconds")
+		case contains(err.Error(), "expiry exceeds maximum"):
+			writeError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.handleDownloadError(w, err)
+		}
 		return
 	}
 
 	response := map[string]interface{}{
 		"url":        url,
-		"expires_in": expiresIn,
+		"expires_in": effectiveExpiresIn,
 		"file_id":    fileID,
 	}
 
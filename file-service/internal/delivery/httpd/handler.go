@@ -13,29 +13,37 @@ import (
 )
 
 type Handler struct {
-	uploadService   service.UploadService
-	downloadService service.DownloadService
-	deleteService   service.DeleteService
-	metadataRepo    repository.FileMetadataRepository
-	storageRepo     repository.StorageRepository
-	logger          zerolog.Logger
+	uploadService        service.UploadService
+	chunkedUploadService service.ChunkedUploadService
+	downloadService      service.DownloadService
+	deleteService        service.DeleteService
+	metadataRepo         repository.FileMetadataRepository
+	storageRepo          repository.StorageRepository
+	logger               zerolog.Logger
+	// Отдавать при ошибке валидации список {field, rule, message}, а не одно сообщение
+	detailedValidationErrors bool
 }
 
 func NewHandler(
 	uploadService service.UploadService,
+	chunkedUploadService service.ChunkedUploadService,
 	downloadService service.DownloadService,
 	deleteService service.DeleteService,
 	metadataRepo repository.FileMetadataRepository,
 	storageRepo repository.StorageRepository,
 	logger zerolog.Logger,
+	detailedValidationErrors bool,
 ) *Handler {
 	return &Handler{
-		uploadService:   uploadService,
-		downloadService: downloadService,
-		deleteService:   deleteService,
-		metadataRepo:    metadataRepo,
-		storageRepo:     storageRepo,
-		logger:          logger,
+		uploadService:        uploadService,
+		chunkedUploadService: chunkedUploadService,
+		downloadService:      downloadService,
+		deleteService:        deleteService,
+		metadataRepo:         metadataRepo,
+		storageRepo:          storageRepo,
+		logger:               logger,
+
+		detailedValidationErrors: detailedValidationErrors,
 	}
 }
 
@@ -48,6 +56,9 @@ func (h *Handler) RegisterRoutes(router chi.Router) {
 		api.Route("/files", func(r chi.Router) {
 			r.Post("/upload", h.UploadFile)
 			r.Post("/upload/bytes", h.UploadBytes) // Новый эндпоинт
+			r.Post("/upload/init", h.InitChunkedUpload)
+			r.Put("/upload/{session_id}/chunk/{n}", h.UploadChunk)
+			r.Post("/upload/{session_id}/complete", h.CompleteChunkedUpload)
 			r.Get("/{file_id}", h.DownloadFile)
 			r.Get("/{file_id}/info", h.GetFileInfo)
 			r.Get("/{file_id}/url", h.GetFileURL)
@@ -59,6 +70,7 @@ func (h *Handler) RegisterRoutes(router chi.Router) {
 			r.Get("/", h.ListFiles)
 			r.Get("/search", h.SearchFiles)
 			r.Delete("/cleanup", h.CleanupFiles)
+			r.Post("/{file_id}/restore", h.RestoreFile)
 			r.Get("/associations/{file_id}", h.GetFileAssociations) // Новый эндпоинт
 			r.Post("/associate", h.AssociateFile)                   // Новый эндпоинт
 		})
@@ -95,6 +107,10 @@ func (h *Handler) AssociateFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
 	exists, err := h.metadataRepo.Exists(r.Context(), req.FileID)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to check file existence")
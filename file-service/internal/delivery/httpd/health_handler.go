@@ -1,12 +1,12 @@
 package httpd
 
 import (
-	"database/sql"
-	"errors"
+	"encoding/json"
 	"fmt"
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
 	"math"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -62,12 +62,26 @@ func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) ListFiles(w http.ResponseWriter, r *http.Request) {
 	page := getIntQueryParam(r, "page", 1)
 	limit := getIntQueryParam(r, "limit", 20)
-	status := r.URL.Query().Get("status")
+	filter := models.FileListFilter{Status: r.URL.Query().Get("status")}
+
+	var err error
+	if filter.From, err = getTimeQueryParam(r, "from"); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid 'from' parameter. Use RFC3339 or YYYY-MM-DD")
+		return
+	}
+	if filter.To, err = getTimeQueryParam(r, "to"); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid 'to' parameter. Use RFC3339 or YYYY-MM-DD")
+		return
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		writeError(w, http.StatusBadRequest, "'from' must be before 'to'")
+		return
+	}
 
 	offset := (page - 1) * limit
 
 	ctx := r.Context()
-	files, total, err := h.metadataRepo.GetAll(ctx, limit, offset, status)
+	files, total, err := h.metadataRepo.GetAll(ctx, limit, offset, filter)
 	if err != nil {
 		h.logger.Error().Err(err).Msg("Failed to list files")
 		writeError(w, http.StatusInternalServerError, "Failed to list files")
@@ -108,7 +122,7 @@ func (h *Handler) ListFiles(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) SearchFiles(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
 	if query == "" {
 		writeError(w, http.StatusBadRequest, "Search query is required")
 		return
@@ -116,65 +130,76 @@ func (h *Handler) SearchFiles(w http.ResponseWriter, r *http.Request) {
 
 	page := getIntQueryParam(r, "page", 1)
 	limit := getIntQueryParam(r, "limit", 20)
-
-	ctx := r.Context()
-
-	filesByName, err := h.metadataRepo.GetByFileName(ctx, query)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		h.logger.Error().Err(err).Msg("Failed to search files by name")
-		writeError(w, http.StatusInternalServerError, "Failed to search files")
+	if page < 1 || limit < 1 || limit > 100 {
+		writeError(w, http.StatusBadRequest, "page must be positive and limit within 1..100")
 		return
 	}
 
-	filesByMetadata, err := h.metadataRepo.SearchByMetadata(ctx, "tags", query)
-	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to search files by metadata")
+	filter := models.FileSearchFilter{
+		MimeType:   r.URL.Query().Get("mime_type"),
+		UploadedBy: r.URL.Query().Get("uploaded_by"),
 	}
 
-	allFiles := make(map[string]*models.FileMetadata)
-
-	if filesByName != nil {
-		allFiles[filesByName.ID] = filesByName
+	var err error
+	if filter.From, err = getTimeQueryParam(r, "from"); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid 'from' parameter. Use RFC3339 or YYYY-MM-DD")
+		return
 	}
-
-	for _, file := range filesByMetadata {
-		allFiles[file.ID] = file
+	if filter.To, err = getTimeQueryParam(r, "to"); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid 'to' parameter. Use RFC3339 or YYYY-MM-DD")
+		return
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		writeError(w, http.StatusBadRequest, "'from' must be before 'to'")
+		return
 	}
 
-	var paginatedFiles []*models.FileMetadata
-	start := (page - 1) * limit
-	end := start + limit
-	i := 0
-
-	for _, file := range allFiles {
-		if i >= start && i < end {
-			paginatedFiles = append(paginatedFiles, file)
-		}
-		i++
-		if i >= end {
-			break
+	if raw := r.URL.Query().Get("metadata"); raw != "" {
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &object); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid 'metadata' parameter. Use a JSON object")
+			return
 		}
+		filter.Metadata = []byte(raw)
+	}
+
+	ctx := r.Context()
+	matches, total, err := h.metadataRepo.SearchFiles(ctx, query, filter, limit, (page-1)*limit)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to search files")
+		writeError(w, http.StatusInternalServerError, "Failed to search files")
+		return
 	}
 
-	results := make([]map[string]interface{}, len(paginatedFiles))
-	for i, file := range paginatedFiles {
+	results := make([]map[string]interface{}, len(matches))
+	for i, match := range matches {
+		file := match.File
 		results[i] = map[string]interface{}{
 			"id":            file.ID,
 			"original_name": file.OriginalName,
 			"size":          file.FileSize,
 			"mime_type":     file.MimeType,
 			"uploaded_at":   file.UploadedAt.Format(time.RFC3339),
+			"uploaded_by":   file.UploadedBy,
 			"hash":          file.Hash,
-			"match_type":    "name", // В реальности нужно определить тип совпадения
+			"match_type":    match.MatchType,
 		}
 	}
 
 	writeSuccess(w, map[string]interface{}{
 		"query":   query,
 		"results": results,
-		"count":   len(allFiles),
+		"count":   total,
 		"page":    page,
 		"limit":   limit,
+		"pagination": map[string]interface{}{
+			"page":     page,
+			"limit":    limit,
+			"total":    total,
+			"pages":    int(math.Ceil(float64(total) / float64(limit))),
+			"has_next": page*limit < total,
+			"has_prev": page > 1,
+		},
 	})
 }
 
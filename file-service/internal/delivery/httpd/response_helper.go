@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"strconv"
 	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/pkg/validation"
 )
 
 func getIntQueryParam(r *http.Request, key string, defaultValue int) int {
@@ -49,6 +51,28 @@ func getBoolQueryParam(r *http.Request, key string, defaultValue bool) bool {
 	return boolValue
 }
 
+// getTimeQueryParam принимает RFC3339 или YYYY-MM-DD; дата без времени в параметре "to"
+// означает конец дня, чтобы интервал включал его целиком
+func getTimeQueryParam(r *http.Request, key string) (*time.Time, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if key == "to" {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
+
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -71,6 +95,30 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, response)
 }
 
+// validateRequest проверяет DTO по тегам validate; при нарушениях отвечает 400 и возвращает false
+func (h *Handler) validateRequest(w http.ResponseWriter, req interface{}) bool {
+	errs := validation.Struct(req)
+	if errs == nil {
+		return true
+	}
+
+	if !h.detailedValidationErrors {
+		writeError(w, http.StatusBadRequest, errs[0].Message)
+		return false
+	}
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": errs[0].Message,
+			"type":    http.StatusText(http.StatusBadRequest),
+			"fields":  errs,
+		},
+		"success":   false,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+	return false
+}
+
 func writeSuccess(w http.ResponseWriter, data interface{}) {
 	response := map[string]interface{}{
 		"success":   true,
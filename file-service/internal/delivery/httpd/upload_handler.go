@@ -1,11 +1,17 @@
 package httpd
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 )
 
+// Заголовок с SHA-256 содержимого, посчитанным на стороне клиента
+const contentSHA256Header = "X-Content-SHA256"
+
 func (h *Handler) UploadFile(w http.ResponseWriter, r *http.Request) {
 	contentType := r.Header.Get("Content-Type")
 	if contentType != "multipart/form-data" && !contains(contentType, "multipart/form-data") {
@@ -31,16 +37,31 @@ func (h *Handler) UploadFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !verifyContentSHA256(r, fileBytes) {
+		writeError(w, http.StatusBadRequest, "Content checksum mismatch")
+		return
+	}
+
 	uploadedBy := r.FormValue("uploaded_by")
 	metadataStr := r.FormValue("metadata")
 
-	var metadata []byte
+	var metadataMap map[string]interface{}
 	if metadataStr != "" {
-		var metadataMap map[string]interface{}
 		if err := json.Unmarshal([]byte(metadataStr), &metadataMap); err != nil {
 			writeError(w, http.StatusBadRequest, "Invalid metadata format")
 			return
 		}
+	}
+	// Срок хранения можно передать отдельным полем формы; он имеет приоритет над metadata.expires_at
+	if expiresAt := r.FormValue("expires_at"); expiresAt != "" {
+		if metadataMap == nil {
+			metadataMap = make(map[string]interface{})
+		}
+		metadataMap["expires_at"] = expiresAt
+	}
+
+	var metadata []byte
+	if metadataMap != nil {
 		metadata, _ = json.Marshal(metadataMap)
 	}
 	if len(metadata) == 0 {
@@ -63,8 +84,10 @@ func (h *Handler) handleUploadError(w http.ResponseWriter, err error) {
 	switch {
 	case contains(errMsg, "file size exceeds limit"):
 		writeError(w, http.StatusRequestEntityTooLarge, errMsg)
-	case contains(errMsg, "file type not allowed"):
+	case contains(errMsg, "file type not allowed"), contains(errMsg, "file content does not match extension"):
 		writeError(w, http.StatusUnsupportedMediaType, errMsg)
+	case contains(errMsg, "invalid expires_at"):
+		writeError(w, http.StatusBadRequest, errMsg)
 	case contains(errMsg, "failed to calculate file hash"):
 		h.logger.Error().Err(err).Msg("Hash calculation error")
 		writeError(w, http.StatusInternalServerError, "Failed to process file")
@@ -80,6 +103,18 @@ func (h *Handler) handleUploadError(w http.ResponseWriter, err error) {
 	}
 }
 
+// verifyContentSHA256 сверяет тело файла с заголовком X-Content-SHA256.
+// Проверка выполняется только если клиент передал заголовок.
+func verifyContentSHA256(r *http.Request, data []byte) bool {
+	expected := strings.TrimSpace(r.Header.Get(contentSHA256Header))
+	if expected == "" {
+		return true
+	}
+
+	sum := sha256.Sum256(data)
+	return strings.EqualFold(hex.EncodeToString(sum[:]), expected)
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || contains(s[1:], substr)))
 }
@@ -102,6 +137,11 @@ func (h *Handler) UploadBytes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !verifyContentSHA256(r, req.FileBytes) {
+		writeError(w, http.StatusBadRequest, "Content checksum mismatch")
+		return
+	}
+
 	ctx := r.Context()
 	response, err := h.uploadService.UploadFileBytes(ctx, req.FileName, req.FileBytes, req.UploadedBy, req.Metadata)
 	if err != nil {
@@ -14,14 +14,16 @@ type UploadFileRequest struct {
 }
 
 type UploadFileResponse struct {
-	FileID     string          `json:"file_id"`
-	FileName   string          `json:"file_name"`
-	FileSize   int64           `json:"file_size"`
-	Hash       string          `json:"hash"`
-	MimeType   string          `json:"mime_type"`
-	UploadedAt time.Time       `json:"uploaded_at"`
-	StorageURL string          `json:"storage_url,omitempty"`
-	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	FileID      string          `json:"file_id"`
+	FileName    string          `json:"file_name"`
+	FileSize    int64           `json:"file_size"`
+	Hash        string          `json:"hash"`
+	ContentHash string          `json:"content_hash,omitempty"`
+	MimeType    string          `json:"mime_type"`
+	UploadedAt  time.Time       `json:"uploaded_at"`
+	StorageURL  string          `json:"storage_url,omitempty"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+	ExpiresAt   *time.Time      `json:"expires_at,omitempty"`
 }
 
 type FileInfoResponse struct {
@@ -30,12 +32,15 @@ type FileInfoResponse struct {
 	FileSize       int64           `json:"file_size"`
 	MimeType       string          `json:"mime_type"`
 	Hash           string          `json:"hash"`
+	ContentHash    string          `json:"content_hash,omitempty"`
 	UploadStatus   string          `json:"upload_status"`
 	UploadedAt     time.Time       `json:"uploaded_at"`
 	AccessCount    int             `json:"access_count"`
+	ReferenceCount int             `json:"reference_count"`
 	LastAccessedAt *time.Time      `json:"last_accessed_at,omitempty"`
 	StorageURL     string          `json:"storage_url,omitempty"`
 	Metadata       json.RawMessage `json:"metadata,omitempty"`
+	ExpiresAt      *time.Time      `json:"expires_at,omitempty"`
 }
 
 type DownloadFileResponse struct {
@@ -43,12 +48,24 @@ type DownloadFileResponse struct {
 	FileName    string `json:"file_name"`
 	ContentType string `json:"content_type"`
 	FileSize    int64  `json:"file_size"`
+	// Partial — в Content только байты RangeStart..RangeEnd (включительно) из FileSize
+	Partial    bool  `json:"-"`
+	RangeStart int64 `json:"-"`
+	RangeEnd   int64 `json:"-"`
 }
 
 type DeleteFileResponse struct {
 	FileID  string `json:"file_id"`
 	Deleted bool   `json:"deleted"`
 	Message string `json:"message,omitempty"`
+	// Сколько загрузок ещё используют файл; пока больше нуля, файл не удаляется
+	ReferenceCount int `json:"reference_count"`
+}
+
+type RestoreFileResponse struct {
+	FileID   string `json:"file_id"`
+	Restored bool   `json:"restored"`
+	Message  string `json:"message,omitempty"`
 }
 
 type AssociateFileRequest struct {
@@ -58,6 +75,34 @@ type AssociateFileRequest struct {
 	AssociationType string `json:"association_type" validate:"required"`
 }
 
+// FileListFilter — фильтры списка файлов; пустые поля не ограничивают выборку
+type FileListFilter struct {
+	Status string
+	From   *time.Time
+	To     *time.Time
+}
+
+// FileSearchFilter — фильтры поиска файлов; пустые поля не ограничивают выборку.
+// Metadata — JSON-объект, который должен содержаться в metadata файла (оператор @>)
+type FileSearchFilter struct {
+	MimeType   string
+	UploadedBy string
+	From       *time.Time
+	To         *time.Time
+	Metadata   []byte
+}
+
+// FileSearchMatch — найденный файл и где совпал запрос: name — в имени файла, metadata — в тегах
+type FileSearchMatch struct {
+	File      *FileMetadata
+	MatchType string
+}
+
+const (
+	FileMatchName     = "name"
+	FileMatchMetadata = "metadata"
+)
+
 type StorageInfo struct {
 	Provider   string `json:"provider"`
 	BucketName string `json:"bucket_name"`
@@ -65,3 +110,17 @@ type StorageInfo struct {
 	UsedSpace  int64  `json:"used_space"`
 	FileCount  int64  `json:"file_count"`
 }
+
+// Алгоритмы сжатия хранимых объектов
+const (
+	StorageCompressionGzip = "gzip"
+	StorageCompressionZstd = "zstd"
+)
+
+// StorageObjectOptions — параметры сохраняемого в хранилище объекта
+type StorageObjectOptions struct {
+	// Пусто — объект хранится как есть
+	Compression string
+	// Размер до сжатия, отдаётся при скачивании
+	OriginalSize int64
+}
@@ -13,6 +13,7 @@ type FileMetadata struct {
 	FileSize        int64           `json:"file_size" db:"file_size"`
 	MimeType        string          `json:"mime_type" db:"mime_type"`
 	Hash            string          `json:"hash" db:"hash"`
+	ContentHash     string          `json:"content_hash,omitempty" db:"content_hash"` // хеш нормализованного текста, пустой для бинарных файлов
 	StorageProvider string          `json:"storage_provider" db:"storage_provider"`
 	StorageBucket   string          `json:"storage_bucket" db:"storage_bucket"`
 	StoragePath     string          `json:"storage_path" db:"storage_path"`
@@ -21,8 +22,10 @@ type FileMetadata struct {
 	UploadedBy      string          `json:"uploaded_by,omitempty" db:"uploaded_by"`
 	UploadedAt      time.Time       `json:"uploaded_at" db:"uploaded_at"`
 	AccessCount     int             `json:"access_count" db:"access_count"`
+	ReferenceCount  int             `json:"reference_count" db:"reference_count"` // сколько загрузок используют файл
 	LastAccessedAt  *time.Time      `json:"last_accessed_at,omitempty" db:"last_accessed_at"`
 	Metadata        json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+	ExpiresAt       *time.Time      `json:"expires_at,omitempty" db:"expires_at"` // после этого времени файл считается удалённым
 }
 
 type FileUploadStatus string
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type StorageMigrationStatus string
+
+const (
+	StorageMigrationRunning   StorageMigrationStatus = "running"
+	StorageMigrationCompleted StorageMigrationStatus = "completed"
+)
+
+func (s StorageMigrationStatus) String() string {
+	return string(s)
+}
+
+// StorageMigration — перенос файлов из SourceBucket в TargetBucket; LastFileID — последний
+// обработанный файл, с него продолжается прерванный перенос
+type StorageMigration struct {
+	ID           string     `json:"id" db:"id"`
+	SourceBucket string     `json:"source_bucket" db:"source_bucket"`
+	TargetBucket string     `json:"target_bucket" db:"target_bucket"`
+	CopyObjects  bool       `json:"copy_objects" db:"copy_objects"`
+	Status       string     `json:"status" db:"status"`
+	LastFileID   *string    `json:"last_file_id,omitempty" db:"last_file_id"`
+	Migrated     int        `json:"migrated" db:"migrated"`
+	Failed       int        `json:"failed" db:"failed"`
+	LastError    *string    `json:"last_error,omitempty" db:"last_error"`
+	StartedAt    time.Time  `json:"started_at" db:"started_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type UploadSessionStatus string
+
+const (
+	UploadSessionActive    UploadSessionStatus = "active"
+	UploadSessionCompleted UploadSessionStatus = "completed"
+	UploadSessionExpired   UploadSessionStatus = "expired"
+)
+
+func (s UploadSessionStatus) String() string {
+	return string(s)
+}
+
+type UploadSession struct {
+	ID          string          `json:"id" db:"id"`
+	FileName    string          `json:"file_name" db:"file_name"`
+	TotalChunks int             `json:"total_chunks" db:"total_chunks"`
+	UploadedBy  string          `json:"uploaded_by,omitempty" db:"uploaded_by"`
+	Metadata    json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+	Status      string          `json:"status" db:"status"`
+	FileID      *string         `json:"file_id,omitempty" db:"file_id"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	ExpiresAt   time.Time       `json:"expires_at" db:"expires_at"`
+}
+
+type UploadChunk struct {
+	SessionID   string    `json:"session_id" db:"session_id"`
+	ChunkNumber int       `json:"chunk_number" db:"chunk_number"`
+	Size        int64     `json:"size" db:"size"`
+	StoragePath string    `json:"-" db:"storage_path"`
+	ReceivedAt  time.Time `json:"received_at" db:"received_at"`
+}
+
+type InitUploadRequest struct {
+	FileName    string          `json:"file_name"`
+	TotalChunks int             `json:"total_chunks"`
+	UploadedBy  string          `json:"uploaded_by,omitempty"`
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
+}
+
+type InitUploadResponse struct {
+	SessionID    string    `json:"session_id"`
+	TotalChunks  int       `json:"total_chunks"`
+	MaxChunkSize int64     `json:"max_chunk_size"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type UploadChunkResponse struct {
+	SessionID      string `json:"session_id"`
+	ChunkNumber    int    `json:"chunk_number"`
+	Size           int64  `json:"size"`
+	ReceivedChunks int    `json:"received_chunks"`
+	TotalChunks    int    `json:"total_chunks"`
+}
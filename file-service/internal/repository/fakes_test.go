@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// fakeS3 — минимальный S3 по HTTP с адресацией path-style: бакеты, PUT/HEAD/GET/DELETE объектов
+// и Range. Подписи запросов не проверяются
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]bool
+	objects map[string]fakeObject // bucket/key → объект
+}
+
+type fakeObject struct {
+	data     []byte
+	metadata http.Header // заголовки x-amz-meta-*
+	modified time.Time
+}
+
+// newFakeS3 запускает сервер с уже созданными бакетами; адрес — host:port для minio.New
+func newFakeS3(t *testing.T, buckets ...string) (*fakeS3, string) {
+	t.Helper()
+
+	s := &fakeS3{buckets: make(map[string]bool), objects: make(map[string]fakeObject)}
+	for _, b := range buckets {
+		s.buckets[b] = true
+	}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, strings.TrimPrefix(server.URL, "http://")
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if path == "" {
+		s.listBuckets(w)
+		return
+	}
+	bucket, key, _ := strings.Cut(path, "/")
+
+	if key == "" {
+		if !s.buckets[bucket] {
+			writeS3Error(w, http.StatusNotFound, "NoSuchBucket", r.Method)
+			return
+		}
+		if r.URL.Query().Has("location") {
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></LocationConstraint>`)
+		}
+		return
+	}
+	if !s.buckets[bucket] {
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket", r.Method)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := readS3Payload(r)
+		if err != nil {
+			writeS3Error(w, http.StatusBadRequest, "IncompleteBody", r.Method)
+			return
+		}
+		metadata := http.Header{}
+		for name, values := range r.Header {
+			if strings.HasPrefix(strings.ToLower(name), "x-amz-meta-") {
+				metadata[name] = values
+			}
+		}
+		s.objects[bucket+"/"+key] = fakeObject{data: data, metadata: metadata, modified: time.Now().UTC()}
+		w.Header().Set("ETag", etag(data))
+	case http.MethodHead, http.MethodGet:
+		obj, ok := s.objects[bucket+"/"+key]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey", r.Method)
+			return
+		}
+		for name, values := range obj.metadata {
+			w.Header()[name] = values
+		}
+		w.Header().Set("ETag", etag(obj.data))
+		w.Header().Set("Last-Modified", obj.modified.Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Accept-Ranges", "bytes")
+
+		data, status := obj.data, http.StatusOK
+		if start, end, ok := parseFakeRange(r.Header.Get("Range"), int64(len(obj.data))); ok && r.Method == http.MethodGet {
+			data, status = obj.data[start:end+1], http.StatusPartialContent
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(obj.data)))
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(s.objects, bucket+"/"+key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+func (s *fakeS3) listBuckets(w http.ResponseWriter) {
+	var buckets strings.Builder
+	for name := range s.buckets {
+		fmt.Fprintf(&buckets, "<Bucket><Name>%s</Name><CreationDate>2024-01-01T00:00:00.000Z</CreationDate></Bucket>", name)
+	}
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><ListAllMyBucketsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Owner><ID>test</ID></Owner><Buckets>%s</Buckets></ListAllMyBucketsResult>`, buckets.String())
+}
+
+// object — сохранённое содержимое объекта, как оно лежит в хранилище
+func (s *fakeS3) object(bucket, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.objects[bucket+"/"+key]
+	return obj.data, ok
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code, method string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	// На HEAD тело не отправляется: клиент определяет ошибку по статусу
+	if method != http.MethodHead {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+	}
+}
+
+// readS3Payload читает тело PUT, в том числе в кодировке aws-chunked, которой клиент подписывает поток по HTTP
+func readS3Payload(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func parseFakeRange(header string, size int64) (int64, int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return 0, 0, false
+	}
+	from, to, _ := strings.Cut(spec, "-")
+	start, err := strconv.ParseInt(from, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	end := size - 1
+	if to != "" {
+		if end, err = strconv.ParseInt(to, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	if end >= size {
+		end = size - 1
+	}
+	return start, end, start <= end
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func newTestS3Repository(t *testing.T, endpoint, bucket string) *S3Repository {
+	t.Helper()
+
+	repo, err := NewS3Repository(endpoint, "access", "secret", "", bucket, "us-east-1", false, time.Second, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("new s3 repository: %v", err)
+	}
+	return repo
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/rs/zerolog"
 
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
@@ -14,15 +17,37 @@ type FileMetadataRepository interface {
 	GetByID(ctx context.Context, id string) (*models.FileMetadata, error)
 	GetByHash(ctx context.Context, hash string, fileSize int64) ([]*models.FileMetadata, error)
 	GetByFileName(ctx context.Context, fileName string) (*models.FileMetadata, error)
-	GetAll(ctx context.Context, limit, offset int, status string) ([]*models.FileMetadata, int, error)
+	GetAll(ctx context.Context, limit, offset int, filter models.FileListFilter) ([]*models.FileMetadata, int, error)
 	UpdateStatus(ctx context.Context, id, status string) error
 	UpdateAccessInfo(ctx context.Context, id string) error
 	UpdateMetadata(ctx context.Context, id string, metadata []byte) error
 	Delete(ctx context.Context, id string) error
 	SoftDelete(ctx context.Context, id string) error
+	// GetDeletedByID — файл, помеченный удалённым и ещё не удалённый окончательно; nil, если такого нет
+	GetDeletedByID(ctx context.Context, id string) (*models.FileMetadata, error)
+	// Restore возвращает помеченный удалённым файл в статус uploaded; sql.ErrNoRows — файл не помечен удалённым.
+	// Просроченный файл восстанавливается бессрочным, иначе очистка сразу удалит его снова
+	Restore(ctx context.Context, id string) error
+	// AddReference учитывает ещё одну загрузку того же файла; false — файл уже удалён.
+	// Срок хранения становится поздним из двух, загрузка без срока снимает его совсем
+	AddReference(ctx context.Context, id string, expiresAt *time.Time) (bool, error)
+	// ReleaseReference снимает одну ссылку и возвращает оставшиеся; на последней ссылке
+	// файл помечается удалённым в том же запросе, чтобы повторная загрузка его уже не нашла
+	ReleaseReference(ctx context.Context, id string) (int, error)
 	GetStats(ctx context.Context) (*models.FileStats, error)
 	Exists(ctx context.Context, id string) (bool, error)
 	SearchByMetadata(ctx context.Context, key, value string) ([]*models.FileMetadata, error)
+	// SearchFiles ищет query в имени файла (без учёта регистра, по подстроке) и в тегах metadata.
+	// Порядок — от новых к старым, при равном времени загрузки по id, поэтому страницы не пересекаются;
+	// total — число всех найденных файлов с учётом фильтров
+	SearchFiles(ctx context.Context, query string, filter models.FileSearchFilter, limit, offset int) ([]models.FileSearchMatch, int, error)
+	// GetExpired — файлы с expires_at не позже before: deleted=false — ещё не помеченные удалёнными,
+	// deleted=true — уже помеченные, ожидающие окончательного удаления
+	GetExpired(ctx context.Context, before time.Time, deleted bool, limit int) ([]*models.FileMetadata, error)
+	// ListByBucket — файлы бакета в любом статусе по возрастанию id, после afterID (nil — с начала)
+	ListByBucket(ctx context.Context, bucket string, afterID *string, limit int) ([]*models.FileMetadata, error)
+	// MoveToBucket переносит запись в другой бакет, если она всё ещё в fromBucket
+	MoveToBucket(ctx context.Context, id, fromBucket, toBucket string) error
 }
 
 type fileMetadataRepository struct {
@@ -40,9 +65,9 @@ func (r *fileMetadataRepository) Create(ctx context.Context, metadata *models.Fi
 		INSERT INTO file_metadata (
 			id, original_name, file_name, file_extension, file_size, mime_type,
 			hash, storage_provider, storage_bucket, storage_path, storage_url,
-			upload_status, uploaded_by, uploaded_at, metadata
+			upload_status, uploaded_by, uploaded_at, metadata, content_hash, expires_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NULLIF($16, ''), $17
 		)
 	`
 
@@ -62,6 +87,8 @@ func (r *fileMetadataRepository) Create(ctx context.Context, metadata *models.Fi
 		metadata.UploadedBy,
 		metadata.UploadedAt,
 		metadata.Metadata,
+		metadata.ContentHash,
+		metadata.ExpiresAt,
 	)
 
 	return err
@@ -71,11 +98,11 @@ func (r *fileMetadataRepository) GetByID(ctx context.Context, id string) (*model
 	query := `
 		SELECT 
 			id, original_name, file_name, file_extension, file_size, mime_type,
-			hash, storage_provider, storage_bucket, storage_path, storage_url,
+			hash, COALESCE(content_hash, ''), storage_provider, storage_bucket, storage_path, storage_url,
 			upload_status, uploaded_by, uploaded_at, access_count, 
-			last_accessed_at, metadata
+			last_accessed_at, metadata, reference_count, expires_at
 		FROM file_metadata
-		WHERE id = $1 AND upload_status != 'deleted'
+		WHERE id = $1 AND upload_status != 'deleted' AND (expires_at IS NULL OR expires_at > NOW())
 	`
 
 	metadata := &models.FileMetadata{}
@@ -87,6 +114,7 @@ func (r *fileMetadataRepository) GetByID(ctx context.Context, id string) (*model
 		&metadata.FileSize,
 		&metadata.MimeType,
 		&metadata.Hash,
+		&metadata.ContentHash,
 		&metadata.StorageProvider,
 		&metadata.StorageBucket,
 		&metadata.StoragePath,
@@ -97,6 +125,8 @@ func (r *fileMetadataRepository) GetByID(ctx context.Context, id string) (*model
 		&metadata.AccessCount,
 		&metadata.LastAccessedAt,
 		&metadata.Metadata,
+		&metadata.ReferenceCount,
+		&metadata.ExpiresAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -110,11 +140,11 @@ func (r *fileMetadataRepository) GetByHash(ctx context.Context, hash string, fil
 	query := `
 		SELECT 
 			id, original_name, file_name, file_extension, file_size, mime_type,
-			hash, storage_provider, storage_bucket, storage_path, storage_url,
+			hash, COALESCE(content_hash, ''), storage_provider, storage_bucket, storage_path, storage_url,
 			upload_status, uploaded_by, uploaded_at, access_count, 
-			last_accessed_at, metadata
+			last_accessed_at, metadata, reference_count, expires_at
 		FROM file_metadata
-		WHERE hash = $1 AND file_size = $2 AND upload_status != 'deleted'
+		WHERE hash = $1 AND file_size = $2 AND upload_status != 'deleted' AND (expires_at IS NULL OR expires_at > NOW())
 		ORDER BY uploaded_at DESC
 	`
 
@@ -135,6 +165,7 @@ func (r *fileMetadataRepository) GetByHash(ctx context.Context, hash string, fil
 			&metadata.FileSize,
 			&metadata.MimeType,
 			&metadata.Hash,
+			&metadata.ContentHash,
 			&metadata.StorageProvider,
 			&metadata.StorageBucket,
 			&metadata.StoragePath,
@@ -145,6 +176,8 @@ func (r *fileMetadataRepository) GetByHash(ctx context.Context, hash string, fil
 			&metadata.AccessCount,
 			&metadata.LastAccessedAt,
 			&metadata.Metadata,
+			&metadata.ReferenceCount,
+			&metadata.ExpiresAt,
 		)
 		if err != nil {
 			return nil, err
@@ -159,11 +192,11 @@ func (r *fileMetadataRepository) GetByFileName(ctx context.Context, fileName str
 	query := `
 		SELECT 
 			id, original_name, file_name, file_extension, file_size, mime_type,
-			hash, storage_provider, storage_bucket, storage_path, storage_url,
+			hash, COALESCE(content_hash, ''), storage_provider, storage_bucket, storage_path, storage_url,
 			upload_status, uploaded_by, uploaded_at, access_count, 
-			last_accessed_at, metadata
+			last_accessed_at, metadata, reference_count, expires_at
 		FROM file_metadata
-		WHERE file_name = $1 AND upload_status != 'deleted'
+		WHERE file_name = $1 AND upload_status != 'deleted' AND (expires_at IS NULL OR expires_at > NOW())
 	`
 
 	metadata := &models.FileMetadata{}
@@ -175,6 +208,7 @@ func (r *fileMetadataRepository) GetByFileName(ctx context.Context, fileName str
 		&metadata.FileSize,
 		&metadata.MimeType,
 		&metadata.Hash,
+		&metadata.ContentHash,
 		&metadata.StorageProvider,
 		&metadata.StorageBucket,
 		&metadata.StoragePath,
@@ -185,6 +219,8 @@ func (r *fileMetadataRepository) GetByFileName(ctx context.Context, fileName str
 		&metadata.AccessCount,
 		&metadata.LastAccessedAt,
 		&metadata.Metadata,
+		&metadata.ReferenceCount,
+		&metadata.ExpiresAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -194,17 +230,25 @@ func (r *fileMetadataRepository) GetByFileName(ctx context.Context, fileName str
 	return metadata, err
 }
 
-func (r *fileMetadataRepository) GetAll(ctx context.Context, limit, offset int, status string) ([]*models.FileMetadata, int, error) {
-	countQuery := `SELECT COUNT(*) FROM file_metadata WHERE upload_status != 'deleted'`
-	var countArgs []interface{}
+func (r *fileMetadataRepository) GetAll(ctx context.Context, limit, offset int, filter models.FileListFilter) ([]*models.FileMetadata, int, error) {
+	where := ` WHERE upload_status != 'deleted' AND (expires_at IS NULL OR expires_at > NOW())`
+	var args []interface{}
 
-	if status != "" {
-		countQuery += ` AND upload_status = $1`
-		countArgs = append(countArgs, status)
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += fmt.Sprintf(` AND upload_status = $%d`, len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where += fmt.Sprintf(` AND uploaded_at >= $%d`, len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where += fmt.Sprintf(` AND uploaded_at <= $%d`, len(args))
 	}
 
 	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&total)
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM file_metadata`+where, args...).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -212,24 +256,13 @@ func (r *fileMetadataRepository) GetAll(ctx context.Context, limit, offset int,
 	query := `
 		SELECT 
 			id, original_name, file_name, file_extension, file_size, mime_type,
-			hash, storage_provider, storage_bucket, storage_path, storage_url,
+			hash, COALESCE(content_hash, ''), storage_provider, storage_bucket, storage_path, storage_url,
 			upload_status, uploaded_by, uploaded_at, access_count, 
-			last_accessed_at, metadata
-		FROM file_metadata
-		WHERE upload_status != 'deleted'
-	`
+			last_accessed_at, metadata, reference_count, expires_at
+		FROM file_metadata` + where
 
-	var queryArgs []interface{}
-	argCount := 1
-
-	if status != "" {
-		query += ` AND upload_status = $1`
-		queryArgs = append(queryArgs, status)
-		argCount++
-	}
-
-	query += ` ORDER BY uploaded_at DESC LIMIT $` + fmt.Sprint(argCount) + ` OFFSET $` + fmt.Sprint(argCount+1)
-	queryArgs = append(queryArgs, limit, offset)
+	queryArgs := append(args, limit, offset)
+	query += fmt.Sprintf(` ORDER BY uploaded_at DESC LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
 
 	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
 	if err != nil {
@@ -248,6 +281,7 @@ func (r *fileMetadataRepository) GetAll(ctx context.Context, limit, offset int,
 			&metadata.FileSize,
 			&metadata.MimeType,
 			&metadata.Hash,
+			&metadata.ContentHash,
 			&metadata.StorageProvider,
 			&metadata.StorageBucket,
 			&metadata.StoragePath,
@@ -258,6 +292,8 @@ func (r *fileMetadataRepository) GetAll(ctx context.Context, limit, offset int,
 			&metadata.AccessCount,
 			&metadata.LastAccessedAt,
 			&metadata.Metadata,
+			&metadata.ReferenceCount,
+			&metadata.ExpiresAt,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -318,6 +354,113 @@ func (r *fileMetadataRepository) SoftDelete(ctx context.Context, id string) erro
 	return err
 }
 
+func (r *fileMetadataRepository) GetDeletedByID(ctx context.Context, id string) (*models.FileMetadata, error) {
+	query := `
+		SELECT 
+			id, original_name, file_name, file_extension, file_size, mime_type,
+			hash, COALESCE(content_hash, ''), storage_provider, storage_bucket, storage_path, storage_url,
+			upload_status, uploaded_by, uploaded_at, access_count, 
+			last_accessed_at, metadata, reference_count, expires_at
+		FROM file_metadata
+		WHERE id = $1 AND upload_status = 'deleted'
+	`
+
+	metadata := &models.FileMetadata{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&metadata.ID,
+		&metadata.OriginalName,
+		&metadata.FileName,
+		&metadata.FileExtension,
+		&metadata.FileSize,
+		&metadata.MimeType,
+		&metadata.Hash,
+		&metadata.ContentHash,
+		&metadata.StorageProvider,
+		&metadata.StorageBucket,
+		&metadata.StoragePath,
+		&metadata.StorageURL,
+		&metadata.UploadStatus,
+		&metadata.UploadedBy,
+		&metadata.UploadedAt,
+		&metadata.AccessCount,
+		&metadata.LastAccessedAt,
+		&metadata.Metadata,
+		&metadata.ReferenceCount,
+		&metadata.ExpiresAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return metadata, err
+}
+
+func (r *fileMetadataRepository) Restore(ctx context.Context, id string) error {
+	// Последняя ссылка снята при удалении; восстановленный файл снова принадлежит одной загрузке
+	query := `
+		UPDATE file_metadata
+		SET upload_status = 'uploaded',
+			reference_count = GREATEST(reference_count, 1),
+			expires_at = CASE WHEN expires_at <= NOW() THEN NULL ELSE expires_at END
+		WHERE id = $1 AND upload_status = 'deleted'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *fileMetadataRepository) AddReference(ctx context.Context, id string, expiresAt *time.Time) (bool, error) {
+	query := `
+		UPDATE file_metadata
+		SET reference_count = reference_count + 1,
+			expires_at = CASE
+				WHEN expires_at IS NULL OR $2::timestamptz IS NULL THEN NULL
+				ELSE GREATEST(expires_at, $2::timestamptz)
+			END
+		WHERE id = $1 AND upload_status != 'deleted'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *fileMetadataRepository) ReleaseReference(ctx context.Context, id string) (int, error) {
+	query := `
+		UPDATE file_metadata
+		SET reference_count = GREATEST(reference_count - 1, 0),
+			upload_status = CASE WHEN reference_count <= 1 THEN 'deleted' ELSE upload_status END
+		WHERE id = $1 AND upload_status != 'deleted'
+		RETURNING reference_count
+	`
+
+	var remaining int
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&remaining)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return remaining, err
+}
+
 func (r *fileMetadataRepository) GetStats(ctx context.Context) (*models.FileStats, error) {
 	stats := &models.FileStats{}
 
@@ -382,7 +525,7 @@ func (r *fileMetadataRepository) GetStats(ctx context.Context) (*models.FileStat
 }
 
 func (r *fileMetadataRepository) Exists(ctx context.Context, id string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM file_metadata WHERE id = $1 AND upload_status != 'deleted')`
+	query := `SELECT EXISTS(SELECT 1 FROM file_metadata WHERE id = $1 AND upload_status != 'deleted' AND (expires_at IS NULL OR expires_at > NOW()))`
 	var exists bool
 	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
 	return exists, err
@@ -392,11 +535,11 @@ func (r *fileMetadataRepository) SearchByMetadata(ctx context.Context, key, valu
 	query := `
 		SELECT 
 			id, original_name, file_name, file_extension, file_size, mime_type,
-			hash, storage_provider, storage_bucket, storage_path, storage_url,
+			hash, COALESCE(content_hash, ''), storage_provider, storage_bucket, storage_path, storage_url,
 			upload_status, uploaded_by, uploaded_at, access_count, 
-			last_accessed_at, metadata
+			last_accessed_at, metadata, reference_count, expires_at
 		FROM file_metadata
-		WHERE upload_status != 'deleted' 
+		WHERE upload_status != 'deleted' AND (expires_at IS NULL OR expires_at > NOW()) 
 		AND metadata->>$1 = $2
 		ORDER BY uploaded_at DESC
 	`
@@ -418,6 +561,7 @@ func (r *fileMetadataRepository) SearchByMetadata(ctx context.Context, key, valu
 			&metadata.FileSize,
 			&metadata.MimeType,
 			&metadata.Hash,
+			&metadata.ContentHash,
 			&metadata.StorageProvider,
 			&metadata.StorageBucket,
 			&metadata.StoragePath,
@@ -428,6 +572,8 @@ func (r *fileMetadataRepository) SearchByMetadata(ctx context.Context, key, valu
 			&metadata.AccessCount,
 			&metadata.LastAccessedAt,
 			&metadata.Metadata,
+			&metadata.ReferenceCount,
+			&metadata.ExpiresAt,
 		)
 		if err != nil {
 			return nil, err
@@ -437,3 +583,235 @@ func (r *fileMetadataRepository) SearchByMetadata(ctx context.Context, key, valu
 
 	return files, nil
 }
+
+func (r *fileMetadataRepository) GetExpired(ctx context.Context, before time.Time, deleted bool, limit int) ([]*models.FileMetadata, error) {
+	status := "upload_status != 'deleted'"
+	if deleted {
+		status = "upload_status = 'deleted'"
+	}
+
+	query := `
+		SELECT 
+			id, original_name, file_name, file_extension, file_size, mime_type,
+			hash, COALESCE(content_hash, ''), storage_provider, storage_bucket, storage_path, storage_url,
+			upload_status, uploaded_by, uploaded_at, access_count, 
+			last_accessed_at, metadata, reference_count, expires_at
+		FROM file_metadata
+		WHERE expires_at IS NOT NULL AND expires_at <= $1 AND ` + status + `
+		ORDER BY expires_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*models.FileMetadata
+	for rows.Next() {
+		metadata := &models.FileMetadata{}
+		err := rows.Scan(
+			&metadata.ID,
+			&metadata.OriginalName,
+			&metadata.FileName,
+			&metadata.FileExtension,
+			&metadata.FileSize,
+			&metadata.MimeType,
+			&metadata.Hash,
+			&metadata.ContentHash,
+			&metadata.StorageProvider,
+			&metadata.StorageBucket,
+			&metadata.StoragePath,
+			&metadata.StorageURL,
+			&metadata.UploadStatus,
+			&metadata.UploadedBy,
+			&metadata.UploadedAt,
+			&metadata.AccessCount,
+			&metadata.LastAccessedAt,
+			&metadata.Metadata,
+			&metadata.ReferenceCount,
+			&metadata.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, metadata)
+	}
+
+	return files, rows.Err()
+}
+
+func (r *fileMetadataRepository) ListByBucket(ctx context.Context, bucket string, afterID *string, limit int) ([]*models.FileMetadata, error) {
+	query := `
+		SELECT 
+			id, original_name, file_name, file_extension, file_size, mime_type,
+			hash, COALESCE(content_hash, ''), storage_provider, storage_bucket, storage_path, storage_url,
+			upload_status, uploaded_by, uploaded_at, access_count, 
+			last_accessed_at, metadata, reference_count, expires_at
+		FROM file_metadata
+		WHERE storage_bucket = $1 AND ($2::uuid IS NULL OR id > $2::uuid)
+		ORDER BY id
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, bucket, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*models.FileMetadata
+	for rows.Next() {
+		metadata := &models.FileMetadata{}
+		err := rows.Scan(
+			&metadata.ID,
+			&metadata.OriginalName,
+			&metadata.FileName,
+			&metadata.FileExtension,
+			&metadata.FileSize,
+			&metadata.MimeType,
+			&metadata.Hash,
+			&metadata.ContentHash,
+			&metadata.StorageProvider,
+			&metadata.StorageBucket,
+			&metadata.StoragePath,
+			&metadata.StorageURL,
+			&metadata.UploadStatus,
+			&metadata.UploadedBy,
+			&metadata.UploadedAt,
+			&metadata.AccessCount,
+			&metadata.LastAccessedAt,
+			&metadata.Metadata,
+			&metadata.ReferenceCount,
+			&metadata.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, metadata)
+	}
+
+	return files, rows.Err()
+}
+
+func (r *fileMetadataRepository) MoveToBucket(ctx context.Context, id, fromBucket, toBucket string) error {
+	query := `
+		UPDATE file_metadata
+		SET storage_bucket = $3
+		WHERE id = $1 AND storage_bucket = $2
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, fromBucket, toBucket)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *fileMetadataRepository) SearchFiles(ctx context.Context, query string, filter models.FileSearchFilter, limit, offset int) ([]models.FileSearchMatch, int, error) {
+	// $1 — шаблон ILIKE, $2 — тег: строка или элемент массива metadata.tags
+	args := []interface{}{"%" + escapeLike(query) + "%", query}
+	where := ` WHERE upload_status != 'deleted' AND (expires_at IS NULL OR expires_at > NOW())
+		AND (original_name ILIKE $1
+			OR metadata @> jsonb_build_object('tags', $2::text)
+			OR metadata @> jsonb_build_object('tags', jsonb_build_array($2::text)))`
+
+	if filter.MimeType != "" {
+		args = append(args, filter.MimeType)
+		where += fmt.Sprintf(` AND mime_type = $%d`, len(args))
+	}
+	if filter.UploadedBy != "" {
+		args = append(args, filter.UploadedBy)
+		where += fmt.Sprintf(` AND uploaded_by = $%d`, len(args))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where += fmt.Sprintf(` AND uploaded_at >= $%d`, len(args))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where += fmt.Sprintf(` AND uploaded_at <= $%d`, len(args))
+	}
+	if len(filter.Metadata) > 0 {
+		args = append(args, string(filter.Metadata))
+		where += fmt.Sprintf(` AND metadata @> $%d::jsonb`, len(args))
+	}
+
+	var total int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM file_metadata`+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query = `
+		SELECT 
+			id, original_name, file_name, file_extension, file_size, mime_type,
+			hash, COALESCE(content_hash, ''), storage_provider, storage_bucket, storage_path, storage_url,
+			upload_status, uploaded_by, uploaded_at, access_count, 
+			last_accessed_at, metadata, reference_count, expires_at,
+			original_name ILIKE $1
+		FROM file_metadata` + where
+
+	queryArgs := append(args, limit, offset)
+	query += fmt.Sprintf(` ORDER BY uploaded_at DESC, id LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var matches []models.FileSearchMatch
+	for rows.Next() {
+		metadata := &models.FileMetadata{}
+		var nameMatched bool
+		err := rows.Scan(
+			&metadata.ID,
+			&metadata.OriginalName,
+			&metadata.FileName,
+			&metadata.FileExtension,
+			&metadata.FileSize,
+			&metadata.MimeType,
+			&metadata.Hash,
+			&metadata.ContentHash,
+			&metadata.StorageProvider,
+			&metadata.StorageBucket,
+			&metadata.StoragePath,
+			&metadata.StorageURL,
+			&metadata.UploadStatus,
+			&metadata.UploadedBy,
+			&metadata.UploadedAt,
+			&metadata.AccessCount,
+			&metadata.LastAccessedAt,
+			&metadata.Metadata,
+			&metadata.ReferenceCount,
+			&metadata.ExpiresAt,
+			&nameMatched,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		matchType := models.FileMatchMetadata
+		if nameMatched {
+			matchType = models.FileMatchName
+		}
+		matches = append(matches, models.FileSearchMatch{File: metadata, MatchType: matchType})
+	}
+
+	return matches, total, rows.Err()
+}
+
+// escapeLike экранирует % и _, чтобы они в запросе искались как обычные символы
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
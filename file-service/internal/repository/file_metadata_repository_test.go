@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+)
+
+// recordingDB — драйвер database/sql, который запоминает запросы с аргументами и отвечает
+// заготовками: COUNT(*) — числом count, остальные SELECT — строками rows
+type recordingDB struct {
+	mu      sync.Mutex
+	queries []recordedQuery
+	count   int64
+	columns []string
+	rows    [][]driver.Value
+}
+
+type recordedQuery struct {
+	query string
+	args  []driver.Value
+}
+
+func (d *recordingDB) Connect(context.Context) (driver.Conn, error) {
+	return &recordingConn{db: d}, nil
+}
+func (d *recordingDB) Driver() driver.Driver { return nil }
+
+type recordingConn struct{ db *recordingDB }
+
+func (c *recordingConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (c *recordingConn) Close() error { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *recordingConn) QueryContext(_ context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	args := make([]driver.Value, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.queries = append(c.db.queries, recordedQuery{query: query, args: args})
+	if strings.Contains(query, "COUNT(*)") {
+		return &recordingRows{columns: []string{"count"}, rows: [][]driver.Value{{c.db.count}}}, nil
+	}
+	return &recordingRows{columns: c.db.columns, rows: c.db.rows}, nil
+}
+
+type recordingRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *recordingRows) Columns() []string { return r.columns }
+func (r *recordingRows) Close() error      { return nil }
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// searchRow — строка выборки SearchFiles в порядке её колонок
+func searchRow(id, name string, uploadedAt time.Time, nameMatched bool) []driver.Value {
+	return []driver.Value{
+		id, name, id + ".txt", ".txt", int64(42), "text/plain",
+		"hash-" + id, "", "minio", "files", "2024/01/02/" + id + ".txt", "",
+		"uploaded", "teacher", uploadedAt, int64(0),
+		nil, []byte(`{"tags":["essay"]}`), int64(1), nil,
+		nameMatched,
+	}
+}
+
+// whereClause — условие запроса от FROM file_metadata до ORDER BY
+func whereClause(t *testing.T, query string) string {
+	t.Helper()
+
+	_, rest, ok := strings.Cut(query, "FROM file_metadata")
+	if !ok {
+		t.Fatalf("query has no FROM file_metadata: %s", query)
+	}
+	where, _, _ := strings.Cut(rest, " ORDER BY")
+	return where
+}
+
+func TestSearchFilesOrderingAndTotals(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		filter    models.FileSearchFilter
+		extraArgs []driver.Value
+		order     string
+	}{
+		{
+			name:  "without filters",
+			order: "ORDER BY uploaded_at DESC, id LIMIT $3 OFFSET $4",
+		},
+		{
+			name: "all filters",
+			filter: models.FileSearchFilter{
+				MimeType:   "text/plain",
+				UploadedBy: "teacher",
+				From:       &from,
+				To:         &to,
+				Metadata:   []byte(`{"course":"go"}`),
+			},
+			extraArgs: []driver.Value{"text/plain", "teacher", from, to, `{"course":"go"}`},
+			order:     "ORDER BY uploaded_at DESC, id LIMIT $8 OFFSET $9",
+		},
+		{
+			name:      "uploader and period end only",
+			filter:    models.FileSearchFilter{UploadedBy: "teacher", To: &to},
+			extraArgs: []driver.Value{"teacher", to},
+			order:     "ORDER BY uploaded_at DESC, id LIMIT $5 OFFSET $6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploaded := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+			fake := &recordingDB{
+				count:   7,
+				columns: make([]string, 21),
+				rows: [][]driver.Value{
+					searchRow("b", "Essay.txt", uploaded, true),
+					searchRow("c", "essay-2.txt", uploaded, true),
+					searchRow("a", "report.txt", uploaded.Add(-time.Hour), false),
+				},
+			}
+			db := sql.OpenDB(fake)
+			defer db.Close()
+			repo := NewFileMetadataRepository(db, zerolog.Nop())
+
+			matches, total, err := repo.SearchFiles(context.Background(), "essay", tt.filter, 3, 3)
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+
+			if total != 7 {
+				t.Fatalf("total = %d, want 7", total)
+			}
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.File.ID+":"+m.MatchType)
+			}
+			want := []string{"b:" + models.FileMatchName, "c:" + models.FileMatchName, "a:" + models.FileMatchMetadata}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("matches = %v, want %v", got, want)
+			}
+
+			if len(fake.queries) != 2 {
+				t.Fatalf("ran %d queries, want COUNT and SELECT", len(fake.queries))
+			}
+			count, page := fake.queries[0], fake.queries[1]
+
+			// Итог считается по тем же условиям и аргументам, что и страница
+			if whereClause(t, count.query) != whereClause(t, page.query) {
+				t.Fatalf("COUNT and SELECT conditions differ:\n%s\n---\n%s", count.query, page.query)
+			}
+			wantArgs := append([]driver.Value{"%essay%", "essay"}, tt.extraArgs...)
+			if !reflect.DeepEqual(count.args, wantArgs) {
+				t.Fatalf("COUNT args = %v, want %v", count.args, wantArgs)
+			}
+			if !reflect.DeepEqual(page.args, append(wantArgs, int64(3), int64(3))) {
+				t.Fatalf("SELECT args = %v, want %v followed by limit and offset", page.args, wantArgs)
+			}
+
+			// Равные uploaded_at упорядочиваются по id, поэтому страницы не перекрываются
+			if !strings.HasSuffix(page.query, tt.order) {
+				t.Fatalf("SELECT ends with %q, want %q", page.query[strings.LastIndex(page.query, "ORDER BY"):], tt.order)
+			}
+		})
+	}
+}
+
+func TestSearchFilesEscapesLikePattern(t *testing.T) {
+	fake := &recordingDB{}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	repo := NewFileMetadataRepository(db, zerolog.Nop())
+
+	if _, _, err := repo.SearchFiles(context.Background(), `100%_a\b`, models.FileSearchFilter{}, 10, 0); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+
+	// В шаблоне ILIKE спецсимволы экранированы, тег сравнивается как есть
+	args := fake.queries[0].args
+	if args[0] != `%100\%\_a\\b%` || args[1] != `100%_a\b` {
+		t.Fatalf("args = %q, want escaped pattern and raw tag", args)
+	}
+}
@@ -1,28 +1,36 @@
 package repository
 
 import (
+	"compress/gzip"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+	"github.com/klauspost/compress/zstd"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/rs/zerolog"
 )
 
 type MinIORepository struct {
-	client *minio.Client
-	bucket string
-	region string
-	logger zerolog.Logger
-
-	ensureMu      sync.Mutex
-	bucketEnsured bool
+	client   *minio.Client
+	bucket   string
+	region   string
+	provider string
+	// Создавать бакет, если его нет; для S3 бакет должен существовать заранее
+	createBucket bool
+	logger       zerolog.Logger
+
+	ensureMu sync.Mutex
+	// Читается без блокировки на каждой операции; ensureMu нужен только до первой успешной проверки
+	bucketEnsured atomic.Bool
 }
 
 func NewMinIORepository(endpoint, accessKey, secretKey, bucket, region string, useSSL bool, connectTimeout time.Duration, logger zerolog.Logger) (*MinIORepository, error) {
@@ -35,10 +43,12 @@ func NewMinIORepository(endpoint, accessKey, secretKey, bucket, region string, u
 	}
 
 	repo := &MinIORepository{
-		client: client,
-		bucket: bucket,
-		region: region,
-		logger: logger,
+		client:       client,
+		bucket:       bucket,
+		region:       region,
+		provider:     "minio",
+		createBucket: true,
+		logger:       logger,
 	}
 
 	if connectTimeout <= 0 {
@@ -66,9 +76,14 @@ func NewMinIORepository(endpoint, accessKey, secretKey, bucket, region string, u
 }
 
 func (r *MinIORepository) ensureBucket(ctx context.Context) error {
+	if r.bucketEnsured.Load() {
+		return nil
+	}
+
 	r.ensureMu.Lock()
 	defer r.ensureMu.Unlock()
-	if r.bucketEnsured {
+	// Пока ждали блокировку, бакет мог проверить другой запрос
+	if r.bucketEnsured.Load() {
 		return nil
 	}
 
@@ -78,9 +93,11 @@ func (r *MinIORepository) ensureBucket(ctx context.Context) error {
 			return fmt.Errorf("minio not ready: %w", err)
 		}
 
-		if _, err := r.client.ListBuckets(ctx); err != nil {
-			time.Sleep(backoff)
-			continue
+		if r.createBucket {
+			if _, err := r.client.ListBuckets(ctx); err != nil {
+				time.Sleep(backoff)
+				continue
+			}
 		}
 
 		exists, err := r.client.BucketExists(ctx, r.bucket)
@@ -89,6 +106,9 @@ func (r *MinIORepository) ensureBucket(ctx context.Context) error {
 			continue
 		}
 
+		if !exists && !r.createBucket {
+			return fmt.Errorf("bucket %s does not exist", r.bucket)
+		}
 		if !exists {
 			if err := r.client.MakeBucket(ctx, r.bucket, minio.MakeBucketOptions{Region: r.region}); err != nil {
 				time.Sleep(backoff)
@@ -97,18 +117,33 @@ func (r *MinIORepository) ensureBucket(ctx context.Context) error {
 			r.logger.Info().Str("bucket", r.bucket).Msg("Created new bucket")
 		}
 
-		r.bucketEnsured = true
+		r.bucketEnsured.Store(true)
 		return nil
 	}
 }
 
-func (r *MinIORepository) UploadFile(ctx context.Context, bucket, fileName string, file io.Reader, size int64) error {
+// Provider возвращает имя хранилища, которое записывается в storage_provider метаданных файла
+func (r *MinIORepository) Provider() string {
+	return r.provider
+}
+
+func (r *MinIORepository) UploadFile(ctx context.Context, bucket, fileName string, file io.Reader, size int64, opts models.StorageObjectOptions) error {
 	if err := r.ensureBucket(ctx); err != nil {
 		return err
 	}
-	uploadInfo, err := r.client.PutObject(ctx, bucket, fileName, file, size, minio.PutObjectOptions{
+	putOpts := minio.PutObjectOptions{
 		ContentType: "application/octet-stream",
-	})
+	}
+	if opts.Compression != "" {
+		// Content-Encoding позволяет клиентам presigned URL распаковать объект самостоятельно
+		putOpts.ContentEncoding = opts.Compression
+		putOpts.UserMetadata = map[string]string{
+			compressionMetaKey:  opts.Compression,
+			originalSizeMetaKey: strconv.FormatInt(opts.OriginalSize, 10),
+		}
+	}
+
+	uploadInfo, err := r.client.PutObject(ctx, bucket, fileName, file, size, putOpts)
 	if err != nil {
 		return fmt.Errorf("failed to upload file: %w", err)
 	}
@@ -146,7 +181,109 @@ func (r *MinIORepository) DownloadFile(ctx context.Context, bucket, fileName str
 		Int64("size", objInfo.Size).
 		Msg("File downloaded from MinIO")
 
-	return object, objInfo.Size, nil
+	compression := objInfo.UserMetadata[compressionMetaKey]
+	if compression == "" {
+		return object, objInfo.Size, nil
+	}
+
+	decoder, err := newDecompressor(compression, object)
+	if err != nil {
+		object.Close()
+		return nil, 0, fmt.Errorf("failed to decompress file: %w", err)
+	}
+
+	size := objInfo.Size
+	if originalSize, err := strconv.ParseInt(objInfo.UserMetadata[originalSizeMetaKey], 10, 64); err == nil {
+		size = originalSize
+	}
+
+	return &decompressedObjectReader{decoder: decoder, object: object}, size, nil
+}
+
+// DownloadFileRange запрашивает у хранилища только нужные байты; сжатый объект хранится одним
+// потоком gzip или zstd, поэтому он распаковывается с начала, а байты до offset пропускаются
+func (r *MinIORepository) DownloadFileRange(ctx context.Context, bucket, fileName string, offset, length int64) (io.ReadCloser, error) {
+	if err := r.ensureBucket(ctx); err != nil {
+		return nil, err
+	}
+	objInfo, err := r.client.StatObject(ctx, bucket, fileName, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, errors.New("file not found")
+		}
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	compression := objInfo.UserMetadata[compressionMetaKey]
+	if compression == "" {
+		opts := minio.GetObjectOptions{}
+		if err := opts.SetRange(offset, offset+length-1); err != nil {
+			return nil, fmt.Errorf("invalid range: %w", err)
+		}
+		object, err := r.client.GetObject(ctx, bucket, fileName, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file: %w", err)
+		}
+		return object, nil
+	}
+
+	object, err := r.client.GetObject(ctx, bucket, fileName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	decoder, err := newDecompressor(compression, object)
+	if err != nil {
+		object.Close()
+		return nil, fmt.Errorf("failed to decompress file: %w", err)
+	}
+	if _, err := io.CopyN(io.Discard, decoder, offset); err != nil {
+		decoder.Close()
+		object.Close()
+		return nil, fmt.Errorf("failed to seek in decompressed file: %w", err)
+	}
+
+	return &decompressedObjectReader{decoder: decoder, object: object, limit: io.LimitReader(decoder, length)}, nil
+}
+
+// Ключи пользовательских метаданных объекта (MinIO отдаёт их в канонической форме)
+const (
+	compressionMetaKey  = "Compression"
+	originalSizeMetaKey = "Original-Size"
+)
+
+// newDecompressor — распаковка объекта по алгоритму из его метаданных
+func newDecompressor(compression string, object io.Reader) (io.ReadCloser, error) {
+	switch compression {
+	case models.StorageCompressionGzip:
+		return gzip.NewReader(object)
+	case models.StorageCompressionZstd:
+		decoder, err := zstd.NewReader(object)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unsupported compression: %s", compression)
+}
+
+// decompressedObjectReader распаковывает объект при чтении и закрывает его вместе с потоком распаковки;
+// с limit читается только часть распакованного содержимого
+type decompressedObjectReader struct {
+	decoder io.ReadCloser
+	object  io.Closer
+	limit   io.Reader
+}
+
+func (r *decompressedObjectReader) Read(p []byte) (int, error) {
+	if r.limit != nil {
+		return r.limit.Read(p)
+	}
+	return r.decoder.Read(p)
+}
+
+func (r *decompressedObjectReader) Close() error {
+	r.decoder.Close()
+	return r.object.Close()
 }
 
 func (r *MinIORepository) DeleteFile(ctx context.Context, bucket, fileName string) error {
@@ -181,6 +318,31 @@ func (r *MinIORepository) FileExists(ctx context.Context, bucket, fileName strin
 	return true, nil
 }
 
+func (r *MinIORepository) CopyFile(ctx context.Context, srcBucket, dstBucket, fileName string) error {
+	_, err := r.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: dstBucket, Object: fileName},
+		minio.CopySrcOptions{Bucket: srcBucket, Object: fileName},
+	)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return errors.New("file not found")
+		}
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	r.logger.Debug().
+		Str("src_bucket", srcBucket).
+		Str("dst_bucket", dstBucket).
+		Str("file", fileName).
+		Msg("File copied in MinIO")
+
+	return nil
+}
+
+func (r *MinIORepository) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	return r.client.BucketExists(ctx, bucket)
+}
+
 func (r *MinIORepository) GetFileInfo(ctx context.Context, bucket, fileName string) (*models.FileInfoResponse, error) {
 	if err := r.ensureBucket(ctx); err != nil {
 		return nil, err
@@ -253,7 +415,7 @@ func (r *MinIORepository) GetBucketStats(ctx context.Context, bucket string) (*m
 	}
 
 	return &models.StorageInfo{
-		Provider:   "minio",
+		Provider:   r.provider,
 		BucketName: bucket,
 		Region:     r.region,
 		UsedSpace:  totalSize,
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+)
+
+func compress(t *testing.T, algorithm string, data []byte) []byte {
+	t.Helper()
+
+	switch algorithm {
+	case models.StorageCompressionZstd:
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer encoder.Close()
+		return encoder.EncodeAll(data, nil)
+	default:
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(data)
+		gz.Close()
+		return buf.Bytes()
+	}
+}
+
+func TestCompressedObjectRoundTrip(t *testing.T) {
+	original := []byte(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 200))
+
+	for _, algorithm := range []string{models.StorageCompressionGzip, models.StorageCompressionZstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			s3, endpoint := newFakeS3(t, "files")
+			repo := newTestS3Repository(t, endpoint, "files")
+			ctx := context.Background()
+
+			stored := compress(t, algorithm, original)
+			opts := models.StorageObjectOptions{Compression: algorithm, OriginalSize: int64(len(original))}
+			if err := repo.UploadFile(ctx, "files", "essay.txt", bytes.NewReader(stored), int64(len(stored)), opts); err != nil {
+				t.Fatalf("upload: %v", err)
+			}
+			if raw, _ := s3.object("files", "essay.txt"); len(raw) >= len(original) {
+				t.Fatalf("stored %d bytes, want compressed object smaller than %d", len(raw), len(original))
+			}
+
+			reader, size, err := repo.DownloadFile(ctx, "files", "essay.txt")
+			if err != nil {
+				t.Fatalf("download: %v", err)
+			}
+			got, err := io.ReadAll(reader)
+			reader.Close()
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if !bytes.Equal(got, original) {
+				t.Fatalf("downloaded %d bytes differ from the original %d bytes", len(got), len(original))
+			}
+			if size != int64(len(original)) {
+				t.Fatalf("size = %d, want original size %d", size, len(original))
+			}
+
+			// Диапазон считается по распакованному содержимому
+			reader, err = repo.DownloadFileRange(ctx, "files", "essay.txt", 100, 50)
+			if err != nil {
+				t.Fatalf("download range: %v", err)
+			}
+			part, _ := io.ReadAll(reader)
+			reader.Close()
+			if !bytes.Equal(part, original[100:150]) {
+				t.Fatalf("range = %q, want %q", part, original[100:150])
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/rs/zerolog"
+)
+
+// S3Repository хранит файлы в AWS S3 (или совместимом хранилище). Протокол совпадает с MinIO,
+// поэтому операции с объектами, presigned URL и статистика бакета общие с MinIORepository;
+// отличаются подключение и учётные данные, а бакет должен быть создан заранее.
+type S3Repository struct {
+	*MinIORepository
+}
+
+// NewS3Repository без accessKey берёт учётные данные по цепочке AWS:
+// переменные окружения, ~/.aws/credentials, затем IAM-роль инстанса
+func NewS3Repository(endpoint, accessKey, secretKey, sessionToken, bucket, region string, useSSL bool, connectTimeout time.Duration, logger zerolog.Logger) (*S3Repository, error) {
+	creds := credentials.NewStaticV4(accessKey, secretKey, sessionToken)
+	if accessKey == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+		})
+	}
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: useSSL,
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	repo := &S3Repository{
+		MinIORepository: &MinIORepository{
+			client:   client,
+			bucket:   bucket,
+			region:   region,
+			provider: "s3",
+			logger:   logger,
+		},
+	}
+
+	if connectTimeout <= 0 {
+		connectTimeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+	if err := repo.ensureBucket(ctx); err != nil {
+		logger.Error().Err(err).
+			Str("endpoint", endpoint).
+			Str("bucket", bucket).
+			Msg("S3 bucket not available during startup; file-service will keep running and retry on demand")
+	}
+
+	logger.Info().
+		Str("endpoint", endpoint).
+		Str("bucket", bucket).
+		Str("region", region).
+		Msg("Connected to S3")
+
+	return repo, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+)
+
+func TestS3RepositoryRoundTrip(t *testing.T) {
+	s3, endpoint := newFakeS3(t, "files")
+	repo := newTestS3Repository(t, endpoint, "files")
+	ctx := context.Background()
+	content := []byte("essay submitted to the course")
+
+	if repo.Provider() != "s3" {
+		t.Fatalf("provider = %q, want s3", repo.Provider())
+	}
+
+	if err := repo.UploadFile(ctx, "files", "2024/01/02/essay.txt", bytes.NewReader(content), int64(len(content)), models.StorageObjectOptions{}); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if stored, ok := s3.object("files", "2024/01/02/essay.txt"); !ok || !bytes.Equal(stored, content) {
+		t.Fatalf("stored object = %q, want %q", stored, content)
+	}
+
+	reader, size, err := repo.DownloadFile(ctx, "files", "2024/01/02/essay.txt")
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	got, _ := io.ReadAll(reader)
+	reader.Close()
+	if !bytes.Equal(got, content) || size != int64(len(content)) {
+		t.Fatalf("downloaded %q (%d bytes), want %q", got, size, content)
+	}
+
+	exists, err := repo.FileExists(ctx, "files", "2024/01/02/essay.txt")
+	if err != nil || !exists {
+		t.Fatalf("exists = %v, %v; want true", exists, err)
+	}
+
+	if err := repo.DeleteFile(ctx, "files", "2024/01/02/essay.txt"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, _, err := repo.DownloadFile(ctx, "files", "2024/01/02/essay.txt"); err == nil || !strings.Contains(err.Error(), "file not found") {
+		t.Fatalf("download after delete: err = %v, want file not found", err)
+	}
+}
+
+func TestS3RepositoryRequiresExistingBucket(t *testing.T) {
+	_, endpoint := newFakeS3(t)
+
+	repo, err := NewS3Repository(endpoint, "access", "secret", "", "missing", "us-east-1", false, time.Second, zerolog.Nop())
+	if err != nil {
+		t.Fatalf("new s3 repository: %v", err)
+	}
+
+	// В отличие от MinIO, бакет S3 не создаётся автоматически
+	err = repo.UploadFile(context.Background(), "missing", "a.txt", strings.NewReader("a"), 1, models.StorageObjectOptions{})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("upload to missing bucket: err = %v, want bucket does not exist", err)
+	}
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type StorageMigrationRepository interface {
+	Create(ctx context.Context, migration *models.StorageMigration) error
+	// GetRunning — незавершённый перенос между бакетами; nil, если такого нет
+	GetRunning(ctx context.Context, sourceBucket, targetBucket string) (*models.StorageMigration, error)
+	// SaveProgress сохраняет курсор и счётчики; с completed переносит в статус completed
+	SaveProgress(ctx context.Context, migration *models.StorageMigration, completed bool) error
+}
+
+type storageMigrationRepository struct {
+	*PostgresRepository
+}
+
+func NewStorageMigrationRepository(db *sql.DB, logger zerolog.Logger) StorageMigrationRepository {
+	return &storageMigrationRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+func (r *storageMigrationRepository) Create(ctx context.Context, migration *models.StorageMigration) error {
+	query := `
+		INSERT INTO storage_migrations (id, source_bucket, target_bucket, copy_objects, status, started_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		migration.ID,
+		migration.SourceBucket,
+		migration.TargetBucket,
+		migration.CopyObjects,
+		migration.Status,
+		migration.StartedAt,
+	)
+
+	return err
+}
+
+func (r *storageMigrationRepository) GetRunning(ctx context.Context, sourceBucket, targetBucket string) (*models.StorageMigration, error) {
+	query := `
+		SELECT id, source_bucket, target_bucket, copy_objects, status, last_file_id,
+			migrated, failed, last_error, started_at, updated_at, completed_at
+		FROM storage_migrations
+		WHERE source_bucket = $1 AND target_bucket = $2 AND status = 'running'
+	`
+
+	migration := &models.StorageMigration{}
+	err := r.db.QueryRowContext(ctx, query, sourceBucket, targetBucket).Scan(
+		&migration.ID,
+		&migration.SourceBucket,
+		&migration.TargetBucket,
+		&migration.CopyObjects,
+		&migration.Status,
+		&migration.LastFileID,
+		&migration.Migrated,
+		&migration.Failed,
+		&migration.LastError,
+		&migration.StartedAt,
+		&migration.UpdatedAt,
+		&migration.CompletedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return migration, err
+}
+
+func (r *storageMigrationRepository) SaveProgress(ctx context.Context, migration *models.StorageMigration, completed bool) error {
+	query := `
+		UPDATE storage_migrations
+		SET last_file_id = $2,
+			migrated = $3,
+			failed = $4,
+			last_error = $5,
+			updated_at = NOW(),
+			status = CASE WHEN $6 THEN 'completed' ELSE status END,
+			completed_at = CASE WHEN $6 THEN NOW() ELSE completed_at END
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		migration.ID,
+		migration.LastFileID,
+		migration.Migrated,
+		migration.Failed,
+		migration.LastError,
+		completed,
+	)
+
+	return err
+}
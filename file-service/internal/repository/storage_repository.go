@@ -9,14 +9,20 @@ import (
 )
 
 type StorageRepository interface {
-	UploadFile(ctx context.Context, bucket, fileName string, file io.Reader, size int64) error
+	UploadFile(ctx context.Context, bucket, fileName string, file io.Reader, size int64, opts models.StorageObjectOptions) error
 	DownloadFile(ctx context.Context, bucket, fileName string) (io.ReadCloser, int64, error)
+	// DownloadFileRange читает length байт содержимого файла начиная с offset
+	DownloadFileRange(ctx context.Context, bucket, fileName string, offset, length int64) (io.ReadCloser, error)
 	DeleteFile(ctx context.Context, bucket, fileName string) error
 	FileExists(ctx context.Context, bucket, fileName string) (bool, error)
+	// CopyFile копирует объект в другой бакет того же хранилища на стороне сервера, с метаданными объекта
+	CopyFile(ctx context.Context, srcBucket, dstBucket, fileName string) error
+	BucketExists(ctx context.Context, bucket string) (bool, error)
 	GetFileInfo(ctx context.Context, bucket, fileName string) (*models.FileInfoResponse, error)
 	GetPresignedURL(ctx context.Context, bucket, fileName string, expiresIn int64) (string, error)
 	ListFiles(ctx context.Context, bucket, prefix string) ([]string, error)
 	GetBucketStats(ctx context.Context, bucket string) (*models.StorageInfo, error)
+	Provider() string
 }
 
 type storageRepository struct {
@@ -31,14 +37,18 @@ func NewStorageRepository(provider StorageRepository, logger zerolog.Logger) Sto
 	}
 }
 
-func (r *storageRepository) UploadFile(ctx context.Context, bucket, fileName string, file io.Reader, size int64) error {
-	return r.provider.UploadFile(ctx, bucket, fileName, file, size)
+func (r *storageRepository) UploadFile(ctx context.Context, bucket, fileName string, file io.Reader, size int64, opts models.StorageObjectOptions) error {
+	return r.provider.UploadFile(ctx, bucket, fileName, file, size, opts)
 }
 
 func (r *storageRepository) DownloadFile(ctx context.Context, bucket, fileName string) (io.ReadCloser, int64, error) {
 	return r.provider.DownloadFile(ctx, bucket, fileName)
 }
 
+func (r *storageRepository) DownloadFileRange(ctx context.Context, bucket, fileName string, offset, length int64) (io.ReadCloser, error) {
+	return r.provider.DownloadFileRange(ctx, bucket, fileName, offset, length)
+}
+
 func (r *storageRepository) DeleteFile(ctx context.Context, bucket, fileName string) error {
 	return r.provider.DeleteFile(ctx, bucket, fileName)
 }
@@ -47,6 +57,14 @@ func (r *storageRepository) FileExists(ctx context.Context, bucket, fileName str
 	return r.provider.FileExists(ctx, bucket, fileName)
 }
 
+func (r *storageRepository) CopyFile(ctx context.Context, srcBucket, dstBucket, fileName string) error {
+	return r.provider.CopyFile(ctx, srcBucket, dstBucket, fileName)
+}
+
+func (r *storageRepository) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	return r.provider.BucketExists(ctx, bucket)
+}
+
 func (r *storageRepository) GetFileInfo(ctx context.Context, bucket, fileName string) (*models.FileInfoResponse, error) {
 	return r.provider.GetFileInfo(ctx, bucket, fileName)
 }
@@ -62,3 +80,7 @@ func (r *storageRepository) ListFiles(ctx context.Context, bucket, prefix string
 func (r *storageRepository) GetBucketStats(ctx context.Context, bucket string) (*models.StorageInfo, error) {
 	return r.provider.GetBucketStats(ctx, bucket)
 }
+
+func (r *storageRepository) Provider() string {
+	return r.provider.Provider()
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+	"github.com/rs/zerolog"
+)
+
+type UploadSessionRepository interface {
+	Create(ctx context.Context, session *models.UploadSession) error
+	GetByID(ctx context.Context, id string) (*models.UploadSession, error)
+	SaveChunk(ctx context.Context, chunk *models.UploadChunk) error
+	ListChunks(ctx context.Context, sessionID string) ([]*models.UploadChunk, error)
+	MarkCompleted(ctx context.Context, id, fileID string) (bool, error)
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*models.UploadSession, error)
+	Expire(ctx context.Context, id string) error
+}
+
+type uploadSessionRepository struct {
+	*PostgresRepository
+}
+
+func NewUploadSessionRepository(db *sql.DB, logger zerolog.Logger) UploadSessionRepository {
+	return &uploadSessionRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+func (r *uploadSessionRepository) Create(ctx context.Context, session *models.UploadSession) error {
+	query := `
+		INSERT INTO upload_sessions (id, file_name, total_chunks, uploaded_by, metadata, status, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		session.ID,
+		session.FileName,
+		session.TotalChunks,
+		session.UploadedBy,
+		session.Metadata,
+		session.Status,
+		session.CreatedAt,
+		session.ExpiresAt,
+	)
+
+	return err
+}
+
+func (r *uploadSessionRepository) GetByID(ctx context.Context, id string) (*models.UploadSession, error) {
+	query := `
+		SELECT id, file_name, total_chunks, COALESCE(uploaded_by, ''), metadata, status, file_id, created_at, expires_at
+		FROM upload_sessions
+		WHERE id = $1
+	`
+
+	session := &models.UploadSession{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&session.ID,
+		&session.FileName,
+		&session.TotalChunks,
+		&session.UploadedBy,
+		&session.Metadata,
+		&session.Status,
+		&session.FileID,
+		&session.CreatedAt,
+		&session.ExpiresAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return session, err
+}
+
+// SaveChunk записывает часть; повторная отправка той же части заменяет прежнюю
+func (r *uploadSessionRepository) SaveChunk(ctx context.Context, chunk *models.UploadChunk) error {
+	query := `
+		INSERT INTO upload_session_chunks (session_id, chunk_number, size, storage_path, received_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (session_id, chunk_number) DO UPDATE
+		SET size = EXCLUDED.size, storage_path = EXCLUDED.storage_path, received_at = EXCLUDED.received_at
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		chunk.SessionID,
+		chunk.ChunkNumber,
+		chunk.Size,
+		chunk.StoragePath,
+		chunk.ReceivedAt,
+	)
+
+	return err
+}
+
+func (r *uploadSessionRepository) ListChunks(ctx context.Context, sessionID string) ([]*models.UploadChunk, error) {
+	query := `
+		SELECT session_id, chunk_number, size, storage_path, received_at
+		FROM upload_session_chunks
+		WHERE session_id = $1
+		ORDER BY chunk_number
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chunks []*models.UploadChunk
+	for rows.Next() {
+		chunk := &models.UploadChunk{}
+		if err := rows.Scan(
+			&chunk.SessionID,
+			&chunk.ChunkNumber,
+			&chunk.Size,
+			&chunk.StoragePath,
+			&chunk.ReceivedAt,
+		); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, rows.Err()
+}
+
+// MarkCompleted переводит активную сессию в completed; false — сессию уже завершил другой запрос
+func (r *uploadSessionRepository) MarkCompleted(ctx context.Context, id, fileID string) (bool, error) {
+	query := `
+		UPDATE upload_sessions
+		SET status = 'completed', file_id = $2
+		WHERE id = $1 AND status = 'active'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, fileID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *uploadSessionRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*models.UploadSession, error) {
+	query := `
+		SELECT id, file_name, total_chunks, COALESCE(uploaded_by, ''), metadata, status, file_id, created_at, expires_at
+		FROM upload_sessions
+		WHERE status = 'active' AND expires_at < $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.UploadSession
+	for rows.Next() {
+		session := &models.UploadSession{}
+		if err := rows.Scan(
+			&session.ID,
+			&session.FileName,
+			&session.TotalChunks,
+			&session.UploadedBy,
+			&session.Metadata,
+			&session.Status,
+			&session.FileID,
+			&session.CreatedAt,
+			&session.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Expire помечает сессию истёкшей и удаляет записи о её частях; сама сессия остаётся,
+// чтобы клиент получал понятную ошибку вместо "не найдено"
+func (r *uploadSessionRepository) Expire(ctx context.Context, id string) error {
+	tx, err := r.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE upload_sessions SET status = 'expired' WHERE id = $1`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM upload_session_chunks WHERE session_id = $1`, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
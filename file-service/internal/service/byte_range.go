@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrRangeNotSatisfiable — ни один байт запрошенного диапазона не попадает в файл
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// RangeNotSatisfiableError несёт размер файла для заголовка Content-Range: bytes */size
+type RangeNotSatisfiableError struct {
+	Size int64
+}
+
+func (e *RangeNotSatisfiableError) Error() string {
+	return ErrRangeNotSatisfiable.Error()
+}
+
+func (e *RangeNotSatisfiableError) Unwrap() error {
+	return ErrRangeNotSatisfiable
+}
+
+// parseRange разбирает заголовок Range (RFC 9110) для файла размера size и возвращает
+// первый и последний байт включительно. ok=false — отдать файл целиком: заголовка нет,
+// он не в байтах, некорректен или запрашивает несколько диапазонов (multipart не поддерживается)
+func parseRange(header string, size int64) (start, end int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+
+	if first == "" {
+		// bytes=-N — последние N байт
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, 0, false, nil
+		}
+		if suffix == 0 || size == 0 {
+			return 0, 0, false, &RangeNotSatisfiableError{Size: size}
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, size - 1, true, nil
+	}
+
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+	end = size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, nil
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+
+	if start >= size {
+		return 0, 0, false, &RangeNotSatisfiableError{Size: size}
+	}
+	return start, end, true, nil
+}
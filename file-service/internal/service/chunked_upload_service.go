@@ -0,0 +1,333 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/repository"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// ChunkedUploadService принимает файл частями в рамках сессии: части складываются во временный
+// префикс хранилища в любом порядке, а при завершении собираются и проходят обычную загрузку.
+type ChunkedUploadService interface {
+	InitUpload(ctx context.Context, req *models.InitUploadRequest) (*models.InitUploadResponse, error)
+	UploadChunk(ctx context.Context, sessionID string, chunkNumber int, data []byte) (*models.UploadChunkResponse, error)
+	CompleteUpload(ctx context.Context, sessionID string) (*models.UploadFileResponse, error)
+	CleanupExpired(ctx context.Context) (int, error)
+	GetConfig() ChunkedUploadConfig
+}
+
+type ChunkedUploadConfig struct {
+	BucketName string
+	// Префикс объектов с частями незавершённых загрузок
+	TempPrefix    string
+	MaxChunkSize  int64
+	MaxChunks     int
+	MaxUploadSize int64
+	SessionTTL    time.Duration
+}
+
+type chunkedUploadService struct {
+	sessionRepo   repository.UploadSessionRepository
+	storageRepo   repository.StorageRepository
+	uploadService UploadService
+	logger        zerolog.Logger
+	config        ChunkedUploadConfig
+}
+
+// Сколько истёкших сессий очищается за один проход
+const expiredSessionsBatch = 100
+
+func NewChunkedUploadService(
+	sessionRepo repository.UploadSessionRepository,
+	storageRepo repository.StorageRepository,
+	uploadService UploadService,
+	logger zerolog.Logger,
+	config ChunkedUploadConfig,
+) ChunkedUploadService {
+	return &chunkedUploadService{
+		sessionRepo:   sessionRepo,
+		storageRepo:   storageRepo,
+		uploadService: uploadService,
+		logger:        logger,
+		config:        config,
+	}
+}
+
+func (s *chunkedUploadService) GetConfig() ChunkedUploadConfig {
+	return s.config
+}
+
+func (s *chunkedUploadService) InitUpload(ctx context.Context, req *models.InitUploadRequest) (*models.InitUploadResponse, error) {
+	if strings.TrimSpace(req.FileName) == "" {
+		return nil, errors.New("file_name is required")
+	}
+	if req.TotalChunks <= 0 || (s.config.MaxChunks > 0 && req.TotalChunks > s.config.MaxChunks) {
+		return nil, fmt.Errorf("total_chunks must be within 1..%d", s.config.MaxChunks)
+	}
+
+	metadata := []byte(req.Metadata)
+	if len(metadata) == 0 {
+		metadata = []byte("{}")
+	}
+
+	now := time.Now()
+	session := &models.UploadSession{
+		ID:          uuid.New().String(),
+		FileName:    req.FileName,
+		TotalChunks: req.TotalChunks,
+		UploadedBy:  req.UploadedBy,
+		Metadata:    metadata,
+		Status:      models.UploadSessionActive.String(),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.config.SessionTTL),
+	}
+
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, fmt.Errorf("failed to create upload session: %w", err)
+	}
+
+	s.logger.Info().
+		Str("session_id", session.ID).
+		Str("file_name", session.FileName).
+		Int("total_chunks", session.TotalChunks).
+		Msg("Chunked upload started")
+
+	return &models.InitUploadResponse{
+		SessionID:    session.ID,
+		TotalChunks:  session.TotalChunks,
+		MaxChunkSize: s.config.MaxChunkSize,
+		ExpiresAt:    session.ExpiresAt,
+	}, nil
+}
+
+// UploadChunk сохраняет часть с номером 0..total_chunks-1. Части принимаются в любом порядке,
+// повторная отправка заменяет ранее полученную часть.
+func (s *chunkedUploadService) UploadChunk(ctx context.Context, sessionID string, chunkNumber int, data []byte) (*models.UploadChunkResponse, error) {
+	session, err := s.getActiveSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if chunkNumber < 0 || chunkNumber >= session.TotalChunks {
+		return nil, fmt.Errorf("chunk number out of range: expected 0..%d", session.TotalChunks-1)
+	}
+	if len(data) == 0 {
+		return nil, errors.New("chunk is empty")
+	}
+	if s.config.MaxChunkSize > 0 && int64(len(data)) > s.config.MaxChunkSize {
+		return nil, fmt.Errorf("chunk size exceeds limit: %d bytes", s.config.MaxChunkSize)
+	}
+
+	storagePath := s.chunkPath(sessionID, chunkNumber)
+	if err := s.storageRepo.UploadFile(
+		ctx,
+		s.config.BucketName,
+		storagePath,
+		bytes.NewReader(data),
+		int64(len(data)),
+		models.StorageObjectOptions{},
+	); err != nil {
+		return nil, fmt.Errorf("failed to upload chunk to storage: %w", err)
+	}
+
+	if err := s.sessionRepo.SaveChunk(ctx, &models.UploadChunk{
+		SessionID:   sessionID,
+		ChunkNumber: chunkNumber,
+		Size:        int64(len(data)),
+		StoragePath: storagePath,
+		ReceivedAt:  time.Now(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save chunk: %w", err)
+	}
+
+	chunks, err := s.sessionRepo.ListChunks(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+
+	return &models.UploadChunkResponse{
+		SessionID:      sessionID,
+		ChunkNumber:    chunkNumber,
+		Size:           int64(len(data)),
+		ReceivedChunks: len(chunks),
+		TotalChunks:    session.TotalChunks,
+	}, nil
+}
+
+// CompleteUpload собирает части по порядку и загружает результат как обычный файл,
+// поэтому хеш, проверка дубликатов и метаданные совпадают с загрузкой одним запросом.
+func (s *chunkedUploadService) CompleteUpload(ctx context.Context, sessionID string) (*models.UploadFileResponse, error) {
+	session, err := s.getActiveSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks, err := s.sessionRepo.ListChunks(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+
+	if missing := missingChunks(chunks, session.TotalChunks); len(missing) > 0 {
+		return nil, fmt.Errorf("missing chunks: %s", joinInts(missing))
+	}
+
+	var total int64
+	for _, chunk := range chunks {
+		total += chunk.Size
+	}
+	if s.config.MaxUploadSize > 0 && total > s.config.MaxUploadSize {
+		return nil, fmt.Errorf("file size exceeds limit: %d bytes", s.config.MaxUploadSize)
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(int(total))
+	for _, chunk := range chunks {
+		if err := s.appendChunk(ctx, &buf, chunk); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := s.uploadService.UploadFileBytes(ctx, session.FileName, buf.Bytes(), session.UploadedBy, session.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	completed, err := s.sessionRepo.MarkCompleted(ctx, sessionID, response.FileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete upload session: %w", err)
+	}
+	if completed {
+		s.deleteChunkObjects(ctx, chunks)
+	}
+
+	s.logger.Info().
+		Str("session_id", sessionID).
+		Str("file_id", response.FileID).
+		Int("chunks", len(chunks)).
+		Int64("size", total).
+		Msg("Chunked upload completed")
+
+	return response, nil
+}
+
+// CleanupExpired удаляет части истёкших сессий из хранилища и помечает сессии expired
+func (s *chunkedUploadService) CleanupExpired(ctx context.Context) (int, error) {
+	sessions, err := s.sessionRepo.ListExpired(ctx, time.Now(), expiredSessionsBatch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list expired upload sessions: %w", err)
+	}
+
+	cleaned := 0
+	for _, session := range sessions {
+		chunks, err := s.sessionRepo.ListChunks(ctx, session.ID)
+		if err != nil {
+			s.logger.Error().Err(err).Str("session_id", session.ID).Msg("Failed to list chunks of expired upload session")
+			continue
+		}
+
+		s.deleteChunkObjects(ctx, chunks)
+
+		if err := s.sessionRepo.Expire(ctx, session.ID); err != nil {
+			s.logger.Error().Err(err).Str("session_id", session.ID).Msg("Failed to expire upload session")
+			continue
+		}
+		cleaned++
+	}
+
+	return cleaned, nil
+}
+
+func (s *chunkedUploadService) getActiveSession(ctx context.Context, sessionID string) (*models.UploadSession, error) {
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return nil, errors.New("upload session not found")
+	}
+
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get upload session: %w", err)
+	}
+	if session == nil {
+		return nil, errors.New("upload session not found")
+	}
+
+	switch session.Status {
+	case models.UploadSessionCompleted.String():
+		fileID := ""
+		if session.FileID != nil {
+			fileID = *session.FileID
+		}
+		return nil, fmt.Errorf("upload session already completed: file_id %s", fileID)
+	case models.UploadSessionExpired.String():
+		return nil, errors.New("upload session expired")
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		return nil, errors.New("upload session expired")
+	}
+
+	return session, nil
+}
+
+func (s *chunkedUploadService) appendChunk(ctx context.Context, buf *bytes.Buffer, chunk *models.UploadChunk) error {
+	reader, _, err := s.storageRepo.DownloadFile(ctx, s.config.BucketName, chunk.StoragePath)
+	if err != nil {
+		return fmt.Errorf("failed to read chunk %d: %w", chunk.ChunkNumber, err)
+	}
+	defer reader.Close()
+
+	n, err := io.Copy(buf, reader)
+	if err != nil {
+		return fmt.Errorf("failed to read chunk %d: %w", chunk.ChunkNumber, err)
+	}
+	if n != chunk.Size {
+		return fmt.Errorf("failed to read chunk %d: stored size %d, expected %d", chunk.ChunkNumber, n, chunk.Size)
+	}
+
+	return nil
+}
+
+func (s *chunkedUploadService) deleteChunkObjects(ctx context.Context, chunks []*models.UploadChunk) {
+	for _, chunk := range chunks {
+		if err := s.storageRepo.DeleteFile(ctx, s.config.BucketName, chunk.StoragePath); err != nil {
+			s.logger.Warn().Err(err).Str("path", chunk.StoragePath).Msg("Failed to delete upload chunk")
+		}
+	}
+}
+
+func (s *chunkedUploadService) chunkPath(sessionID string, chunkNumber int) string {
+	return fmt.Sprintf("%s/%s/%06d", strings.TrimSuffix(s.config.TempPrefix, "/"), sessionID, chunkNumber)
+}
+
+// missingChunks возвращает номера частей, которые ещё не получены; chunks отсортированы по номеру
+func missingChunks(chunks []*models.UploadChunk, total int) []int {
+	var missing []int
+	next := 0
+	for _, chunk := range chunks {
+		for ; next < chunk.ChunkNumber && next < total; next++ {
+			missing = append(missing, next)
+		}
+		next = chunk.ChunkNumber + 1
+	}
+	for ; next < total; next++ {
+		missing = append(missing, next)
+	}
+	return missing
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+)
+
+func newTestUploadService(storage *memStorage, metadata *memMetadataRepo, config UploadConfig) UploadService {
+	if config.MaxUploadSize == 0 {
+		config.MaxUploadSize = 1 << 20
+	}
+	if config.BucketName == "" {
+		config.BucketName = "files"
+	}
+	return NewUploadService(metadata, storage, NewHashService("sha256"), NewTextExtractor(), zerolog.Nop(), config)
+}
+
+func TestChunkedUploadHashesLikeSingleUpload(t *testing.T) {
+	ctx := context.Background()
+	content := bytes.Repeat([]byte("Chunked essay text, paragraph after paragraph.\n"), 50)
+
+	single, err := newTestUploadService(newMemStorage(), newMemMetadataRepo(), UploadConfig{}).
+		UploadFileBytes(ctx, "essay.txt", content, "student", nil)
+	if err != nil {
+		t.Fatalf("single upload: %v", err)
+	}
+
+	storage := newMemStorage()
+	chunked := NewChunkedUploadService(
+		newMemSessionRepo(),
+		storage,
+		newTestUploadService(storage, newMemMetadataRepo(), UploadConfig{}),
+		zerolog.Nop(),
+		ChunkedUploadConfig{BucketName: "files", TempPrefix: "uploads/", MaxChunks: 10, SessionTTL: time.Hour},
+	)
+
+	session, err := chunked.InitUpload(ctx, &models.InitUploadRequest{FileName: "essay.txt", TotalChunks: 3, UploadedBy: "student"})
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+
+	third := len(content) / 3
+	parts := [][]byte{content[:third], content[third : 2*third], content[2*third:]}
+	// Части приходят не по порядку
+	for _, n := range []int{2, 0, 1} {
+		if _, err := chunked.UploadChunk(ctx, session.SessionID, n, parts[n]); err != nil {
+			t.Fatalf("chunk %d: %v", n, err)
+		}
+	}
+
+	assembled, err := chunked.CompleteUpload(ctx, session.SessionID)
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+
+	if assembled.Hash != single.Hash || assembled.FileSize != single.FileSize || assembled.ContentHash != single.ContentHash {
+		t.Fatalf("chunked upload hash %s/%d/%s, single upload %s/%d/%s",
+			assembled.Hash, assembled.FileSize, assembled.ContentHash, single.Hash, single.FileSize, single.ContentHash)
+	}
+	// После сборки в хранилище остаётся только сам файл, временные части удалены
+	if n := storage.count(); n != 1 {
+		t.Fatalf("storage holds %d objects after completion, want 1", n)
+	}
+}
+
+func TestChunkedUploadRejectsMissingChunk(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemStorage()
+	chunked := NewChunkedUploadService(
+		newMemSessionRepo(),
+		storage,
+		newTestUploadService(storage, newMemMetadataRepo(), UploadConfig{}),
+		zerolog.Nop(),
+		ChunkedUploadConfig{BucketName: "files", TempPrefix: "uploads", MaxChunks: 10, SessionTTL: time.Hour},
+	)
+
+	session, err := chunked.InitUpload(ctx, &models.InitUploadRequest{FileName: "essay.txt", TotalChunks: 3})
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if _, err := chunked.UploadChunk(ctx, session.SessionID, 0, []byte("first")); err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+
+	if _, err := chunked.CompleteUpload(ctx, session.SessionID); err == nil || err.Error() != "missing chunks: 1, 2" {
+		t.Fatalf("complete: err = %v, want missing chunks: 1, 2", err)
+	}
+}
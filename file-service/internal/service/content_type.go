@@ -0,0 +1,113 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// oleMimeType — составной документ OLE2: так хранятся .doc, .xls и .ppt
+const oleMimeType = "application/x-ole-storage"
+
+const ooxmlPrefix = "application/vnd.openxmlformats-officedocument."
+
+var contentSignatures = []struct {
+	magic    []byte
+	mimeType string
+}{
+	{[]byte("%PDF-"), "application/pdf"},
+	{[]byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1"), oleMimeType},
+	{[]byte("Rar!\x1A\x07"), "application/x-rar-compressed"},
+	{[]byte("7z\xBC\xAF\x27\x1C"), "application/x-7z-compressed"},
+	{[]byte("PK\x03\x04"), "application/zip"},
+	{[]byte("PK\x05\x06"), "application/zip"},
+}
+
+// extensionContentTypes — какие типы по содержимому допустимы для расширения.
+// Расширений, которых здесь нет, проверка не касается.
+var extensionContentTypes = map[string][]string{
+	".txt":  {"text/"},
+	".pdf":  {"application/pdf"},
+	".doc":  {oleMimeType},
+	".xls":  {oleMimeType},
+	".ppt":  {oleMimeType},
+	".docx": {ooxmlPrefix + "wordprocessingml.document"},
+	".xlsx": {ooxmlPrefix + "spreadsheetml.sheet"},
+	".pptx": {ooxmlPrefix + "presentationml.presentation"},
+	// docx/xlsx/pptx — тоже zip-архивы
+	".zip":  {"application/zip", ooxmlPrefix},
+	".rar":  {"application/x-rar-compressed"},
+	".7z":   {"application/x-7z-compressed"},
+	".jpg":  {"image/jpeg"},
+	".jpeg": {"image/jpeg"},
+	".png":  {"image/png"},
+	".gif":  {"image/gif"},
+	".bmp":  {"image/bmp"},
+}
+
+// detectContentType определяет тип по сигнатуре содержимого; zip дополнительно
+// разбирается, чтобы отличить docx/xlsx/pptx от обычного архива
+func detectContentType(fileBytes []byte) string {
+	for _, sig := range contentSignatures {
+		if !bytes.HasPrefix(fileBytes, sig.magic) {
+			continue
+		}
+		if sig.mimeType == "application/zip" {
+			return detectZipContentType(fileBytes)
+		}
+		return sig.mimeType
+	}
+
+	sniffLen := 512
+	if len(fileBytes) < sniffLen {
+		sniffLen = len(fileBytes)
+	}
+	ct := strings.ToLower(http.DetectContentType(fileBytes[:sniffLen]))
+	if semi := strings.Index(ct, ";"); semi >= 0 {
+		ct = strings.TrimSpace(ct[:semi])
+	}
+	return ct
+}
+
+func detectZipContentType(fileBytes []byte) string {
+	reader, err := zip.NewReader(bytes.NewReader(fileBytes), int64(len(fileBytes)))
+	if err != nil {
+		return "application/zip"
+	}
+
+	hasContentTypes := false
+	kind := ""
+	for _, f := range reader.File {
+		switch {
+		case f.Name == "[Content_Types].xml":
+			hasContentTypes = true
+		case strings.HasPrefix(f.Name, "word/"):
+			kind = "wordprocessingml.document"
+		case strings.HasPrefix(f.Name, "xl/"):
+			kind = "spreadsheetml.sheet"
+		case strings.HasPrefix(f.Name, "ppt/"):
+			kind = "presentationml.presentation"
+		}
+	}
+
+	if hasContentTypes && kind != "" {
+		return ooxmlPrefix + kind
+	}
+	return "application/zip"
+}
+
+// contentMatchesExtension сверяет тип по содержимому с допустимыми для расширения
+func contentMatchesExtension(ext, detected string) bool {
+	allowed, ok := extensionContentTypes[ext]
+	if !ok {
+		return true
+	}
+
+	for _, prefix := range allowed {
+		if strings.HasPrefix(detected, prefix) {
+			return true
+		}
+	}
+	return false
+}
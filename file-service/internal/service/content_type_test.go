@@ -0,0 +1,81 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+// testZip собирает zip-архив с пустыми файлами по именам
+func testZip(t *testing.T, names ...string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range names {
+		if _, err := archive.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestContentMatchesExtension(t *testing.T) {
+	docx := testZip(t, "[Content_Types].xml", "word/document.xml")
+
+	tests := []struct {
+		name    string
+		ext     string
+		content []byte
+		want    bool
+	}{
+		{"pdf", ".pdf", []byte("%PDF-1.7\n..."), true},
+		{"png renamed to pdf", ".pdf", pngHeader, false},
+		{"docx", ".docx", docx, true},
+		{"zip renamed to docx", ".docx", testZip(t, "notes.txt"), false},
+		{"docx renamed to xlsx", ".xlsx", docx, false},
+		{"docx as zip", ".zip", docx, true},
+		{"text", ".txt", []byte("plain essay text"), true},
+		{"pdf renamed to txt", ".txt", []byte("%PDF-1.4"), false},
+		{"old word document", ".doc", []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1rest"), true},
+		{"unchecked extension", ".go", pngHeader, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detected := detectContentType(tt.content)
+			if got := contentMatchesExtension(tt.ext, detected); got != tt.want {
+				t.Fatalf("contentMatchesExtension(%s, %s) = %v, want %v", tt.ext, detected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUploadRejectsSpoofedExtension(t *testing.T) {
+	storage := newMemStorage()
+	metadata := newMemMetadataRepo()
+	upload := newTestUploadService(storage, metadata, UploadConfig{VerifyContentType: true})
+
+	_, err := upload.UploadFileBytes(context.Background(), "essay.pdf", pngHeader, "student", nil)
+	if err == nil || !strings.Contains(err.Error(), "does not match extension .pdf") {
+		t.Fatalf("spoofed upload: err = %v, want content mismatch", err)
+	}
+	if storage.count() != 0 || len(metadata.files) != 0 {
+		t.Fatal("spoofed file was stored")
+	}
+
+	response, err := upload.UploadFileBytes(context.Background(), "essay.pdf", []byte("%PDF-1.7\n%%EOF"), "student", nil)
+	if err != nil {
+		t.Fatalf("genuine pdf: %v", err)
+	}
+	if !strings.Contains(string(response.Metadata), `"detected_mime_type":"application/pdf"`) {
+		t.Fatalf("metadata = %s, want detected_mime_type", response.Metadata)
+	}
+}
@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/repository"
@@ -14,6 +16,11 @@ type DeleteService interface {
 	DeleteFile(ctx context.Context, fileID string, hardDelete bool) (*models.DeleteFileResponse, error)
 	DeleteFileByHash(ctx context.Context, hash string, fileSize int64, hardDelete bool) ([]*models.DeleteFileResponse, error)
 	CleanupExpiredFiles(ctx context.Context, daysOld int) (int, error)
+	// SweepExpiredFiles помечает удалёнными файлы с истёкшим expires_at, а помеченные раньше
+	// grace period назад удаляет из хранилища и БД; возвращает число файлов каждого вида
+	SweepExpiredFiles(ctx context.Context) (softDeleted, hardDeleted int, err error)
+	// RestoreFile отменяет мягкое удаление, если объект ещё есть в хранилище
+	RestoreFile(ctx context.Context, fileID string) (*models.RestoreFileResponse, error)
 }
 
 type deleteService struct {
@@ -21,6 +28,14 @@ type deleteService struct {
 	storageRepo  repository.StorageRepository
 	logger       zerolog.Logger
 	bucketName   string
+	config       DeleteConfig
+}
+
+type DeleteConfig struct {
+	// Сколько просроченный файл остаётся помеченным удалённым до окончательного удаления
+	ExpiredGracePeriod time.Duration
+	// Файлов каждого вида за один проход SweepExpiredFiles
+	SweepBatchSize int
 }
 
 func NewDeleteService(
@@ -28,12 +43,14 @@ func NewDeleteService(
 	storageRepo repository.StorageRepository,
 	logger zerolog.Logger,
 	bucketName string,
+	config DeleteConfig,
 ) DeleteService {
 	return &deleteService{
 		metadataRepo: metadataRepo,
 		storageRepo:  storageRepo,
 		logger:       logger,
 		bucketName:   bucketName,
+		config:       config,
 	}
 }
 
@@ -54,8 +71,29 @@ func (s *deleteService) DeleteFile(ctx context.Context, fileID string, hardDelet
 		}, nil
 	}
 
+	// Одинаковое содержимое хранится одним объектом на несколько загрузок: удаление снимает одну ссылку,
+	// а запись и объект удаляются только вместе с последней
+	remaining, err := s.metadataRepo.ReleaseReference(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release file reference: %w", err)
+	}
+
+	if remaining > 0 {
+		s.logger.Info().
+			Str("file_id", fileID).
+			Int("reference_count", remaining).
+			Msg("File reference released, file is still in use")
+
+		return &models.DeleteFileResponse{
+			FileID:         fileID,
+			Deleted:        false,
+			Message:        "File is still referenced by other uploads",
+			ReferenceCount: remaining,
+		}, nil
+	}
+
 	if hardDelete {
-		if err := s.storageRepo.DeleteFile(ctx, s.bucketName, metadata.StoragePath); err != nil {
+		if err := s.storageRepo.DeleteFile(ctx, objectBucket(metadata, s.bucketName), metadata.StoragePath); err != nil {
 			return nil, fmt.Errorf("failed to delete file from storage: %w", err)
 		}
 
@@ -73,21 +111,17 @@ func (s *deleteService) DeleteFile(ctx context.Context, fileID string, hardDelet
 			Deleted: true,
 			Message: "File permanently deleted",
 		}, nil
-	} else {
-		if err := s.metadataRepo.SoftDelete(ctx, fileID); err != nil {
-			return nil, fmt.Errorf("failed to soft delete file: %w", err)
-		}
+	}
 
-		s.logger.Info().
-			Str("file_id", fileID).
-			Msg("File soft deleted")
+	s.logger.Info().
+		Str("file_id", fileID).
+		Msg("File soft deleted")
 
-		return &models.DeleteFileResponse{
-			FileID:  fileID,
-			Deleted: true,
-			Message: "File marked as deleted",
-		}, nil
-	}
+	return &models.DeleteFileResponse{
+		FileID:  fileID,
+		Deleted: true,
+		Message: "File marked as deleted",
+	}, nil
 }
 
 func (s *deleteService) DeleteFileByHash(ctx context.Context, hash string, fileSize int64, hardDelete bool) ([]*models.DeleteFileResponse, error) {
@@ -120,3 +154,87 @@ func (s *deleteService) CleanupExpiredFiles(ctx context.Context, daysOld int) (i
 
 	return 0, nil
 }
+
+func (s *deleteService) SweepExpiredFiles(ctx context.Context) (int, int, error) {
+	now := time.Now()
+
+	expired, err := s.metadataRepo.GetExpired(ctx, now, false, s.config.SweepBatchSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get expired files: %w", err)
+	}
+
+	softDeleted := 0
+	for _, file := range expired {
+		if err := s.metadataRepo.SoftDelete(ctx, file.ID); err != nil {
+			s.logger.Error().Err(err).Str("file_id", file.ID).Msg("Failed to soft delete expired file")
+			continue
+		}
+		softDeleted++
+	}
+
+	// Срок отсчитывается от expires_at: файл, помеченный позже из-за паузы очистки, ждёт меньше
+	purge, err := s.metadataRepo.GetExpired(ctx, now.Add(-s.config.ExpiredGracePeriod), true, s.config.SweepBatchSize)
+	if err != nil {
+		return softDeleted, 0, fmt.Errorf("failed to get expired deleted files: %w", err)
+	}
+
+	hardDeleted := 0
+	for _, file := range purge {
+		// Запись остаётся, пока объект не удалён, чтобы следующий проход повторил попытку
+		if err := s.storageRepo.DeleteFile(ctx, objectBucket(file, s.bucketName), file.StoragePath); err != nil {
+			s.logger.Error().Err(err).Str("file_id", file.ID).Msg("Failed to delete expired file from storage")
+			continue
+		}
+		if err := s.metadataRepo.Delete(ctx, file.ID); err != nil {
+			s.logger.Error().Err(err).Str("file_id", file.ID).Msg("Failed to delete expired file metadata")
+			continue
+		}
+		hardDeleted++
+	}
+
+	return softDeleted, hardDeleted, nil
+}
+
+func (s *deleteService) RestoreFile(ctx context.Context, fileID string) (*models.RestoreFileResponse, error) {
+	metadata, err := s.metadataRepo.GetDeletedByID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	if metadata == nil {
+		exists, err := s.metadataRepo.Exists(ctx, fileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file metadata: %w", err)
+		}
+		if exists {
+			return nil, errors.New("file is not deleted")
+		}
+		return nil, errors.New("file not found")
+	}
+
+	exists, err := s.storageRepo.FileExists(ctx, objectBucket(metadata, s.bucketName), metadata.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check file in storage: %w", err)
+	}
+	if !exists {
+		return nil, errors.New("file no longer exists in storage")
+	}
+
+	if err := s.metadataRepo.Restore(ctx, fileID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Файл восстановили или удалили окончательно между проверкой и обновлением
+			return nil, errors.New("file is not deleted")
+		}
+		return nil, fmt.Errorf("failed to restore file metadata: %w", err)
+	}
+
+	s.logger.Info().
+		Str("file_id", fileID).
+		Str("storage_path", metadata.StoragePath).
+		Msg("File restored")
+
+	return &models.RestoreFileResponse{
+		FileID:   fileID,
+		Restored: true,
+		Message:  "File restored",
+	}, nil
+}
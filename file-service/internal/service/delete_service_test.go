@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+)
+
+// storedFile кладёт в хранилище и репозиторий файл со статусом и сроком хранения (nil — бессрочно)
+func storedFile(storage *memStorage, metadata *memMetadataRepo, id, status string, expiresAt *time.Time) {
+	path := "2024/01/01/" + id
+	storage.objects["files/"+path] = []byte("content of " + id)
+	metadata.files[id] = &models.FileMetadata{
+		ID:             id,
+		StorageBucket:  "files",
+		StoragePath:    path,
+		UploadStatus:   status,
+		ReferenceCount: 1,
+		ExpiresAt:      expiresAt,
+	}
+}
+
+func at(t time.Time) *time.Time { return &t }
+
+func TestSharedBlobSurvivesDeletingOneUpload(t *testing.T) {
+	ctx := context.Background()
+	storage := newMemStorage()
+	metadata := newMemMetadataRepo()
+	upload := newTestUploadService(storage, metadata, UploadConfig{CheckDuplicate: true})
+	deletes := NewDeleteService(metadata, storage, zerolog.Nop(), "files", DeleteConfig{})
+	downloads := NewDownloadService(metadata, storage, zerolog.Nop(), "files", DownloadConfig{})
+	content := []byte("the same essay submitted by two works")
+
+	first, err := upload.UploadFileBytes(ctx, "essay.txt", content, "work-1", nil)
+	if err != nil {
+		t.Fatalf("first upload: %v", err)
+	}
+	second, err := upload.UploadFileBytes(ctx, "copy.txt", content, "work-2", nil)
+	if err != nil {
+		t.Fatalf("second upload: %v", err)
+	}
+	if second.FileID != first.FileID || storage.count() != 1 {
+		t.Fatalf("duplicate stored separately: ids %s/%s, %d objects", first.FileID, second.FileID, storage.count())
+	}
+
+	// Удаление первой работы снимает только её ссылку
+	response, err := deletes.DeleteFile(ctx, first.FileID, true)
+	if err != nil {
+		t.Fatalf("delete first: %v", err)
+	}
+	if response.Deleted || response.ReferenceCount != 1 {
+		t.Fatalf("first delete = %+v, want one remaining reference", response)
+	}
+
+	file, err := downloads.DownloadFile(ctx, second.FileID)
+	if err != nil {
+		t.Fatalf("download after first delete: %v", err)
+	}
+	if string(file.Content) != string(content) {
+		t.Fatalf("content = %q, want %q", file.Content, content)
+	}
+
+	// Последняя ссылка удаляет и запись, и объект
+	response, err = deletes.DeleteFile(ctx, second.FileID, true)
+	if err != nil {
+		t.Fatalf("delete second: %v", err)
+	}
+	if !response.Deleted || storage.count() != 0 {
+		t.Fatalf("last delete = %+v with %d objects left", response, storage.count())
+	}
+	if _, err := downloads.DownloadFile(ctx, second.FileID); err == nil {
+		t.Fatal("file still downloadable after the last reference was deleted")
+	}
+}
+
+func TestSweepExpiredFilesPicksOnlyPastDue(t *testing.T) {
+	now := time.Now()
+	storage := newMemStorage()
+	metadata := newMemMetadataRepo()
+	uploaded, deleted := models.FileStatusUploaded.String(), models.FileStatusDeleted.String()
+
+	storedFile(storage, metadata, "past-due", uploaded, at(now.Add(-time.Minute)))
+	storedFile(storage, metadata, "due-later", uploaded, at(now.Add(time.Hour)))
+	storedFile(storage, metadata, "no-expiry", uploaded, nil)
+	storedFile(storage, metadata, "expired-long-ago", deleted, at(now.Add(-48*time.Hour)))
+	storedFile(storage, metadata, "expired-in-grace", deleted, at(now.Add(-time.Hour)))
+	// Удалён вручную, без срока хранения: очистка его не касается
+	storedFile(storage, metadata, "deleted-by-user", deleted, nil)
+
+	sweeper := NewDeleteService(metadata, storage, zerolog.Nop(), "files", DeleteConfig{
+		ExpiredGracePeriod: 24 * time.Hour,
+		SweepBatchSize:     10,
+	})
+
+	softDeleted, hardDeleted, err := sweeper.SweepExpiredFiles(context.Background())
+	if err != nil {
+		t.Fatalf("sweep: %v", err)
+	}
+	if softDeleted != 1 || hardDeleted != 1 {
+		t.Fatalf("sweep = %d soft, %d hard; want 1 and 1", softDeleted, hardDeleted)
+	}
+
+	want := map[string]string{
+		"past-due":         deleted,
+		"due-later":        uploaded,
+		"no-expiry":        uploaded,
+		"expired-long-ago": "",
+		"expired-in-grace": deleted,
+		"deleted-by-user":  deleted,
+	}
+	for id, status := range want {
+		if got := metadata.status(id); got != status {
+			t.Errorf("%s: status %q, want %q", id, got, status)
+		}
+	}
+	if _, ok := storage.objects["files/2024/01/01/expired-long-ago"]; ok {
+		t.Error("purged file is still in storage")
+	}
+	if storage.count() != 5 {
+		t.Errorf("storage holds %d objects, want 5", storage.count())
+	}
+}
+
+func TestRestoreFile(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	storage := newMemStorage()
+	metadata := newMemMetadataRepo()
+	uploaded, deleted := models.FileStatusUploaded.String(), models.FileStatusDeleted.String()
+
+	storedFile(storage, metadata, "soft-deleted", deleted, nil)
+	storedFile(storage, metadata, "expired", deleted, at(now.Add(-time.Hour)))
+	storedFile(storage, metadata, "blob-gone", deleted, nil)
+	delete(storage.objects, "files/2024/01/01/blob-gone")
+	storedFile(storage, metadata, "live", uploaded, nil)
+
+	restorer := NewDeleteService(metadata, storage, zerolog.Nop(), "files", DeleteConfig{})
+
+	tests := []struct {
+		id      string
+		wantErr string
+		status  string
+	}{
+		{"soft-deleted", "", uploaded},
+		{"expired", "", uploaded},
+		{"blob-gone", "file no longer exists in storage", deleted},
+		{"live", "file is not deleted", uploaded},
+		{"unknown", "file not found", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			response, err := restorer.RestoreFile(ctx, tt.id)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil || !response.Restored {
+				t.Fatalf("restore = %+v, %v", response, err)
+			}
+			if got := metadata.status(tt.id); got != tt.status {
+				t.Fatalf("status = %q, want %q", got, tt.status)
+			}
+		})
+	}
+
+	// Просроченный файл восстанавливается бессрочным, иначе очистка сразу удалит его снова
+	if file, _ := metadata.GetByID(ctx, "expired"); file == nil || file.ExpiresAt != nil {
+		t.Fatalf("restored expired file = %+v, want visible without expires_at", file)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/repository"
@@ -13,9 +14,13 @@ import (
 
 type DownloadService interface {
 	DownloadFile(ctx context.Context, fileID string) (*models.DownloadFileResponse, error)
+	// DownloadFileRange отдаёт часть файла по заголовку Range; если диапазон не применим
+	// (несколько диапазонов, некорректный заголовок), файл отдаётся целиком с Partial=false
+	DownloadFileRange(ctx context.Context, fileID, rangeHeader string) (*models.DownloadFileResponse, error)
 	DownloadFileByHash(ctx context.Context, hash string, fileSize int64) (*models.DownloadFileResponse, error)
 	GetFileInfo(ctx context.Context, fileID string) (*models.FileInfoResponse, error)
-	GetPresignedURL(ctx context.Context, fileID string, expiresIn int64) (string, error)
+	// GetPresignedURL возвращает ссылку и фактический срок её действия в секундах
+	GetPresignedURL(ctx context.Context, fileID string, expiresIn int64) (string, int64, error)
 }
 
 type downloadService struct {
@@ -23,6 +28,14 @@ type downloadService struct {
 	storageRepo  repository.StorageRepository
 	logger       zerolog.Logger
 	bucketName   string
+	config       DownloadConfig
+}
+
+type DownloadConfig struct {
+	// Максимальный срок действия presigned URL
+	MaxPresignedExpiry time.Duration
+	// Запросы сверх максимума отклоняются, иначе срок урезается до максимума
+	RejectOverMaxExpiry bool
 }
 
 func NewDownloadService(
@@ -30,12 +43,14 @@ func NewDownloadService(
 	storageRepo repository.StorageRepository,
 	logger zerolog.Logger,
 	bucketName string,
+	config DownloadConfig,
 ) DownloadService {
 	return &downloadService{
 		metadataRepo: metadataRepo,
 		storageRepo:  storageRepo,
 		logger:       logger,
 		bucketName:   bucketName,
+		config:       config,
 	}
 }
 
@@ -52,7 +67,7 @@ func (s *downloadService) DownloadFile(ctx context.Context, fileID string) (*mod
 		return nil, errors.New("file has been deleted")
 	}
 
-	fileReader, fileSize, err := s.storageRepo.DownloadFile(ctx, s.bucketName, metadata.StoragePath)
+	fileReader, fileSize, err := s.storageRepo.DownloadFile(ctx, objectBucket(metadata, s.bucketName), metadata.StoragePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download file from storage: %w", err)
 	}
@@ -82,6 +97,63 @@ func (s *downloadService) DownloadFile(ctx context.Context, fileID string) (*mod
 	}, nil
 }
 
+func (s *downloadService) DownloadFileRange(ctx context.Context, fileID, rangeHeader string) (*models.DownloadFileResponse, error) {
+	metadata, err := s.metadataRepo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+	if metadata == nil {
+		return nil, errors.New("file not found")
+	}
+
+	if metadata.UploadStatus == models.FileStatusDeleted.String() {
+		return nil, errors.New("file has been deleted")
+	}
+
+	start, end, ok, err := parseRange(rangeHeader, metadata.FileSize)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return s.DownloadFile(ctx, fileID)
+	}
+
+	fileReader, err := s.storageRepo.DownloadFileRange(ctx, objectBucket(metadata, s.bucketName), metadata.StoragePath, start, end-start+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file from storage: %w", err)
+	}
+
+	// Докачка и просмотр частями — одно обращение к файлу, а не по одному на каждый диапазон
+	if start == 0 {
+		if err := s.metadataRepo.UpdateAccessInfo(ctx, fileID); err != nil {
+			s.logger.Error().Err(err).Str("file_id", fileID).Msg("Failed to update access info")
+		}
+	}
+
+	fileContent, err := io.ReadAll(fileReader)
+	fileReader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file content: %w", err)
+	}
+
+	s.logger.Debug().
+		Str("file_id", fileID).
+		Int64("range_start", start).
+		Int64("range_end", end).
+		Int64("size", metadata.FileSize).
+		Msg("File range downloaded")
+
+	return &models.DownloadFileResponse{
+		Content:     fileContent,
+		FileName:    metadata.OriginalName,
+		ContentType: metadata.MimeType,
+		FileSize:    metadata.FileSize,
+		Partial:     true,
+		RangeStart:  start,
+		RangeEnd:    start + int64(len(fileContent)) - 1,
+	}, nil
+}
+
 func (s *downloadService) DownloadFileByHash(ctx context.Context, hash string, fileSize int64) (*models.DownloadFileResponse, error) {
 	files, err := s.metadataRepo.GetByHash(ctx, hash, fileSize)
 	if err != nil {
@@ -97,7 +169,7 @@ func (s *downloadService) DownloadFileByHash(ctx context.Context, hash string, f
 		return nil, errors.New("file has been deleted")
 	}
 
-	fileReader, actualFileSize, err := s.storageRepo.DownloadFile(ctx, s.bucketName, metadata.StoragePath)
+	fileReader, actualFileSize, err := s.storageRepo.DownloadFile(ctx, objectBucket(metadata, s.bucketName), metadata.StoragePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download file from storage: %w", err)
 	}
@@ -148,31 +220,46 @@ func (s *downloadService) GetFileInfo(ctx context.Context, fileID string) (*mode
 		FileSize:       metadata.FileSize,
 		MimeType:       metadata.MimeType,
 		Hash:           metadata.Hash,
+		ContentHash:    metadata.ContentHash,
 		UploadStatus:   metadata.UploadStatus,
 		UploadedAt:     metadata.UploadedAt,
 		AccessCount:    metadata.AccessCount,
+		ReferenceCount: metadata.ReferenceCount,
 		LastAccessedAt: metadata.LastAccessedAt,
 		StorageURL:     storageURL,
 		Metadata:       metadata.Metadata,
+		ExpiresAt:      metadata.ExpiresAt,
 	}, nil
 }
 
-func (s *downloadService) GetPresignedURL(ctx context.Context, fileID string, expiresIn int64) (string, error) {
+func (s *downloadService) GetPresignedURL(ctx context.Context, fileID string, expiresIn int64) (string, int64, error) {
+	if expiresIn <= 0 {
+		return "", 0, errors.New("invalid expiry")
+	}
+
+	maxExpiry := int64(s.config.MaxPresignedExpiry / time.Second)
+	if maxExpiry > 0 && expiresIn > maxExpiry {
+		if s.config.RejectOverMaxExpiry {
+			return "", 0, fmt.Errorf("expiry exceeds maximum of %d seconds", maxExpiry)
+		}
+		expiresIn = maxExpiry
+	}
+
 	metadata, err := s.metadataRepo.GetByID(ctx, fileID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get file metadata: %w", err)
+		return "", 0, fmt.Errorf("failed to get file metadata: %w", err)
 	}
 	if metadata == nil {
-		return "", errors.New("file not found")
+		return "", 0, errors.New("file not found")
 	}
 
 	if metadata.UploadStatus == models.FileStatusDeleted.String() {
-		return "", errors.New("file has been deleted")
+		return "", 0, errors.New("file has been deleted")
 	}
 
-	url, err := s.storageRepo.GetPresignedURL(ctx, s.bucketName, metadata.StoragePath, expiresIn)
+	url, err := s.storageRepo.GetPresignedURL(ctx, objectBucket(metadata, s.bucketName), metadata.StoragePath, expiresIn)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+		return "", 0, fmt.Errorf("failed to generate presigned URL: %w", err)
 	}
 
 	if err := s.metadataRepo.UpdateAccessInfo(ctx, fileID); err != nil {
@@ -185,5 +272,14 @@ func (s *downloadService) GetPresignedURL(ctx context.Context, fileID string, ex
 		Int64("expires_in", expiresIn).
 		Msg("Generated presigned URL")
 
-	return url, nil
+	return url, expiresIn, nil
+}
+
+// objectBucket — бакет объекта файла: записи, перенесённые migrate-storage, указывают на новый
+// бакет, ещё не перенесённые — на прежний; бакет из конфигурации — для записей без него
+func objectBucket(metadata *models.FileMetadata, fallback string) string {
+	if metadata.StorageBucket != "" {
+		return metadata.StorageBucket
+	}
+	return fallback
 }
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+)
+
+func TestDownloadFileRange(t *testing.T) {
+	content := make([]byte, 1000)
+	for i := range content {
+		content[i] = byte(i % 251)
+	}
+
+	storage := newMemStorage()
+	metadata := newMemMetadataRepo()
+	storage.objects["files/2024/01/01/data.bin"] = content
+	metadata.files["file-1"] = &models.FileMetadata{
+		ID:            "file-1",
+		FileSize:      int64(len(content)),
+		StorageBucket: "files",
+		StoragePath:   "2024/01/01/data.bin",
+		UploadStatus:  models.FileStatusUploaded.String(),
+	}
+	downloads := NewDownloadService(metadata, storage, zerolog.Nop(), "files", DownloadConfig{})
+
+	tests := []struct {
+		name       string
+		header     string
+		partial    bool
+		start, end int64
+	}{
+		{"middle", "bytes=300-599", true, 300, 599},
+		{"open end", "bytes=900-", true, 900, 999},
+		{"suffix", "bytes=-100", true, 900, 999},
+		{"end past size", "bytes=950-5000", true, 950, 999},
+		{"multiple ranges", "bytes=0-10,20-30", false, 0, 999},
+		{"not bytes", "items=0-10", false, 0, 999},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := downloads.DownloadFileRange(context.Background(), "file-1", tt.header)
+			if err != nil {
+				t.Fatalf("download: %v", err)
+			}
+			if file.Partial != tt.partial {
+				t.Fatalf("partial = %v, want %v", file.Partial, tt.partial)
+			}
+			if want := content[tt.start : tt.end+1]; !bytes.Equal(file.Content, want) {
+				t.Fatalf("got %d bytes, want bytes %d-%d of the file", len(file.Content), tt.start, tt.end)
+			}
+			if tt.partial && (file.RangeStart != tt.start || file.RangeEnd != tt.end || file.FileSize != int64(len(content))) {
+				t.Fatalf("range %d-%d/%d, want %d-%d/%d", file.RangeStart, file.RangeEnd, file.FileSize, tt.start, tt.end, len(content))
+			}
+		})
+	}
+
+	_, err := downloads.DownloadFileRange(context.Background(), "file-1", "bytes=1000-")
+	var notSatisfiable *RangeNotSatisfiableError
+	if !errors.As(err, &notSatisfiable) || notSatisfiable.Size != int64(len(content)) {
+		t.Fatalf("range past the end: err = %v, want RangeNotSatisfiableError with size", err)
+	}
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/repository"
+)
+
+// Заглушки хранилища и репозиториев в памяти: встроенный интерфейс остаётся nil, поэтому вызов
+// метода, который тест не ожидает, сразу падает с паникой. Условия выборок повторяют SQL репозиториев
+
+type memStorage struct {
+	repository.StorageRepository
+
+	mu      sync.Mutex
+	objects map[string][]byte // bucket/path → содержимое
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{objects: make(map[string][]byte)}
+}
+
+func (s *memStorage) Provider() string { return "memory" }
+
+func (s *memStorage) UploadFile(_ context.Context, bucket, fileName string, file io.Reader, _ int64, _ models.StorageObjectOptions) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[bucket+"/"+fileName] = data
+	return nil
+}
+
+func (s *memStorage) DownloadFile(_ context.Context, bucket, fileName string) (io.ReadCloser, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[bucket+"/"+fileName]
+	if !ok {
+		return nil, 0, errors.New("file not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+func (s *memStorage) DownloadFileRange(_ context.Context, bucket, fileName string, offset, length int64) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[bucket+"/"+fileName]
+	if !ok {
+		return nil, errors.New("file not found")
+	}
+	end := offset + length
+	if end > int64(len(data)) {
+		end = int64(len(data))
+	}
+	return io.NopCloser(bytes.NewReader(data[offset:end])), nil
+}
+
+func (s *memStorage) DeleteFile(_ context.Context, bucket, fileName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, bucket+"/"+fileName)
+	return nil
+}
+
+func (s *memStorage) FileExists(_ context.Context, bucket, fileName string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.objects[bucket+"/"+fileName]
+	return ok, nil
+}
+
+func (s *memStorage) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.objects)
+}
+
+type memMetadataRepo struct {
+	repository.FileMetadataRepository
+
+	mu    sync.Mutex
+	files map[string]*models.FileMetadata
+	now   func() time.Time
+}
+
+func newMemMetadataRepo() *memMetadataRepo {
+	return &memMetadataRepo{files: make(map[string]*models.FileMetadata), now: time.Now}
+}
+
+// live — файл не удалён и не просрочен, как в условиях выборок репозитория
+func (r *memMetadataRepo) live(f *models.FileMetadata) bool {
+	return f.UploadStatus != models.FileStatusDeleted.String() && (f.ExpiresAt == nil || f.ExpiresAt.After(r.now()))
+}
+
+func (r *memMetadataRepo) Create(_ context.Context, metadata *models.FileMetadata) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *metadata
+	r.files[metadata.ID] = &copied
+	return nil
+}
+
+func (r *memMetadataRepo) GetByID(_ context.Context, id string) (*models.FileMetadata, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.files[id]
+	if !ok || !r.live(f) {
+		return nil, nil
+	}
+	copied := *f
+	return &copied, nil
+}
+
+func (r *memMetadataRepo) GetByHash(_ context.Context, hash string, fileSize int64) ([]*models.FileMetadata, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []*models.FileMetadata
+	for _, f := range r.files {
+		if f.Hash == hash && f.FileSize == fileSize && r.live(f) {
+			copied := *f
+			found = append(found, &copied)
+		}
+	}
+	return found, nil
+}
+
+func (r *memMetadataRepo) Exists(_ context.Context, id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.files[id]
+	return ok && r.live(f), nil
+}
+
+func (r *memMetadataRepo) UpdateAccessInfo(context.Context, string) error { return nil }
+
+func (r *memMetadataRepo) AddReference(_ context.Context, id string, expiresAt *time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.files[id]
+	if !ok || f.UploadStatus == models.FileStatusDeleted.String() {
+		return false, nil
+	}
+	f.ReferenceCount++
+	if f.ExpiresAt == nil || expiresAt == nil {
+		f.ExpiresAt = nil
+	} else if expiresAt.After(*f.ExpiresAt) {
+		f.ExpiresAt = expiresAt
+	}
+	return true, nil
+}
+
+func (r *memMetadataRepo) ReleaseReference(_ context.Context, id string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.files[id]
+	if !ok || f.UploadStatus == models.FileStatusDeleted.String() {
+		return 0, nil
+	}
+	if f.ReferenceCount <= 1 {
+		f.UploadStatus = models.FileStatusDeleted.String()
+	}
+	if f.ReferenceCount > 0 {
+		f.ReferenceCount--
+	}
+	return f.ReferenceCount, nil
+}
+
+func (r *memMetadataRepo) SoftDelete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.files[id]; ok {
+		f.UploadStatus = models.FileStatusDeleted.String()
+	}
+	return nil
+}
+
+func (r *memMetadataRepo) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.files, id)
+	return nil
+}
+
+func (r *memMetadataRepo) GetDeletedByID(_ context.Context, id string) (*models.FileMetadata, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.files[id]
+	if !ok || f.UploadStatus != models.FileStatusDeleted.String() {
+		return nil, nil
+	}
+	copied := *f
+	return &copied, nil
+}
+
+func (r *memMetadataRepo) Restore(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.files[id]
+	if !ok || f.UploadStatus != models.FileStatusDeleted.String() {
+		return sql.ErrNoRows
+	}
+	f.UploadStatus = models.FileStatusUploaded.String()
+	if f.ReferenceCount < 1 {
+		f.ReferenceCount = 1
+	}
+	if f.ExpiresAt != nil && !f.ExpiresAt.After(r.now()) {
+		f.ExpiresAt = nil
+	}
+	return nil
+}
+
+func (r *memMetadataRepo) GetExpired(_ context.Context, before time.Time, deleted bool, limit int) ([]*models.FileMetadata, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []*models.FileMetadata
+	for _, f := range r.files {
+		if f.ExpiresAt == nil || f.ExpiresAt.After(before) {
+			continue
+		}
+		if (f.UploadStatus == models.FileStatusDeleted.String()) != deleted {
+			continue
+		}
+		copied := *f
+		found = append(found, &copied)
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ExpiresAt.Before(*found[j].ExpiresAt) })
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+// status — статус записи в обход фильтров выборок; "" — записи нет
+func (r *memMetadataRepo) status(id string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.files[id]; ok {
+		return f.UploadStatus
+	}
+	return ""
+}
+
+type memSessionRepo struct {
+	repository.UploadSessionRepository
+
+	mu       sync.Mutex
+	sessions map[string]*models.UploadSession
+	chunks   map[string]map[int]*models.UploadChunk
+}
+
+func newMemSessionRepo() *memSessionRepo {
+	return &memSessionRepo{
+		sessions: make(map[string]*models.UploadSession),
+		chunks:   make(map[string]map[int]*models.UploadChunk),
+	}
+}
+
+func (r *memSessionRepo) Create(_ context.Context, session *models.UploadSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *session
+	r.sessions[session.ID] = &copied
+	r.chunks[session.ID] = make(map[int]*models.UploadChunk)
+	return nil
+}
+
+func (r *memSessionRepo) GetByID(_ context.Context, id string) (*models.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r *memSessionRepo) SaveChunk(_ context.Context, chunk *models.UploadChunk) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *chunk
+	r.chunks[chunk.SessionID][chunk.ChunkNumber] = &copied
+	return nil
+}
+
+func (r *memSessionRepo) ListChunks(_ context.Context, sessionID string) ([]*models.UploadChunk, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var chunks []*models.UploadChunk
+	for _, chunk := range r.chunks[sessionID] {
+		copied := *chunk
+		chunks = append(chunks, &copied)
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].ChunkNumber < chunks[j].ChunkNumber })
+	return chunks, nil
+}
+
+func (r *memSessionRepo) MarkCompleted(_ context.Context, id, fileID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok || session.Status != models.UploadSessionActive.String() {
+		return false, nil
+	}
+	session.Status = models.UploadSessionCompleted.String()
+	session.FileID = &fileID
+	return true, nil
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/repository"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// StorageMigrationService переносит файлы между бакетами: копирует объекты (или проверяет, что они
+// уже скопированы), сверяет хеш и переводит запись на новый бакет
+type StorageMigrationService interface {
+	// Migrate продолжает незавершённый перенос той же пары бакетов или начинает новый
+	Migrate(ctx context.Context, opts StorageMigrationOptions) (*models.StorageMigration, error)
+}
+
+type StorageMigrationOptions struct {
+	SourceBucket string
+	TargetBucket string
+	// Копировать объекты на стороне хранилища; false — объекты уже перенесены (например, mc mirror),
+	// проверяется только их наличие в целевом бакете
+	CopyObjects bool
+	// Сверять хеш содержимого в целевом бакете с хешем в метаданных до переключения записи
+	VerifyHash bool
+	// Файлов между сохранениями прогресса
+	BatchSize int
+}
+
+type storageMigrationService struct {
+	metadataRepo  repository.FileMetadataRepository
+	migrationRepo repository.StorageMigrationRepository
+	storageRepo   repository.StorageRepository
+	hashService   HashService
+	logger        zerolog.Logger
+}
+
+func NewStorageMigrationService(
+	metadataRepo repository.FileMetadataRepository,
+	migrationRepo repository.StorageMigrationRepository,
+	storageRepo repository.StorageRepository,
+	hashService HashService,
+	logger zerolog.Logger,
+) StorageMigrationService {
+	return &storageMigrationService{
+		metadataRepo:  metadataRepo,
+		migrationRepo: migrationRepo,
+		storageRepo:   storageRepo,
+		hashService:   hashService,
+		logger:        logger,
+	}
+}
+
+func (s *storageMigrationService) Migrate(ctx context.Context, opts StorageMigrationOptions) (*models.StorageMigration, error) {
+	if opts.SourceBucket == "" || opts.TargetBucket == "" || opts.SourceBucket == opts.TargetBucket {
+		return nil, errors.New("source and target buckets must be set and differ")
+	}
+	if opts.BatchSize <= 0 {
+		return nil, errors.New("batch size must be positive")
+	}
+
+	exists, err := s.storageRepo.BucketExists(ctx, opts.TargetBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check target bucket: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("target bucket %q does not exist", opts.TargetBucket)
+	}
+
+	migration, err := s.migrationRepo.GetRunning(ctx, opts.SourceBucket, opts.TargetBucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage migration: %w", err)
+	}
+	if migration != nil {
+		s.logger.Info().
+			Str("migration_id", migration.ID).
+			Int("migrated", migration.Migrated).
+			Int("failed", migration.Failed).
+			Msg("Resuming storage migration")
+	} else {
+		migration = &models.StorageMigration{
+			ID:           uuid.New().String(),
+			SourceBucket: opts.SourceBucket,
+			TargetBucket: opts.TargetBucket,
+			CopyObjects:  opts.CopyObjects,
+			Status:       models.StorageMigrationRunning.String(),
+			StartedAt:    time.Now(),
+		}
+		if err := s.migrationRepo.Create(ctx, migration); err != nil {
+			return nil, fmt.Errorf("failed to create storage migration: %w", err)
+		}
+		s.logger.Info().
+			Str("migration_id", migration.ID).
+			Str("source_bucket", opts.SourceBucket).
+			Str("target_bucket", opts.TargetBucket).
+			Bool("copy_objects", opts.CopyObjects).
+			Msg("Storage migration started")
+	}
+
+	for {
+		files, err := s.metadataRepo.ListByBucket(ctx, opts.SourceBucket, migration.LastFileID, opts.BatchSize)
+		if err != nil {
+			return migration, fmt.Errorf("failed to list files: %w", err)
+		}
+		if len(files) == 0 {
+			break
+		}
+
+		for _, file := range files {
+			if err := ctx.Err(); err != nil {
+				return migration, s.saveProgress(migration, err)
+			}
+
+			if err := s.migrateFile(ctx, file, opts); err != nil {
+				s.logger.Error().Err(err).Str("file_id", file.ID).Msg("Failed to migrate file")
+				errMsg := fmt.Sprintf("%s: %v", file.ID, err)
+				migration.LastError = &errMsg
+				migration.Failed++
+			} else {
+				migration.Migrated++
+			}
+			fileID := file.ID
+			migration.LastFileID = &fileID
+		}
+
+		if err := s.migrationRepo.SaveProgress(ctx, migration, false); err != nil {
+			return migration, fmt.Errorf("failed to save migration progress: %w", err)
+		}
+
+		s.logger.Info().
+			Str("migration_id", migration.ID).
+			Int("migrated", migration.Migrated).
+			Int("failed", migration.Failed).
+			Msg("Storage migration progress")
+	}
+
+	if err := s.migrationRepo.SaveProgress(ctx, migration, true); err != nil {
+		return migration, fmt.Errorf("failed to complete storage migration: %w", err)
+	}
+	migration.Status = models.StorageMigrationCompleted.String()
+
+	s.logger.Info().
+		Str("migration_id", migration.ID).
+		Int("migrated", migration.Migrated).
+		Int("failed", migration.Failed).
+		Msg("Storage migration completed")
+
+	return migration, nil
+}
+
+// saveProgress сохраняет прогресс прерванного переноса вне отменённого контекста
+func (s *storageMigrationService) saveProgress(migration *models.StorageMigration, cause error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.migrationRepo.SaveProgress(ctx, migration, false); err != nil {
+		return fmt.Errorf("storage migration interrupted (%v), failed to save progress: %w", cause, err)
+	}
+	return fmt.Errorf("storage migration interrupted: %w", cause)
+}
+
+// migrateFile переключает запись только после того, как объект есть в целевом бакете
+// и его содержимое совпало с хешем; исходный объект не удаляется
+func (s *storageMigrationService) migrateFile(ctx context.Context, file *models.FileMetadata, opts StorageMigrationOptions) error {
+	if opts.CopyObjects {
+		if err := s.storageRepo.CopyFile(ctx, opts.SourceBucket, opts.TargetBucket, file.StoragePath); err != nil {
+			return fmt.Errorf("failed to copy object: %w", err)
+		}
+	} else {
+		exists, err := s.storageRepo.FileExists(ctx, opts.TargetBucket, file.StoragePath)
+		if err != nil {
+			return fmt.Errorf("failed to check object in target bucket: %w", err)
+		}
+		if !exists {
+			return errors.New("object not found in target bucket")
+		}
+	}
+
+	if opts.VerifyHash {
+		if err := s.verifyHash(ctx, opts.TargetBucket, file); err != nil {
+			return err
+		}
+	}
+
+	if err := s.metadataRepo.MoveToBucket(ctx, file.ID, opts.SourceBucket, opts.TargetBucket); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Запись удалили или перенесли, пока копировался объект
+			return errors.New("file metadata changed during migration")
+		}
+		return fmt.Errorf("failed to update file metadata: %w", err)
+	}
+	return nil
+}
+
+func (s *storageMigrationService) verifyHash(ctx context.Context, bucket string, file *models.FileMetadata) error {
+	reader, _, err := s.storageRepo.DownloadFile(ctx, bucket, file.StoragePath)
+	if err != nil {
+		return fmt.Errorf("failed to read copied object: %w", err)
+	}
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read copied object: %w", err)
+	}
+
+	match, err := s.hashService.VerifyHash(content, file.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to hash copied object: %w", err)
+	}
+	if !match {
+		return errors.New("hash mismatch after copy")
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedTextType — из файла такого типа текст не извлекается
+var ErrUnsupportedTextType = errors.New("unsupported type for text extraction")
+
+const (
+	mimeTypePDF  = "application/pdf"
+	mimeTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+
+	// Ограничение на распакованный размер потока/части документа
+	maxExtractedPartSize = 50 << 20
+)
+
+type TextExtractor interface {
+	ExtractText(mimeType string, content []byte) (string, error)
+}
+
+type textExtractor struct{}
+
+func NewTextExtractor() TextExtractor {
+	return &textExtractor{}
+}
+
+func (e *textExtractor) ExtractText(mimeType string, content []byte) (string, error) {
+	switch {
+	case mimeType == mimeTypePDF:
+		return extractPDFText(content)
+	case mimeType == mimeTypeDOCX:
+		return extractDOCXText(content)
+	case strings.HasPrefix(mimeType, "text/"):
+		if !utf8.Valid(content) {
+			return "", errors.New("text file is not valid UTF-8")
+		}
+		return string(content), nil
+	default:
+		return "", ErrUnsupportedTextType
+	}
+}
+
+// NormalizeText приводит текст к виду, не зависящему от формата файла:
+// нижний регистр и одиночные пробелы между словами.
+func NormalizeText(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// extractDOCXText собирает текст из word/document.xml: содержимое w:t, абзацы через перевод строки.
+func extractDOCXText(content []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return "", fmt.Errorf("failed to open docx: %w", err)
+	}
+
+	var document *zip.File
+	for _, f := range archive.File {
+		if f.Name == "word/document.xml" {
+			document = f
+			break
+		}
+	}
+	if document == nil {
+		return "", errors.New("docx has no word/document.xml")
+	}
+
+	rc, err := document.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open docx document: %w", err)
+	}
+	defer rc.Close()
+
+	var b strings.Builder
+	decoder := xml.NewDecoder(io.LimitReader(rc, maxExtractedPartSize))
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to parse docx document: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				b.WriteByte(' ')
+			case "br", "cr":
+				b.WriteByte('\n')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				b.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				b.Write(t)
+			}
+		}
+	}
+
+	return b.String(), nil
+}
+
+var pdfStreamRe = regexp.MustCompile(`>>\s*stream\r?\n`)
+
+// extractPDFText достаёт текст из потоков содержимого PDF (операторы Tj/TJ/'/").
+// Поддерживаются несжатые и FlateDecode-потоки с литеральными строками; шрифты
+// с собственной кодировкой (CID) дают нечитаемый текст — этого хватает для
+// сравнения документов, сохранённых из одного источника разными программами.
+func extractPDFText(content []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(content, " \t\r\n"), []byte("%PDF")) {
+		return "", errors.New("content is not a pdf")
+	}
+
+	var b strings.Builder
+	for _, loc := range pdfStreamRe.FindAllIndex(content, -1) {
+		// Словарь потока — от заголовка объекта до ключевого слова stream
+		dictStart := bytes.LastIndex(content[:loc[0]], []byte("obj"))
+		if dictStart < 0 {
+			dictStart = 0
+		}
+		dict := content[dictStart:loc[0]]
+		start := loc[1]
+		end := bytes.Index(content[start:], []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		data := content[start : start+end]
+
+		// Изображения, шрифты и прочие бинарные потоки текст не содержат
+		if bytes.Contains(dict, []byte("/Subtype")) || bytes.Contains(dict, []byte("/Length1")) {
+			continue
+		}
+		if bytes.Contains(dict, []byte("/Filter")) {
+			if !bytes.Contains(dict, []byte("/FlateDecode")) {
+				continue
+			}
+			inflated, err := inflate(data)
+			if err != nil {
+				continue
+			}
+			data = inflated
+		}
+
+		extractPDFContentText(data, &b)
+	}
+
+	text := b.String()
+	if strings.TrimSpace(text) == "" {
+		return "", errors.New("no text found in pdf")
+	}
+	return text, nil
+}
+
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(io.LimitReader(r, maxExtractedPartSize))
+}
+
+// extractPDFContentText разбирает поток содержимого: строки копятся до оператора,
+// выводятся для операторов показа текста и отбрасываются для остальных.
+func extractPDFContentText(data []byte, b *strings.Builder) {
+	var pending []string
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '(':
+			s, next := readPDFString(data, i)
+			pending = append(pending, s)
+			i = next
+		case c == '%':
+			for i < len(data) && data[i] != '\n' && data[i] != '\r' {
+				i++
+			}
+		case isPDFRegular(c) && c != '[' && c != ']' && c != '<' && c != '>':
+			j := i
+			for j < len(data) && isPDFRegular(data[j]) && data[j] != '(' && data[j] != '[' && data[j] != ']' && data[j] != '<' && data[j] != '>' {
+				j++
+			}
+			op := string(data[i:j])
+			switch op {
+			case "Tj", "TJ", "'", `"`:
+				if op != "Tj" && op != "TJ" {
+					b.WriteByte('\n')
+				}
+				b.WriteString(strings.Join(pending, ""))
+				pending = pending[:0]
+			case "Td", "TD", "T*", "ET":
+				b.WriteByte('\n')
+				pending = pending[:0]
+			default:
+				if v, err := strconv.ParseFloat(op, 64); err == nil {
+					// Большой отрицательный сдвиг внутри TJ обычно означает пробел между словами
+					if v <= -200 && len(pending) > 0 {
+						pending = append(pending, " ")
+					}
+				} else if op[0] != '/' {
+					pending = pending[:0]
+				}
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+}
+
+func isPDFRegular(c byte) bool {
+	return c != ' ' && c != '\t' && c != '\r' && c != '\n' && c != '\f' && c != 0
+}
+
+// readPDFString читает литеральную строку PDF начиная с '(' с учётом вложенных скобок и escape-последовательностей.
+func readPDFString(data []byte, start int) (string, int) {
+	var b strings.Builder
+	depth := 0
+	i := start
+	for i < len(data) {
+		c := data[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(data) {
+				return b.String(), i
+			}
+			switch e := data[i]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// перенос строки внутри строки игнорируется
+			default:
+				if e >= '0' && e <= '7' {
+					v := 0
+					k := 0
+					for k < 3 && i < len(data) && data[i] >= '0' && data[i] <= '7' {
+						v = v*8 + int(data[i]-'0')
+						i++
+						k++
+					}
+					b.WriteRune(rune(byte(v)))
+					continue
+				}
+				b.WriteByte(e)
+			}
+		case '(':
+			depth++
+			if depth > 1 {
+				b.WriteByte(c)
+			}
+		case ')':
+			depth--
+			if depth == 0 {
+				return b.String(), i + 1
+			}
+			b.WriteByte(c)
+		default:
+			if c < 0x80 {
+				b.WriteByte(c)
+			} else {
+				// Однобайтовая кодировка (WinAnsi/Latin-1) переводится в UTF-8
+				b.WriteRune(rune(c))
+			}
+		}
+		i++
+	}
+	return b.String(), i
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+// testDOCX собирает минимальный DOCX: абзацы из word/document.xml, слова разбиты на несколько w:t
+func testDOCX(t *testing.T, paragraphs ...string) []byte {
+	t.Helper()
+
+	var body bytes.Buffer
+	for _, p := range paragraphs {
+		fmt.Fprintf(&body, `<w:p><w:r><w:t>%s</w:t></w:r></w:p>`, p)
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, err := archive.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>`+
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>%s</w:body></w:document>`, body.String())
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testPDF собирает PDF с одним потоком содержимого, по строке Tj на абзац
+func testPDF(t *testing.T, compress bool, paragraphs ...string) []byte {
+	t.Helper()
+
+	var stream bytes.Buffer
+	stream.WriteString("BT /F1 12 Tf 72 720 Td\n")
+	for _, p := range paragraphs {
+		fmt.Fprintf(&stream, "(%s) Tj 0 -14 Td\n", p)
+	}
+	stream.WriteString("ET\n")
+
+	data := stream.Bytes()
+	filter := ""
+	if compress {
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(data)
+		zw.Close()
+		data = z.Bytes()
+		filter = " /Filter /FlateDecode"
+	}
+
+	var pdf bytes.Buffer
+	pdf.WriteString("%PDF-1.7\n1 0 obj << /Type /Catalog >> endobj\n")
+	fmt.Fprintf(&pdf, "4 0 obj << /Length %d%s >>\nstream\n", len(data), filter)
+	pdf.Write(data)
+	pdf.WriteString("\nendstream\nendobj\n%%EOF\n")
+	return pdf.Bytes()
+}
+
+func TestContentHashSameTextInDOCXAndPDF(t *testing.T) {
+	s := &uploadService{
+		hashService: NewHashService("sha256"),
+		extractor:   NewTextExtractor(),
+		logger:      zerolog.Nop(),
+	}
+	paragraphs := []string{"Plagiarism detection compares works.", "The same essay saved twice."}
+
+	docx := testDOCX(t, paragraphs...)
+	pdf := testPDF(t, false, paragraphs...)
+	flatePDF := testPDF(t, true, paragraphs...)
+
+	docxHash := s.calculateContentHash(mimeTypeDOCX, docx)
+	if docxHash == "" {
+		t.Fatal("no content hash for docx")
+	}
+	for name, content := range map[string][]byte{"pdf": pdf, "flate pdf": flatePDF} {
+		if got := s.calculateContentHash(mimeTypePDF, content); got != docxHash {
+			t.Errorf("%s content hash = %q, want the docx one %q", name, got, docxHash)
+		}
+	}
+
+	if other := s.calculateContentHash(mimeTypePDF, testPDF(t, false, "A different essay.")); other == docxHash {
+		t.Error("different text has the same content hash")
+	}
+	if got := s.calculateContentHash("image/png", []byte{0x89, 'P', 'N', 'G'}); got != "" {
+		t.Errorf("binary file content hash = %q, want empty", got)
+	}
+}
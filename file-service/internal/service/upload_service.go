@@ -1,7 +1,11 @@
 package service
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -13,6 +17,7 @@ import (
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/models"
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/repository"
 	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
 	"github.com/rs/zerolog"
 )
 
@@ -31,6 +36,7 @@ type uploadService struct {
 	metadataRepo repository.FileMetadataRepository
 	storageRepo  repository.StorageRepository
 	hashService  HashService
+	extractor    TextExtractor
 	logger       zerolog.Logger
 	config       UploadConfig
 }
@@ -41,12 +47,26 @@ type UploadConfig struct {
 	AllowedTypes   []string
 	GenerateHash   bool
 	CheckDuplicate bool
+	// Отклонять файлы, содержимое которых не соответствует расширению
+	VerifyContentType bool
+	// Сжатие объектов перед сохранением в хранилище
+	Compression CompressionConfig
+}
+
+type CompressionConfig struct {
+	Enabled bool
+	// Префиксы MIME-типов, которые имеет смысл сжимать
+	Types   []string
+	MinSize int64
+	// models.StorageCompressionGzip или models.StorageCompressionZstd
+	Algorithm string
 }
 
 func NewUploadService(
 	metadataRepo repository.FileMetadataRepository,
 	storageRepo repository.StorageRepository,
 	hashService HashService,
+	extractor TextExtractor,
 	logger zerolog.Logger,
 	config UploadConfig,
 ) UploadService {
@@ -54,6 +74,7 @@ func NewUploadService(
 		metadataRepo: metadataRepo,
 		storageRepo:  storageRepo,
 		hashService:  hashService,
+		extractor:    extractor,
 		logger:       logger,
 		config:       config,
 	}
@@ -89,6 +110,24 @@ func (s *uploadService) UploadFileBytes(ctx context.Context, fileName string, fi
 		return nil, fmt.Errorf("file type not allowed: %s", mimeType)
 	}
 
+	// Расширение можно подменить, поэтому тип сверяется с сигнатурой содержимого
+	detectedType := detectContentType(fileBytes)
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if s.config.VerifyContentType && !contentMatchesExtension(ext, detectedType) {
+		s.logger.Warn().
+			Str("file_name", fileName).
+			Str("declared_mime_type", mimeType).
+			Str("detected_mime_type", detectedType).
+			Msg("File content does not match its extension")
+		return nil, fmt.Errorf("file content does not match extension %s: detected %s", ext, detectedType)
+	}
+	metadata = withContentTypes(metadata, mimeType, detectedType)
+
+	expiresAt, err := expiresAtFromMetadata(metadata, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	fileHash, err := s.hashService.CalculateHash(fileBytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate file hash: %w", err)
@@ -105,20 +144,39 @@ func (s *uploadService) UploadFileBytes(ctx context.Context, fileName string, fi
 				Int("duplicates", len(duplicates)).
 				Msg("Duplicate file found")
 
-			return s.createDuplicateResponse(duplicates[0]), nil
+			// Новая загрузка ссылается на существующий файл; если его успели удалить, файл сохраняется заново
+			referenced, err := s.metadataRepo.AddReference(ctx, duplicates[0].ID, expiresAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to add file reference: %w", err)
+			}
+			if referenced {
+				if duplicates[0].ExpiresAt != nil && (expiresAt == nil || expiresAt.After(*duplicates[0].ExpiresAt)) {
+					duplicates[0].ExpiresAt = expiresAt
+				}
+				return s.createDuplicateResponse(duplicates[0]), nil
+			}
 		}
 	}
 
+	contentHash := s.calculateContentHash(mimeType, fileBytes)
+
 	uniqueFileName := s.generateUniqueFileName(fileName)
 
 	storagePath := s.generateStoragePath(uniqueFileName)
 
+	// Хеш и размер в метаданных считаются по исходному содержимому, сжимается только хранимый объект
+	storedBytes, objectOpts := s.compressForStorage(mimeType, fileBytes)
+	if objectOpts.Compression != "" {
+		metadata = withCompression(metadata, objectOpts)
+	}
+
 	if err := s.storageRepo.UploadFile(
 		ctx,
 		s.config.BucketName,
 		storagePath,
-		strings.NewReader(string(fileBytes)),
-		int64(len(fileBytes)),
+		bytes.NewReader(storedBytes),
+		int64(len(storedBytes)),
+		objectOpts,
 	); err != nil {
 		return nil, fmt.Errorf("failed to upload file to storage: %w", err)
 	}
@@ -132,13 +190,16 @@ func (s *uploadService) UploadFileBytes(ctx context.Context, fileName string, fi
 		FileSize:        int64(len(fileBytes)),
 		MimeType:        mimeType,
 		Hash:            fileHash,
-		StorageProvider: "minio",
+		ContentHash:     contentHash,
+		StorageProvider: s.storageRepo.Provider(),
 		StorageBucket:   s.config.BucketName,
 		StoragePath:     storagePath,
 		UploadStatus:    models.FileStatusUploaded.String(),
+		ReferenceCount:  1,
 		UploadedBy:      uploadedBy,
 		UploadedAt:      time.Now(),
 		Metadata:        metadata,
+		ExpiresAt:       expiresAt,
 	}
 
 	if err := s.metadataRepo.Create(ctx, fileMetadata); err != nil {
@@ -157,17 +218,38 @@ func (s *uploadService) UploadFileBytes(ctx context.Context, fileName string, fi
 		Msg("File uploaded successfully")
 
 	return &models.UploadFileResponse{
-		FileID:     fileID,
-		FileName:   uniqueFileName,
-		FileSize:   fileMetadata.FileSize,
-		Hash:       fileHash,
-		MimeType:   mimeType,
-		UploadedAt: fileMetadata.UploadedAt,
-		StorageURL: storageURL,
-		Metadata:   metadata,
+		FileID:      fileID,
+		FileName:    uniqueFileName,
+		FileSize:    fileMetadata.FileSize,
+		Hash:        fileHash,
+		ContentHash: contentHash,
+		MimeType:    mimeType,
+		UploadedAt:  fileMetadata.UploadedAt,
+		StorageURL:  storageURL,
+		Metadata:    metadata,
+		ExpiresAt:   expiresAt,
 	}, nil
 }
 
+// expiresAtFromMetadata читает срок хранения из поля expires_at метаданных (RFC 3339); nil — бессрочно
+func expiresAtFromMetadata(metadata []byte, now time.Time) (*time.Time, error) {
+	var fields struct {
+		ExpiresAt string `json:"expires_at"`
+	}
+	if err := json.Unmarshal(metadata, &fields); err != nil || fields.ExpiresAt == "" {
+		return nil, nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, fields.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expires_at: must be RFC 3339 time")
+	}
+	if !expiresAt.After(now) {
+		return nil, fmt.Errorf("invalid expires_at: must be in the future")
+	}
+	return &expiresAt, nil
+}
+
 func (s *uploadService) CheckDuplicate(ctx context.Context, fileHash string, fileSize int64) ([]*models.FileMetadata, error) {
 	return s.metadataRepo.GetByHash(ctx, fileHash, fileSize)
 }
@@ -176,15 +258,63 @@ func (s *uploadService) createDuplicateResponse(existingFile *models.FileMetadat
 	storageURL := s.generateStorageURL(existingFile.StoragePath)
 
 	return &models.UploadFileResponse{
-		FileID:     existingFile.ID,
-		FileName:   existingFile.FileName,
-		FileSize:   existingFile.FileSize,
-		Hash:       existingFile.Hash,
-		MimeType:   existingFile.MimeType,
-		UploadedAt: existingFile.UploadedAt,
-		StorageURL: storageURL,
-		Metadata:   existingFile.Metadata,
+		FileID:      existingFile.ID,
+		FileName:    existingFile.FileName,
+		FileSize:    existingFile.FileSize,
+		Hash:        existingFile.Hash,
+		ContentHash: existingFile.ContentHash,
+		MimeType:    existingFile.MimeType,
+		UploadedAt:  existingFile.UploadedAt,
+		StorageURL:  storageURL,
+		Metadata:    existingFile.Metadata,
+		ExpiresAt:   existingFile.ExpiresAt,
+	}
+}
+
+// calculateContentHash хеширует нормализованный текст документа, чтобы одинаковый текст
+// в разных PDF/DOCX давал одинаковый хеш. Для файлов без текста возвращает пустую строку.
+func (s *uploadService) calculateContentHash(mimeType string, fileBytes []byte) string {
+	if s.extractor == nil {
+		return ""
+	}
+
+	text, err := s.extractor.ExtractText(mimeType, fileBytes)
+	if err != nil {
+		if !errors.Is(err, ErrUnsupportedTextType) {
+			s.logger.Warn().Err(err).Str("mime_type", mimeType).Msg("Failed to extract text for content hash")
+		}
+		return ""
+	}
+
+	normalized := NormalizeText(text)
+	if normalized == "" {
+		return ""
+	}
+
+	contentHash, err := s.hashService.CalculateHashFromString(normalized)
+	if err != nil {
+		s.logger.Warn().Err(err).Msg("Failed to calculate content hash")
+		return ""
+	}
+	return contentHash
+}
+
+// withContentTypes добавляет в метаданные файла тип по расширению и тип по содержимому.
+// Метаданные, которые не являются JSON-объектом, остаются как есть.
+func withContentTypes(metadata []byte, declared, detected string) []byte {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(metadata, &fields); err != nil || fields == nil {
+		return metadata
+	}
+
+	fields["declared_mime_type"] = declared
+	fields["detected_mime_type"] = detected
+
+	updated, err := json.Marshal(fields)
+	if err != nil {
+		return metadata
 	}
+	return updated
 }
 
 func (s *uploadService) detectMimeType(fileName string, fileBytes []byte) string {
@@ -235,6 +365,112 @@ func (s *uploadService) detectMimeType(fileName string, fileBytes []byte) string
 	return "application/octet-stream"
 }
 
+// compressForStorage сжимает содержимое алгоритмом из конфигурации, если тип сжимаемый и это даёт выигрыш.
+// Архивы и изображения уже сжаты, их повторное сжатие только тратит CPU.
+func (s *uploadService) compressForStorage(mimeType string, fileBytes []byte) ([]byte, models.StorageObjectOptions) {
+	cfg := s.config.Compression
+	if !cfg.Enabled || int64(len(fileBytes)) < cfg.MinSize || isPrecompressedType(mimeType) {
+		return fileBytes, models.StorageObjectOptions{}
+	}
+
+	compressible := false
+	for _, prefix := range cfg.Types {
+		if strings.HasPrefix(mimeType, prefix) {
+			compressible = true
+			break
+		}
+	}
+	if !compressible {
+		return fileBytes, models.StorageObjectOptions{}
+	}
+
+	algorithm := cfg.Algorithm
+	if algorithm == "" {
+		algorithm = models.StorageCompressionGzip
+	}
+
+	compressed, err := compressBytes(algorithm, fileBytes)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("algorithm", algorithm).Msg("Failed to compress file, storing as is")
+		return fileBytes, models.StorageObjectOptions{}
+	}
+
+	if len(compressed) >= len(fileBytes) {
+		return fileBytes, models.StorageObjectOptions{}
+	}
+
+	s.logger.Debug().
+		Str("mime_type", mimeType).
+		Str("algorithm", algorithm).
+		Int("original_size", len(fileBytes)).
+		Int("stored_size", len(compressed)).
+		Msg("File compressed for storage")
+
+	return compressed, models.StorageObjectOptions{
+		Compression:  algorithm,
+		OriginalSize: int64(len(fileBytes)),
+	}
+}
+
+func compressBytes(algorithm string, data []byte) ([]byte, error) {
+	switch algorithm {
+	case models.StorageCompressionZstd:
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer encoder.Close()
+		return encoder.EncodeAll(data, make([]byte, 0, len(data)/2)), nil
+	case models.StorageCompressionGzip:
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(data); err != nil {
+			return nil, err
+		}
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported compression algorithm: %s", algorithm)
+}
+
+// withCompression отмечает в метаданных файла, что объект хранится сжатым, и его исходный размер.
+// Метаданные, которые не являются JSON-объектом, остаются как есть.
+func withCompression(metadata []byte, opts models.StorageObjectOptions) []byte {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(metadata, &fields); err != nil || fields == nil {
+		return metadata
+	}
+
+	fields["compressed"] = true
+	fields["compression"] = opts.Compression
+	fields["original_size"] = opts.OriginalSize
+
+	updated, err := json.Marshal(fields)
+	if err != nil {
+		return metadata
+	}
+	return updated
+}
+
+func isPrecompressedType(mimeType string) bool {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"),
+		strings.HasPrefix(mimeType, "audio/"),
+		strings.HasPrefix(mimeType, "video/"),
+		mimeType == "application/zip",
+		mimeType == "application/gzip",
+		mimeType == "application/x-gzip",
+		mimeType == "application/x-rar-compressed",
+		mimeType == "application/x-7z-compressed",
+		// docx/xlsx/pptx — это zip-архивы
+		strings.HasPrefix(mimeType, "application/vnd.openxmlformats-officedocument."):
+		return true
+	}
+	return false
+}
+
 func (s *uploadService) isAllowedType(mimeType, fileName string) bool {
 	if len(s.config.AllowedTypes) == 0 {
 		return true
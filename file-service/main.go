@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
@@ -11,7 +12,9 @@ import (
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/app"
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/config"
 	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/database"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/service"
 	"github.com/RubachokBoss/plagiarism-checker/file-service/pkg/logger"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/pkg/tracing"
 )
 
 func main() {
@@ -24,6 +27,9 @@ func main() {
 			migrateCmd.Parse(os.Args[2:])
 			runMigrations(*migrateDirection)
 			return
+		case "migrate-storage":
+			runStorageMigration(os.Args[2:])
+			return
 		}
 	}
 
@@ -34,18 +40,18 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to load configuration")
 	}
 
-	db, err := database.NewPostgres(cfg.Database)
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Enabled:     cfg.Tracing.Enabled,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to database")
+		log.Fatal().Err(err).Msg("Failed to initialize tracing")
 	}
-	defer db.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := db.PingContext(ctx); err != nil {
-		log.Fatal().Err(err).Msg("Failed to ping database")
-	}
+	db := runStartupChecks(cfg, log)
+	defer db.Close()
 
 	log.Info().Msg("Database connection established")
 
@@ -78,6 +84,10 @@ func main() {
 		log.Error().Err(err).Msg("Failed to shutdown gracefully")
 	}
 
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	log.Info().Msg("File Service stopped")
 }
 
@@ -105,3 +115,56 @@ func runMigrations(direction string) {
 		log.Fatal().Msg("Invalid migration direction. Use 'up' or 'down'")
 	}
 }
+
+func runStorageMigration(args []string) {
+	log := logger.New()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load configuration")
+	}
+
+	target := cfg.StorageMigration.TargetBucket
+	if target == "" {
+		target = cfg.Storage.BucketName
+	}
+
+	cmd := flag.NewFlagSet("migrate-storage", flag.ExitOnError)
+	source := cmd.String("source", cfg.StorageMigration.SourceBucket, "bucket to move files from")
+	targetBucket := cmd.String("target", target, "bucket to move files to")
+	copyObjects := cmd.Bool("copy", cfg.StorageMigration.CopyObjects, "copy objects server-side (false: objects are already in the target bucket)")
+	verifyHash := cmd.Bool("verify", cfg.StorageMigration.VerifyHash, "verify content hash in the target bucket before switching metadata")
+	batchSize := cmd.Int("batch", cfg.StorageMigration.BatchSize, "files between progress saves")
+	cmd.Parse(args)
+
+	db, err := database.NewPostgres(cfg.Database)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	migration, err := app.MigrateStorage(ctx, cfg, log, db, service.StorageMigrationOptions{
+		SourceBucket: *source,
+		TargetBucket: *targetBucket,
+		CopyObjects:  *copyObjects,
+		VerifyHash:   *verifyHash,
+		BatchSize:    *batchSize,
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Storage migration failed")
+		if migration != nil {
+			fmt.Printf("Migration %s stopped: %d file(s) moved, %d failed; run the command again to resume\n",
+				migration.ID, migration.Migrated, migration.Failed)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Printf("Migration %s completed: %d file(s) moved from %s to %s, %d failed\n",
+		migration.ID, migration.Migrated, migration.SourceBucket, migration.TargetBucket, migration.Failed)
+	if migration.Failed > 0 {
+		fmt.Println("Failed files stay in the source bucket; run the command again to retry them")
+		os.Exit(1)
+	}
+}
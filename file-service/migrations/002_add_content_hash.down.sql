@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_file_metadata_content_hash;
+
+ALTER TABLE file_metadata DROP COLUMN IF EXISTS content_hash;
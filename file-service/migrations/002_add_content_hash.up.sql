@@ -0,0 +1,4 @@
+-- Хеш нормализованного текста документа: совпадает у файлов с одинаковым текстом в разной обёртке
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS content_hash VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_file_metadata_content_hash ON file_metadata(content_hash);
@@ -0,0 +1,25 @@
+-- Сессии загрузки файла по частям
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    id UUID PRIMARY KEY,
+    file_name VARCHAR(255) NOT NULL,
+    total_chunks INTEGER NOT NULL CHECK (total_chunks > 0),
+    uploaded_by VARCHAR(255),
+    metadata JSONB DEFAULT '{}',
+    status VARCHAR(50) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'completed', 'expired')),
+    file_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_upload_sessions_expires_at ON upload_sessions(expires_at) WHERE status = 'active';
+
+-- Принятые части; повторная отправка части перезаписывает строку
+CREATE TABLE IF NOT EXISTS upload_session_chunks (
+    session_id UUID NOT NULL REFERENCES upload_sessions(id) ON DELETE CASCADE,
+    chunk_number INTEGER NOT NULL CHECK (chunk_number >= 0),
+    size BIGINT NOT NULL,
+    storage_path VARCHAR(500) NOT NULL,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (session_id, chunk_number)
+);
@@ -0,0 +1 @@
+ALTER TABLE file_metadata DROP COLUMN IF EXISTS reference_count;
@@ -0,0 +1,3 @@
+-- Число загрузок, которые ссылаются на файл: повторная загрузка того же содержимого
+-- возвращает существующий файл, и объект удаляется из хранилища только после последней ссылки
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS reference_count INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_file_metadata_expires_at;
+ALTER TABLE file_metadata DROP COLUMN IF EXISTS expires_at;
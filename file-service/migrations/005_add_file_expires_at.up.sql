@@ -0,0 +1,5 @@
+-- Срок хранения файла: после expires_at файл не отдаётся, а фоновая очистка помечает его удалённым
+-- и после периода ожидания удаляет запись и объект в хранилище
+ALTER TABLE file_metadata ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_file_metadata_expires_at ON file_metadata(expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_file_metadata_storage_bucket;
+DROP TABLE IF EXISTS storage_migrations;
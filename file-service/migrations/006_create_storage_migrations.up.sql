@@ -0,0 +1,23 @@
+-- Перенос файлов между бакетами: прогресс сохраняется после каждой пачки, прерванный перенос
+-- продолжается с last_file_id (файлы обходятся по возрастанию id)
+CREATE TABLE IF NOT EXISTS storage_migrations (
+    id UUID PRIMARY KEY,
+    source_bucket VARCHAR(255) NOT NULL,
+    target_bucket VARCHAR(255) NOT NULL,
+    copy_objects BOOLEAN NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'running'
+        CHECK (status IN ('running', 'completed')),
+    last_file_id UUID,
+    migrated INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Не больше одного незавершённого переноса на пару бакетов
+CREATE UNIQUE INDEX IF NOT EXISTS idx_storage_migrations_running
+    ON storage_migrations(source_bucket, target_bucket) WHERE status = 'running';
+
+CREATE INDEX IF NOT EXISTS idx_file_metadata_storage_bucket ON file_metadata(storage_bucket, id);
@@ -0,0 +1,97 @@
+package selfcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Check — проверка одной зависимости при старте сервиса.
+// Optional-проверка при ошибке только предупреждает и не останавливает запуск.
+type Check struct {
+	Name     string
+	Optional bool
+	Run      func(ctx context.Context) error
+}
+
+type result struct {
+	name     string
+	optional bool
+	err      error
+	duration time.Duration
+}
+
+// Run выполняет проверки параллельно, каждую не дольше timeout, и пишет сводку OK/FAIL.
+// Возвращает одну ошибку со списком всех упавших обязательных проверок.
+func Run(ctx context.Context, logger zerolog.Logger, timeout time.Duration, checks ...Check) error {
+	results := make([]result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, timeout, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	var failed []string
+	for _, res := range results {
+		switch {
+		case res.err == nil:
+			logger.Info().
+				Str("check", res.name).
+				Dur("duration", res.duration).
+				Msg("Startup check OK")
+		case res.optional:
+			logger.Warn().
+				Err(res.err).
+				Str("check", res.name).
+				Dur("duration", res.duration).
+				Msg("Startup check FAIL (optional, continuing)")
+		default:
+			logger.Error().
+				Err(res.err).
+				Str("check", res.name).
+				Dur("duration", res.duration).
+				Msg("Startup check FAIL")
+			failed = append(failed, fmt.Sprintf("%s: %v", res.name, res.err))
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d startup check(s) failed: %s", len(failed), strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func runCheck(ctx context.Context, timeout time.Duration, check Check) result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Run(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errors.New("timed out after " + timeout.String())
+	}
+
+	return result{
+		name:     check.Name,
+		optional: check.Optional,
+		err:      err,
+		duration: time.Since(start),
+	}
+}
@@ -0,0 +1,85 @@
+// Package tracing настраивает OpenTelemetry: экспорт трасс по OTLP/HTTP и middleware,
+// продолжающий трассу вызывающего сервиса из заголовков W3C traceparent/tracestate.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Config struct {
+	Enabled bool
+	// URL коллектора OTLP/HTTP, например http://otel-collector:4318
+	Endpoint    string
+	ServiceName string
+	// Доля трасс, начинаемых в этом сервисе; решение вызывающего сервиса соблюдается всегда
+	SampleRatio float64
+}
+
+// Init регистрирует глобальные провайдер трасс и пропагатор и возвращает функцию, которая
+// отправляет накопленные спаны и останавливает экспорт. Пропагатор ставится и при выключенном
+// экспорте, чтобы контекст трассировки от шлюза не терялся по пути к следующему сервису.
+func Init(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !cfg.Enabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Middleware открывает серверный спан на каждый запрос, продолжая трассу из заголовков.
+// Имя спана — метод и шаблон маршрута chi; запросы к skipPaths (проверки здоровья, метрики) не трассируются.
+func Middleware(skipPaths ...string) func(http.Handler) http.Handler {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		named := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+
+			// Шаблон маршрута известен только после того, как chi нашёл обработчик
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if pattern := rctx.RoutePattern(); pattern != "" {
+					span := trace.SpanFromContext(r.Context())
+					span.SetName(r.Method + " " + pattern)
+					span.SetAttributes(attribute.String("http.route", pattern))
+				}
+			}
+		})
+
+		return otelhttp.NewHandler(named, "http.request",
+			otelhttp.WithFilter(func(r *http.Request) bool { return !skip[r.URL.Path] }),
+			otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string { return r.Method }),
+		)
+	}
+}
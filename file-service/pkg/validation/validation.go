@@ -0,0 +1,213 @@
+// Package validation проверяет DTO по тегам validate и возвращает ошибки с путём к полю.
+// Поддерживаются правила required, uuid, email, min, max и oneof; путь строится из имён
+// json-тегов (author.email, items[2].id). Пустое необязательное поле остальными правилами не проверяется.
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// FieldError — нарушенное правило одного поля
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Errors — все нарушения в порядке полей структуры
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Struct проверяет v (структуру или указатель на неё); nil — ошибок нет
+func Struct(v interface{}) Errors {
+	var errs Errors
+	validateStruct(reflect.Indirect(reflect.ValueOf(v)), "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func validateStruct(v reflect.Value, prefix string, errs *Errors) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := fieldName(field)
+		if !ok {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		validateField(v.Field(i), path, field.Tag.Get("validate"), errs)
+	}
+}
+
+func validateField(v reflect.Value, path, tag string, errs *Errors) {
+	required := hasRule(tag, "required")
+
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if required {
+				*errs = append(*errs, FieldError{Field: path, Rule: "required", Message: path + " is required"})
+			}
+			return
+		}
+		v = v.Elem()
+	}
+
+	if v.IsZero() {
+		if required {
+			*errs = append(*errs, FieldError{Field: path, Rule: "required", Message: path + " is required"})
+			return
+		}
+		// Числа проверяются и при нуле: page=0 нарушает min=1
+		if !isNumber(v) {
+			return
+		}
+	}
+
+	for _, r := range orderedRules(tag) {
+		if fe, ok := checkRule(v, path, r.name, r.param); !ok {
+			*errs = append(*errs, fe)
+			// Одного нарушения на поле достаточно, чтобы подсветить его в форме
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		validateStruct(v, path, errs)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateStruct(reflect.Indirect(v.Index(i)), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+func checkRule(v reflect.Value, path, rule, param string) (FieldError, bool) {
+	fail := func(message string) (FieldError, bool) {
+		return FieldError{Field: path, Rule: rule, Message: path + " " + message}, false
+	}
+
+	switch rule {
+	case "uuid":
+		if _, err := uuid.Parse(v.String()); v.Kind() != reflect.String || err != nil {
+			return fail("must be a valid UUID")
+		}
+	case "email":
+		if _, err := mail.ParseAddress(v.String()); v.Kind() != reflect.String || err != nil {
+			return fail("must be a valid email address")
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			break
+		}
+		size, unit := measure(v)
+		if rule == "min" && size < limit {
+			return fail("must be at least " + param + unit)
+		}
+		if rule == "max" && size > limit {
+			return fail("must be at most " + param + unit)
+		}
+	case "oneof":
+		allowed := strings.Fields(param)
+		value := fmt.Sprint(v.Interface())
+		for _, a := range allowed {
+			if a == value {
+				return FieldError{}, true
+			}
+		}
+		return fail("must be one of: " + strings.Join(allowed, ", "))
+	}
+	return FieldError{}, true
+}
+
+// measure — длина строки в символах, число элементов коллекции или само число
+func measure(v reflect.Value) (float64, string) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), ""
+	}
+	return 0, ""
+}
+
+func isNumber(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func fieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
+
+type rule struct {
+	name  string
+	param string
+}
+
+func orderedRules(tag string) []rule {
+	var rules []rule
+	for _, part := range strings.Split(tag, ",") {
+		if part == "" || part == "required" {
+			continue
+		}
+		name, param, _ := strings.Cut(part, "=")
+		rules = append(rules, rule{name: name, param: param})
+	}
+	return rules
+}
+
+func hasRule(tag, name string) bool {
+	for _, part := range strings.Split(tag, ",") {
+		if part == name {
+			return true
+		}
+	}
+	return false
+}
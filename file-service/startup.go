@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/config"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/internal/database"
+	"github.com/RubachokBoss/plagiarism-checker/file-service/pkg/selfcheck"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/rs/zerolog"
+)
+
+// runStartupChecks проверяет конфигурацию и зависимости, выводит сводку OK/FAIL
+// и завершает процесс со списком всех проблем. Возвращает открытое подключение к БД.
+func runStartupChecks(cfg *config.Config, log zerolog.Logger) *sql.DB {
+	var db *sql.DB
+	checks := []selfcheck.Check{
+		{Name: "config", Run: func(context.Context) error { return cfg.Validate() }},
+		{Name: "database", Run: func(context.Context) error {
+			var err error
+			db, err = database.NewPostgres(cfg.Database)
+			return err
+		}},
+	}
+	if cfg.Startup.SelfCheck {
+		checks = append(checks, storageCheck(cfg))
+	}
+
+	if err := selfcheck.Run(context.Background(), log, cfg.Startup.CheckTimeout, checks...); err != nil {
+		log.Fatal().Err(err).Msg("Startup self-check failed")
+	}
+
+	return db
+}
+
+// storageCheck необязательная: MinIORepository сам дожидается хранилища и создаёт бакет,
+// а для S3 отсутствие бакета ещё раз залогирует репозиторий
+func storageCheck(cfg *config.Config) selfcheck.Check {
+	return selfcheck.Check{
+		Name:     cfg.Storage.Provider,
+		Optional: true,
+		Run: func(ctx context.Context) error {
+			client, err := storageClient(cfg)
+			if err != nil {
+				return err
+			}
+
+			exists, err := client.BucketExists(ctx, cfg.Storage.BucketName)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("bucket %s does not exist yet", cfg.Storage.BucketName)
+			}
+			return nil
+		},
+	}
+}
+
+func storageClient(cfg *config.Config) (*minio.Client, error) {
+	if cfg.Storage.Provider != "s3" {
+		return minio.New(cfg.MinIO.Endpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(cfg.MinIO.AccessKey, cfg.MinIO.SecretKey, ""),
+			Secure: cfg.MinIO.UseSSL,
+		})
+	}
+
+	creds := credentials.NewStaticV4(cfg.S3.AccessKey, cfg.S3.SecretKey, cfg.S3.SessionToken)
+	if cfg.S3.AccessKey == "" {
+		creds = credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.FileAWSCredentials{},
+			&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+		})
+	}
+	return minio.New(cfg.S3.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: cfg.S3.UseSSL,
+		Region: cfg.Storage.Region,
+	})
+}
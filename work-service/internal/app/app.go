@@ -12,6 +12,9 @@ import (
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/repository"
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/service"
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/service/integration"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/buildinfo"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/httpmetrics"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/tracing"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -24,12 +27,15 @@ type App struct {
 	config         *config.Config
 	db             *sql.DB
 	rabbitmqClient integration.RabbitMQClient
+	workService    service.WorkService
+	stopReaper     context.CancelFunc
 }
 
 func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 	fileClient := integration.NewFileClient(
 		cfg.Services.File.URL,
 		cfg.Services.File.UploadEndpoint,
+		cfg.Services.File.APIKey,
 		cfg.Services.File.Timeout,
 		cfg.Services.File.RetryCount,
 		cfg.Services.File.RetryDelay,
@@ -39,6 +45,7 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 	analysisClient := integration.NewAnalysisClient(
 		cfg.Services.Analysis.URL,
 		cfg.Services.Analysis.ReportsEndpoint,
+		cfg.Services.Analysis.APIKey,
 		cfg.Services.Analysis.Timeout,
 		cfg.Services.Analysis.RetryCount,
 		cfg.Services.Analysis.RetryDelay,
@@ -62,16 +69,31 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 	workRepo := repository.NewWorkRepository(db, log)
 	assignmentRepo := repository.NewAssignmentRepository(db, log)
 	studentRepo := repository.NewStudentRepository(db, log)
+	purgeRepo := repository.NewPurgeRepository(db, log)
+	outboxRepo := repository.NewOutboxRepository(db, log)
+	teacherRepo := repository.NewTeacherRepository(db, log)
+	courseRepo := repository.NewCourseRepository(db, log)
 
-	assignmentService := service.NewAssignmentService(assignmentRepo, log)
+	assignmentService := service.NewAssignmentService(assignmentRepo, courseRepo, log)
 	studentService := service.NewStudentService(studentRepo, log)
+	teacherService := service.NewTeacherService(teacherRepo, log)
+	courseService := service.NewCourseService(courseRepo, teacherRepo, assignmentRepo, log)
 	workService := service.NewWorkService(
 		workRepo,
 		studentRepo,
 		assignmentRepo,
+		outboxRepo,
 		fileClient,
+		analysisClient,
 		rabbitmqClient,
 		log,
+		service.WorkConfig{
+			ResubmissionPolicy: cfg.Works.DefaultResubmissionPolicy(),
+			IdempotencyKeyTTL:  cfg.Works.IdempotencyKeyTTL,
+			OutboxMaxAttempts:  cfg.Outbox.MaxAttempts,
+			OutboxBaseDelay:    cfg.Outbox.BaseDelay,
+			OutboxMaxDelay:     cfg.Outbox.MaxDelay,
+		},
 	)
 	reportService := service.NewReportService(
 		workRepo,
@@ -81,18 +103,46 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 		log,
 	)
 
+	purgeService := service.NewPurgeService(
+		purgeRepo,
+		studentRepo,
+		workRepo,
+		fileClient,
+		analysisClient,
+		log,
+		service.PurgeConfig{
+			Enabled:         cfg.Privacy.PurgeEnabled,
+			ConfirmationTTL: cfg.Privacy.PurgeConfirmationTTL,
+		},
+	)
+
 	handler := httpd.NewHandler(
 		workService,
 		assignmentService,
 		studentService,
+		teacherService,
+		courseService,
 		reportService,
+		purgeService,
 		log,
+		cfg.Server.DetailedValidationErrors,
 	)
 
 	router := chi.NewRouter()
 
 	router.Use(middleware.RequestID)
 	router.Use(middleware.RealIP)
+	router.Use(tracing.Middleware("/health", "/ready", "/version", cfg.Metrics.Path))
+
+	var metrics *httpmetrics.Registry
+	if cfg.Metrics.Enabled {
+		metrics = httpmetrics.New(httpmetrics.Config{
+			SizeBuckets:    cfg.Metrics.SizeBuckets,
+			LatencyBuckets: cfg.Metrics.LatencyBuckets,
+		})
+		router.Use(metrics.Middleware)
+	}
+
 	router.Use(middleware.Logger)
 	router.Use(middleware.Recoverer)
 	router.Use(middleware.Timeout(60 * time.Second))
@@ -106,7 +156,16 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 		MaxAge:           cfg.CORS.MaxAge,
 	}))
 
+	// Без ключа доступны только проверки здоровья, версия и метрики
+	router.Use(httpd.RequireAPIKey(cfg.Auth.APIKeys, "/health", "/ready", "/version", cfg.Metrics.Path))
+
 	handler.RegisterRoutes(router)
+	if cfg.Server.VersionEndpoint {
+		router.Get("/version", buildinfo.Handler("work-service"))
+	}
+	if metrics != nil {
+		router.Get(cfg.Metrics.Path, metrics.Handler)
+	}
 
 	server := &http.Server{
 		Addr:         cfg.Server.Address,
@@ -122,6 +181,7 @@ func New(cfg *config.Config, log zerolog.Logger, db *sql.DB) (*App, error) {
 		config:         cfg,
 		db:             db,
 		rabbitmqClient: rabbitmqClient,
+		workService:    workService,
 	}, nil
 }
 
@@ -155,6 +215,11 @@ func connectRabbitMQWithRetry(cfg *config.Config, log zerolog.Logger) (integrati
 }
 
 func (a *App) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopReaper = cancel
+	go a.reapAbandonedWorks(ctx)
+	go a.relayOutboxEvents(ctx)
+
 	a.logger.Info().Msgf("Starting work service on %s", a.config.Server.Address)
 	return a.server.ListenAndServe()
 }
@@ -162,6 +227,10 @@ func (a *App) Run() error {
 func (a *App) Shutdown(ctx context.Context) error {
 	a.logger.Info().Msg("Shutting down work service...")
 
+	if a.stopReaper != nil {
+		a.stopReaper()
+	}
+
 	if a.rabbitmqClient != nil {
 		if err := a.rabbitmqClient.Close(); err != nil {
 			a.logger.Error().Err(err).Msg("Failed to close RabbitMQ connection")
@@ -176,3 +245,67 @@ func (a *App) Shutdown(ctx context.Context) error {
 
 	return a.server.Shutdown(ctx)
 }
+
+// reapAbandonedWorks периодически удаляет работы, к которым не загрузили файл за works.pending_file_ttl,
+// и освобождает ключи идемпотентности старше works.idempotency_key_ttl
+func (a *App) reapAbandonedWorks(ctx context.Context) {
+	interval := a.config.Works.ReaperInterval
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := a.workService.DeleteAbandonedWorks(ctx, a.config.Works.PendingFileTTL)
+			if err != nil {
+				a.logger.Error().Err(err).Msg("Failed to delete abandoned works")
+				continue
+			}
+			if deleted > 0 {
+				a.logger.Info().Int("works", deleted).Msg("Abandoned works without file deleted")
+			}
+
+			released, err := a.workService.ReleaseExpiredIdempotencyKeys(ctx)
+			if err != nil {
+				a.logger.Error().Err(err).Msg("Failed to release expired idempotency keys")
+				continue
+			}
+			if released > 0 {
+				a.logger.Info().Int("keys", released).Msg("Expired idempotency keys released")
+			}
+		}
+	}
+}
+
+// relayOutboxEvents периодически публикует события outbox, которые не удалось отправить сразу
+func (a *App) relayOutboxEvents(ctx context.Context) {
+	interval := a.config.Outbox.RelayInterval
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			published, err := a.workService.PublishPendingEvents(ctx, a.config.Outbox.BatchSize)
+			if err != nil {
+				a.logger.Error().Err(err).Msg("Failed to publish outbox events")
+				continue
+			}
+			if published > 0 {
+				a.logger.Info().Int("events", published).Msg("Outbox events published")
+			}
+		}
+	}
+}
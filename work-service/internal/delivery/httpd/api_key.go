@@ -0,0 +1,55 @@
+package httpd
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// APIKeyHeader — заголовок с общим ключом, который другие сервисы и шлюз передают в запросах
+const APIKeyHeader = "X-API-Key"
+
+// RequireAPIKey отклоняет (401) запросы без одного из ключей keys. Несколько ключей нужны для
+// ротации: новый добавляется к старому, а старый убирается, когда все клиенты перешли.
+// Пустой список отключает проверку; пути из open (health-проверки, метрики) доступны без ключа.
+func RequireAPIKey(keys []string, open ...string) func(http.Handler) http.Handler {
+	var valid [][]byte
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			valid = append(valid, []byte(key))
+		}
+	}
+
+	openPaths := make(map[string]bool, len(open))
+	for _, path := range open {
+		openPaths[path] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(valid) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if openPaths[r.URL.Path] || validAPIKey(valid, r.Header.Get(APIKeyHeader)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			writeError(w, http.StatusUnauthorized, "Invalid or missing API key")
+		})
+	}
+}
+
+func validAPIKey(valid [][]byte, provided string) bool {
+	if provided == "" {
+		return false
+	}
+
+	// Все ключи сравниваются за постоянное время, чтобы по задержке нельзя было подобрать ключ
+	matched := 0
+	for _, key := range valid {
+		matched |= subtle.ConstantTimeCompare(key, []byte(provided))
+	}
+	return matched == 1
+}
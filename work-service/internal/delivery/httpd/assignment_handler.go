@@ -3,6 +3,7 @@ package httpd
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
 	"github.com/go-chi/chi/v5"
@@ -15,16 +16,14 @@ func (h *Handler) CreateAssignment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Title == "" {
-		writeError(w, http.StatusBadRequest, "title is required")
+	if !h.validateRequest(w, &req) {
 		return
 	}
 
 	ctx := r.Context()
 	assignment, err := h.assignmentService.CreateAssignment(ctx, &req)
 	if err != nil {
-		h.logger.Error().Err(err).Msg("Failed to create assignment")
-		writeError(w, http.StatusInternalServerError, "Failed to create assignment")
+		h.handleAssignmentError(w, err)
 		return
 	}
 
@@ -88,8 +87,7 @@ func (h *Handler) UpdateAssignment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Title == "" {
-		writeError(w, http.StatusBadRequest, "title is required")
+	if !h.validateRequest(w, &req) {
 		return
 	}
 
@@ -132,8 +130,19 @@ func (h *Handler) GetWorksByAssignment(w http.ResponseWriter, r *http.Request) {
 	page := getIntQueryParam(r, "page", 1)
 	limit := getIntQueryParam(r, "limit", 20)
 
+	// ?is_late=true — только опоздавшие работы, false — сданные в срок
+	var isLate *bool
+	if value := r.URL.Query().Get("is_late"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "is_late must be true or false")
+			return
+		}
+		isLate = &parsed
+	}
+
 	ctx := r.Context()
-	response, err := h.workService.GetWorksByAssignment(ctx, assignmentID, page, limit)
+	response, err := h.workService.GetWorksByAssignment(ctx, assignmentID, isLate, page, limit)
 	if err != nil {
 		h.handleServiceError(w, err)
 		return
@@ -142,6 +151,27 @@ func (h *Handler) GetWorksByAssignment(w http.ResponseWriter, r *http.Request) {
 	writeSuccess(w, response)
 }
 
+func (h *Handler) GetFileAssignments(w http.ResponseWriter, r *http.Request) {
+	fileID := chi.URLParam(r, "file_id")
+	if fileID == "" {
+		writeError(w, http.StatusBadRequest, "File ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	assignments, err := h.assignmentService.GetAssignmentsByFileID(ctx, fileID)
+	if err != nil {
+		h.handleAssignmentError(w, err)
+		return
+	}
+
+	writeSuccess(w, map[string]interface{}{
+		"file_id":     fileID,
+		"assignments": assignments,
+		"count":       len(assignments),
+	})
+}
+
 func (h *Handler) handleAssignmentError(w http.ResponseWriter, err error) {
 	errMsg := err.Error()
 
@@ -150,6 +180,8 @@ func (h *Handler) handleAssignmentError(w http.ResponseWriter, err error) {
 		writeError(w, http.StatusNotFound, errMsg)
 	case errMsg == "cannot delete assignment with existing works":
 		writeError(w, http.StatusConflict, errMsg)
+	case errMsg == "course does not exist":
+		writeError(w, http.StatusBadRequest, errMsg)
 	default:
 		h.logger.Error().Err(err).Msg("Assignment service error")
 		writeError(w, http.StatusInternalServerError, "Internal server error")
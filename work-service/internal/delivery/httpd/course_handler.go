@@ -0,0 +1,157 @@
+package httpd
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) CreateCourse(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateCourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	ctx := r.Context()
+	course, err := h.courseService.CreateCourse(ctx, &req)
+	if err != nil {
+		h.handleCourseError(w, err)
+		return
+	}
+
+	writeSuccess(w, course)
+}
+
+func (h *Handler) GetCourseByID(w http.ResponseWriter, r *http.Request) {
+	courseID := chi.URLParam(r, "id")
+	if courseID == "" {
+		writeError(w, http.StatusBadRequest, "Course ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	course, err := h.courseService.GetCourseByID(ctx, courseID)
+	if err != nil {
+		h.handleCourseError(w, err)
+		return
+	}
+
+	writeSuccess(w, course)
+}
+
+func (h *Handler) GetAllCourses(w http.ResponseWriter, r *http.Request) {
+	page := getIntQueryParam(r, "page", 1)
+	limit := getIntQueryParam(r, "limit", 20)
+
+	ctx := r.Context()
+	courses, total, err := h.courseService.GetAllCourses(ctx, page, limit)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to get courses")
+		writeError(w, http.StatusInternalServerError, "Failed to get courses")
+		return
+	}
+
+	response := map[string]interface{}{
+		"courses": courses,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	}
+
+	writeSuccess(w, response)
+}
+
+func (h *Handler) UpdateCourse(w http.ResponseWriter, r *http.Request) {
+	courseID := chi.URLParam(r, "id")
+	if courseID == "" {
+		writeError(w, http.StatusBadRequest, "Course ID is required")
+		return
+	}
+
+	var req models.CreateCourseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if !h.validateRequest(w, &req) {
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.courseService.UpdateCourse(ctx, courseID, &req); err != nil {
+		h.handleCourseError(w, err)
+		return
+	}
+
+	writeSuccess(w, map[string]interface{}{
+		"message": "Course updated successfully",
+	})
+}
+
+func (h *Handler) DeleteCourse(w http.ResponseWriter, r *http.Request) {
+	courseID := chi.URLParam(r, "id")
+	if courseID == "" {
+		writeError(w, http.StatusBadRequest, "Course ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	if err := h.courseService.DeleteCourse(ctx, courseID); err != nil {
+		h.handleCourseError(w, err)
+		return
+	}
+
+	writeSuccess(w, map[string]interface{}{
+		"message": "Course deleted successfully",
+	})
+}
+
+func (h *Handler) GetCourseAssignments(w http.ResponseWriter, r *http.Request) {
+	courseID := chi.URLParam(r, "id")
+	if courseID == "" {
+		writeError(w, http.StatusBadRequest, "Course ID is required")
+		return
+	}
+
+	page := getIntQueryParam(r, "page", 1)
+	limit := getIntQueryParam(r, "limit", 20)
+
+	ctx := r.Context()
+	assignments, total, err := h.courseService.GetCourseAssignments(ctx, courseID, page, limit)
+	if err != nil {
+		h.handleCourseError(w, err)
+		return
+	}
+
+	writeSuccess(w, map[string]interface{}{
+		"course_id":   courseID,
+		"assignments": assignments,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+	})
+}
+
+func (h *Handler) handleCourseError(w http.ResponseWriter, err error) {
+	errMsg := err.Error()
+
+	switch {
+	case errMsg == "course not found":
+		writeError(w, http.StatusNotFound, errMsg)
+	case errMsg == "teacher does not exist":
+		writeError(w, http.StatusBadRequest, errMsg)
+	case errMsg == "cannot delete course with existing assignments":
+		writeError(w, http.StatusConflict, errMsg)
+	default:
+		h.logger.Error().Err(err).Msg("Course service error")
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
@@ -0,0 +1,78 @@
+package httpd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+)
+
+func TestCourseHandlerStatuses(t *testing.T) {
+	const teacherID = "7f1c2a4e-0b9d-4c3e-9a51-2d6f8e4b1c07"
+
+	tests := []struct {
+		name   string
+		err    error
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"create", nil, http.MethodPost, "/api/v1/courses", `{"title":"Go basics","teacher_id":"` + teacherID + `"}`, http.StatusOK},
+		{"create without teacher", nil, http.MethodPost, "/api/v1/courses", `{"title":"Go basics"}`, http.StatusOK},
+		{"create with malformed teacher id", nil, http.MethodPost, "/api/v1/courses", `{"title":"Go basics","teacher_id":"bob"}`, http.StatusBadRequest},
+		{"create with unknown teacher", errors.New("teacher does not exist"), http.MethodPost, "/api/v1/courses", `{"title":"Go basics","teacher_id":"` + teacherID + `"}`, http.StatusBadRequest},
+		{"get missing course", errors.New("course not found"), http.MethodGet, "/api/v1/courses/course-1", "", http.StatusNotFound},
+		{"delete course with assignments", errors.New("cannot delete course with existing assignments"), http.MethodDelete, "/api/v1/courses/course-1", "", http.StatusConflict},
+		{"assignments of missing course", errors.New("course not found"), http.MethodGet, "/api/v1/courses/course-1/assignments", "", http.StatusNotFound},
+		// Внутренняя ошибка не раскрывается клиенту
+		{"repository failure", errors.New("failed to get course: connection refused"), http.MethodGet, "/api/v1/courses/course-1", "", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, &fakeCourseService{err: tt.err}, nil, tt.method, tt.path, tt.body)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status == http.StatusInternalServerError {
+				var body map[string]string
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["message"] != "Internal server error" {
+					t.Fatalf("body = %s, want a generic message", rec.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestCourseAssignmentsResponse(t *testing.T) {
+	courseID := "course-1"
+	courses := &fakeCourseService{assignments: []models.AssignmentWithStats{
+		{Assignment: models.Assignment{ID: "assignment-1", CourseID: &courseID}, TotalWorks: 2},
+	}}
+
+	rec := serve(t, courses, nil, http.MethodGet, "/api/v1/courses/course-1/assignments?page=1&limit=10", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+
+	var resp struct {
+		Data struct {
+			CourseID    string                       `json:"course_id"`
+			Assignments []models.AssignmentWithStats `json:"assignments"`
+			Total       int                          `json:"total"`
+			Limit       int                          `json:"limit"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Data.CourseID != "course-1" || resp.Data.Total != 1 || resp.Data.Limit != 10 {
+		t.Fatalf("response = %+v, want course-1 with 1 assignment and limit 10", resp.Data)
+	}
+	if got := resp.Data.Assignments; len(got) != 1 || got[0].CourseID == nil || *got[0].CourseID != "course-1" {
+		t.Fatalf("assignments = %+v, want assignment-1 of course-1", got)
+	}
+}
@@ -0,0 +1,87 @@
+package httpd
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/service"
+)
+
+// Заглушки сервисов: встроенный интерфейс остаётся nil, поэтому вызов метода, который тест
+// не ожидает, сразу падает с паникой. err, если задан, возвращается из любого метода заглушки
+
+type fakeCourseService struct {
+	service.CourseService
+
+	err         error
+	assignments []models.AssignmentWithStats
+}
+
+func (s *fakeCourseService) CreateCourse(_ context.Context, req *models.CreateCourseRequest) (*models.Course, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.Course{ID: "course-1", Title: req.Title, TeacherID: req.TeacherID}, nil
+}
+
+func (s *fakeCourseService) GetCourseByID(_ context.Context, id string) (*models.CourseWithStats, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.CourseWithStats{Course: models.Course{ID: id}}, nil
+}
+
+func (s *fakeCourseService) DeleteCourse(context.Context, string) error {
+	return s.err
+}
+
+func (s *fakeCourseService) GetCourseAssignments(context.Context, string, int, int) ([]models.AssignmentWithStats, int, error) {
+	if s.err != nil {
+		return nil, 0, s.err
+	}
+	return s.assignments, len(s.assignments), nil
+}
+
+type fakeTeacherService struct {
+	service.TeacherService
+
+	err error
+}
+
+func (s *fakeTeacherService) CreateTeacher(_ context.Context, req *models.CreateTeacherRequest) (*models.Teacher, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.Teacher{ID: "teacher-1", Name: req.Name, Email: req.Email}, nil
+}
+
+func (s *fakeTeacherService) GetTeacherByID(_ context.Context, id string) (*models.TeacherWithStats, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &models.TeacherWithStats{Teacher: models.Teacher{ID: id}}, nil
+}
+
+func (s *fakeTeacherService) DeleteTeacher(context.Context, string) error {
+	return s.err
+}
+
+// serve прогоняет запрос через маршруты обработчика с заданными сервисами курсов и преподавателей
+func serve(t *testing.T, courses service.CourseService, teachers service.TeacherService, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := chi.NewRouter()
+	NewHandler(nil, nil, nil, teachers, courses, nil, nil, zerolog.Nop(), false).RegisterRoutes(router)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/service"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/validation"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
@@ -16,28 +17,43 @@ type Handler struct {
 	workService       service.WorkService
 	assignmentService service.AssignmentService
 	studentService    service.StudentService
+	teacherService    service.TeacherService
+	courseService     service.CourseService
 	reportService     service.ReportService
+	purgeService      service.PurgeService
 	logger            zerolog.Logger
+	// Отдавать при ошибке валидации список {field, rule, message}, а не одно сообщение
+	detailedValidationErrors bool
 }
 
 func NewHandler(
 	workService service.WorkService,
 	assignmentService service.AssignmentService,
 	studentService service.StudentService,
+	teacherService service.TeacherService,
+	courseService service.CourseService,
 	reportService service.ReportService,
+	purgeService service.PurgeService,
 	logger zerolog.Logger,
+	detailedValidationErrors bool,
 ) *Handler {
 	return &Handler{
 		workService:       workService,
 		assignmentService: assignmentService,
 		studentService:    studentService,
+		teacherService:    teacherService,
+		courseService:     courseService,
 		reportService:     reportService,
+		purgeService:      purgeService,
 		logger:            logger,
+
+		detailedValidationErrors: detailedValidationErrors,
 	}
 }
 
 func (h *Handler) RegisterRoutes(router chi.Router) {
 	router.Get("/health", h.HealthCheck)
+	router.Get("/ready", h.ReadyCheck)
 
 	router.Route("/api/v1", func(api chi.Router) {
 		api.Route("/works", func(r chi.Router) {
@@ -46,6 +62,7 @@ func (h *Handler) RegisterRoutes(router chi.Router) {
 			r.Get("/{id}", h.GetWorkByID)
 			r.Delete("/{id}", h.DeleteWork)
 			r.Get("/{id}/reports", h.GetWorkReport)
+			r.Get("/{id}/percentile", h.GetWorkPercentile)
 			r.Put("/{id}/status", h.UpdateWorkStatus)
 		})
 
@@ -58,14 +75,38 @@ func (h *Handler) RegisterRoutes(router chi.Router) {
 			r.Get("/{id}/works", h.GetWorksByAssignment)
 		})
 
+		api.Get("/files/{file_id}/assignments", h.GetFileAssignments)
+
 		api.Route("/students", func(r chi.Router) {
 			r.Post("/", h.CreateStudent)
+			r.Post("/import", h.ImportStudents)
 			r.Get("/", h.GetAllStudents)
 			r.Get("/{id}", h.GetStudentByID)
 			r.Get("/email/{email}", h.GetStudentByEmail)
 			r.Put("/{id}", h.UpdateStudent)
 			r.Delete("/{id}", h.DeleteStudent)
 			r.Get("/{id}/works", h.GetWorksByStudent)
+			r.Delete("/{id}/data", h.RequestStudentDataPurge)
+			r.Get("/{id}/data/purges/{purge_id}", h.GetStudentDataPurge)
+			r.Post("/{id}/data/purges/{purge_id}/confirm", h.ConfirmStudentDataPurge)
+			r.Post("/{id}/data/purges/{purge_id}/cancel", h.CancelStudentDataPurge)
+		})
+
+		api.Route("/teachers", func(r chi.Router) {
+			r.Post("/", h.CreateTeacher)
+			r.Get("/", h.GetAllTeachers)
+			r.Get("/{id}", h.GetTeacherByID)
+			r.Put("/{id}", h.UpdateTeacher)
+			r.Delete("/{id}", h.DeleteTeacher)
+		})
+
+		api.Route("/courses", func(r chi.Router) {
+			r.Post("/", h.CreateCourse)
+			r.Get("/", h.GetAllCourses)
+			r.Get("/{id}", h.GetCourseByID)
+			r.Put("/{id}", h.UpdateCourse)
+			r.Delete("/{id}", h.DeleteCourse)
+			r.Get("/{id}/assignments", h.GetCourseAssignments)
 		})
 	})
 }
@@ -80,6 +121,15 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, response)
 }
 
+func (h *Handler) ReadyCheck(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"status":    "ready",
+		"timestamp": time.Now().UTC(),
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
 func getIntQueryParam(r *http.Request, key string, defaultValue int) int {
 	value := r.URL.Query().Get(key)
 	if value == "" {
@@ -110,6 +160,25 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	})
 }
 
+// validateRequest проверяет DTO по тегам validate; при нарушениях отвечает 400 и возвращает false
+func (h *Handler) validateRequest(w http.ResponseWriter, req interface{}) bool {
+	errs := validation.Struct(req)
+	if errs == nil {
+		return true
+	}
+
+	if !h.detailedValidationErrors {
+		writeError(w, http.StatusBadRequest, errs[0].Message)
+		return false
+	}
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"error":   http.StatusText(http.StatusBadRequest),
+		"message": errs[0].Message,
+		"errors":  errs,
+	})
+	return false
+}
+
 func writeSuccess(w http.ResponseWriter, data interface{}) {
 	response := map[string]interface{}{
 		"success": true,
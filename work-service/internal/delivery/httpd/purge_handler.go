@@ -0,0 +1,113 @@
+package httpd
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handler) RequestStudentDataPurge(w http.ResponseWriter, r *http.Request) {
+	studentID := chi.URLParam(r, "id")
+	if studentID == "" {
+		writeError(w, http.StatusBadRequest, "Student ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	response, err := h.purgeService.RequestPurge(ctx, studentID, r.Header.Get("X-User-ID"))
+	if err != nil {
+		h.handlePurgeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, map[string]interface{}{
+		"success": true,
+		"data":    response,
+		"message": "Student hidden until the purge is confirmed",
+	})
+}
+
+func (h *Handler) ConfirmStudentDataPurge(w http.ResponseWriter, r *http.Request) {
+	studentID := chi.URLParam(r, "id")
+	purgeID := chi.URLParam(r, "purge_id")
+
+	var req models.ConfirmPurgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.ConfirmationToken == "" {
+		writeError(w, http.StatusBadRequest, "confirmation_token is required")
+		return
+	}
+
+	ctx := r.Context()
+	purge, err := h.purgeService.ConfirmPurge(ctx, studentID, purgeID, req.ConfirmationToken)
+	if err != nil {
+		h.handlePurgeError(w, err)
+		return
+	}
+
+	if purge.Status == models.PurgeStatusPartial {
+		writeJSON(w, http.StatusBadGateway, map[string]interface{}{
+			"success": false,
+			"data":    purge,
+			"message": "Purge partially failed, confirm again to retry",
+		})
+		return
+	}
+
+	writeSuccess(w, purge)
+}
+
+func (h *Handler) CancelStudentDataPurge(w http.ResponseWriter, r *http.Request) {
+	studentID := chi.URLParam(r, "id")
+	purgeID := chi.URLParam(r, "purge_id")
+
+	ctx := r.Context()
+	purge, err := h.purgeService.CancelPurge(ctx, studentID, purgeID)
+	if err != nil {
+		h.handlePurgeError(w, err)
+		return
+	}
+
+	writeSuccess(w, purge)
+}
+
+func (h *Handler) GetStudentDataPurge(w http.ResponseWriter, r *http.Request) {
+	studentID := chi.URLParam(r, "id")
+	purgeID := chi.URLParam(r, "purge_id")
+
+	ctx := r.Context()
+	purge, err := h.purgeService.GetPurge(ctx, studentID, purgeID)
+	if err != nil {
+		h.handlePurgeError(w, err)
+		return
+	}
+
+	writeSuccess(w, purge)
+}
+
+func (h *Handler) handlePurgeError(w http.ResponseWriter, err error) {
+	errMsg := err.Error()
+
+	switch errMsg {
+	case "data purge is disabled":
+		writeError(w, http.StatusForbidden, errMsg)
+	case "student not found", "purge request not found":
+		writeError(w, http.StatusNotFound, errMsg)
+	case "invalid confirmation token":
+		writeError(w, http.StatusForbidden, errMsg)
+	case "purge already in progress", "purge request is no longer active",
+		"only unconfirmed purge requests can be cancelled":
+		writeError(w, http.StatusConflict, errMsg)
+	case "purge confirmation expired":
+		writeError(w, http.StatusGone, errMsg)
+	default:
+		h.logger.Error().Err(err).Msg("Purge service error")
+		writeError(w, http.StatusInternalServerError, "Internal server error")
+	}
+}
@@ -23,12 +23,31 @@ func (h *Handler) GetWorkReport(w http.ResponseWriter, r *http.Request) {
 	writeSuccess(w, report)
 }
 
+func (h *Handler) GetWorkPercentile(w http.ResponseWriter, r *http.Request) {
+	workID := chi.URLParam(r, "id")
+	if workID == "" {
+		writeError(w, http.StatusBadRequest, "Work ID is required")
+		return
+	}
+
+	ctx := r.Context()
+	percentile, err := h.reportService.GetWorkPercentile(ctx, workID)
+	if err != nil {
+		h.handleReportError(w, err)
+		return
+	}
+
+	writeSuccess(w, percentile)
+}
+
 func (h *Handler) handleReportError(w http.ResponseWriter, err error) {
 	errMsg := err.Error()
 
 	switch {
-	case errMsg == "work not found":
+	case errMsg == "work not found", errMsg == "analysis report not found":
 		writeError(w, http.StatusNotFound, errMsg)
+	case errMsg == "analysis is not completed":
+		writeError(w, http.StatusConflict, errMsg)
 	default:
 		h.logger.Error().Err(err).Msg("Report service error")
 		writeError(w, http.StatusInternalServerError, "Internal server error")
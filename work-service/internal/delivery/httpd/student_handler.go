@@ -2,12 +2,17 @@ package httpd
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
 	"github.com/go-chi/chi/v5"
 )
 
+// Размер CSV для импорта студентов
+const maxStudentImportSize = 10 << 20
+
 func (h *Handler) CreateStudent(w http.ResponseWriter, r *http.Request) {
 	var req models.CreateStudentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -15,24 +20,49 @@ func (h *Handler) CreateStudent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" {
-		writeError(w, http.StatusBadRequest, "name is required")
+	if !h.validateRequest(w, &req) {
 		return
 	}
 
-	if req.Email == "" {
-		writeError(w, http.StatusBadRequest, "email is required")
+	ctx := r.Context()
+	student, err := h.studentService.CreateStudent(ctx, &req)
+	if err != nil {
+		h.handleStudentError(w, err)
 		return
 	}
 
+	writeSuccess(w, student)
+}
+
+// ImportStudents принимает CSV с колонками name и email: файлом в поле file формы
+// multipart/form-data или телом запроса (Content-Type: text/csv)
+func (h *Handler) ImportStudents(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxStudentImportSize)
+
+	var csvData io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(maxStudentImportSize); err != nil {
+			writeError(w, http.StatusBadRequest, "Failed to parse form data")
+			return
+		}
+
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "File is required")
+			return
+		}
+		defer file.Close()
+		csvData = file
+	}
+
 	ctx := r.Context()
-	student, err := h.studentService.CreateStudent(ctx, &req)
+	result, err := h.studentService.ImportStudents(ctx, csvData)
 	if err != nil {
 		h.handleStudentError(w, err)
 		return
 	}
 
-	writeSuccess(w, student)
+	writeSuccess(w, result)
 }
 
 func (h *Handler) GetStudentByID(w http.ResponseWriter, r *http.Request) {
@@ -114,13 +144,7 @@ func (h *Handler) UpdateStudent(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" {
-		writeError(w, http.StatusBadRequest, "name is required")
-		return
-	}
-
-	if req.Email == "" {
-		writeError(w, http.StatusBadRequest, "email is required")
+	if !h.validateRequest(w, &req) {
 		return
 	}
 
@@ -185,6 +209,9 @@ func (h *Handler) handleStudentError(w http.ResponseWriter, err error) {
 		writeError(w, http.StatusConflict, errMsg)
 	case errMsg == "cannot delete student with existing works":
 		writeError(w, http.StatusConflict, errMsg)
+	case errMsg == "csv file is empty", errMsg == "csv must contain name and email columns",
+		strings.HasPrefix(errMsg, "invalid csv header"), strings.HasPrefix(errMsg, "failed to read csv"):
+		writeError(w, http.StatusBadRequest, errMsg)
 	default:
 		h.logger.Error().Err(err).Msg("Student service error")
 		writeError(w, http.StatusInternalServerError, "Internal server error")
@@ -0,0 +1,33 @@
+package httpd
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestTeacherHandlerStatuses(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"create", nil, http.MethodPost, "/api/v1/teachers", `{"name":"Ada Lovelace","email":"ada@example.com"}`, http.StatusOK},
+		{"create with invalid email", nil, http.MethodPost, "/api/v1/teachers", `{"name":"Ada Lovelace","email":"ada"}`, http.StatusBadRequest},
+		{"create with taken email", errors.New("teacher with this email already exists"), http.MethodPost, "/api/v1/teachers", `{"name":"Ada Lovelace","email":"ada@example.com"}`, http.StatusConflict},
+		{"get missing teacher", errors.New("teacher not found"), http.MethodGet, "/api/v1/teachers/teacher-1", "", http.StatusNotFound},
+		{"delete teacher with courses", errors.New("cannot delete teacher with existing courses"), http.MethodDelete, "/api/v1/teachers/teacher-1", "", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(t, nil, &fakeTeacherService{err: tt.err}, tt.method, tt.path, tt.body)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
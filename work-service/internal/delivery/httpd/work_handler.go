@@ -8,7 +8,6 @@ import (
 
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
 	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
 )
 
 func (h *Handler) CreateWork(w http.ResponseWriter, r *http.Request) {
@@ -23,18 +22,7 @@ func (h *Handler) CreateWork(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.StudentID == "" || req.AssignmentID == "" {
-		writeError(w, http.StatusBadRequest, "student_id and assignment_id are required")
-		return
-	}
-
-	if _, err := uuid.Parse(req.StudentID); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid student_id format")
-		return
-	}
-
-	if _, err := uuid.Parse(req.AssignmentID); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid assignment_id format")
+	if !h.validateRequest(w, &req) {
 		return
 	}
 
@@ -67,31 +55,22 @@ func (h *Handler) UploadWork(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	studentID := r.FormValue("student_id")
-	assignmentID := r.FormValue("assignment_id")
-
-	if studentID == "" || assignmentID == "" {
-		writeError(w, http.StatusBadRequest, "student_id and assignment_id are required")
-		return
+	req := &models.UploadWorkRequest{
+		StudentID:      r.FormValue("student_id"),
+		AssignmentID:   r.FormValue("assignment_id"),
+		FileContent:    fileContent,
+		FileName:       header.Filename,
+		IdempotencyKey: r.Header.Get("Idempotency-Key"),
 	}
-
-	if _, err := uuid.Parse(studentID); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid student_id format")
+	if !h.validateRequest(w, req) {
 		return
 	}
 
-	if _, err := uuid.Parse(assignmentID); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid assignment_id format")
+	if len(req.IdempotencyKey) > 255 {
+		writeError(w, http.StatusBadRequest, "Idempotency-Key must not exceed 255 characters")
 		return
 	}
 
-	req := &models.UploadWorkRequest{
-		StudentID:    studentID,
-		AssignmentID: assignmentID,
-		FileContent:  fileContent,
-		FileName:     header.Filename,
-	}
-
 	ctx := r.Context()
 	response, err := h.workService.UploadWork(ctx, req)
 	if err != nil {
@@ -168,8 +147,7 @@ func (h *Handler) UpdateWorkStatus(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
-	if req.Status == "" {
-		writeError(w, http.StatusBadRequest, "status is required")
+	if !h.validateRequest(w, &req) {
 		return
 	}
 
@@ -195,6 +173,8 @@ func (h *Handler) handleServiceError(w http.ResponseWriter, err error) {
 		writeError(w, http.StatusConflict, errMsg)
 	case errMsg == "work not found":
 		writeError(w, http.StatusNotFound, errMsg)
+	case errMsg == "idempotency key already used for another work":
+		writeError(w, http.StatusUnprocessableEntity, errMsg)
 	case errMsg == "invalid work status":
 		writeError(w, http.StatusBadRequest, errMsg)
 	default:
@@ -5,11 +5,25 @@ import (
 )
 
 type Assignment struct {
-	ID          string    `json:"id" db:"id"`
-	Title       string    `json:"title" db:"title"`
-	Description string    `json:"description" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID          string     `json:"id" db:"id"`
+	Title       string     `json:"title" db:"title"`
+	Description string     `json:"description" db:"description"`
+	DueAt       *time.Time `json:"due_at,omitempty" db:"due_at"`       // Срок сдачи; nil — работы не опаздывают
+	CourseID    *string    `json:"course_id,omitempty" db:"course_id"` // nil — задание вне курсов
+	// reject, replace или version; nil — works.resubmission_policy из конфигурации
+	ResubmissionPolicy *string   `json:"resubmission_policy,omitempty" db:"resubmission_policy"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// FileAssignment — задание, в котором используется файл, и работа, через которую он с ним связан
+type FileAssignment struct {
+	AssignmentID string    `json:"assignment_id" db:"assignment_id"`
+	Title        string    `json:"title" db:"title"`
+	WorkID       string    `json:"work_id" db:"work_id"`
+	StudentID    string    `json:"student_id" db:"student_id"`
+	WorkStatus   string    `json:"work_status" db:"work_status"`
+	LinkedAt     time.Time `json:"linked_at" db:"linked_at"`
 }
 
 type AssignmentWithStats struct {
@@ -18,3 +32,13 @@ type AssignmentWithStats struct {
 	AnalyzedWorks int `json:"analyzed_works" db:"analyzed_works"`
 	PendingWorks  int `json:"pending_works" db:"pending_works"`
 }
+
+// Политики повторной сдачи работы студентом по тому же заданию
+const (
+	// Повторная сдача отклоняется
+	ResubmissionReject = "reject"
+	// Новая попытка заменяет прежние: их файлы удаляются, отчёты помечаются заменёнными
+	ResubmissionReplace = "replace"
+	// Все попытки хранятся как версии
+	ResubmissionVersion = "version"
+)
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+type Course struct {
+	ID          string    `json:"id" db:"id"`
+	Title       string    `json:"title" db:"title"`
+	Description string    `json:"description" db:"description"`
+	TeacherID   *string   `json:"teacher_id,omitempty" db:"teacher_id"` // nil — преподаватель не назначен
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CourseWithStats struct {
+	Course
+	TotalAssignments int `json:"total_assignments" db:"total_assignments"`
+}
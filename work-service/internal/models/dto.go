@@ -8,17 +8,20 @@ type CreateWorkRequest struct {
 }
 
 type CreateWorkResponse struct {
-	ID        string    `json:"id"`
-	Status    string    `json:"status"`
-	FileID    string    `json:"file_id,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            string    `json:"id"`
+	Status        string    `json:"status"`
+	FileID        string    `json:"file_id,omitempty"`
+	AttemptNumber int       `json:"attempt_number"`
+	IsLate        bool      `json:"is_late"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 type UploadWorkRequest struct {
-	StudentID    string `json:"student_id" validate:"required,uuid"`
-	AssignmentID string `json:"assignment_id" validate:"required,uuid"`
-	FileContent  []byte `json:"-"` // Для внутреннего использования
-	FileName     string `json:"file_name"`
+	StudentID      string `json:"student_id" validate:"required,uuid"`
+	AssignmentID   string `json:"assignment_id" validate:"required,uuid"`
+	FileContent    []byte `json:"-"` // Для внутреннего использования
+	FileName       string `json:"file_name"`
+	IdempotencyKey string `json:"-"`
 }
 
 type UpdateWorkStatusRequest struct {
@@ -28,6 +31,23 @@ type UpdateWorkStatusRequest struct {
 type CreateAssignmentRequest struct {
 	Title       string `json:"title" validate:"required,min=3,max=255"`
 	Description string `json:"description" validate:"max=1000"`
+	// RFC 3339, например 2026-12-01T23:59:00+03:00; без поля срока нет
+	DueAt *time.Time `json:"due_at,omitempty"`
+	// Курс задания; должен существовать, без поля задание не относится к курсу
+	CourseID *string `json:"course_id,omitempty" validate:"uuid"`
+	// Политика повторной сдачи; без поля действует works.resubmission_policy
+	ResubmissionPolicy *string `json:"resubmission_policy,omitempty" validate:"oneof=reject replace version"`
+}
+
+type CreateTeacherRequest struct {
+	Name  string `json:"name" validate:"required,min=2,max=255"`
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+type CreateCourseRequest struct {
+	Title       string  `json:"title" validate:"required,min=3,max=255"`
+	Description string  `json:"description" validate:"max=1000"`
+	TeacherID   *string `json:"teacher_id,omitempty" validate:"uuid"`
 }
 
 type CreateStudentRequest struct {
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const EventTypeWorkCreated = "work.created"
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending   OutboxStatus = "pending"
+	OutboxStatusPublished OutboxStatus = "published"
+	OutboxStatusFailed    OutboxStatus = "failed"
+)
+
+func (s OutboxStatus) String() string {
+	return string(s)
+}
+
+// OutboxEvent — событие, сохранённое вместе с изменением работы и ожидающее публикации в RabbitMQ
+type OutboxEvent struct {
+	ID            string          `json:"id" db:"id"`
+	AggregateID   string          `json:"aggregate_id" db:"aggregate_id"`
+	EventType     string          `json:"event_type" db:"event_type"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	Status        string          `json:"status" db:"status"`
+	Attempts      int             `json:"attempts" db:"attempts"`
+	LastError     string          `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	PublishedAt   *time.Time      `json:"published_at,omitempty" db:"published_at"`
+	// Заголовки W3C traceparent/tracestate запроса, создавшего событие
+	TraceContext map[string]string `json:"trace_context,omitempty" db:"trace_context"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// StudentDataPurge — запрос на удаление всех данных студента и запись аудита о том, что удалено
+type StudentDataPurge struct {
+	ID                string     `json:"id" db:"id"`
+	StudentID         string     `json:"student_id" db:"student_id"`
+	Status            string     `json:"status" db:"status"`
+	ConfirmationToken string     `json:"-" db:"confirmation_token"`
+	RequestedBy       string     `json:"requested_by,omitempty" db:"requested_by"`
+	WorkIDs           []string   `json:"work_ids" db:"work_ids"`
+	DeletedFileIDs    []string   `json:"deleted_file_ids" db:"deleted_file_ids"`
+	FailedFileIDs     []string   `json:"failed_file_ids" db:"failed_file_ids"`
+	ReportsDeleted    int        `json:"reports_deleted" db:"reports_deleted"`
+	WorksDeleted      int        `json:"works_deleted" db:"works_deleted"`
+	Error             string     `json:"error,omitempty" db:"error"`
+	ExpiresAt         time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	ConfirmedAt       *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CompletedAt       *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+const (
+	PurgeStatusPendingConfirmation = "pending_confirmation"
+	PurgeStatusInProgress          = "in_progress"
+	PurgeStatusPartial             = "partial"
+	PurgeStatusCompleted           = "completed"
+	PurgeStatusCancelled           = "cancelled"
+	PurgeStatusExpired             = "expired"
+)
+
+type PurgeRequestResponse struct {
+	Purge             *StudentDataPurge `json:"purge"`
+	ConfirmationToken string            `json:"confirmation_token"`
+}
+
+type ConfirmPurgeRequest struct {
+	ConfirmationToken string `json:"confirmation_token"`
+}
@@ -18,3 +18,28 @@ type StudentWithStats struct {
 	AnalyzedWorks int `json:"analyzed_works" db:"analyzed_works"`
 	PendingWorks  int `json:"pending_works" db:"pending_works"`
 }
+
+// Итог строки импорта студентов из CSV
+const (
+	StudentImportCreated = "created"
+	StudentImportSkipped = "skipped"
+	StudentImportError   = "error"
+)
+
+// StudentImportRow — результат одной строки CSV; line — номер строки в файле, заголовок — строка 1
+type StudentImportRow struct {
+	Line      int    `json:"line"`
+	Name      string `json:"name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Status    string `json:"status"`
+	StudentID string `json:"student_id,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+type StudentImportResult struct {
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Skipped int                `json:"skipped"`
+	Errored int                `json:"errored"`
+	Rows    []StudentImportRow `json:"rows"`
+}
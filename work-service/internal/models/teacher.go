@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+type Teacher struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Email     string    `json:"email" db:"email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type TeacherWithStats struct {
+	Teacher
+	TotalCourses int `json:"total_courses" db:"total_courses"`
+}
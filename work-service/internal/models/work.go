@@ -5,13 +5,20 @@ import (
 )
 
 type Work struct {
-	ID           string    `json:"id" db:"id"`
-	StudentID    string    `json:"student_id" db:"student_id"`
-	AssignmentID string    `json:"assignment_id" db:"assignment_id"`
-	FileID       string    `json:"file_id" db:"file_id"`
-	Status       string    `json:"status" db:"status"` // uploaded, analyzing, analyzed, failed
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID             string     `json:"id" db:"id"`
+	StudentID      string     `json:"student_id" db:"student_id"`
+	AssignmentID   string     `json:"assignment_id" db:"assignment_id"`
+	FileID         string     `json:"file_id" db:"file_id"`
+	Status         string     `json:"status" db:"status"` // uploaded, analyzing, analyzed, failed
+	AttemptNumber  int        `json:"attempt_number" db:"attempt_number"`
+	SupersededAt   *time.Time `json:"superseded_at,omitempty" db:"superseded_at"`
+	ReplacedBy     *string    `json:"replaced_by,omitempty" db:"replaced_by"` // Попытка, заменившая эту по политике replace
+	IsLate         bool       `json:"is_late" db:"is_late"`                   // Файл загружен после due_at задания
+	IdempotencyKey string     `json:"-" db:"idempotency_key"`
+	// Когда сохранён IdempotencyKey; от этого момента отсчитывается срок жизни ключа
+	IdempotencyKeyAt *time.Time `json:"-" db:"idempotency_key_at"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type WorkWithDetails struct {
@@ -21,6 +28,9 @@ type WorkWithDetails struct {
 	AssignmentTitle string `json:"assignment_title" db:"assignment_title"`
 }
 
+// PendingFileID — file_id работы, созданной через CreateWork, пока к ней не загружен файл
+const PendingFileID = "pending"
+
 type WorkStatus string
 
 const (
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"github.com/rs/zerolog"
+	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
 )
@@ -12,9 +13,16 @@ type AssignmentRepository interface {
 	Create(ctx context.Context, assignment *models.Assignment) error
 	GetByID(ctx context.Context, id string) (*models.AssignmentWithStats, error)
 	GetAll(ctx context.Context, limit, offset int) ([]models.AssignmentWithStats, int, error)
+	// GetByCourseID — задания курса с той же статистикой работ, что и GetAll
+	GetByCourseID(ctx context.Context, courseID string, limit, offset int) ([]models.AssignmentWithStats, int, error)
 	Update(ctx context.Context, assignment *models.Assignment) error
 	Delete(ctx context.Context, id string) error
 	Exists(ctx context.Context, id string) (bool, error)
+	GetByFileID(ctx context.Context, fileID string) ([]models.FileAssignment, error)
+	// GetDueAt — срок сдачи задания; nil, если срока нет или задание не найдено
+	GetDueAt(ctx context.Context, id string) (*time.Time, error)
+	// GetResubmissionPolicy — политика повторной сдачи задания; "", если она не задана
+	GetResubmissionPolicy(ctx context.Context, id string) (string, error)
 }
 
 type assignmentRepository struct {
@@ -29,14 +37,17 @@ func NewAssignmentRepository(db *sql.DB, logger zerolog.Logger) AssignmentReposi
 
 func (r *assignmentRepository) Create(ctx context.Context, assignment *models.Assignment) error {
 	query := `
-		INSERT INTO assignments (id, title, description, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO assignments (id, title, description, due_at, course_id, resubmission_policy, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
 		assignment.ID,
 		assignment.Title,
 		assignment.Description,
+		assignment.DueAt,
+		assignment.CourseID,
+		assignment.ResubmissionPolicy,
 		assignment.CreatedAt,
 		assignment.UpdatedAt,
 	)
@@ -47,7 +58,7 @@ func (r *assignmentRepository) Create(ctx context.Context, assignment *models.As
 func (r *assignmentRepository) GetByID(ctx context.Context, id string) (*models.AssignmentWithStats, error) {
 	query := `
 		SELECT 
-			a.id, a.title, a.description, a.created_at, a.updated_at,
+			a.id, a.title, a.description, a.due_at, a.course_id, a.resubmission_policy, a.created_at, a.updated_at,
 			COUNT(w.id) as total_works,
 			COUNT(CASE WHEN w.status = 'analyzed' THEN 1 END) as analyzed_works,
 			COUNT(CASE WHEN w.status IN ('uploaded', 'analyzing') THEN 1 END) as pending_works
@@ -62,6 +73,9 @@ func (r *assignmentRepository) GetByID(ctx context.Context, id string) (*models.
 		&assignment.ID,
 		&assignment.Title,
 		&assignment.Description,
+		&assignment.DueAt,
+		&assignment.CourseID,
+		&assignment.ResubmissionPolicy,
 		&assignment.CreatedAt,
 		&assignment.UpdatedAt,
 		&assignment.TotalWorks,
@@ -86,7 +100,7 @@ func (r *assignmentRepository) GetAll(ctx context.Context, limit, offset int) ([
 
 	query := `
 		SELECT 
-			a.id, a.title, a.description, a.created_at, a.updated_at,
+			a.id, a.title, a.description, a.due_at, a.course_id, a.resubmission_policy, a.created_at, a.updated_at,
 			COUNT(w.id) as total_works,
 			COUNT(CASE WHEN w.status = 'analyzed' THEN 1 END) as analyzed_works,
 			COUNT(CASE WHEN w.status IN ('uploaded', 'analyzing') THEN 1 END) as pending_works
@@ -110,6 +124,9 @@ func (r *assignmentRepository) GetAll(ctx context.Context, limit, offset int) ([
 			&assignment.ID,
 			&assignment.Title,
 			&assignment.Description,
+			&assignment.DueAt,
+			&assignment.CourseID,
+			&assignment.ResubmissionPolicy,
 			&assignment.CreatedAt,
 			&assignment.UpdatedAt,
 			&assignment.TotalWorks,
@@ -125,16 +142,72 @@ func (r *assignmentRepository) GetAll(ctx context.Context, limit, offset int) ([
 	return assignments, total, nil
 }
 
+func (r *assignmentRepository) GetByCourseID(ctx context.Context, courseID string, limit, offset int) ([]models.AssignmentWithStats, int, error) {
+	countQuery := `SELECT COUNT(*) FROM assignments WHERE course_id = $1`
+	var total int
+	err := r.db.QueryRowContext(ctx, countQuery, courseID).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT 
+			a.id, a.title, a.description, a.due_at, a.course_id, a.resubmission_policy, a.created_at, a.updated_at,
+			COUNT(w.id) as total_works,
+			COUNT(CASE WHEN w.status = 'analyzed' THEN 1 END) as analyzed_works,
+			COUNT(CASE WHEN w.status IN ('uploaded', 'analyzing') THEN 1 END) as pending_works
+		FROM assignments a
+		LEFT JOIN works w ON a.id = w.assignment_id
+		WHERE a.course_id = $1
+		GROUP BY a.id
+		ORDER BY a.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, courseID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	assignments := []models.AssignmentWithStats{}
+	for rows.Next() {
+		var assignment models.AssignmentWithStats
+		err := rows.Scan(
+			&assignment.ID,
+			&assignment.Title,
+			&assignment.Description,
+			&assignment.DueAt,
+			&assignment.CourseID,
+			&assignment.ResubmissionPolicy,
+			&assignment.CreatedAt,
+			&assignment.UpdatedAt,
+			&assignment.TotalWorks,
+			&assignment.AnalyzedWorks,
+			&assignment.PendingWorks,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, total, rows.Err()
+}
+
 func (r *assignmentRepository) Update(ctx context.Context, assignment *models.Assignment) error {
 	query := `
 		UPDATE assignments
-		SET title = $1, description = $2, updated_at = $3
-		WHERE id = $4
+		SET title = $1, description = $2, due_at = $3, course_id = $4, resubmission_policy = $5, updated_at = $6
+		WHERE id = $7
 	`
 
 	_, err := r.db.ExecContext(ctx, query,
 		assignment.Title,
 		assignment.Description,
+		assignment.DueAt,
+		assignment.CourseID,
+		assignment.ResubmissionPolicy,
 		assignment.UpdatedAt,
 		assignment.ID,
 	)
@@ -148,9 +221,64 @@ func (r *assignmentRepository) Delete(ctx context.Context, id string) error {
 	return err
 }
 
+// GetByFileID возвращает задания, в работах которых используется файл
+func (r *assignmentRepository) GetByFileID(ctx context.Context, fileID string) ([]models.FileAssignment, error) {
+	query := `
+		SELECT a.id, a.title, w.id, w.student_id, w.status, w.created_at
+		FROM works w
+		JOIN assignments a ON a.id = w.assignment_id
+		WHERE w.file_id = $1
+		ORDER BY w.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []models.FileAssignment{}
+	for rows.Next() {
+		var assignment models.FileAssignment
+		if err := rows.Scan(
+			&assignment.AssignmentID,
+			&assignment.Title,
+			&assignment.WorkID,
+			&assignment.StudentID,
+			&assignment.WorkStatus,
+			&assignment.LinkedAt,
+		); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, assignment)
+	}
+
+	return assignments, rows.Err()
+}
+
 func (r *assignmentRepository) Exists(ctx context.Context, id string) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM assignments WHERE id = $1)`
 	var exists bool
 	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
 	return exists, err
 }
+
+func (r *assignmentRepository) GetDueAt(ctx context.Context, id string) (*time.Time, error) {
+	query := `SELECT due_at FROM assignments WHERE id = $1`
+	var dueAt *time.Time
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&dueAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return dueAt, err
+}
+
+func (r *assignmentRepository) GetResubmissionPolicy(ctx context.Context, id string) (string, error) {
+	query := `SELECT COALESCE(resubmission_policy, '') FROM assignments WHERE id = $1`
+	var policy string
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&policy)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return policy, err
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+)
+
+type CourseRepository interface {
+	Create(ctx context.Context, course *models.Course) error
+	GetByID(ctx context.Context, id string) (*models.CourseWithStats, error)
+	GetAll(ctx context.Context, limit, offset int) ([]models.CourseWithStats, int, error)
+	Update(ctx context.Context, course *models.Course) error
+	Delete(ctx context.Context, id string) error
+	Exists(ctx context.Context, id string) (bool, error)
+}
+
+type courseRepository struct {
+	*PostgresRepository
+}
+
+func NewCourseRepository(db *sql.DB, logger zerolog.Logger) CourseRepository {
+	return &courseRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+func (r *courseRepository) Create(ctx context.Context, course *models.Course) error {
+	query := `
+		INSERT INTO courses (id, title, description, teacher_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		course.ID,
+		course.Title,
+		course.Description,
+		course.TeacherID,
+		course.CreatedAt,
+		course.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *courseRepository) GetByID(ctx context.Context, id string) (*models.CourseWithStats, error) {
+	query := `
+		SELECT 
+			c.id, c.title, COALESCE(c.description, ''), c.teacher_id, c.created_at, c.updated_at,
+			COUNT(a.id) as total_assignments
+		FROM courses c
+		LEFT JOIN assignments a ON c.id = a.course_id
+		WHERE c.id = $1
+		GROUP BY c.id
+	`
+
+	course := &models.CourseWithStats{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&course.ID,
+		&course.Title,
+		&course.Description,
+		&course.TeacherID,
+		&course.CreatedAt,
+		&course.UpdatedAt,
+		&course.TotalAssignments,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return course, err
+}
+
+func (r *courseRepository) GetAll(ctx context.Context, limit, offset int) ([]models.CourseWithStats, int, error) {
+	countQuery := `SELECT COUNT(*) FROM courses`
+	var total int
+	err := r.db.QueryRowContext(ctx, countQuery).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT 
+			c.id, c.title, COALESCE(c.description, ''), c.teacher_id, c.created_at, c.updated_at,
+			COUNT(a.id) as total_assignments
+		FROM courses c
+		LEFT JOIN assignments a ON c.id = a.course_id
+		GROUP BY c.id
+		ORDER BY c.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var courses []models.CourseWithStats
+	for rows.Next() {
+		var course models.CourseWithStats
+		err := rows.Scan(
+			&course.ID,
+			&course.Title,
+			&course.Description,
+			&course.TeacherID,
+			&course.CreatedAt,
+			&course.UpdatedAt,
+			&course.TotalAssignments,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		courses = append(courses, course)
+	}
+
+	return courses, total, rows.Err()
+}
+
+func (r *courseRepository) Update(ctx context.Context, course *models.Course) error {
+	query := `
+		UPDATE courses
+		SET title = $1, description = $2, teacher_id = $3, updated_at = $4
+		WHERE id = $5
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		course.Title,
+		course.Description,
+		course.TeacherID,
+		course.UpdatedAt,
+		course.ID,
+	)
+
+	return err
+}
+
+func (r *courseRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM courses WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *courseRepository) Exists(ctx context.Context, id string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM courses WHERE id = $1)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
+	return exists, err
+}
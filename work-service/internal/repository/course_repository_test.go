@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// scriptedDB — драйвер database/sql, который запоминает запросы с аргументами и отвечает
+// строками из respond; пустой ответ — запрос ничего не нашёл
+type scriptedDB struct {
+	mu      sync.Mutex
+	queries []scriptedQuery
+	respond func(query string) [][]driver.Value
+}
+
+type scriptedQuery struct {
+	query string
+	args  []driver.Value
+}
+
+func (d *scriptedDB) Connect(context.Context) (driver.Conn, error) {
+	return &scriptedConn{db: d}, nil
+}
+func (d *scriptedDB) Driver() driver.Driver { return nil }
+
+type scriptedConn struct{ db *scriptedDB }
+
+func (c *scriptedConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepare is not supported")
+}
+func (c *scriptedConn) Close() error { return nil }
+func (c *scriptedConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *scriptedConn) QueryContext(_ context.Context, query string, named []driver.NamedValue) (driver.Rows, error) {
+	args := make([]driver.Value, len(named))
+	for i, arg := range named {
+		args[i] = arg.Value
+	}
+
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.queries = append(c.db.queries, scriptedQuery{query: query, args: args})
+	var rows [][]driver.Value
+	if c.db.respond != nil {
+		rows = c.db.respond(query)
+	}
+	return &scriptedRows{rows: rows}, nil
+}
+
+type scriptedRows struct{ rows [][]driver.Value }
+
+func (r *scriptedRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+func (r *scriptedRows) Close() error { return nil }
+
+func (r *scriptedRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestGetByCourseIDScopesAssignmentsToCourse(t *testing.T) {
+	created := time.Date(2024, 9, 1, 10, 0, 0, 0, time.UTC)
+	due := created.Add(14 * 24 * time.Hour)
+	fake := &scriptedDB{respond: func(query string) [][]driver.Value {
+		if strings.Contains(query, "COUNT(*)") {
+			return [][]driver.Value{{int64(3)}}
+		}
+		return [][]driver.Value{
+			{"assignment-2", "Essay", "", due, "course-1", "version", created, created, int64(4), int64(3), int64(1)},
+			{"assignment-1", "Quiz", "", nil, "course-1", nil, created, created, int64(0), int64(0), int64(0)},
+		}
+	}}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+	repo := NewAssignmentRepository(db, zerolog.Nop())
+
+	assignments, total, err := repo.GetByCourseID(context.Background(), "course-1", 2, 2)
+	if err != nil {
+		t.Fatalf("get by course: %v", err)
+	}
+
+	if total != 3 || len(assignments) != 2 {
+		t.Fatalf("got %d assignments of %d, want 2 of 3", len(assignments), total)
+	}
+	first := assignments[0]
+	if first.CourseID == nil || *first.CourseID != "course-1" || first.DueAt == nil || !first.DueAt.Equal(due) {
+		t.Fatalf("first assignment = %+v, want course-1 with a deadline", first)
+	}
+	if first.TotalWorks != 4 || first.AnalyzedWorks != 3 || first.PendingWorks != 1 {
+		t.Fatalf("first assignment stats = %d/%d/%d, want 4/3/1", first.TotalWorks, first.AnalyzedWorks, first.PendingWorks)
+	}
+	if assignments[1].DueAt != nil || assignments[1].ResubmissionPolicy != nil {
+		t.Fatalf("second assignment = %+v, want no deadline and no policy", assignments[1])
+	}
+
+	// И итог, и страница считаются только по заданиям курса
+	count, page := fake.queries[0], fake.queries[1]
+	if !strings.Contains(count.query, "WHERE course_id = $1") || !reflect.DeepEqual(count.args, []driver.Value{"course-1"}) {
+		t.Fatalf("COUNT = %s %v, want it scoped to course-1", count.query, count.args)
+	}
+	if !strings.Contains(page.query, "WHERE a.course_id = $1") || !reflect.DeepEqual(page.args, []driver.Value{"course-1", int64(2), int64(2)}) {
+		t.Fatalf("SELECT = %s %v, want it scoped to course-1 with limit and offset", page.query, page.args)
+	}
+}
+
+func TestGetByCourseIDEmptyCourse(t *testing.T) {
+	fake := &scriptedDB{respond: func(query string) [][]driver.Value {
+		if strings.Contains(query, "COUNT(*)") {
+			return [][]driver.Value{{int64(0)}}
+		}
+		return nil
+	}}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	assignments, total, err := NewAssignmentRepository(db, zerolog.Nop()).GetByCourseID(context.Background(), "course-1", 20, 0)
+	if err != nil {
+		t.Fatalf("get by course: %v", err)
+	}
+	// Пустой курс отдаёт в JSON [], а не null
+	if assignments == nil || len(assignments) != 0 || total != 0 {
+		t.Fatalf("got %v (total %d), want an empty non-nil slice", assignments, total)
+	}
+}
+
+func TestCourseAndTeacherNotFound(t *testing.T) {
+	db := sql.OpenDB(&scriptedDB{})
+	defer db.Close()
+	ctx := context.Background()
+
+	if course, err := NewCourseRepository(db, zerolog.Nop()).GetByID(ctx, "missing"); course != nil || err != nil {
+		t.Fatalf("course GetByID = %v, %v; want nil, nil", course, err)
+	}
+	teachers := NewTeacherRepository(db, zerolog.Nop())
+	if teacher, err := teachers.GetByID(ctx, "missing"); teacher != nil || err != nil {
+		t.Fatalf("teacher GetByID = %v, %v; want nil, nil", teacher, err)
+	}
+	if teacher, err := teachers.GetByEmail(ctx, "nobody@example.com"); teacher != nil || err != nil {
+		t.Fatalf("teacher GetByEmail = %v, %v; want nil, nil", teacher, err)
+	}
+}
+
+func TestCourseGetByIDCountsAssignments(t *testing.T) {
+	created := time.Date(2024, 9, 1, 10, 0, 0, 0, time.UTC)
+	fake := &scriptedDB{respond: func(string) [][]driver.Value {
+		return [][]driver.Value{{"course-1", "Go", "", nil, created, created, int64(5)}}
+	}}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	course, err := NewCourseRepository(db, zerolog.Nop()).GetByID(context.Background(), "course-1")
+	if err != nil {
+		t.Fatalf("get course: %v", err)
+	}
+	// Курс без преподавателя: teacher_id NULL
+	if course.ID != "course-1" || course.TeacherID != nil || course.TotalAssignments != 5 {
+		t.Fatalf("course = %+v, want course-1 without teacher and 5 assignments", course)
+	}
+	if !reflect.DeepEqual(fake.queries[0].args, []driver.Value{"course-1"}) {
+		t.Fatalf("args = %v, want course-1", fake.queries[0].args)
+	}
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+)
+
+type OutboxRepository interface {
+	// ClaimDue возвращает события, время публикации которых наступило, и откладывает их на lease,
+	// чтобы другой экземпляр сервиса не опубликовал их одновременно
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id string) error
+	// MarkRetry увеличивает attempts и переносит публикацию на nextAttemptAt
+	MarkRetry(ctx context.Context, id string, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, id string, lastError string) error
+}
+
+type outboxRepository struct {
+	*PostgresRepository
+}
+
+func NewOutboxRepository(db *sql.DB, logger zerolog.Logger) OutboxRepository {
+	return &outboxRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+const outboxColumns = `
+	id, aggregate_id, event_type, payload, status, attempts,
+	COALESCE(last_error, ''), next_attempt_at, created_at, published_at, trace_context
+`
+
+// insertOutboxEvent пишет событие в транзакции изменения, к которому оно относится
+func insertOutboxEvent(ctx context.Context, tx *sql.Tx, event *models.OutboxEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	if event.Status == "" {
+		event.Status = models.OutboxStatusPending.String()
+	}
+	now := time.Now()
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = now
+	}
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = now
+	}
+
+	var traceContext []byte
+	if len(event.TraceContext) > 0 {
+		var err error
+		if traceContext, err = json.Marshal(event.TraceContext); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO outbox_events (id, aggregate_id, event_type, payload, status, attempts, next_attempt_at, created_at, trace_context)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		event.ID,
+		event.AggregateID,
+		event.EventType,
+		[]byte(event.Payload),
+		event.Status,
+		event.Attempts,
+		event.NextAttemptAt,
+		event.CreatedAt,
+		traceContext,
+	)
+	return err
+}
+
+func (r *outboxRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.OutboxEvent, error) {
+	query := `
+		UPDATE outbox_events
+		SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var event models.OutboxEvent
+		var payload, traceContext []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.AggregateID,
+			&event.EventType,
+			&payload,
+			&event.Status,
+			&event.Attempts,
+			&event.LastError,
+			&event.NextAttemptAt,
+			&event.CreatedAt,
+			&event.PublishedAt,
+			&traceContext,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		if len(traceContext) > 0 {
+			// Повреждённый контекст трассировки не должен мешать публикации
+			_ = json.Unmarshal(traceContext, &event.TraceContext)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id string) error {
+	query := `
+		UPDATE outbox_events
+		SET status = 'published', attempts = attempts + 1, last_error = NULL, published_at = $1
+		WHERE id = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
+func (r *outboxRepository) MarkRetry(ctx context.Context, id string, nextAttemptAt time.Time, lastError string) error {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2
+		WHERE id = $3 AND status = 'pending'
+	`
+	_, err := r.db.ExecContext(ctx, query, lastError, nextAttemptAt, id)
+	return err
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id string, lastError string) error {
+	query := `
+		UPDATE outbox_events
+		SET status = 'failed', attempts = attempts + 1, last_error = $1
+		WHERE id = $2
+	`
+	_, err := r.db.ExecContext(ctx, query, lastError, id)
+	return err
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+)
+
+type PurgeRepository interface {
+	Create(ctx context.Context, purge *models.StudentDataPurge) error
+	GetByID(ctx context.Context, id string) (*models.StudentDataPurge, error)
+	GetActiveByStudentID(ctx context.Context, studentID string) (*models.StudentDataPurge, error)
+	Update(ctx context.Context, purge *models.StudentDataPurge) error
+}
+
+type purgeRepository struct {
+	*PostgresRepository
+}
+
+func NewPurgeRepository(db *sql.DB, logger zerolog.Logger) PurgeRepository {
+	return &purgeRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+const purgeColumns = `
+	id, student_id, status, confirmation_token, requested_by,
+	work_ids, deleted_file_ids, failed_file_ids, reports_deleted, works_deleted,
+	error, expires_at, created_at, confirmed_at, completed_at, updated_at
+`
+
+func (r *purgeRepository) Create(ctx context.Context, purge *models.StudentDataPurge) error {
+	query := `
+		INSERT INTO student_data_purges (` + purgeColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		purge.ID,
+		purge.StudentID,
+		purge.Status,
+		purge.ConfirmationToken,
+		nullString(purge.RequestedBy),
+		pq.Array(purge.WorkIDs),
+		pq.Array(purge.DeletedFileIDs),
+		pq.Array(purge.FailedFileIDs),
+		purge.ReportsDeleted,
+		purge.WorksDeleted,
+		nullString(purge.Error),
+		purge.ExpiresAt,
+		purge.CreatedAt,
+		purge.ConfirmedAt,
+		purge.CompletedAt,
+		purge.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *purgeRepository) GetByID(ctx context.Context, id string) (*models.StudentDataPurge, error) {
+	query := `SELECT ` + purgeColumns + ` FROM student_data_purges WHERE id = $1`
+	return r.scanPurge(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *purgeRepository) GetActiveByStudentID(ctx context.Context, studentID string) (*models.StudentDataPurge, error) {
+	query := `
+		SELECT ` + purgeColumns + `
+		FROM student_data_purges
+		WHERE student_id = $1
+			AND status IN ('pending_confirmation', 'in_progress', 'partial')
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	return r.scanPurge(r.db.QueryRowContext(ctx, query, studentID))
+}
+
+func (r *purgeRepository) Update(ctx context.Context, purge *models.StudentDataPurge) error {
+	query := `
+		UPDATE student_data_purges
+		SET status = $1,
+			work_ids = $2,
+			deleted_file_ids = $3,
+			failed_file_ids = $4,
+			reports_deleted = $5,
+			works_deleted = $6,
+			error = $7,
+			confirmed_at = $8,
+			completed_at = $9,
+			updated_at = $10
+		WHERE id = $11
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		purge.Status,
+		pq.Array(purge.WorkIDs),
+		pq.Array(purge.DeletedFileIDs),
+		pq.Array(purge.FailedFileIDs),
+		purge.ReportsDeleted,
+		purge.WorksDeleted,
+		nullString(purge.Error),
+		purge.ConfirmedAt,
+		purge.CompletedAt,
+		purge.UpdatedAt,
+		purge.ID,
+	)
+
+	return err
+}
+
+func (r *purgeRepository) scanPurge(row *sql.Row) (*models.StudentDataPurge, error) {
+	purge := &models.StudentDataPurge{}
+	var requestedBy, purgeError sql.NullString
+
+	err := row.Scan(
+		&purge.ID,
+		&purge.StudentID,
+		&purge.Status,
+		&purge.ConfirmationToken,
+		&requestedBy,
+		pq.Array(&purge.WorkIDs),
+		pq.Array(&purge.DeletedFileIDs),
+		pq.Array(&purge.FailedFileIDs),
+		&purge.ReportsDeleted,
+		&purge.WorksDeleted,
+		&purgeError,
+		&purge.ExpiresAt,
+		&purge.CreatedAt,
+		&purge.ConfirmedAt,
+		&purge.CompletedAt,
+		&purge.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	purge.RequestedBy = requestedBy.String
+	purge.Error = purgeError.String
+	return purge, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
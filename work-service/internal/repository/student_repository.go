@@ -3,19 +3,27 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog"
+	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
 )
 
 type StudentRepository interface {
 	Create(ctx context.Context, student *models.Student) error
+	// CreateBatch добавляет всех студентов в одной транзакции: при ошибке не создаётся никто
+	CreateBatch(ctx context.Context, students []models.Student) error
+	// GetExistingEmails — какие из emails уже заняты, включая студентов, ожидающих удаления данных
+	GetExistingEmails(ctx context.Context, emails []string) (map[string]bool, error)
 	GetByID(ctx context.Context, id string) (*models.StudentWithStats, error)
 	GetByEmail(ctx context.Context, email string) (*models.Student, error)
 	GetAll(ctx context.Context, limit, offset int) ([]models.StudentWithStats, int, error)
 	Update(ctx context.Context, student *models.Student) error
 	Delete(ctx context.Context, id string) error
 	Exists(ctx context.Context, id string) (bool, error)
+	SoftDelete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) error
 }
 
 type studentRepository struct {
@@ -45,6 +53,58 @@ func (r *studentRepository) Create(ctx context.Context, student *models.Student)
 	return err
 }
 
+func (r *studentRepository) CreateBatch(ctx context.Context, students []models.Student) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO students (id, name, email, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, student := range students {
+		if _, err := stmt.ExecContext(ctx,
+			student.ID,
+			student.Name,
+			student.Email,
+			student.CreatedAt,
+			student.UpdatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *studentRepository) GetExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	query := `SELECT email FROM students WHERE email = ANY($1)`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(emails))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		existing[email] = true
+	}
+
+	return existing, rows.Err()
+}
+
 func (r *studentRepository) GetByID(ctx context.Context, id string) (*models.StudentWithStats, error) {
 	query := `
 		SELECT 
@@ -54,7 +114,7 @@ func (r *studentRepository) GetByID(ctx context.Context, id string) (*models.Stu
 			COUNT(CASE WHEN w.status IN ('uploaded', 'analyzing') THEN 1 END) as pending_works
 		FROM students s
 		LEFT JOIN works w ON s.id = w.student_id
-		WHERE s.id = $1
+		WHERE s.id = $1 AND s.deleted_at IS NULL
 		GROUP BY s.id
 	`
 
@@ -81,7 +141,7 @@ func (r *studentRepository) GetByEmail(ctx context.Context, email string) (*mode
 	query := `
 		SELECT id, name, email, created_at, updated_at
 		FROM students
-		WHERE email = $1
+		WHERE email = $1 AND deleted_at IS NULL
 	`
 
 	student := &models.Student{}
@@ -101,7 +161,7 @@ func (r *studentRepository) GetByEmail(ctx context.Context, email string) (*mode
 }
 
 func (r *studentRepository) GetAll(ctx context.Context, limit, offset int) ([]models.StudentWithStats, int, error) {
-	countQuery := `SELECT COUNT(*) FROM students`
+	countQuery := `SELECT COUNT(*) FROM students WHERE deleted_at IS NULL`
 	var total int
 	err := r.db.QueryRowContext(ctx, countQuery).Scan(&total)
 	if err != nil {
@@ -116,6 +176,7 @@ func (r *studentRepository) GetAll(ctx context.Context, limit, offset int) ([]mo
 			COUNT(CASE WHEN w.status IN ('uploaded', 'analyzing') THEN 1 END) as pending_works
 		FROM students s
 		LEFT JOIN works w ON s.id = w.student_id
+		WHERE s.deleted_at IS NULL
 		GROUP BY s.id
 		ORDER BY s.created_at DESC
 		LIMIT $1 OFFSET $2
@@ -173,8 +234,21 @@ func (r *studentRepository) Delete(ctx context.Context, id string) error {
 }
 
 func (r *studentRepository) Exists(ctx context.Context, id string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM students WHERE id = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM students WHERE id = $1 AND deleted_at IS NULL)`
 	var exists bool
 	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
 	return exists, err
 }
+
+// SoftDelete скрывает студента до подтверждения удаления его данных
+func (r *studentRepository) SoftDelete(ctx context.Context, id string) error {
+	query := `UPDATE students SET deleted_at = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	return err
+}
+
+func (r *studentRepository) Restore(ctx context.Context, id string) error {
+	query := `UPDATE students SET deleted_at = NULL WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+)
+
+type TeacherRepository interface {
+	Create(ctx context.Context, teacher *models.Teacher) error
+	GetByID(ctx context.Context, id string) (*models.TeacherWithStats, error)
+	GetByEmail(ctx context.Context, email string) (*models.Teacher, error)
+	GetAll(ctx context.Context, limit, offset int) ([]models.TeacherWithStats, int, error)
+	Update(ctx context.Context, teacher *models.Teacher) error
+	Delete(ctx context.Context, id string) error
+	Exists(ctx context.Context, id string) (bool, error)
+}
+
+type teacherRepository struct {
+	*PostgresRepository
+}
+
+func NewTeacherRepository(db *sql.DB, logger zerolog.Logger) TeacherRepository {
+	return &teacherRepository{
+		PostgresRepository: NewPostgresRepository(db, logger),
+	}
+}
+
+func (r *teacherRepository) Create(ctx context.Context, teacher *models.Teacher) error {
+	query := `
+		INSERT INTO teachers (id, name, email, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		teacher.ID,
+		teacher.Name,
+		teacher.Email,
+		teacher.CreatedAt,
+		teacher.UpdatedAt,
+	)
+
+	return err
+}
+
+func (r *teacherRepository) GetByID(ctx context.Context, id string) (*models.TeacherWithStats, error) {
+	query := `
+		SELECT 
+			t.id, t.name, t.email, t.created_at, t.updated_at,
+			COUNT(c.id) as total_courses
+		FROM teachers t
+		LEFT JOIN courses c ON t.id = c.teacher_id
+		WHERE t.id = $1
+		GROUP BY t.id
+	`
+
+	teacher := &models.TeacherWithStats{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&teacher.ID,
+		&teacher.Name,
+		&teacher.Email,
+		&teacher.CreatedAt,
+		&teacher.UpdatedAt,
+		&teacher.TotalCourses,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return teacher, err
+}
+
+func (r *teacherRepository) GetByEmail(ctx context.Context, email string) (*models.Teacher, error) {
+	query := `
+		SELECT id, name, email, created_at, updated_at
+		FROM teachers
+		WHERE email = $1
+	`
+
+	teacher := &models.Teacher{}
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
+		&teacher.ID,
+		&teacher.Name,
+		&teacher.Email,
+		&teacher.CreatedAt,
+		&teacher.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return teacher, err
+}
+
+func (r *teacherRepository) GetAll(ctx context.Context, limit, offset int) ([]models.TeacherWithStats, int, error) {
+	countQuery := `SELECT COUNT(*) FROM teachers`
+	var total int
+	err := r.db.QueryRowContext(ctx, countQuery).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT 
+			t.id, t.name, t.email, t.created_at, t.updated_at,
+			COUNT(c.id) as total_courses
+		FROM teachers t
+		LEFT JOIN courses c ON t.id = c.teacher_id
+		GROUP BY t.id
+		ORDER BY t.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var teachers []models.TeacherWithStats
+	for rows.Next() {
+		var teacher models.TeacherWithStats
+		err := rows.Scan(
+			&teacher.ID,
+			&teacher.Name,
+			&teacher.Email,
+			&teacher.CreatedAt,
+			&teacher.UpdatedAt,
+			&teacher.TotalCourses,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		teachers = append(teachers, teacher)
+	}
+
+	return teachers, total, rows.Err()
+}
+
+func (r *teacherRepository) Update(ctx context.Context, teacher *models.Teacher) error {
+	query := `
+		UPDATE teachers
+		SET name = $1, email = $2, updated_at = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		teacher.Name,
+		teacher.Email,
+		teacher.UpdatedAt,
+		teacher.ID,
+	)
+
+	return err
+}
+
+func (r *teacherRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM teachers WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *teacherRepository) Exists(ctx context.Context, id string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM teachers WHERE id = $1)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&exists)
+	return exists, err
+}
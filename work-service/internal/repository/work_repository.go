@@ -3,23 +3,46 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"github.com/lib/pq"
 	"github.com/rs/zerolog"
 	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
 )
 
+// ErrDuplicateWork — у студента уже есть работа по заданию или ключ идемпотентности занят
+var ErrDuplicateWork = errors.New("work already exists")
+
+// ErrFileAlreadyAttached — у работы уже есть файл (или её удалили), повторная привязка не выполнена
+var ErrFileAlreadyAttached = errors.New("work already has a file attached")
+
 type WorkRepository interface {
 	Create(ctx context.Context, work *models.Work) error
+	CreateResubmission(ctx context.Context, work *models.Work) error
 	GetByID(ctx context.Context, id string) (*models.Work, error)
+	// GetByStudentAndAssignment возвращает последнюю попытку студента по заданию
 	GetByStudentAndAssignment(ctx context.Context, studentID, assignmentID string) (*models.Work, error)
-	GetByAssignmentID(ctx context.Context, assignmentID string, limit, offset int) ([]models.WorkWithDetails, int, error)
+	// GetByAssignmentID — работы задания; isLate != nil оставляет только сданные с опозданием или в срок
+	GetByAssignmentID(ctx context.Context, assignmentID string, isLate *bool, limit, offset int) ([]models.WorkWithDetails, int, error)
 	GetByStudentID(ctx context.Context, studentID string, limit, offset int) ([]models.WorkWithDetails, int, error)
 	GetAll(ctx context.Context, limit, offset int) ([]models.WorkWithDetails, int, error)
 	UpdateStatus(ctx context.Context, id, status string) error
 	UpdateFileID(ctx context.Context, id, fileID string) error
+	// GetByIdempotencyKey находит работу по ключу независимо от его срока; срок проверяет сервис
+	GetByIdempotencyKey(ctx context.Context, key string) (*models.Work, error)
+	// ReleaseIdempotencyKey снимает с работы истёкший ключ, чтобы его можно было использовать снова
+	ReleaseIdempotencyKey(ctx context.Context, workID, key string) error
+	ReleaseExpiredIdempotencyKeys(ctx context.Context, savedBefore time.Time) (int, error)
+	// AttachFile в одной транзакции привязывает файл к работе без файла, переводит её в analyzing,
+	// отмечает сдачу после срока isLate и сохраняет событие в outbox; ErrFileAlreadyAttached — файл уже привязан
+	AttachFile(ctx context.Context, id, fileID, idempotencyKey string, isLate bool, event *models.OutboxEvent) error
 	Delete(ctx context.Context, id string) error
-	GetPreviousWorks(ctx context.Context, assignmentID, excludeWorkID string) ([]models.Work, error)
+	DeletePendingFileWorks(ctx context.Context, createdBefore time.Time) (int, error)
+	ListByStudentID(ctx context.Context, studentID string) ([]models.Work, error)
+	// MarkReplaced отмечает прежние попытки студента с файлом заменёнными работой work и возвращает их;
+	// уже заменённые попытки повторно не возвращаются
+	MarkReplaced(ctx context.Context, work *models.Work) ([]models.Work, error)
 }
 
 type workRepository struct {
@@ -34,26 +57,76 @@ func NewWorkRepository(db *sql.DB, logger zerolog.Logger) WorkRepository {
 
 func (r *workRepository) Create(ctx context.Context, work *models.Work) error {
 	query := `
-		INSERT INTO works (id, student_id, assignment_id, file_id, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO works (id, student_id, assignment_id, file_id, status, attempt_number, idempotency_key, idempotency_key_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), CASE WHEN $7 = '' THEN NULL ELSE $8::timestamptz END, $8, $9)
+	`
+
+	return duplicateWorkErr(r.insert(ctx, r.db, query, work))
+}
+
+// CreateResubmission в одной транзакции помечает прежние попытки студента по заданию
+// как superseded и добавляет новую попытку work
+func (r *workRepository) CreateResubmission(ctx context.Context, work *models.Work) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	supersede := `
+		UPDATE works
+		SET superseded_at = $1, updated_at = $1
+		WHERE student_id = $2 AND assignment_id = $3 AND superseded_at IS NULL
+	`
+	if _, err := tx.ExecContext(ctx, supersede, work.CreatedAt, work.StudentID, work.AssignmentID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO works (id, student_id, assignment_id, file_id, status, attempt_number, idempotency_key, idempotency_key_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), CASE WHEN $7 = '' THEN NULL ELSE $8::timestamptz END, $8, $9)
 	`
+	if err := duplicateWorkErr(r.insert(ctx, tx, query, work)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (r *workRepository) insert(ctx context.Context, db execer, query string, work *models.Work) error {
+	if work.AttemptNumber < 1 {
+		work.AttemptNumber = 1
+	}
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.ExecContext(ctx, query,
 		work.ID,
 		work.StudentID,
 		work.AssignmentID,
 		work.FileID,
 		work.Status,
+		work.AttemptNumber,
+		work.IdempotencyKey,
 		work.CreatedAt,
 		work.UpdatedAt,
 	)
+	return err
+}
 
+func duplicateWorkErr(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrDuplicateWork
+	}
 	return err
 }
 
 func (r *workRepository) GetByID(ctx context.Context, id string) (*models.Work, error) {
 	query := `
-		SELECT id, student_id, assignment_id, file_id, status, created_at, updated_at
+		SELECT id, student_id, assignment_id, file_id, status, attempt_number, superseded_at, is_late, created_at, updated_at
 		FROM works
 		WHERE id = $1
 	`
@@ -65,6 +138,9 @@ func (r *workRepository) GetByID(ctx context.Context, id string) (*models.Work,
 		&work.AssignmentID,
 		&work.FileID,
 		&work.Status,
+		&work.AttemptNumber,
+		&work.SupersededAt,
+		&work.IsLate,
 		&work.CreatedAt,
 		&work.UpdatedAt,
 	)
@@ -78,9 +154,11 @@ func (r *workRepository) GetByID(ctx context.Context, id string) (*models.Work,
 
 func (r *workRepository) GetByStudentAndAssignment(ctx context.Context, studentID, assignmentID string) (*models.Work, error) {
 	query := `
-		SELECT id, student_id, assignment_id, file_id, status, created_at, updated_at
+		SELECT id, student_id, assignment_id, file_id, status, attempt_number, superseded_at, is_late, created_at, updated_at
 		FROM works
 		WHERE student_id = $1 AND assignment_id = $2
+		ORDER BY attempt_number DESC
+		LIMIT 1
 	`
 
 	work := &models.Work{}
@@ -90,6 +168,9 @@ func (r *workRepository) GetByStudentAndAssignment(ctx context.Context, studentI
 		&work.AssignmentID,
 		&work.FileID,
 		&work.Status,
+		&work.AttemptNumber,
+		&work.SupersededAt,
+		&work.IsLate,
 		&work.CreatedAt,
 		&work.UpdatedAt,
 	)
@@ -101,28 +182,28 @@ func (r *workRepository) GetByStudentAndAssignment(ctx context.Context, studentI
 	return work, err
 }
 
-func (r *workRepository) GetByAssignmentID(ctx context.Context, assignmentID string, limit, offset int) ([]models.WorkWithDetails, int, error) {
-	countQuery := `SELECT COUNT(*) FROM works WHERE assignment_id = $1`
+func (r *workRepository) GetByAssignmentID(ctx context.Context, assignmentID string, isLate *bool, limit, offset int) ([]models.WorkWithDetails, int, error) {
+	countQuery := `SELECT COUNT(*) FROM works WHERE assignment_id = $1 AND ($2::boolean IS NULL OR is_late = $2)`
 	var total int
-	err := r.db.QueryRowContext(ctx, countQuery, assignmentID).Scan(&total)
+	err := r.db.QueryRowContext(ctx, countQuery, assignmentID, isLate).Scan(&total)
 	if err != nil {
 		return nil, 0, err
 	}
 
 	query := `
 		SELECT 
-			w.id, w.student_id, w.assignment_id, w.file_id, w.status, w.created_at, w.updated_at,
+			w.id, w.student_id, w.assignment_id, w.file_id, w.status, w.attempt_number, w.superseded_at, w.replaced_by, w.is_late, w.created_at, w.updated_at,
 			s.name as student_name, s.email as student_email,
 			a.title as assignment_title
 		FROM works w
 		JOIN students s ON w.student_id = s.id
 		JOIN assignments a ON w.assignment_id = a.id
-		WHERE w.assignment_id = $1
+		WHERE w.assignment_id = $1 AND ($2::boolean IS NULL OR w.is_late = $2)
 		ORDER BY w.created_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT $3 OFFSET $4
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, assignmentID, limit, offset)
+	rows, err := r.db.QueryContext(ctx, query, assignmentID, isLate, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -137,6 +218,10 @@ func (r *workRepository) GetByAssignmentID(ctx context.Context, assignmentID str
 			&work.AssignmentID,
 			&work.FileID,
 			&work.Status,
+			&work.AttemptNumber,
+			&work.SupersededAt,
+			&work.ReplacedBy,
+			&work.IsLate,
 			&work.CreatedAt,
 			&work.UpdatedAt,
 			&work.StudentName,
@@ -162,7 +247,7 @@ func (r *workRepository) GetByStudentID(ctx context.Context, studentID string, l
 
 	query := `
 		SELECT 
-			w.id, w.student_id, w.assignment_id, w.file_id, w.status, w.created_at, w.updated_at,
+			w.id, w.student_id, w.assignment_id, w.file_id, w.status, w.attempt_number, w.superseded_at, w.is_late, w.created_at, w.updated_at,
 			s.name as student_name, s.email as student_email,
 			a.title as assignment_title
 		FROM works w
@@ -188,6 +273,9 @@ func (r *workRepository) GetByStudentID(ctx context.Context, studentID string, l
 			&work.AssignmentID,
 			&work.FileID,
 			&work.Status,
+			&work.AttemptNumber,
+			&work.SupersededAt,
+			&work.IsLate,
 			&work.CreatedAt,
 			&work.UpdatedAt,
 			&work.StudentName,
@@ -213,7 +301,7 @@ func (r *workRepository) GetAll(ctx context.Context, limit, offset int) ([]model
 
 	query := `
 		SELECT 
-			w.id, w.student_id, w.assignment_id, w.file_id, w.status, w.created_at, w.updated_at,
+			w.id, w.student_id, w.assignment_id, w.file_id, w.status, w.attempt_number, w.superseded_at, w.is_late, w.created_at, w.updated_at,
 			s.name as student_name, s.email as student_email,
 			a.title as assignment_title
 		FROM works w
@@ -238,6 +326,9 @@ func (r *workRepository) GetAll(ctx context.Context, limit, offset int) ([]model
 			&work.AssignmentID,
 			&work.FileID,
 			&work.Status,
+			&work.AttemptNumber,
+			&work.SupersededAt,
+			&work.IsLate,
 			&work.CreatedAt,
 			&work.UpdatedAt,
 			&work.StudentName,
@@ -275,21 +366,141 @@ func (r *workRepository) UpdateFileID(ctx context.Context, id, fileID string) er
 	return err
 }
 
+func (r *workRepository) GetByIdempotencyKey(ctx context.Context, key string) (*models.Work, error) {
+	query := `
+		SELECT id, student_id, assignment_id, file_id, status, attempt_number, superseded_at, is_late,
+			COALESCE(idempotency_key, ''), idempotency_key_at, created_at, updated_at
+		FROM works
+		WHERE idempotency_key = $1
+	`
+
+	work := &models.Work{}
+	err := r.db.QueryRowContext(ctx, query, key).Scan(
+		&work.ID,
+		&work.StudentID,
+		&work.AssignmentID,
+		&work.FileID,
+		&work.Status,
+		&work.AttemptNumber,
+		&work.SupersededAt,
+		&work.IsLate,
+		&work.IdempotencyKey,
+		&work.IdempotencyKeyAt,
+		&work.CreatedAt,
+		&work.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return work, err
+}
+
+func (r *workRepository) ReleaseIdempotencyKey(ctx context.Context, workID, key string) error {
+	query := `
+		UPDATE works
+		SET idempotency_key = NULL, idempotency_key_at = NULL
+		WHERE id = $1 AND idempotency_key = $2
+	`
+
+	_, err := r.db.ExecContext(ctx, query, workID, key)
+	return err
+}
+
+func (r *workRepository) ReleaseExpiredIdempotencyKeys(ctx context.Context, savedBefore time.Time) (int, error) {
+	query := `
+		UPDATE works
+		SET idempotency_key = NULL, idempotency_key_at = NULL
+		WHERE idempotency_key IS NOT NULL AND idempotency_key_at < $1
+	`
+
+	result, err := r.db.ExecContext(ctx, query, savedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	released, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(released), nil
+}
+
+func (r *workRepository) AttachFile(ctx context.Context, id, fileID, idempotencyKey string, isLate bool, event *models.OutboxEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Ключ сохраняется и для работы, созданной без него, чтобы повтор запроса нашёл её
+	query := `
+		UPDATE works
+		SET file_id = $1, status = $2,
+			idempotency_key = COALESCE(idempotency_key, NULLIF($3, '')),
+			idempotency_key_at = CASE WHEN idempotency_key IS NULL AND $3 <> '' THEN $5 ELSE idempotency_key_at END,
+			is_late = $4, updated_at = $5
+		WHERE id = $6 AND file_id = $7
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		fileID,
+		models.WorkStatusAnalyzing.String(),
+		idempotencyKey,
+		isLate,
+		time.Now(),
+		id,
+		models.PendingFileID,
+	)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrFileAlreadyAttached
+	}
+
+	if err := insertOutboxEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 func (r *workRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM works WHERE id = $1`
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *workRepository) GetPreviousWorks(ctx context.Context, assignmentID, excludeWorkID string) ([]models.Work, error) {
+func (r *workRepository) DeletePendingFileWorks(ctx context.Context, createdBefore time.Time) (int, error) {
+	query := `DELETE FROM works WHERE file_id = $1 AND created_at < $2`
+	result, err := r.db.ExecContext(ctx, query, models.PendingFileID, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(deleted), nil
+}
+
+func (r *workRepository) ListByStudentID(ctx context.Context, studentID string) ([]models.Work, error) {
 	query := `
-		SELECT id, student_id, assignment_id, file_id, status, created_at, updated_at
+		SELECT id, student_id, assignment_id, file_id, status, attempt_number, superseded_at, is_late, created_at, updated_at
 		FROM works
-		WHERE assignment_id = $1 AND id != $2
+		WHERE student_id = $1
 		ORDER BY created_at
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, assignmentID, excludeWorkID)
+	rows, err := r.db.QueryContext(ctx, query, studentID)
 	if err != nil {
 		return nil, err
 	}
@@ -304,6 +515,9 @@ func (r *workRepository) GetPreviousWorks(ctx context.Context, assignmentID, exc
 			&work.AssignmentID,
 			&work.FileID,
 			&work.Status,
+			&work.AttemptNumber,
+			&work.SupersededAt,
+			&work.IsLate,
 			&work.CreatedAt,
 			&work.UpdatedAt,
 		)
@@ -315,3 +529,37 @@ func (r *workRepository) GetPreviousWorks(ctx context.Context, assignmentID, exc
 
 	return works, nil
 }
+
+func (r *workRepository) MarkReplaced(ctx context.Context, work *models.Work) ([]models.Work, error) {
+	query := `
+		UPDATE works
+		SET replaced_by = $1, updated_at = $2
+		WHERE student_id = $3 AND assignment_id = $4 AND id != $1
+			AND attempt_number < $5 AND replaced_by IS NULL AND file_id != $6
+		RETURNING id, file_id, attempt_number
+	`
+
+	rows, err := r.db.QueryContext(ctx, query,
+		work.ID,
+		time.Now(),
+		work.StudentID,
+		work.AssignmentID,
+		work.AttemptNumber,
+		models.PendingFileID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var replaced []models.Work
+	for rows.Next() {
+		previous := models.Work{StudentID: work.StudentID, AssignmentID: work.AssignmentID}
+		if err := rows.Scan(&previous.ID, &previous.FileID, &previous.AttemptNumber); err != nil {
+			return nil, err
+		}
+		replaced = append(replaced, previous)
+	}
+
+	return replaced, rows.Err()
+}
@@ -18,27 +18,37 @@ type AssignmentService interface {
 	GetAllAssignments(ctx context.Context, page, limit int) ([]models.AssignmentWithStats, int, error)
 	UpdateAssignment(ctx context.Context, id string, req *models.CreateAssignmentRequest) error
 	DeleteAssignment(ctx context.Context, id string) error
+	GetAssignmentsByFileID(ctx context.Context, fileID string) ([]models.FileAssignment, error)
 }
 
 type assignmentService struct {
 	assignmentRepo repository.AssignmentRepository
+	courseRepo     repository.CourseRepository
 	logger         zerolog.Logger
 }
 
-func NewAssignmentService(assignmentRepo repository.AssignmentRepository, logger zerolog.Logger) AssignmentService {
+func NewAssignmentService(assignmentRepo repository.AssignmentRepository, courseRepo repository.CourseRepository, logger zerolog.Logger) AssignmentService {
 	return &assignmentService{
 		assignmentRepo: assignmentRepo,
+		courseRepo:     courseRepo,
 		logger:         logger,
 	}
 }
 
 func (s *assignmentService) CreateAssignment(ctx context.Context, req *models.CreateAssignmentRequest) (*models.Assignment, error) {
+	if err := s.checkCourse(ctx, req.CourseID); err != nil {
+		return nil, err
+	}
+
 	assignment := &models.Assignment{
-		ID:          uuid.New().String(),
-		Title:       req.Title,
-		Description: req.Description,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:                 uuid.New().String(),
+		Title:              req.Title,
+		Description:        req.Description,
+		DueAt:              req.DueAt,
+		CourseID:           req.CourseID,
+		ResubmissionPolicy: req.ResubmissionPolicy,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
 	}
 
 	if err := s.assignmentRepo.Create(ctx, assignment); err != nil {
@@ -92,8 +102,15 @@ func (s *assignmentService) UpdateAssignment(ctx context.Context, id string, req
 		return errors.New("assignment not found")
 	}
 
+	if err := s.checkCourse(ctx, req.CourseID); err != nil {
+		return err
+	}
+
 	assignment.Title = req.Title
 	assignment.Description = req.Description
+	assignment.DueAt = req.DueAt
+	assignment.CourseID = req.CourseID
+	assignment.ResubmissionPolicy = req.ResubmissionPolicy
 	assignment.UpdatedAt = time.Now()
 
 	return s.assignmentRepo.Update(ctx, &assignment.Assignment)
@@ -114,3 +131,28 @@ func (s *assignmentService) DeleteAssignment(ctx context.Context, id string) err
 
 	return s.assignmentRepo.Delete(ctx, id)
 }
+
+func (s *assignmentService) GetAssignmentsByFileID(ctx context.Context, fileID string) ([]models.FileAssignment, error) {
+	assignments, err := s.assignmentRepo.GetByFileID(ctx, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get assignments by file: %w", err)
+	}
+
+	return assignments, nil
+}
+
+// checkCourse — курс задания, если указан, должен существовать
+func (s *assignmentService) checkCourse(ctx context.Context, courseID *string) error {
+	if courseID == nil {
+		return nil
+	}
+
+	exists, err := s.courseRepo.Exists(ctx, *courseID)
+	if err != nil {
+		return fmt.Errorf("failed to check course: %w", err)
+	}
+	if !exists {
+		return errors.New("course does not exist")
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/repository"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+type CourseService interface {
+	CreateCourse(ctx context.Context, req *models.CreateCourseRequest) (*models.Course, error)
+	GetCourseByID(ctx context.Context, id string) (*models.CourseWithStats, error)
+	GetAllCourses(ctx context.Context, page, limit int) ([]models.CourseWithStats, int, error)
+	UpdateCourse(ctx context.Context, id string, req *models.CreateCourseRequest) error
+	DeleteCourse(ctx context.Context, id string) error
+	GetCourseAssignments(ctx context.Context, id string, page, limit int) ([]models.AssignmentWithStats, int, error)
+}
+
+type courseService struct {
+	courseRepo     repository.CourseRepository
+	teacherRepo    repository.TeacherRepository
+	assignmentRepo repository.AssignmentRepository
+	logger         zerolog.Logger
+}
+
+func NewCourseService(
+	courseRepo repository.CourseRepository,
+	teacherRepo repository.TeacherRepository,
+	assignmentRepo repository.AssignmentRepository,
+	logger zerolog.Logger,
+) CourseService {
+	return &courseService{
+		courseRepo:     courseRepo,
+		teacherRepo:    teacherRepo,
+		assignmentRepo: assignmentRepo,
+		logger:         logger,
+	}
+}
+
+func (s *courseService) CreateCourse(ctx context.Context, req *models.CreateCourseRequest) (*models.Course, error) {
+	if err := s.checkTeacher(ctx, req.TeacherID); err != nil {
+		return nil, err
+	}
+
+	course := &models.Course{
+		ID:          uuid.New().String(),
+		Title:       req.Title,
+		Description: req.Description,
+		TeacherID:   req.TeacherID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := s.courseRepo.Create(ctx, course); err != nil {
+		return nil, fmt.Errorf("failed to create course: %w", err)
+	}
+
+	s.logger.Info().
+		Str("course_id", course.ID).
+		Str("title", course.Title).
+		Msg("Course created")
+
+	return course, nil
+}
+
+func (s *courseService) GetCourseByID(ctx context.Context, id string) (*models.CourseWithStats, error) {
+	course, err := s.courseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course: %w", err)
+	}
+	if course == nil {
+		return nil, errors.New("course not found")
+	}
+
+	return course, nil
+}
+
+func (s *courseService) GetAllCourses(ctx context.Context, page, limit int) ([]models.CourseWithStats, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	courses, total, err := s.courseRepo.GetAll(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get all courses: %w", err)
+	}
+
+	return courses, total, nil
+}
+
+func (s *courseService) UpdateCourse(ctx context.Context, id string, req *models.CreateCourseRequest) error {
+	course, err := s.courseRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get course: %w", err)
+	}
+	if course == nil {
+		return errors.New("course not found")
+	}
+
+	if err := s.checkTeacher(ctx, req.TeacherID); err != nil {
+		return err
+	}
+
+	course.Title = req.Title
+	course.Description = req.Description
+	course.TeacherID = req.TeacherID
+	course.UpdatedAt = time.Now()
+
+	return s.courseRepo.Update(ctx, &course.Course)
+}
+
+func (s *courseService) DeleteCourse(ctx context.Context, id string) error {
+	course, err := s.courseRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get course: %w", err)
+	}
+	if course == nil {
+		return errors.New("course not found")
+	}
+
+	if course.TotalAssignments > 0 {
+		return errors.New("cannot delete course with existing assignments")
+	}
+
+	return s.courseRepo.Delete(ctx, id)
+}
+
+func (s *courseService) GetCourseAssignments(ctx context.Context, id string, page, limit int) ([]models.AssignmentWithStats, int, error) {
+	exists, err := s.courseRepo.Exists(ctx, id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to check course: %w", err)
+	}
+	if !exists {
+		return nil, 0, errors.New("course not found")
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	offset := (page - 1) * limit
+
+	assignments, total, err := s.assignmentRepo.GetByCourseID(ctx, id, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get course assignments: %w", err)
+	}
+
+	return assignments, total, nil
+}
+
+// checkTeacher — преподаватель курса, если указан, должен существовать
+func (s *courseService) checkTeacher(ctx context.Context, teacherID *string) error {
+	if teacherID == nil {
+		return nil
+	}
+
+	exists, err := s.teacherRepo.Exists(ctx, *teacherID)
+	if err != nil {
+		return fmt.Errorf("failed to check teacher: %w", err)
+	}
+	if !exists {
+		return errors.New("teacher does not exist")
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/repository"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/service/integration"
+)
+
+// Заглушки репозиториев в памяти: встроенный интерфейс остаётся nil, поэтому вызов метода,
+// который тест не ожидает, сразу падает с паникой. Ограничения повторяют схему БД
+
+type memWorkRepo struct {
+	repository.WorkRepository
+
+	mu    sync.Mutex
+	works map[string]*models.Work
+}
+
+func newMemWorkRepo() *memWorkRepo {
+	return &memWorkRepo{works: make(map[string]*models.Work)}
+}
+
+// insert соблюдает UNIQUE (student_id, assignment_id, attempt_number)
+func (r *memWorkRepo) insert(work *models.Work) error {
+	for _, w := range r.works {
+		if w.StudentID == work.StudentID && w.AssignmentID == work.AssignmentID && w.AttemptNumber == work.AttemptNumber {
+			return repository.ErrDuplicateWork
+		}
+	}
+	copied := *work
+	if copied.IdempotencyKey != "" {
+		savedAt := copied.CreatedAt
+		copied.IdempotencyKeyAt = &savedAt
+	}
+	r.works[work.ID] = &copied
+	return nil
+}
+
+func (r *memWorkRepo) Create(_ context.Context, work *models.Work) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.insert(work)
+}
+
+func (r *memWorkRepo) CreateResubmission(_ context.Context, work *models.Work) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, w := range r.works {
+		if w.StudentID == work.StudentID && w.AssignmentID == work.AssignmentID && w.SupersededAt == nil {
+			supersededAt := work.CreatedAt
+			w.SupersededAt = &supersededAt
+		}
+	}
+	return r.insert(work)
+}
+
+func (r *memWorkRepo) GetByID(_ context.Context, id string) (*models.Work, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.works[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *w
+	return &copied, nil
+}
+
+func (r *memWorkRepo) GetByStudentAndAssignment(_ context.Context, studentID, assignmentID string) (*models.Work, error) {
+	attempts := r.attempts(studentID, assignmentID)
+	if len(attempts) == 0 {
+		return nil, nil
+	}
+	return &attempts[len(attempts)-1], nil
+}
+
+func (r *memWorkRepo) GetByIdempotencyKey(_ context.Context, key string) (*models.Work, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, w := range r.works {
+		if w.IdempotencyKey == key {
+			copied := *w
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// AttachFile, как UPDATE ... WHERE file_id = pending, привязывает файл только к работе без файла
+func (r *memWorkRepo) AttachFile(_ context.Context, id, fileID, idempotencyKey string, isLate bool, _ *models.OutboxEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w, ok := r.works[id]
+	if !ok || w.FileID != models.PendingFileID {
+		return repository.ErrFileAlreadyAttached
+	}
+	w.FileID = fileID
+	w.Status = models.WorkStatusAnalyzing.String()
+	if w.IdempotencyKey == "" && idempotencyKey != "" {
+		savedAt := time.Now()
+		w.IdempotencyKey = idempotencyKey
+		w.IdempotencyKeyAt = &savedAt
+	}
+	w.IsLate = isLate
+	return nil
+}
+
+func (r *memWorkRepo) ReleaseIdempotencyKey(_ context.Context, workID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if w, ok := r.works[workID]; ok && w.IdempotencyKey == key {
+		w.IdempotencyKey = ""
+		w.IdempotencyKeyAt = nil
+	}
+	return nil
+}
+
+func (r *memWorkRepo) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.works, id)
+	return nil
+}
+
+// attempts — попытки студента по заданию по возрастанию номера
+func (r *memWorkRepo) attempts(studentID, assignmentID string) []models.Work {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []models.Work
+	for _, w := range r.works {
+		if w.StudentID == studentID && w.AssignmentID == assignmentID {
+			found = append(found, *w)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].AttemptNumber < found[j].AttemptNumber })
+	return found
+}
+
+// fakeStudentRepo — существующие студенты по id и занятые email; created — студенты из CreateBatch
+type fakeStudentRepo struct {
+	repository.StudentRepository
+
+	ids     map[string]bool
+	emails  map[string]bool
+	created []models.Student
+	lookups [][]string
+}
+
+func (r *fakeStudentRepo) Exists(_ context.Context, id string) (bool, error) {
+	return r.ids[id], nil
+}
+
+func (r *fakeStudentRepo) GetExistingEmails(_ context.Context, emails []string) (map[string]bool, error) {
+	r.lookups = append(r.lookups, emails)
+	existing := make(map[string]bool)
+	for _, email := range emails {
+		if r.emails[email] {
+			existing[email] = true
+		}
+	}
+	return existing, nil
+}
+
+func (r *fakeStudentRepo) CreateBatch(_ context.Context, students []models.Student) error {
+	r.created = append(r.created, students...)
+	return nil
+}
+
+// fakeAssignmentRepo — существующие задания, их политика повторной сдачи ("" — политика не задана)
+// и сроки сдачи (нет в dueAt — срока нет)
+type fakeAssignmentRepo struct {
+	repository.AssignmentRepository
+
+	policies map[string]string
+	dueAt    map[string]time.Time
+}
+
+func (r *fakeAssignmentRepo) Exists(_ context.Context, id string) (bool, error) {
+	_, ok := r.policies[id]
+	return ok, nil
+}
+
+func (r *fakeAssignmentRepo) GetResubmissionPolicy(_ context.Context, id string) (string, error) {
+	return r.policies[id], nil
+}
+
+func (r *fakeAssignmentRepo) GetDueAt(_ context.Context, id string) (*time.Time, error) {
+	dueAt, ok := r.dueAt[id]
+	if !ok {
+		return nil, nil
+	}
+	return &dueAt, nil
+}
+
+// fakeFileClient выдаёт загруженным файлам номера по порядку и запоминает удалённые
+type fakeFileClient struct {
+	integration.FileClient
+
+	mu      sync.Mutex
+	uploads int
+	deleted []string
+}
+
+func (c *fakeFileClient) UploadFile(context.Context, []byte, string) (*integration.UploadResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.uploads++
+	return &integration.UploadResponse{FileID: fmt.Sprintf("file-%d", c.uploads)}, nil
+}
+
+func (c *fakeFileClient) DeleteFile(_ context.Context, fileID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deleted = append(c.deleted, fileID)
+	return nil
+}
+
+type fakeOutboxRepo struct {
+	repository.OutboxRepository
+}
+
+func (r *fakeOutboxRepo) MarkPublished(context.Context, string) error { return nil }
+
+// fakeRabbitMQ запоминает опубликованные события о новых работах
+type fakeRabbitMQ struct {
+	integration.RabbitMQClient
+
+	mu        sync.Mutex
+	published []models.WorkCreatedEvent
+}
+
+func (c *fakeRabbitMQ) PublishWorkCreated(_ context.Context, event *models.WorkCreatedEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.published = append(c.published, *event)
+	return nil
+}
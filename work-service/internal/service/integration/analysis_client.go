@@ -1,18 +1,26 @@
 package integration
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
 
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/tracing"
 	"github.com/rs/zerolog"
 )
 
 type AnalysisClient interface {
 	GetReport(ctx context.Context, workID string) (*AnalysisReport, error)
+	// GetWorkPercentile возвращает nil без ошибки, если отчёта по работе нет
+	GetWorkPercentile(ctx context.Context, workID string) (*WorkPercentile, error)
+	DeleteStudentReports(ctx context.Context, studentID string) (int, error)
+	// SupersedeReport отмечает отчёт работы заменённым работой supersededBy; отчёта ещё нет — не ошибка
+	SupersedeReport(ctx context.Context, workID, supersededBy string) error
 }
 
 type analysisClient struct {
@@ -34,7 +42,19 @@ type AnalysisReport struct {
 	AnalyzedAt      *time.Time `json:"analyzed_at,omitempty"`
 }
 
-func NewAnalysisClient(baseURL, reportsEndpoint string, timeout time.Duration, retryCount int, retryDelay time.Duration, logger zerolog.Logger) AnalysisClient {
+type WorkPercentile struct {
+	WorkID          string  `json:"work_id"`
+	AssignmentID    string  `json:"assignment_id"`
+	MatchPercentage int     `json:"match_percentage"`
+	Percentile      float64 `json:"percentile"`
+	LowerCount      int     `json:"lower_count"`
+	PeerCount       int     `json:"peer_count"`
+}
+
+// ErrReportNotCompleted — отчёт по работе есть, но анализ ещё не завершён
+var ErrReportNotCompleted = errors.New("report is not completed")
+
+func NewAnalysisClient(baseURL, reportsEndpoint, apiKey string, timeout time.Duration, retryCount int, retryDelay time.Duration, logger zerolog.Logger) AnalysisClient {
 	return &analysisClient{
 		baseURL:         baseURL,
 		reportsEndpoint: reportsEndpoint,
@@ -42,7 +62,8 @@ func NewAnalysisClient(baseURL, reportsEndpoint string, timeout time.Duration, r
 		retryCount:      retryCount,
 		retryDelay:      retryDelay,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: tracing.Transport(&serviceRoleTransport{base: newAPIKeyTransport(apiKey)}),
 		},
 		logger: logger,
 	}
@@ -97,3 +118,135 @@ func (c *analysisClient) GetReport(ctx context.Context, workID string) (*Analysi
 
 	return nil, fmt.Errorf("failed to get analysis report after %d attempts: %w", c.retryCount+1, lastErr)
 }
+
+func (c *analysisClient) GetWorkPercentile(ctx context.Context, workID string) (*WorkPercentile, error) {
+	url := fmt.Sprintf("%s%s/%s/percentile", c.baseURL, c.reportsEndpoint, workID)
+
+	var lastErr error
+	for i := 0; i <= c.retryCount; i++ {
+		if i > 0 {
+			c.logger.Warn().Int("attempt", i).Msg("Retrying work percentile fetch")
+			time.Sleep(c.retryDelay * time.Duration(i))
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to get work percentile: %w", err)
+			continue
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			var envelope struct {
+				Data WorkPercentile `json:"data"`
+			}
+			err := json.NewDecoder(resp.Body).Decode(&envelope)
+			resp.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+			return &envelope.Data, nil
+		case http.StatusNotFound:
+			resp.Body.Close()
+			return nil, nil
+		case http.StatusConflict:
+			resp.Body.Close()
+			return nil, ErrReportNotCompleted
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		lastErr = fmt.Errorf("analysis service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return nil, fmt.Errorf("failed to get work percentile after %d attempts: %w", c.retryCount+1, lastErr)
+}
+
+func (c *analysisClient) DeleteStudentReports(ctx context.Context, studentID string) (int, error) {
+	url := fmt.Sprintf("%s/api/v1/reports/student/%s", c.baseURL, studentID)
+
+	var lastErr error
+	for i := 0; i <= c.retryCount; i++ {
+		if i > 0 {
+			c.logger.Warn().Int("attempt", i).Msg("Retrying student reports deletion")
+			time.Sleep(c.retryDelay * time.Duration(i))
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to delete reports: %w", err)
+			continue
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			var envelope struct {
+				Data struct {
+					Deleted int `json:"deleted"`
+				} `json:"data"`
+			}
+			err := json.NewDecoder(resp.Body).Decode(&envelope)
+			resp.Body.Close()
+			if err != nil {
+				return 0, fmt.Errorf("failed to decode response: %w", err)
+			}
+			return envelope.Data.Deleted, nil
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		lastErr = fmt.Errorf("analysis service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return 0, fmt.Errorf("failed to delete student reports after %d attempts: %w", c.retryCount+1, lastErr)
+}
+
+func (c *analysisClient) SupersedeReport(ctx context.Context, workID, supersededBy string) error {
+	url := fmt.Sprintf("%s%s/%s/supersede", c.baseURL, c.reportsEndpoint, workID)
+
+	payload, err := json.Marshal(map[string]string{"superseded_by": supersededBy})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var lastErr error
+	for i := 0; i <= c.retryCount; i++ {
+		if i > 0 {
+			c.logger.Warn().Int("attempt", i).Msg("Retrying report supersession")
+			time.Sleep(c.retryDelay * time.Duration(i))
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to supersede report: %w", err)
+			continue
+		}
+
+		// Анализ прежней работы мог ещё не начаться: отмечать нечего
+		if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return nil
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		lastErr = fmt.Errorf("analysis service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return fmt.Errorf("failed to supersede report after %d attempts: %w", c.retryCount+1, lastErr)
+}
@@ -0,0 +1,34 @@
+package integration
+
+import "net/http"
+
+// apiKeyTransport добавляет к запросам в другие сервисы общий ключ из services.<имя>.api_key
+type apiKeyTransport struct {
+	key  string
+	base http.RoundTripper
+}
+
+func newAPIKeyTransport(key string) http.RoundTripper {
+	if key == "" {
+		return http.DefaultTransport
+	}
+	return &apiKeyTransport{key: key, base: http.DefaultTransport}
+}
+
+func (t *apiKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper не должен менять исходный запрос
+	req = req.Clone(req.Context())
+	req.Header.Set("X-API-Key", t.key)
+	return t.base.RoundTrip(req)
+}
+
+// serviceRoleTransport помечает запросы в analysis-service ролью service: без X-User-Role отчёты не отдаются
+type serviceRoleTransport struct {
+	base http.RoundTripper
+}
+
+func (t *serviceRoleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-User-Role", "service")
+	return t.base.RoundTrip(req)
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/tracing"
 	"github.com/rs/zerolog"
 )
 
@@ -17,6 +18,7 @@ type FileClient interface {
 	UploadFile(ctx context.Context, fileContent []byte, fileName string) (*UploadResponse, error)
 	GetFile(ctx context.Context, fileID string) ([]byte, error)
 	DeleteFile(ctx context.Context, fileID string) error
+	PurgeFile(ctx context.Context, fileID string) error
 }
 
 type fileClient struct {
@@ -35,7 +37,7 @@ type UploadResponse struct {
 	Size   int64
 }
 
-func NewFileClient(baseURL, uploadEndpoint string, timeout time.Duration, retryCount int, retryDelay time.Duration, logger zerolog.Logger) FileClient {
+func NewFileClient(baseURL, uploadEndpoint, apiKey string, timeout time.Duration, retryCount int, retryDelay time.Duration, logger zerolog.Logger) FileClient {
 	return &fileClient{
 		baseURL:        baseURL,
 		uploadEndpoint: uploadEndpoint,
@@ -43,7 +45,8 @@ func NewFileClient(baseURL, uploadEndpoint string, timeout time.Duration, retryC
 		retryCount:     retryCount,
 		retryDelay:     retryDelay,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: tracing.Transport(newAPIKeyTransport(apiKey)),
 		},
 		logger: logger,
 	}
@@ -179,3 +182,26 @@ func (c *fileClient) DeleteFile(ctx context.Context, fileID string) error {
 
 	return nil
 }
+
+// PurgeFile безвозвратно удаляет файл; отсутствующий файл считается уже удалённым
+func (c *fileClient) PurgeFile(ctx context.Context, fileID string) error {
+	url := fmt.Sprintf("%s/api/v1/files/%s?hard=true", c.baseURL, fileID)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to purge file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("file service returned status %d", resp.StatusCode)
+	}
+}
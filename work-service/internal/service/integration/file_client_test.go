@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/tracing"
+)
+
+func TestUploadFilePropagatesTraceContext(t *testing.T) {
+	// Init без экспорта ставит только пропагатор W3C, как в сервисе с выключенной трассировкой
+	if _, err := tracing.Init(context.Background(), tracing.Config{}); err != nil {
+		t.Fatalf("init tracing: %v", err)
+	}
+	spans := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	var traceparent, apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		apiKey = r.Header.Get("X-API-Key")
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data":{"file_id":"file-1","hash":"abc","file_size":5}}`)
+	}))
+	defer server.Close()
+
+	client := NewFileClient(server.URL, "/api/v1/files/upload", "internal-key", time.Second, 0, 0, zerolog.Nop())
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "work upload")
+	resp, err := client.UploadFile(ctx, []byte("essay"), "essay.txt")
+	parent.End()
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	if resp.FileID != "file-1" {
+		t.Fatalf("file id = %q, want file-1", resp.FileID)
+	}
+
+	// Заголовок продолжает трассу вызывающего и указывает на клиентский спан запроса
+	var clientSpan trace.SpanContext
+	for _, span := range spans.Ended() {
+		if span.SpanKind() == trace.SpanKindClient {
+			clientSpan = span.SpanContext()
+		}
+	}
+	if !clientSpan.IsValid() {
+		t.Fatal("no client span recorded for the upload request")
+	}
+	traceID := parent.SpanContext().TraceID()
+	if clientSpan.TraceID() != traceID {
+		t.Fatalf("client span trace = %s, want %s", clientSpan.TraceID(), traceID)
+	}
+	want := fmt.Sprintf("00-%s-%s-01", traceID, clientSpan.SpanID())
+	if traceparent != want {
+		t.Fatalf("traceparent = %q, want %q", traceparent, want)
+	}
+	if apiKey != "internal-key" {
+		t.Fatalf("X-API-Key = %q, want the configured key alongside the trace header", apiKey)
+	}
+}
@@ -7,8 +7,12 @@ import (
 	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/tracing"
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type RabbitMQClient interface {
@@ -101,6 +105,20 @@ func (c *rabbitMQClient) PublishWorkCreated(ctx context.Context, event *models.W
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
+	ctx, span := tracing.Tracer().Start(ctx, c.routingKey+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination.name", c.exchange),
+			attribute.String("work_id", event.WorkID),
+		),
+	)
+	defer span.End()
+
+	// Контекст трассировки уходит в заголовках: analysis-service продолжит ту же трассу
+	headers := amqp091.Table{}
+	tracing.InjectAMQP(ctx, headers)
+
 	publishCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
@@ -112,6 +130,7 @@ func (c *rabbitMQClient) PublishWorkCreated(ctx context.Context, event *models.W
 		false,        // immediate
 		amqp091.Publishing{
 			ContentType:  "application/json",
+			Headers:      headers,
 			Body:         body,
 			DeliveryMode: amqp091.Persistent, // Сохраняем сообщение
 			Timestamp:    time.Now(),
@@ -119,6 +138,8 @@ func (c *rabbitMQClient) PublishWorkCreated(ctx context.Context, event *models.W
 	)
 
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
 
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/tracing"
+)
+
+// outboxLease — на сколько откладывается событие, взятое в публикацию; если экземпляр
+// упадёт посреди отправки, событие подхватит фоновая публикация после истечения срока
+const outboxLease = time.Minute
+
+// newOutboxEvent сохраняет с событием контекст трассировки ctx, чтобы фоновая публикация продолжила трассу запроса
+func newOutboxEvent(ctx context.Context, aggregateID, eventType string, payload interface{}) (*models.OutboxEvent, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	return &models.OutboxEvent{
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       body,
+		NextAttemptAt: time.Now().Add(outboxLease),
+		TraceContext:  tracing.Inject(ctx),
+	}, nil
+}
+
+// PublishPendingEvents отправляет события outbox, время которых наступило; возвращает число опубликованных
+func (s *workService) PublishPendingEvents(ctx context.Context, limit int) (int, error) {
+	events, err := s.outboxRepo.ClaimDue(ctx, time.Now(), outboxLease, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	published := 0
+	for i := range events {
+		if s.publishOutboxEvent(tracing.Extract(ctx, events[i].TraceContext), &events[i]) {
+			published++
+		}
+	}
+	return published, nil
+}
+
+// publishOutboxEvent отправляет событие и фиксирует результат в outbox. После outbox.max_attempts
+// неудач событие помечается failed, а работа — failed: анализ по ней уже не начнётся.
+func (s *workService) publishOutboxEvent(ctx context.Context, event *models.OutboxEvent) bool {
+	err := s.publishEvent(ctx, event)
+	if err == nil {
+		if err := s.outboxRepo.MarkPublished(ctx, event.ID); err != nil {
+			// Событие уже ушло; повторная отправка возможна, потребитель обрабатывает работу по work_id
+			s.logger.Error().Err(err).Str("event_id", event.ID).Msg("Failed to mark outbox event as published")
+		}
+		return true
+	}
+
+	attempts := event.Attempts + 1
+	if attempts >= s.config.OutboxMaxAttempts {
+		if err := s.outboxRepo.MarkFailed(ctx, event.ID, err.Error()); err != nil {
+			s.logger.Error().Err(err).Str("event_id", event.ID).Msg("Failed to mark outbox event as failed")
+		}
+		if err := s.workRepo.UpdateStatus(ctx, event.AggregateID, models.WorkStatusFailed.String()); err != nil {
+			s.logger.Error().Err(err).Str("work_id", event.AggregateID).Msg("Failed to mark work as failed")
+		}
+
+		s.logger.Error().
+			Err(err).
+			Str("event_id", event.ID).
+			Str("work_id", event.AggregateID).
+			Int("attempts", attempts).
+			Msg("Outbox event abandoned after max attempts")
+		return false
+	}
+
+	nextAttemptAt := time.Now().Add(outboxBackoff(attempts, s.config.OutboxBaseDelay, s.config.OutboxMaxDelay))
+	if markErr := s.outboxRepo.MarkRetry(ctx, event.ID, nextAttemptAt, err.Error()); markErr != nil {
+		s.logger.Error().Err(markErr).Str("event_id", event.ID).Msg("Failed to schedule outbox event retry")
+	}
+
+	s.logger.Warn().
+		Err(err).
+		Str("event_id", event.ID).
+		Str("work_id", event.AggregateID).
+		Int("attempts", attempts).
+		Time("next_attempt_at", nextAttemptAt).
+		Msg("Failed to publish outbox event, will retry")
+	return false
+}
+
+func (s *workService) publishEvent(ctx context.Context, event *models.OutboxEvent) error {
+	switch event.EventType {
+	case models.EventTypeWorkCreated:
+		var payload models.WorkCreatedEvent
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal event: %w", err)
+		}
+		return s.rabbitmqClient.PublishWorkCreated(ctx, &payload)
+	default:
+		return fmt.Errorf("unknown event type: %s", event.EventType)
+	}
+}
+
+// outboxBackoff — base * 2^(attempts-1), не больше max
+func outboxBackoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		if delay >= max/2 {
+			return max
+		}
+		delay *= 2
+	}
+
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/repository"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/service/integration"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+)
+
+// PurgeService удаляет все данные студента во всех сервисах.
+// Удаление двухшаговое: запрос скрывает студента (мягкое удаление) и выдаёт токен,
+// подтверждение с токеном удаляет файлы, отчёты и работы. Частичные сбои
+// фиксируются в записи аудита, подтверждение можно повторить.
+type PurgeService interface {
+	RequestPurge(ctx context.Context, studentID, requestedBy string) (*models.PurgeRequestResponse, error)
+	ConfirmPurge(ctx context.Context, studentID, purgeID, token string) (*models.StudentDataPurge, error)
+	CancelPurge(ctx context.Context, studentID, purgeID string) (*models.StudentDataPurge, error)
+	GetPurge(ctx context.Context, studentID, purgeID string) (*models.StudentDataPurge, error)
+}
+
+type purgeService struct {
+	purgeRepo      repository.PurgeRepository
+	studentRepo    repository.StudentRepository
+	workRepo       repository.WorkRepository
+	fileClient     integration.FileClient
+	analysisClient integration.AnalysisClient
+	logger         zerolog.Logger
+	config         PurgeConfig
+}
+
+type PurgeConfig struct {
+	Enabled         bool
+	ConfirmationTTL time.Duration
+}
+
+func NewPurgeService(
+	purgeRepo repository.PurgeRepository,
+	studentRepo repository.StudentRepository,
+	workRepo repository.WorkRepository,
+	fileClient integration.FileClient,
+	analysisClient integration.AnalysisClient,
+	logger zerolog.Logger,
+	config PurgeConfig,
+) PurgeService {
+	return &purgeService{
+		purgeRepo:      purgeRepo,
+		studentRepo:    studentRepo,
+		workRepo:       workRepo,
+		fileClient:     fileClient,
+		analysisClient: analysisClient,
+		logger:         logger,
+		config:         config,
+	}
+}
+
+func (s *purgeService) RequestPurge(ctx context.Context, studentID, requestedBy string) (*models.PurgeRequestResponse, error) {
+	if !s.config.Enabled {
+		return nil, errors.New("data purge is disabled")
+	}
+
+	active, err := s.purgeRepo.GetActiveByStudentID(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check active purge: %w", err)
+	}
+	if active != nil {
+		return &models.PurgeRequestResponse{Purge: active, ConfirmationToken: active.ConfirmationToken}, nil
+	}
+
+	student, err := s.studentRepo.GetByID(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student: %w", err)
+	}
+	if student == nil {
+		return nil, errors.New("student not found")
+	}
+
+	works, err := s.workRepo.ListByStudentID(ctx, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list student works: %w", err)
+	}
+
+	token, err := generateConfirmationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	purge := &models.StudentDataPurge{
+		ID:                uuid.New().String(),
+		StudentID:         studentID,
+		Status:            models.PurgeStatusPendingConfirmation,
+		ConfirmationToken: token,
+		RequestedBy:       requestedBy,
+		WorkIDs:           workIDs(works),
+		DeletedFileIDs:    []string{},
+		FailedFileIDs:     []string{},
+		ExpiresAt:         now.Add(s.config.ConfirmationTTL),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	if err := s.purgeRepo.Create(ctx, purge); err != nil {
+		return nil, fmt.Errorf("failed to create purge request: %w", err)
+	}
+
+	if err := s.studentRepo.SoftDelete(ctx, studentID); err != nil {
+		return nil, fmt.Errorf("failed to soft delete student: %w", err)
+	}
+
+	s.logger.Info().
+		Str("student_id", studentID).
+		Str("purge_id", purge.ID).
+		Int("works", len(purge.WorkIDs)).
+		Msg("Student data purge requested")
+
+	return &models.PurgeRequestResponse{Purge: purge, ConfirmationToken: token}, nil
+}
+
+func (s *purgeService) ConfirmPurge(ctx context.Context, studentID, purgeID, token string) (*models.StudentDataPurge, error) {
+	if !s.config.Enabled {
+		return nil, errors.New("data purge is disabled")
+	}
+
+	purge, err := s.GetPurge(ctx, studentID, purgeID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch purge.Status {
+	case models.PurgeStatusCompleted:
+		return purge, nil
+	case models.PurgeStatusInProgress:
+		return nil, errors.New("purge already in progress")
+	case models.PurgeStatusCancelled, models.PurgeStatusExpired:
+		return nil, errors.New("purge request is no longer active")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(token), []byte(purge.ConfirmationToken)) != 1 {
+		return nil, errors.New("invalid confirmation token")
+	}
+
+	now := time.Now()
+	if purge.Status == models.PurgeStatusPendingConfirmation && now.After(purge.ExpiresAt) {
+		purge.Status = models.PurgeStatusExpired
+		purge.UpdatedAt = now
+		if err := s.purgeRepo.Update(ctx, purge); err != nil {
+			return nil, fmt.Errorf("failed to update purge: %w", err)
+		}
+		if err := s.studentRepo.Restore(ctx, studentID); err != nil {
+			s.logger.Error().Err(err).Str("student_id", studentID).Msg("Failed to restore student after expired purge")
+		}
+		return nil, errors.New("purge confirmation expired")
+	}
+
+	purge.Status = models.PurgeStatusInProgress
+	purge.ConfirmedAt = &now
+	purge.UpdatedAt = now
+	if err := s.purgeRepo.Update(ctx, purge); err != nil {
+		return nil, fmt.Errorf("failed to update purge: %w", err)
+	}
+
+	s.executePurge(ctx, purge)
+
+	if err := s.purgeRepo.Update(ctx, purge); err != nil {
+		return nil, fmt.Errorf("failed to update purge: %w", err)
+	}
+
+	return purge, nil
+}
+
+// executePurge удаляет данные во внешних сервисах, затем в своей БД.
+// Работы удаляются только после успешного удаления файлов и отчётов,
+// чтобы при повторе можно было снова найти оставшиеся файлы.
+func (s *purgeService) executePurge(ctx context.Context, purge *models.StudentDataPurge) {
+	var failures []string
+
+	works, err := s.workRepo.ListByStudentID(ctx, purge.StudentID)
+	if err != nil {
+		failures = append(failures, fmt.Sprintf("failed to list works: %v", err))
+	}
+
+	deleted := make(map[string]bool, len(purge.DeletedFileIDs))
+	for _, fileID := range purge.DeletedFileIDs {
+		deleted[fileID] = true
+	}
+
+	purge.FailedFileIDs = []string{}
+	for _, work := range works {
+		if work.FileID == "" || deleted[work.FileID] {
+			continue
+		}
+
+		if err := s.fileClient.PurgeFile(ctx, work.FileID); err != nil {
+			s.logger.Error().
+				Err(err).
+				Str("purge_id", purge.ID).
+				Str("file_id", work.FileID).
+				Msg("Failed to purge file")
+			purge.FailedFileIDs = append(purge.FailedFileIDs, work.FileID)
+			continue
+		}
+
+		deleted[work.FileID] = true
+		purge.DeletedFileIDs = append(purge.DeletedFileIDs, work.FileID)
+	}
+
+	if len(purge.FailedFileIDs) > 0 {
+		failures = append(failures, fmt.Sprintf("failed to delete %d files", len(purge.FailedFileIDs)))
+	}
+
+	reportsDeleted, err := s.analysisClient.DeleteStudentReports(ctx, purge.StudentID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("purge_id", purge.ID).Msg("Failed to delete student reports")
+		failures = append(failures, fmt.Sprintf("failed to delete reports: %v", err))
+	} else {
+		purge.ReportsDeleted += reportsDeleted
+	}
+
+	now := time.Now()
+	purge.UpdatedAt = now
+
+	if len(failures) > 0 {
+		purge.Status = models.PurgeStatusPartial
+		purge.Error = strings.Join(failures, "; ")
+		s.logger.Warn().
+			Str("purge_id", purge.ID).
+			Str("error", purge.Error).
+			Msg("Student data purge partially failed")
+		return
+	}
+
+	// Работы удаляются каскадно вместе со студентом
+	if err := s.studentRepo.Delete(ctx, purge.StudentID); err != nil {
+		purge.Status = models.PurgeStatusPartial
+		purge.Error = fmt.Sprintf("failed to delete student: %v", err)
+		return
+	}
+
+	purge.WorksDeleted = len(works)
+	purge.Status = models.PurgeStatusCompleted
+	purge.Error = ""
+	purge.CompletedAt = &now
+
+	s.logger.Info().
+		Str("purge_id", purge.ID).
+		Str("student_id", purge.StudentID).
+		Int("works_deleted", purge.WorksDeleted).
+		Int("files_deleted", len(purge.DeletedFileIDs)).
+		Int("reports_deleted", purge.ReportsDeleted).
+		Msg("Student data purged")
+}
+
+func (s *purgeService) CancelPurge(ctx context.Context, studentID, purgeID string) (*models.StudentDataPurge, error) {
+	purge, err := s.GetPurge(ctx, studentID, purgeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if purge.Status != models.PurgeStatusPendingConfirmation {
+		return nil, errors.New("only unconfirmed purge requests can be cancelled")
+	}
+
+	if err := s.studentRepo.Restore(ctx, studentID); err != nil {
+		return nil, fmt.Errorf("failed to restore student: %w", err)
+	}
+
+	purge.Status = models.PurgeStatusCancelled
+	purge.UpdatedAt = time.Now()
+	if err := s.purgeRepo.Update(ctx, purge); err != nil {
+		return nil, fmt.Errorf("failed to update purge: %w", err)
+	}
+
+	s.logger.Info().
+		Str("student_id", studentID).
+		Str("purge_id", purgeID).
+		Msg("Student data purge cancelled")
+
+	return purge, nil
+}
+
+func (s *purgeService) GetPurge(ctx context.Context, studentID, purgeID string) (*models.StudentDataPurge, error) {
+	purge, err := s.purgeRepo.GetByID(ctx, purgeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get purge: %w", err)
+	}
+	if purge == nil || purge.StudentID != studentID {
+		return nil, errors.New("purge request not found")
+	}
+
+	return purge, nil
+}
+
+func generateConfirmationToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func workIDs(works []models.Work) []string {
+	ids := make([]string, 0, len(works))
+	for _, work := range works {
+		ids = append(ids, work.ID)
+	}
+	return ids
+}
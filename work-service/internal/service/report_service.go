@@ -13,6 +13,7 @@ import (
 
 type ReportService interface {
 	GetWorkReport(ctx context.Context, workID string) (*models.ReportResponse, error)
+	GetWorkPercentile(ctx context.Context, workID string) (*integration.WorkPercentile, error)
 }
 
 type reportService struct {
@@ -76,3 +77,27 @@ func (s *reportService) GetWorkReport(ctx context.Context, workID string) (*mode
 
 	return report, nil
 }
+
+// GetWorkPercentile показывает, у какой доли других работ задания процент совпадения ниже
+func (s *reportService) GetWorkPercentile(ctx context.Context, workID string) (*integration.WorkPercentile, error) {
+	work, err := s.workRepo.GetByID(ctx, workID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get work: %w", err)
+	}
+	if work == nil {
+		return nil, errors.New("work not found")
+	}
+
+	percentile, err := s.analysisClient.GetWorkPercentile(ctx, workID)
+	if err != nil {
+		if errors.Is(err, integration.ErrReportNotCompleted) {
+			return nil, errors.New("analysis is not completed")
+		}
+		return nil, err
+	}
+	if percentile == nil {
+		return nil, errors.New("analysis report not found")
+	}
+
+	return percentile, nil
+}
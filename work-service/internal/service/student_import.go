@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/validation"
+)
+
+// ImportStudents проверяет строки CSV так же, как POST /students, пропускает email, уже занятые
+// или повторённые выше в файле, и создаёт остальных студентов одной транзакцией.
+// Занятость email проверяется одним запросом на весь файл.
+func (s *studentService) ImportStudents(ctx context.Context, csvData io.Reader) (*models.StudentImportResult, error) {
+	reader := csv.NewReader(csvData)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("csv file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+	nameCol, emailCol := -1, -1
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))) {
+		case "name":
+			nameCol = i
+		case "email":
+			emailCol = i
+		}
+	}
+	if nameCol < 0 || emailCol < 0 {
+		return nil, errors.New("csv must contain name and email columns")
+	}
+
+	result := &models.StudentImportResult{Rows: []models.StudentImportRow{}}
+	// Строки, прошедшие проверку, и индексы их результатов в result.Rows
+	var candidates []*models.Student
+	var candidateRows []int
+	seen := make(map[string]int)
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var row models.StudentImportRow
+		var parseErr *csv.ParseError
+		if err == nil {
+			row.Line, _ = reader.FieldPos(0)
+		}
+		switch {
+		case errors.As(err, &parseErr):
+			row.Line = parseErr.StartLine
+			row.Status = models.StudentImportError
+			row.Reason = "malformed csv row: " + parseErr.Err.Error()
+		case err != nil:
+			return nil, fmt.Errorf("failed to read csv: %w", err)
+		case nameCol >= len(record) || emailCol >= len(record):
+			row.Status = models.StudentImportError
+			row.Reason = "row has fewer columns than the header"
+		default:
+			req := &models.CreateStudentRequest{
+				Name:  strings.TrimSpace(record[nameCol]),
+				Email: strings.TrimSpace(record[emailCol]),
+			}
+			row.Name, row.Email = req.Name, req.Email
+
+			if errs := validation.Struct(req); errs != nil {
+				row.Status = models.StudentImportError
+				row.Reason = errs.Error()
+			} else if firstLine, ok := seen[req.Email]; ok {
+				row.Status = models.StudentImportSkipped
+				row.Reason = fmt.Sprintf("duplicate email, first seen on line %d", firstLine)
+			} else {
+				seen[req.Email] = row.Line
+				student := newStudent(req)
+				row.StudentID = student.ID
+				candidates = append(candidates, student)
+				candidateRows = append(candidateRows, len(result.Rows))
+			}
+		}
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	var emails []string
+	for _, student := range candidates {
+		emails = append(emails, student.Email)
+	}
+	existing := map[string]bool{}
+	if len(emails) > 0 {
+		existing, err = s.studentRepo.GetExistingEmails(ctx, emails)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing students: %w", err)
+		}
+	}
+
+	students := make([]models.Student, 0, len(candidates))
+	for i, student := range candidates {
+		row := &result.Rows[candidateRows[i]]
+		if existing[student.Email] {
+			row.Status = models.StudentImportSkipped
+			row.Reason = "student with this email already exists"
+			row.StudentID = ""
+			continue
+		}
+		row.Status = models.StudentImportCreated
+		students = append(students, *student)
+	}
+
+	if len(students) > 0 {
+		if err := s.studentRepo.CreateBatch(ctx, students); err != nil {
+			return nil, fmt.Errorf("failed to create students: %w", err)
+		}
+	}
+
+	for _, row := range result.Rows {
+		switch row.Status {
+		case models.StudentImportCreated:
+			result.Created++
+		case models.StudentImportSkipped:
+			result.Skipped++
+		default:
+			result.Errored++
+		}
+	}
+	result.Total = len(result.Rows)
+
+	s.logger.Info().
+		Int("total", result.Total).
+		Int("created", result.Created).
+		Int("skipped", result.Skipped).
+		Int("errored", result.Errored).
+		Msg("Students imported")
+
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+)
+
+func TestImportStudentsMixedRows(t *testing.T) {
+	repo := &fakeStudentRepo{emails: map[string]bool{"bob@example.com": true}}
+	s := NewStudentService(repo, zerolog.Nop())
+
+	// Колонки в другом порядке, заголовок с BOM, как сохраняет Excel
+	csvData := "\ufeffEmail,Name\n" +
+		"alice@example.com,Alice Smith\n" + // 2: создаётся
+		"bob@example.com,Bob Jones\n" + // 3: email уже занят
+		"alice@example.com,Alice Again\n" + // 4: повтор строки 2
+		"not-an-email,Carol\n" + // 5: не проходит проверку
+		"dave@example.com\n" + // 6: не хватает колонки
+		"\"eve@example.com,Eve\n" // 7: незакрытая кавычка
+
+	result, err := s.ImportStudents(context.Background(), strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+
+	want := []struct {
+		line   int
+		status string
+		reason string
+	}{
+		{2, models.StudentImportCreated, ""},
+		{3, models.StudentImportSkipped, "student with this email already exists"},
+		{4, models.StudentImportSkipped, "duplicate email, first seen on line 2"},
+		{5, models.StudentImportError, "email"},
+		{6, models.StudentImportError, "row has fewer columns than the header"},
+		{7, models.StudentImportError, "malformed csv row"},
+	}
+	if len(result.Rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(result.Rows), len(want), result.Rows)
+	}
+	for i, w := range want {
+		row := result.Rows[i]
+		if row.Line != w.line || row.Status != w.status || !strings.Contains(row.Reason, w.reason) {
+			t.Errorf("row %d = %+v, want line %d %s (%s)", i, row, w.line, w.status, w.reason)
+		}
+	}
+
+	if result.Total != 6 || result.Created != 1 || result.Skipped != 2 || result.Errored != 3 {
+		t.Fatalf("totals = %d/%d/%d/%d, want 6 total, 1 created, 2 skipped, 3 errored",
+			result.Total, result.Created, result.Skipped, result.Errored)
+	}
+
+	// Создаётся только новый студент, и его id отдаётся в строке результата
+	if len(repo.created) != 1 || repo.created[0].Email != "alice@example.com" || repo.created[0].Name != "Alice Smith" {
+		t.Fatalf("created = %+v, want only Alice", repo.created)
+	}
+	if result.Rows[0].StudentID != repo.created[0].ID || result.Rows[1].StudentID != "" {
+		t.Fatalf("student ids = %q, %q; want Alice's id and none for the skipped row", result.Rows[0].StudentID, result.Rows[1].StudentID)
+	}
+	// Занятость email проверяется одним запросом по прошедшим проверку строкам
+	if !reflect.DeepEqual(repo.lookups, [][]string{{"alice@example.com", "bob@example.com"}}) {
+		t.Fatalf("email lookups = %v, want one lookup of alice and bob", repo.lookups)
+	}
+}
+
+func TestImportStudentsRejectsBadHeader(t *testing.T) {
+	for _, csvData := range []string{"", "name,phone\nAlice,123\n"} {
+		repo := &fakeStudentRepo{}
+		if _, err := NewStudentService(repo, zerolog.Nop()).ImportStudents(context.Background(), strings.NewReader(csvData)); err == nil {
+			t.Errorf("import of %q succeeded, want a header error", csvData)
+		}
+		if len(repo.created) != 0 {
+			t.Errorf("import of %q created %d students", csvData, len(repo.created))
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
@@ -19,6 +20,8 @@ type StudentService interface {
 	GetAllStudents(ctx context.Context, page, limit int) ([]models.StudentWithStats, int, error)
 	UpdateStudent(ctx context.Context, id string, req *models.CreateStudentRequest) error
 	DeleteStudent(ctx context.Context, id string) error
+	// ImportStudents создаёт студентов из CSV с колонками name и email
+	ImportStudents(ctx context.Context, csvData io.Reader) (*models.StudentImportResult, error)
 }
 
 type studentService struct {
@@ -42,13 +45,7 @@ func (s *studentService) CreateStudent(ctx context.Context, req *models.CreateSt
 		return nil, errors.New("student with this email already exists")
 	}
 
-	student := &models.Student{
-		ID:        uuid.New().String(),
-		Name:      req.Name,
-		Email:     req.Email,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
+	student := newStudent(req)
 
 	if err := s.studentRepo.Create(ctx, student); err != nil {
 		return nil, fmt.Errorf("failed to create student: %w", err)
@@ -145,3 +142,14 @@ func (s *studentService) DeleteStudent(ctx context.Context, id string) error {
 
 	return s.studentRepo.Delete(ctx, id)
 }
+
+func newStudent(req *models.CreateStudentRequest) *models.Student {
+	now := time.Now()
+	return &models.Student{
+		ID:        uuid.New().String(),
+		Name:      req.Name,
+		Email:     req.Email,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
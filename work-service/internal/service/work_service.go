@@ -18,42 +18,80 @@ type WorkService interface {
 	CreateWork(ctx context.Context, req *models.CreateWorkRequest) (*models.CreateWorkResponse, error)
 	UploadWork(ctx context.Context, req *models.UploadWorkRequest) (*models.CreateWorkResponse, error)
 	GetWorkByID(ctx context.Context, id string) (*models.WorkWithDetails, error)
-	GetWorksByAssignment(ctx context.Context, assignmentID string, page, limit int) (*models.WorksResponse, error)
+	// GetWorksByAssignment — isLate != nil оставляет только работы, сданные с опозданием (true) или в срок (false)
+	GetWorksByAssignment(ctx context.Context, assignmentID string, isLate *bool, page, limit int) (*models.WorksResponse, error)
 	GetWorksByStudent(ctx context.Context, studentID string, page, limit int) (*models.WorksResponse, error)
 	GetAllWorks(ctx context.Context, page, limit int) (*models.WorksResponse, error)
 	UpdateWorkStatus(ctx context.Context, id, status string) error
 	DeleteWork(ctx context.Context, id string) error
-	GetPreviousWorks(ctx context.Context, assignmentID, excludeWorkID string) ([]models.Work, error)
+	DeleteAbandonedWorks(ctx context.Context, olderThan time.Duration) (int, error)
+	// ReleaseExpiredIdempotencyKeys освобождает ключи идемпотентности старше WorkConfig.IdempotencyKeyTTL
+	ReleaseExpiredIdempotencyKeys(ctx context.Context) (int, error)
+	PublishPendingEvents(ctx context.Context, limit int) (int, error)
+}
+
+// WorkConfig — повторная сдача работ и повторы публикации событий из outbox
+type WorkConfig struct {
+	// Политика повторной сдачи для заданий без своей (models.Resubmission*)
+	ResubmissionPolicy string
+	// Срок, в течение которого повтор с тем же Idempotency-Key возвращает прежнюю работу (0 — бессрочно)
+	IdempotencyKeyTTL time.Duration
+	OutboxMaxAttempts int
+	OutboxBaseDelay   time.Duration
+	OutboxMaxDelay    time.Duration
 }
 
 type workService struct {
 	workRepo       repository.WorkRepository
 	studentRepo    repository.StudentRepository
 	assignmentRepo repository.AssignmentRepository
+	outboxRepo     repository.OutboxRepository
 	fileClient     integration.FileClient
+	analysisClient integration.AnalysisClient
 	rabbitmqClient integration.RabbitMQClient
 	logger         zerolog.Logger
+	config         WorkConfig
 }
 
 func NewWorkService(
 	workRepo repository.WorkRepository,
 	studentRepo repository.StudentRepository,
 	assignmentRepo repository.AssignmentRepository,
+	outboxRepo repository.OutboxRepository,
 	fileClient integration.FileClient,
+	analysisClient integration.AnalysisClient,
 	rabbitmqClient integration.RabbitMQClient,
 	logger zerolog.Logger,
+	config WorkConfig,
 ) WorkService {
 	return &workService{
 		workRepo:       workRepo,
 		studentRepo:    studentRepo,
 		assignmentRepo: assignmentRepo,
+		outboxRepo:     outboxRepo,
 		fileClient:     fileClient,
+		analysisClient: analysisClient,
 		rabbitmqClient: rabbitmqClient,
 		logger:         logger,
+		config:         config,
 	}
 }
 
 func (s *workService) CreateWork(ctx context.Context, req *models.CreateWorkRequest) (*models.CreateWorkResponse, error) {
+	work, err := s.createWork(ctx, req, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CreateWorkResponse{
+		ID:            work.ID,
+		Status:        work.Status,
+		AttemptNumber: work.AttemptNumber,
+		CreatedAt:     work.CreatedAt,
+	}, nil
+}
+
+func (s *workService) createWork(ctx context.Context, req *models.CreateWorkRequest, idempotencyKey string) (*models.Work, error) {
 	studentExists, err := s.studentRepo.Exists(ctx, req.StudentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check student existence: %w", err)
@@ -75,21 +113,39 @@ func (s *workService) CreateWork(ctx context.Context, req *models.CreateWorkRequ
 		return nil, fmt.Errorf("failed to check existing work: %w", err)
 	}
 	if existingWork != nil {
-		return nil, errors.New("work already submitted for this assignment")
+		policy, err := s.resubmissionPolicy(ctx, req.AssignmentID)
+		if err != nil {
+			return nil, err
+		}
+		if policy == models.ResubmissionReject {
+			return nil, errors.New("work already submitted for this assignment")
+		}
 	}
 
 	workID := uuid.New().String()
 	work := &models.Work{
-		ID:           workID,
-		StudentID:    req.StudentID,
-		AssignmentID: req.AssignmentID,
-		FileID:       "pending", // Временное значение
-		Status:       models.WorkStatusUploaded.String(),
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		ID:             workID,
+		StudentID:      req.StudentID,
+		AssignmentID:   req.AssignmentID,
+		FileID:         models.PendingFileID, // Временное значение до загрузки файла
+		Status:         models.WorkStatusUploaded.String(),
+		AttemptNumber:  1,
+		IdempotencyKey: idempotencyKey,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 
-	if err := s.workRepo.Create(ctx, work); err != nil {
+	if existingWork != nil {
+		work.AttemptNumber = existingWork.AttemptNumber + 1
+		err = s.workRepo.CreateResubmission(ctx, work)
+	} else {
+		err = s.workRepo.Create(ctx, work)
+	}
+	if err != nil {
+		// Параллельный запрос успел создать работу между проверкой и вставкой
+		if errors.Is(err, repository.ErrDuplicateWork) {
+			return nil, errors.New("work already submitted for this assignment")
+		}
 		return nil, fmt.Errorf("failed to create work: %w", err)
 	}
 
@@ -97,65 +153,240 @@ func (s *workService) CreateWork(ctx context.Context, req *models.CreateWorkRequ
 		Str("work_id", workID).
 		Str("student_id", req.StudentID).
 		Str("assignment_id", req.AssignmentID).
+		Int("attempt", work.AttemptNumber).
 		Msg("Work created")
 
-	return &models.CreateWorkResponse{
-		ID:        workID,
-		Status:    work.Status,
-		CreatedAt: work.CreatedAt,
-	}, nil
+	return work, nil
 }
 
+// UploadWork безопасен для повтора клиентом:
+//   - ошибка загрузки файла — удаляется работа, созданная этим запросом;
+//   - ошибка транзакции привязки файла — удаляется загруженный файл и созданная работа;
+//   - ошибка публикации — событие остаётся в outbox и отправляется фоновой публикацией.
+//
+// Работа без файла, оставшаяся от прерванной попытки, используется повторно, а повтор
+// с тем же Idempotency-Key после успешной загрузки возвращает уже созданную работу,
+// пока ключ не старше works.idempotency_key_ttl.
 func (s *workService) UploadWork(ctx context.Context, req *models.UploadWorkRequest) (*models.CreateWorkResponse, error) {
-	createReq := &models.CreateWorkRequest{
-		StudentID:    req.StudentID,
-		AssignmentID: req.AssignmentID,
-	}
-
-	workResponse, err := s.CreateWork(ctx, createReq)
+	work, created, err := s.workForUpload(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	if work.FileID != models.PendingFileID {
+		s.logger.Info().
+			Str("work_id", work.ID).
+			Str("idempotency_key", req.IdempotencyKey).
+			Msg("Repeated upload, returning existing work")
+		return uploadResponse(work), nil
+	}
+
+	// Работу из прошлой попытки не удаляем: её подхватит следующий повтор или удалит reaper
+	compensate := func() {
+		if !created {
+			return
+		}
+		if err := s.workRepo.Delete(ctx, work.ID); err != nil {
+			s.logger.Error().Err(err).Str("work_id", work.ID).Msg("Failed to delete work after failed upload")
+		}
+	}
+
 	uploadResp, err := s.fileClient.UploadFile(ctx, req.FileContent, req.FileName)
 	if err != nil {
-		s.workRepo.Delete(ctx, workResponse.ID)
+		compensate()
 		return nil, fmt.Errorf("failed to upload file: %w", err)
 	}
 	if uploadResp == nil || uploadResp.FileID == "" {
-		s.workRepo.Delete(ctx, workResponse.ID)
+		compensate()
 		return nil, errors.New("file service returned empty file_id")
 	}
 
-	if err := s.workRepo.UpdateFileID(ctx, workResponse.ID, uploadResp.FileID); err != nil {
-		s.workRepo.Delete(ctx, workResponse.ID)
-		s.fileClient.DeleteFile(ctx, uploadResp.FileID)
-		return nil, fmt.Errorf("failed to update work with file id: %w", err)
+	// Сдача — момент загрузки файла, а не создания работы через POST /works
+	isLate, err := s.submittedLate(ctx, req.AssignmentID, time.Now())
+	if err != nil {
+		s.deleteOrphanFile(ctx, uploadResp.FileID)
+		compensate()
+		return nil, err
 	}
 
-	event := &models.WorkCreatedEvent{
-		WorkID:       workResponse.ID,
+	event, err := newOutboxEvent(ctx, work.ID, models.EventTypeWorkCreated, &models.WorkCreatedEvent{
+		WorkID:       work.ID,
 		FileID:       uploadResp.FileID,
 		StudentID:    req.StudentID,
 		AssignmentID: req.AssignmentID,
 		Timestamp:    time.Now().Unix(),
+	})
+	if err != nil {
+		s.deleteOrphanFile(ctx, uploadResp.FileID)
+		compensate()
+		return nil, err
 	}
 
-	if err := s.rabbitmqClient.PublishWorkCreated(ctx, event); err != nil {
-		s.logger.Error().Err(err).Msg("Failed to publish work created event")
+	if err := s.workRepo.AttachFile(ctx, work.ID, uploadResp.FileID, req.IdempotencyKey, isLate, event); err != nil {
+		s.deleteOrphanFile(ctx, uploadResp.FileID)
+
+		// Параллельный повтор того же запроса успел привязать свой файл
+		if errors.Is(err, repository.ErrFileAlreadyAttached) {
+			current, getErr := s.workRepo.GetByID(ctx, work.ID)
+			if getErr != nil {
+				return nil, fmt.Errorf("failed to get work: %w", getErr)
+			}
+			if current == nil {
+				return nil, errors.New("work not found")
+			}
+			return uploadResponse(current), nil
+		}
+
+		compensate()
+		return nil, fmt.Errorf("failed to attach file to work: %w", err)
 	}
 
-	if err := s.workRepo.UpdateStatus(ctx, workResponse.ID, models.WorkStatusAnalyzing.String()); err != nil {
-		s.logger.Error().Err(err).Msg("Failed to update work status to analyzing")
+	s.publishOutboxEvent(ctx, event)
+
+	if work.AttemptNumber > 1 {
+		s.replacePreviousAttempts(ctx, work)
 	}
 
 	s.logger.Info().
-		Str("work_id", workResponse.ID).
+		Str("work_id", work.ID).
 		Str("file_id", uploadResp.FileID).
 		Msg("Work uploaded and analysis started")
 
-	workResponse.FileID = uploadResp.FileID
-	return workResponse, nil
+	work.FileID = uploadResp.FileID
+	work.Status = models.WorkStatusAnalyzing.String()
+	work.IsLate = isLate
+	return uploadResponse(work), nil
+}
+
+// resubmissionPolicy — политика задания, а если она не задана — из конфигурации
+func (s *workService) resubmissionPolicy(ctx context.Context, assignmentID string) (string, error) {
+	policy, err := s.assignmentRepo.GetResubmissionPolicy(ctx, assignmentID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get resubmission policy: %w", err)
+	}
+	if policy == "" {
+		policy = s.config.ResubmissionPolicy
+	}
+	return policy, nil
+}
+
+// replacePreviousAttempts при политике replace отмечает прежние попытки заменёнными, снимает ссылку
+// на их файлы и помечает их отчёты. Вызывается после привязки файла, чтобы неудачная загрузка не
+// оставила студента без сданной работы; ошибки только логируются — новая попытка уже сдана.
+func (s *workService) replacePreviousAttempts(ctx context.Context, work *models.Work) {
+	policy, err := s.resubmissionPolicy(ctx, work.AssignmentID)
+	if err != nil {
+		s.logger.Error().Err(err).Str("work_id", work.ID).Msg("Failed to replace previous attempts")
+		return
+	}
+	if policy != models.ResubmissionReplace {
+		return
+	}
+
+	replaced, err := s.workRepo.MarkReplaced(ctx, work)
+	if err != nil {
+		s.logger.Error().Err(err).Str("work_id", work.ID).Msg("Failed to mark previous attempts as replaced")
+		return
+	}
+
+	for _, previous := range replaced {
+		// Файл удаляется по ссылке: если его же загрузил кто-то ещё, объект останется
+		if err := s.fileClient.DeleteFile(ctx, previous.FileID); err != nil {
+			s.logger.Error().Err(err).Str("work_id", previous.ID).Str("file_id", previous.FileID).Msg("Failed to delete file of replaced attempt")
+		}
+		if err := s.analysisClient.SupersedeReport(ctx, previous.ID, work.ID); err != nil {
+			s.logger.Error().Err(err).Str("work_id", previous.ID).Msg("Failed to supersede report of replaced attempt")
+		}
+
+		s.logger.Info().
+			Str("work_id", previous.ID).
+			Int("attempt", previous.AttemptNumber).
+			Str("replaced_by", work.ID).
+			Msg("Previous attempt replaced")
+	}
+}
+
+// submittedLate сравнивает время сдачи со сроком задания; задание без срока — всегда в срок
+func (s *workService) submittedLate(ctx context.Context, assignmentID string, submittedAt time.Time) (bool, error) {
+	dueAt, err := s.assignmentRepo.GetDueAt(ctx, assignmentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get assignment deadline: %w", err)
+	}
+	return dueAt != nil && submittedAt.After(*dueAt), nil
+}
+
+// workForUpload возвращает работу, в которую идёт загрузка; created — работа создана этим запросом
+func (s *workService) workForUpload(ctx context.Context, req *models.UploadWorkRequest) (*models.Work, bool, error) {
+	if req.IdempotencyKey != "" {
+		work, err := s.workRepo.GetByIdempotencyKey(ctx, req.IdempotencyKey)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to check idempotency key: %w", err)
+		}
+		if work != nil && s.idempotencyKeyExpired(work) {
+			if err := s.workRepo.ReleaseIdempotencyKey(ctx, work.ID, req.IdempotencyKey); err != nil {
+				return nil, false, fmt.Errorf("failed to release expired idempotency key: %w", err)
+			}
+			work = nil
+		}
+		if work != nil {
+			return sameUploadWork(work, req)
+		}
+	}
+
+	existing, err := s.workRepo.GetByStudentAndAssignment(ctx, req.StudentID, req.AssignmentID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check existing work: %w", err)
+	}
+	if existing != nil && existing.FileID == models.PendingFileID {
+		return existing, false, nil
+	}
+
+	work, err := s.createWork(ctx, &models.CreateWorkRequest{
+		StudentID:    req.StudentID,
+		AssignmentID: req.AssignmentID,
+	}, req.IdempotencyKey)
+	if err != nil {
+		// Параллельный запрос с тем же ключом успел создать работу: продолжаем загрузку в неё
+		if req.IdempotencyKey != "" {
+			if winner, getErr := s.workRepo.GetByIdempotencyKey(ctx, req.IdempotencyKey); getErr == nil && winner != nil {
+				return sameUploadWork(winner, req)
+			}
+		}
+		return nil, false, err
+	}
+	return work, true, nil
+}
+
+// sameUploadWork возвращает найденную по ключу работу, если ключ пришёл от того же студента по тому же заданию
+func sameUploadWork(work *models.Work, req *models.UploadWorkRequest) (*models.Work, bool, error) {
+	if work.StudentID != req.StudentID || work.AssignmentID != req.AssignmentID {
+		return nil, false, errors.New("idempotency key already used for another work")
+	}
+	return work, false, nil
+}
+
+func (s *workService) idempotencyKeyExpired(work *models.Work) bool {
+	if s.config.IdempotencyKeyTTL <= 0 || work.IdempotencyKeyAt == nil {
+		return false
+	}
+	return time.Since(*work.IdempotencyKeyAt) > s.config.IdempotencyKeyTTL
+}
+
+func (s *workService) deleteOrphanFile(ctx context.Context, fileID string) {
+	if err := s.fileClient.DeleteFile(ctx, fileID); err != nil {
+		s.logger.Error().Err(err).Str("file_id", fileID).Msg("Failed to delete orphan file")
+	}
+}
+
+func uploadResponse(work *models.Work) *models.CreateWorkResponse {
+	return &models.CreateWorkResponse{
+		ID:            work.ID,
+		Status:        work.Status,
+		FileID:        work.FileID,
+		AttemptNumber: work.AttemptNumber,
+		IsLate:        work.IsLate,
+		CreatedAt:     work.CreatedAt,
+	}
 }
 
 func (s *workService) GetWorkByID(ctx context.Context, id string) (*models.WorkWithDetails, error) {
@@ -181,7 +412,7 @@ func (s *workService) GetWorkByID(ctx context.Context, id string) (*models.WorkW
 	return nil, errors.New("work details not found")
 }
 
-func (s *workService) GetWorksByAssignment(ctx context.Context, assignmentID string, page, limit int) (*models.WorksResponse, error) {
+func (s *workService) GetWorksByAssignment(ctx context.Context, assignmentID string, isLate *bool, page, limit int) (*models.WorksResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -191,7 +422,7 @@ func (s *workService) GetWorksByAssignment(ctx context.Context, assignmentID str
 
 	offset := (page - 1) * limit
 
-	works, total, err := s.workRepo.GetByAssignmentID(ctx, assignmentID, limit, offset)
+	works, total, err := s.workRepo.GetByAssignmentID(ctx, assignmentID, isLate, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get works by assignment: %w", err)
 	}
@@ -267,7 +498,7 @@ func (s *workService) DeleteWork(ctx context.Context, id string) error {
 		return errors.New("work not found")
 	}
 
-	if work.FileID != "" && work.FileID != "pending" {
+	if work.FileID != "" && work.FileID != models.PendingFileID {
 		if err := s.fileClient.DeleteFile(ctx, work.FileID); err != nil {
 			s.logger.Error().Err(err).Str("file_id", work.FileID).Msg("Failed to delete file")
 		}
@@ -276,6 +507,23 @@ func (s *workService) DeleteWork(ctx context.Context, id string) error {
 	return s.workRepo.Delete(ctx, id)
 }
 
-func (s *workService) GetPreviousWorks(ctx context.Context, assignmentID, excludeWorkID string) ([]models.Work, error) {
-	return s.workRepo.GetPreviousWorks(ctx, assignmentID, excludeWorkID)
+// DeleteAbandonedWorks удаляет работы, к которым так и не загрузили файл за olderThan
+func (s *workService) DeleteAbandonedWorks(ctx context.Context, olderThan time.Duration) (int, error) {
+	deleted, err := s.workRepo.DeletePendingFileWorks(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete abandoned works: %w", err)
+	}
+	return deleted, nil
+}
+
+func (s *workService) ReleaseExpiredIdempotencyKeys(ctx context.Context) (int, error) {
+	if s.config.IdempotencyKeyTTL <= 0 {
+		return 0, nil
+	}
+	released, err := s.workRepo.ReleaseExpiredIdempotencyKeys(ctx, time.Now().Add(-s.config.IdempotencyKeyTTL))
+	if err != nil {
+		return 0, fmt.Errorf("failed to release expired idempotency keys: %w", err)
+	}
+	return released, nil
 }
+
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/models"
+)
+
+func newTestWorkService(works *memWorkRepo, assignments *fakeAssignmentRepo, config WorkConfig) WorkService {
+	students := &fakeStudentRepo{ids: map[string]bool{"alice": true}}
+	return NewWorkService(works, students, assignments, nil, nil, nil, nil, zerolog.Nop(), config)
+}
+
+// newUploadTestService — сервис с загрузкой файлов: file-service и RabbitMQ заменены заглушками
+func newUploadTestService(works *memWorkRepo, assignments *fakeAssignmentRepo, config WorkConfig) (WorkService, *fakeFileClient, *fakeRabbitMQ) {
+	students := &fakeStudentRepo{ids: map[string]bool{"alice": true}}
+	files := &fakeFileClient{}
+	rabbitmq := &fakeRabbitMQ{}
+	s := NewWorkService(works, students, assignments, &fakeOutboxRepo{}, files, nil, rabbitmq, zerolog.Nop(), config)
+	return s, files, rabbitmq
+}
+
+func TestResubmissionBlockedByRejectPolicy(t *testing.T) {
+	works := newMemWorkRepo()
+	assignments := &fakeAssignmentRepo{policies: map[string]string{"essay": ""}}
+	s := newTestWorkService(works, assignments, WorkConfig{ResubmissionPolicy: models.ResubmissionReject})
+	req := &models.CreateWorkRequest{StudentID: "alice", AssignmentID: "essay"}
+
+	first, err := s.CreateWork(context.Background(), req)
+	if err != nil {
+		t.Fatalf("first submission: %v", err)
+	}
+	if first.AttemptNumber != 1 {
+		t.Fatalf("first attempt = %d, want 1", first.AttemptNumber)
+	}
+
+	_, err = s.CreateWork(context.Background(), req)
+	if err == nil || err.Error() != "work already submitted for this assignment" {
+		t.Fatalf("second submission: err = %v, want already submitted", err)
+	}
+	if got := works.attempts("alice", "essay"); len(got) != 1 || got[0].SupersededAt != nil {
+		t.Fatalf("attempts after rejected resubmission = %+v, want the first one untouched", got)
+	}
+}
+
+func TestResubmissionCreatesNewAttempt(t *testing.T) {
+	works := newMemWorkRepo()
+	// Политика задания важнее значения по умолчанию из конфигурации
+	assignments := &fakeAssignmentRepo{policies: map[string]string{"essay": models.ResubmissionVersion}}
+	s := newTestWorkService(works, assignments, WorkConfig{ResubmissionPolicy: models.ResubmissionReject})
+	req := &models.CreateWorkRequest{StudentID: "alice", AssignmentID: "essay"}
+
+	var ids []string
+	for want := 1; want <= 3; want++ {
+		resp, err := s.CreateWork(context.Background(), req)
+		if err != nil {
+			t.Fatalf("submission %d: %v", want, err)
+		}
+		if resp.AttemptNumber != want {
+			t.Fatalf("submission %d: attempt = %d", want, resp.AttemptNumber)
+		}
+		ids = append(ids, resp.ID)
+	}
+
+	attempts := works.attempts("alice", "essay")
+	if len(attempts) != 3 {
+		t.Fatalf("stored %d attempts, want 3", len(attempts))
+	}
+	// Прежние попытки остаются, но помечаются заменёнными; актуальна только последняя
+	for i, w := range attempts {
+		if w.ID != ids[i] {
+			t.Fatalf("attempt %d has id %s, want %s", i+1, w.ID, ids[i])
+		}
+		if superseded := w.SupersededAt != nil; superseded != (i < 2) {
+			t.Fatalf("attempt %d superseded = %v", i+1, superseded)
+		}
+	}
+}
+
+func TestUploadMarksLateSubmissions(t *testing.T) {
+	tests := []struct {
+		name   string
+		dueAt  map[string]time.Time
+		isLate bool
+	}{
+		{"no deadline", nil, false},
+		{"before deadline", map[string]time.Time{"essay": time.Now().Add(time.Hour)}, false},
+		{"after deadline", map[string]time.Time{"essay": time.Now().Add(-time.Minute)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			works := newMemWorkRepo()
+			assignments := &fakeAssignmentRepo{policies: map[string]string{"essay": ""}, dueAt: tt.dueAt}
+			s, _, _ := newUploadTestService(works, assignments, WorkConfig{})
+
+			resp, err := s.UploadWork(context.Background(), &models.UploadWorkRequest{
+				StudentID:    "alice",
+				AssignmentID: "essay",
+				FileName:     "essay.txt",
+				FileContent:  []byte("essay"),
+			})
+			if err != nil {
+				t.Fatalf("upload: %v", err)
+			}
+
+			if resp.IsLate != tt.isLate {
+				t.Fatalf("response is_late = %v, want %v", resp.IsLate, tt.isLate)
+			}
+			stored, _ := works.GetByID(context.Background(), resp.ID)
+			if stored.IsLate != tt.isLate || stored.FileID != resp.FileID {
+				t.Fatalf("stored work = %+v, want is_late %v with file %s", stored, tt.isLate, resp.FileID)
+			}
+		})
+	}
+}
+
+func uploadRequest(studentID, key string) *models.UploadWorkRequest {
+	return &models.UploadWorkRequest{
+		StudentID:      studentID,
+		AssignmentID:   "essay",
+		FileName:       "essay.txt",
+		FileContent:    []byte("essay"),
+		IdempotencyKey: key,
+	}
+}
+
+func TestUploadReplayReturnsSameWork(t *testing.T) {
+	works := newMemWorkRepo()
+	assignments := &fakeAssignmentRepo{policies: map[string]string{"essay": models.ResubmissionVersion}}
+	s, files, rabbitmq := newUploadTestService(works, assignments, WorkConfig{IdempotencyKeyTTL: time.Hour})
+
+	first, err := s.UploadWork(context.Background(), uploadRequest("alice", "key-1"))
+	if err != nil {
+		t.Fatalf("first upload: %v", err)
+	}
+	// Клиент не дождался ответа и повторил запрос с тем же ключом
+	replay, err := s.UploadWork(context.Background(), uploadRequest("alice", "key-1"))
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+
+	if replay.ID != first.ID || replay.FileID != first.FileID || replay.AttemptNumber != 1 {
+		t.Fatalf("replay = %+v, want the first work %+v", replay, first)
+	}
+	if files.uploads != 1 || len(rabbitmq.published) != 1 {
+		t.Fatalf("uploaded %d files and published %d events, want one of each", files.uploads, len(rabbitmq.published))
+	}
+	if got := works.attempts("alice", "essay"); len(got) != 1 {
+		t.Fatalf("stored %d attempts, want 1", len(got))
+	}
+
+	// Ключ чужой работы не отдаёт её другому студенту
+	students := &fakeStudentRepo{ids: map[string]bool{"alice": true, "bob": true}}
+	other := NewWorkService(works, students, assignments, &fakeOutboxRepo{}, files, nil, rabbitmq, zerolog.Nop(), WorkConfig{})
+	if _, err := other.UploadWork(context.Background(), uploadRequest("bob", "key-1")); err == nil || err.Error() != "idempotency key already used for another work" {
+		t.Fatalf("upload by another student: err = %v, want key already used", err)
+	}
+}
+
+func TestUploadWithExpiredKeyCreatesNewAttempt(t *testing.T) {
+	works := newMemWorkRepo()
+	assignments := &fakeAssignmentRepo{policies: map[string]string{"essay": models.ResubmissionVersion}}
+	s, files, _ := newUploadTestService(works, assignments, WorkConfig{IdempotencyKeyTTL: time.Hour})
+
+	first, err := s.UploadWork(context.Background(), uploadRequest("alice", "key-1"))
+	if err != nil {
+		t.Fatalf("first upload: %v", err)
+	}
+	savedAt := time.Now().Add(-2 * time.Hour)
+	works.works[first.ID].IdempotencyKeyAt = &savedAt
+
+	// Срок ключа истёк: тот же ключ — уже новая сдача, а не повтор старой
+	second, err := s.UploadWork(context.Background(), uploadRequest("alice", "key-1"))
+	if err != nil {
+		t.Fatalf("upload with expired key: %v", err)
+	}
+
+	if second.ID == first.ID || second.AttemptNumber != 2 || files.uploads != 2 {
+		t.Fatalf("second upload = %+v after %d file uploads, want attempt 2 of a new work", second, files.uploads)
+	}
+	if old, _ := works.GetByID(context.Background(), first.ID); old.IdempotencyKey != "" {
+		t.Fatalf("expired key still held by the first work: %q", old.IdempotencyKey)
+	}
+	if current, _ := works.GetByIdempotencyKey(context.Background(), "key-1"); current == nil || current.ID != second.ID {
+		t.Fatalf("key-1 belongs to %+v, want the new work", current)
+	}
+}
+
+// racingWorkRepo не находит работу по ключу при первом поиске: её создал параллельный запрос
+// с тем же ключом уже после проверки
+type racingWorkRepo struct {
+	*memWorkRepo
+
+	missed bool
+}
+
+func (r *racingWorkRepo) GetByIdempotencyKey(ctx context.Context, key string) (*models.Work, error) {
+	if !r.missed {
+		r.missed = true
+		return nil, nil
+	}
+	return r.memWorkRepo.GetByIdempotencyKey(ctx, key)
+}
+
+func TestUploadReusesWorkOfConcurrentSameKeyRequest(t *testing.T) {
+	works := newMemWorkRepo()
+	assignments := &fakeAssignmentRepo{policies: map[string]string{"essay": models.ResubmissionReject}}
+	s, files, _ := newUploadTestService(works, assignments, WorkConfig{})
+
+	winner, err := s.UploadWork(context.Background(), uploadRequest("alice", "key-1"))
+	if err != nil {
+		t.Fatalf("winner upload: %v", err)
+	}
+
+	students := &fakeStudentRepo{ids: map[string]bool{"alice": true}}
+	racing := NewWorkService(&racingWorkRepo{memWorkRepo: works}, students, assignments, &fakeOutboxRepo{}, files, nil, &fakeRabbitMQ{}, zerolog.Nop(), WorkConfig{})
+
+	// Создать вторую работу не дала политика reject, но ключ тот же — это повтор, а не ошибка
+	resp, err := racing.UploadWork(context.Background(), uploadRequest("alice", "key-1"))
+	if err != nil {
+		t.Fatalf("racing upload: %v", err)
+	}
+	if resp.ID != winner.ID || resp.FileID != winner.FileID || files.uploads != 1 {
+		t.Fatalf("racing upload = %+v after %d file uploads, want the winner %+v", resp, files.uploads, winner)
+	}
+}
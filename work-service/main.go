@@ -12,6 +12,7 @@ import (
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/config"
 	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/database"
 	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/logger"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/tracing"
 )
 
 func main() {
@@ -34,18 +35,18 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to load configuration")
 	}
 
-	db, err := database.NewPostgres(cfg.Database)
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Enabled:     cfg.Tracing.Enabled,
+		Endpoint:    cfg.Tracing.Endpoint,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to database")
+		log.Fatal().Err(err).Msg("Failed to initialize tracing")
 	}
-	defer db.Close()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
 
-	if err := db.PingContext(ctx); err != nil {
-		log.Fatal().Err(err).Msg("Failed to ping database")
-	}
+	db := runStartupChecks(cfg, log)
+	defer db.Close()
 
 	log.Info().Msg("Database connection established")
 
@@ -78,6 +79,10 @@ func main() {
 		log.Error().Err(err).Msg("Failed to shutdown gracefully")
 	}
 
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
+
 	log.Info().Msg("Work Service stopped")
 }
 
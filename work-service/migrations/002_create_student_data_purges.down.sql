@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS update_student_data_purges_updated_at ON student_data_purges;
+DROP TABLE IF EXISTS student_data_purges;
+
+ALTER TABLE students DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,29 @@
+-- Мягкое удаление студента до подтверждения удаления данных
+ALTER TABLE students ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+-- Запросы на удаление данных студента (GDPR), одновременно журнал аудита
+CREATE TABLE IF NOT EXISTS student_data_purges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    student_id UUID NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending_confirmation'
+        CHECK (status IN ('pending_confirmation', 'in_progress', 'partial', 'completed', 'cancelled', 'expired')),
+    confirmation_token VARCHAR(64) NOT NULL,
+    requested_by VARCHAR(255),
+    work_ids TEXT[] NOT NULL DEFAULT '{}',
+    deleted_file_ids TEXT[] NOT NULL DEFAULT '{}',
+    failed_file_ids TEXT[] NOT NULL DEFAULT '{}',
+    reports_deleted INTEGER NOT NULL DEFAULT 0,
+    works_deleted INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_student_data_purges_student_id ON student_data_purges(student_id);
+
+CREATE TRIGGER update_student_data_purges_updated_at
+    BEFORE UPDATE ON student_data_purges
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
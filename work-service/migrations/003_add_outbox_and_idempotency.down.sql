@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS outbox_events;
+DROP INDEX IF EXISTS idx_works_idempotency_key;
+ALTER TABLE works DROP COLUMN IF EXISTS idempotency_key;
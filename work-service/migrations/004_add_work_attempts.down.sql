@@ -0,0 +1,8 @@
+-- Однократная сдача снова требует одну работу на студента и задание: прежние попытки удаляются
+DELETE FROM works WHERE superseded_at IS NOT NULL;
+
+ALTER TABLE works DROP CONSTRAINT IF EXISTS works_student_assignment_attempt_key;
+ALTER TABLE works ADD CONSTRAINT works_student_id_assignment_id_key UNIQUE (student_id, assignment_id);
+
+ALTER TABLE works DROP COLUMN IF EXISTS superseded_at;
+ALTER TABLE works DROP COLUMN IF EXISTS attempt_number;
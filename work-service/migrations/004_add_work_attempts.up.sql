@@ -0,0 +1,6 @@
+-- Несколько попыток сдачи одной работы: прежние попытки помечаются superseded_at
+ALTER TABLE works ADD COLUMN IF NOT EXISTS attempt_number INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE works ADD COLUMN IF NOT EXISTS superseded_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE works DROP CONSTRAINT IF EXISTS works_student_id_assignment_id_key;
+ALTER TABLE works ADD CONSTRAINT works_student_assignment_attempt_key UNIQUE (student_id, assignment_id, attempt_number);
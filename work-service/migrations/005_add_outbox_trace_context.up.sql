@@ -0,0 +1,2 @@
+-- Контекст трассировки запроса, создавшего событие: фоновая публикация продолжает ту же трассу
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS trace_context JSONB;
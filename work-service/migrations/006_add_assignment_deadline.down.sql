@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_works_assignment_is_late;
+ALTER TABLE works DROP COLUMN IF EXISTS is_late;
+ALTER TABLE assignments DROP COLUMN IF EXISTS due_at;
@@ -0,0 +1,5 @@
+-- Срок сдачи задания и отметка о сдаче работы после него
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS due_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE works ADD COLUMN IF NOT EXISTS is_late BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_works_assignment_is_late ON works(assignment_id, is_late);
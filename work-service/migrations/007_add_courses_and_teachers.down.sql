@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_assignments_course_id;
+ALTER TABLE assignments DROP COLUMN IF EXISTS course_id;
+DROP TABLE IF EXISTS courses;
+DROP TABLE IF EXISTS teachers;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_works_idempotency_key_at;
+ALTER TABLE works DROP COLUMN IF EXISTS idempotency_key_at;
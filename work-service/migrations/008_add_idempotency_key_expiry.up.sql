@@ -0,0 +1,6 @@
+-- Момент сохранения ключа идемпотентности: по истечении works.idempotency_key_ttl ключ освобождается
+ALTER TABLE works ADD COLUMN IF NOT EXISTS idempotency_key_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE works SET idempotency_key_at = updated_at WHERE idempotency_key IS NOT NULL AND idempotency_key_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_works_idempotency_key_at ON works(idempotency_key_at) WHERE idempotency_key IS NOT NULL;
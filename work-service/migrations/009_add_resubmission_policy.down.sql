@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_works_replaced_by;
+ALTER TABLE works DROP COLUMN IF EXISTS replaced_by;
+ALTER TABLE assignments DROP COLUMN IF EXISTS resubmission_policy;
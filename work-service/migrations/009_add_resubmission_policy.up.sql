@@ -0,0 +1,8 @@
+-- Политика повторной сдачи по заданию: NULL — works.resubmission_policy из конфигурации
+ALTER TABLE assignments ADD COLUMN IF NOT EXISTS resubmission_policy VARCHAR(20)
+    CHECK (resubmission_policy IN ('reject', 'replace', 'version'));
+
+-- Попытка, заменённая при политике replace: её файл удалён, источником плагиата она не считается
+ALTER TABLE works ADD COLUMN IF NOT EXISTS replaced_by UUID REFERENCES works(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_works_replaced_by ON works(replaced_by) WHERE replaced_by IS NOT NULL;
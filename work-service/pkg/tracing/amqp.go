@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"context"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+)
+
+// amqpCarrier — заголовки сообщения RabbitMQ как носитель контекста трассировки
+type amqpCarrier amqp.Table
+
+func (c amqpCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+func (c amqpCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c amqpCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// InjectAMQP записывает контекст трассировки ctx в заголовки публикуемого сообщения
+func InjectAMQP(ctx context.Context, headers amqp.Table) {
+	otel.GetTextMapPropagator().Inject(ctx, amqpCarrier(headers))
+}
+
+// ExtractAMQP продолжает в ctx трассу из заголовков полученного сообщения
+func ExtractAMQP(ctx context.Context, headers amqp.Table) context.Context {
+	if len(headers) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, amqpCarrier(headers))
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/config"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/internal/database"
+	"github.com/RubachokBoss/plagiarism-checker/work-service/pkg/selfcheck"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog"
+)
+
+// runStartupChecks проверяет конфигурацию и зависимости, выводит сводку OK/FAIL
+// и завершает процесс со списком всех проблем. Возвращает открытое подключение к БД.
+func runStartupChecks(cfg *config.Config, log zerolog.Logger) *sql.DB {
+	var db *sql.DB
+	checks := []selfcheck.Check{
+		{Name: "config", Run: func(context.Context) error { return cfg.Validate() }},
+		{Name: "database", Run: func(context.Context) error {
+			var err error
+			db, err = database.NewPostgres(cfg.Database)
+			return err
+		}},
+	}
+	if cfg.Startup.SelfCheck {
+		checks = append(checks, rabbitMQCheck(cfg.RabbitMQ.URL, true))
+	}
+
+	if err := selfcheck.Run(context.Background(), log, cfg.Startup.CheckTimeout, checks...); err != nil {
+		log.Fatal().Err(err).Msg("Startup self-check failed")
+	}
+
+	return db
+}
+
+// rabbitMQCheck проверяет, что брокер принимает подключение
+func rabbitMQCheck(url string, optional bool) selfcheck.Check {
+	return selfcheck.Check{
+		Name:     "rabbitmq",
+		Optional: optional,
+		Run: func(ctx context.Context) error {
+			timeout := 5 * time.Second
+			if deadline, ok := ctx.Deadline(); ok {
+				timeout = time.Until(deadline)
+			}
+
+			conn, err := amqp.DialConfig(url, amqp.Config{Dial: amqp.DefaultDial(timeout)})
+			if err != nil {
+				return err
+			}
+			return conn.Close()
+		},
+	}
+}